@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
@@ -38,15 +41,20 @@ import (
 
 	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/clustertlspolicy"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/dnshealthcheckprobe"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/dnspolicy"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/dnsrecord"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/managedzone"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/tlspolicy"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/aws"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/dnsprovider"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/health"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/inventory"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	//+kubebuilder:scaffold:imports
 )
@@ -73,12 +81,111 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var certProvider string
+	var enableWebhooks bool
+	var route53RequestsPerSecond float64
+	var route53OwnerID string
+	var route53RecordPolicy string
+	var route53RecordSetCacheTTL time.Duration
+	var zoneIDFilter string
+	var domainFilter string
+	var tlsReadinessGate bool
+	var sharedCertificateNamespace string
+	var certificateExpiryWarningWindow time.Duration
+	var startupJitterWindow time.Duration
+	var providerConnectivityCheckInterval time.Duration
+	var dnsChangeWebhookURL string
+	var allowedIssuerGroups string
+	var dnsChangeActor string
+	var tlsPolicyResyncPeriod time.Duration
+	var dnsPolicyResyncPeriod time.Duration
+	var dnsRecordResyncPeriod time.Duration
+	var managedZoneResyncPeriod time.Duration
+	var dnsRecordMaxConcurrentReconciles int
+	var dnsPolicyMaxConcurrentReconciles int
+	var tlsPolicyMaxConcurrentReconciles int
+	var managedZoneMaxConcurrentReconciles int
+	var gatewayMaxConcurrentReconciles int
+	var inventoryAddr string
+	var inventoryTokenFile string
+	var inventoryCertDir string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&certProvider, "cert-provider", "glbc-ca", "The name of the certificate provider to use")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the admission webhooks. "+
+			"The webhook server expects the serving certificates to be mounted at /tmp/k8s-webhook-server/serving-certs.")
+	flag.Float64Var(&route53RequestsPerSecond, "route53-requests-per-second", aws.DefaultRoute53RequestsPerSecond,
+		"The maximum rate of requests sent to Route53 per hosted zone, not per account: the requests to several zones of an account can exceed the account limit. "+
+			"Throttled requests are retried with an exponential backoff.")
+	flag.DurationVar(&route53RecordSetCacheTTL, "route53-record-set-cache-ttl", aws.DefaultRecordSetCacheTTL,
+		"How long the record sets listed from a Route53 hosted zone are reused by the DNSRecords of the zone. "+
+			"The zones are only listed, and the cache only used, to check the owners of the records when --route53-owner-id is set, and for CAA records. "+
+			"The cache of a zone is dropped when the controller changes its records. Set to 0 to disable the cache.")
+	flag.StringVar(&route53OwnerID, "route53-owner-id", "",
+		"The owner recorded in TXT records for the Route53 records created by this controller. "+
+			"When set, records owned by another controller are not changed.")
+	flag.StringVar(&route53RecordPolicy, "route53-record-policy", "",
+		"How the Route53 records existing at the names of a DNSRecord are handled: \"adopt-only\" claims the records without an owner, "+
+			"\"strict-delete\" claims them and deletes the records at those names that are not among the endpoints of the DNSRecord. "+
+			"When empty, only the records previously published for the DNSRecord are claimed. Records at other names are never deleted. "+
+			"Requires --route53-owner-id.")
+	flag.StringVar(&zoneIDFilter, "zone-id-filter", "",
+		"A comma separated list of the provider IDs of the zones the controller is allowed to manage. All zones are allowed when empty.")
+	flag.StringVar(&domainFilter, "domain-filter", "",
+		"A comma separated list of the domains, and their subdomains, of the zones the controller is allowed to manage. All zones are allowed when empty.")
+	flag.BoolVar(&tlsReadinessGate, "tls-readiness-gate", false,
+		"Do not place gateways on clusters while TLSPolicies report their TLS certificates as not ready.")
+	flag.StringVar(&sharedCertificateNamespace, "shared-certificate-namespace", "",
+		"The namespace of the certificates shared by the gateways requesting identical certificates from a ClusterIssuer. "+
+			"Certificates are not shared when empty.")
+	flag.DurationVar(&certificateExpiryWarningWindow, "certificate-expiry-warning-window", tlspolicy.DefaultCertificateExpiryWarningWindow,
+		"How long before its expiry a TLSPolicy Certificate that was not renewed is reported by the CertificateExpiringSoon condition of the policy.")
+	flag.DurationVar(&startupJitterWindow, "startup-jitter-window", controller.DefaultStartupJitterWindow,
+		"The window the first reconcile of each resource is randomly spread over once the controller starts, "+
+			"so that a restart does not reconcile every resource at once. Disabled when 0.")
+	flag.DurationVar(&providerConnectivityCheckInterval, "provider-connectivity-check-interval", dnsprovider.DefaultConnectivityCheckInterval,
+		"How often the DNS providers of the managed zones are checked to be reachable. "+
+			"The controller is not ready while a provider is unreachable for 3 consecutive checks. Disabled when 0.")
+	flag.StringVar(&dnsChangeWebhookURL, "dns-change-webhook-url", "",
+		"The URL each change applied to the DNS providers is posted to as JSON, e.g. for an audit trail. Disabled when empty.")
+	flag.StringVar(&dnsChangeActor, "dns-change-actor", dns.DefaultChangeActor,
+		"The actor the changes posted to the DNS change webhook are reported as made by.")
+	flag.StringVar(&allowedIssuerGroups, "allowed-issuer-groups", certmanv1.SchemeGroupVersion.Group,
+		"A comma separated list of the API groups of the issuers TLSPolicies may reference, e.g. to add the group of the external issuers of another signer.")
+	flag.DurationVar(&tlsPolicyResyncPeriod, "tlspolicy-resync-period", 0,
+		"The longest a TLSPolicy or ClusterTLSPolicy waits to be reconciled again after a successful reconcile. "+
+			"Disabled when 0, the policies are then only requeued when their certificates need it.")
+	flag.DurationVar(&dnsPolicyResyncPeriod, "dnspolicy-resync-period", 0,
+		"The longest a DNSPolicy waits to be reconciled again after a successful reconcile. "+
+			"Disabled when 0, the policies are then only requeued when their records need it.")
+	flag.DurationVar(&dnsRecordResyncPeriod, "dnsrecord-resync-period", 0,
+		"The longest a DNSRecord waits to be published again after a successful reconcile, e.g. to restore records changed in the DNS provider. "+
+			"Disabled when 0, the records are then only requeued when their provider needs it.")
+	flag.DurationVar(&managedZoneResyncPeriod, "managedzone-resync-period", 0,
+		"The longest a ManagedZone waits to be reconciled again after a successful reconcile, e.g. to refresh its record count. "+
+			"Disabled when 0, the zones are then only requeued when their provider needs it.")
+	flag.IntVar(&dnsRecordMaxConcurrentReconciles, "dnsrecord-max-concurrent-reconciles", 1,
+		"The number of DNSRecords published at once. The requests sent to Route53 stay within --route53-requests-per-second for each hosted zone.")
+	flag.IntVar(&dnsPolicyMaxConcurrentReconciles, "dnspolicy-max-concurrent-reconciles", 1,
+		"The number of DNSPolicies reconciled at once.")
+	flag.IntVar(&tlsPolicyMaxConcurrentReconciles, "tlspolicy-max-concurrent-reconciles", 1,
+		"The number of TLSPolicies, and of ClusterTLSPolicies, reconciled at once.")
+	flag.IntVar(&managedZoneMaxConcurrentReconciles, "managedzone-max-concurrent-reconciles", 1,
+		"The number of ManagedZones reconciled at once. The requests sent to Route53 stay within --route53-requests-per-second for each hosted zone.")
+	flag.IntVar(&gatewayMaxConcurrentReconciles, "gateway-max-concurrent-reconciles", 1,
+		"The number of Gateways placed at once.")
+	flag.StringVar(&inventoryAddr, "inventory-bind-address", "",
+		"The address the read-only inventory endpoint of the managed gateways, DNS names and certificates binds to. Disabled when empty.")
+	flag.StringVar(&inventoryTokenFile, "inventory-token-file", "",
+		"The file holding the bearer token the requests to the inventory endpoint must carry. Required when the endpoint is enabled.")
+	flag.StringVar(&inventoryCertDir, "inventory-cert-dir", "",
+		"The directory holding the tls.crt and tls.key the inventory endpoint is served over HTTPS with, "+
+			"e.g. the serving certificates of the webhook server at /tmp/k8s-webhook-server/serving-certs. "+
+			"When empty the endpoint is served over plain HTTP and the bearer token is sent in the clear, "+
+			"it must then be bound to localhost or sit behind a TLS-terminating proxy.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -86,6 +193,7 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	v1alpha1.SetAllowedIssuerGroups(strings.Split(allowedIssuerGroups, ",")...)
 
 	ctx := ctrl.SetupSignalHandler()
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
@@ -101,8 +209,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	recordPolicy, err := aws.ParseRecordPolicy(route53RecordPolicy)
+	if err == nil {
+		err = recordPolicy.ValidateOwner(route53OwnerID)
+	}
+	if err != nil {
+		setupLog.Error(err, "invalid route53 record policy")
+		os.Exit(1)
+	}
+
 	placer := placement.NewOCMPlacer(mgr.GetClient())
-	provider := dnsprovider.NewProvider(mgr.GetClient())
+	zoneFilter := dns.NewZoneFilter(zoneIDFilter, domainFilter)
+	provider := dnsprovider.NewProvider(mgr.GetClient(), aws.NewRateLimiter(route53RequestsPerSecond), aws.NewRecordSetCache(route53RecordSetCacheTTL), route53OwnerID, recordPolicy, zoneFilter)
 
 	healthMonitor := health.NewMonitor()
 	healthCheckQueue := health.NewRequestQueue(time.Second * 5)
@@ -117,10 +235,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	var changeSink dns.ChangeSink
+	if dnsChangeWebhookURL != "" {
+		changeSink = dns.NewWebhookChangeSink(dnsChangeWebhookURL, dnsChangeActor)
+	}
+
 	if err = (&dnsrecord.DNSRecordReconciler{
 		Client:      mgr.GetClient(),
 		Scheme:      mgr.GetScheme(),
 		DNSProvider: provider.DNSProviderFactory,
+		ZoneFilter:  zoneFilter,
+		ChangeSink:  changeSink,
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow:     startupJitterWindow,
+			ResyncPeriod:            dnsRecordResyncPeriod,
+			MaxConcurrentReconciles: dnsRecordMaxConcurrentReconciles,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DNSRecord")
 		os.Exit(1)
@@ -138,10 +268,21 @@ func main() {
 		},
 		DNSProvider: provider.DNSProviderFactory,
 		Placer:      placer,
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow:     startupJitterWindow,
+			ResyncPeriod:            dnsPolicyResyncPeriod,
+			MaxConcurrentReconciles: dnsPolicyMaxConcurrentReconciles,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DNSPolicy")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&v1alpha1.DNSPolicy{}).SetupWebhookWithManager(mgr, dnsprovider.NewGeoCodeValidator(mgr.GetClient())); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "DNSPolicy")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	tlsPolicyBaseReconciler := reconcilers.NewBaseReconciler(
@@ -154,32 +295,78 @@ func main() {
 		TargetRefReconciler: reconcilers.TargetRefReconciler{
 			BaseReconciler: tlsPolicyBaseReconciler,
 		},
+		SharedCertificateNamespace:     sharedCertificateNamespace,
+		CertificateExpiryWarningWindow: certificateExpiryWarningWindow,
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow:     startupJitterWindow,
+			ResyncPeriod:            tlsPolicyResyncPeriod,
+			MaxConcurrentReconciles: tlsPolicyMaxConcurrentReconciles,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "TLSPolicy")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&v1alpha1.TLSPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "TLSPolicy")
+			os.Exit(1)
+		}
+	}
+
+	if err = (&clustertlspolicy.ClusterTLSPolicyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow:     startupJitterWindow,
+			ResyncPeriod:            tlsPolicyResyncPeriod,
+			MaxConcurrentReconciles: tlsPolicyMaxConcurrentReconciles,
+		},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterTLSPolicy")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err = (&managedzone.ManagedZoneReconciler{
 		Client:      mgr.GetClient(),
 		Scheme:      mgr.GetScheme(),
 		DNSProvider: provider.DNSProviderFactory,
+		ZoneFilter:  zoneFilter,
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow:     startupJitterWindow,
+			ResyncPeriod:            managedZoneResyncPeriod,
+			MaxConcurrentReconciles: managedZoneMaxConcurrentReconciles,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ManagedZone")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&v1alpha1.ManagedZone{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ManagedZone")
+			os.Exit(1)
+		}
+	}
 	if err = (&gateway.GatewayClassReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow: startupJitterWindow,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GatewayClass")
 		os.Exit(1)
 	}
 
 	if err = (&gateway.GatewayReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		Placement: placer,
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		Placement:        placer,
+		TLSReadinessGate: tlsReadinessGate,
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow:     startupJitterWindow,
+			MaxConcurrentReconciles: gatewayMaxConcurrentReconciles,
+		},
 	}).SetupWithManager(mgr, ctx); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Gateway")
 		os.Exit(1)
@@ -189,6 +376,9 @@ func main() {
 		Client:        mgr.GetClient(),
 		HealthMonitor: healthMonitor,
 		Queue:         healthCheckQueue,
+		ReconcilerOptions: controller.ReconcilerOptions{
+			StartupJitterWindow: startupJitterWindow,
+		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DNSHealthCheckProbe")
 		os.Exit(1)
@@ -203,6 +393,32 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if providerConnectivityCheckInterval > 0 {
+		connectivityCheck := dnsprovider.NewConnectivityCheck(mgr.GetClient(), provider.DNSProviderFactory, providerConnectivityCheckInterval)
+		if err := mgr.Add(connectivityCheck); err != nil {
+			setupLog.Error(err, "unable to set up dns provider connectivity check")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("dns-providers", connectivityCheck.Checker); err != nil {
+			setupLog.Error(err, "unable to set up dns provider ready check")
+			os.Exit(1)
+		}
+	}
+
+	if inventoryAddr != "" {
+		token, err := os.ReadFile(inventoryTokenFile)
+		if err == nil && len(bytes.TrimSpace(token)) == 0 {
+			err = fmt.Errorf("no token in %q", inventoryTokenFile)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to read the inventory token")
+			os.Exit(1)
+		}
+		if err := mgr.Add(inventory.NewServer(inventoryAddr, inventoryCertDir, mgr.GetClient(), string(bytes.TrimSpace(token)))); err != nil {
+			setupLog.Error(err, "unable to set up inventory server")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
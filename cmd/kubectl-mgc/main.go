@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-mgc is a kubectl plugin for the multicluster gateway controller resources, run as `kubectl mgc`
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/tlspolicy"
+)
+
+const usage = `Usage: kubectl mgc tlspolicy preview [flags] <namespace>/<name>
+
+Prints the listeners a TLSPolicy would issue Certificates for, their issuer and the validation errors
+reconciling the policy would report, without applying it.
+
+Flags:
+`
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(certmanv1.AddToScheme(scheme))
+	utilruntime.Must(gatewayapi.AddToScheme(scheme))
+}
+
+func main() {
+	var sharedCertificateNamespace string
+	var allowedIssuerGroups string
+	flag.StringVar(&sharedCertificateNamespace, "shared-certificate-namespace", "",
+		"The namespace the controller shares Certificates in, as set on the controller.")
+	flag.StringVar(&allowedIssuerGroups, "allowed-issuer-groups", certmanv1.SchemeGroupVersion.Group,
+		"The comma separated API groups of the issuers TLSPolicies may reference, as set on the controller.")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	v1alpha1.SetAllowedIssuerGroups(strings.Split(allowedIssuerGroups, ",")...)
+
+	newClient := func() (client.Client, error) {
+		config, err := ctrl.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		return client.New(config, client.Options{Scheme: scheme})
+	}
+	if err := run(context.Background(), flag.Args(), os.Stdout, newClient, sharedCertificateNamespace); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the subcommand in args, the client is only created once the arguments are valid
+func run(ctx context.Context, args []string, out io.Writer, newClient func() (client.Client, error), sharedCertificateNamespace string) error {
+	if len(args) != 3 || args[0] != "tlspolicy" || args[1] != "preview" {
+		return fmt.Errorf("unknown command %q, see --help", strings.Join(args, " "))
+	}
+	namespace, name, ok := strings.Cut(args[2], "/")
+	if !ok || namespace == "" || name == "" {
+		return fmt.Errorf("invalid TLSPolicy %q, expected <namespace>/<name>", args[2])
+	}
+
+	k8sClient, err := newClient()
+	if err != nil {
+		return err
+	}
+	return previewTLSPolicy(ctx, k8sClient, client.ObjectKey{Namespace: namespace, Name: name}, out, sharedCertificateNamespace)
+}
+
+// previewTLSPolicy prints the plan of the TLSPolicy, computed by the reconciler of the controller so that it matches
+// what reconciling the policy does
+func previewTLSPolicy(ctx context.Context, k8sClient client.Client, key client.ObjectKey, out io.Writer, sharedCertificateNamespace string) error {
+	tlsPolicy := &v1alpha1.TLSPolicy{}
+	if err := k8sClient.Get(ctx, key, tlsPolicy); err != nil {
+		return fmt.Errorf("failed to get TLSPolicy %s: %w", key, err)
+	}
+
+	r := &tlspolicy.TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(k8sClient, k8sClient.Scheme(), k8sClient, ctrl.Log, record.NewFakeRecorder(0)),
+		},
+		SharedCertificateNamespace: sharedCertificateNamespace,
+	}
+	preview, err := r.Preview(ctrl.LoggerInto(ctx, r.Logger()), tlsPolicy)
+	if err != nil {
+		return err
+	}
+	printPreview(out, preview)
+	return nil
+}
+
+func printPreview(out io.Writer, preview *tlspolicy.Preview) {
+	fmt.Fprintf(out, "TLSPolicy: %s\n", preview.Policy)
+	if preview.Issuer != "" {
+		fmt.Fprintf(out, "Issuer: %s\n", preview.Issuer)
+	}
+	if preview.Secret != "" {
+		fmt.Fprintf(out, "Secret: %s\n", preview.Secret)
+	}
+
+	fmt.Fprintln(out)
+	if len(preview.Listeners) == 0 {
+		fmt.Fprintln(out, "No listeners")
+	} else {
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "GATEWAY\tLISTENER\tHOSTNAME\tCERTIFICATE\tDNS NAMES\tISSUER\tREASON")
+		for _, l := range preview.Listeners {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", l.Gateway, l.Listener, orNone(l.Hostname), orNone(l.Certificate),
+				orNone(strings.Join(l.DNSNames, ",")), orNone(l.Issuer), orNone(l.Reason))
+		}
+		w.Flush()
+	}
+
+	if len(preview.Errors) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Errors:")
+		for _, err := range preview.Errors {
+			fmt.Fprintf(out, "  - %s\n", err)
+		}
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,130 @@
+//go:build unit
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestRun_tlsPolicyPreview(t *testing.T) {
+	gateway := testutil.NewTestGateway("prod-web", "istio", "test").Gateway
+	gateway.Spec.Listeners = []gatewayv1beta1.Listener{
+		{Name: "api", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("api.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+		{Name: "other", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("other.example.org")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+	}
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "test").
+		WithTargetGateway("prod-web").
+		WithIssuer("letsencrypt", certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Spec.AutoConfigureListeners = true
+	tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+	issuer := &certmanv1.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "letsencrypt"}}
+
+	testCases := []struct {
+		name       string
+		args       []string
+		objects    []client.Object
+		wantErr    string
+		wantOutput []string
+	}{
+		{
+			name:    "prints the listeners the policy issues Certificates for",
+			args:    []string{"tlspolicy", "preview", "test/prod-web-tls"},
+			objects: []client.Object{gateway, tlsPolicy, issuer},
+			wantOutput: []string{
+				"TLSPolicy: test/prod-web-tls",
+				"Issuer: ClusterIssuer letsencrypt",
+				"GATEWAY        LISTENER  HOSTNAME           CERTIFICATE            DNS NAMES        ISSUER                     REASON",
+				"test/prod-web  api       api.example.com    test/prod-web-api-tls  api.example.com  ClusterIssuer letsencrypt  -",
+				"test/prod-web  other     other.example.org  -                      -                -                          spec.listeners[1].tls: Required value: the TLS block cannot be empty",
+			},
+		},
+		{
+			name:    "prints the validation errors",
+			args:    []string{"tlspolicy", "preview", "test/prod-web-tls"},
+			objects: []client.Object{gateway, tlsPolicy},
+			wantOutput: []string{
+				"Errors:",
+				`  - clusterissuers.cert-manager.io "letsencrypt" not found`,
+			},
+		},
+		{
+			name:    "the target gateway is not found",
+			args:    []string{"tlspolicy", "preview", "test/prod-web-tls"},
+			objects: []client.Object{tlsPolicy, issuer},
+			wantOutput: []string{
+				"No listeners",
+				`"prod-web" not found`,
+			},
+		},
+		{
+			name:    "the policy is not found",
+			args:    []string{"tlspolicy", "preview", "test/missing"},
+			wantErr: "failed to get TLSPolicy test/missing",
+		},
+		{
+			name:    "invalid policy name",
+			args:    []string{"tlspolicy", "preview", "prod-web-tls"},
+			wantErr: `invalid TLSPolicy "prod-web-tls"`,
+		},
+		{
+			name:    "unknown command",
+			args:    []string{"dnspolicy", "preview", "test/prod-web"},
+			wantErr: `unknown command "dnspolicy preview test/prod-web"`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			objects := make([]client.Object, 0, len(testCase.objects))
+			for _, obj := range testCase.objects {
+				objects = append(objects, obj.DeepCopyObject().(client.Object))
+			}
+			f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			out := &bytes.Buffer{}
+
+			err := run(context.TODO(), testCase.args, out, func() (client.Client, error) { return f, nil }, "")
+			if testCase.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+					t.Fatalf("expected error %q, got %v", testCase.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			for _, line := range testCase.wantOutput {
+				if !strings.Contains(out.String(), line+"\n") {
+					t.Errorf("expected the output to contain %q, got:\n%s", line, out.String())
+				}
+			}
+		})
+	}
+
+	// the preview does not apply the policy
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway.DeepCopy(), tlsPolicy.DeepCopy(), issuer.DeepCopy()).Build()
+	if err := run(context.TODO(), []string{"tlspolicy", "preview", "test/prod-web-tls"}, &bytes.Buffer{}, func() (client.Client, error) { return f, nil }, ""); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	certs := &certmanv1.CertificateList{}
+	if err := f.List(context.TODO(), certs); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	gw := &gatewayv1beta1.Gateway{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(gateway), gw); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(certs.Items) != 0 || gw.Spec.Listeners[0].TLS != nil {
+		t.Errorf("expected the preview not to change any resource, got %d Certificates and listener TLS %v", len(certs.Items), gw.Spec.Listeners[0].TLS)
+	}
+}
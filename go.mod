@@ -3,20 +3,22 @@ module github.com/Kuadrant/multicluster-gateway-controller
 go 1.20
 
 require (
-	github.com/aws/aws-sdk-go v1.44.175
+	github.com/aws/aws-sdk-go v1.50.0
 	github.com/go-logr/logr v1.2.3
 	github.com/google/uuid v1.3.0
 	github.com/goombaio/namegenerator v0.0.0-20181006234301-989e774b106e
 	github.com/jetstack/cert-manager v1.7.1
+	github.com/kuadrant/authorino v0.10.0
 	github.com/kuadrant/kuadrant-operator v0.1.1-0.20230323151616-58593d01833a
 	github.com/martinlindhe/base36 v1.1.1
-	github.com/miekg/dns v1.1.34
 	github.com/onsi/ginkgo/v2 v2.6.1
 	github.com/onsi/gomega v1.24.2
 	github.com/operator-framework/api v0.17.5
 	github.com/prometheus/client_golang v1.14.0
 	github.com/rs/xid v1.4.0
-	golang.org/x/net v0.8.0
+	golang.org/x/net v0.17.0
+	golang.org/x/oauth2 v0.5.0
+	golang.org/x/time v0.3.0
 	google.golang.org/api v0.110.0
 	k8s.io/api v0.26.1
 	k8s.io/apimachinery v0.26.1
@@ -64,7 +66,6 @@ require (
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/kuadrant/authorino v0.10.0 // indirect
 	github.com/kuadrant/authorino-operator v0.4.1 // indirect
 	github.com/kuadrant/limitador-operator v0.4.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
@@ -90,12 +91,10 @@ require (
 	go.uber.org/atomic v1.10.0 // indirect
 	go.uber.org/multierr v1.8.0 // indirect
 	go.uber.org/zap v1.24.0 // indirect
-	golang.org/x/crypto v0.5.0 // indirect
-	golang.org/x/oauth2 v0.5.0 // indirect
-	golang.org/x/sys v0.6.0 // indirect
-	golang.org/x/term v0.6.0 // indirect
-	golang.org/x/text v0.8.0 // indirect
-	golang.org/x/time v0.3.0 // indirect
+	golang.org/x/crypto v0.14.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/term v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
 	gomodules.xyz/jsonpatch/v2 v2.2.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/genproto v0.0.0-20230209215440-0dfe4f8abfcc // indirect
@@ -13,6 +13,14 @@ type ConditionReason string
 
 const (
 	ConditionTypeReady ConditionType = "Ready"
+	// ConditionTypeTLSCertificatesReady is set on a Gateway by the TLSPolicy controller, it is true once the Secrets of
+	// the listeners configured by TLSPolicies hold a certificate
+	ConditionTypeTLSCertificatesReady ConditionType = "kuadrant.io/TLSCertificatesReady"
+	// ConditionTypePaused is set on a policy while its reconciliation is paused by the kuadrant.io/paused annotation
+	ConditionTypePaused ConditionType = "Paused"
+	// ConditionTypeMaintenanceWindow is set on a DNSRecord while the changes to its managed zones are frozen by the
+	// kuadrant.io/maintenance-window annotation
+	ConditionTypeMaintenanceWindow ConditionType = "MaintenanceWindow"
 
 	//common policy reasons for policy affected conditions
 
@@ -20,8 +28,20 @@ const (
 	PolicyReasonInvalid    ConditionReason = "Invalid"
 	PolicyReasonUnknown    ConditionReason = "Unknown"
 	PolicyReasonConflicted ConditionReason = "Conflicted"
+	PolicyReasonPaused     ConditionReason = "Paused"
 )
 
+// BuildPausedCondition returns the condition of a policy whose reconciliation is paused
+func BuildPausedCondition(observedGeneration int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(ConditionTypePaused),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(PolicyReasonPaused),
+		Message:            "reconciliation is paused by the kuadrant.io/paused annotation, remove it to resume",
+		ObservedGeneration: observedGeneration,
+	}
+}
+
 func BuildPolicyAffectedCondition(conditionType ConditionType, policyObject runtime.Object, targetRef metav1.Object, reason ConditionReason, err error) metav1.Condition {
 
 	condition := metav1.Condition{
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultStartupJitterWindow is the window the first reconciles of the controllers are spread over when none is
+// configured
+const DefaultStartupJitterWindow = 30 * time.Second
+
+// startupJitter delays the first reconcile of each object within the window following the first reconcile of the
+// controller. Controllers only start reconciling once the leader election is acquired, so the window starts when
+// the work does.
+type startupJitter struct {
+	reconcile.Reconciler
+
+	window time.Duration
+	now    func() time.Time
+	int63n func(int64) int64
+
+	mux     sync.Mutex
+	started time.Time
+	seen    map[reconcile.Request]struct{}
+}
+
+// WithStartupJitter returns a reconciler spreading the first reconcile of each object over the window after the
+// controller starts, so that the objects reconciled on a restart do not all reach the provider APIs at once. Objects
+// are requeued to a random time in the window, and reconciled right away once it is past. Every later reconcile of
+// an object, and every reconcile after the window, is passed to the reconciler. A zero window disables the jitter.
+func WithStartupJitter(r reconcile.Reconciler, window time.Duration) reconcile.Reconciler {
+	if window <= 0 {
+		return r
+	}
+	return &startupJitter{
+		Reconciler: r,
+		window:     window,
+		now:        time.Now,
+		int63n:     rand.New(rand.NewSource(time.Now().UnixNano())).Int63n,
+		seen:       map[reconcile.Request]struct{}{},
+	}
+}
+
+func (j *startupJitter) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	if delay := j.delay(req); delay > 0 {
+		return reconcile.Result{RequeueAfter: delay}, nil
+	}
+	return j.Reconciler.Reconcile(ctx, req)
+}
+
+// delay returns how long the reconcile of the request is delayed, zero if it is not
+func (j *startupJitter) delay(req reconcile.Request) time.Duration {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+
+	if j.seen == nil {
+		return 0
+	}
+	now := j.now()
+	if j.started.IsZero() {
+		j.started = now
+	}
+	elapsed := now.Sub(j.started)
+	if elapsed >= j.window {
+		// the window is over, the requests are no longer tracked
+		j.seen = nil
+		return 0
+	}
+	if _, ok := j.seen[req]; ok {
+		return 0
+	}
+	j.seen[req] = struct{}{}
+	return time.Duration(j.int63n(int64(j.window))) - elapsed
+}
@@ -0,0 +1,99 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type countingReconciler struct {
+	reconciled map[reconcile.Request]int
+}
+
+func (r *countingReconciler) Reconcile(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+	r.reconciled[req]++
+	return reconcile.Result{}, nil
+}
+
+func TestWithStartupJitter(t *testing.T) {
+	const objects = 1000
+	window := 30 * time.Second
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+
+	inner := &countingReconciler{reconciled: map[reconcile.Request]int{}}
+	r := WithStartupJitter(inner, window).(*startupJitter)
+	r.now = func() time.Time { return now }
+	r.int63n = rand.New(rand.NewSource(1)).Int63n
+
+	requests := make([]reconcile.Request, 0, objects)
+	for i := 0; i < objects; i++ {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: fmt.Sprintf("policy-%d", i)}})
+	}
+
+	// on a restart every object is reconciled at once, each is requeued to a random time of the window
+	buckets := make([]int, window/time.Second)
+	for _, req := range requests {
+		result, err := r.Reconcile(context.TODO(), req)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if result.RequeueAfter <= 0 || result.RequeueAfter >= window {
+			t.Fatalf("expected the reconcile to be delayed within the window, got %v", result.RequeueAfter)
+		}
+		buckets[result.RequeueAfter/time.Second]++
+	}
+	if len(inner.reconciled) != 0 {
+		t.Errorf("expected no object to be reconciled right away, got %d", len(inner.reconciled))
+	}
+	// the reconciles are spread over the window rather than simultaneous, each second of the window gets about
+	// objects/30 of them
+	for second, count := range buckets {
+		if count == 0 || count > 3*objects/len(buckets) {
+			t.Errorf("expected the reconciles to be spread over the window, got %d reconciles in second %d: %v", count, second, buckets)
+		}
+	}
+
+	// the requeued reconciles are passed to the reconciler
+	now = start.Add(10 * time.Second)
+	if result, err := r.Reconcile(context.TODO(), requests[0]); err != nil || result.RequeueAfter != 0 {
+		t.Fatalf("expected the requeued reconcile not to be delayed, got %v %v", result, err)
+	}
+	if inner.reconciled[requests[0]] != 1 {
+		t.Errorf("expected the requeued object to be reconciled")
+	}
+
+	// an object first seen later in the window is delayed within what is left of it, or reconciled right away
+	late := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: "late"}}
+	result, err := r.Reconcile(context.TODO(), late)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result.RequeueAfter >= window-10*time.Second {
+		t.Errorf("expected the late object to be delayed within the rest of the window, got %v", result.RequeueAfter)
+	}
+
+	// after the window the objects are reconciled right away
+	now = start.Add(window)
+	other := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: "other"}}
+	if result, err := r.Reconcile(context.TODO(), other); err != nil || result.RequeueAfter != 0 {
+		t.Fatalf("expected no delay after the window, got %v %v", result, err)
+	}
+	if inner.reconciled[other] != 1 {
+		t.Errorf("expected the object to be reconciled after the window")
+	}
+}
+
+func TestWithStartupJitter_disabled(t *testing.T) {
+	inner := &countingReconciler{reconciled: map[reconcile.Request]int{}}
+	if r := WithStartupJitter(inner, 0); r != reconcile.Reconciler(inner) {
+		t.Errorf("expected a zero window to disable the jitter, got %T", r)
+	}
+}
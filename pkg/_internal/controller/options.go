@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"time"
+
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcilerOptions are the options shared by the reconcilers of the controllers, embedded in each reconciler
+type ReconcilerOptions struct {
+	// StartupJitterWindow spreads the first reconcile of each object over the window after the controller starts,
+	// the objects are reconciled right away when zero
+	StartupJitterWindow time.Duration
+	// ResyncPeriod is the longest an object waits to be reconciled again after a successful reconcile, the objects
+	// are only requeued when the reconciler needs it when zero
+	ResyncPeriod time.Duration
+	// MaxConcurrentReconciles is the number of objects reconciled at once, the objects are reconciled one at a time
+	// when zero
+	MaxConcurrentReconciles int
+}
+
+// WrapReconciler returns the reconciler with the startup jitter and the resync period of the options applied
+func (o ReconcilerOptions) WrapReconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return WithStartupJitter(WithResyncPeriod(r, o.ResyncPeriod), o.StartupJitterWindow)
+}
+
+// ControllerOptions returns the options of the controller of the reconciler
+func (o ReconcilerOptions) ControllerOptions() ctrlcontroller.Options {
+	return ctrlcontroller.Options{MaxConcurrentReconciles: o.MaxConcurrentReconciles}
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// resyncPeriod requeues every successfully reconciled object within the period
+type resyncPeriod struct {
+	reconcile.Reconciler
+
+	period time.Duration
+}
+
+// WithResyncPeriod returns a reconciler requeueing each object it reconciles without error after the period, or
+// sooner if the reconciler requeues it sooner, so that changes made outside of the watched resources, e.g. in the
+// DNS providers, are noticed within the period. Failed reconciles keep the backoff of the controller. A zero period
+// leaves the requeues to the reconciler, the objects are then only resynced with the cache of the manager.
+func WithResyncPeriod(r reconcile.Reconciler, period time.Duration) reconcile.Reconciler {
+	if period <= 0 {
+		return r
+	}
+	return &resyncPeriod{Reconciler: r, period: period}
+}
+
+func (p *resyncPeriod) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	result, err := p.Reconciler.Reconcile(ctx, req)
+	if err != nil || (result.Requeue && result.RequeueAfter == 0) {
+		return result, err
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > p.period {
+		result.RequeueAfter = p.period
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+//go:build unit
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type resultReconciler struct {
+	result     reconcile.Result
+	err        error
+	reconciles int
+}
+
+func (r *resultReconciler) Reconcile(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	r.reconciles++
+	return r.result, r.err
+}
+
+func TestWithResyncPeriod(t *testing.T) {
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: "policy"}}
+
+	// reconciles returns the number of reconciles of the object over an hour, each reconcile being followed by the
+	// next at the requeue of its result
+	reconciles := func(period time.Duration) int {
+		t.Helper()
+		inner := &resultReconciler{}
+		r := WithResyncPeriod(inner, period)
+		for elapsed := time.Duration(0); elapsed < time.Hour; {
+			result, err := r.Reconcile(context.TODO(), req)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if result.RequeueAfter == 0 {
+				break
+			}
+			elapsed += result.RequeueAfter
+		}
+		return inner.reconciles
+	}
+	if got := reconciles(0); got != 1 {
+		t.Errorf("expected the object not to be requeued without a resync period, got %d reconciles", got)
+	}
+	if got := reconciles(10 * time.Minute); got != 6 {
+		t.Errorf("expected 6 reconciles in an hour with a resync period of 10m, got %d", got)
+	}
+	if got := reconciles(time.Minute); got != 60 {
+		t.Errorf("expected 60 reconciles in an hour with a resync period of 1m, got %d", got)
+	}
+
+	testCases := []struct {
+		name   string
+		result reconcile.Result
+		err    error
+		want   reconcile.Result
+	}{
+		{
+			name:   "sooner requeue is kept",
+			result: reconcile.Result{RequeueAfter: 30 * time.Second},
+			want:   reconcile.Result{RequeueAfter: 30 * time.Second},
+		},
+		{
+			name:   "later requeue is shortened",
+			result: reconcile.Result{RequeueAfter: time.Hour},
+			want:   reconcile.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:   "immediate requeue is kept",
+			result: reconcile.Result{Requeue: true},
+			want:   reconcile.Result{Requeue: true},
+		},
+		{
+			name: "failed reconcile keeps the backoff",
+			err:  errors.New("failed"),
+			want: reconcile.Result{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := WithResyncPeriod(&resultReconciler{result: testCase.result, err: testCase.err}, time.Minute)
+			got, err := r.Reconcile(context.TODO(), req)
+			if !errors.Is(err, testCase.err) {
+				t.Errorf("expected error %v, got %v", testCase.err, err)
+			}
+			if got != testCase.want {
+				t.Errorf("expected %+v, got %+v", testCase.want, got)
+			}
+		})
+	}
+}
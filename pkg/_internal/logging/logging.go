@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+)
+
+// Keys of the log fields shared by the reconcilers, so that the lines about the same objects can be correlated across
+// controllers
+const (
+	// KeyTraceID is the ID generated for each reconcile, set on every line logged by the reconcile
+	KeyTraceID     = "traceID"
+	KeyPolicy      = "policy"
+	KeyPolicyKind  = "policyKind"
+	KeyGateway     = "gateway"
+	KeyHTTPRoute   = "httpRoute"
+	KeyManagedZone = "managedZone"
+	KeyDNSRecord   = "dnsRecord"
+)
+
+// IntoReconcile returns a context holding the logger with a new trace ID and the given fields, so that every line
+// logged during the reconcile shares the trace ID, and the logger
+func IntoReconcile(ctx context.Context, logger logr.Logger, keysAndValues ...interface{}) (context.Context, logr.Logger) {
+	logger = logger.WithValues(append([]interface{}{KeyTraceID, string(uuid.NewUUID())}, keysAndValues...)...)
+	return crlog.IntoContext(ctx, logger), logger
+}
+
+// With returns a context holding the logger of the context with the given fields added, and the logger
+func With(ctx context.Context, keysAndValues ...interface{}) (context.Context, logr.Logger) {
+	logger := crlog.FromContext(ctx).WithValues(keysAndValues...)
+	return crlog.IntoContext(ctx, logger), logger
+}
+
+// PolicyTarget returns the field of the target of the policy, the gateway or HTTPRoute it targets
+func PolicyTarget(policy common.KuadrantPolicy) []interface{} {
+	targetRef := policy.GetTargetRef()
+	namespace := policy.GetNamespace()
+	if targetRef.Namespace != nil {
+		namespace = string(*targetRef.Namespace)
+	}
+	target := types.NamespacedName{Namespace: namespace, Name: string(targetRef.Name)}
+	if common.IsTargetRefHTTPRoute(targetRef) {
+		return []interface{}{KeyHTTPRoute, target}
+	}
+	return []interface{}{KeyGateway, target}
+}
@@ -1,11 +1,59 @@
 package metadata
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// PausedAnnotation pauses the reconciliation of the object it is set on when "true"
+const PausedAnnotation = "kuadrant.io/paused"
+
+// IsPaused returns true if the reconciliation of the object is paused by the PausedAnnotation
+func IsPaused(obj metav1.Object) bool {
+	return GetAnnotation(obj, PausedAnnotation) == "true"
+}
+
+// ForceSyncAnnotation forces a full reconcile of the object it is set on each time its value changes, e.g. to the
+// current timestamp
+const ForceSyncAnnotation = "kuadrant.io/force-sync"
+
+// ForceSyncRequested returns true if the ForceSyncAnnotation of the object is set to a value other than the one last
+// observed by its reconciler
+func ForceSyncRequested(obj metav1.Object, observed string) bool {
+	value := GetAnnotation(obj, ForceSyncAnnotation)
+	return value != "" && value != observed
+}
+
+// MaintenanceWindowAnnotation freezes the changes made to the DNS providers for the object it is set on, during an
+// interval of two RFC 3339 times separated by a slash, e.g. 2023-12-22T18:00:00Z/2024-01-02T08:00:00Z
+const MaintenanceWindowAnnotation = "kuadrant.io/maintenance-window"
+
+// GetMaintenanceWindow returns the start and end of the MaintenanceWindowAnnotation of the object, and false if the
+// annotation is not set
+func GetMaintenanceWindow(obj metav1.Object) (start, end time.Time, ok bool, err error) {
+	value := GetAnnotation(obj, MaintenanceWindowAnnotation)
+	if value == "" {
+		return start, end, false, nil
+	}
+	startValue, endValue, found := strings.Cut(value, "/")
+	if !found {
+		return start, end, true, fmt.Errorf("invalid %s annotation %q, it must be a start and an end time separated by a slash", MaintenanceWindowAnnotation, value)
+	}
+	if start, err = time.Parse(time.RFC3339, strings.TrimSpace(startValue)); err != nil {
+		return start, end, true, fmt.Errorf("invalid start of the %s annotation: %w", MaintenanceWindowAnnotation, err)
+	}
+	if end, err = time.Parse(time.RFC3339, strings.TrimSpace(endValue)); err != nil {
+		return start, end, true, fmt.Errorf("invalid end of the %s annotation: %w", MaintenanceWindowAnnotation, err)
+	}
+	if !end.After(start) {
+		return start, end, true, fmt.Errorf("invalid %s annotation %q, the end must be after the start", MaintenanceWindowAnnotation, value)
+	}
+	return start, end, true, nil
+}
+
 func GetAnnotationsByPrefix(obj metav1.Object, prefix string) map[string]string {
 	annotations := map[string]string{}
 
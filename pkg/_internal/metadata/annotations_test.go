@@ -4,6 +4,7 @@ package metadata
 
 import (
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -226,6 +227,71 @@ func Test_hasAnnotation(t *testing.T) {
 	}
 }
 
+func Test_getMaintenanceWindow(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		wantOK    bool
+		wantStart string
+		wantEnd   string
+		wantErr   bool
+	}{
+		{
+			name: "annotation not set",
+		},
+		{
+			name:      "start and end",
+			value:     "2023-12-22T18:00:00Z/2024-01-02T08:00:00+01:00",
+			wantOK:    true,
+			wantStart: "2023-12-22T18:00:00Z",
+			wantEnd:   "2024-01-02T07:00:00Z",
+		},
+		{
+			name:    "missing end",
+			value:   "2023-12-22T18:00:00Z",
+			wantOK:  true,
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			value:   "2023-12-22/2024-01-02",
+			wantOK:  true,
+			wantErr: true,
+		},
+		{
+			name:    "end before start",
+			value:   "2024-01-02T08:00:00Z/2023-12-22T18:00:00Z",
+			wantOK:  true,
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			obj := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-object"}}
+			if testCase.value != "" {
+				obj.Annotations = map[string]string{MaintenanceWindowAnnotation: testCase.value}
+			}
+			start, end, ok, err := GetMaintenanceWindow(obj)
+			if ok != testCase.wantOK {
+				t.Errorf("expected the window to be set %v, got %v", testCase.wantOK, ok)
+			}
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("expected error %v, got %v", testCase.wantErr, err)
+			}
+			if testCase.wantErr || !ok {
+				return
+			}
+			if got := start.UTC().Format(time.RFC3339); got != testCase.wantStart {
+				t.Errorf("expected start %s, got %s", testCase.wantStart, got)
+			}
+			if got := end.UTC().Format(time.RFC3339); got != testCase.wantEnd {
+				t.Errorf("expected end %s, got %s", testCase.wantEnd, got)
+			}
+		})
+	}
+}
+
 func assertAnnotations(expectedAnnotations map[string]string) func(obj metav1.Object, t *testing.T) {
 	return func(obj metav1.Object, t *testing.T) {
 		annotations := obj.GetAnnotations()
@@ -0,0 +1,116 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterTLSPolicySpec defines the desired state of ClusterTLSPolicy
+type ClusterTLSPolicySpec struct {
+	// NamespaceSelector selects the namespaces of the Gateways the policy applies to. Every namespace is selected
+	// when empty.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// GatewaySelector selects the Gateways, in the selected namespaces, the policy applies to. Every Gateway is
+	// selected when empty.
+	// +optional
+	GatewaySelector *metav1.LabelSelector `json:"gatewaySelector,omitempty"`
+
+	// CertificateSpec is the certificate config of the TLSPolicy created for each selected Gateway. An Issuer in
+	// issuerRef or issuerRefs has to be in the namespace of each Gateway, a ClusterIssuer is usually referenced
+	// instead.
+	CertificateSpec `json:",inline"`
+}
+
+// ClusterTLSPolicyStatus defines the observed state of ClusterTLSPolicy
+type ClusterTLSPolicyStatus struct {
+	// conditions are any conditions associated with the policy
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// observedGeneration is the most recently observed generation of the ClusterTLSPolicy
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Gateways are the namespace/name of the Gateways a TLSPolicy is created for
+	// +optional
+	Gateways []string `json:"gateways,omitempty"`
+
+	// Overridden are the selected Gateways the policy yields to another policy for
+	// +optional
+	Overridden []ClusterTLSPolicyOverride `json:"overridden,omitempty"`
+}
+
+// ClusterTLSPolicyOverride is a selected Gateway the policy yields to another policy for
+type ClusterTLSPolicyOverride struct {
+	// Gateway is the namespace/name of the Gateway
+	Gateway string `json:"gateway"`
+
+	// Policy is the kind and name of the policy applying to the Gateway instead, a TLSPolicy in the namespace of
+	// the Gateway or a ClusterTLSPolicy with a lower name
+	Policy string `json:"policy"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="ClusterTLSPolicy ready."
+
+// ClusterTLSPolicy is the Schema for the clustertlspolicies API. It applies its certificate config to the Gateways it
+// selects across namespaces, by creating a TLSPolicy targeting each of them, unless a TLSPolicy already targets the
+// Gateway in its namespace.
+type ClusterTLSPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterTLSPolicySpec   `json:"spec,omitempty"`
+	Status ClusterTLSPolicyStatus `json:"status,omitempty"`
+}
+
+// Validate ensures the selectors and the certificate config of the policy are valid
+func (p *ClusterTLSPolicy) Validate() error {
+	if p.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(p.Spec.NamespaceSelector); err != nil {
+			return fmt.Errorf("invalid value for spec.namespaceSelector, %w", err)
+		}
+	}
+
+	if p.Spec.GatewaySelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(p.Spec.GatewaySelector); err != nil {
+			return fmt.Errorf("invalid value for spec.gatewaySelector, %w", err)
+		}
+	}
+
+	return p.Spec.CertificateSpec.Validate()
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterTLSPolicyList contains a list of ClusterTLSPolicy
+type ClusterTLSPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterTLSPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterTLSPolicy{}, &ClusterTLSPolicyList{})
+}
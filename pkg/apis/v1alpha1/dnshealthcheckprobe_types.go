@@ -29,9 +29,15 @@ type DNSHealthCheckProbeSpec struct {
 	Protocol                 HealthProtocol        `json:"protocol,omitempty"`
 	Interval                 metav1.Duration       `json:"interval,omitempty"`
 	AdditionalHeadersRef     *AdditionalHeadersRef `json:"additionalHeadersRef,omitempty"`
+	AdditionalHeaders        AdditionalHeaders     `json:"additionalHeaders,omitempty"`
 	FailureThreshold         *int                  `json:"failureThreshold,omitempty"`
+	SuccessThreshold         *int                  `json:"successThreshold,omitempty"`
 	ExpectedResponses        []int                 `json:"expectedResponses,omitempty"`
 	AllowInsecureCertificate bool                  `json:"allowInsecureCertificate,omitempty"`
+	// TLSServerName is the server name sent in the TLS handshake of HTTPS probes, the host by default
+	TLSServerName string `json:"tlsServerName,omitempty"`
+	// ExpectedBody is a regular expression the body of the response must match for the probe to succeed
+	ExpectedBody string `json:"expectedBody,omitempty"`
 }
 
 type AdditionalHeadersRef struct {
@@ -47,13 +53,30 @@ type AdditionalHeader struct {
 
 // DNSHealthCheckProbeStatus defines the observed state of DNSHealthCheckProbe
 type DNSHealthCheckProbeStatus struct {
-	LastCheckedAt       metav1.Time `json:"lastCheckedAt"`
-	ConsecutiveFailures int         `json:"consecutiveFailures,omitempty"`
-	Reason              string      `json:"reason,omitempty"`
-	Status              int         `json:"status,omitempty"`
-	Healthy             *bool       `json:"healthy"`
+	LastCheckedAt        metav1.Time `json:"lastCheckedAt"`
+	ConsecutiveFailures  int         `json:"consecutiveFailures,omitempty"`
+	ConsecutiveSuccesses int         `json:"consecutiveSuccesses,omitempty"`
+	Reason               string      `json:"reason,omitempty"`
+	Status               int         `json:"status,omitempty"`
+	Healthy              *bool       `json:"healthy"`
+	// FailureType is the kind of failure of the last health check, empty when it succeeded
+	FailureType ProbeFailureType `json:"failureType,omitempty"`
 }
 
+// ProbeFailureType is the kind of failure of a health check
+type ProbeFailureType string
+
+const (
+	// ProbeFailureTLSHandshake is a failure to establish a TLS connection, e.g. an invalid certificate
+	ProbeFailureTLSHandshake ProbeFailureType = "TLSHandshake"
+	// ProbeFailureHTTPStatus is a response with an unexpected HTTP status code
+	ProbeFailureHTTPStatus ProbeFailureType = "HTTPStatus"
+	// ProbeFailureResponseBody is a response with a body not matching the expected body
+	ProbeFailureResponseBody ProbeFailureType = "ResponseBody"
+	// ProbeFailureRequest is any other failure to get a response, e.g. a connection refused
+	ProbeFailureRequest ProbeFailureType = "Request"
+)
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Healthy",type="boolean",JSONPath=".status.healthy",description="DNSHealthCheckProbe healthy."
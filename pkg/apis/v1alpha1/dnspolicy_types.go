@@ -17,10 +17,16 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"bytes"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
@@ -37,6 +43,104 @@ type DNSPolicySpec struct {
 
 	// +optional
 	LoadBalancing *LoadBalancingSpec `json:"loadBalancing"`
+
+	// excludeClusters is a label selector matching the target clusters to leave out of the DNS records, e.g. while a
+	// cluster is under maintenance. The gateway stays placed on the excluded clusters.
+	// +optional
+	ExcludeClusters *metav1.LabelSelector `json:"excludeClusters,omitempty"`
+
+	// hostOverrides publish the DNS records of listeners under another hostname than the listener hostname, e.g. a
+	// public domain for a gateway listening on an internal name. The certificates of the listeners still match the
+	// listener hostnames.
+	// +optional
+	HostOverrides []HostOverride `json:"hostOverrides,omitempty"`
+
+	// dnsDrainDuration is how long the weighted records of a cluster no longer targeted by a gateway are kept with a
+	// weight of zero before they are removed, so that resolvers answer with the other clusters and clients move away
+	// from the cluster before its records disappear. The records of the last cluster of a gateway are removed right
+	// away, as there is no other cluster to drain to. It requires weighted load balancing and cannot be set with
+	// latency or geoproximity load balancing or delegateToCNAME. The endpoints draining are reported in the
+	// drainingEndpoints of the policy status.
+	// +optional
+	DNSDrainDuration *metav1.Duration `json:"dnsDrainDuration,omitempty"`
+
+	// staticRecords are published as declared alongside the load balanced records of the gateway listeners, e.g. SRV
+	// records for the discovery of the services behind the gateway. Each record must be in a ManagedZone.
+	// +optional
+	StaticRecords []StaticRecord `json:"staticRecords,omitempty"`
+
+	// delegateToCNAME publishes the host of each listener as a single CNAME record pointing at the target, e.g. an
+	// external GSLB balancing the traffic across the clusters, instead of the load balanced records. The loadBalancing
+	// configuration is ignored.
+	// +optional
+	DelegateToCNAME *DelegateToCNAME `json:"delegateToCNAME,omitempty"`
+
+	// recordNameTemplate is a Go template for an additional name published for each cluster of the gateway
+	// listeners, e.g. "{{ .Cluster }}.{{ .Host }}" for per-cluster debug names. The .Cluster, .Gateway and
+	// .Listener names, and the listener .Host, with a "*" replaced by "wildcard", are available. The name points at
+	// the gateway of that cluster only, the load balanced records of the listener host are still published. The
+	// name must be in the ManagedZone of the listener host. Ignored with delegateToCNAME.
+	// +optional
+	RecordNameTemplate string `json:"recordNameTemplate,omitempty"`
+}
+
+// DelegateToCNAME is the target the listener hosts are published as a CNAME of
+type DelegateToCNAME struct {
+	// target is the hostname the CNAME records point at, e.g. gslb.example.net
+	// +required
+	Target string `json:"target"`
+}
+
+// StaticRecord is a DNS record published by the policy as declared
+type StaticRecord struct {
+	// dnsName is the name of the record, e.g. _sip._tcp.example.com for an SRV record
+	// +required
+	DNSName string `json:"dnsName"`
+
+	// recordType is the type of the record
+	// +kubebuilder:validation:Enum=A;AAAA;CNAME;SRV;MX
+	// +required
+	RecordType DNSRecordType `json:"recordType"`
+
+	// recordTTL is the TTL of the record in seconds, the provider default is used when unset
+	// +optional
+	RecordTTL TTL `json:"recordTTL,omitempty"`
+
+	// targets of the record, for any type but SRV and MX
+	// +optional
+	Targets []string `json:"targets,omitempty"`
+
+	// srvTargets are the targets of an SRV record
+	// +optional
+	SRVTargets []SRVTarget `json:"srvTargets,omitempty"`
+
+	// mxTargets are the targets of an MX record
+	// +optional
+	MXTargets []MXTarget `json:"mxTargets,omitempty"`
+}
+
+// Endpoint returns the DNSRecord endpoint of the static record
+func (r StaticRecord) Endpoint() *Endpoint {
+	return &Endpoint{
+		DNSName:    r.DNSName,
+		RecordType: string(r.RecordType),
+		RecordTTL:  r.RecordTTL,
+		Targets:    r.Targets,
+		SRVTargets: r.SRVTargets,
+		MXTargets:  r.MXTargets,
+	}
+}
+
+// HostOverride maps a listener hostname to the hostname published in DNS
+type HostOverride struct {
+	// listenerHostname is the hostname of the gateway listeners to publish under another hostname
+	// +required
+	ListenerHostname gatewayv1beta1.Hostname `json:"listenerHostname"`
+
+	// hostname is published in DNS for the listeners. It must be in a ManagedZone, and be a wildcard if, and only if,
+	// the listener hostname is a wildcard.
+	// +required
+	Hostname gatewayv1beta1.Hostname `json:"hostname"`
 }
 
 type LoadBalancingSpec struct {
@@ -44,6 +148,10 @@ type LoadBalancingSpec struct {
 	Weighted *LoadBalancingWeighted `json:"weighted,omitempty"`
 	// +optional
 	Geo *LoadBalancingGeo `json:"geo,omitempty"`
+	// +optional
+	Latency *LoadBalancingLatency `json:"latency,omitempty"`
+	// +optional
+	Geoproximity *LoadBalancingGeoproximity `json:"geoproximity,omitempty"`
 }
 
 // +kubebuilder:validation:Minimum=0
@@ -67,6 +175,34 @@ type LoadBalancingWeighted struct {
 	DefaultWeight Weight `json:"defaultWeight,omitempty"`
 	// +optional
 	Custom []*CustomWeight `json:"custom,omitempty"`
+
+	// rampDuration, when set, ramps the weight of a cluster joining the target clusters linearly from 0 to its
+	// weight over the duration, e.g. for canary rollouts. The clusters targeted when the ramp is first set, and a
+	// cluster joining while no other cluster is targeted, get their weight right away. The weight published for
+	// each cluster is reported in the clusterWeights of the policy status.
+	// +optional
+	RampDuration *metav1.Duration `json:"rampDuration,omitempty"`
+
+	// listeners override the defaultWeight and custom weights for the records of the named gateway listeners, e.g. to
+	// split the traffic of two listeners differently across the target clusters. The other listeners use the weights
+	// above. Listener weights cannot be ramped, rampDuration must not be set with them.
+	// +optional
+	Listeners []ListenerWeights `json:"listeners,omitempty"`
+}
+
+// ListenerWeights are the weights of the records of a gateway listener
+type ListenerWeights struct {
+	// name of the gateway listener
+	// +required
+	Name gatewayv1beta1.SectionName `json:"name"`
+
+	// defaultWeight is the record weight of the listener for the target clusters not matching a custom weight
+	// +required
+	DefaultWeight Weight `json:"defaultWeight"`
+
+	// custom weights of the listener records, the custom weights of the policy do not apply to the listener
+	// +optional
+	Custom []*CustomWeight `json:"custom,omitempty"`
 }
 
 type LoadBalancingGeo struct {
@@ -77,6 +213,74 @@ type LoadBalancingGeo struct {
 	// Route53: https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/resource-record-sets-values-geo.html
 	// +required
 	DefaultGeo string `json:"defaultGeo,omitempty"`
+
+	// regionGeoCodes map the region of a target cluster, read from its topology.kubernetes.io/region label, to a geo
+	// code. A geo code set on the cluster with the kuadrant.io/lb-attribute-geo-code annotation or label takes
+	// precedence, and clusters in a region missing from the mapping are assigned the defaultGeo.
+	// +optional
+	RegionGeoCodes []RegionGeoCode `json:"regionGeoCodes,omitempty"`
+
+	// geoFallback maps a geo code to the ordered geo codes its traffic falls back to while the health checks of its
+	// clusters all fail, e.g. {"IE": ["GB", "DE"]}. The records of the geo then point at the first fallback geo with a
+	// healthy cluster. The traffic of the geo goes to the defaultGeo when no fallback geo has a healthy cluster.
+	// +optional
+	GeoFallback map[string][]string `json:"geoFallback,omitempty"`
+}
+
+// RegionGeoCode maps a cluster region to a geo code
+type RegionGeoCode struct {
+	// region is the value of the topology.kubernetes.io/region label of the clusters, e.g. eu-west-1
+	// +required
+	Region string `json:"region"`
+
+	// geoCode is the geo code of the clusters in the region, e.g. IE
+	// +required
+	GeoCode string `json:"geoCode"`
+}
+
+// LoadBalancingLatency routes traffic to the cluster with the lowest latency for the client.
+//
+// The region of each target cluster is read from the kuadrant.io/lb-attribute-region annotation. If any target cluster
+// has no region set, weighted routing is used instead.
+//
+// Route53: https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/routing-policy-latency.html
+type LoadBalancingLatency struct{}
+
+// LoadBalancingGeoproximity routes traffic to the cluster closest to the client, where the area served by each cluster
+// is grown or shrunk by its bias.
+//
+// The location of each target cluster is read from its kuadrant.io/lb-attribute-coordinates annotation, a
+// "latitude,longitude" pair such as "53.35,-6.26", or else from its kuadrant.io/lb-attribute-region annotation. If any
+// target cluster has no location set, weighted routing is used instead.
+//
+// Route53: https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/routing-policy-geoproximity.html
+type LoadBalancingGeoproximity struct {
+	// defaultBias is the bias of the target clusters not matching a custom bias, 0 leaves their area unchanged.
+	// +optional
+	DefaultBias Bias `json:"defaultBias,omitempty"`
+
+	// custom biases of the target clusters, the first custom bias whose selector matches the labels of a cluster
+	// applies
+	// +optional
+	Custom []*CustomBias `json:"custom,omitempty"`
+}
+
+// Bias grows, when positive, or shrinks, when negative, the area from which traffic is routed to a cluster
+// +kubebuilder:validation:Minimum=-99
+// +kubebuilder:validation:Maximum=99
+type Bias int32
+
+const (
+	MinimumBias Bias = -99
+	MaximumBias Bias = 99
+)
+
+type CustomBias struct {
+	// Label selector used by MGC to match the target clusters the bias applies to
+	// +required
+	Selector *metav1.LabelSelector `json:"selector"`
+	// +required
+	Bias Bias `json:"bias"`
 }
 
 // DNSPolicyStatus defines the observed state of DNSPolicy
@@ -96,6 +300,98 @@ type DNSPolicyStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	HealthCheck *HealthCheckStatus `json:"healthCheck,omitempty"`
+
+	// managedZones are the managed zones selected for the listeners of the target gateways
+	// +optional
+	ManagedZones []ListenerManagedZone `json:"managedZones,omitempty"`
+
+	// clusterGeos are the geo codes resolved for the target clusters when geo load balancing is used
+	// +optional
+	ClusterGeos []ClusterGeo `json:"clusterGeos,omitempty"`
+
+	// observedForceSync is the value of the kuadrant.io/force-sync annotation last reconciled
+	// +optional
+	ObservedForceSync string `json:"observedForceSync,omitempty"`
+
+	// clusterWeights are the weights published for the target clusters when the weighted load balancing has a
+	// rampDuration
+	// +optional
+	ClusterWeights []ClusterWeight `json:"clusterWeights,omitempty"`
+
+	// drainingEndpoints are the weighted endpoints of the clusters no longer targeted that are kept with a weight of
+	// zero while they drain, when the policy has a dnsDrainDuration
+	// +optional
+	DrainingEndpoints []DrainingEndpoint `json:"drainingEndpoints,omitempty"`
+}
+
+// DrainingEndpoint is a weighted endpoint kept with a weight of zero while it drains
+type DrainingEndpoint struct {
+	// dnsName is the name of the record set of the endpoint
+	DNSName string `json:"dnsName"`
+
+	// setIdentifier identifies the endpoint in its record set
+	SetIdentifier string `json:"setIdentifier"`
+
+	// drainStartTime is when the endpoint started draining, it is removed once it has drained for the dnsDrainDuration
+	DrainStartTime metav1.Time `json:"drainStartTime"`
+}
+
+// ClusterWeight is the weight published for a target cluster
+type ClusterWeight struct {
+	// cluster is the name of the target cluster
+	Cluster string `json:"cluster"`
+
+	// weight is the weight published for the cluster, interpolated while its weight ramps up
+	Weight int `json:"weight"`
+
+	// targetWeight is the weight of the cluster once its weight has ramped up
+	TargetWeight int `json:"targetWeight"`
+
+	// rampStartTime is when the cluster joined and its weight started ramping up, unset once it has ramped up
+	// +optional
+	RampStartTime *metav1.Time `json:"rampStartTime,omitempty"`
+}
+
+// GeoCodeSource is where the geo code of a cluster is resolved from
+type GeoCodeSource string
+
+const (
+	// GeoCodeSourceAnnotation is the kuadrant.io/lb-attribute-geo-code annotation of the cluster
+	GeoCodeSourceAnnotation GeoCodeSource = "Annotation"
+	// GeoCodeSourceLabel is the kuadrant.io/lb-attribute-geo-code label of the cluster
+	GeoCodeSourceLabel GeoCodeSource = "Label"
+	// GeoCodeSourceRegion is the regionGeoCodes mapping of the topology.kubernetes.io/region label of the cluster
+	GeoCodeSourceRegion GeoCodeSource = "Region"
+	// GeoCodeSourceDefault is the defaultGeo of the policy
+	GeoCodeSourceDefault GeoCodeSource = "Default"
+)
+
+// ClusterGeo is the geo code resolved for a target cluster
+type ClusterGeo struct {
+	// cluster is the name of the target cluster
+	Cluster string `json:"cluster"`
+
+	// geoCode is the geo code the cluster is published under
+	GeoCode string `json:"geoCode"`
+
+	// source is where the geo code is resolved from, one of Annotation, Label, Region or Default
+	Source GeoCodeSource `json:"source"`
+}
+
+// ListenerManagedZone is the managed zone the DNS records of a gateway listener are published in
+type ListenerManagedZone struct {
+	// gateway is the name of the target gateway
+	Gateway string `json:"gateway"`
+
+	// listener is the name of the gateway listener
+	Listener string `json:"listener"`
+
+	// managedZone is the name of the managed zone selected for the listener, the zone with the longest domain
+	// matching the listener host
+	ManagedZone string `json:"managedZone"`
+
+	// domain is the domain name of the managed zone
+	Domain string `json:"domain"`
 }
 
 //+kubebuilder:object:root=true
@@ -139,6 +435,95 @@ func (p *DNSPolicy) Validate() error {
 		return fmt.Errorf("invalid targetRef.Namespace %s. Currently only supporting references to the same namespace", *p.Spec.TargetRef.Namespace)
 	}
 
+	if p.Spec.ExcludeClusters != nil {
+		if _, err := metav1.LabelSelectorAsSelector(p.Spec.ExcludeClusters); err != nil {
+			return fmt.Errorf("invalid excludeClusters selector: %w", err)
+		}
+	}
+
+	if p.Spec.DNSDrainDuration != nil && p.Spec.DNSDrainDuration.Duration < 0 {
+		return fmt.Errorf("invalid dnsDrainDuration %s, must not be negative", p.Spec.DNSDrainDuration.Duration)
+	}
+
+	// the drain zeroes the weights of the records of a cluster, the other routing policies have no weights to zero
+	if p.Spec.DNSDrainDuration != nil && p.Spec.DNSDrainDuration.Duration > 0 {
+		if p.Spec.DelegateToCNAME != nil {
+			return fmt.Errorf("invalid dnsDrainDuration, cannot be set with delegateToCNAME")
+		}
+		if lb := p.Spec.LoadBalancing; lb != nil && (lb.Latency != nil || lb.Geoproximity != nil) {
+			return fmt.Errorf("invalid dnsDrainDuration, requires weighted load balancing and cannot be set with latency or geoproximity load balancing")
+		}
+	}
+
+	if lb := p.Spec.LoadBalancing; lb != nil && lb.Weighted != nil && lb.Weighted.RampDuration != nil && lb.Weighted.RampDuration.Duration < 0 {
+		return fmt.Errorf("invalid loadBalancing.weighted.rampDuration %s, must not be negative", lb.Weighted.RampDuration.Duration)
+	}
+
+	if lb := p.Spec.LoadBalancing; lb != nil && lb.Weighted != nil {
+		if err := lb.Weighted.validateListeners(); err != nil {
+			return err
+		}
+	}
+
+	staticRecords := map[string]struct{}{}
+	for _, record := range p.Spec.StaticRecords {
+		key := strings.ToLower(record.DNSName) + "/" + string(record.RecordType)
+		if _, ok := staticRecords[key]; ok {
+			return fmt.Errorf("invalid staticRecords, %s record %s is declared more than once", record.RecordType, record.DNSName)
+		}
+		staticRecords[key] = struct{}{}
+		endpoint := record.Endpoint()
+		if len(endpoint.RecordValues()) == 0 {
+			return fmt.Errorf("invalid staticRecords, %s record %s has no targets", record.RecordType, record.DNSName)
+		}
+		if err := endpoint.Validate(); err != nil {
+			return fmt.Errorf("invalid staticRecords: %w", err)
+		}
+	}
+
+	listenerHostnames := map[gatewayv1beta1.Hostname]struct{}{}
+	for _, override := range p.Spec.HostOverrides {
+		if _, ok := listenerHostnames[override.ListenerHostname]; ok {
+			return fmt.Errorf("invalid hostOverrides, listener hostname %s is overridden more than once", override.ListenerHostname)
+		}
+		listenerHostnames[override.ListenerHostname] = struct{}{}
+		if strings.HasPrefix(string(override.ListenerHostname), "*") != strings.HasPrefix(string(override.Hostname), "*") {
+			return fmt.Errorf("invalid hostOverrides, hostname %s must be a wildcard if, and only if, listener hostname %s is a wildcard", override.Hostname, override.ListenerHostname)
+		}
+	}
+
+	if p.Spec.LoadBalancing != nil && p.Spec.LoadBalancing.Geo != nil {
+		regions := map[string]struct{}{}
+		for _, regionGeoCode := range p.Spec.LoadBalancing.Geo.RegionGeoCodes {
+			if _, ok := regions[regionGeoCode.Region]; ok {
+				return fmt.Errorf("invalid loadBalancing.geo.regionGeoCodes, region %s is mapped more than once", regionGeoCode.Region)
+			}
+			regions[regionGeoCode.Region] = struct{}{}
+			if regionGeoCode.GeoCode == "" {
+				return fmt.Errorf("invalid loadBalancing.geo.regionGeoCodes, region %s has no geo code", regionGeoCode.Region)
+			}
+		}
+		if err := validateGeoFallback(p.Spec.LoadBalancing.Geo.GeoFallback); err != nil {
+			return err
+		}
+	}
+
+	if p.Spec.LoadBalancing != nil && p.Spec.LoadBalancing.Geoproximity != nil {
+		if err := p.Spec.LoadBalancing.Geoproximity.validate(); err != nil {
+			return err
+		}
+	}
+
+	if p.Spec.DelegateToCNAME != nil {
+		if err := validateTargetHost(p.Spec.DelegateToCNAME.Target); err != nil {
+			return fmt.Errorf("invalid delegateToCNAME: %w", err)
+		}
+	}
+
+	if err := p.validateRecordNameTemplate(); err != nil {
+		return err
+	}
+
 	if p.Spec.HealthCheck != nil {
 		return p.Spec.HealthCheck.Validate()
 	}
@@ -146,9 +531,170 @@ func (p *DNSPolicy) Validate() error {
 	return nil
 }
 
+// validateRecordNameTemplate ensures the record name template renders valid, and distinct, names for two clusters
+func (p *DNSPolicy) validateRecordNameTemplate() error {
+	if p.Spec.RecordNameTemplate == "" {
+		return nil
+	}
+	first, err := p.RenderRecordName("cluster-a", "gateway", "listener", "api.example.com")
+	if err != nil {
+		return fmt.Errorf("invalid value for spec.recordNameTemplate, %w", err)
+	}
+	second, err := p.RenderRecordName("cluster-b", "gateway", "listener", "api.example.com")
+	if err != nil {
+		return fmt.Errorf("invalid value for spec.recordNameTemplate, %w", err)
+	}
+	if first == second {
+		return fmt.Errorf("invalid value for spec.recordNameTemplate, the name must differ for each cluster, e.g. by using {{ .Cluster }}")
+	}
+	if first == "api.example.com" {
+		return fmt.Errorf("invalid value for spec.recordNameTemplate, the name must differ from the listener host")
+	}
+	return nil
+}
+
+// RenderRecordName returns the additional name published for the cluster from the record name template of the
+// policy, empty if the policy has no template
+func (p *DNSPolicy) RenderRecordName(cluster, gateway, listener, host string) (string, error) {
+	if p.Spec.RecordNameTemplate == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("recordName").Option("missingkey=error").Parse(p.Spec.RecordNameTemplate)
+	if err != nil {
+		return "", err
+	}
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, map[string]string{
+		"Cluster":  cluster,
+		"Gateway":  gateway,
+		"Listener": listener,
+		"Host":     strings.ReplaceAll(host, "*", "wildcard"),
+	}); err != nil {
+		return "", err
+	}
+	recordName := strings.ToLower(name.String())
+	if errs := validation.IsDNS1123Subdomain(recordName); len(errs) > 0 {
+		return "", fmt.Errorf("invalid record name %q for cluster %s: %s", recordName, cluster, strings.Join(errs, ", "))
+	}
+	return recordName, nil
+}
+
+// validateGeoFallback ensures each geo falls back to an ordered list of other geos, each listed once
+func validateGeoFallback(geoFallback map[string][]string) error {
+	geoCodes := make([]string, 0, len(geoFallback))
+	for geoCode := range geoFallback {
+		geoCodes = append(geoCodes, geoCode)
+	}
+	sort.Strings(geoCodes)
+	for _, geoCode := range geoCodes {
+		if geoCode == "" {
+			return fmt.Errorf("invalid loadBalancing.geo.geoFallback, a fallback is set for an empty geo code")
+		}
+		if len(geoFallback[geoCode]) == 0 {
+			return fmt.Errorf("invalid loadBalancing.geo.geoFallback, geo %s has no fallback geo codes", geoCode)
+		}
+		fallbacks := map[string]struct{}{}
+		for _, fallback := range geoFallback[geoCode] {
+			if fallback == "" || fallback == geoCode {
+				return fmt.Errorf("invalid loadBalancing.geo.geoFallback, geo %s cannot fall back to %q", geoCode, fallback)
+			}
+			if _, ok := fallbacks[fallback]; ok {
+				return fmt.Errorf("invalid loadBalancing.geo.geoFallback, geo %s falls back to %s more than once", geoCode, fallback)
+			}
+			fallbacks[fallback] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// validate ensures the biases are in the range accepted by the DNS providers and the custom bias selectors are valid
+func (g *LoadBalancingGeoproximity) validate() error {
+	if err := g.DefaultBias.validate(); err != nil {
+		return fmt.Errorf("invalid loadBalancing.geoproximity.defaultBias, %w", err)
+	}
+	for i, custom := range g.Custom {
+		if custom == nil || custom.Selector == nil {
+			return fmt.Errorf("invalid loadBalancing.geoproximity.custom[%d], a selector is required", i)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(custom.Selector); err != nil {
+			return fmt.Errorf("invalid loadBalancing.geoproximity.custom[%d].selector: %w", i, err)
+		}
+		if err := custom.Bias.validate(); err != nil {
+			return fmt.Errorf("invalid loadBalancing.geoproximity.custom[%d].bias, %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (b Bias) validate() error {
+	if b < MinimumBias || b > MaximumBias {
+		return fmt.Errorf("bias %d must be between %d and %d", b, MinimumBias, MaximumBias)
+	}
+	return nil
+}
+
+// validateListeners ensures each listener is weighted once, with weights that are not all zero, and that the listener
+// weights are not ramped
+func (w *LoadBalancingWeighted) validateListeners() error {
+	if len(w.Listeners) > 0 && w.RampDuration != nil {
+		return fmt.Errorf("invalid loadBalancing.weighted.listeners, listener weights cannot be set with rampDuration")
+	}
+	listeners := map[gatewayv1beta1.SectionName]struct{}{}
+	for _, listener := range w.Listeners {
+		if _, ok := listeners[listener.Name]; ok {
+			return fmt.Errorf("invalid loadBalancing.weighted.listeners, listener %s is weighted more than once", listener.Name)
+		}
+		listeners[listener.Name] = struct{}{}
+		total := listener.DefaultWeight
+		for _, custom := range listener.Custom {
+			if custom.Selector == nil {
+				return fmt.Errorf("invalid loadBalancing.weighted.listeners, a custom weight of listener %s has no selector", listener.Name)
+			}
+			if _, err := metav1.LabelSelectorAsSelector(custom.Selector); err != nil {
+				return fmt.Errorf("invalid loadBalancing.weighted.listeners, custom weight selector of listener %s: %w", listener.Name, err)
+			}
+			total += custom.Weight
+		}
+		// the records of the listener would all have a weight of zero, leaving the split of its traffic to the provider
+		if total == 0 {
+			return fmt.Errorf("invalid loadBalancing.weighted.listeners, the weights of listener %s are all zero", listener.Name)
+		}
+	}
+	return nil
+}
+
+// ForListener returns the weights of the records of the named listener
+func (w *LoadBalancingWeighted) ForListener(name string) *LoadBalancingWeighted {
+	for _, listener := range w.Listeners {
+		if string(listener.Name) == name {
+			return &LoadBalancingWeighted{DefaultWeight: listener.DefaultWeight, Custom: listener.Custom}
+		}
+	}
+	return w
+}
+
+// GetHostOverride returns the hostname to publish in DNS for the listener hostname, and true if it is overridden
+func (p *DNSPolicy) GetHostOverride(listenerHostname string) (string, bool) {
+	for _, override := range p.Spec.HostOverrides {
+		if string(override.ListenerHostname) == listenerHostname {
+			return string(override.Hostname), true
+		}
+	}
+	return listenerHostname, false
+}
+
 // Default sets default values for the fields in the resource. Compatible with
 // the defaulting interface used by webhooks
 func (p *DNSPolicy) Default() {
+	// weighted routing is used when no load balancing is configured, make that explicit
+	if p.Spec.LoadBalancing == nil {
+		p.Spec.LoadBalancing = &LoadBalancingSpec{
+			Weighted: &LoadBalancingWeighted{
+				DefaultWeight: DefaultWeight,
+			},
+		}
+	}
+
 	if p.Spec.HealthCheck != nil {
 		p.Spec.HealthCheck.Default()
 	}
@@ -171,10 +717,39 @@ type HealthCheckSpec struct {
 	Port                      *int                  `json:"port,omitempty"`
 	Protocol                  *HealthProtocol       `json:"protocol,omitempty"`
 	FailureThreshold          *int                  `json:"failureThreshold,omitempty"`
+	SuccessThreshold          *int                  `json:"successThreshold,omitempty"`
 	AdditionalHeadersRef      *AdditionalHeadersRef `json:"additionalHeadersRef,omitempty"`
+	AdditionalHeaders         AdditionalHeaders     `json:"additionalHeaders,omitempty"`
 	ExpectedResponses         []int                 `json:"expectedResponses,omitempty"`
 	AllowInsecureCertificates bool                  `json:"allowInsecureCertificates,omitempty"`
 	Interval                  *metav1.Duration      `json:"interval,omitempty"`
+	// ExpectedBody is a regular expression the body of the response must match, in addition to its status code, for
+	// the health check to succeed, e.g. `"status": *"ok"`. Only the first 64KiB of the body are matched.
+	ExpectedBody string `json:"expectedBody,omitempty"`
+	// TLSServerName is the server name sent in the TLS handshake of HTTPS health checks, the listener hostname by
+	// default
+	TLSServerName string `json:"tlsServerName,omitempty"`
+	// ProviderHealthChecks creates health checks in the DNS provider for the weighted and latency record sets of
+	// the clusters, so that the provider itself stops answering with the clusters failing them. Only supported by
+	// Route53.
+	ProviderHealthChecks bool `json:"providerHealthChecks,omitempty"`
+}
+
+// maxProviderHealthCheckFailureThreshold is the highest failure threshold accepted by Route53 health checks
+const maxProviderHealthCheckFailureThreshold = 10
+
+// ProviderHealthCheck returns the health check of the DNS provider configured by the spec, nil when provider health
+// checks are not enabled
+func (s *HealthCheckSpec) ProviderHealthCheck() *ProviderHealthCheck {
+	if s == nil || !s.ProviderHealthChecks {
+		return nil
+	}
+	return &ProviderHealthCheck{
+		Endpoint:         s.Endpoint,
+		Port:             s.Port,
+		Protocol:         s.Protocol,
+		FailureThreshold: s.FailureThreshold,
+	}
 }
 
 func (s *HealthCheckSpec) Validate() error {
@@ -184,6 +759,22 @@ func (s *HealthCheckSpec) Validate() error {
 		}
 	}
 
+	if s.FailureThreshold != nil && *s.FailureThreshold < 1 {
+		return fmt.Errorf("invalid value for spec.healthCheckSpec.failureThreshold %v, it must be at least 1", *s.FailureThreshold)
+	}
+
+	if s.SuccessThreshold != nil && *s.SuccessThreshold < 1 {
+		return fmt.Errorf("invalid value for spec.healthCheckSpec.successThreshold %v, it must be at least 1", *s.SuccessThreshold)
+	}
+
+	if _, err := regexp.Compile(s.ExpectedBody); err != nil {
+		return fmt.Errorf("invalid value for spec.healthCheckSpec.expectedBody %q: %w", s.ExpectedBody, err)
+	}
+
+	if s.ProviderHealthChecks && s.FailureThreshold != nil && *s.FailureThreshold > maxProviderHealthCheckFailureThreshold {
+		return fmt.Errorf("invalid value for spec.healthCheckSpec.failureThreshold %v, it cannot be greater than %d with provider health checks", *s.FailureThreshold, maxProviderHealthCheckFailureThreshold)
+	}
+
 	return nil
 }
 
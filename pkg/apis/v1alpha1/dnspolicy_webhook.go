@@ -0,0 +1,205 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// GeoCodeValidator returns an error if the geo code is not recognized by the DNS provider of the managed zone
+// +kubebuilder:object:generate=false
+type GeoCodeValidator func(ctx context.Context, managedZone *ManagedZone, geoCode string) error
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of DNSPolicies. The geo codes of the
+// policies are only checked against the DNS providers when a geo code validator is given.
+func (p *DNSPolicy) SetupWebhookWithManager(mgr ctrl.Manager, validateGeoCode GeoCodeValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(p).
+		WithValidator(&dnsPolicyValidator{client: mgr.GetClient(), validateGeoCode: validateGeoCode}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-kuadrant-io-v1alpha1-dnspolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=kuadrant.io,resources=dnspolicies,verbs=create;update,versions=v1alpha1,name=mdnspolicy.kb.io,admissionReviewVersions=v1
+
+// DNSPolicy defaulting only fills in unset fields, so applying it more than once has no further effect.
+var _ webhook.Defaulter = &DNSPolicy{}
+
+//+kubebuilder:webhook:path=/validate-kuadrant-io-v1alpha1-dnspolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kuadrant.io,resources=dnspolicies,verbs=create;update,versions=v1alpha1,name=vdnspolicy.kb.io,admissionReviewVersions=v1
+
+// dnsPolicyValidator refuses geo load balancing configurations that would split the traffic of a geo code across
+// clusters unintentionally, or that the DNS providers of the hosts of the target gateway would refuse
+type dnsPolicyValidator struct {
+	client          client.Reader
+	validateGeoCode GeoCodeValidator
+}
+
+var _ admission.CustomValidator = &dnsPolicyValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *dnsPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	p, ok := obj.(*DNSPolicy)
+	if !ok {
+		return fmt.Errorf("%T is not a *v1alpha1.DNSPolicy", obj)
+	}
+	return v.validateGeo(ctx, p)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *dnsPolicyValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) error {
+	p, ok := newObj.(*DNSPolicy)
+	if !ok {
+		return fmt.Errorf("%T is not a *v1alpha1.DNSPolicy", newObj)
+	}
+	// the finalizer must always be removable from a policy being deleted
+	if p.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	return v.validateGeo(ctx, p)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *dnsPolicyValidator) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+func (v *dnsPolicyValidator) validateGeo(ctx context.Context, p *DNSPolicy) error {
+	if p.Spec.LoadBalancing == nil || p.Spec.LoadBalancing.Geo == nil {
+		return nil
+	}
+	if err := validateGeoMapping(p.Spec.LoadBalancing); err != nil {
+		return err
+	}
+	if v.validateGeoCode == nil {
+		return nil
+	}
+
+	managedZones, err := v.targetManagedZones(ctx, p)
+	if err != nil {
+		return err
+	}
+	for _, managedZone := range managedZones {
+		for _, geoCode := range geoCodes(p.Spec.LoadBalancing.Geo) {
+			if err := v.validateGeoCode(ctx, managedZone, geoCode); err != nil {
+				return fmt.Errorf("invalid loadBalancing.geo, geo code %s is not supported by managed zone %s: %w", geoCode, managedZone.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateGeoMapping refuses regions mapped to a geo code already used by other clusters, whose traffic would be
+// split evenly with them, unless the weighted load balancing is set to weight the clusters sharing the geo code
+func validateGeoMapping(lb *LoadBalancingSpec) error {
+	if lb.Weighted != nil {
+		return nil
+	}
+	regions := map[string][]string{}
+	for _, regionGeoCode := range lb.Geo.RegionGeoCodes {
+		regions[regionGeoCode.GeoCode] = append(regions[regionGeoCode.GeoCode], regionGeoCode.Region)
+	}
+	for _, regionGeoCode := range lb.Geo.RegionGeoCodes {
+		if regionGeoCode.GeoCode == lb.Geo.DefaultGeo {
+			return fmt.Errorf("invalid loadBalancing.geo.regionGeoCodes, region %s is mapped to the defaultGeo %s, set loadBalancing.weighted to weight the clusters sharing the geo code", regionGeoCode.Region, regionGeoCode.GeoCode)
+		}
+		if shared := regions[regionGeoCode.GeoCode]; len(shared) > 1 {
+			return fmt.Errorf("invalid loadBalancing.geo.regionGeoCodes, regions %s are all mapped to geo code %s, set loadBalancing.weighted to weight the clusters sharing the geo code", strings.Join(shared, ", "), regionGeoCode.GeoCode)
+		}
+	}
+	return nil
+}
+
+// geoCodes returns the geo codes set in the geo load balancing spec, including the geos of the fallbacks
+func geoCodes(geo *LoadBalancingGeo) []string {
+	codes := []string{geo.DefaultGeo}
+	for _, regionGeoCode := range geo.RegionGeoCodes {
+		codes = append(codes, regionGeoCode.GeoCode)
+	}
+	fallbackGeoCodes := make([]string, 0, len(geo.GeoFallback))
+	for geoCode := range geo.GeoFallback {
+		fallbackGeoCodes = append(fallbackGeoCodes, geoCode)
+	}
+	sort.Strings(fallbackGeoCodes)
+	for _, geoCode := range fallbackGeoCodes {
+		codes = append(codes, geoCode)
+		codes = append(codes, geo.GeoFallback[geoCode]...)
+	}
+	return codes
+}
+
+// targetManagedZones returns the managed zones the hosts of the listeners of the target gateway are published in,
+// sorted by name. None are returned while the gateway does not exist, its zones are checked when it is reconciled.
+func (v *dnsPolicyValidator) targetManagedZones(ctx context.Context, p *DNSPolicy) ([]*ManagedZone, error) {
+	gateway := &gatewayv1beta1.Gateway{}
+	if err := v.client.Get(ctx, client.ObjectKey{Namespace: p.Namespace, Name: string(p.Spec.TargetRef.Name)}, gateway); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	managedZoneList := &ManagedZoneList{}
+	if err := v.client.List(ctx, managedZoneList, client.InNamespace(p.Namespace)); err != nil {
+		return nil, err
+	}
+
+	selected := map[string]*ManagedZone{}
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Hostname == nil {
+			continue
+		}
+		host, _ := p.GetHostOverride(string(*listener.Hostname))
+		for _, managedZone := range managedZonesForHost(host, managedZoneList.Items) {
+			selected[managedZone.Name] = managedZone
+		}
+	}
+	managedZones := make([]*ManagedZone, 0, len(selected))
+	for _, managedZone := range selected {
+		managedZones = append(managedZones, managedZone)
+	}
+	sort.Slice(managedZones, func(i, j int) bool {
+		return managedZones[i].Name < managedZones[j].Name
+	})
+	return managedZones, nil
+}
+
+// managedZonesForHost returns the managed zones with the longest domain the host is a subdomain of
+func managedZonesForHost(host string, managedZones []ManagedZone) []*ManagedZone {
+	host = strings.ToLower(host)
+	var matches []*ManagedZone
+	longest := 0
+	for i := range managedZones {
+		domain := strings.ToLower(strings.TrimSuffix(managedZones[i].Spec.DomainName, "."))
+		if !strings.HasSuffix(host, "."+domain) || len(domain) < longest {
+			continue
+		}
+		if len(domain) > longest {
+			matches, longest = nil, len(domain)
+		}
+		matches = append(matches, &managedZones[i])
+	}
+	return matches
+}
@@ -18,8 +18,12 @@ package v1alpha1
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // SetID returns an id that should be unique across a set of endpoints
@@ -37,6 +41,7 @@ type ProviderSpecificProperty struct {
 type Targets []string
 
 // TTL is a structure defining the TTL of a DNS record
+// +kubebuilder:validation:Minimum=0
 type TTL int64
 
 // Labels store metadata related to the endpoint
@@ -56,7 +61,8 @@ type Endpoint struct {
 	RecordType string `json:"recordType,omitempty"`
 	// Identifier to distinguish multiple records with the same name and type (e.g. Route53 records with routing policies other than 'simple')
 	SetIdentifier string `json:"setIdentifier,omitempty"`
-	// TTL for the record
+	// TTL for the record in seconds. When unset the provider default is used
+	// +optional
 	RecordTTL TTL `json:"recordTTL,omitempty"`
 	// Labels stores labels defined for the Endpoint
 	// +optional
@@ -64,6 +70,57 @@ type Endpoint struct {
 	// ProviderSpecific stores provider specific config
 	// +optional
 	ProviderSpecific ProviderSpecific `json:"providerSpecific,omitempty"`
+	// SRVTargets are the targets of an SRV record, used instead of targets
+	// +optional
+	SRVTargets []SRVTarget `json:"srvTargets,omitempty"`
+	// MXTargets are the targets of an MX record, used instead of targets
+	// +optional
+	MXTargets []MXTarget `json:"mxTargets,omitempty"`
+	// CAATargets are the targets of a CAA record, used instead of targets
+	// +optional
+	CAATargets []CAATarget `json:"caaTargets,omitempty"`
+}
+
+// SRVTarget is a target of an SRV record, see RFC 2782
+type SRVTarget struct {
+	// Priority of the target, the targets with the lowest priority are used first
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Priority int32 `json:"priority"`
+	// Weight of the target among the targets with the same priority
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Weight int32 `json:"weight"`
+	// Port of the service on the target
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+	// Target is the hostname of the target
+	Target string `json:"target"`
+}
+
+// MXTarget is a target of an MX record, see RFC 1035
+type MXTarget struct {
+	// Priority of the mail server, the servers with the lowest priority are used first
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Priority int32 `json:"priority"`
+	// Host is the hostname of the mail server
+	Host string `json:"host"`
+}
+
+// CAATarget is a target of a CAA record, see RFC 8659
+type CAATarget struct {
+	// Flags of the target, 128 marks the tag as critical
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=255
+	Flags int32 `json:"flags"`
+	// Tag is the property of the target: issue and issuewild authorise a CA to issue certificates, iodef is where
+	// the CAs report the requests violating the record
+	// +kubebuilder:validation:Enum=issue;issuewild;iodef
+	Tag string `json:"tag"`
+	// Value of the property, e.g. the domain of the CA for an issue target
+	Value string `json:"value"`
 }
 
 // WithSetIdentifier applies the given set identifier to the endpoint.
@@ -97,7 +154,111 @@ func (e *Endpoint) GetProviderSpecificProperty(key string) (ProviderSpecificProp
 }
 
 func (e *Endpoint) String() string {
-	return fmt.Sprintf("%s %d IN %s %s %s %s", e.DNSName, e.RecordTTL, e.RecordType, e.SetIdentifier, e.Targets, e.ProviderSpecific)
+	return fmt.Sprintf("%s %d IN %s %s %s %s", e.DNSName, e.RecordTTL, e.RecordType, e.SetIdentifier, e.RecordValues(), e.ProviderSpecific)
+}
+
+// RecordValues returns the values of the record in zone file format, the structured targets of SRV, MX and CAA
+// records are formatted, e.g. "10 5 5060 sip.example.com" for an SRV target
+func (e *Endpoint) RecordValues() Targets {
+	switch DNSRecordType(e.RecordType) {
+	case SRVRecordType:
+		values := make(Targets, 0, len(e.SRVTargets))
+		for _, target := range e.SRVTargets {
+			values = append(values, fmt.Sprintf("%d %d %d %s", target.Priority, target.Weight, target.Port, target.Target))
+		}
+		return values
+	case MXRecordType:
+		values := make(Targets, 0, len(e.MXTargets))
+		for _, target := range e.MXTargets {
+			values = append(values, fmt.Sprintf("%d %s", target.Priority, target.Host))
+		}
+		return values
+	case CAARecordType:
+		values := make(Targets, 0, len(e.CAATargets))
+		for _, target := range e.CAATargets {
+			values = append(values, fmt.Sprintf(`%d %s "%s"`, target.Flags, target.Tag, target.Value))
+		}
+		return values
+	default:
+		return e.Targets
+	}
+}
+
+// Validate returns an error if the structured targets of an SRV, MX or CAA record are invalid
+func (e *Endpoint) Validate() error {
+	switch DNSRecordType(e.RecordType) {
+	case SRVRecordType:
+		// the name of an SRV record is _service._proto.name
+		labels := strings.Split(e.DNSName, ".")
+		if len(labels) < 3 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+			return fmt.Errorf("invalid SRV record name %s, it must be of the form _service._proto.name", e.DNSName)
+		}
+		if len(e.Targets) > 0 || len(e.SRVTargets) == 0 {
+			return fmt.Errorf("invalid SRV record %s, srvTargets must be set instead of targets", e.DNSName)
+		}
+		for _, target := range e.SRVTargets {
+			for _, field := range []struct {
+				name  string
+				value int32
+			}{{"priority", target.Priority}, {"weight", target.Weight}, {"port", target.Port}} {
+				if field.value < 0 || field.value > math.MaxUint16 {
+					return fmt.Errorf("invalid SRV record %s, %s %d must be between 0 and %d", e.DNSName, field.name, field.value, math.MaxUint16)
+				}
+			}
+			// a target of "." means the service is not available at the name
+			if target.Target != "." {
+				if err := validateTargetHost(target.Target); err != nil {
+					return fmt.Errorf("invalid SRV record %s: %w", e.DNSName, err)
+				}
+			}
+		}
+	case MXRecordType:
+		if len(e.Targets) > 0 || len(e.MXTargets) == 0 {
+			return fmt.Errorf("invalid MX record %s, mxTargets must be set instead of targets", e.DNSName)
+		}
+		for _, target := range e.MXTargets {
+			if target.Priority < 0 || target.Priority > math.MaxUint16 {
+				return fmt.Errorf("invalid MX record %s, priority %d must be between 0 and %d", e.DNSName, target.Priority, math.MaxUint16)
+			}
+			if err := validateTargetHost(target.Host); err != nil {
+				return fmt.Errorf("invalid MX record %s: %w", e.DNSName, err)
+			}
+		}
+	case CAARecordType:
+		if len(e.Targets) > 0 || len(e.CAATargets) == 0 {
+			return fmt.Errorf("invalid CAA record %s, caaTargets must be set instead of targets", e.DNSName)
+		}
+		for _, target := range e.CAATargets {
+			if target.Flags < 0 || target.Flags > math.MaxUint8 {
+				return fmt.Errorf("invalid CAA record %s, flags %d must be between 0 and %d", e.DNSName, target.Flags, math.MaxUint8)
+			}
+			switch target.Tag {
+			case "issue", "issuewild", "iodef":
+			default:
+				return fmt.Errorf("invalid CAA record %s, tag %q must be one of issue, issuewild or iodef", e.DNSName, target.Tag)
+			}
+			if strings.ContainsAny(target.Value, "\"\n") {
+				return fmt.Errorf("invalid CAA record %s, value %q must not contain quotes or line breaks", e.DNSName, target.Value)
+			}
+		}
+	default:
+		if len(e.SRVTargets) > 0 || len(e.MXTargets) > 0 || len(e.CAATargets) > 0 {
+			return fmt.Errorf("invalid %s record %s, srvTargets, mxTargets and caaTargets are only supported by SRV, MX and CAA records", e.RecordType, e.DNSName)
+		}
+	}
+	return nil
+}
+
+// validateTargetHost returns an error if the host targeted by an SRV or MX record is not a hostname, the target must
+// not be an IP address
+func validateTargetHost(host string) error {
+	if net.ParseIP(host) != nil {
+		return fmt.Errorf("target %s must be a hostname, not an IP address", host)
+	}
+	if errs := validation.IsDNS1123Subdomain(strings.TrimSuffix(strings.ToLower(host), ".")); len(errs) > 0 {
+		return fmt.Errorf("target %s is not a valid hostname: %s", host, strings.Join(errs, ", "))
+	}
+	return nil
 }
 
 // DNSRecordSpec defines the desired state of DNSRecord
@@ -105,9 +266,36 @@ type DNSRecordSpec struct {
 	// +kubebuilder:validation:Required
 	// +required
 	ManagedZoneRef *ManagedZoneReference `json:"managedZone,omitempty"`
+	// AdditionalManagedZoneRefs are other managed zones the endpoints are also published to, e.g. the zones of the
+	// same domain hosted by other DNS providers. The endpoints are published to each zone independently, a failure
+	// in one zone is reported in its status without preventing the other zones from being published to.
+	// +optional
+	AdditionalManagedZoneRefs []ManagedZoneReference `json:"additionalManagedZones,omitempty"`
 	// +kubebuilder:validation:MinItems=1
 	// +optional
 	Endpoints []*Endpoint `json:"endpoints,omitempty"`
+	// DryRun, when true, causes the changes required to publish the endpoints to be reported in status.plan
+	// without making any changes in the DNS provider. A DNSRecord deleted in dry run mode is kept, with its
+	// deletion planned in status.plan, until dry run is disabled when it was published before.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// HealthCheck, when set, causes health checks to be created in the DNS provider for the weighted, latency and
+	// failover record sets of the endpoints, so that the provider stops answering with the targets failing them.
+	// Only supported by Route53.
+	// +optional
+	HealthCheck *ProviderHealthCheck `json:"healthCheck,omitempty"`
+}
+
+// ProviderHealthCheck configures the health checks created in the DNS provider for a DNSRecord
+type ProviderHealthCheck struct {
+	// Endpoint is the path requested by the health checks, / by default
+	Endpoint string `json:"endpoint,omitempty"`
+	// Port is the port requested by the health checks, 443 for HTTPS and 80 for HTTP by default
+	Port *int `json:"port,omitempty"`
+	// Protocol is the protocol of the health checks, HTTPS by default
+	Protocol *HealthProtocol `json:"protocol,omitempty"`
+	// FailureThreshold is the number of consecutive failed health checks after which a target is unhealthy
+	FailureThreshold *int `json:"failureThreshold,omitempty"`
 }
 
 // DNSRecordStatus defines the observed state of DNSRecord
@@ -136,11 +324,67 @@ type DNSRecordStatus struct {
 	// Note: This will not be required if/when we switch to using external-dns since when
 	// running with a "sync" policy it will clean up unused records automatically.
 	Endpoints []*Endpoint `json:"endpoints,omitempty"`
+
+	// publishedEndpoints are the endpoints exactly as they were last applied by the provider, with provider
+	// defaults such as the TTL resolved. Weights and geo codes are found in the provider specific properties.
+	// +optional
+	PublishedEndpoints []*Endpoint `json:"publishedEndpoints,omitempty"`
+
+	// endpointsPublished is the number of endpoints last applied by the provider
+	// +optional
+	EndpointsPublished int64 `json:"endpointsPublished,omitempty"`
+
+	// lastPublishedTime is the time the endpoints were last applied by the provider
+	// +optional
+	LastPublishedTime *metav1.Time `json:"lastPublishedTime,omitempty"`
+
+	// plan is the list of record changes that would be made in the DNS provider, set when spec.dryRun is true
+	// +optional
+	Plan []string `json:"plan,omitempty"`
+
+	// managedZones is the status of the record in each of its managed zones. The record is Ready only once it is
+	// published to all of them.
+	// +optional
+	ManagedZones []DNSRecordZoneStatus `json:"managedZones,omitempty"`
+
+	// observedForceSync is the value of the kuadrant.io/force-sync annotation last reconciled, the record is
+	// published again to all of its managed zones when the value changes
+	// +optional
+	ObservedForceSync string `json:"observedForceSync,omitempty"`
+}
+
+// DNSRecordZoneStatus is the status of a DNSRecord in one of its managed zones
+type DNSRecordZoneStatus struct {
+	// name is the name of the managed zone
+	Name string `json:"name"`
+
+	// conditions are the conditions of the record in the managed zone, the "Ready" condition reports whether the
+	// endpoints are published to it
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// observedGeneration is the generation of the DNSRecord last published to the managed zone
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// endpoints are the last endpoints that were successfully published to the managed zone, used to delete the
+	// records no longer present in DNSRecordSpec.Endpoints
+	// +optional
+	Endpoints []*Endpoint `json:"endpoints,omitempty"`
+
+	// publishedEndpoints are the endpoints exactly as they were last applied by the provider of the managed zone
+	// +optional
+	PublishedEndpoints []*Endpoint `json:"publishedEndpoints,omitempty"`
+
+	// lastPublishedTime is the time the endpoints were last applied by the provider of the managed zone
+	// +optional
+	LastPublishedTime *metav1.Time `json:"lastPublishedTime,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="DNSRecord ready."
+//+kubebuilder:printcolumn:name="Endpoints",type="integer",JSONPath=".status.endpointsPublished",description="Number of endpoints published."
+//+kubebuilder:printcolumn:name="Last Published",type="date",JSONPath=".status.lastPublishedTime",description="Last published at."
 
 // DNSRecord is the Schema for the dnsrecords API
 type DNSRecord struct {
@@ -161,7 +405,7 @@ type DNSRecordList struct {
 }
 
 // DNSRecordType is a DNS resource record type.
-// +kubebuilder:validation:Enum=CNAME;A
+// +kubebuilder:validation:Enum=CNAME;A;AAAA;SRV;MX;CAA
 type DNSRecordType string
 
 const (
@@ -171,8 +415,23 @@ const (
 	// ARecordType is an RFC 1035 A record.
 	ARecordType DNSRecordType = "A"
 
+	// AAAARecordType is an RFC 3596 AAAA record.
+	AAAARecordType DNSRecordType = "AAAA"
+
 	// NSRecordType is a name server record.
 	NSRecordType DNSRecordType = "NS"
+
+	// TXTRecordType is an RFC 1035 TXT record.
+	TXTRecordType DNSRecordType = "TXT"
+
+	// SRVRecordType is an RFC 2782 SRV record.
+	SRVRecordType DNSRecordType = "SRV"
+
+	// MXRecordType is an RFC 1035 MX record.
+	MXRecordType DNSRecordType = "MX"
+
+	// CAARecordType is an RFC 8659 CAA record.
+	CAARecordType DNSRecordType = "CAA"
 )
 
 const (
@@ -241,6 +500,25 @@ func (endpoint *Endpoint) DeleteProviderSpecific(name string) bool {
 	return deleted
 }
 
+// TargetsManagedZone returns whether the record references the named managed zone, as its managed zone or one of
+// its additional managed zones, or is still published to it
+func (r *DNSRecord) TargetsManagedZone(zoneName string) bool {
+	if r.Spec.ManagedZoneRef != nil && r.Spec.ManagedZoneRef.Name == zoneName {
+		return true
+	}
+	for _, zoneRef := range r.Spec.AdditionalManagedZoneRefs {
+		if zoneRef.Name == zoneName {
+			return true
+		}
+	}
+	for _, zoneStatus := range r.Status.ManagedZones {
+		if zoneStatus.Name == zoneName {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	SchemeBuilder.Register(&DNSRecord{}, &DNSRecordList{})
 }
@@ -40,10 +40,42 @@ type ManagedZoneSpec struct {
 	// Reference to another managed zone that this managed zone belongs to.
 	// +optional
 	ParentManagedZone *ManagedZoneReference `json:"parentManagedZone,omitempty"`
-	// +required
-	SecretRef *SecretRef `json:"dnsProviderSecretRef"`
+	// SecretRef is the secret holding the credentials of the DNS provider. When not set, the provider must be set and
+	// the default credentials of the provider are used, only supported by the aws provider.
+	// +optional
+	SecretRef *SecretRef `json:"dnsProviderSecretRef,omitempty"`
+	// Provider is the DNS provider hosting this zone. If not set, the provider is determined by the type of the
+	// secret referenced by dnsProviderSecretRef.
+	// +optional
+	Provider DNSProviderType `json:"provider,omitempty"`
+	// DNSSEC enables the DNSSEC signing of the zone by the provider, only supported by the aws provider. The DS record
+	// to add at the registrar, or in the parent zone, is reported in the status once the zone is signed. When not set,
+	// the signing of the zone is left unchanged.
+	// +optional
+	DNSSEC DNSSECMode `json:"dnssec,omitempty"`
+	// DNSSECKMSKeyARN is the ARN of the customer managed KMS key, in us-east-1, the aws provider creates the
+	// key-signing key of the zone with. It is not required when the hosted zone already has a key-signing key.
+	// +optional
+	DNSSECKMSKeyARN string `json:"dnssecKMSKeyARN,omitempty"`
 }
 
+// +kubebuilder:validation:Enum=enabled
+type DNSSECMode string
+
+const (
+	DNSSECEnabled DNSSECMode = "enabled"
+)
+
+// +kubebuilder:validation:Enum=aws;google;azure;cloudflare
+type DNSProviderType string
+
+const (
+	DNSProviderTypeAWS        DNSProviderType = "aws"
+	DNSProviderTypeGoogle     DNSProviderType = "google"
+	DNSProviderTypeAzure      DNSProviderType = "azure"
+	DNSProviderTypeCloudflare DNSProviderType = "cloudflare"
+)
+
 type SecretRef struct {
 	//+required
 	Namespace string `json:"namespace"`
@@ -71,6 +103,10 @@ type ManagedZoneStatus struct {
 
 	// The NameServers assigned by the provider for this zone (i.e. route53.DelegationSet.NameServers)
 	NameServers []*string `json:"nameServers,omitempty"`
+
+	// The DS record of the key-signing key of the zone, to add at the registrar, or in the parent zone, to establish
+	// the DNSSEC chain of trust. Only set when dnssec is enabled.
+	DSRecord string `json:"dsRecord,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,106 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ManagedZoneCascadeDeleteAnnotation allows a ManagedZone to be deleted while DNSRecords still target it. The
+// DNSRecords are deleted, and their records removed from the provider, before the zone itself.
+const ManagedZoneCascadeDeleteAnnotation = "kuadrant.io/delete-dns-records"
+
+func (mz *ManagedZone) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(mz).
+		WithValidator(&managedZoneValidator{client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kuadrant-io-v1alpha1-managedzone,mutating=false,failurePolicy=fail,sideEffects=None,groups=kuadrant.io,resources=managedzones,verbs=delete,versions=v1alpha1,name=vmanagedzone.kb.io,admissionReviewVersions=v1
+
+// managedZoneValidator refuses the deletion of a ManagedZone targeted by DNSRecords, which would otherwise be left
+// unable to remove their records from the provider, other than the CAA records of the TLSPolicies
+type managedZoneValidator struct {
+	client client.Reader
+}
+
+var _ admission.CustomValidator = &managedZoneValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *managedZoneValidator) ValidateCreate(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *managedZoneValidator) ValidateUpdate(_ context.Context, _, _ runtime.Object) error {
+	return nil
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *managedZoneValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	mz, ok := obj.(*ManagedZone)
+	if !ok {
+		return fmt.Errorf("%T is not a *v1alpha1.ManagedZone", obj)
+	}
+	if mz.GetAnnotations()[ManagedZoneCascadeDeleteAnnotation] == "true" {
+		return nil
+	}
+
+	dnsRecords, err := DNSRecordsForManagedZone(ctx, v.client, mz)
+	if err != nil {
+		return err
+	}
+	// the CAA records published for the TLSPolicies are deleted with the zone
+	var names []string
+	for _, dnsRecord := range dnsRecords {
+		if dnsRecord.Labels[TLSPolicyCAALabel] == "true" {
+			continue
+		}
+		names = append(names, dnsRecord.Name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return fmt.Errorf("managed zone %s is still targeted by DNSRecords %s, delete them first or set the %s annotation to \"true\" to delete them with the zone",
+		mz.Name, strings.Join(names, ", "), ManagedZoneCascadeDeleteAnnotation)
+}
+
+// DNSRecordsForManagedZone returns the DNSRecords targeting the managed zone, as their managed zone or one of their
+// additional managed zones, or still published to it while they are being removed from it
+func DNSRecordsForManagedZone(ctx context.Context, c client.Reader, mz *ManagedZone) ([]DNSRecord, error) {
+	dnsRecordList := &DNSRecordList{}
+	if err := c.List(ctx, dnsRecordList, client.InNamespace(mz.Namespace)); err != nil {
+		return nil, err
+	}
+	var dnsRecords []DNSRecord
+	for _, dnsRecord := range dnsRecordList.Items {
+		if dnsRecord.TargetsManagedZone(mz.Name) {
+			dnsRecords = append(dnsRecords, dnsRecord)
+		}
+	}
+	return dnsRecords, nil
+}
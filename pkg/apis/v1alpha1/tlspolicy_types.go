@@ -18,24 +18,173 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
+	"text/template"
+	"time"
 
 	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
+// PolicyTargetReferenceWithSectionName identifies the target of a policy, optionally narrowed down to a section of it
+type PolicyTargetReferenceWithSectionName struct {
+	gatewayapiv1alpha2.PolicyTargetReference `json:",inline"`
+
+	// SectionName is the name of a listener of the target Gateway. When set, the policy only manages the TLS config
+	// and Certificates of that listener, so that several policies can target different listeners of the same
+	// Gateway.
+	// Only supported for policies targeting a Gateway.
+	// +optional
+	SectionName *gatewayv1beta1.SectionName `json:"sectionName,omitempty"`
+}
+
 // TLSPolicySpec defines the desired state of TLSPolicy
 type TLSPolicySpec struct {
 	// +kubebuilder:validation:Required
 	// +required
-	TargetRef gatewayapiv1alpha2.PolicyTargetReference `json:"targetRef"`
+	TargetRef PolicyTargetReferenceWithSectionName `json:"targetRef"`
+
+	// TargetSelector selects Gateways, in the same namespace as the policy, that the policy applies to in addition
+	// to the targetRef Gateway, e.g. identically configured blue and green Gateways. Certificates are issued for
+	// every selected Gateway, and the Certificates of a Gateway are deleted once its labels no longer match.
+	// Only supported for policies targeting a Gateway.
+	// +optional
+	TargetSelector *metav1.LabelSelector `json:"targetSelector,omitempty"`
+
+	// SolverRef is a reference to a ManagedZone, in the same namespace as the policy, that will be used to solve
+	// DNS01 challenges for the hosts of the generated Certificates. Every host, including wildcard hosts, must be
+	// the zone domain or a subdomain of it.
+	// Certificates are labelled with the zone name so that an ACME issuer can select the matching DNS01 solver.
+	// +optional
+	SolverRef *ManagedZoneReference `json:"solverRef,omitempty"`
+
+	// IssuerFallbackThreshold is how long a Certificate must be failing with the active issuer before the next
+	// issuer in issuerRefs is used. Defaults to 10m.
+	// +optional
+	IssuerFallbackThreshold *metav1.Duration `json:"issuerFallbackThreshold,omitempty"`
+
+	// CertificateLabels are added to every Certificate created by this policy. Labels set by the controller take
+	// precedence.
+	// +optional
+	CertificateLabels map[string]string `json:"certificateLabels,omitempty"`
+
+	// CertificateAnnotations are added to every Certificate created by this policy.
+	// +optional
+	CertificateAnnotations map[string]string `json:"certificateAnnotations,omitempty"`
+
+	// SecretRef is a reference to an existing Secret, in the same namespace as the policy, holding the tls.crt and
+	// tls.key used by the target listeners. No Certificates are created for a policy with a secretRef, the Secret is
+	// only verified to hold a valid certificate and private key pair.
+	// secretRef cannot be set together with issuerRef or issuerRefs.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// AutoConfigureListeners, when true, sets the TLS mode of the target Gateway listeners matching
+	// listenerHostnames to Terminate and their certificateRefs to a generated Secret name. Listeners with a TLS
+	// config that was set by other means are left untouched.
+	// Only supported for policies targeting a Gateway.
+	// +optional
+	AutoConfigureListeners bool `json:"autoConfigureListeners,omitempty"`
+
+	// ListenerHostnames select the listeners configured when autoConfigureListeners is true. A listener is selected
+	// when its hostname is equal to one of the hostnames, or matched by one of the wildcard hostnames.
+	// +optional
+	ListenerHostnames []gatewayv1beta1.Hostname `json:"listenerHostnames,omitempty"`
+
+	// ListenerSecretNameTemplate is a Go template for the name of the Secret referenced by each configured
+	// listener. The .Gateway name, and the listener .Name and .Hostname are available, with a "*" in the hostname
+	// replaced by "wildcard". When secretRef is set, the listeners reference that Secret instead.
+	// Defaults to "{{ .Gateway }}-{{ .Name }}-tls".
+	// +optional
+	ListenerSecretNameTemplate string `json:"listenerSecretNameTemplate,omitempty"`
+
+	// ConsolidateWildcard, when true and the hostnames of all listeners selected by listenerHostnames are direct
+	// subdomains of the same parent domain, issues a single wildcard Certificate for the parent domain and points
+	// every selected listener at its Secret. The Secret name is generated from listenerSecretNameTemplate with the
+	// .Name "wildcard". Listeners with hostnames under different parent domains get individual Certificates.
+	// Requires autoConfigureListeners.
+	// +optional
+	ConsolidateWildcard bool `json:"consolidateWildcard,omitempty"`
+
+	// CertificateNamespace is the namespace the Certificates of the configured listeners, and their Secrets, are
+	// created in instead of the namespace of the Gateway. The listeners reference the Secrets with their namespace,
+	// which the Gateway is only allowed to do when a ReferenceGrant in the namespace permits it. An Issuer in
+	// issuerRef or issuerRefs has to be in this namespace as well.
+	// Requires autoConfigureListeners.
+	// +optional
+	CertificateNamespace string `json:"certificateNamespace,omitempty"`
+
+	// ReadinessPolicy decides the Ready condition of the policy from the certificate status of its listeners. With
+	// All the policy is not ready once the certificate of any listener fails, with Any it stays ready as long as the
+	// certificate of one listener has not failed. Listeners waiting for their certificate do not fail the policy.
+	// Defaults to All.
+	// +kubebuilder:default=All
+	// +optional
+	ReadinessPolicy TLSPolicyReadinessPolicy `json:"readinessPolicy,omitempty"`
+
+	// CertificateValidation, when set, verifies the certificates issued for the policy once their Secrets hold a
+	// certificate, and reports the result in the CertificateValidated condition of the policy. The leaf certificate
+	// must cover every dnsName of its Certificate.
+	// Not supported together with secretRef.
+	// +optional
+	CertificateValidation *CertificateValidation `json:"certificateValidation,omitempty"`
+
+	// CAA, when set, publishes a CAA record at the domain of the solverRef ManagedZone authorising the CAs of the
+	// policy issuers, and no other CA, to issue certificates for the zone. The record holds the CAs of every policy
+	// with a caa referencing the zone, and is updated when their issuers change.
+	// Requires solverRef, not supported together with secretRef.
+	// +optional
+	CAA *TLSPolicyCAA `json:"caa,omitempty"`
 
 	CertificateSpec `json:",inline"`
 }
 
+// TLSPolicyReadinessPolicy decides the Ready condition of a TLSPolicy from the certificate status of its listeners
+// +kubebuilder:validation:Enum=All;Any
+type TLSPolicyReadinessPolicy string
+
+const (
+	// TLSPolicyReadinessAll requires the certificates of all the listeners not to fail
+	TLSPolicyReadinessAll TLSPolicyReadinessPolicy = "All"
+	// TLSPolicyReadinessAny requires the certificate of at least one listener not to fail
+	TLSPolicyReadinessAny TLSPolicyReadinessPolicy = "Any"
+)
+
+// CertificateValidation configures the verification of the certificates issued for a TLSPolicy
+type CertificateValidation struct {
+	// TrustAnchorSecretRef is a reference to a Secret, in the same namespace as the policy, holding the PEM encoded
+	// root certificates trusted for the policy in its ca.crt entry. When set, the tls.crt of each issued certificate
+	// must chain from its leaf to one of the roots through the intermediates it holds, so that an issuer omitting
+	// an intermediate fails the validation.
+	// +optional
+	TrustAnchorSecretRef *corev1.LocalObjectReference `json:"trustAnchorSecretRef,omitempty"`
+}
+
+// TLSPolicyCAA configures the CAA record published for a TLSPolicy, see RFC 8659
+type TLSPolicyCAA struct {
+	// IssuerDomains are the CAA domains of the CAs authorised to issue certificates, e.g. letsencrypt.org. Defaults
+	// to the domains of the CAs of the ACME servers of the policy issuers, and is required when an issuer is not an
+	// ACME issuer of a known CA, e.g. a Vault or external issuer.
+	// +optional
+	IssuerDomains []string `json:"issuerDomains,omitempty"`
+}
+
+// TLSPolicyCAALabel marks the DNSRecords holding the CAA record of a ManagedZone published for the TLSPolicies. The
+// records are deleted with the zone and do not prevent its deletion.
+const TLSPolicyCAALabel = "kuadrant.io/tlspolicy-caa"
+
+const (
+	DefaultIssuerFallbackThreshold    = 10 * time.Minute
+	DefaultListenerSecretNameTemplate = "{{ .Gateway }}-{{ .Name }}-tls"
+)
+
 // CertificateSpec defines the certificate manager certificate spec that can be set via the TLSPolicy.
 // Rather than allowing the whole certmanv1.CertificateSpec to be inlined we are only including the same fields that are
 // currently supported by the annotation approach to securing gateways as outlined here https://cert-manager.io/docs/usage/gateway/#supported-annotations
@@ -46,7 +195,24 @@ type CertificateSpec struct {
 	// If the `kind` field is set to `ClusterIssuer`, a ClusterIssuer with the
 	// provided name will be used.
 	// The `name` field in this stanza is required at all times.
-	IssuerRef cmmeta.ObjectReference `json:"issuerRef"`
+	// The `group` field defaults to cert-manager.io, other groups are only accepted when allowed by the
+	// controller, in which case the `kind` field is required.
+	// Exactly one of issuerRef or issuerRefs must be set, unless secretRef is set.
+	// +optional
+	IssuerRef cmmeta.ObjectReference `json:"issuerRef,omitempty"`
+
+	// IssuerRefs is an ordered list of issuers for this certificate. The first issuer is used until its
+	// Certificates have been failing for longer than the issuerFallbackThreshold, in which case the next issuer in
+	// the list is used.
+	// Exactly one of issuerRef or issuerRefs must be set, unless secretRef is set.
+	// +optional
+	IssuerRefs []cmmeta.ObjectReference `json:"issuerRefs,omitempty"`
+
+	// IssuerRules select the issuer of the Certificates by hostname, e.g. a public issuer for the public hosts and an
+	// internal CA for the "*.internal" hosts of the same Gateway. A Certificate uses the issuer of the first rule
+	// matching all its hosts, Certificates not matching any rule use issuerRef or issuerRefs.
+	// +optional
+	IssuerRules []IssuerRule `json:"issuerRules,omitempty"`
 
 	// CommonName is a common name to be used on the Certificate.
 	// The CommonName should have a length of 64 characters or fewer to avoid
@@ -56,6 +222,13 @@ type CertificateSpec struct {
 	// +optional
 	CommonName string `json:"commonName,omitempty"`
 
+	// OmitCommonName, when true, leaves the commonName of the Certificates empty. Otherwise, unless commonName is
+	// set, the commonName defaults to the primary host of each Certificate, i.e. the hostname of the first listener
+	// it is issued for, or the wildcard host of a wildcard Certificate.
+	// omitCommonName cannot be set together with commonName.
+	// +optional
+	OmitCommonName bool `json:"omitCommonName,omitempty"`
+
 	// The requested 'duration' (i.e. lifetime) of the Certificate. This option
 	// may be ignored/overridden by some issuer types. If unset this defaults to
 	// 90 days. Certificate will be renewed either 2/3 through its duration or
@@ -73,8 +246,9 @@ type CertificateSpec struct {
 	// +optional
 	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
 
-	// Usages is the set of x509 usages that are requested for the certificate.
-	// Defaults to `digital signature` and `key encipherment` if not specified.
+	// Usages is the set of x509 key usages and extended key usages that are requested for the certificate, e.g.
+	// `client auth` for the certificates of mTLS clients. Each usage can only be listed once.
+	// Defaults to `digital signature`, `key encipherment` and `server auth` if not specified.
 	// +optional
 	Usages []certmanv1.KeyUsage `json:"usages,omitempty"`
 
@@ -84,14 +258,276 @@ type CertificateSpec struct {
 	// was created, renewed, or Spec was changed. Revisions will be removed by
 	// oldest first if the number of revisions exceeds this number. If set,
 	// revisionHistoryLimit must be a value of `1` or greater. If unset (`nil`),
-	// revisions will not be garbage collected. Default value is `nil`.
-	// +kubebuilder:validation:ExclusiveMaximum=false
+	// revisions will not be garbage collected. Default value is `nil`. Changing the limit updates the existing
+	// Certificates.
+	// +kubebuilder:validation:Minimum=1
 	// +optional
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 
-	// Options to control private keys used for the Certificate.
+	// Options to control private keys used for the Certificate. The algorithm must be RSA (the default) or ECDSA,
+	// and the size, when set, must be supported by the algorithm: 2048 to 8192 bits for RSA, and 256, 384 or 521
+	// bits for ECDSA. Changing the options updates the existing Certificates.
 	// +optional
 	PrivateKey *certmanv1.CertificatePrivateKey `json:"privateKey,omitempty"`
+
+	// SecretTemplate defines labels and annotations to be copied to the Certificate's Secret, e.g. for a service
+	// mesh to pick it up. Labels set by the controller take precedence. Changes to the template are applied to the
+	// existing Certificates, and by cert-manager to their Secrets.
+	// +optional
+	SecretTemplate *certmanv1.CertificateSecretTemplate `json:"secretTemplate,omitempty"`
+
+	// AdditionalOutputFormats are the extra formats of the private key and certificate chain written to the
+	// Certificate's Secret, `CombinedPEM` (the `tls-combined.pem` key) or `DER` (the `key.der` key), each listed once.
+	// Requires the `AdditionalCertificateOutputFormats` feature gate of cert-manager.
+	// +optional
+	AdditionalOutputFormats []certmanv1.CertificateAdditionalOutputFormat `json:"additionalOutputFormats,omitempty"`
+}
+
+// IssuerRule is the issuer of the Certificates for the hosts matching a hostname
+type IssuerRule struct {
+	// Hostname is the host, or the wildcard hostname, e.g. "*.internal.example.com", of the hosts using the issuer.
+	// A wildcard hostname matches every subdomain of its domain.
+	// +kubebuilder:validation:Required
+	// +required
+	Hostname gatewayv1beta1.Hostname `json:"hostname"`
+
+	// IssuerRef is a reference to the issuer of the Certificates for the matching hosts
+	// +kubebuilder:validation:Required
+	// +required
+	IssuerRef cmmeta.ObjectReference `json:"issuerRef"`
+}
+
+func (s *CertificateSpec) Validate() error {
+	if s.IssuerRef.Name == "" && len(s.IssuerRefs) == 0 {
+		return fmt.Errorf("invalid value for spec.issuerRef, one of spec.issuerRef or spec.issuerRefs must be set")
+	}
+
+	if s.IssuerRef.Name != "" && len(s.IssuerRefs) > 0 {
+		return fmt.Errorf("invalid value for spec.issuerRefs, it cannot be set together with spec.issuerRef")
+	}
+
+	if err := s.validateIssuerRefs(); err != nil {
+		return err
+	}
+
+	if err := s.validateIssuerRules(); err != nil {
+		return err
+	}
+
+	if s.OmitCommonName && s.CommonName != "" {
+		return fmt.Errorf("invalid value for spec.omitCommonName, it cannot be set together with spec.commonName")
+	}
+
+	if err := s.validatePrivateKey(); err != nil {
+		return err
+	}
+
+	if err := s.validateUsages(); err != nil {
+		return err
+	}
+
+	if err := s.validateRevisionHistoryLimit(); err != nil {
+		return err
+	}
+
+	if err := s.validateAdditionalOutputFormats(); err != nil {
+		return err
+	}
+
+	if s.Duration != nil && s.RenewBefore != nil && s.RenewBefore.Duration >= s.Duration.Duration {
+		return fmt.Errorf("invalid value for spec.renewBefore %v, it must be shorter than spec.duration %v", s.RenewBefore.Duration, s.Duration.Duration)
+	}
+
+	return nil
+}
+
+// allowedIssuerGroups are the API groups of the issuers the policies may reference
+var allowedIssuerGroups = sets.New(certmanv1.SchemeGroupVersion.Group)
+
+// SetAllowedIssuerGroups sets the API groups of the issuers the policies may reference, e.g. the group of the external
+// issuers of an alternate signer, cert-manager.io when none are set. It is meant to be called once on start up,
+// before the policies are validated.
+func SetAllowedIssuerGroups(groups ...string) {
+	allowedIssuerGroups = sets.New[string]()
+	for _, group := range groups {
+		if group = strings.TrimSpace(group); group != "" {
+			allowedIssuerGroups.Insert(group)
+		}
+	}
+	if allowedIssuerGroups.Len() == 0 {
+		allowedIssuerGroups.Insert(certmanv1.SchemeGroupVersion.Group)
+	}
+}
+
+// AllowedIssuerGroups returns the API groups of the issuers the policies may reference, sorted
+func AllowedIssuerGroups() []string {
+	return sets.List(allowedIssuerGroups)
+}
+
+// validateIssuerRefs ensures every issuer reference points at a cert-manager Issuer or ClusterIssuer, or at an issuer
+// of another allowed group. The issuers themselves are not required to exist.
+func (s *CertificateSpec) validateIssuerRefs() error {
+	for _, issuerRef := range s.GetIssuerRefs() {
+		if err := validateIssuerRef(issuerRef); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateIssuerRef(issuerRef cmmeta.ObjectReference) error {
+	group := issuerRef.Group
+	if group == "" {
+		group = certmanv1.SchemeGroupVersion.Group
+	}
+	if !allowedIssuerGroups.Has(group) {
+		if allowedIssuerGroups.Len() == 1 {
+			return fmt.Errorf("invalid value for issuerRef.group %s, the only supported group is %s", group, AllowedIssuerGroups()[0])
+		}
+		return fmt.Errorf("invalid value for issuerRef.group %s, the supported groups are %s", group, strings.Join(AllowedIssuerGroups(), ", "))
+	}
+	// the kinds of the issuers of other groups are defined by their signers
+	if group != certmanv1.SchemeGroupVersion.Group {
+		if issuerRef.Kind == "" {
+			return fmt.Errorf("invalid value for issuerRef.kind, it must be set for the issuers of group %s", group)
+		}
+		return nil
+	}
+	if issuerRef.Kind != "" && issuerRef.Kind != certmanv1.IssuerKind && issuerRef.Kind != certmanv1.ClusterIssuerKind {
+		return fmt.Errorf("invalid value for issuerRef.kind %s, the only supported kinds are %s and %s", issuerRef.Kind, certmanv1.IssuerKind, certmanv1.ClusterIssuerKind)
+	}
+	return nil
+}
+
+// validateIssuerRules ensures every rule has a valid hostname, with a wildcard only as its leftmost label, and a
+// reference to a cert-manager Issuer or ClusterIssuer
+func (s *CertificateSpec) validateIssuerRules() error {
+	for i, rule := range s.IssuerRules {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(string(rule.Hostname), "*.")); len(errs) > 0 {
+			return fmt.Errorf("invalid value for spec.issuerRules[%d].hostname %q, %s", i, rule.Hostname, strings.Join(errs, ", "))
+		}
+		if rule.IssuerRef.Name == "" {
+			return fmt.Errorf("invalid value for spec.issuerRules[%d].issuerRef, the name must be set", i)
+		}
+		if err := validateIssuerRef(rule.IssuerRef); err != nil {
+			return fmt.Errorf("invalid value for spec.issuerRules[%d], %w", i, err)
+		}
+	}
+	return nil
+}
+
+// IssuerRefForHosts returns the issuer of the first issuer rule matching all the hosts, false when no rule matches
+func (s *CertificateSpec) IssuerRefForHosts(hosts []string) (cmmeta.ObjectReference, bool) {
+	for _, rule := range s.IssuerRules {
+		matches := len(hosts) > 0
+		for _, host := range hosts {
+			if !hostnameMatches(rule.Hostname, host) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return rule.IssuerRef, true
+		}
+	}
+	return cmmeta.ObjectReference{}, false
+}
+
+// hostnameMatches returns true if the host is the hostname or, for a wildcard hostname, a subdomain of its domain
+func hostnameMatches(hostname gatewayv1beta1.Hostname, host string) bool {
+	pattern, host := strings.ToLower(string(hostname)), strings.ToLower(host)
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+domain)
+	}
+	return host == pattern
+}
+
+// keyUsages are the usages supported by cert-manager
+var keyUsages = sets.New[certmanv1.KeyUsage](
+	certmanv1.UsageSigning, certmanv1.UsageDigitalSignature, certmanv1.UsageContentCommitment,
+	certmanv1.UsageKeyEncipherment, certmanv1.UsageKeyAgreement, certmanv1.UsageDataEncipherment,
+	certmanv1.UsageCertSign, certmanv1.UsageCRLSign, certmanv1.UsageEncipherOnly, certmanv1.UsageDecipherOnly,
+	certmanv1.UsageAny, certmanv1.UsageServerAuth, certmanv1.UsageClientAuth, certmanv1.UsageCodeSigning,
+	certmanv1.UsageEmailProtection, certmanv1.UsageSMIME, certmanv1.UsageIPsecEndSystem, certmanv1.UsageIPsecTunnel,
+	certmanv1.UsageIPsecUser, certmanv1.UsageTimestamping, certmanv1.UsageOCSPSigning, certmanv1.UsageMicrosoftSGC,
+	certmanv1.UsageNetscapeSGC,
+)
+
+// validateUsages ensures every usage is supported by cert-manager and listed once
+func (s *CertificateSpec) validateUsages() error {
+	listed := sets.New[certmanv1.KeyUsage]()
+	for _, usage := range s.Usages {
+		if !keyUsages.Has(usage) {
+			return fmt.Errorf("invalid value for spec.usages %q, it is not a supported key usage", usage)
+		}
+		if listed.Has(usage) {
+			return fmt.Errorf("invalid value for spec.usages, %q is listed more than once", usage)
+		}
+		listed.Insert(usage)
+	}
+	return nil
+}
+
+// validateRevisionHistoryLimit ensures at least the current CertificateRequest revision is kept when a limit is set
+func (s *CertificateSpec) validateRevisionHistoryLimit() error {
+	if s.RevisionHistoryLimit != nil && *s.RevisionHistoryLimit < 1 {
+		return fmt.Errorf("invalid value for revisionHistoryLimit %d, it must be 1 or greater", *s.RevisionHistoryLimit)
+	}
+	return nil
+}
+
+// validateAdditionalOutputFormats ensures every output format is supported by cert-manager and listed once
+func (s *CertificateSpec) validateAdditionalOutputFormats() error {
+	listed := sets.New[certmanv1.CertificateOutputFormatType]()
+	for _, format := range s.AdditionalOutputFormats {
+		switch format.Type {
+		case certmanv1.CertificateOutputFormatDER, certmanv1.CertificateOutputFormatCombinedPEM:
+		default:
+			return fmt.Errorf("invalid value for spec.additionalOutputFormats %q, the only supported formats are %s and %s", format.Type, certmanv1.CertificateOutputFormatCombinedPEM, certmanv1.CertificateOutputFormatDER)
+		}
+		if listed.Has(format.Type) {
+			return fmt.Errorf("invalid value for spec.additionalOutputFormats, %q is listed more than once", format.Type)
+		}
+		listed.Insert(format.Type)
+	}
+	return nil
+}
+
+// validatePrivateKey ensures the private key algorithm is RSA or ECDSA and that the size, when set, is supported by
+// the algorithm.
+func (s *CertificateSpec) validatePrivateKey() error {
+	if s.PrivateKey == nil {
+		return nil
+	}
+
+	switch s.PrivateKey.Algorithm {
+	case "", certmanv1.RSAKeyAlgorithm:
+		if s.PrivateKey.Size != 0 && (s.PrivateKey.Size < 2048 || s.PrivateKey.Size > 8192) {
+			return fmt.Errorf("invalid value for privateKey.size %d, %s keys must be between 2048 and 8192 bits", s.PrivateKey.Size, certmanv1.RSAKeyAlgorithm)
+		}
+	case certmanv1.ECDSAKeyAlgorithm:
+		if s.PrivateKey.Size != 0 && s.PrivateKey.Size != 256 && s.PrivateKey.Size != 384 && s.PrivateKey.Size != 521 {
+			return fmt.Errorf("invalid value for privateKey.size %d, %s keys must be 256, 384 or 521 bits", s.PrivateKey.Size, certmanv1.ECDSAKeyAlgorithm)
+		}
+	default:
+		return fmt.Errorf("invalid value for privateKey.algorithm %s, the only supported algorithms are %s and %s", s.PrivateKey.Algorithm, certmanv1.RSAKeyAlgorithm, certmanv1.ECDSAKeyAlgorithm)
+	}
+
+	switch s.PrivateKey.RotationPolicy {
+	case "", certmanv1.RotationPolicyNever, certmanv1.RotationPolicyAlways:
+	default:
+		return fmt.Errorf("invalid value for privateKey.rotationPolicy %s, the only supported policies are %s and %s", s.PrivateKey.RotationPolicy, certmanv1.RotationPolicyNever, certmanv1.RotationPolicyAlways)
+	}
+
+	return nil
+}
+
+// GetIssuerRefs returns the ordered list of issuers for this certificate
+func (s *CertificateSpec) GetIssuerRefs() []cmmeta.ObjectReference {
+	if len(s.IssuerRefs) > 0 {
+		return s.IssuerRefs
+	}
+	return []cmmeta.ObjectReference{s.IssuerRef}
 }
 
 // TLSPolicyStatus defines the observed state of TLSPolicy
@@ -108,6 +544,40 @@ type TLSPolicyStatus struct {
 	// recorded in the status condition
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ActiveIssuer is the issuer currently used for the Certificates created by this policy
+	// +optional
+	ActiveIssuer *cmmeta.ObjectReference `json:"activeIssuer,omitempty"`
+
+	// LastIssuerTransitionTime is the last time the active issuer changed
+	// +optional
+	LastIssuerTransitionTime *metav1.Time `json:"lastIssuerTransitionTime,omitempty"`
+
+	// observedForceSync is the value of the kuadrant.io/force-sync annotation last reconciled
+	// +optional
+	ObservedForceSync string `json:"observedForceSync,omitempty"`
+
+	// Listeners is the certificate status of each Gateway listener the policy issues a Certificate for
+	// +optional
+	Listeners []TLSPolicyListenerStatus `json:"listeners,omitempty"`
+
+	// CAAIssuerDomains are the sorted CAA domains of the CAs the policy authorises in the CAA record of its
+	// solverRef ManagedZone
+	// +optional
+	CAAIssuerDomains []string `json:"caaIssuerDomains,omitempty"`
+}
+
+// TLSPolicyListenerStatus is the certificate status of a Gateway listener
+type TLSPolicyListenerStatus struct {
+	// Gateway is the namespace/name of the Gateway of the listener
+	Gateway string `json:"gateway"`
+
+	// Name is the name of the listener
+	Name string `json:"name"`
+
+	// Conditions holds the Ready condition of the listener certificate
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -133,22 +603,209 @@ func (p *TLSPolicy) GetRulesHostnames() []string {
 }
 
 func (p *TLSPolicy) GetTargetRef() gatewayapiv1alpha2.PolicyTargetReference {
-	return p.Spec.TargetRef
+	return p.Spec.TargetRef.PolicyTargetReference
+}
+
+// GetSectionName returns the name of the target Gateway listener, or an empty string if the policy targets the
+// whole Gateway
+func (p *TLSPolicy) GetSectionName() string {
+	if p.Spec.TargetRef.SectionName == nil {
+		return ""
+	}
+	return string(*p.Spec.TargetRef.SectionName)
 }
 
 func (p *TLSPolicy) Validate() error {
+	if err := p.validateTargetRef(); err != nil {
+		return err
+	}
+
+	if err := p.validateSecretRef(); err != nil {
+		return err
+	}
+
+	if err := p.validateAutoConfigureListeners(); err != nil {
+		return err
+	}
+
+	if err := p.validateConsolidateWildcard(); err != nil {
+		return err
+	}
+
+	if err := p.validateCertificateNamespace(); err != nil {
+		return err
+	}
+
+	if err := p.validateCertificateValidation(); err != nil {
+		return err
+	}
+
+	if err := p.validateCAA(); err != nil {
+		return err
+	}
+
+	// the certificate spec only applies when certificates are issued for the policy
+	if p.Spec.SecretRef != nil {
+		return nil
+	}
+
+	return p.Spec.CertificateSpec.Validate()
+}
+
+func (p *TLSPolicy) validateAutoConfigureListeners() error {
+	if !p.Spec.AutoConfigureListeners {
+		return nil
+	}
+
+	if p.Spec.TargetRef.Kind != ("Gateway") {
+		return fmt.Errorf("invalid value for spec.autoConfigureListeners, it is only supported for a Gateway targetRef")
+	}
+
+	if len(p.Spec.ListenerHostnames) == 0 {
+		return fmt.Errorf("invalid value for spec.listenerHostnames, at least one hostname must be set when spec.autoConfigureListeners is true")
+	}
+
+	if _, err := template.New("listenerSecretName").Parse(p.Spec.GetListenerSecretNameTemplate()); err != nil {
+		return fmt.Errorf("invalid value for spec.listenerSecretNameTemplate, %w", err)
+	}
+
+	return nil
+}
+
+func (p *TLSPolicy) validateConsolidateWildcard() error {
+	if !p.Spec.ConsolidateWildcard {
+		return nil
+	}
+
+	if !p.Spec.AutoConfigureListeners {
+		return fmt.Errorf("invalid value for spec.consolidateWildcard, it requires spec.autoConfigureListeners to be true")
+	}
+
+	if p.Spec.SecretRef != nil {
+		return fmt.Errorf("invalid value for spec.consolidateWildcard, it cannot be set together with spec.secretRef")
+	}
+
+	return nil
+}
+
+func (p *TLSPolicy) validateCertificateNamespace() error {
+	if p.Spec.CertificateNamespace == "" {
+		return nil
+	}
+
+	if !p.Spec.AutoConfigureListeners {
+		return fmt.Errorf("invalid value for spec.certificateNamespace, it requires spec.autoConfigureListeners to be true")
+	}
+
+	if p.Spec.SecretRef != nil {
+		return fmt.Errorf("invalid value for spec.certificateNamespace, it cannot be set together with spec.secretRef")
+	}
+
+	if errs := validation.IsDNS1123Label(p.Spec.CertificateNamespace); len(errs) > 0 {
+		return fmt.Errorf("invalid value for spec.certificateNamespace, %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (p *TLSPolicy) validateCertificateValidation() error {
+	if p.Spec.CertificateValidation == nil {
+		return nil
+	}
+
+	if p.Spec.SecretRef != nil {
+		return fmt.Errorf("invalid value for spec.certificateValidation, it cannot be set together with spec.secretRef")
+	}
+
+	if ref := p.Spec.CertificateValidation.TrustAnchorSecretRef; ref != nil && ref.Name == "" {
+		return fmt.Errorf("invalid value for spec.certificateValidation.trustAnchorSecretRef, the name must be set")
+	}
+
+	return nil
+}
+
+func (p *TLSPolicy) validateCAA() error {
+	if p.Spec.CAA == nil {
+		return nil
+	}
+
+	if p.Spec.SolverRef == nil {
+		return fmt.Errorf("invalid value for spec.caa, it requires spec.solverRef to be set")
+	}
+
+	if p.Spec.SecretRef != nil {
+		return fmt.Errorf("invalid value for spec.caa, it cannot be set together with spec.secretRef")
+	}
+
+	for _, domain := range p.Spec.CAA.IssuerDomains {
+		if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q for spec.caa.issuerDomains, %s", domain, strings.Join(errs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// GetListenerSecretNameTemplate returns the template for the Secret names referenced by auto configured listeners
+func (s *TLSPolicySpec) GetListenerSecretNameTemplate() string {
+	if s.ListenerSecretNameTemplate == "" {
+		return DefaultListenerSecretNameTemplate
+	}
+	return s.ListenerSecretNameTemplate
+}
+
+func (p *TLSPolicy) validateSecretRef() error {
+	if p.Spec.SecretRef == nil {
+		return nil
+	}
+
+	if p.Spec.SecretRef.Name == "" {
+		return fmt.Errorf("invalid value for spec.secretRef, the name must be set")
+	}
+
+	if p.Spec.IssuerRef.Name != "" || len(p.Spec.IssuerRefs) > 0 {
+		return fmt.Errorf("invalid value for spec.secretRef, it cannot be set together with spec.issuerRef or spec.issuerRefs")
+	}
+
+	if len(p.Spec.IssuerRules) > 0 {
+		return fmt.Errorf("invalid value for spec.secretRef, it cannot be set together with spec.issuerRules")
+	}
+
+	return nil
+}
+
+func (p *TLSPolicy) validateTargetRef() error {
 	if p.Spec.TargetRef.Group != ("gateway.networking.k8s.io") {
 		return fmt.Errorf("invalid targetRef.Group %s. The only supported group is gateway.networking.k8s.io", p.Spec.TargetRef.Group)
 	}
 
-	if p.Spec.TargetRef.Kind != ("Gateway") {
-		return fmt.Errorf("invalid targetRef.Kind %s. The only supported kind is Gateway", p.Spec.TargetRef.Kind)
+	if p.Spec.TargetRef.Kind != ("Gateway") && p.Spec.TargetRef.Kind != ("HTTPRoute") {
+		return fmt.Errorf("invalid targetRef.Kind %s. The only supported kinds are Gateway and HTTPRoute", p.Spec.TargetRef.Kind)
 	}
 
 	if p.Spec.TargetRef.Namespace != nil && string(*p.Spec.TargetRef.Namespace) != p.Namespace {
 		return fmt.Errorf("invalid targetRef.Namespace %s. Currently only supporting references to the same namespace", *p.Spec.TargetRef.Namespace)
 	}
 
+	if p.Spec.TargetSelector != nil {
+		if p.Spec.TargetRef.Kind != ("Gateway") {
+			return fmt.Errorf("invalid value for spec.targetSelector, it is only supported for a Gateway targetRef")
+		}
+		if _, err := metav1.LabelSelectorAsSelector(p.Spec.TargetSelector); err != nil {
+			return fmt.Errorf("invalid value for spec.targetSelector, %w", err)
+		}
+	}
+
+	if p.Spec.TargetRef.SectionName != nil {
+		if p.Spec.TargetRef.Kind != ("Gateway") {
+			return fmt.Errorf("invalid targetRef.SectionName %s. A sectionName is only supported for a Gateway targetRef", *p.Spec.TargetRef.SectionName)
+		}
+		// the section name is part of the back reference annotation key set on the Gateway
+		if errs := validation.IsQualifiedName(fmt.Sprintf("kuadrant.io/tlspolicy.%s", *p.Spec.TargetRef.SectionName)); len(errs) > 0 {
+			return fmt.Errorf("invalid targetRef.SectionName %s. %s", *p.Spec.TargetRef.SectionName, strings.Join(errs, ", "))
+		}
+	}
+
 	return nil
 }
 
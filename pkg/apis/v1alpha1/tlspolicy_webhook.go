@@ -0,0 +1,73 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (p *TLSPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(p).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kuadrant-io-v1alpha1-tlspolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kuadrant.io,resources=tlspolicies,verbs=create;update,versions=v1alpha1,name=vtlspolicy.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &TLSPolicy{}
+
+// ValidateCreate implements webhook.Validator. Only the structure of the target, issuer and secret references, the
+// revision history limit and the private key options are validated, the referenced issuers and secret may be created
+// after the policy.
+func (p *TLSPolicy) ValidateCreate() error {
+	return p.validateSpec()
+}
+
+// ValidateUpdate implements webhook.Validator
+func (p *TLSPolicy) ValidateUpdate(_ runtime.Object) error {
+	// the finalizer must always be removable from a policy being deleted
+	if p.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	return p.validateSpec()
+}
+
+// ValidateDelete implements webhook.Validator
+func (p *TLSPolicy) ValidateDelete() error {
+	return nil
+}
+
+func (p *TLSPolicy) validateSpec() error {
+	if err := p.validateTargetRef(); err != nil {
+		return err
+	}
+	if err := p.validateSecretRef(); err != nil {
+		return err
+	}
+	if err := p.Spec.CertificateSpec.validateIssuerRefs(); err != nil {
+		return err
+	}
+	if err := p.Spec.CertificateSpec.validateIssuerRules(); err != nil {
+		return err
+	}
+	if err := p.Spec.CertificateSpec.validateRevisionHistoryLimit(); err != nil {
+		return err
+	}
+	return p.Spec.CertificateSpec.validatePrivateKey()
+}
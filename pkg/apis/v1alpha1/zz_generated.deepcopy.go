@@ -23,9 +23,11 @@ package v1alpha1
 
 import (
 	certmanagerv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
-
+	metav1 "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/gateway-api/apis/v1beta1"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -77,10 +79,35 @@ func (in *AdditionalHeadersRef) DeepCopy() *AdditionalHeadersRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CAATarget) DeepCopyInto(out *CAATarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CAATarget.
+func (in *CAATarget) DeepCopy() *CAATarget {
+	if in == nil {
+		return nil
+	}
+	out := new(CAATarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
 	*out = *in
 	out.IssuerRef = in.IssuerRef
+	if in.IssuerRefs != nil {
+		in, out := &in.IssuerRefs, &out.IssuerRefs
+		*out = make([]metav1.ObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.IssuerRules != nil {
+		in, out := &in.IssuerRules, &out.IssuerRules
+		*out = make([]IssuerRule, len(*in))
+		copy(*out, *in)
+	}
 	if in.Duration != nil {
 		in, out := &in.Duration, &out.Duration
 		*out = new(v1.Duration)
@@ -106,6 +133,16 @@ func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
 		*out = new(certmanagerv1.CertificatePrivateKey)
 		**out = **in
 	}
+	if in.SecretTemplate != nil {
+		in, out := &in.SecretTemplate, &out.SecretTemplate
+		*out = new(certmanagerv1.CertificateSecretTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalOutputFormats != nil {
+		in, out := &in.AdditionalOutputFormats, &out.AdditionalOutputFormats
+		*out = make([]certmanagerv1.CertificateAdditionalOutputFormat, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSpec.
@@ -118,6 +155,212 @@ func (in *CertificateSpec) DeepCopy() *CertificateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateValidation) DeepCopyInto(out *CertificateValidation) {
+	*out = *in
+	if in.TrustAnchorSecretRef != nil {
+		in, out := &in.TrustAnchorSecretRef, &out.TrustAnchorSecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateValidation.
+func (in *CertificateValidation) DeepCopy() *CertificateValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterGeo) DeepCopyInto(out *ClusterGeo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterGeo.
+func (in *ClusterGeo) DeepCopy() *ClusterGeo {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterGeo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTLSPolicy) DeepCopyInto(out *ClusterTLSPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTLSPolicy.
+func (in *ClusterTLSPolicy) DeepCopy() *ClusterTLSPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTLSPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTLSPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTLSPolicyList) DeepCopyInto(out *ClusterTLSPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterTLSPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTLSPolicyList.
+func (in *ClusterTLSPolicyList) DeepCopy() *ClusterTLSPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTLSPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterTLSPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTLSPolicyOverride) DeepCopyInto(out *ClusterTLSPolicyOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTLSPolicyOverride.
+func (in *ClusterTLSPolicyOverride) DeepCopy() *ClusterTLSPolicyOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTLSPolicyOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTLSPolicySpec) DeepCopyInto(out *ClusterTLSPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewaySelector != nil {
+		in, out := &in.GatewaySelector, &out.GatewaySelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.CertificateSpec.DeepCopyInto(&out.CertificateSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTLSPolicySpec.
+func (in *ClusterTLSPolicySpec) DeepCopy() *ClusterTLSPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTLSPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterTLSPolicyStatus) DeepCopyInto(out *ClusterTLSPolicyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Overridden != nil {
+		in, out := &in.Overridden, &out.Overridden
+		*out = make([]ClusterTLSPolicyOverride, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTLSPolicyStatus.
+func (in *ClusterTLSPolicyStatus) DeepCopy() *ClusterTLSPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterTLSPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWeight) DeepCopyInto(out *ClusterWeight) {
+	*out = *in
+	if in.RampStartTime != nil {
+		in, out := &in.RampStartTime, &out.RampStartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWeight.
+func (in *ClusterWeight) DeepCopy() *ClusterWeight {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWeight)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomBias) DeepCopyInto(out *CustomBias) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomBias.
+func (in *CustomBias) DeepCopy() *CustomBias {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomBias)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomWeight) DeepCopyInto(out *CustomWeight) {
 	*out = *in
@@ -206,11 +449,21 @@ func (in *DNSHealthCheckProbeSpec) DeepCopyInto(out *DNSHealthCheckProbeSpec) {
 		*out = new(AdditionalHeadersRef)
 		**out = **in
 	}
+	if in.AdditionalHeaders != nil {
+		in, out := &in.AdditionalHeaders, &out.AdditionalHeaders
+		*out = make(AdditionalHeaders, len(*in))
+		copy(*out, *in)
+	}
 	if in.FailureThreshold != nil {
 		in, out := &in.FailureThreshold, &out.FailureThreshold
 		*out = new(int)
 		**out = **in
 	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int)
+		**out = **in
+	}
 	if in.ExpectedResponses != nil {
 		in, out := &in.ExpectedResponses, &out.ExpectedResponses
 		*out = make([]int, len(*in))
@@ -322,6 +575,33 @@ func (in *DNSPolicySpec) DeepCopyInto(out *DNSPolicySpec) {
 		*out = new(LoadBalancingSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExcludeClusters != nil {
+		in, out := &in.ExcludeClusters, &out.ExcludeClusters
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostOverrides != nil {
+		in, out := &in.HostOverrides, &out.HostOverrides
+		*out = make([]HostOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSDrainDuration != nil {
+		in, out := &in.DNSDrainDuration, &out.DNSDrainDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.StaticRecords != nil {
+		in, out := &in.StaticRecords, &out.StaticRecords
+		*out = make([]StaticRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DelegateToCNAME != nil {
+		in, out := &in.DelegateToCNAME, &out.DelegateToCNAME
+		*out = new(DelegateToCNAME)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSPolicySpec.
@@ -349,6 +629,30 @@ func (in *DNSPolicyStatus) DeepCopyInto(out *DNSPolicyStatus) {
 		*out = new(HealthCheckStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ManagedZones != nil {
+		in, out := &in.ManagedZones, &out.ManagedZones
+		*out = make([]ListenerManagedZone, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterGeos != nil {
+		in, out := &in.ClusterGeos, &out.ClusterGeos
+		*out = make([]ClusterGeo, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterWeights != nil {
+		in, out := &in.ClusterWeights, &out.ClusterWeights
+		*out = make([]ClusterWeight, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DrainingEndpoints != nil {
+		in, out := &in.DrainingEndpoints, &out.DrainingEndpoints
+		*out = make([]DrainingEndpoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSPolicyStatus.
@@ -443,6 +747,11 @@ func (in *DNSRecordSpec) DeepCopyInto(out *DNSRecordSpec) {
 		*out = new(ManagedZoneReference)
 		**out = **in
 	}
+	if in.AdditionalManagedZoneRefs != nil {
+		in, out := &in.AdditionalManagedZoneRefs, &out.AdditionalManagedZoneRefs
+		*out = make([]ManagedZoneReference, len(*in))
+		copy(*out, *in)
+	}
 	if in.Endpoints != nil {
 		in, out := &in.Endpoints, &out.Endpoints
 		*out = make([]*Endpoint, len(*in))
@@ -454,6 +763,11 @@ func (in *DNSRecordSpec) DeepCopyInto(out *DNSRecordSpec) {
 			}
 		}
 	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(ProviderHealthCheck)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordSpec.
@@ -487,6 +801,33 @@ func (in *DNSRecordStatus) DeepCopyInto(out *DNSRecordStatus) {
 			}
 		}
 	}
+	if in.PublishedEndpoints != nil {
+		in, out := &in.PublishedEndpoints, &out.PublishedEndpoints
+		*out = make([]*Endpoint, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Endpoint)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.LastPublishedTime != nil {
+		in, out := &in.LastPublishedTime, &out.LastPublishedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedZones != nil {
+		in, out := &in.ManagedZones, &out.ManagedZones
+		*out = make([]DNSRecordZoneStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordStatus.
@@ -500,26 +841,120 @@ func (in *DNSRecordStatus) DeepCopy() *DNSRecordStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Endpoint) DeepCopyInto(out *Endpoint) {
+func (in *DNSRecordZoneStatus) DeepCopyInto(out *DNSRecordZoneStatus) {
 	*out = *in
-	if in.Targets != nil {
-		in, out := &in.Targets, &out.Targets
-		*out = make(Targets, len(*in))
-		copy(*out, *in)
-	}
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(Labels, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ProviderSpecific != nil {
-		in, out := &in.ProviderSpecific, &out.ProviderSpecific
-		*out = make(ProviderSpecific, len(*in))
-		copy(*out, *in)
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]*Endpoint, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Endpoint)
+				(*in).DeepCopyInto(*out)
+			}
+		}
 	}
-}
+	if in.PublishedEndpoints != nil {
+		in, out := &in.PublishedEndpoints, &out.PublishedEndpoints
+		*out = make([]*Endpoint, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Endpoint)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.LastPublishedTime != nil {
+		in, out := &in.LastPublishedTime, &out.LastPublishedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSRecordZoneStatus.
+func (in *DNSRecordZoneStatus) DeepCopy() *DNSRecordZoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DNSRecordZoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DelegateToCNAME) DeepCopyInto(out *DelegateToCNAME) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DelegateToCNAME.
+func (in *DelegateToCNAME) DeepCopy() *DelegateToCNAME {
+	if in == nil {
+		return nil
+	}
+	out := new(DelegateToCNAME)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainingEndpoint) DeepCopyInto(out *DrainingEndpoint) {
+	*out = *in
+	in.DrainStartTime.DeepCopyInto(&out.DrainStartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainingEndpoint.
+func (in *DrainingEndpoint) DeepCopy() *DrainingEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainingEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Endpoint) DeepCopyInto(out *Endpoint) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make(Targets, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(Labels, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ProviderSpecific != nil {
+		in, out := &in.ProviderSpecific, &out.ProviderSpecific
+		*out = make(ProviderSpecific, len(*in))
+		copy(*out, *in)
+	}
+	if in.SRVTargets != nil {
+		in, out := &in.SRVTargets, &out.SRVTargets
+		*out = make([]SRVTarget, len(*in))
+		copy(*out, *in)
+	}
+	if in.MXTargets != nil {
+		in, out := &in.MXTargets, &out.MXTargets
+		*out = make([]MXTarget, len(*in))
+		copy(*out, *in)
+	}
+	if in.CAATargets != nil {
+		in, out := &in.CAATargets, &out.CAATargets
+		*out = make([]CAATarget, len(*in))
+		copy(*out, *in)
+	}
+}
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Endpoint.
 func (in *Endpoint) DeepCopy() *Endpoint {
@@ -549,11 +984,21 @@ func (in *HealthCheckSpec) DeepCopyInto(out *HealthCheckSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int)
+		**out = **in
+	}
 	if in.AdditionalHeadersRef != nil {
 		in, out := &in.AdditionalHeadersRef, &out.AdditionalHeadersRef
 		*out = new(AdditionalHeadersRef)
 		**out = **in
 	}
+	if in.AdditionalHeaders != nil {
+		in, out := &in.AdditionalHeaders, &out.AdditionalHeaders
+		*out = make(AdditionalHeaders, len(*in))
+		copy(*out, *in)
+	}
 	if in.ExpectedResponses != nil {
 		in, out := &in.ExpectedResponses, &out.ExpectedResponses
 		*out = make([]int, len(*in))
@@ -598,6 +1043,37 @@ func (in *HealthCheckStatus) DeepCopy() *HealthCheckStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostOverride) DeepCopyInto(out *HostOverride) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostOverride.
+func (in *HostOverride) DeepCopy() *HostOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(HostOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerRule) DeepCopyInto(out *IssuerRule) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerRule.
+func (in *IssuerRule) DeepCopy() *IssuerRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Labels) DeepCopyInto(out *Labels) {
 	{
@@ -619,9 +1095,70 @@ func (in Labels) DeepCopy() Labels {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerManagedZone) DeepCopyInto(out *ListenerManagedZone) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerManagedZone.
+func (in *ListenerManagedZone) DeepCopy() *ListenerManagedZone {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerManagedZone)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListenerWeights) DeepCopyInto(out *ListenerWeights) {
+	*out = *in
+	if in.Custom != nil {
+		in, out := &in.Custom, &out.Custom
+		*out = make([]*CustomWeight, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(CustomWeight)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListenerWeights.
+func (in *ListenerWeights) DeepCopy() *ListenerWeights {
+	if in == nil {
+		return nil
+	}
+	out := new(ListenerWeights)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancingGeo) DeepCopyInto(out *LoadBalancingGeo) {
 	*out = *in
+	if in.RegionGeoCodes != nil {
+		in, out := &in.RegionGeoCodes, &out.RegionGeoCodes
+		*out = make([]RegionGeoCode, len(*in))
+		copy(*out, *in)
+	}
+	if in.GeoFallback != nil {
+		in, out := &in.GeoFallback, &out.GeoFallback
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancingGeo.
@@ -634,6 +1171,47 @@ func (in *LoadBalancingGeo) DeepCopy() *LoadBalancingGeo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancingGeoproximity) DeepCopyInto(out *LoadBalancingGeoproximity) {
+	*out = *in
+	if in.Custom != nil {
+		in, out := &in.Custom, &out.Custom
+		*out = make([]*CustomBias, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(CustomBias)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancingGeoproximity.
+func (in *LoadBalancingGeoproximity) DeepCopy() *LoadBalancingGeoproximity {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancingGeoproximity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalancingLatency) DeepCopyInto(out *LoadBalancingLatency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancingLatency.
+func (in *LoadBalancingLatency) DeepCopy() *LoadBalancingLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalancingLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancingSpec) DeepCopyInto(out *LoadBalancingSpec) {
 	*out = *in
@@ -645,8 +1223,18 @@ func (in *LoadBalancingSpec) DeepCopyInto(out *LoadBalancingSpec) {
 	if in.Geo != nil {
 		in, out := &in.Geo, &out.Geo
 		*out = new(LoadBalancingGeo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(LoadBalancingLatency)
 		**out = **in
 	}
+	if in.Geoproximity != nil {
+		in, out := &in.Geoproximity, &out.Geoproximity
+		*out = new(LoadBalancingGeoproximity)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancingSpec.
@@ -673,6 +1261,18 @@ func (in *LoadBalancingWeighted) DeepCopyInto(out *LoadBalancingWeighted) {
 			}
 		}
 	}
+	if in.RampDuration != nil {
+		in, out := &in.RampDuration, &out.RampDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]ListenerWeights, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancingWeighted.
@@ -685,6 +1285,21 @@ func (in *LoadBalancingWeighted) DeepCopy() *LoadBalancingWeighted {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MXTarget) DeepCopyInto(out *MXTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MXTarget.
+func (in *MXTarget) DeepCopy() *MXTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(MXTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ManagedHost) DeepCopyInto(out *ManagedHost) {
 	*out = *in
@@ -842,6 +1457,57 @@ func (in *ManagedZoneStatus) DeepCopy() *ManagedZoneStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyTargetReferenceWithSectionName) DeepCopyInto(out *PolicyTargetReferenceWithSectionName) {
+	*out = *in
+	in.PolicyTargetReference.DeepCopyInto(&out.PolicyTargetReference)
+	if in.SectionName != nil {
+		in, out := &in.SectionName, &out.SectionName
+		*out = new(v1beta1.SectionName)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyTargetReferenceWithSectionName.
+func (in *PolicyTargetReferenceWithSectionName) DeepCopy() *PolicyTargetReferenceWithSectionName {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyTargetReferenceWithSectionName)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderHealthCheck) DeepCopyInto(out *ProviderHealthCheck) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int)
+		**out = **in
+	}
+	if in.Protocol != nil {
+		in, out := &in.Protocol, &out.Protocol
+		*out = new(HealthProtocol)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderHealthCheck.
+func (in *ProviderHealthCheck) DeepCopy() *ProviderHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ProviderSpecific) DeepCopyInto(out *ProviderSpecific) {
 	{
@@ -876,6 +1542,36 @@ func (in *ProviderSpecificProperty) DeepCopy() *ProviderSpecificProperty {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionGeoCode) DeepCopyInto(out *RegionGeoCode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionGeoCode.
+func (in *RegionGeoCode) DeepCopy() *RegionGeoCode {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionGeoCode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SRVTarget) DeepCopyInto(out *SRVTarget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SRVTarget.
+func (in *SRVTarget) DeepCopy() *SRVTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SRVTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretRef) DeepCopyInto(out *SecretRef) {
 	*out = *in
@@ -891,6 +1587,36 @@ func (in *SecretRef) DeepCopy() *SecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRecord) DeepCopyInto(out *StaticRecord) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SRVTargets != nil {
+		in, out := &in.SRVTargets, &out.SRVTargets
+		*out = make([]SRVTarget, len(*in))
+		copy(*out, *in)
+	}
+	if in.MXTargets != nil {
+		in, out := &in.MXTargets, &out.MXTargets
+		*out = make([]MXTarget, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRecord.
+func (in *StaticRecord) DeepCopy() *StaticRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSPolicy) DeepCopyInto(out *TLSPolicy) {
 	*out = *in
@@ -918,6 +1644,26 @@ func (in *TLSPolicy) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSPolicyCAA) DeepCopyInto(out *TLSPolicyCAA) {
+	*out = *in
+	if in.IssuerDomains != nil {
+		in, out := &in.IssuerDomains, &out.IssuerDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSPolicyCAA.
+func (in *TLSPolicyCAA) DeepCopy() *TLSPolicyCAA {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSPolicyCAA)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSPolicyList) DeepCopyInto(out *TLSPolicyList) {
 	*out = *in
@@ -950,10 +1696,81 @@ func (in *TLSPolicyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSPolicyListenerStatus) DeepCopyInto(out *TLSPolicyListenerStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSPolicyListenerStatus.
+func (in *TLSPolicyListenerStatus) DeepCopy() *TLSPolicyListenerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSPolicyListenerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TLSPolicySpec) DeepCopyInto(out *TLSPolicySpec) {
 	*out = *in
 	in.TargetRef.DeepCopyInto(&out.TargetRef)
+	if in.TargetSelector != nil {
+		in, out := &in.TargetSelector, &out.TargetSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SolverRef != nil {
+		in, out := &in.SolverRef, &out.SolverRef
+		*out = new(ManagedZoneReference)
+		**out = **in
+	}
+	if in.IssuerFallbackThreshold != nil {
+		in, out := &in.IssuerFallbackThreshold, &out.IssuerFallbackThreshold
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.CertificateLabels != nil {
+		in, out := &in.CertificateLabels, &out.CertificateLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CertificateAnnotations != nil {
+		in, out := &in.CertificateAnnotations, &out.CertificateAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ListenerHostnames != nil {
+		in, out := &in.ListenerHostnames, &out.ListenerHostnames
+		*out = make([]v1beta1.Hostname, len(*in))
+		copy(*out, *in)
+	}
+	if in.CertificateValidation != nil {
+		in, out := &in.CertificateValidation, &out.CertificateValidation
+		*out = new(CertificateValidation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CAA != nil {
+		in, out := &in.CAA, &out.CAA
+		*out = new(TLSPolicyCAA)
+		(*in).DeepCopyInto(*out)
+	}
 	in.CertificateSpec.DeepCopyInto(&out.CertificateSpec)
 }
 
@@ -977,6 +1794,27 @@ func (in *TLSPolicyStatus) DeepCopyInto(out *TLSPolicyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ActiveIssuer != nil {
+		in, out := &in.ActiveIssuer, &out.ActiveIssuer
+		*out = new(metav1.ObjectReference)
+		**out = **in
+	}
+	if in.LastIssuerTransitionTime != nil {
+		in, out := &in.LastIssuerTransitionTime, &out.LastIssuerTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Listeners != nil {
+		in, out := &in.Listeners, &out.Listeners
+		*out = make([]TLSPolicyListenerStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CAAIssuerDomains != nil {
+		in, out := &in.CAAIssuerDomains, &out.CAAIssuerDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSPolicyStatus.
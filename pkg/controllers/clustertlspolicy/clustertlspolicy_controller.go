@@ -0,0 +1,339 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustertlspolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	// ClusterTLSPolicyLabel is set on the TLSPolicies created for a ClusterTLSPolicy to the name of the policy
+	ClusterTLSPolicyLabel = "kuadrant.io/cluster-tlspolicy"
+
+	TLSPoliciesAppliedReason = "TLSPoliciesApplied"
+)
+
+// ErrTLSPolicyConflict is returned when a TLSPolicy that is not managed by the ClusterTLSPolicy already has the name
+// of the TLSPolicy to create for a Gateway
+var ErrTLSPolicyConflict = errors.New("TLSPolicy already exists and is not managed by the ClusterTLSPolicy")
+
+// ClusterTLSPolicyReconciler reconciles a ClusterTLSPolicy object
+type ClusterTLSPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	controller.ReconcilerOptions
+}
+
+//+kubebuilder:rbac:groups=kuadrant.io,resources=clustertlspolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=clustertlspolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=tlspolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+func (r *ClusterTLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	_ = log.FromContext(ctx)
+	previous := &v1alpha1.ClusterTLSPolicy{}
+	if err := r.Client.Get(ctx, req.NamespacedName, previous); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// the TLSPolicies of a deleted policy are garbage collected through their owner reference
+	if previous.DeletionTimestamp != nil && !previous.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	clusterPolicy := previous.DeepCopy()
+
+	log.Log.V(3).Info("ClusterTLSPolicyReconciler Reconcile", "clusterTLSPolicy", clusterPolicy.Name)
+
+	specErr := clusterPolicy.Validate()
+	var reconcileErr error
+	if specErr == nil {
+		reconcileErr = r.reconcileTLSPolicies(ctx, clusterPolicy)
+	}
+
+	clusterPolicy.Status.ObservedGeneration = clusterPolicy.Generation
+	meta.SetStatusCondition(&clusterPolicy.Status.Conditions, readyCondition(clusterPolicy, specErr, reconcileErr))
+	if !equality.Semantic.DeepEqual(previous.Status, clusterPolicy.Status) {
+		if err := r.Status().Update(ctx, clusterPolicy); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// an invalid spec or a conflicting TLSPolicy is reported in the status, the policy is reconciled again once
+	// either of them changes
+	if errors.Is(reconcileErr, ErrTLSPolicyConflict) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, reconcileErr
+}
+
+// reconcileTLSPolicies creates or updates a TLSPolicy targeting each Gateway selected by the ClusterTLSPolicy, unless
+// another policy takes precedence for the Gateway, and deletes the TLSPolicies of the Gateways it no longer applies to.
+// The status of the policy lists the Gateways it applies to and the ones it yields to another policy for.
+func (r *ClusterTLSPolicyReconciler) reconcileTLSPolicies(ctx context.Context, clusterPolicy *v1alpha1.ClusterTLSPolicy) error {
+	namespaces := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaces); err != nil {
+		return err
+	}
+	namespaceLabels := make(map[string]labels.Set, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		namespaceLabels[namespace.Name] = namespace.Labels
+	}
+
+	gateways := &gatewayv1beta1.GatewayList{}
+	if err := r.Client.List(ctx, gateways); err != nil {
+		return err
+	}
+	sort.Slice(gateways.Items, func(i, j int) bool {
+		return gatewayKey(&gateways.Items[i]) < gatewayKey(&gateways.Items[j])
+	})
+
+	tlsPolicies := &v1alpha1.TLSPolicyList{}
+	if err := r.Client.List(ctx, tlsPolicies); err != nil {
+		return err
+	}
+	clusterPolicies := &v1alpha1.ClusterTLSPolicyList{}
+	if err := r.Client.List(ctx, clusterPolicies); err != nil {
+		return err
+	}
+
+	clusterPolicy.Status.Gateways = nil
+	clusterPolicy.Status.Overridden = nil
+	desired := map[client.ObjectKey]bool{}
+	var conflicts []string
+	for i := range gateways.Items {
+		gateway := &gateways.Items[i]
+		if !selectsGateway(clusterPolicy, gateway, namespaceLabels) {
+			continue
+		}
+		if overriddenBy := overridingPolicy(clusterPolicy, gateway, namespaceLabels, tlsPolicies.Items, clusterPolicies.Items); overriddenBy != "" {
+			clusterPolicy.Status.Overridden = append(clusterPolicy.Status.Overridden, v1alpha1.ClusterTLSPolicyOverride{
+				Gateway: gatewayKey(gateway),
+				Policy:  overriddenBy,
+			})
+			continue
+		}
+
+		tlsPolicy := r.desiredTLSPolicy(clusterPolicy, gateway)
+		desired[client.ObjectKeyFromObject(tlsPolicy)] = true
+		if err := r.applyTLSPolicy(ctx, clusterPolicy, tlsPolicy); errors.Is(err, ErrTLSPolicyConflict) {
+			conflicts = append(conflicts, client.ObjectKeyFromObject(tlsPolicy).String())
+			continue
+		} else if err != nil {
+			return err
+		}
+		clusterPolicy.Status.Gateways = append(clusterPolicy.Status.Gateways, gatewayKey(gateway))
+	}
+
+	for i := range tlsPolicies.Items {
+		tlsPolicy := &tlsPolicies.Items[i]
+		if tlsPolicy.Labels[ClusterTLSPolicyLabel] != clusterPolicy.Name || desired[client.ObjectKeyFromObject(tlsPolicy)] {
+			continue
+		}
+		log.Log.V(3).Info("deleting TLSPolicy of ClusterTLSPolicy", "clusterTLSPolicy", clusterPolicy.Name, "tlsPolicy", client.ObjectKeyFromObject(tlsPolicy))
+		if err := r.Client.Delete(ctx, tlsPolicy); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrTLSPolicyConflict, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// desiredTLSPolicy returns the TLSPolicy applying the certificate config of the ClusterTLSPolicy to the Gateway
+func (r *ClusterTLSPolicyReconciler) desiredTLSPolicy(clusterPolicy *v1alpha1.ClusterTLSPolicy, gateway *gatewayv1beta1.Gateway) *v1alpha1.TLSPolicy {
+	return &v1alpha1.TLSPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", clusterPolicy.Name, gateway.Name),
+			Namespace: gateway.Namespace,
+			Labels:    map[string]string{ClusterTLSPolicyLabel: clusterPolicy.Name},
+		},
+		Spec: v1alpha1.TLSPolicySpec{
+			TargetRef: v1alpha1.PolicyTargetReferenceWithSectionName{
+				PolicyTargetReference: gatewayapiv1alpha2.PolicyTargetReference{
+					Group: gatewayv1beta1.GroupName,
+					Kind:  "Gateway",
+					Name:  gatewayapiv1alpha2.ObjectName(gateway.Name),
+				},
+			},
+			CertificateSpec: *clusterPolicy.Spec.CertificateSpec.DeepCopy(),
+		},
+	}
+}
+
+// applyTLSPolicy creates the TLSPolicy, or updates the spec of the existing one, owned by the ClusterTLSPolicy. An
+// existing TLSPolicy that is not labelled for the ClusterTLSPolicy is left as is and an ErrTLSPolicyConflict is
+// returned.
+func (r *ClusterTLSPolicyReconciler) applyTLSPolicy(ctx context.Context, clusterPolicy *v1alpha1.ClusterTLSPolicy, tlsPolicy *v1alpha1.TLSPolicy) error {
+	if err := controllerutil.SetControllerReference(clusterPolicy, tlsPolicy, r.Scheme); err != nil {
+		return err
+	}
+
+	existing := &v1alpha1.TLSPolicy{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), existing); err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Log.V(3).Info("creating TLSPolicy of ClusterTLSPolicy", "clusterTLSPolicy", clusterPolicy.Name, "tlsPolicy", client.ObjectKeyFromObject(tlsPolicy))
+			return r.Client.Create(ctx, tlsPolicy)
+		}
+		return err
+	}
+	if existing.Labels[ClusterTLSPolicyLabel] != clusterPolicy.Name {
+		return ErrTLSPolicyConflict
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, tlsPolicy.Spec) {
+		return nil
+	}
+	existing.Spec = tlsPolicy.Spec
+	return r.Client.Update(ctx, existing)
+}
+
+// overridingPolicy returns the policy taking precedence over the ClusterTLSPolicy for the Gateway, empty when there is
+// none. A TLSPolicy in the namespace of the Gateway that targets or selects it always takes precedence, a
+// ClusterTLSPolicy also selecting the Gateway takes precedence when its name is lower.
+func overridingPolicy(clusterPolicy *v1alpha1.ClusterTLSPolicy, gateway *gatewayv1beta1.Gateway, namespaceLabels map[string]labels.Set, tlsPolicies []v1alpha1.TLSPolicy, clusterPolicies []v1alpha1.ClusterTLSPolicy) string {
+	for i := range tlsPolicies {
+		tlsPolicy := &tlsPolicies[i]
+		if tlsPolicy.Namespace != gateway.Namespace || tlsPolicy.Labels[ClusterTLSPolicyLabel] != "" ||
+			tlsPolicy.DeletionTimestamp != nil {
+			continue
+		}
+		if targetsGateway(tlsPolicy, gateway) {
+			return fmt.Sprintf("TLSPolicy %s", client.ObjectKeyFromObject(tlsPolicy))
+		}
+	}
+
+	for i := range clusterPolicies {
+		other := &clusterPolicies[i]
+		if other.Name >= clusterPolicy.Name || other.DeletionTimestamp != nil || other.Validate() != nil {
+			continue
+		}
+		if selectsGateway(other, gateway, namespaceLabels) {
+			return fmt.Sprintf("ClusterTLSPolicy %s", other.Name)
+		}
+	}
+	return ""
+}
+
+// targetsGateway returns whether the TLSPolicy applies to the Gateway through its targetRef or its targetSelector
+func targetsGateway(tlsPolicy *v1alpha1.TLSPolicy, gateway *gatewayv1beta1.Gateway) bool {
+	targetRef := tlsPolicy.Spec.TargetRef
+	if string(targetRef.Group) == gatewayv1beta1.GroupName && targetRef.Kind == "Gateway" &&
+		string(targetRef.Name) == gateway.Name {
+		return true
+	}
+	if tlsPolicy.Spec.TargetSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(tlsPolicy.Spec.TargetSelector)
+	return err == nil && selector.Matches(labels.Set(gateway.Labels))
+}
+
+// selectsGateway returns whether the namespace and gateway selectors of a valid ClusterTLSPolicy match the Gateway
+func selectsGateway(clusterPolicy *v1alpha1.ClusterTLSPolicy, gateway *gatewayv1beta1.Gateway, namespaceLabels map[string]labels.Set) bool {
+	matches := func(labelSelector *metav1.LabelSelector, set labels.Set) bool {
+		if labelSelector == nil {
+			return true
+		}
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		return err == nil && selector.Matches(set)
+	}
+	return matches(clusterPolicy.Spec.NamespaceSelector, namespaceLabels[gateway.Namespace]) &&
+		matches(clusterPolicy.Spec.GatewaySelector, labels.Set(gateway.Labels))
+}
+
+func gatewayKey(gateway *gatewayv1beta1.Gateway) string {
+	return client.ObjectKeyFromObject(gateway).String()
+}
+
+func readyCondition(clusterPolicy *v1alpha1.ClusterTLSPolicy, specErr, reconcileErr error) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               string(conditions.ConditionTypeReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             TLSPoliciesAppliedReason,
+		Message:            fmt.Sprintf("TLSPolicy applied to %d Gateways", len(clusterPolicy.Status.Gateways)),
+		ObservedGeneration: clusterPolicy.Generation,
+	}
+	switch {
+	case specErr != nil:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(conditions.PolicyReasonInvalid)
+		cond.Message = specErr.Error()
+	case errors.Is(reconcileErr, ErrTLSPolicyConflict):
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(conditions.PolicyReasonConflicted)
+		cond.Message = reconcileErr.Error()
+	case reconcileErr != nil:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "ReconciliationError"
+		cond.Message = reconcileErr.Error()
+	}
+	return cond
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterTLSPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the Gateways a policy selects, and the policies taking precedence for them, can change with any of these
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ClusterTLSPolicy{}).
+		Watches(&source.Kind{Type: &v1alpha1.TLSPolicy{}}, handler.EnqueueRequestsFromMapFunc(r.allClusterTLSPolicies)).
+		Watches(&source.Kind{Type: &gatewayv1beta1.Gateway{}}, handler.EnqueueRequestsFromMapFunc(r.allClusterTLSPolicies)).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.allClusterTLSPolicies)).
+		WithOptions(r.ControllerOptions()).
+		Complete(r.WrapReconciler(r))
+}
+
+// allClusterTLSPolicies returns a request for every ClusterTLSPolicy
+func (r *ClusterTLSPolicyReconciler) allClusterTLSPolicies(obj client.Object) []reconcile.Request {
+	clusterPolicies := &v1alpha1.ClusterTLSPolicyList{}
+	if err := r.Client.List(context.TODO(), clusterPolicies); err != nil {
+		log.Log.Error(err, "failed to list cluster TLS policies", "object", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(clusterPolicies.Items))
+	for _, clusterPolicy := range clusterPolicies.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterPolicy)})
+	}
+	return requests
+}
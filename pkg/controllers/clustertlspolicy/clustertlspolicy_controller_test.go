@@ -0,0 +1,180 @@
+//go:build unit
+
+package clustertlspolicy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestClusterTLSPolicyReconciler_precedence(t *testing.T) {
+	namespace := func(name, env string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"env": env}}}
+	}
+	gateway := func(name, ns string, labels map[string]string) client.Object {
+		gw := testutil.NewTestGateway(name, "istio", ns).WithHTTPSListener(name+"."+ns+".example.com", name+"-tls").Gateway
+		gw.Labels = labels
+		return gw
+	}
+	clusterPolicy := func(name string, namespaceSelector, gatewaySelector map[string]string) *v1alpha1.ClusterTLSPolicy {
+		p := &v1alpha1.ClusterTLSPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if namespaceSelector != nil {
+			p.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: namespaceSelector}
+		}
+		if gatewaySelector != nil {
+			p.Spec.GatewaySelector = &metav1.LabelSelector{MatchLabels: gatewaySelector}
+		}
+		p.Spec.IssuerRef = cmmeta.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer", Group: "cert-manager.io"}
+		return p
+	}
+
+	edge := map[string]string{"tier": "edge"}
+	defaultTLS := clusterPolicy("default-tls", map[string]string{"env": "prod"}, edge)
+	userPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "green").
+		WithTargetGateway("prod-web").
+		WithIssuer("internal-ca", "Issuer", "cert-manager.io").TLSPolicy
+
+	f := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).WithObjects(
+		namespace("blue", "prod"), namespace("green", "prod"), namespace("dev", "dev"),
+		gateway("prod-web", "blue", edge), gateway("prod-web", "green", edge),
+		gateway("internal", "green", nil), gateway("prod-web", "dev", edge),
+		defaultTLS, userPolicy,
+	).Build()
+	r := &ClusterTLSPolicyReconciler{Client: f, Scheme: testutil.GetValidTestScheme()}
+	ctx := context.TODO()
+
+	reconcile := func(name string) *v1alpha1.ClusterTLSPolicy {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, testutil.BuildValidTestRequest(name, "")); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		p := &v1alpha1.ClusterTLSPolicy{}
+		if err := f.Get(ctx, client.ObjectKey{Name: name}, p); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !meta.IsStatusConditionTrue(p.Status.Conditions, string(conditions.ConditionTypeReady)) {
+			t.Errorf("expected %s to be ready, got %v", name, p.Status.Conditions)
+		}
+		return p
+	}
+	generatedPolicy := func(name, ns string) *v1alpha1.TLSPolicy {
+		t.Helper()
+		tlsPolicy := &v1alpha1.TLSPolicy{}
+		if err := f.Get(ctx, client.ObjectKey{Name: name, Namespace: ns}, tlsPolicy); k8serrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return tlsPolicy
+	}
+
+	// the namespace-scoped policy of the green gateway takes precedence, the dev namespace is not selected
+	status := reconcile("default-tls").Status
+	if want := []string{"blue/prod-web"}; !reflect.DeepEqual(status.Gateways, want) {
+		t.Errorf("expected gateways %v, got %v", want, status.Gateways)
+	}
+	if want := []v1alpha1.ClusterTLSPolicyOverride{{Gateway: "green/prod-web", Policy: "TLSPolicy green/prod-web-tls"}}; !reflect.DeepEqual(status.Overridden, want) {
+		t.Errorf("expected overridden %v, got %v", want, status.Overridden)
+	}
+	generated := generatedPolicy("default-tls-prod-web", "blue")
+	if generated == nil {
+		t.Fatalf("expected a TLSPolicy to be created for blue/prod-web")
+	}
+	if generated.Labels[ClusterTLSPolicyLabel] != "default-tls" || !metav1.IsControlledBy(generated, defaultTLS) {
+		t.Errorf("expected the TLSPolicy to be owned by the cluster policy, got %v", generated.ObjectMeta)
+	}
+	if generated.Spec.TargetRef.Name != "prod-web" || generated.Spec.IssuerRef.Name != "letsencrypt" {
+		t.Errorf("expected the TLSPolicy to target prod-web with the cluster policy issuer, got %v", generated.Spec)
+	}
+	for _, ns := range []string{"green", "dev"} {
+		if generatedPolicy("default-tls-prod-web", ns) != nil {
+			t.Errorf("expected no TLSPolicy to be created in %s", ns)
+		}
+	}
+
+	// a namespace-scoped policy selecting the blue gateway takes over from the generated policy
+	blueSelector := testutil.NewTestTLSPolicy("edge-tls", "blue").
+		WithTargetGateway("other").
+		WithTargetSelector(edge).
+		WithIssuer("internal-ca", "Issuer", "cert-manager.io").TLSPolicy
+	if err := f.Create(ctx, blueSelector); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	status = reconcile("default-tls").Status
+	if len(status.Gateways) != 0 || len(status.Overridden) != 2 {
+		t.Errorf("expected both gateways to be overridden, got gateways %v and overridden %v", status.Gateways, status.Overridden)
+	}
+	if generatedPolicy("default-tls-prod-web", "blue") != nil {
+		t.Errorf("expected the TLSPolicy of the overridden gateway to be deleted")
+	}
+
+	// the cluster policy with the lower name takes precedence
+	if err := f.Delete(ctx, blueSelector); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := f.Create(ctx, clusterPolicy("a-tls", nil, edge)); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	status = reconcile("default-tls").Status
+	if want := []v1alpha1.ClusterTLSPolicyOverride{
+		{Gateway: "blue/prod-web", Policy: "ClusterTLSPolicy a-tls"},
+		{Gateway: "green/prod-web", Policy: "TLSPolicy green/prod-web-tls"},
+	}; len(status.Gateways) != 0 || !reflect.DeepEqual(status.Overridden, want) {
+		t.Errorf("expected overridden %v, got gateways %v and overridden %v", want, status.Gateways, status.Overridden)
+	}
+	status = reconcile("a-tls").Status
+	if want := []string{"blue/prod-web", "dev/prod-web"}; !reflect.DeepEqual(status.Gateways, want) {
+		t.Errorf("expected gateways %v, got %v", want, status.Gateways)
+	}
+	if generatedPolicy("a-tls-prod-web", "dev") == nil {
+		t.Errorf("expected a TLSPolicy to be created for dev/prod-web")
+	}
+}
+
+func TestClusterTLSPolicyReconciler_conflict(t *testing.T) {
+	clusterPolicy := &v1alpha1.ClusterTLSPolicy{ObjectMeta: metav1.ObjectMeta{Name: "default-tls"}}
+	clusterPolicy.Spec.IssuerRef = cmmeta.ObjectReference{Name: "letsencrypt", Kind: "ClusterIssuer", Group: "cert-manager.io"}
+	// a policy with the name of the generated policy that does not target the gateway
+	existing := testutil.NewTestTLSPolicy("default-tls-prod-web", "blue").
+		WithTargetHTTPRoute("prod-web").
+		WithIssuer("internal-ca", "Issuer", "cert-manager.io").TLSPolicy
+
+	f := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "blue"}},
+		testutil.NewTestGateway("prod-web", "istio", "blue").Gateway,
+		clusterPolicy, existing,
+	).Build()
+	r := &ClusterTLSPolicyReconciler{Client: f, Scheme: testutil.GetValidTestScheme()}
+	ctx := context.TODO()
+
+	if _, err := r.Reconcile(ctx, testutil.BuildValidTestRequest("default-tls", "")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(clusterPolicy), clusterPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cond := meta.FindStatusCondition(clusterPolicy.Status.Conditions, string(conditions.ConditionTypeReady))
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != string(conditions.PolicyReasonConflicted) {
+		t.Errorf("expected a conflicted ready condition, got %v", cond)
+	}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(existing), existing); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if existing.Spec.IssuerRef.Name != "internal-ca" {
+		t.Errorf("expected the existing TLSPolicy to be left as is, got %v", existing.Spec)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/health"
@@ -33,6 +34,7 @@ type DNSHealthCheckProbeReconciler struct {
 	client.Client
 	HealthMonitor *health.Monitor
 	Queue         *health.QueuedProbeWorker
+	controller.ReconcilerOptions
 }
 
 // +kubebuilder:rbac:groups=kuadrant.io,resources=dnshealthcheckprobes,verbs=get;list;watch;create;update;patch;delete
@@ -95,7 +97,7 @@ func (r *DNSHealthCheckProbeReconciler) Reconcile(ctx context.Context, req ctrl.
 		f := false
 		logger.V(1).Info(
 			"error getting additional headers for probe",
-			"secret name", probeObj.Spec.AdditionalHeadersRef.Name,
+			"secret ref", probeObj.Spec.AdditionalHeadersRef,
 			"error", err)
 		//update probe status
 		probeObj.Status.Healthy = &f
@@ -118,6 +120,8 @@ func (r *DNSHealthCheckProbeReconciler) Reconcile(ctx context.Context, req ctrl.
 			p.AdditionalHeaders = additionalHeaders
 			p.ExpectedResponses = probeObj.Spec.ExpectedResponses
 			p.AllowInsecureCertificate = probeObj.Spec.AllowInsecureCertificate
+			p.TLSServerName = probeObj.Spec.TLSServerName
+			p.ExpectedBody = probeObj.Spec.ExpectedBody
 		})
 	} else {
 		notifier, err := r.newProbeNotifierFor(ctx, logger, previous)
@@ -136,6 +140,8 @@ func (r *DNSHealthCheckProbeReconciler) Reconcile(ctx context.Context, req ctrl.
 			AdditionalHeaders:        additionalHeaders,
 			ExpectedResponses:        probeObj.Spec.ExpectedResponses,
 			AllowInsecureCertificate: probeObj.Spec.AllowInsecureCertificate,
+			TLSServerName:            probeObj.Spec.TLSServerName,
+			ExpectedBody:             probeObj.Spec.ExpectedBody,
 			Notifier:                 notifier,
 			Queue:                    r.Queue,
 		})
@@ -148,7 +154,7 @@ func (r *DNSHealthCheckProbeReconciler) Reconcile(ctx context.Context, req ctrl.
 func (r *DNSHealthCheckProbeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.DNSHealthCheckProbe{}).
-		Complete(r)
+		Complete(r.WrapReconciler(r))
 }
 
 func (r *DNSHealthCheckProbeReconciler) deleteProbe(probeObj *v1alpha1.DNSHealthCheckProbe) {
@@ -159,9 +165,23 @@ func probeId(probeObj *v1alpha1.DNSHealthCheckProbe) string {
 	return fmt.Sprintf("%s/%s", probeObj.Namespace, probeObj.Name)
 }
 
+// getAdditionalHeaders returns the inline additional headers of the probe followed by the headers read from the
+// additional headers secret
 func getAdditionalHeaders(ctx context.Context, clt client.Client, probeObj *v1alpha1.DNSHealthCheckProbe) (v1alpha1.AdditionalHeaders, error) {
 	additionalHeaders := v1alpha1.AdditionalHeaders{}
 
+	for _, h := range probeObj.Spec.AdditionalHeaders {
+		if strings.ContainsAny(strings.TrimSpace(h.Name), " \t") {
+			probeObj.Status.ConsecutiveFailures = 0
+			probeObj.Status.Reason = "invalid header found: " + h.Name
+			return nil, fmt.Errorf("invalid header, must not contain whitespace '%v': %w", h.Name, ErrInvalidHeader)
+		}
+		additionalHeaders = append(additionalHeaders, v1alpha1.AdditionalHeader{
+			Name:  strings.TrimSpace(h.Name),
+			Value: h.Value,
+		})
+	}
+
 	if probeObj.Spec.AdditionalHeadersRef != nil {
 		secretKey := client.ObjectKey{Name: probeObj.Spec.AdditionalHeadersRef.Name, Namespace: probeObj.Namespace}
 		additionalHeadersSecret := &v1.Secret{}
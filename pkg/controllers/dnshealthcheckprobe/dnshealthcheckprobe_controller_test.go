@@ -3,6 +3,7 @@ package dnshealthcheckprobe
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
@@ -224,6 +225,72 @@ func TestGetAdditionalHeaders(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "combines inline headers with headers from secret",
+			Secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "probe-headers",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"Authorization": []byte("Bearer token"),
+				},
+				Type: "Opaque",
+			},
+			Probe: &v1alpha1.DNSHealthCheckProbe{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "probe",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.DNSHealthCheckProbeSpec{
+					AdditionalHeaders: v1alpha1.AdditionalHeaders{
+						{Name: "Host", Value: "app.example.com"},
+					},
+					AdditionalHeadersRef: &v1alpha1.AdditionalHeadersRef{
+						Name: "probe-headers",
+					},
+				},
+			},
+			Verify: func(t *testing.T, headers v1alpha1.AdditionalHeaders, err error) {
+				if err != nil {
+					t.Fatalf("expected no error, got: %s", err)
+				}
+
+				expectedHeaders := v1alpha1.AdditionalHeaders{
+					{Name: "Host", Value: "app.example.com"},
+					{Name: "Authorization", Value: "Bearer token"},
+				}
+				if !reflect.DeepEqual(headers, expectedHeaders) {
+					t.Fatalf("expected headers %+v, got %+v", expectedHeaders, headers)
+				}
+			},
+		},
+		{
+			name: "fails when inline header contains untrimmable whitespace",
+			Secret: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "probe-headers",
+					Namespace: "default",
+				},
+				Type: "Opaque",
+			},
+			Probe: &v1alpha1.DNSHealthCheckProbe{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "probe",
+					Namespace: "default",
+				},
+				Spec: v1alpha1.DNSHealthCheckProbeSpec{
+					AdditionalHeaders: v1alpha1.AdditionalHeaders{
+						{Name: "header 1", Value: "value"},
+					},
+				},
+			},
+			Verify: func(t *testing.T, headers v1alpha1.AdditionalHeaders, err error) {
+				if !errors.Is(err, ErrInvalidHeader) {
+					t.Fatalf("expected Invalid header error, got: %s", err)
+				}
+			},
+		},
 		{
 			name: "ignores secret in other namespace",
 			Secret: &v1.Secret{
@@ -33,28 +33,7 @@ func (n StatusUpdateProbeNotifier) Notify(ctx context.Context, result health.Pro
 		return health.NotificationResult{}, err
 	}
 
-	// Increase the number of consecutive failures if it failed previously
-	if !result.Healthy {
-		probeHealthy := true
-		if probeObj.Status.Healthy != nil {
-			probeHealthy = *probeObj.Status.Healthy
-		}
-		if probeHealthy {
-			probeObj.Status.ConsecutiveFailures = 1
-		} else {
-			probeObj.Status.ConsecutiveFailures++
-		}
-	} else {
-		probeObj.Status.ConsecutiveFailures = 0
-	}
-
-	probeObj.Status.LastCheckedAt = metav1.NewTime(result.CheckedAt)
-	if probeObj.Status.Healthy == nil {
-		probeObj.Status.Healthy = aws.Bool(true)
-	}
-	probeObj.Status.Healthy = &result.Healthy
-	probeObj.Status.Reason = result.Reason
-	probeObj.Status.Status = result.Status
+	applyProbeResult(probeObj, result)
 
 	if err := n.apiClient.Status().Update(ctx, probeObj); err != nil {
 		if errors.IsConflict(err) {
@@ -66,3 +45,40 @@ func (n StatusUpdateProbeNotifier) Notify(ctx context.Context, result health.Pro
 
 	return health.NotificationResult{}, nil
 }
+
+// applyProbeResult updates the status of the probe with the result of a health check. The probe only becomes
+// unhealthy after failureThreshold consecutive failures, and only becomes healthy again after successThreshold
+// consecutive successes. Both thresholds default to 1.
+func applyProbeResult(probeObj *v1alpha1.DNSHealthCheckProbe, result health.ProbeResult) {
+	failureThreshold := 1
+	if probeObj.Spec.FailureThreshold != nil {
+		failureThreshold = *probeObj.Spec.FailureThreshold
+	}
+	successThreshold := 1
+	if probeObj.Spec.SuccessThreshold != nil {
+		successThreshold = *probeObj.Spec.SuccessThreshold
+	}
+
+	if probeObj.Status.Healthy == nil {
+		probeObj.Status.Healthy = aws.Bool(true)
+	}
+
+	if result.Healthy {
+		probeObj.Status.ConsecutiveFailures = 0
+		probeObj.Status.ConsecutiveSuccesses++
+		if probeObj.Status.ConsecutiveSuccesses >= successThreshold {
+			probeObj.Status.Healthy = aws.Bool(true)
+		}
+	} else {
+		probeObj.Status.ConsecutiveSuccesses = 0
+		probeObj.Status.ConsecutiveFailures++
+		if probeObj.Status.ConsecutiveFailures >= failureThreshold {
+			probeObj.Status.Healthy = aws.Bool(false)
+		}
+	}
+
+	probeObj.Status.LastCheckedAt = metav1.NewTime(result.CheckedAt)
+	probeObj.Status.Reason = result.Reason
+	probeObj.Status.Status = result.Status
+	probeObj.Status.FailureType = result.FailureType
+}
@@ -0,0 +1,89 @@
+package dnshealthcheckprobe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/health"
+)
+
+func TestApplyProbeResult(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		spec                     v1alpha1.DNSHealthCheckProbeSpec
+		results                  []bool
+		wantHealthy              bool
+		wantConsecutiveFailures  int
+		wantConsecutiveSuccesses int
+	}{
+		{
+			name:                     "healthy after a successful check",
+			results:                  []bool{true},
+			wantHealthy:              true,
+			wantConsecutiveSuccesses: 1,
+		},
+		{
+			name:                    "unhealthy after a single failure by default",
+			results:                 []bool{false},
+			wantHealthy:             false,
+			wantConsecutiveFailures: 1,
+		},
+		{
+			name:                    "stays healthy below the failure threshold",
+			spec:                    v1alpha1.DNSHealthCheckProbeSpec{FailureThreshold: aws.Int(3)},
+			results:                 []bool{true, false, false},
+			wantHealthy:             true,
+			wantConsecutiveFailures: 2,
+		},
+		{
+			name:                    "unhealthy at the failure threshold",
+			spec:                    v1alpha1.DNSHealthCheckProbeSpec{FailureThreshold: aws.Int(3)},
+			results:                 []bool{true, false, false, false},
+			wantHealthy:             false,
+			wantConsecutiveFailures: 3,
+		},
+		{
+			name:                    "a success resets the consecutive failures",
+			spec:                    v1alpha1.DNSHealthCheckProbeSpec{FailureThreshold: aws.Int(3)},
+			results:                 []bool{false, false, true, false, false},
+			wantHealthy:             true,
+			wantConsecutiveFailures: 2,
+		},
+		{
+			name:                     "stays unhealthy below the success threshold",
+			spec:                     v1alpha1.DNSHealthCheckProbeSpec{SuccessThreshold: aws.Int(2)},
+			results:                  []bool{false, true},
+			wantHealthy:              false,
+			wantConsecutiveSuccesses: 1,
+		},
+		{
+			name:                     "healthy at the success threshold",
+			spec:                     v1alpha1.DNSHealthCheckProbeSpec{SuccessThreshold: aws.Int(2)},
+			results:                  []bool{false, true, true},
+			wantHealthy:              true,
+			wantConsecutiveSuccesses: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			probeObj := &v1alpha1.DNSHealthCheckProbe{Spec: testCase.spec}
+			for _, healthy := range testCase.results {
+				applyProbeResult(probeObj, health.ProbeResult{CheckedAt: time.Now(), Healthy: healthy})
+			}
+
+			if probeObj.Status.Healthy == nil || *probeObj.Status.Healthy != testCase.wantHealthy {
+				t.Errorf("expected healthy %v, got %v", testCase.wantHealthy, aws.BoolValue(probeObj.Status.Healthy))
+			}
+			if probeObj.Status.ConsecutiveFailures != testCase.wantConsecutiveFailures {
+				t.Errorf("expected %d consecutive failures, got %d", testCase.wantConsecutiveFailures, probeObj.Status.ConsecutiveFailures)
+			}
+			if probeObj.Status.ConsecutiveSuccesses != testCase.wantConsecutiveSuccesses {
+				t.Errorf("expected %d consecutive successes, got %d", testCase.wantConsecutiveSuccesses, probeObj.Status.ConsecutiveSuccesses)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package dnspolicy
 import (
 	"context"
 	"fmt"
+	"net"
 	"regexp"
 	"sort"
 	"strconv"
@@ -14,6 +15,7 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -21,6 +23,7 @@ import (
 
 	"github.com/kuadrant/kuadrant-operator/pkg/common"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
@@ -42,42 +45,52 @@ type dnsHelper struct {
 	client.Client
 }
 
-func findMatchingManagedZone(originalHost, host string, zones []v1alpha1.ManagedZone) (*v1alpha1.ManagedZone, string, error) {
+// findMatchingManagedZone returns the managed zone with the longest domain the host is a subdomain of, and the
+// subdomain of the host in that zone. When several zones have that domain, the first by namespace and name is
+// selected.
+func findMatchingManagedZone(host string, zones []v1alpha1.ManagedZone) (*v1alpha1.ManagedZone, string, error) {
 	if len(zones) == 0 {
 		return nil, "", fmt.Errorf("%w : %s", ErrNoManagedZoneForHost, host)
 	}
 	host = strings.ToLower(host)
-	//get the TLD from this host
-	tld, _ := publicsuffix.PublicSuffix(host)
 
-	//The host is a TLD, so we now know `originalHost` can't possibly have a valid `ManagedZone` available.
-	if host == tld {
-		return nil, "", fmt.Errorf("no valid zone found for host: %v", originalHost)
+	var matches []v1alpha1.ManagedZone
+	for _, zone := range zones {
+		domain := managedZoneDomain(zone)
+		// We do not currently support creating records for Apex domains, and a ManagedZone represents an Apex domain, as such
+		// a zone only matches the subdomains of its domain. A zone of a TLD, e.g. co.uk, never matches.
+		if !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if tld, _ := publicsuffix.PublicSuffix(domain); tld == domain {
+			continue
+		}
+		matches = append(matches, zone)
 	}
-
-	hostParts := strings.SplitN(host, ".", 2)
-	if len(hostParts) < 2 {
-		return nil, "", fmt.Errorf("no valid zone found for host: %s", originalHost)
+	if len(matches) == 0 {
+		return nil, "", fmt.Errorf("%w : no valid zone found for host: %s", ErrNoManagedZoneForHost, host)
 	}
-	parentDomain := hostParts[1]
 
-	// We do not currently support creating records for Apex domains, and a ManagedZone represents an Apex domain, as such
-	// we should never be trying to find a managed zone that matches the `originalHost` exactly. Instead, we just continue
-	// on to the next possible valid host to try i.e. the parent domain.
-	if host == originalHost {
-		return findMatchingManagedZone(originalHost, parentDomain, zones)
-	}
-
-	zone, ok := slice.Find(zones, func(zone v1alpha1.ManagedZone) bool {
-		return strings.ToLower(zone.Spec.DomainName) == host
+	sort.SliceStable(matches, func(i, j int) bool {
+		di, dj := managedZoneDomain(matches[i]), managedZoneDomain(matches[j])
+		if len(di) != len(dj) {
+			return len(di) > len(dj)
+		}
+		if matches[i].Namespace != matches[j].Namespace {
+			return matches[i].Namespace < matches[j].Namespace
+		}
+		return matches[i].Name < matches[j].Name
 	})
-
-	if ok {
-		subdomain := strings.Replace(strings.ToLower(originalHost), "."+strings.ToLower(zone.Spec.DomainName), "", 1)
-		return &zone, subdomain, nil
+	zone := matches[0]
+	domain := managedZoneDomain(zone)
+	if len(matches) > 1 && managedZoneDomain(matches[1]) == domain {
+		log.Log.Info("multiple managed zones match host, selecting the first by namespace and name", "host", host, "domain", domain, "managedZone", zone.Name)
 	}
-	return findMatchingManagedZone(originalHost, parentDomain, zones)
+	return &zone, strings.TrimSuffix(host, "."+domain), nil
+}
 
+func managedZoneDomain(zone v1alpha1.ManagedZone) string {
+	return strings.ToLower(strings.TrimSuffix(zone.Spec.DomainName, "."))
 }
 
 func commonDNSRecordLabels(gwKey, apKey client.ObjectKey) map[string]string {
@@ -147,7 +160,9 @@ func withGatewayListener[T metav1.Object](gateway common.GatewayWrapper, listene
 // specific host.
 // A CNAME record for the geo specific host is created for every Geo, with weight information for that target added,
 // pointing to a target cluster hostname.
-// An A record for the target cluster hostname is created for any IP targets retrieved for that cluster.
+// An A record for the target cluster hostname is created for any IPv4 targets retrieved for that cluster, and an AAAA
+// record for any IPv6 targets. A cluster reporting several addresses gets a single record set holding all of them, so
+// that resolvers round-robin between them.
 //
 // Example(Weighted only)
 //
@@ -170,20 +185,82 @@ func withGatewayListener[T metav1.Object](gateway common.GatewayWrapper, listene
 // ab1.lb-a1b2.shop.example.com A 192.22.2.1 192.22.2.5
 // ab2.lb-a1b2.shop.example.com A 192.22.2.3
 // ab3.lb-a1b2.shop.example.com A 192.22.2.4
+//
+// Example(Latency, all clusters with a region)
+//
+// www.example.com CNAME lb-1ab1.www.example.com
+// lb-1ab1.www.example.com CNAME geolocation * default.lb-1ab1.www.example.com
+// default.lb-1ab1.www.example.com CNAME latency eu-west-1 1bc1.lb-1ab1.www.example.com
+// default.lb-1ab1.www.example.com CNAME latency us-east-1 aws.lb.com
+// 1bc1.lb-1ab1.www.example.com A 192.22.2.1
+//
+// Example(Geoproximity, all clusters with a location)
+//
+// www.example.com CNAME lb-1ab1.www.example.com
+// lb-1ab1.www.example.com CNAME geolocation * default.lb-1ab1.www.example.com
+// default.lb-1ab1.www.example.com CNAME geoproximity 53.35,-6.26 bias 20 1bc1.lb-1ab1.www.example.com
+// default.lb-1ab1.www.example.com CNAME geoproximity us-east-1 bias 0 aws.lb.com
+// 1bc1.lb-1ab1.www.example.com A 192.22.2.1
 
-func (dh *dnsHelper) setEndpoints(ctx context.Context, mcgTarget *dns.MultiClusterGatewayTarget, dnsRecord *v1alpha1.DNSRecord, dnsPolicy *v1alpha1.DNSPolicy, listener gatewayv1beta1.Listener) error {
+// setRoutingPolicy sets the properties of the routing policy in use on the endpoint of a cluster target. Existing
+// endpoints are reused so the properties of the routing policies not in use are removed.
+func setRoutingPolicy(endpoint *v1alpha1.Endpoint, mcgTarget *dns.MultiClusterGatewayTarget, cgwTarget dns.ClusterGatewayTarget) {
+	properties := map[string]string{}
+	switch {
+	case mcgTarget.IsGeoproximityRouting():
+		// the coordinates of a cluster take precedence over its region
+		if latitude, longitude, ok := cgwTarget.GetCoordinates(); ok {
+			properties[dns.ProviderSpecificGeoproximityCoordinates] = latitude + "," + longitude
+		} else {
+			properties[dns.ProviderSpecificGeoproximityRegion] = cgwTarget.GetRegion()
+		}
+		properties[dns.ProviderSpecificGeoproximityBias] = strconv.Itoa(cgwTarget.GetBias())
+	case mcgTarget.IsLatencyRouting():
+		properties[dns.ProviderSpecificRegion] = cgwTarget.GetRegion()
+	default:
+		properties[dns.ProviderSpecificWeight] = strconv.Itoa(cgwTarget.GetWeight())
+	}
+	for _, name := range []string{dns.ProviderSpecificWeight, dns.ProviderSpecificRegion, dns.ProviderSpecificGeoproximityRegion, dns.ProviderSpecificGeoproximityCoordinates, dns.ProviderSpecificGeoproximityBias} {
+		if value, ok := properties[name]; ok {
+			endpoint.SetProviderSpecific(name, value)
+		} else {
+			endpoint.DeleteProviderSpecific(name)
+		}
+	}
+}
+
+// setEndpoints removes endpoints whose health check probes are unhealthy. If that leaves no endpoints the full set is
+// published instead and allUnhealthy is returned as true. The endpoints of the clusters no longer targeted are
+// drained by the drain when it is not nil.
+func (dh *dnsHelper) setEndpoints(ctx context.Context, mcgTarget *dns.MultiClusterGatewayTarget, dnsRecord *v1alpha1.DNSRecord, dnsPolicy *v1alpha1.DNSPolicy, listener gatewayv1beta1.Listener, drain *endpointDrain) (allUnhealthy bool, err error) {
 
 	old := dnsRecord.DeepCopy()
-	gwListenerHost := string(*listener.Hostname)
+	// the records are published under the host override of the listener if any
+	gwListenerHost, _ := dnsPolicy.GetHostOverride(string(*listener.Hostname))
+	setDNSRecordHost(dnsRecord, gwListenerHost)
+	metadata.CopyAnnotation(dnsPolicy, dnsRecord, metadata.ForceSyncAnnotation)
+	// the DNS provider checks the health of the clusters itself when provider health checks are enabled
+	dnsRecord.Spec.HealthCheck = dnsPolicy.Spec.HealthCheck.ProviderHealthCheck()
 	cnameHost := gwListenerHost
-	if isWildCardListener(listener) {
+	if strings.HasPrefix(gwListenerHost, "*") {
 		cnameHost = strings.Replace(gwListenerHost, "*.", "", -1)
 	}
 
+	// the load balancing is delegated to the CNAME target, the listener host is its only record
+	if dnsPolicy.Spec.DelegateToCNAME != nil {
+		dnsRecord.Spec.Endpoints = []*v1alpha1.Endpoint{
+			createOrUpdateEndpoint(gwListenerHost, []string{dnsPolicy.Spec.DelegateToCNAME.Target}, v1alpha1.CNAMERecordType, "", dns.DefaultCnameTTL, nil),
+		}
+		if !equality.Semantic.DeepEqual(old, dnsRecord) {
+			return false, dh.Update(ctx, dnsRecord)
+		}
+		return false, nil
+	}
+
 	//Health Checks currently modify endpoints so we have to keep existing ones in order to not lose health check ids
 	currentEndpoints := make(map[string]*v1alpha1.Endpoint, len(dnsRecord.Spec.Endpoints))
 	for _, endpoint := range dnsRecord.Spec.Endpoints {
-		currentEndpoints[endpoint.SetID()] = endpoint
+		currentEndpoints[endpointKey(endpoint.DNSName, endpoint.SetIdentifier, v1alpha1.DNSRecordType(endpoint.RecordType))] = endpoint
 	}
 
 	var (
@@ -197,26 +274,48 @@ func (dh *dnsHelper) setEndpoints(ctx context.Context, mcgTarget *dns.MultiClust
 		geoLbName := strings.ToLower(fmt.Sprintf("%s.%s", geoCode, lbName))
 		var clusterEndpoints []*v1alpha1.Endpoint
 		for _, cgwTarget := range cgwTargets {
-			var ipValues []string
+			var ipv4Values []string
+			var ipv6Values []string
 			var hostValues []string
 			for _, gwa := range cgwTarget.GatewayAddresses {
-				if *gwa.Type == gatewayv1beta1.IPAddressType {
-					ipValues = append(ipValues, gwa.Value)
-				} else {
+				if *gwa.Type != gatewayv1beta1.IPAddressType {
 					hostValues = append(hostValues, gwa.Value)
+				} else if isIPv6(gwa.Value) {
+					ipv6Values = append(ipv6Values, gwa.Value)
+				} else {
+					ipv4Values = append(ipv4Values, gwa.Value)
 				}
 			}
 
-			if len(ipValues) > 0 {
+			// a dual stack cluster has both an A and an AAAA record for its hostname
+			if len(ipv4Values) > 0 || len(ipv6Values) > 0 {
+				// the addresses are ordered so that the status of the gateway reporting them in another order does not
+				// update the record set
+				ipv4Values, ipv6Values = sets.List(sets.New(ipv4Values...)), sets.List(sets.New(ipv6Values...))
 				clusterLbName := strings.ToLower(fmt.Sprintf("%s.%s", cgwTarget.GetShortCode(), lbName))
-				endpoint = createOrUpdateEndpoint(clusterLbName, ipValues, v1alpha1.ARecordType, "", dns.DefaultTTL, currentEndpoints)
-				clusterEndpoints = append(clusterEndpoints, endpoint)
+				if len(ipv4Values) > 0 {
+					endpoint = createOrUpdateEndpoint(clusterLbName, ipv4Values, v1alpha1.ARecordType, "", dns.DefaultTTL, currentEndpoints)
+					clusterEndpoints = append(clusterEndpoints, endpoint)
+				}
+				if len(ipv6Values) > 0 {
+					endpoint = createOrUpdateEndpoint(clusterLbName, ipv6Values, v1alpha1.AAAARecordType, "", dns.DefaultTTL, currentEndpoints)
+					clusterEndpoints = append(clusterEndpoints, endpoint)
+				}
 				hostValues = append(hostValues, clusterLbName)
 			}
 
+			// the name rendered from the record name template points at the gateway of the cluster only, through the
+			// cluster record of its addresses or else its hostname
+			if clusterRecordName, err := dnsPolicy.RenderRecordName(cgwTarget.GetName(), mcgTarget.Gateway.Name, string(listener.Name), gwListenerHost); err != nil {
+				return false, err
+			} else if clusterRecordName != "" && len(hostValues) > 0 {
+				endpoint = createOrUpdateEndpoint(clusterRecordName, []string{hostValues[len(hostValues)-1]}, v1alpha1.CNAMERecordType, "", dns.DefaultCnameTTL, currentEndpoints)
+				clusterEndpoints = append(clusterEndpoints, endpoint)
+			}
+
 			for _, hostValue := range hostValues {
 				endpoint = createOrUpdateEndpoint(geoLbName, []string{hostValue}, v1alpha1.CNAMERecordType, hostValue, dns.DefaultTTL, currentEndpoints)
-				endpoint.SetProviderSpecific(dns.ProviderSpecificWeight, strconv.Itoa(cgwTarget.GetWeight()))
+				setRoutingPolicy(endpoint, mcgTarget, cgwTarget)
 				clusterEndpoints = append(clusterEndpoints, endpoint)
 			}
 		}
@@ -252,13 +351,14 @@ func (dh *dnsHelper) setEndpoints(ctx context.Context, mcgTarget *dns.MultiClust
 		newEndpoints = append(newEndpoints, endpoint)
 	}
 
-	sort.Slice(newEndpoints, func(i, j int) bool {
-		return newEndpoints[i].SetID() < newEndpoints[j].SetID()
-	})
+	if drain != nil {
+		newEndpoints = drain.apply(newEndpoints, old.Spec.Endpoints)
+	}
+	sortEndpoints(newEndpoints)
 
 	probes, err := dh.getDNSHealthCheckProbes(ctx, mcgTarget.Gateway, dnsPolicy)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// if the checks on endpoints based on probes results in there being no healthy endpoints
@@ -274,19 +374,32 @@ func (dh *dnsHelper) setEndpoints(ctx context.Context, mcgTarget *dns.MultiClust
 		if len(checkProbes) == 0 {
 			continue
 		}
+		unhealthy := sets.New[string]()
 		for _, probe := range checkProbes {
-			probeHealthy := true
-			if probe.Status.Healthy != nil {
-				probeHealthy = *probe.Status.Healthy
+			if isProbeUnhealthy(probe) {
+				unhealthy.Insert(probe.Name)
 			}
-			// if any probe for any target is reporting unhealthy remove it from the endpoint list
-			if !probeHealthy && probe.Spec.FailureThreshold != nil && probe.Status.ConsecutiveFailures >= *probe.Spec.FailureThreshold {
-				newEndpoints = append(newEndpoints[:i], newEndpoints[i+1:]...)
-				removedEndpoints++
-				i--
-				break
+		}
+		if unhealthy.Len() == 0 {
+			continue
+		}
+		// the unhealthy addresses of a round-robin record set are removed from it, the endpoint is removed once none
+		// of its targets are healthy. The endpoint is copied as the full set is kept in storeEndpoints.
+		var healthyTargets v1alpha1.Targets
+		for _, target := range newEndpoints[i].Targets {
+			if !unhealthy.Has(dnsHealthCheckProbeName(target, mcgTarget.Gateway.Name, string(listener.Name))) {
+				healthyTargets = append(healthyTargets, target)
 			}
 		}
+		if len(healthyTargets) > 0 {
+			endpoint = newEndpoints[i].DeepCopy()
+			endpoint.Targets = healthyTargets
+			newEndpoints[i] = endpoint
+			continue
+		}
+		newEndpoints = append(newEndpoints[:i], newEndpoints[i+1:]...)
+		removedEndpoints++
+		i--
 	}
 	// after checkProbes are checked the newEndpoints is looped through until count is 0
 	// if any are found that need to be removed because a parent with no children present
@@ -300,7 +413,7 @@ func (dh *dnsHelper) setEndpoints(ctx context.Context, mcgTarget *dns.MultiClust
 		for i := 0; i < len(newEndpoints); i++ {
 			checkEndpoint := newEndpoints[i]
 			for _, target := range checkEndpoint.Targets {
-				if len(re.FindAllString(target, -1)) > 0 {
+				if len(re.FindAllString(target, -1)) > 0 || isIPv6(target) {
 					// don't check the children of targets which are ips.
 					continue endpointsLoop
 				}
@@ -314,16 +427,84 @@ func (dh *dnsHelper) setEndpoints(ctx context.Context, mcgTarget *dns.MultiClust
 		removedEndpoints--
 	}
 
+	newEndpoints = setGeoFallbacks(newEndpoints, storeEndpoints, lbName, mcgTarget.GetGeoFallback())
+
 	// if there are no healthy endpoints after checking, publish the full set before checks
+	allUnhealthy = len(newEndpoints) == 0 && len(storeEndpoints) > 0
 	if len(newEndpoints) == 0 {
 		dnsRecord.Spec.Endpoints = storeEndpoints
 	} else {
 		dnsRecord.Spec.Endpoints = newEndpoints
 	}
 	if !equality.Semantic.DeepEqual(old, dnsRecord) {
-		return dh.Update(ctx, dnsRecord)
+		return allUnhealthy, dh.Update(ctx, dnsRecord)
 	}
-	return nil
+	return allUnhealthy, nil
+}
+
+// setGeoFallbacks publishes again the geo records of lbName removed as their geo has no healthy cluster, pointing at
+// the geo of the first fallback of the geo that still has a healthy cluster. A geo record without such a fallback is
+// left out, so that the traffic of the geo goes to the default geo. The default geo record, pointing at the default
+// geo, falls back the same way.
+func setGeoFallbacks(endpoints, allEndpoints []*v1alpha1.Endpoint, lbName string, geoFallback map[string][]string) []*v1alpha1.Endpoint {
+	if len(geoFallback) == 0 || len(endpoints) == 0 {
+		return endpoints
+	}
+
+	published := sets.New[string]()
+	healthyGeos := map[string]*v1alpha1.Endpoint{}
+	for _, endpoint := range endpoints {
+		published.Insert(endpointKey(endpoint.DNSName, endpoint.SetIdentifier, v1alpha1.DNSRecordType(endpoint.RecordType)))
+		if endpoint.DNSName == lbName && endpoint.SetIdentifier != "default" {
+			healthyGeos[endpoint.SetIdentifier] = endpoint
+		}
+	}
+	// the geo code of each geo host, read from the geo records published before the health checks
+	geoCodes := map[string]string{}
+	for _, endpoint := range allEndpoints {
+		if endpoint.DNSName == lbName && endpoint.SetIdentifier != "default" && len(endpoint.Targets) == 1 {
+			geoCodes[endpoint.Targets[0]] = endpoint.SetIdentifier
+		}
+	}
+
+	for _, endpoint := range allEndpoints {
+		if endpoint.DNSName != lbName || len(endpoint.Targets) != 1 ||
+			published.Has(endpointKey(endpoint.DNSName, endpoint.SetIdentifier, v1alpha1.DNSRecordType(endpoint.RecordType))) {
+			continue
+		}
+		for _, fallback := range geoFallback[geoCodes[endpoint.Targets[0]]] {
+			if healthyGeo, ok := healthyGeos[fallback]; ok {
+				endpoint = endpoint.DeepCopy()
+				endpoint.Targets = append(v1alpha1.Targets{}, healthyGeo.Targets...)
+				endpoints = append(endpoints, endpoint)
+				break
+			}
+		}
+	}
+	sortEndpoints(endpoints)
+	return endpoints
+}
+
+// sortEndpoints orders the endpoints by set identifier and record type, so that the endpoints of a DNSRecord are only
+// updated when they change
+func sortEndpoints(endpoints []*v1alpha1.Endpoint) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].SetID() != endpoints[j].SetID() {
+			return endpoints[i].SetID() < endpoints[j].SetID()
+		}
+		return endpoints[i].RecordType < endpoints[j].RecordType
+	})
+}
+
+// isProbeUnhealthy returns true if the probe reports unhealthy past its failure threshold. A probe that is recovering
+// stays unhealthy until it reaches its success threshold and reports healthy.
+func isProbeUnhealthy(probe *v1alpha1.DNSHealthCheckProbe) bool {
+	probeHealthy := true
+	if probe.Status.Healthy != nil {
+		probeHealthy = *probe.Status.Healthy
+	}
+	recovering := probe.Status.ConsecutiveSuccesses > 0
+	return !probeHealthy && probe.Spec.FailureThreshold != nil && (probe.Status.ConsecutiveFailures >= *probe.Spec.FailureThreshold || recovering)
 }
 
 func getNumChildrenOfParent(endpoints []*v1alpha1.Endpoint, parent *v1alpha1.Endpoint) int {
@@ -342,10 +523,22 @@ func findChildren(endpoints []*v1alpha1.Endpoint, parent *v1alpha1.Endpoint) []*
 	return foundEPs
 }
 
+// endpointKey identifies an endpoint among the endpoints of a DNSRecord, the A and AAAA endpoints of a dual stack
+// cluster share their name and set identifier
+func endpointKey(dnsName, setIdentifier string, recordType v1alpha1.DNSRecordType) string {
+	return dnsName + setIdentifier + "/" + string(recordType)
+}
+
+// isIPv6 returns true if the value is an IPv6 address
+func isIPv6(value string) bool {
+	ip := net.ParseIP(value)
+	return ip != nil && ip.To4() == nil
+}
+
 func createOrUpdateEndpoint(dnsName string, targets v1alpha1.Targets, recordType v1alpha1.DNSRecordType, setIdentifier string,
 	recordTTL v1alpha1.TTL, currentEndpoints map[string]*v1alpha1.Endpoint) (endpoint *v1alpha1.Endpoint) {
 	ok := false
-	endpointID := dnsName + setIdentifier
+	endpointID := endpointKey(dnsName, setIdentifier, recordType)
 	if endpoint, ok = currentEndpoints[endpointID]; !ok {
 		endpoint = &v1alpha1.Endpoint{}
 		if setIdentifier != "" {
@@ -371,6 +564,10 @@ func (r *dnsHelper) removeDNSForDeletedListeners(ctx context.Context, upstreamGa
 	}
 
 	for _, dns := range dnsList.Items {
+		// the static records are not published for a listener
+		if _, ok := dns.Labels[LabelStaticRecords]; ok {
+			continue
+		}
 		listenerExists := false
 		for _, listener := range upstreamGateway.Spec.Listeners {
 			if listener.Name == gatewayv1beta1.SectionName(dns.Labels[LabelListenerReference]) {
@@ -388,14 +585,19 @@ func (r *dnsHelper) removeDNSForDeletedListeners(ctx context.Context, upstreamGa
 
 }
 
-func (r *dnsHelper) getManagedZoneForListener(ctx context.Context, ns string, listener gatewayv1beta1.Listener) (*v1alpha1.ManagedZone, error) {
+// getManagedZoneForListener returns the managed zone of the host published for the listener, the listener hostname
+// or its host override in the policy
+func (r *dnsHelper) getManagedZoneForListener(ctx context.Context, ns string, listener gatewayv1beta1.Listener, dnsPolicy *v1alpha1.DNSPolicy) (*v1alpha1.ManagedZone, error) {
 	var managedZones v1alpha1.ManagedZoneList
 	if err := r.List(ctx, &managedZones, client.InNamespace(ns)); err != nil {
 		log.FromContext(ctx).Error(err, "unable to list managed zones for gateway ", "in ns", ns)
 		return nil, err
 	}
-	host := string(*listener.Hostname)
-	mz, _, err := findMatchingManagedZone(host, host, managedZones.Items)
+	host, overridden := dnsPolicy.GetHostOverride(string(*listener.Hostname))
+	mz, _, err := findMatchingManagedZone(host, managedZones.Items)
+	if err != nil && overridden {
+		return nil, fmt.Errorf("invalid host override %s for listener %s: %w", host, listener.Name, err)
+	}
 	return mz, err
 }
 
@@ -436,10 +638,6 @@ func (r *dnsHelper) deleteDNSRecordForListener(ctx context.Context, owner metav1
 	return r.Delete(ctx, &dnsRecord, &client.DeleteOptions{})
 }
 
-func isWildCardListener(l gatewayv1beta1.Listener) bool {
-	return strings.HasPrefix(string(*l.Hostname), "*")
-}
-
 func (dh *dnsHelper) getDNSHealthCheckProbes(ctx context.Context, gateway *gatewayv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy) ([]*v1alpha1.DNSHealthCheckProbe, error) {
 	list := &v1alpha1.DNSHealthCheckProbeList{}
 	if err := dh.List(ctx, list, &client.ListOptions{
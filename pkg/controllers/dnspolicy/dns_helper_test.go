@@ -4,6 +4,8 @@ package dnspolicy
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -16,8 +18,10 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
 	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
@@ -304,6 +308,113 @@ func Test_dnsHelper_findMatchingManagedZone(t *testing.T) {
 			},
 			Assert: assertSub("test.example.com", "sub.domain", ""),
 		},
+		{
+			name: "finds the longest matching managed zone whatever the order",
+			Host: "foo.bar.example.com",
+			Zones: []v1alpha1.ManagedZone{
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "bar.example.com",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "bar.example.com",
+					},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "example.com",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "example.com",
+					},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "foo.bar.example.com",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "foo.bar.example.com",
+					},
+				},
+			},
+			Assert: assertSub("bar.example.com", "foo", ""),
+		},
+		{
+			name: "does not match a zone sharing a suffix with the host",
+			Host: "foo.myexample.com",
+			Zones: []v1alpha1.ManagedZone{
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "example.com",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "example.com",
+					},
+				},
+			},
+			Assert: assertSub("", "", "no valid zone found"),
+		},
+		{
+			name: "selects the first managed zone by name when zones have the same domain",
+			Host: "foo.example.com",
+			Zones: []v1alpha1.ManagedZone{
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "zone-b",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "example.com",
+					},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "zone-a",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "Example.com.",
+					},
+				},
+			},
+			Assert: func(t *testing.T, zone *v1alpha1.ManagedZone, subdomain string, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if zone.Name != "zone-a" || subdomain != "foo" {
+					t.Errorf("expected managed zone zone-a with subdomain foo, got %s with subdomain %s", zone.Name, subdomain)
+				}
+			},
+		},
+		{
+			name: "matches a wildcard host",
+			Host: "*.test.example.com",
+			Zones: []v1alpha1.ManagedZone{
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "example.com",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "example.com",
+					},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "test.example.com",
+						Namespace: "test",
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						DomainName: "test.example.com",
+					},
+				},
+			},
+			Assert: assertSub("test.example.com", "*", ""),
+		},
 		{
 			name: "returns a single subdomain",
 			Host: "sub.test.example.com",
@@ -398,7 +509,7 @@ func Test_dnsHelper_findMatchingManagedZone(t *testing.T) {
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			mx, subDomain, err := findMatchingManagedZone(testCase.Host, testCase.Host, testCase.Zones)
+			mx, subDomain, err := findMatchingManagedZone(testCase.Host, testCase.Zones)
 			testCase.Assert(t, mx, subDomain, err)
 		})
 	}
@@ -407,15 +518,16 @@ func Test_dnsHelper_findMatchingManagedZone(t *testing.T) {
 func Test_dnsHelper_setEndpoints(t *testing.T) {
 
 	testCases := []struct {
-		name      string
-		mcgTarget *dns.MultiClusterGatewayTarget
-		listener  gatewayv1beta1.Listener
-		dnsRecord *v1alpha1.DNSRecord
-		dnsPolicy *v1alpha1.DNSPolicy
-		probeOne  *v1alpha1.DNSHealthCheckProbe
-		probeTwo  *v1alpha1.DNSHealthCheckProbe
-		wantSpec  *v1alpha1.DNSRecordSpec
-		wantErr   bool
+		name             string
+		mcgTarget        *dns.MultiClusterGatewayTarget
+		listener         gatewayv1beta1.Listener
+		dnsRecord        *v1alpha1.DNSRecord
+		dnsPolicy        *v1alpha1.DNSPolicy
+		probeOne         *v1alpha1.DNSHealthCheckProbe
+		probeTwo         *v1alpha1.DNSHealthCheckProbe
+		wantSpec         *v1alpha1.DNSRecordSpec
+		wantErr          bool
+		wantAllUnhealthy bool
 	}{
 		{
 			name:     "test wildcard listener weighted",
@@ -541,19 +653,24 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			},
 		},
 		{
-			name:     "sets geo weighted endpoints wildcard",
-			listener: getTestListener("*.example.com"),
+			name:     "sets latency endpoints",
+			listener: getTestListener("test.example.com"),
 			mcgTarget: &dns.MultiClusterGatewayTarget{
 				Gateway: &gatewayv1beta1.Gateway{
 					ObjectMeta: v1.ObjectMeta{Name: "testgw"},
 				},
+				LoadBalancing: &v1alpha1.LoadBalancingSpec{
+					Latency: &v1alpha1.LoadBalancingLatency{},
+				},
 				ClusterGatewayTargets: []dns.ClusterGatewayTarget{
 					{
-
 						ClusterGateway: &dns.ClusterGateway{
 							Cluster: &testutil.TestResource{
 								ObjectMeta: v1.ObjectMeta{
 									Name: "test-cluster-1",
+									Annotations: map[string]string{
+										dns.AnnotationLBAttributeRegion: "eu-west-1",
+									},
 								},
 							},
 							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
@@ -561,21 +678,19 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
 									Value: "1.1.1.1",
 								},
-								{
-									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
-									Value: "2.2.2.2",
-								},
 							},
 						},
-						Geo:    testutil.Pointer(dns.GeoCode("NA")),
+						Geo:    testutil.Pointer(dns.GeoCode("default")),
 						Weight: testutil.Pointer(120),
 					},
 					{
-
 						ClusterGateway: &dns.ClusterGateway{
 							Cluster: &testutil.TestResource{
 								ObjectMeta: v1.ObjectMeta{
 									Name: "test-cluster-2",
+									Annotations: map[string]string{
+										dns.AnnotationLBAttributeRegion: "us-east-1",
+									},
 								},
 							},
 							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
@@ -585,19 +700,14 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 								},
 							},
 						},
-						Geo:    testutil.Pointer(dns.GeoCode("IE")),
+						Geo:    testutil.Pointer(dns.GeoCode("default")),
 						Weight: testutil.Pointer(120),
 					},
 				},
-				LoadBalancing: &v1alpha1.LoadBalancingSpec{
-					Geo: &v1alpha1.LoadBalancingGeo{
-						DefaultGeo: "NA",
-					},
-				},
 			},
 			dnsRecord: &v1alpha1.DNSRecord{
 				ObjectMeta: v1.ObjectMeta{
-					Name: "gw-test",
+					Name: "test.example.com",
 				},
 			},
 			dnsPolicy: &v1alpha1.DNSPolicy{},
@@ -616,40 +726,40 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			wantSpec: &v1alpha1.DNSRecordSpec{
 				Endpoints: []*v1alpha1.Endpoint{
 					{
-						DNSName:    "20qri0.lb-ocnswx.example.com",
-						Targets:    []string{"1.1.1.1", "2.2.2.2"},
+						DNSName:    "20qri0.lb-ocnswx.test.example.com",
+						Targets:    []string{"1.1.1.1"},
 						RecordType: "A",
 						RecordTTL:  dns.DefaultTTL,
 					},
 					{
-						DNSName:       "na.lb-ocnswx.example.com",
-						Targets:       []string{"20qri0.lb-ocnswx.example.com"},
+						DNSName:       "default.lb-ocnswx.test.example.com",
+						Targets:       []string{"20qri0.lb-ocnswx.test.example.com"},
 						RecordType:    "CNAME",
-						SetIdentifier: "20qri0.lb-ocnswx.example.com",
+						SetIdentifier: "20qri0.lb-ocnswx.test.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
-								Name:  "weight",
-								Value: "120",
+								Name:  "region",
+								Value: "eu-west-1",
 							},
 						},
 					},
 					{
-						DNSName:       "ie.lb-ocnswx.example.com",
+						DNSName:       "default.lb-ocnswx.test.example.com",
 						Targets:       []string{"mylb.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "mylb.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
-								Name:  "weight",
-								Value: "120",
+								Name:  "region",
+								Value: "us-east-1",
 							},
 						},
 					},
 					{
-						DNSName:       "lb-ocnswx.example.com",
-						Targets:       []string{"na.lb-ocnswx.example.com"},
+						DNSName:       "lb-ocnswx.test.example.com",
+						Targets:       []string{"default.lb-ocnswx.test.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "default",
 						RecordTTL:     dns.DefaultCnameTTL,
@@ -661,34 +771,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "lb-ocnswx.example.com",
-						Targets:       []string{"na.lb-ocnswx.example.com"},
-						RecordType:    "CNAME",
-						SetIdentifier: "NA",
-						RecordTTL:     dns.DefaultCnameTTL,
-						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
-							{
-								Name:  "geo-code",
-								Value: "NA",
-							},
-						},
-					},
-					{
-						DNSName:       "lb-ocnswx.example.com",
-						Targets:       []string{"ie.lb-ocnswx.example.com"},
-						RecordType:    "CNAME",
-						SetIdentifier: "IE",
-						RecordTTL:     dns.DefaultCnameTTL,
-						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
-							{
-								Name:  "geo-code",
-								Value: "IE",
-							},
-						},
-					},
-					{
-						DNSName:    "*.example.com",
-						Targets:    []string{"lb-ocnswx.example.com"},
+						DNSName:    "test.example.com",
+						Targets:    []string{"lb-ocnswx.test.example.com"},
 						RecordType: "CNAME",
 						RecordTTL:  dns.DefaultCnameTTL,
 					},
@@ -696,22 +780,24 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			},
 		},
 		{
-			name:     "sets weighted endpoints",
+			name:     "sets geoproximity endpoints",
 			listener: getTestListener("test.example.com"),
 			mcgTarget: &dns.MultiClusterGatewayTarget{
 				Gateway: &gatewayv1beta1.Gateway{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "testgw",
-						Namespace: "testns",
-					},
+					ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+				},
+				LoadBalancing: &v1alpha1.LoadBalancingSpec{
+					Geoproximity: &v1alpha1.LoadBalancingGeoproximity{},
 				},
 				ClusterGatewayTargets: []dns.ClusterGatewayTarget{
 					{
-
 						ClusterGateway: &dns.ClusterGateway{
 							Cluster: &testutil.TestResource{
 								ObjectMeta: v1.ObjectMeta{
 									Name: "test-cluster-1",
+									Annotations: map[string]string{
+										dns.AnnotationLBAttributeCoordinates: "53.35,-6.26",
+									},
 								},
 							},
 							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
@@ -719,21 +805,20 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
 									Value: "1.1.1.1",
 								},
-								{
-									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
-									Value: "2.2.2.2",
-								},
 							},
 						},
 						Geo:    testutil.Pointer(dns.GeoCode("default")),
 						Weight: testutil.Pointer(120),
+						Bias:   testutil.Pointer(20),
 					},
 					{
-
 						ClusterGateway: &dns.ClusterGateway{
 							Cluster: &testutil.TestResource{
 								ObjectMeta: v1.ObjectMeta{
 									Name: "test-cluster-2",
+									Annotations: map[string]string{
+										dns.AnnotationLBAttributeRegion: "us-east-1",
+									},
 								},
 							},
 							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
@@ -745,6 +830,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 						Geo:    testutil.Pointer(dns.GeoCode("default")),
 						Weight: testutil.Pointer(120),
+						Bias:   testutil.Pointer(0),
 					},
 				},
 			},
@@ -769,40 +855,48 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			wantSpec: &v1alpha1.DNSRecordSpec{
 				Endpoints: []*v1alpha1.Endpoint{
 					{
-						DNSName:    "20qri0.lb-0ecjaw.test.example.com",
-						Targets:    []string{"1.1.1.1", "2.2.2.2"},
+						DNSName:    "20qri0.lb-ocnswx.test.example.com",
+						Targets:    []string{"1.1.1.1"},
 						RecordType: "A",
 						RecordTTL:  dns.DefaultTTL,
 					},
 					{
-						DNSName:       "default.lb-0ecjaw.test.example.com",
-						Targets:       []string{"20qri0.lb-0ecjaw.test.example.com"},
+						DNSName:       "default.lb-ocnswx.test.example.com",
+						Targets:       []string{"20qri0.lb-ocnswx.test.example.com"},
 						RecordType:    "CNAME",
-						SetIdentifier: "20qri0.lb-0ecjaw.test.example.com",
+						SetIdentifier: "20qri0.lb-ocnswx.test.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
-								Name:  "weight",
-								Value: "120",
+								Name:  "geoproximity-coordinates",
+								Value: "53.35,-6.26",
+							},
+							{
+								Name:  "geoproximity-bias",
+								Value: "20",
 							},
 						},
 					},
 					{
-						DNSName:       "default.lb-0ecjaw.test.example.com",
+						DNSName:       "default.lb-ocnswx.test.example.com",
 						Targets:       []string{"mylb.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "mylb.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
-								Name:  "weight",
-								Value: "120",
+								Name:  "geoproximity-region",
+								Value: "us-east-1",
+							},
+							{
+								Name:  "geoproximity-bias",
+								Value: "0",
 							},
 						},
 					},
 					{
-						DNSName:       "lb-0ecjaw.test.example.com",
-						Targets:       []string{"default.lb-0ecjaw.test.example.com"},
+						DNSName:       "lb-ocnswx.test.example.com",
+						Targets:       []string{"default.lb-ocnswx.test.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "default",
 						RecordTTL:     dns.DefaultCnameTTL,
@@ -815,7 +909,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 					},
 					{
 						DNSName:    "test.example.com",
-						Targets:    []string{"lb-0ecjaw.test.example.com"},
+						Targets:    []string{"lb-ocnswx.test.example.com"},
 						RecordType: "CNAME",
 						RecordTTL:  dns.DefaultCnameTTL,
 					},
@@ -823,8 +917,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			},
 		},
 		{
-			name:     "sets geo weighted endpoints",
-			listener: getTestListener("test.example.com"),
+			name:     "sets geo weighted endpoints wildcard",
+			listener: getTestListener("*.example.com"),
 			mcgTarget: &dns.MultiClusterGatewayTarget{
 				Gateway: &gatewayv1beta1.Gateway{
 					ObjectMeta: v1.ObjectMeta{Name: "testgw"},
@@ -879,7 +973,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			},
 			dnsRecord: &v1alpha1.DNSRecord{
 				ObjectMeta: v1.ObjectMeta{
-					Name: "test.example.com",
+					Name: "gw-test",
 				},
 			},
 			dnsPolicy: &v1alpha1.DNSPolicy{},
@@ -898,16 +992,16 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			wantSpec: &v1alpha1.DNSRecordSpec{
 				Endpoints: []*v1alpha1.Endpoint{
 					{
-						DNSName:    "20qri0.lb-ocnswx.test.example.com",
+						DNSName:    "20qri0.lb-ocnswx.example.com",
 						Targets:    []string{"1.1.1.1", "2.2.2.2"},
 						RecordType: "A",
 						RecordTTL:  dns.DefaultTTL,
 					},
 					{
-						DNSName:       "na.lb-ocnswx.test.example.com",
-						Targets:       []string{"20qri0.lb-ocnswx.test.example.com"},
+						DNSName:       "na.lb-ocnswx.example.com",
+						Targets:       []string{"20qri0.lb-ocnswx.example.com"},
 						RecordType:    "CNAME",
-						SetIdentifier: "20qri0.lb-ocnswx.test.example.com",
+						SetIdentifier: "20qri0.lb-ocnswx.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
@@ -917,7 +1011,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "ie.lb-ocnswx.test.example.com",
+						DNSName:       "ie.lb-ocnswx.example.com",
 						Targets:       []string{"mylb.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "mylb.example.com",
@@ -930,8 +1024,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "lb-ocnswx.test.example.com",
-						Targets:       []string{"na.lb-ocnswx.test.example.com"},
+						DNSName:       "lb-ocnswx.example.com",
+						Targets:       []string{"na.lb-ocnswx.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "default",
 						RecordTTL:     dns.DefaultCnameTTL,
@@ -943,8 +1037,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "lb-ocnswx.test.example.com",
-						Targets:       []string{"na.lb-ocnswx.test.example.com"},
+						DNSName:       "lb-ocnswx.example.com",
+						Targets:       []string{"na.lb-ocnswx.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "NA",
 						RecordTTL:     dns.DefaultCnameTTL,
@@ -956,8 +1050,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "lb-ocnswx.test.example.com",
-						Targets:       []string{"ie.lb-ocnswx.test.example.com"},
+						DNSName:       "lb-ocnswx.example.com",
+						Targets:       []string{"ie.lb-ocnswx.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "IE",
 						RecordTTL:     dns.DefaultCnameTTL,
@@ -969,8 +1063,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:    "test.example.com",
-						Targets:    []string{"lb-ocnswx.test.example.com"},
+						DNSName:    "*.example.com",
+						Targets:    []string{"lb-ocnswx.example.com"},
 						RecordType: "CNAME",
 						RecordTTL:  dns.DefaultCnameTTL,
 					},
@@ -978,11 +1072,14 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			},
 		},
 		{
-			name:     "sets no endpoints when no target addresses",
+			name:     "sets weighted endpoints",
 			listener: getTestListener("test.example.com"),
 			mcgTarget: &dns.MultiClusterGatewayTarget{
 				Gateway: &gatewayv1beta1.Gateway{
-					ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "testgw",
+						Namespace: "testns",
+					},
 				},
 				ClusterGatewayTargets: []dns.ClusterGatewayTarget{
 					{
@@ -993,9 +1090,18 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 									Name: "test-cluster-1",
 								},
 							},
-							GatewayAddresses: []gatewayv1beta1.GatewayAddress{},
+							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+								{
+									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+									Value: "1.1.1.1",
+								},
+								{
+									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+									Value: "2.2.2.2",
+								},
+							},
 						},
-						Geo:    testutil.Pointer(dns.GeoCode("NA")),
+						Geo:    testutil.Pointer(dns.GeoCode("default")),
 						Weight: testutil.Pointer(120),
 					},
 					{
@@ -1006,17 +1112,17 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 									Name: "test-cluster-2",
 								},
 							},
-							GatewayAddresses: []gatewayv1beta1.GatewayAddress{},
+							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+								{
+									Type:  testutil.Pointer(gatewayv1beta1.HostnameAddressType),
+									Value: "mylb.example.com",
+								},
+							},
 						},
-						Geo:    testutil.Pointer(dns.GeoCode("IE")),
+						Geo:    testutil.Pointer(dns.GeoCode("default")),
 						Weight: testutil.Pointer(120),
 					},
 				},
-				LoadBalancing: &v1alpha1.LoadBalancingSpec{
-					Geo: &v1alpha1.LoadBalancingGeo{
-						DefaultGeo: "NA",
-					},
-				},
 			},
 			dnsRecord: &v1alpha1.DNSRecord{
 				ObjectMeta: v1.ObjectMeta{
@@ -1037,18 +1143,67 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 				},
 			},
 			wantSpec: &v1alpha1.DNSRecordSpec{
-				Endpoints: []*v1alpha1.Endpoint{},
+				Endpoints: []*v1alpha1.Endpoint{
+					{
+						DNSName:    "20qri0.lb-0ecjaw.test.example.com",
+						Targets:    []string{"1.1.1.1", "2.2.2.2"},
+						RecordType: "A",
+						RecordTTL:  dns.DefaultTTL,
+					},
+					{
+						DNSName:       "default.lb-0ecjaw.test.example.com",
+						Targets:       []string{"20qri0.lb-0ecjaw.test.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "20qri0.lb-0ecjaw.test.example.com",
+						RecordTTL:     dns.DefaultTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "weight",
+								Value: "120",
+							},
+						},
+					},
+					{
+						DNSName:       "default.lb-0ecjaw.test.example.com",
+						Targets:       []string{"mylb.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "mylb.example.com",
+						RecordTTL:     dns.DefaultTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "weight",
+								Value: "120",
+							},
+						},
+					},
+					{
+						DNSName:       "lb-0ecjaw.test.example.com",
+						Targets:       []string{"default.lb-0ecjaw.test.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "default",
+						RecordTTL:     dns.DefaultCnameTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "geo-code",
+								Value: "*",
+							},
+						},
+					},
+					{
+						DNSName:    "test.example.com",
+						Targets:    []string{"lb-0ecjaw.test.example.com"},
+						RecordType: "CNAME",
+						RecordTTL:  dns.DefaultCnameTTL,
+					},
+				},
 			},
 		},
 		{
-			name:     "test endpoint presence when probe is present but no health status is reported yet",
-			listener: getTestListener("*.example.com"),
+			name:     "sets geo weighted endpoints",
+			listener: getTestListener("test.example.com"),
 			mcgTarget: &dns.MultiClusterGatewayTarget{
 				Gateway: &gatewayv1beta1.Gateway{
-					ObjectMeta: v1.ObjectMeta{
-						Name:      "testgw",
-						Namespace: "testns",
-					},
+					ObjectMeta: v1.ObjectMeta{Name: "testgw"},
 				},
 				ClusterGatewayTargets: []dns.ClusterGatewayTarget{
 					{
@@ -1070,7 +1225,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 								},
 							},
 						},
-						Geo:    testutil.Pointer(dns.GeoCode("default")),
+						Geo:    testutil.Pointer(dns.GeoCode("NA")),
 						Weight: testutil.Pointer(120),
 					},
 					{
@@ -1088,63 +1243,47 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 								},
 							},
 						},
-						Geo:    testutil.Pointer(dns.GeoCode("default")),
+						Geo:    testutil.Pointer(dns.GeoCode("IE")),
 						Weight: testutil.Pointer(120),
 					},
 				},
+				LoadBalancing: &v1alpha1.LoadBalancingSpec{
+					Geo: &v1alpha1.LoadBalancingGeo{
+						DefaultGeo: "NA",
+					},
+				},
 			},
 			dnsRecord: &v1alpha1.DNSRecord{
 				ObjectMeta: v1.ObjectMeta{
 					Name: "test.example.com",
 				},
 			},
-			dnsPolicy: &v1alpha1.DNSPolicy{
-				ObjectMeta: v1.ObjectMeta{
-					Name:      "testpolicy",
-					Namespace: "testns",
-				},
-			},
+			dnsPolicy: &v1alpha1.DNSPolicy{},
 			probeOne: &v1alpha1.DNSHealthCheckProbe{
 				ObjectMeta: v1.ObjectMeta{
-					Labels: commonDNSRecordLabels(
-						types.NamespacedName{
-							Namespace: "testns",
-							Name:      "testgw"},
-						types.NamespacedName{
-							Namespace: "testns",
-							Name:      "testpolicy",
-						}),
 					Name:      dnsHealthCheckProbeName("1.1.1.1", "testgw", "test"),
-					Namespace: "testns",
+					Namespace: "namespace",
 				},
 			},
 			probeTwo: &v1alpha1.DNSHealthCheckProbe{
 				ObjectMeta: v1.ObjectMeta{
-					Labels: commonDNSRecordLabels(
-						types.NamespacedName{
-							Namespace: "testns",
-							Name:      "testgw"},
-						types.NamespacedName{
-							Namespace: "testns",
-							Name:      "testpolicy",
-						}),
 					Name:      dnsHealthCheckProbeName("2.2.2.2", "testgw", "test"),
-					Namespace: "testns",
+					Namespace: "namespace",
 				},
 			},
 			wantSpec: &v1alpha1.DNSRecordSpec{
 				Endpoints: []*v1alpha1.Endpoint{
 					{
-						DNSName:    "20qri0.lb-0ecjaw.example.com",
+						DNSName:    "20qri0.lb-ocnswx.test.example.com",
 						Targets:    []string{"1.1.1.1", "2.2.2.2"},
 						RecordType: "A",
 						RecordTTL:  dns.DefaultTTL,
 					},
 					{
-						DNSName:       "default.lb-0ecjaw.example.com",
-						Targets:       []string{"20qri0.lb-0ecjaw.example.com"},
+						DNSName:       "na.lb-ocnswx.test.example.com",
+						Targets:       []string{"20qri0.lb-ocnswx.test.example.com"},
 						RecordType:    "CNAME",
-						SetIdentifier: "20qri0.lb-0ecjaw.example.com",
+						SetIdentifier: "20qri0.lb-ocnswx.test.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
@@ -1154,7 +1293,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "default.lb-0ecjaw.example.com",
+						DNSName:       "ie.lb-ocnswx.test.example.com",
 						Targets:       []string{"mylb.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "mylb.example.com",
@@ -1167,8 +1306,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "lb-0ecjaw.example.com",
-						Targets:       []string{"default.lb-0ecjaw.example.com"},
+						DNSName:       "lb-ocnswx.test.example.com",
+						Targets:       []string{"na.lb-ocnswx.test.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "default",
 						RecordTTL:     dns.DefaultCnameTTL,
@@ -1180,8 +1319,34 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:    "*.example.com",
-						Targets:    []string{"lb-0ecjaw.example.com"},
+						DNSName:       "lb-ocnswx.test.example.com",
+						Targets:       []string{"na.lb-ocnswx.test.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "NA",
+						RecordTTL:     dns.DefaultCnameTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "geo-code",
+								Value: "NA",
+							},
+						},
+					},
+					{
+						DNSName:       "lb-ocnswx.test.example.com",
+						Targets:       []string{"ie.lb-ocnswx.test.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "IE",
+						RecordTTL:     dns.DefaultCnameTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "geo-code",
+								Value: "IE",
+							},
+						},
+					},
+					{
+						DNSName:    "test.example.com",
+						Targets:    []string{"lb-ocnswx.test.example.com"},
 						RecordType: "CNAME",
 						RecordTTL:  dns.DefaultCnameTTL,
 					},
@@ -1189,8 +1354,71 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 			},
 		},
 		{
-			name:     "test endpoint presence when probe is present but healthy status is reported",
+			name:     "sets no endpoints when no target addresses",
 			listener: getTestListener("test.example.com"),
+			mcgTarget: &dns.MultiClusterGatewayTarget{
+				Gateway: &gatewayv1beta1.Gateway{
+					ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+				},
+				ClusterGatewayTargets: []dns.ClusterGatewayTarget{
+					{
+
+						ClusterGateway: &dns.ClusterGateway{
+							Cluster: &testutil.TestResource{
+								ObjectMeta: v1.ObjectMeta{
+									Name: "test-cluster-1",
+								},
+							},
+							GatewayAddresses: []gatewayv1beta1.GatewayAddress{},
+						},
+						Geo:    testutil.Pointer(dns.GeoCode("NA")),
+						Weight: testutil.Pointer(120),
+					},
+					{
+
+						ClusterGateway: &dns.ClusterGateway{
+							Cluster: &testutil.TestResource{
+								ObjectMeta: v1.ObjectMeta{
+									Name: "test-cluster-2",
+								},
+							},
+							GatewayAddresses: []gatewayv1beta1.GatewayAddress{},
+						},
+						Geo:    testutil.Pointer(dns.GeoCode("IE")),
+						Weight: testutil.Pointer(120),
+					},
+				},
+				LoadBalancing: &v1alpha1.LoadBalancingSpec{
+					Geo: &v1alpha1.LoadBalancingGeo{
+						DefaultGeo: "NA",
+					},
+				},
+			},
+			dnsRecord: &v1alpha1.DNSRecord{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test.example.com",
+				},
+			},
+			dnsPolicy: &v1alpha1.DNSPolicy{},
+			probeOne: &v1alpha1.DNSHealthCheckProbe{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      dnsHealthCheckProbeName("1.1.1.1", "testgw", "test"),
+					Namespace: "namespace",
+				},
+			},
+			probeTwo: &v1alpha1.DNSHealthCheckProbe{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      dnsHealthCheckProbeName("2.2.2.2", "testgw", "test"),
+					Namespace: "namespace",
+				},
+			},
+			wantSpec: &v1alpha1.DNSRecordSpec{
+				Endpoints: []*v1alpha1.Endpoint{},
+			},
+		},
+		{
+			name:     "test endpoint presence when probe is present but no health status is reported yet",
+			listener: getTestListener("*.example.com"),
 			mcgTarget: &dns.MultiClusterGatewayTarget{
 				Gateway: &gatewayv1beta1.Gateway{
 					ObjectMeta: v1.ObjectMeta{
@@ -1212,6 +1440,10 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
 									Value: "1.1.1.1",
 								},
+								{
+									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+									Value: "2.2.2.2",
+								},
 							},
 						},
 						Geo:    testutil.Pointer(dns.GeoCode("default")),
@@ -1222,13 +1454,13 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						ClusterGateway: &dns.ClusterGateway{
 							Cluster: &testutil.TestResource{
 								ObjectMeta: v1.ObjectMeta{
-									Name: "test-cluster-1",
+									Name: "test-cluster-2",
 								},
 							},
 							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
 								{
-									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
-									Value: "2.2.2.2",
+									Type:  testutil.Pointer(gatewayv1beta1.HostnameAddressType),
+									Value: "mylb.example.com",
 								},
 							},
 						},
@@ -1261,11 +1493,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 					Name:      dnsHealthCheckProbeName("1.1.1.1", "testgw", "test"),
 					Namespace: "testns",
 				},
-				Status: v1alpha1.DNSHealthCheckProbeStatus{
-					Healthy: aws.Bool(true),
-				},
 			},
-
 			probeTwo: &v1alpha1.DNSHealthCheckProbe{
 				ObjectMeta: v1.ObjectMeta{
 					Labels: commonDNSRecordLabels(
@@ -1279,29 +1507,20 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 					Name:      dnsHealthCheckProbeName("2.2.2.2", "testgw", "test"),
 					Namespace: "testns",
 				},
-				Status: v1alpha1.DNSHealthCheckProbeStatus{
-					Healthy: aws.Bool(true),
-				},
 			},
 			wantSpec: &v1alpha1.DNSRecordSpec{
 				Endpoints: []*v1alpha1.Endpoint{
 					{
-						DNSName:    "20qri0.lb-0ecjaw.test.example.com",
-						Targets:    []string{"1.1.1.1"},
-						RecordType: "A",
-						RecordTTL:  dns.DefaultTTL,
-					},
-					{
-						DNSName:    "20qri0.lb-0ecjaw.test.example.com",
-						Targets:    []string{"2.2.2.2"},
+						DNSName:    "20qri0.lb-0ecjaw.example.com",
+						Targets:    []string{"1.1.1.1", "2.2.2.2"},
 						RecordType: "A",
 						RecordTTL:  dns.DefaultTTL,
 					},
 					{
-						DNSName:       "default.lb-0ecjaw.test.example.com",
-						Targets:       []string{"20qri0.lb-0ecjaw.test.example.com"},
+						DNSName:       "default.lb-0ecjaw.example.com",
+						Targets:       []string{"20qri0.lb-0ecjaw.example.com"},
 						RecordType:    "CNAME",
-						SetIdentifier: "20qri0.lb-0ecjaw.test.example.com",
+						SetIdentifier: "20qri0.lb-0ecjaw.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
@@ -1311,10 +1530,10 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "default.lb-0ecjaw.test.example.com",
-						Targets:       []string{"20qri0.lb-0ecjaw.test.example.com"},
+						DNSName:       "default.lb-0ecjaw.example.com",
+						Targets:       []string{"mylb.example.com"},
 						RecordType:    "CNAME",
-						SetIdentifier: "20qri0.lb-0ecjaw.test.example.com",
+						SetIdentifier: "mylb.example.com",
 						RecordTTL:     dns.DefaultTTL,
 						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
 							{
@@ -1324,8 +1543,8 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:       "lb-0ecjaw.test.example.com",
-						Targets:       []string{"default.lb-0ecjaw.test.example.com"},
+						DNSName:       "lb-0ecjaw.example.com",
+						Targets:       []string{"default.lb-0ecjaw.example.com"},
 						RecordType:    "CNAME",
 						SetIdentifier: "default",
 						RecordTTL:     dns.DefaultCnameTTL,
@@ -1337,8 +1556,165 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 						},
 					},
 					{
-						DNSName:    "test.example.com",
-						Targets:    []string{"lb-0ecjaw.test.example.com"},
+						DNSName:    "*.example.com",
+						Targets:    []string{"lb-0ecjaw.example.com"},
+						RecordType: "CNAME",
+						RecordTTL:  dns.DefaultCnameTTL,
+					},
+				},
+			},
+		},
+		{
+			name:     "test endpoint presence when probe is present but healthy status is reported",
+			listener: getTestListener("test.example.com"),
+			mcgTarget: &dns.MultiClusterGatewayTarget{
+				Gateway: &gatewayv1beta1.Gateway{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      "testgw",
+						Namespace: "testns",
+					},
+				},
+				ClusterGatewayTargets: []dns.ClusterGatewayTarget{
+					{
+
+						ClusterGateway: &dns.ClusterGateway{
+							Cluster: &testutil.TestResource{
+								ObjectMeta: v1.ObjectMeta{
+									Name: "test-cluster-1",
+								},
+							},
+							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+								{
+									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+									Value: "1.1.1.1",
+								},
+							},
+						},
+						Geo:    testutil.Pointer(dns.GeoCode("default")),
+						Weight: testutil.Pointer(120),
+					},
+					{
+
+						ClusterGateway: &dns.ClusterGateway{
+							Cluster: &testutil.TestResource{
+								ObjectMeta: v1.ObjectMeta{
+									Name: "test-cluster-1",
+								},
+							},
+							GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+								{
+									Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+									Value: "2.2.2.2",
+								},
+							},
+						},
+						Geo:    testutil.Pointer(dns.GeoCode("default")),
+						Weight: testutil.Pointer(120),
+					},
+				},
+			},
+			dnsRecord: &v1alpha1.DNSRecord{
+				ObjectMeta: v1.ObjectMeta{
+					Name: "test.example.com",
+				},
+			},
+			dnsPolicy: &v1alpha1.DNSPolicy{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      "testpolicy",
+					Namespace: "testns",
+				},
+			},
+			probeOne: &v1alpha1.DNSHealthCheckProbe{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: commonDNSRecordLabels(
+						types.NamespacedName{
+							Namespace: "testns",
+							Name:      "testgw"},
+						types.NamespacedName{
+							Namespace: "testns",
+							Name:      "testpolicy",
+						}),
+					Name:      dnsHealthCheckProbeName("1.1.1.1", "testgw", "test"),
+					Namespace: "testns",
+				},
+				Status: v1alpha1.DNSHealthCheckProbeStatus{
+					Healthy: aws.Bool(true),
+				},
+			},
+
+			probeTwo: &v1alpha1.DNSHealthCheckProbe{
+				ObjectMeta: v1.ObjectMeta{
+					Labels: commonDNSRecordLabels(
+						types.NamespacedName{
+							Namespace: "testns",
+							Name:      "testgw"},
+						types.NamespacedName{
+							Namespace: "testns",
+							Name:      "testpolicy",
+						}),
+					Name:      dnsHealthCheckProbeName("2.2.2.2", "testgw", "test"),
+					Namespace: "testns",
+				},
+				Status: v1alpha1.DNSHealthCheckProbeStatus{
+					Healthy: aws.Bool(true),
+				},
+			},
+			wantSpec: &v1alpha1.DNSRecordSpec{
+				Endpoints: []*v1alpha1.Endpoint{
+					{
+						DNSName:    "20qri0.lb-0ecjaw.test.example.com",
+						Targets:    []string{"1.1.1.1"},
+						RecordType: "A",
+						RecordTTL:  dns.DefaultTTL,
+					},
+					{
+						DNSName:    "20qri0.lb-0ecjaw.test.example.com",
+						Targets:    []string{"2.2.2.2"},
+						RecordType: "A",
+						RecordTTL:  dns.DefaultTTL,
+					},
+					{
+						DNSName:       "default.lb-0ecjaw.test.example.com",
+						Targets:       []string{"20qri0.lb-0ecjaw.test.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "20qri0.lb-0ecjaw.test.example.com",
+						RecordTTL:     dns.DefaultTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "weight",
+								Value: "120",
+							},
+						},
+					},
+					{
+						DNSName:       "default.lb-0ecjaw.test.example.com",
+						Targets:       []string{"20qri0.lb-0ecjaw.test.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "20qri0.lb-0ecjaw.test.example.com",
+						RecordTTL:     dns.DefaultTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "weight",
+								Value: "120",
+							},
+						},
+					},
+					{
+						DNSName:       "lb-0ecjaw.test.example.com",
+						Targets:       []string{"default.lb-0ecjaw.test.example.com"},
+						RecordType:    "CNAME",
+						SetIdentifier: "default",
+						RecordTTL:     dns.DefaultCnameTTL,
+						ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+							{
+								Name:  "geo-code",
+								Value: "*",
+							},
+						},
+					},
+					{
+						DNSName:    "test.example.com",
+						Targets:    []string{"lb-0ecjaw.test.example.com"},
 						RecordType: "CNAME",
 						RecordTTL:  dns.DefaultCnameTTL,
 					},
@@ -1593,6 +1969,7 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 					ConsecutiveFailures: 6,
 				},
 			},
+			wantAllUnhealthy: true,
 			wantSpec: &v1alpha1.DNSRecordSpec{
 				Endpoints: []*v1alpha1.Endpoint{
 					{
@@ -1821,9 +2198,13 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(testCase.dnsRecord, testCase.probeOne, testCase.probeTwo).Build()
 			s := dnsHelper{Client: f}
-			if err := s.setEndpoints(context.TODO(), testCase.mcgTarget, testCase.dnsRecord, testCase.dnsPolicy, testCase.listener); (err != nil) != testCase.wantErr {
+			allUnhealthy, err := s.setEndpoints(context.TODO(), testCase.mcgTarget, testCase.dnsRecord, testCase.dnsPolicy, testCase.listener, nil)
+			if (err != nil) != testCase.wantErr {
 				t.Errorf("SetEndpoints() error = %v, wantErr %v", err, testCase.wantErr)
 			}
+			if allUnhealthy != testCase.wantAllUnhealthy {
+				t.Errorf("SetEndpoints() allUnhealthy = %v, want %v", allUnhealthy, testCase.wantAllUnhealthy)
+			}
 
 			gotRecord := &v1alpha1.DNSRecord{}
 			if err := f.Get(context.TODO(), client.ObjectKeyFromObject(testCase.dnsRecord), gotRecord); err != nil {
@@ -1851,6 +2232,666 @@ func Test_dnsHelper_setEndpoints(t *testing.T) {
 	}
 }
 
+func Test_dnsHelper_setEndpoints_customWeights(t *testing.T) {
+	clusterGateways := []dns.ClusterGateway{
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:   "test-cluster-1",
+					Labels: map[string]string{"kuadrant.io/lb-attribute-custom-weight": "primary"},
+				},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "1.1.1.1",
+				},
+			},
+		},
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:   "test-cluster-2",
+					Labels: map[string]string{"kuadrant.io/lb-attribute-custom-weight": "secondary"},
+				},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "2.2.2.2",
+				},
+			},
+		},
+	}
+	loadBalancing := &v1alpha1.LoadBalancingSpec{
+		Weighted: &v1alpha1.LoadBalancingWeighted{
+			DefaultWeight: 120,
+			Custom: []*v1alpha1.CustomWeight{
+				{
+					Selector: &v1.LabelSelector{
+						MatchLabels: map[string]string{"kuadrant.io/lb-attribute-custom-weight": "primary"},
+					},
+					Weight: 80,
+				},
+				{
+					Selector: &v1.LabelSelector{
+						MatchLabels: map[string]string{"kuadrant.io/lb-attribute-custom-weight": "secondary"},
+					},
+					Weight: 20,
+				},
+			},
+		},
+	}
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, loadBalancing)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test.example.com",
+		},
+	}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, &v1alpha1.DNSPolicy{}, getTestListener("test.example.com"), nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+
+	gotRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+
+	wantWeights := map[string]string{
+		"20qri0.lb-ocnswx.test.example.com": "80",
+		"2pj3we.lb-ocnswx.test.example.com": "20",
+	}
+	gotWeights := map[string]string{}
+	for _, endpoint := range gotRecord.Spec.Endpoints {
+		if endpoint.DNSName != "default.lb-ocnswx.test.example.com" {
+			continue
+		}
+		if weight, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok {
+			gotWeights[endpoint.SetIdentifier] = weight.Value
+		}
+	}
+	if !reflect.DeepEqual(gotWeights, wantWeights) {
+		t.Errorf("SetEndpoints() got weights %v, want %v", gotWeights, wantWeights)
+	}
+}
+
+func Test_dnsHelper_setEndpoints_dualStack(t *testing.T) {
+	clusterGateways := []dns.ClusterGateway{
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: "test-cluster-1"},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "1.1.1.1",
+				},
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "2001:db8::1",
+				},
+			},
+		},
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: "test-cluster-2"},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "2001:db8::2",
+				},
+			},
+		},
+	}
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test.example.com",
+		},
+	}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, &v1alpha1.DNSPolicy{}, getTestListener("test.example.com"), nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+
+	gotRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+
+	wantAddresses := []string{
+		"20qri0.lb-ocnswx.test.example.com A [1.1.1.1]",
+		"20qri0.lb-ocnswx.test.example.com AAAA [2001:db8::1]",
+		"2pj3we.lb-ocnswx.test.example.com AAAA [2001:db8::2]",
+	}
+	var gotAddresses []string
+	var clusterTargets []string
+	for _, endpoint := range gotRecord.Spec.Endpoints {
+		switch endpoint.RecordType {
+		case string(v1alpha1.ARecordType), string(v1alpha1.AAAARecordType):
+			gotAddresses = append(gotAddresses, fmt.Sprintf("%s %s %v", endpoint.DNSName, endpoint.RecordType, endpoint.Targets))
+		}
+		if endpoint.DNSName == "default.lb-ocnswx.test.example.com" {
+			clusterTargets = append(clusterTargets, endpoint.Targets...)
+		}
+	}
+	if !reflect.DeepEqual(gotAddresses, wantAddresses) {
+		t.Errorf("SetEndpoints() got address records %v, want %v", gotAddresses, wantAddresses)
+	}
+	// the cluster hostname is targeted once, whether it has an A record, an AAAA record, or both
+	wantClusterTargets := []string{"20qri0.lb-ocnswx.test.example.com", "2pj3we.lb-ocnswx.test.example.com"}
+	if !reflect.DeepEqual(clusterTargets, wantClusterTargets) {
+		t.Errorf("SetEndpoints() got cluster targets %v, want %v", clusterTargets, wantClusterTargets)
+	}
+}
+
+func Test_dnsHelper_setEndpoints_roundRobin(t *testing.T) {
+	address := func(value string) gatewayv1beta1.GatewayAddress {
+		return gatewayv1beta1.GatewayAddress{Type: testutil.Pointer(gatewayv1beta1.IPAddressType), Value: value}
+	}
+	clusterGateways := []dns.ClusterGateway{
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: "test-cluster-1"},
+			},
+			// the addresses reported by the gateway status, in any order and possibly repeated
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{address("2.2.2.2"), address("1.1.1.1"), address("2.2.2.2")},
+		},
+	}
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw", Namespace: "testns"},
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: v1.ObjectMeta{Name: "testpolicy", Namespace: "testns"},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{Name: "test.example.com", Namespace: "testns"},
+	}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+	listener := getTestListener("test.example.com")
+
+	// addressRecords returns the A records of the DNSRecord and the CNAME targets of the cluster hostnames
+	addressRecords := func() ([]string, []string) {
+		t.Helper()
+		if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, dnsPolicy, listener, nil); err != nil {
+			t.Fatalf("SetEndpoints() unexpected error %v", err)
+		}
+		gotRecord := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+			t.Fatalf("error getting updated DNSRecord %v", err)
+		}
+		var addresses, clusterTargets []string
+		for _, endpoint := range gotRecord.Spec.Endpoints {
+			if endpoint.RecordType == string(v1alpha1.ARecordType) {
+				addresses = append(addresses, fmt.Sprintf("%s A %v", endpoint.DNSName, endpoint.Targets))
+			}
+			if endpoint.DNSName == "default.lb-0ecjaw.test.example.com" {
+				clusterTargets = append(clusterTargets, endpoint.Targets...)
+			}
+		}
+		return addresses, clusterTargets
+	}
+
+	// every address of the cluster is published in a single record set
+	wantAddresses := []string{"20qri0.lb-0ecjaw.test.example.com A [1.1.1.1 2.2.2.2]"}
+	wantClusterTargets := []string{"20qri0.lb-0ecjaw.test.example.com"}
+	addresses, clusterTargets := addressRecords()
+	if !reflect.DeepEqual(addresses, wantAddresses) {
+		t.Errorf("SetEndpoints() got address records %v, want %v", addresses, wantAddresses)
+	}
+	if !reflect.DeepEqual(clusterTargets, wantClusterTargets) {
+		t.Errorf("SetEndpoints() got cluster targets %v, want %v", clusterTargets, wantClusterTargets)
+	}
+
+	// an unhealthy address is removed from the record set, the cluster is still published with its healthy address
+	probe := &v1alpha1.DNSHealthCheckProbe{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      dnsHealthCheckProbeName("2.2.2.2", "testgw", "test"),
+			Namespace: "testns",
+			Labels:    commonDNSRecordLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(dnsPolicy)),
+		},
+		Spec: v1alpha1.DNSHealthCheckProbeSpec{
+			FailureThreshold: aws.Int(4),
+		},
+		Status: v1alpha1.DNSHealthCheckProbeStatus{
+			Healthy:             aws.Bool(false),
+			ConsecutiveFailures: 5,
+		},
+	}
+	if err := f.Create(context.TODO(), probe); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	addresses, clusterTargets = addressRecords()
+	wantAddresses = []string{"20qri0.lb-0ecjaw.test.example.com A [1.1.1.1]"}
+	if !reflect.DeepEqual(addresses, wantAddresses) {
+		t.Errorf("SetEndpoints() got address records %v, want %v", addresses, wantAddresses)
+	}
+	if !reflect.DeepEqual(clusterTargets, wantClusterTargets) {
+		t.Errorf("SetEndpoints() got cluster targets %v, want %v", clusterTargets, wantClusterTargets)
+	}
+}
+
+func Test_dnsHelper_hostOverride(t *testing.T) {
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: v1.ObjectMeta{Name: "test-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			HostOverrides: []v1alpha1.HostOverride{
+				{ListenerHostname: "internal.svc", Hostname: "public.example.com"},
+			},
+		},
+	}
+	listener := getTestListener("internal.svc")
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: v1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, []dns.ClusterGateway{
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: "test-cluster-1"},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "1.1.1.1",
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw-test", Namespace: "test"},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	s := dnsHelper{Client: f}
+
+	mz, err := s.getManagedZoneForListener(context.TODO(), "test", listener, dnsPolicy)
+	if err != nil {
+		t.Fatalf("expected the managed zone of the host override, got error %v", err)
+	}
+	if mz.Name != managedZone.Name {
+		t.Errorf("expected managed zone %s, got %s", managedZone.Name, mz.Name)
+	}
+	if _, err := s.getManagedZoneForListener(context.TODO(), "test", listener, &v1alpha1.DNSPolicy{}); err == nil {
+		t.Errorf("expected no managed zone for the listener hostname without override")
+	}
+
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, dnsPolicy, listener, nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+	gotRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+	if len(gotRecord.Spec.Endpoints) == 0 {
+		t.Fatalf("expected endpoints to be set")
+	}
+	published := false
+	for _, endpoint := range gotRecord.Spec.Endpoints {
+		if !strings.HasSuffix(endpoint.DNSName, "public.example.com") {
+			t.Errorf("expected endpoint %s to be published under the host override", endpoint.DNSName)
+		}
+		if endpoint.DNSName == "public.example.com" {
+			published = true
+		}
+	}
+	if !published {
+		t.Errorf("expected an endpoint for public.example.com, got %v", gotRecord.Spec.Endpoints)
+	}
+}
+
+func Test_dnsHelper_setEndpoints_recordNameTemplate(t *testing.T) {
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: v1.ObjectMeta{Name: "test-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			TargetRef:          gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Name: "testgw"},
+			RecordNameTemplate: "{{ .Cluster }}.debug.{{ .Host }}",
+		},
+	}
+	if err := dnsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+	listener := getTestListener("test.example.com")
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+	}
+	cluster := func(name, address string) dns.ClusterGateway {
+		return dns.ClusterGateway{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: name},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: address,
+				},
+			},
+		}
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, []dns.ClusterGateway{
+		cluster("test-cluster-1", "1.1.1.1"),
+		cluster("test-cluster-2", "2.2.2.2"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw-test", Namespace: "test"},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, dnsPolicy, listener, nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+	gotRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+
+	lbName := fmt.Sprintf("lb-%s.test.example.com", mcgTarget.GetShortCode())
+	endpoints := map[string]*v1alpha1.Endpoint{}
+	for _, endpoint := range gotRecord.Spec.Endpoints {
+		endpoints[endpoint.DNSName] = endpoint
+	}
+	for _, target := range mcgTarget.ClusterGatewayTargets {
+		name := target.GetName() + ".debug.test.example.com"
+		endpoint, ok := endpoints[name]
+		if !ok {
+			t.Fatalf("expected an endpoint for the debug name %s, got %v", name, gotRecord.Spec.Endpoints)
+		}
+		clusterLbName := fmt.Sprintf("%s.%s", target.GetShortCode(), lbName)
+		if endpoint.RecordType != string(v1alpha1.CNAMERecordType) || !reflect.DeepEqual(endpoint.Targets, v1alpha1.Targets{clusterLbName}) {
+			t.Errorf("expected the debug name %s to be a CNAME of %s, got %s %v", name, clusterLbName, endpoint.RecordType, endpoint.Targets)
+		}
+	}
+	// the load balanced record of the listener host is kept
+	if endpoint, ok := endpoints["test.example.com"]; !ok || !reflect.DeepEqual(endpoint.Targets, v1alpha1.Targets{lbName}) {
+		t.Errorf("expected the listener host to be a CNAME of %s, got %v", lbName, endpoint)
+	}
+
+	for _, invalid := range []string{"{{ .Cluster", "{{ .Unknown }}.{{ .Host }}", "debug.{{ .Host }}", "{{ .Host }}", "{{ .Cluster }}_{{ .Host }}"} {
+		dnsPolicy.Spec.RecordNameTemplate = invalid
+		if err := dnsPolicy.Validate(); err == nil {
+			t.Errorf("expected record name template %q to be invalid", invalid)
+		}
+	}
+}
+
+func Test_dnsHelper_setEndpoints_geoFallback(t *testing.T) {
+	cluster := func(name, geoCode, address string) dns.ClusterGateway {
+		return dns.ClusterGateway{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{
+					Name:        name,
+					Annotations: map[string]string{dns.AnnotationLBAttributeGeoCode: geoCode},
+				},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: address,
+				},
+			},
+		}
+	}
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw", Namespace: "testns"},
+	}
+	loadBalancing := &v1alpha1.LoadBalancingSpec{
+		Geo: &v1alpha1.LoadBalancingGeo{
+			DefaultGeo: "NA",
+			// DE has no cluster, the traffic of IE falls back to GB
+			GeoFallback: map[string][]string{"IE": {"DE", "GB"}},
+		},
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, []dns.ClusterGateway{
+		cluster("test-cluster-1", "IE", "1.1.1.1"),
+		cluster("test-cluster-2", "GB", "2.2.2.2"),
+		cluster("test-cluster-3", "NA", "3.3.3.3"),
+	}, loadBalancing)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: v1.ObjectMeta{Name: "testpolicy", Namespace: "testns"},
+		Spec: v1alpha1.DNSPolicySpec{
+			TargetRef:     gatewayapiv1alpha2.PolicyTargetReference{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Name: "testgw"},
+			LoadBalancing: loadBalancing,
+		},
+	}
+	if err := dnsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{Name: "test.example.com", Namespace: "testns"},
+	}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+	listener := getTestListener("test.example.com")
+	lbName := fmt.Sprintf("lb-%s.test.example.com", mcgTarget.GetShortCode())
+
+	// geoRecords returns the targets of the geo records of lbName, keyed by set identifier
+	geoRecords := func() map[string][]string {
+		t.Helper()
+		if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, dnsPolicy, listener, nil); err != nil {
+			t.Fatalf("SetEndpoints() unexpected error %v", err)
+		}
+		gotRecord := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+			t.Fatalf("error getting updated DNSRecord %v", err)
+		}
+		records := map[string][]string{}
+		for _, endpoint := range gotRecord.Spec.Endpoints {
+			if endpoint.DNSName == lbName {
+				records[endpoint.SetIdentifier] = endpoint.Targets
+			}
+		}
+		return records
+	}
+	unhealthy := func(address string) {
+		t.Helper()
+		probe := &v1alpha1.DNSHealthCheckProbe{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      dnsHealthCheckProbeName(address, "testgw", "test"),
+				Namespace: "testns",
+				Labels:    commonDNSRecordLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(dnsPolicy)),
+			},
+			Spec: v1alpha1.DNSHealthCheckProbeSpec{
+				FailureThreshold: aws.Int(4),
+			},
+			Status: v1alpha1.DNSHealthCheckProbeStatus{
+				Healthy:             aws.Bool(false),
+				ConsecutiveFailures: 5,
+			},
+		}
+		if err := f.Create(context.TODO(), probe); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	geoHost := func(geoCode string) []string {
+		return []string{fmt.Sprintf("%s.%s", strings.ToLower(geoCode), lbName)}
+	}
+
+	want := map[string][]string{"IE": geoHost("IE"), "GB": geoHost("GB"), "NA": geoHost("NA"), "default": geoHost("NA")}
+	if got := geoRecords(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SetEndpoints() got geo records %v, want %v", got, want)
+	}
+
+	// the unhealthy IE geo is routed to its first fallback with a healthy cluster
+	unhealthy("1.1.1.1")
+	want = map[string][]string{"IE": geoHost("GB"), "GB": geoHost("GB"), "NA": geoHost("NA"), "default": geoHost("NA")}
+	if got := geoRecords(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SetEndpoints() got geo records %v, want %v", got, want)
+	}
+
+	// without a healthy fallback, IE is left to the default geo
+	unhealthy("2.2.2.2")
+	want = map[string][]string{"NA": geoHost("NA"), "default": geoHost("NA")}
+	if got := geoRecords(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SetEndpoints() got geo records %v, want %v", got, want)
+	}
+
+	for _, invalid := range []map[string][]string{{"IE": nil}, {"IE": {"IE"}}, {"IE": {"GB", "GB"}}, {"": {"GB"}}} {
+		dnsPolicy.Spec.LoadBalancing.Geo.GeoFallback = invalid
+		if err := dnsPolicy.Validate(); err == nil {
+			t.Errorf("expected geo fallback %v to be invalid", invalid)
+		}
+	}
+}
+
+func Test_dnsHelper_setEndpoints_delegateToCNAME(t *testing.T) {
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: v1.ObjectMeta{Name: "test-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			DelegateToCNAME: &v1alpha1.DelegateToCNAME{Target: "gslb.example.net"},
+			LoadBalancing: &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{DefaultGeo: "IE"},
+			},
+		},
+	}
+	listener := getTestListener("test.example.com")
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+	}
+	var clusterGateways []dns.ClusterGateway
+	for i, address := range []string{"1.1.1.1", "2.2.2.2"} {
+		clusterGateways = append(clusterGateways, dns.ClusterGateway{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("test-cluster-%d", i+1)},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: address,
+				},
+			},
+		})
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, dnsPolicy.Spec.LoadBalancing)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw-test", Namespace: "test"},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, dnsPolicy, listener, nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+	gotRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+	if len(gotRecord.Spec.Endpoints) != 1 {
+		t.Fatalf("expected exactly one endpoint, got %v", gotRecord.Spec.Endpoints)
+	}
+	endpoint := gotRecord.Spec.Endpoints[0]
+	if endpoint.DNSName != "test.example.com" || endpoint.RecordType != string(v1alpha1.CNAMERecordType) ||
+		len(endpoint.Targets) != 1 || endpoint.Targets[0] != "gslb.example.net" {
+		t.Errorf("expected a CNAME from test.example.com to gslb.example.net, got %v", endpoint)
+	}
+	if len(endpoint.ProviderSpecific) != 0 || endpoint.SetIdentifier != "" {
+		t.Errorf("expected no routing policy on the CNAME, got %v", endpoint)
+	}
+}
+
+func Test_dnsHelper_setEndpoints_providerHealthChecks(t *testing.T) {
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: v1.ObjectMeta{Name: "test-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			HealthCheck: &v1alpha1.HealthCheckSpec{
+				Endpoint:             "/healthz",
+				FailureThreshold:     testutil.Pointer(5),
+				ProviderHealthChecks: true,
+			},
+		},
+	}
+	listener := getTestListener("test.example.com")
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+	}
+	clusterGateways := []dns.ClusterGateway{
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: "test-cluster-1"},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "1.1.1.1",
+				},
+			},
+		},
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, dnsPolicy.Spec.LoadBalancing)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw-test", Namespace: "test"},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, dnsPolicy, listener, nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+	gotRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+	want := &v1alpha1.ProviderHealthCheck{Endpoint: "/healthz", FailureThreshold: testutil.Pointer(5)}
+	if !equality.Semantic.DeepEqual(gotRecord.Spec.HealthCheck, want) {
+		t.Errorf("expected the provider health check %+v, got %+v", want, gotRecord.Spec.HealthCheck)
+	}
+
+	// the provider health checks are removed once disabled
+	dnsPolicy.Spec.HealthCheck.ProviderHealthChecks = false
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, gotRecord, dnsPolicy, listener, nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+	if gotRecord.Spec.HealthCheck != nil {
+		t.Errorf("expected no provider health check, got %+v", gotRecord.Spec.HealthCheck)
+	}
+}
+
 func Test_dnsHelper_getDNSRecordForListener(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -1980,3 +3021,51 @@ func assertSub(domain string, subdomain string, err string) func(t *testing.T, e
 		}
 	}
 }
+
+func Test_dnsHelper_setEndpoints_forceSync(t *testing.T) {
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "test-policy",
+			Namespace:   "test",
+			Annotations: map[string]string{metadata.ForceSyncAnnotation: "2023-10-14T10:00:00Z"},
+		},
+	}
+	listener := getTestListener("test.example.com")
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw"},
+	}
+	clusterGateways := []dns.ClusterGateway{
+		{
+			Cluster: &testutil.TestResource{
+				ObjectMeta: v1.ObjectMeta{Name: "test-cluster-1"},
+			},
+			GatewayAddresses: []gatewayv1beta1.GatewayAddress{
+				{
+					Type:  testutil.Pointer(gatewayv1beta1.IPAddressType),
+					Value: "1.1.1.1",
+				},
+			},
+		},
+	}
+	mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, dnsPolicy.Spec.LoadBalancing)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{Name: "testgw-test", Namespace: "test"},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(dnsRecord).Build()
+	s := dnsHelper{Client: f}
+
+	if _, err := s.setEndpoints(context.TODO(), mcgTarget, dnsRecord, dnsPolicy, listener, nil); err != nil {
+		t.Fatalf("SetEndpoints() unexpected error %v", err)
+	}
+	gotRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), gotRecord); err != nil {
+		t.Fatalf("error getting updated DNSRecord %v", err)
+	}
+	if got := gotRecord.Annotations[metadata.ForceSyncAnnotation]; got != "2023-10-14T10:00:00Z" {
+		t.Errorf("expected the force sync of the policy to be passed on to the DNSRecord, got %q", got)
+	}
+}
@@ -21,10 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/kuadrant/authorino/pkg/log"
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -40,6 +42,9 @@ import (
 	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/logging"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/events"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
@@ -48,9 +53,26 @@ import (
 
 const (
 	DNSPolicyFinalizer                                    = "kuadrant.io/dns-policy"
+	DNSPolicyGatewayFinalizer                             = "kuadrant.io/dns-policy-gateway"
 	DNSPoliciesBackRefAnnotation                          = "kuadrant.io/dnspolicies"
 	DNSPolicyBackRefAnnotation                            = "kuadrant.io/dnspolicy"
 	DNSPolicyAffected            conditions.ConditionType = "kuadrant.io/DNSPolicyAffected"
+
+	DNSPolicyLatencyRoutingDegraded      conditions.ConditionType = "LatencyRoutingDegraded"
+	DNSPolicyGeoproximityRoutingDegraded conditions.ConditionType = "GeoproximityRoutingDegraded"
+	DNSPolicyDefaultGeoAssigned          conditions.ConditionType = "DefaultGeoAssigned"
+	DNSPolicyHealthCheckDegraded         conditions.ConditionType = "HealthCheckDegraded"
+	DNSPolicyClustersExcluded            conditions.ConditionType = "ClustersExcluded"
+	DNSPolicyListenersNotProgrammed      conditions.ConditionType = "ListenersNotProgrammed"
+	DNSPolicyRecordsPublished            conditions.ConditionType = "RecordsPublished"
+
+	DNSPolicyReasonRecordsPublished      = "RecordsPublished"
+	DNSPolicyReasonNoReadyClusters       = "NoReadyClusters"
+	DNSPolicyReasonNoGatewayAddresses    = "NoGatewayAddresses"
+	DNSPolicyReasonNoMatchingManagedZone = "NoMatchingManagedZone"
+
+	EventReasonDNSPolicyPaused  = "ReconciliationPaused"
+	EventReasonDNSPolicyResumed = "ReconciliationResumed"
 )
 
 type DNSPolicyRefsConfig struct{}
@@ -65,17 +87,19 @@ type DNSPolicyReconciler struct {
 	DNSProvider dns.DNSProviderFactory
 	dnsHelper   dnsHelper
 	Placer      gateway.GatewayPlacer
+	controller.ReconcilerOptions
 }
 
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnspolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnspolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnspolicies/finalizers,verbs=update
 //+kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *DNSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := r.Logger().WithValues("DNSPolicy", req.NamespacedName)
+	ctx, log := logging.IntoReconcile(ctx, r.Logger(), logging.KeyPolicy, req.NamespacedName, logging.KeyPolicyKind, "DNSPolicy")
 	log.Info("Reconciling DNSPolicy")
-	ctx = crlog.IntoContext(ctx, log)
 
 	previous := &v1alpha1.DNSPolicy{}
 	if err := r.Client().Get(ctx, req.NamespacedName, previous); err != nil {
@@ -84,10 +108,36 @@ func (r *DNSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	dnsPolicy := previous.DeepCopy()
+	ctx, log = logging.With(ctx, logging.PolicyTarget(dnsPolicy)...)
 	log.V(3).Info("DNSPolicyReconciler Reconcile", "dnsPolicy", dnsPolicy)
 
 	markedForDeletion := dnsPolicy.GetDeletionTimestamp() != nil
 
+	// a paused policy is left untouched, the deletion of the policy is still honoured
+	if metadata.IsPaused(dnsPolicy) && !markedForDeletion {
+		return r.reconcilePaused(ctx, dnsPolicy)
+	}
+	if meta.IsStatusConditionTrue(dnsPolicy.Status.Conditions, string(conditions.ConditionTypePaused)) {
+		log.Info("reconciliation resumed")
+		r.EventRecorder().Eventf(dnsPolicy, corev1.EventTypeNormal, EventReasonDNSPolicyResumed,
+			"reconciliation resumed, the %s annotation was removed", metadata.PausedAnnotation)
+	}
+	if metadata.ForceSyncRequested(dnsPolicy, dnsPolicy.Status.ObservedForceSync) {
+		// the value is passed on to the DNSRecords of the policy so that they are published again
+		log.Info("force sync requested", "value", metadata.GetAnnotation(dnsPolicy, metadata.ForceSyncAnnotation))
+	}
+
+	if !markedForDeletion {
+		// a target gateway being deleted is not ready, so it is fetched directly to clean up its DNS records
+		targetGateway := &gatewayapiv1beta1.Gateway{}
+		if err := r.Client().Get(ctx, targetGatewayKey(dnsPolicy), targetGateway); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		} else if err == nil && targetGateway.GetDeletionTimestamp() != nil {
+			log.V(3).Info("target gateway being deleted, cleaning up dns records")
+			return r.reconcileGatewayDeletion(ctx, dnsPolicy, targetGateway)
+		}
+	}
+
 	targetNetworkObject, err := r.FetchValidTargetRef(ctx, dnsPolicy.GetTargetRef(), dnsPolicy.Namespace)
 	if err != nil {
 		if !markedForDeletion {
@@ -144,6 +194,37 @@ func (r *DNSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, specErr
 	}
 
+	// the policy is reconciled again to step the weights of the clusters ramping up and to remove the endpoints
+	// done draining
+	var requeueAfter time.Duration
+	if ramp := weightRampDuration(dnsPolicy); ramp > 0 {
+		requeueAfter = weightRampRequeueAfter(dnsPolicy.Status.ClusterWeights, ramp, time.Now())
+	}
+	if drain := drainDuration(dnsPolicy); drain > 0 {
+		if after := drainRequeueAfter(dnsPolicy.Status.DrainingEndpoints, drain, time.Now()); after > 0 && (requeueAfter == 0 || after < requeueAfter) {
+			requeueAfter = after
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reconcilePaused sets the Paused condition of a policy paused by the paused annotation, none of the DNS records or
+// health checks of the policy are changed while it is paused
+func (r *DNSPolicyReconciler) reconcilePaused(ctx context.Context, dnsPolicy *v1alpha1.DNSPolicy) (ctrl.Result, error) {
+	if meta.IsStatusConditionTrue(dnsPolicy.Status.Conditions, string(conditions.ConditionTypePaused)) {
+		return ctrl.Result{}, nil
+	}
+	crlog.FromContext(ctx).Info("reconciliation paused")
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, conditions.BuildPausedCondition(dnsPolicy.Generation))
+	if err := r.Client().Status().Update(ctx, dnsPolicy); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.EventRecorder().Eventf(dnsPolicy, corev1.EventTypeNormal, EventReasonDNSPolicyPaused,
+		"reconciliation paused by the %s annotation", metadata.PausedAnnotation)
 	return ctrl.Result{}, nil
 }
 
@@ -237,13 +318,24 @@ func (r *DNSPolicyReconciler) deleteResources(ctx context.Context, dnsPolicy *v1
 	return r.updateGatewayCondition(ctx, metav1.Condition{Type: string(DNSPolicyAffected)}, gatewayDiffObj)
 }
 
+func targetGatewayKey(dnsPolicy *v1alpha1.DNSPolicy) client.ObjectKey {
+	namespace := dnsPolicy.Namespace
+	if dnsPolicy.Spec.TargetRef.Namespace != nil {
+		namespace = string(*dnsPolicy.Spec.TargetRef.Namespace)
+	}
+	return client.ObjectKey{Name: string(dnsPolicy.Spec.TargetRef.Name), Namespace: namespace}
+}
+
 func (r *DNSPolicyReconciler) calculateStatus(dnsPolicy *v1alpha1.DNSPolicy, specErr error) *v1alpha1.DNSPolicyStatus {
 	newStatus := dnsPolicy.Status.DeepCopy()
 	if specErr != nil {
 		newStatus.ObservedGeneration = dnsPolicy.Generation
+	} else {
+		newStatus.ObservedForceSync = metadata.GetAnnotation(dnsPolicy, metadata.ForceSyncAnnotation)
 	}
 	readyCond := r.readyCondition(string(dnsPolicy.Spec.TargetRef.Kind), specErr)
 	meta.SetStatusCondition(&newStatus.Conditions, *readyCond)
+	meta.RemoveStatusCondition(&newStatus.Conditions, string(conditions.ConditionTypePaused))
 	return newStatus
 }
 
@@ -310,5 +402,10 @@ func (r *DNSPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&source.Kind{Type: &v1alpha1.DNSHealthCheckProbe{}},
 			handler.EnqueueRequestsFromMapFunc(probeEventMapper.MapToPolicy),
 		).
-		Complete(r)
+		Watches(
+			&source.Kind{Type: &v1alpha1.DNSRecord{}},
+			handler.EnqueueRequestsFromMapFunc(r.policiesWithHostConflict),
+		).
+		WithOptions(r.ControllerOptions()).
+		Complete(r.WrapReconciler(r))
 }
@@ -2,20 +2,42 @@ package dnspolicy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/logging"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	mgcgateway "github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
 )
 
+const (
+	// DNSRecordCleanupTimeout is how long the deletion of a gateway is held while its DNS records are removed from
+	// the provider
+	DNSRecordCleanupTimeout = 5 * time.Minute
+
+	minCleanupRetryInterval = time.Second
+	maxCleanupRetryInterval = 30 * time.Second
+
+	EventReasonDNSRecordCleanupTimeout = "DNSRecordCleanupTimeout"
+)
+
 func (r *DNSPolicyReconciler) reconcileDNSRecords(ctx context.Context, dnsPolicy *v1alpha1.DNSPolicy, gwDiffObj *reconcilers.GatewayDiff) error {
 	log := crlog.FromContext(ctx)
 
@@ -25,23 +47,288 @@ func (r *DNSPolicyReconciler) reconcileDNSRecords(ctx context.Context, dnsPolicy
 		if err != nil {
 			return err
 		}
+		if err := r.RemoveFinalizer(ctx, gw.Gateway, DNSPolicyGatewayFinalizer); client.IgnoreNotFound(err) != nil {
+			return err
+		}
 	}
 
 	// Reconcile DNSRecords for each gateway directly referred by the policy (existing and new)
+	clustersWithoutRegion := sets.New[string]()
+	clustersWithDefaultGeo := sets.New[string]()
+	unhealthyHosts := sets.New[string]()
+	excludedClusters := sets.New[string]()
+	recordsStatus := &recordsPublishedStatus{}
+	if ramp := weightRampDuration(dnsPolicy); ramp > 0 {
+		recordsStatus.weightRamp = newWeightRamp(ramp, dnsPolicy.Status.ClusterWeights, time.Now())
+	}
+	if drain := drainDuration(dnsPolicy); drain > 0 {
+		recordsStatus.endpointDrain = newEndpointDrain(drain, dnsPolicy.Status.DrainingEndpoints, time.Now())
+	}
 	for _, gw := range append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...) {
 		log.V(1).Info("reconcileDNSRecords: gateway with valid and missing policy ref", "key", gw.Key())
-		err := r.reconcileGatewayDNSRecords(ctx, gw.Gateway, dnsPolicy)
+		err := r.reconcileGatewayDNSRecords(ctx, gw.Gateway, dnsPolicy, clustersWithoutRegion, clustersWithDefaultGeo, unhealthyHosts, excludedClusters, recordsStatus)
 		if err != nil {
 			return err
 		}
 	}
 
+	setLatencyRoutingCondition(dnsPolicy, sets.List(clustersWithoutRegion))
+	setGeoproximityRoutingCondition(dnsPolicy, sets.List(sets.New(recordsStatus.clustersWithoutLocation...)))
+	setDefaultGeoCondition(dnsPolicy, sets.List(clustersWithDefaultGeo))
+	setHealthCheckCondition(dnsPolicy, sets.List(unhealthyHosts))
+	setClustersExcludedCondition(dnsPolicy, sets.List(excludedClusters))
+	setListenersNotProgrammedCondition(dnsPolicy, recordsStatus.notProgrammed)
+	setHostConflictCondition(dnsPolicy, recordsStatus)
+	setRecordsPublishedCondition(dnsPolicy, recordsStatus)
+	dnsPolicy.Status.ManagedZones = recordsStatus.managedZones
+	dnsPolicy.Status.ClusterGeos = recordsStatus.sortedClusterGeos()
+	dnsPolicy.Status.ClusterWeights = nil
+	if recordsStatus.weightRamp != nil {
+		dnsPolicy.Status.ClusterWeights = recordsStatus.weightRamp.sortedWeights()
+	}
+	dnsPolicy.Status.DrainingEndpoints = nil
+	if recordsStatus.endpointDrain != nil {
+		dnsPolicy.Status.DrainingEndpoints = recordsStatus.endpointDrain.sortedEndpoints()
+	}
+
 	return nil
 }
 
-func (r *DNSPolicyReconciler) reconcileGatewayDNSRecords(ctx context.Context, gateway *gatewayv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy) error {
+// unpublishedListener is a gateway listener for which no DNS records are published, and the reason why
+type unpublishedListener struct {
+	gateway  string
+	listener string
+	reason   string
+	message  string
+}
+
+// recordsPublishedStatus collects the gateways and listeners the DNS records are published for, the managed zones
+// selected for the listeners, the geo codes resolved for the clusters, the weights ramped for them and the endpoints
+// draining
+type recordsPublishedStatus struct {
+	gateways     []string
+	unpublished  []unpublishedListener
+	managedZones []v1alpha1.ListenerManagedZone
+	clusterGeos  map[string]v1alpha1.ClusterGeo
+	// notProgrammed lists the listeners left out of DNS in a cluster as they are not programmed there
+	notProgrammed []string
+	// weightRamp is nil when the weights are not ramped
+	weightRamp *weightRamp
+	// endpointDrain is nil when the endpoints of the clusters leaving the gateways are not drained
+	endpointDrain *endpointDrain
+	// clustersWithoutLocation are the target clusters preventing geoproximity routing as they have no location
+	clustersWithoutLocation []string
+}
+
+func (s *recordsPublishedStatus) geosResolved(clusterGeos []v1alpha1.ClusterGeo) {
+	if s.clusterGeos == nil {
+		s.clusterGeos = map[string]v1alpha1.ClusterGeo{}
+	}
+	for _, clusterGeo := range clusterGeos {
+		s.clusterGeos[clusterGeo.Cluster] = clusterGeo
+	}
+}
+
+func (s *recordsPublishedStatus) sortedClusterGeos() []v1alpha1.ClusterGeo {
+	var clusterGeos []v1alpha1.ClusterGeo
+	for _, cluster := range sets.List(sets.KeySet(s.clusterGeos)) {
+		clusterGeos = append(clusterGeos, s.clusterGeos[cluster])
+	}
+	return clusterGeos
+}
+
+func (s *recordsPublishedStatus) managedZoneSelected(gateway *gatewayv1beta1.Gateway, listener gatewayv1beta1.Listener, managedZone *v1alpha1.ManagedZone) {
+	s.managedZones = append(s.managedZones, v1alpha1.ListenerManagedZone{
+		Gateway:     gateway.Name,
+		Listener:    string(listener.Name),
+		ManagedZone: managedZone.Name,
+		Domain:      managedZone.Spec.DomainName,
+	})
+}
+
+func (s *recordsPublishedStatus) notPublished(gateway *gatewayv1beta1.Gateway, listener gatewayv1beta1.Listener, reason, message string) {
+	s.unpublished = append(s.unpublished, unpublishedListener{
+		gateway:  gateway.Name,
+		listener: string(listener.Name),
+		reason:   reason,
+		message:  message,
+	})
+}
+
+func (s *recordsPublishedStatus) listenerNotProgrammed(gateway *gatewayv1beta1.Gateway, listener gatewayv1beta1.Listener, cluster string) {
+	s.notProgrammed = append(s.notProgrammed, fmt.Sprintf("%s/%s in cluster %s", gateway.Name, listener.Name, cluster))
+}
+
+// listenerProgrammed returns false when the listener of the gateway is reported as not programmed in the cluster. A
+// listener without a Programmed condition for the cluster, e.g. as its downstream gateway sets no listener
+// conditions, is considered programmed.
+func listenerProgrammed(gateway *gatewayv1beta1.Gateway, cluster string, listener gatewayv1beta1.Listener) bool {
+	name := mgcgateway.ClusterListenerStatusName(cluster, string(listener.Name))
+	for _, status := range gateway.Status.Listeners {
+		if status.Name != name {
+			continue
+		}
+		if cond := meta.FindStatusCondition(status.Conditions, string(gatewayv1beta1.ListenerConditionProgrammed)); cond != nil {
+			return cond.Status == metav1.ConditionTrue
+		}
+	}
+	return true
+}
+
+// listenerLoadBalancing returns the load balancing of the records of the listener, with the weights of the listener
+// when the policy sets them
+func listenerLoadBalancing(dnsPolicy *v1alpha1.DNSPolicy, listener gatewayv1beta1.Listener) *v1alpha1.LoadBalancingSpec {
+	lb := dnsPolicy.Spec.LoadBalancing
+	if lb == nil || lb.Weighted == nil || len(lb.Weighted.Listeners) == 0 {
+		return lb
+	}
+	listenerLB := *lb
+	listenerLB.Weighted = lb.Weighted.ForListener(string(listener.Name))
+	return &listenerLB
+}
+
+// setListenersNotProgrammedCondition sets a condition on the policy listing the listeners left out of the DNS
+// records in the clusters they are not programmed in
+func setListenersNotProgrammedCondition(dnsPolicy *v1alpha1.DNSPolicy, notProgrammed []string) {
+	if len(notProgrammed) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyListenersNotProgrammed))
+		return
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(DNSPolicyListenersNotProgrammed),
+		Status:             metav1.ConditionTrue,
+		Reason:             "ListenersNotProgrammed",
+		Message:            fmt.Sprintf("listeners %s are not programmed and their clusters are not published in DNS", strings.Join(notProgrammed, ", ")),
+		ObservedGeneration: dnsPolicy.Generation,
+	})
+}
+
+// setRecordsPublishedCondition sets a condition on the policy reporting whether DNS records are published for every
+// listener of the target gateways. When they are not, the reason of the first unpublished listener is used and the
+// message explains why records are missing for each of them.
+func setRecordsPublishedCondition(dnsPolicy *v1alpha1.DNSPolicy, status *recordsPublishedStatus) {
+	if len(status.gateways) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyRecordsPublished))
+		return
+	}
+	cond := metav1.Condition{
+		Type:               string(DNSPolicyRecordsPublished),
+		Status:             metav1.ConditionTrue,
+		Reason:             DNSPolicyReasonRecordsPublished,
+		Message:            fmt.Sprintf("DNS records are published for all listeners of gateways %v", status.gateways),
+		ObservedGeneration: dnsPolicy.Generation,
+	}
+	if len(status.unpublished) > 0 {
+		var messages []string
+		for _, unpublished := range status.unpublished {
+			messages = append(messages, fmt.Sprintf("gateway %s listener %s: %s", unpublished.gateway, unpublished.listener, unpublished.message))
+		}
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = status.unpublished[0].reason
+		cond.Message = fmt.Sprintf("no DNS records published for %s", strings.Join(messages, "; "))
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, cond)
+}
+
+// setLatencyRoutingCondition sets a warning condition on the policy when latency routing is requested but weighted
+// routing is used because some target clusters have no region set
+func setLatencyRoutingCondition(dnsPolicy *v1alpha1.DNSPolicy, clustersWithoutRegion []string) {
+	if dnsPolicy.Spec.LoadBalancing == nil || dnsPolicy.Spec.LoadBalancing.Latency == nil || len(clustersWithoutRegion) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyLatencyRoutingDegraded))
+		return
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(DNSPolicyLatencyRoutingDegraded),
+		Status:             metav1.ConditionTrue,
+		Reason:             "MissingClusterRegion",
+		Message:            fmt.Sprintf("clusters %v have no %s annotation, using weighted routing", clustersWithoutRegion, dns.AnnotationLBAttributeRegion),
+		ObservedGeneration: dnsPolicy.Generation,
+	})
+}
+
+// setGeoproximityRoutingCondition sets a warning condition on the policy when geoproximity routing is requested but
+// weighted routing is used because some target clusters have no location set
+func setGeoproximityRoutingCondition(dnsPolicy *v1alpha1.DNSPolicy, clustersWithoutLocation []string) {
+	if dnsPolicy.Spec.LoadBalancing == nil || dnsPolicy.Spec.LoadBalancing.Geoproximity == nil || len(clustersWithoutLocation) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyGeoproximityRoutingDegraded))
+		return
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(DNSPolicyGeoproximityRoutingDegraded),
+		Status:             metav1.ConditionTrue,
+		Reason:             "MissingClusterLocation",
+		Message:            fmt.Sprintf("clusters %v have neither a valid %s nor a %s annotation, using weighted routing", clustersWithoutLocation, dns.AnnotationLBAttributeCoordinates, dns.AnnotationLBAttributeRegion),
+		ObservedGeneration: dnsPolicy.Generation,
+	})
+}
+
+// setDefaultGeoCondition sets a condition on the policy listing the target clusters that have no geo code and were
+// assigned the default geo
+func setDefaultGeoCondition(dnsPolicy *v1alpha1.DNSPolicy, clustersWithDefaultGeo []string) {
+	if dnsPolicy.Spec.LoadBalancing == nil || dnsPolicy.Spec.LoadBalancing.Geo == nil || len(clustersWithDefaultGeo) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyDefaultGeoAssigned))
+		return
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(DNSPolicyDefaultGeoAssigned),
+		Status:             metav1.ConditionTrue,
+		Reason:             "MissingClusterGeo",
+		Message:            fmt.Sprintf("clusters %v have no %s label or mapped region, assigned default geo %s", clustersWithDefaultGeo, dns.LabelLBAttributeGeoCode, dnsPolicy.Spec.LoadBalancing.Geo.DefaultGeo),
+		ObservedGeneration: dnsPolicy.Generation,
+	})
+}
+
+// setHealthCheckCondition sets a condition on the policy when every endpoint of a listener host is unhealthy and the
+// full set of endpoints is kept published instead of an empty record
+func setHealthCheckCondition(dnsPolicy *v1alpha1.DNSPolicy, unhealthyHosts []string) {
+	if len(unhealthyHosts) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyHealthCheckDegraded))
+		return
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(DNSPolicyHealthCheckDegraded),
+		Status:             metav1.ConditionTrue,
+		Reason:             "AllEndpointsUnhealthy",
+		Message:            fmt.Sprintf("all endpoints for hosts %v are unhealthy, publishing all endpoints", unhealthyHosts),
+		ObservedGeneration: dnsPolicy.Generation,
+	})
+}
+
+// setClustersExcludedCondition sets a condition on the policy listing the target clusters matching the excludeClusters
+// selector and left out of the DNS records
+func setClustersExcludedCondition(dnsPolicy *v1alpha1.DNSPolicy, excludedClusters []string) {
+	if len(excludedClusters) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyClustersExcluded))
+		return
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(DNSPolicyClustersExcluded),
+		Status:             metav1.ConditionTrue,
+		Reason:             "ClustersExcluded",
+		Message:            fmt.Sprintf("clusters %v match the excludeClusters selector and are not published in DNS", excludedClusters),
+		ObservedGeneration: dnsPolicy.Generation,
+	})
+}
+
+func (r *DNSPolicyReconciler) reconcileGatewayDNSRecords(ctx context.Context, gateway *gatewayv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy, clustersWithoutRegion, clustersWithDefaultGeo, unhealthyHosts, excludedClusters sets.Set[string], recordsStatus *recordsPublishedStatus) error {
 	log := crlog.FromContext(ctx)
 
+	excludeSelector := labels.Nothing()
+	if dnsPolicy.Spec.ExcludeClusters != nil {
+		selector, err := metav1.LabelSelectorAsSelector(dnsPolicy.Spec.ExcludeClusters)
+		if err != nil {
+			return fmt.Errorf("invalid excludeClusters selector: %w", err)
+		}
+		excludeSelector = selector
+	}
+
+	// the finalizer holds the deletion of the gateway until its DNS records are removed from the provider
+	if !controllerutil.ContainsFinalizer(gateway, DNSPolicyGatewayFinalizer) {
+		if err := r.AddFinalizer(ctx, gateway, DNSPolicyGatewayFinalizer); err != nil {
+			return err
+		}
+	}
+
 	if err := r.dnsHelper.removeDNSForDeletedListeners(ctx, gateway); err != nil {
 		log.V(3).Info("error removing DNS for deleted listeners")
 		return err
@@ -53,20 +340,42 @@ func (r *DNSPolicyReconciler) reconcileGatewayDNSRecords(ctx context.Context, ga
 		return err
 	}
 	clusters := placed.UnsortedList()
+	recordsStatus.gateways = append(recordsStatus.gateways, gateway.Name)
 
 	log.V(3).Info("checking gateway for attached routes ", "gateway", gateway.Name, "clusters", placed)
 
 	for _, listener := range gateway.Spec.Listeners {
 		var clusterGateways []dns.ClusterGateway
-		var mz, err = r.dnsHelper.getManagedZoneForListener(ctx, gateway.Namespace, listener)
+		notProgrammed := sets.New[string]()
+		if listener.Hostname == nil || *listener.Hostname == "" {
+			log.Info("skipping listener no hostname assigned", listener.Name, "in ns ", gateway.Namespace)
+			continue
+		}
+		listenerHost := *listener.Hostname
+		var mz, err = r.dnsHelper.getManagedZoneForListener(ctx, gateway.Namespace, listener, dnsPolicy)
+		if errors.Is(err, ErrNoManagedZoneForHost) {
+			log.Info("skipping listener with no matching managed zone", "listener", listener.Name, "error", err)
+			recordsStatus.notPublished(gateway, listener, DNSPolicyReasonNoMatchingManagedZone, err.Error())
+			continue
+		}
 		if err != nil {
 			return err
 		}
-		listenerHost := *listener.Hostname
-		if listenerHost == "" {
-			log.Info("skipping listener no hostname assigned", listener.Name, "in ns ", gateway.Namespace)
+		recordsStatus.managedZoneSelected(gateway, listener, mz)
+		conflict, err := r.findHostConflict(ctx, gateway, dnsPolicy, listener, mz)
+		if err != nil {
+			return err
+		}
+		if conflict != nil {
+			log.Info("skipping listener with a host published by another policy", "listener", listener.Name, "conflict", conflict.String())
+			// a record created for the listener after the one of the other policy is removed, leaving the host to it
+			if err := r.dnsHelper.deleteDNSRecordForListener(ctx, gateway, listener); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to delete dns record for listener %s : %s", listener.Name, err)
+			}
+			recordsStatus.notPublished(gateway, listener, DNSPolicyReasonHostConflict, conflict.String())
 			continue
 		}
+		ctx, log := logging.With(ctx, logging.KeyManagedZone, client.ObjectKeyFromObject(mz))
 		for _, downstreamCluster := range clusters {
 			// Only consider host for dns if there's at least 1 attached route to the listener for this host in *any* gateway
 
@@ -81,10 +390,21 @@ func (r *DNSPolicyReconciler) reconcileGatewayDNSRecords(ctx context.Context, ga
 				continue
 			}
 			log.V(1).Info("hostHasAttachedRoutes", "host", listener.Name, "hostHasAttachedRoutes", attached)
+			if !listenerProgrammed(gateway, downstreamCluster, listener) {
+				log.Info("listener is not programmed in the cluster, leaving it out of DNS", "listener", listener.Name, "cluster", downstreamCluster)
+				notProgrammed.Insert(downstreamCluster)
+				recordsStatus.listenerNotProgrammed(gateway, listener, downstreamCluster)
+				continue
+			}
 			cg, err := r.Placer.GetClusterGateway(ctx, gateway, downstreamCluster)
 			if err != nil {
 				return fmt.Errorf("get cluster gateway failed: %s", err)
 			}
+			if excludeSelector.Matches(labels.Set(cg.Cluster.GetLabels())) {
+				log.V(1).Info("cluster matches the excludeClusters selector, leaving it out of DNS", "listener", listener.Name, "cluster", downstreamCluster)
+				excludedClusters.Insert(cg.Cluster.GetName())
+				continue
+			}
 			clusterGateways = append(clusterGateways, cg)
 		}
 
@@ -94,7 +414,12 @@ func (r *DNSPolicyReconciler) reconcileGatewayDNSRecords(ctx context.Context, ga
 			if err := r.dnsHelper.deleteDNSRecordForListener(ctx, gateway, listener); client.IgnoreNotFound(err) != nil {
 				return fmt.Errorf("failed to delete dns record for listener %s : %s", listener.Name, err)
 			}
-			return nil
+			message := fmt.Sprintf("none of the clusters %v has routes attached to the listener", sets.List(placed))
+			if notProgrammed.Len() > 0 {
+				message = fmt.Sprintf("the listener is not programmed in clusters %v and none of the other clusters %v has routes attached to it", sets.List(notProgrammed), sets.List(placed.Difference(notProgrammed)))
+			}
+			recordsStatus.notPublished(gateway, listener, DNSPolicyReasonNoReadyClusters, message)
+			continue
 		}
 		dnsRecord, err := r.dnsHelper.createDNSRecordForListener(ctx, gateway, dnsPolicy, mz, listener)
 		if err := client.IgnoreAlreadyExists(err); err != nil {
@@ -107,17 +432,46 @@ func (r *DNSPolicyReconciler) reconcileGatewayDNSRecords(ctx context.Context, ga
 			}
 		}
 
-		mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, dnsPolicy.Spec.LoadBalancing)
+		mcgTarget, err := dns.NewMultiClusterGatewayTarget(gateway, clusterGateways, listenerLoadBalancing(dnsPolicy, listener))
 		if err != nil {
 			return fmt.Errorf("failed to create multi cluster gateway target for listener %s : %s ", listener.Name, err)
 		}
 		log.Info("setting dns dnsTargets for gateway listener", "listener", dnsRecord.Name, "values", mcgTarget)
+		// the load balancing of a policy delegating to a CNAME is left to the CNAME target
+		if dnsPolicy.Spec.DelegateToCNAME == nil {
+			if dnsPolicy.Spec.LoadBalancing != nil && dnsPolicy.Spec.LoadBalancing.Latency != nil && !mcgTarget.IsLatencyRouting() {
+				log.Info("latency routing requested but not all clusters have a region, using weighted routing", "listener", listener.Name)
+				clustersWithoutRegion.Insert(mcgTarget.ClustersWithoutRegion()...)
+			}
+			if dnsPolicy.Spec.LoadBalancing != nil && dnsPolicy.Spec.LoadBalancing.Geoproximity != nil && !mcgTarget.IsGeoproximityRouting() {
+				log.Info("geoproximity routing requested but not all clusters have a location, using weighted routing", "listener", listener.Name)
+				recordsStatus.clustersWithoutLocation = append(recordsStatus.clustersWithoutLocation, mcgTarget.ClustersWithoutLocation()...)
+			}
+			clustersWithDefaultGeo.Insert(mcgTarget.ClustersWithDefaultGeo()...)
+			recordsStatus.geosResolved(mcgTarget.ClusterGeos())
+			if recordsStatus.weightRamp != nil {
+				recordsStatus.weightRamp.apply(mcgTarget)
+			}
+		}
 
-		if err := r.dnsHelper.setEndpoints(ctx, mcgTarget, dnsRecord, dnsPolicy, listener); err != nil {
+		allUnhealthy, err := r.dnsHelper.setEndpoints(ctx, mcgTarget, dnsRecord, dnsPolicy, listener, recordsStatus.endpointDrain)
+		if err != nil {
 			return fmt.Errorf("failed to add dns record dnsTargets %s %v", err, mcgTarget)
 		}
+		if allUnhealthy {
+			log.Info("all endpoints are unhealthy, publishing all endpoints", "listener", listener.Name)
+			unhealthyHosts.Insert(string(listenerHost))
+		}
+		if len(dnsRecord.Spec.Endpoints) == 0 {
+			clusterNames := sets.New[string]()
+			for _, cg := range clusterGateways {
+				clusterNames.Insert(cg.Cluster.GetName())
+			}
+			recordsStatus.notPublished(gateway, listener, DNSPolicyReasonNoGatewayAddresses,
+				fmt.Sprintf("the gateway has no addresses in clusters %v", sets.List(clusterNames)))
+		}
 	}
-	return nil
+	return r.reconcileStaticDNSRecords(ctx, gateway, dnsPolicy)
 }
 
 func (r *DNSPolicyReconciler) deleteGatewayDNSRecords(ctx context.Context, gateway *gatewayv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy) error {
@@ -137,3 +491,57 @@ func (r *DNSPolicyReconciler) deleteGatewayDNSRecords(ctx context.Context, gatew
 	}
 	return nil
 }
+
+// reconcileGatewayDeletion deletes the DNSRecords of a gateway being deleted and releases the gateway finalizer once
+// the DNSRecords, and so the provider records, are gone. While the provider records are being removed the gateway is
+// checked again with an increasing interval. The finalizer is released after the DNSRecordCleanupTimeout regardless,
+// so an unreachable provider does not block the gateway deletion forever; the DNSRecords keep retrying the removal.
+func (r *DNSPolicyReconciler) reconcileGatewayDeletion(ctx context.Context, dnsPolicy *v1alpha1.DNSPolicy, gateway *gatewayv1beta1.Gateway) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(gateway, DNSPolicyGatewayFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.deleteGatewayDNSRecords(ctx, gateway, dnsPolicy); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	listOptions := &client.ListOptions{LabelSelector: labels.SelectorFromSet(commonDNSRecordLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(dnsPolicy)))}
+	recordsList := &v1alpha1.DNSRecordList{}
+	if err := r.Client().List(ctx, recordsList, listOptions); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if len(recordsList.Items) > 0 {
+		deletingFor := time.Since(gateway.GetDeletionTimestamp().Time)
+		if remaining := DNSRecordCleanupTimeout - deletingFor; remaining > 0 {
+			log.V(1).Info("waiting for DNS records to be removed from the provider", "gateway", client.ObjectKeyFromObject(gateway), "dnsRecords", len(recordsList.Items))
+			return ctrl.Result{RequeueAfter: cleanupRetryInterval(deletingFor, remaining)}, nil
+		}
+		log.Info("timed out waiting for DNS records to be removed from the provider, releasing gateway", "gateway", client.ObjectKeyFromObject(gateway), "dnsRecords", len(recordsList.Items))
+		r.EventRecorder().Eventf(dnsPolicy, corev1.EventTypeWarning, EventReasonDNSRecordCleanupTimeout,
+			"timed out after %s waiting for %d DNS records of gateway %s to be removed from the provider", DNSRecordCleanupTimeout, len(recordsList.Items), gateway.Name)
+	}
+
+	if err := r.RemoveFinalizer(ctx, gateway, DNSPolicyGatewayFinalizer); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// cleanupRetryInterval returns an interval that grows with the time the gateway has been deleting for, bounded by the
+// time remaining before the cleanup timeout
+func cleanupRetryInterval(deletingFor, remaining time.Duration) time.Duration {
+	interval := deletingFor
+	if interval < minCleanupRetryInterval {
+		interval = minCleanupRetryInterval
+	}
+	if interval > maxCleanupRetryInterval {
+		interval = maxCleanupRetryInterval
+	}
+	if interval > remaining {
+		interval = remaining
+	}
+	return interval
+}
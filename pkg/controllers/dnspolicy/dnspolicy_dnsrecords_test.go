@@ -0,0 +1,976 @@
+//go:build unit
+
+package dnspolicy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestDNSPolicyReconciler_reconcileGatewayDeletion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s", err)
+	}
+	if err := gatewayapiv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s", err)
+	}
+
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+	}
+	newGateway := func(deletingFor time.Duration) *gatewayapiv1beta1.Gateway {
+		return &gatewayapiv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-gateway",
+				Namespace:         "test",
+				Finalizers:        []string{DNSPolicyGatewayFinalizer},
+				DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-deletingFor)},
+			},
+		}
+	}
+	// the record finalizer stands in for the DNSRecord controller waiting for the provider records to be removed
+	newDNSRecord := func() *v1alpha1.DNSRecord {
+		return &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-gateway-test",
+				Namespace:  "test",
+				Labels:     commonDNSRecordLabels(client.ObjectKey{Name: "test-gateway", Namespace: "test"}, client.ObjectKeyFromObject(dnsPolicy)),
+				Finalizers: []string{"kuadrant.io/dns-record"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		gateway       *gatewayapiv1beta1.Gateway
+		objects       []client.Object
+		wantRequeue   bool
+		wantFinalizer bool
+	}{
+		{
+			name:          "releases the gateway when it has no DNS records",
+			gateway:       newGateway(time.Second),
+			wantFinalizer: false,
+		},
+		{
+			name:          "holds the gateway while the DNS records are being removed",
+			gateway:       newGateway(time.Second),
+			objects:       []client.Object{newDNSRecord()},
+			wantRequeue:   true,
+			wantFinalizer: true,
+		},
+		{
+			name:          "releases the gateway after the cleanup timeout",
+			gateway:       newGateway(DNSRecordCleanupTimeout + time.Second),
+			objects:       []client.Object{newDNSRecord()},
+			wantFinalizer: false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(testCase.objects, testCase.gateway)...).Build()
+			r := &DNSPolicyReconciler{
+				TargetRefReconciler: reconcilers.TargetRefReconciler{
+					BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+				},
+			}
+
+			ctx := logr.NewContext(context.TODO(), logr.Discard())
+			result, err := r.reconcileGatewayDeletion(ctx, dnsPolicy, testCase.gateway)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got := result.RequeueAfter > 0; got != testCase.wantRequeue {
+				t.Errorf("expected requeue %v, got %v", testCase.wantRequeue, result)
+			}
+
+			gateway := &gatewayapiv1beta1.Gateway{}
+			err = f.Get(context.TODO(), client.ObjectKeyFromObject(testCase.gateway), gateway)
+			if testCase.wantFinalizer {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				if !controllerutil.ContainsFinalizer(gateway, DNSPolicyGatewayFinalizer) {
+					t.Errorf("expected the gateway to keep the %s finalizer", DNSPolicyGatewayFinalizer)
+				}
+			} else if !apierrors.IsNotFound(err) {
+				t.Errorf("expected the gateway to be released and deleted, got %v", err)
+			}
+
+			records := &v1alpha1.DNSRecordList{}
+			if err := f.List(context.TODO(), records); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			for _, record := range records.Items {
+				if record.DeletionTimestamp == nil {
+					t.Errorf("expected DNSRecord %s to be deleted", record.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestDNSPolicyReconciler_reconcileGatewayDeletion_drain(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s", err)
+	}
+	if err := gatewayapiv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s", err)
+	}
+
+	// there is no other cluster to drain the records of a deleted gateway to, they are deleted right away
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			DNSDrainDuration: &metav1.Duration{Duration: 2 * time.Minute},
+		},
+	}
+	gateway := &gatewayapiv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-gateway",
+			Namespace:         "test",
+			Finalizers:        []string{DNSPolicyGatewayFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-gateway-test",
+			Namespace:  "test",
+			Labels:     commonDNSRecordLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(dnsPolicy)),
+			Finalizers: []string{"kuadrant.io/dns-record"},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*v1alpha1.Endpoint{
+				(&v1alpha1.Endpoint{DNSName: "test.example.com", RecordType: "CNAME", SetIdentifier: "aaa", Targets: []string{"aaa.lb.example.com"}}).
+					WithProviderSpecific(dns.ProviderSpecificWeight, "120"),
+				{DNSName: "aaa.lb.example.com", RecordType: "A", Targets: []string{"1.1.1.1"}},
+			},
+		},
+	}
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway, dnsRecord).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+	if _, err := r.reconcileGatewayDeletion(ctx, dnsPolicy, gateway); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	got := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got.DeletionTimestamp == nil {
+		t.Errorf("expected DNSRecord %s to be deleted", got.Name)
+	}
+}
+
+func TestCleanupRetryInterval(t *testing.T) {
+	testCases := []struct {
+		name        string
+		deletingFor time.Duration
+		remaining   time.Duration
+		want        time.Duration
+	}{
+		{name: "starts at the minimum interval", deletingFor: 0, remaining: time.Minute, want: minCleanupRetryInterval},
+		{name: "grows with the deletion time", deletingFor: 10 * time.Second, remaining: time.Minute, want: 10 * time.Second},
+		{name: "is capped at the maximum interval", deletingFor: 2 * time.Minute, remaining: 3 * time.Minute, want: maxCleanupRetryInterval},
+		{name: "does not go beyond the timeout", deletingFor: 20 * time.Second, remaining: 5 * time.Second, want: 5 * time.Second},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := cleanupRetryInterval(testCase.deletingFor, testCase.remaining); got != testCase.want {
+				t.Errorf("cleanupRetryInterval() = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
+
+// testGatewayPlacer places the gateway on the clusters with a cluster gateway, with a route attached to every
+// listener
+type testGatewayPlacer struct {
+	gateway.GatewayPlacer
+	clusterGateways map[string]dns.ClusterGateway
+}
+
+func (p *testGatewayPlacer) GetPlacedClusters(_ context.Context, _ *gatewayapiv1beta1.Gateway) (sets.Set[string], error) {
+	placed := sets.New[string]()
+	for cluster := range p.clusterGateways {
+		placed.Insert(cluster)
+	}
+	return placed, nil
+}
+
+func (p *testGatewayPlacer) ListenerTotalAttachedRoutes(_ context.Context, _ *gatewayapiv1beta1.Gateway, _ string, _ string) (int, error) {
+	return 1, nil
+}
+
+func (p *testGatewayPlacer) GetClusterGateway(_ context.Context, _ *gatewayapiv1beta1.Gateway, cluster string) (dns.ClusterGateway, error) {
+	return p.clusterGateways[cluster], nil
+}
+
+func TestDNSPolicyReconciler_reconcileGatewayDNSRecords_recordsPublished(t *testing.T) {
+	scheme := testScheme(t)
+
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test", Generation: 2},
+	}
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	clusterGateway := func(addresses ...string) dns.ClusterGateway {
+		cg := dns.ClusterGateway{
+			Cluster: &testutil.TestResource{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-1"}},
+		}
+		for _, address := range addresses {
+			cg.GatewayAddresses = append(cg.GatewayAddresses, gatewayapiv1beta1.GatewayAddress{
+				Type:  testutil.Pointer(gatewayapiv1beta1.IPAddressType),
+				Value: address,
+			})
+		}
+		return cg
+	}
+
+	testCases := []struct {
+		name            string
+		hostname        string
+		objects         []client.Object
+		clusterGateways map[string]dns.ClusterGateway
+		wantStatus      metav1.ConditionStatus
+		wantReason      string
+	}{
+		{
+			name:            "records are published",
+			hostname:        "test.example.com",
+			objects:         []client.Object{managedZone},
+			clusterGateways: map[string]dns.ClusterGateway{"test-cluster-1": clusterGateway("1.1.1.1")},
+			wantStatus:      metav1.ConditionTrue,
+			wantReason:      DNSPolicyReasonRecordsPublished,
+		},
+		{
+			name:            "no managed zone matches the listener host",
+			hostname:        "test.example.org",
+			objects:         []client.Object{managedZone},
+			clusterGateways: map[string]dns.ClusterGateway{"test-cluster-1": clusterGateway("1.1.1.1")},
+			wantStatus:      metav1.ConditionFalse,
+			wantReason:      DNSPolicyReasonNoMatchingManagedZone,
+		},
+		{
+			name:       "the gateway is not placed on any cluster",
+			hostname:   "test.example.com",
+			objects:    []client.Object{managedZone},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: DNSPolicyReasonNoReadyClusters,
+		},
+		{
+			name:            "the gateway has no addresses",
+			hostname:        "test.example.com",
+			objects:         []client.Object{managedZone},
+			clusterGateways: map[string]dns.ClusterGateway{"test-cluster-1": clusterGateway()},
+			wantStatus:      metav1.ConditionFalse,
+			wantReason:      DNSPolicyReasonNoGatewayAddresses,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			gw := &gatewayapiv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-gateway", Namespace: "test"},
+				Spec: gatewayapiv1beta1.GatewaySpec{
+					Listeners: []gatewayapiv1beta1.Listener{getTestListener(testCase.hostname)},
+				},
+			}
+			f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(testCase.objects, gw)...).Build()
+			r := &DNSPolicyReconciler{
+				TargetRefReconciler: reconcilers.TargetRefReconciler{
+					BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+				},
+				dnsHelper: dnsHelper{Client: f},
+				Placer:    &testGatewayPlacer{clusterGateways: testCase.clusterGateways},
+			}
+
+			ctx := logr.NewContext(context.TODO(), logr.Discard())
+			recordsStatus := &recordsPublishedStatus{}
+			err := r.reconcileGatewayDNSRecords(ctx, gw, dnsPolicy, sets.New[string](), sets.New[string](), sets.New[string](), sets.New[string](), recordsStatus)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			wantZones := 1
+			if testCase.wantReason == DNSPolicyReasonNoMatchingManagedZone {
+				wantZones = 0
+			}
+			if len(recordsStatus.managedZones) != wantZones {
+				t.Fatalf("expected %d selected managed zones, got %v", wantZones, recordsStatus.managedZones)
+			}
+			if wantZones > 0 && recordsStatus.managedZones[0].ManagedZone != managedZone.Name {
+				t.Errorf("expected managed zone %s to be selected, got %v", managedZone.Name, recordsStatus.managedZones[0])
+			}
+
+			setRecordsPublishedCondition(dnsPolicy, recordsStatus)
+			cond := meta.FindStatusCondition(dnsPolicy.Status.Conditions, string(DNSPolicyRecordsPublished))
+			if cond == nil {
+				t.Fatalf("expected the %s condition to be set", DNSPolicyRecordsPublished)
+			}
+			if cond.Status != testCase.wantStatus || cond.Reason != testCase.wantReason {
+				t.Errorf("expected condition %s with reason %s, got %s %s", testCase.wantStatus, testCase.wantReason, cond.Status, cond.Reason)
+			}
+			if !strings.Contains(cond.Message, gw.Name) {
+				t.Errorf("expected the condition message to include the gateway name, got %q", cond.Message)
+			}
+			if cond.ObservedGeneration != dnsPolicy.Generation {
+				t.Errorf("expected observed generation %d, got %d", dnsPolicy.Generation, cond.ObservedGeneration)
+			}
+		})
+	}
+
+	setRecordsPublishedCondition(dnsPolicy, &recordsPublishedStatus{})
+	if meta.FindStatusCondition(dnsPolicy.Status.Conditions, string(DNSPolicyRecordsPublished)) != nil {
+		t.Errorf("expected the %s condition to be removed without target gateways", DNSPolicyRecordsPublished)
+	}
+}
+
+func TestDNSPolicyReconciler_reconcileGatewayDNSRecords_clusterGeos(t *testing.T) {
+	scheme := testScheme(t)
+
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			LoadBalancing: &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo:     "US",
+					RegionGeoCodes: []v1alpha1.RegionGeoCode{{Region: "eu-west-1", GeoCode: "IE"}},
+				},
+			},
+		},
+	}
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	gw := &gatewayapiv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gateway", Namespace: "test"},
+		Spec: gatewayapiv1beta1.GatewaySpec{
+			Listeners: []gatewayapiv1beta1.Listener{getTestListener("test.example.com")},
+		},
+	}
+	clusterGateway := func(name string, labels, annotations map[string]string) dns.ClusterGateway {
+		return dns.ClusterGateway{
+			Cluster: &testutil.TestResource{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}},
+			GatewayAddresses: []gatewayapiv1beta1.GatewayAddress{
+				{Type: testutil.Pointer(gatewayapiv1beta1.IPAddressType), Value: "1.1.1.1"},
+			},
+		}
+	}
+	eu := map[string]string{"topology.kubernetes.io/region": "eu-west-1"}
+	clusterGateways := map[string]dns.ClusterGateway{
+		"test-cluster-1": clusterGateway("test-cluster-1", eu, nil),
+		"test-cluster-2": clusterGateway("test-cluster-2", eu, map[string]string{dns.AnnotationLBAttributeGeoCode: "ES"}),
+		"test-cluster-3": clusterGateway("test-cluster-3", nil, nil),
+	}
+
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedZone, gw).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		dnsHelper: dnsHelper{Client: f},
+		Placer:    &testGatewayPlacer{clusterGateways: clusterGateways},
+	}
+
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+	recordsStatus := &recordsPublishedStatus{}
+	clustersWithDefaultGeo := sets.New[string]()
+	err := r.reconcileGatewayDNSRecords(ctx, gw, dnsPolicy, sets.New[string](), clustersWithDefaultGeo, sets.New[string](), sets.New[string](), recordsStatus)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	want := []v1alpha1.ClusterGeo{
+		{Cluster: "test-cluster-1", GeoCode: "IE", Source: v1alpha1.GeoCodeSourceRegion},
+		{Cluster: "test-cluster-2", GeoCode: "ES", Source: v1alpha1.GeoCodeSourceAnnotation},
+		{Cluster: "test-cluster-3", GeoCode: "US", Source: v1alpha1.GeoCodeSourceDefault},
+	}
+	if got := recordsStatus.sortedClusterGeos(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected cluster geos %v, got %v", want, got)
+	}
+	if got := sets.List(clustersWithDefaultGeo); !reflect.DeepEqual(got, []string{"test-cluster-3"}) {
+		t.Errorf("expected only test-cluster-3 to be assigned the default geo, got %v", got)
+	}
+}
+
+func TestDNSPolicyReconciler_reconcileGatewayDNSRecords_listenerNotProgrammed(t *testing.T) {
+	scheme := testScheme(t)
+
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+	}
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	listener := getTestListener("test.example.com")
+	gw := &gatewayapiv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gateway", Namespace: "test"},
+		Spec: gatewayapiv1beta1.GatewaySpec{
+			Listeners: []gatewayapiv1beta1.Listener{listener},
+		},
+	}
+	clusterGateway := func(name, address string) dns.ClusterGateway {
+		return dns.ClusterGateway{
+			Cluster: &testutil.TestResource{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			GatewayAddresses: []gatewayapiv1beta1.GatewayAddress{
+				{Type: testutil.Pointer(gatewayapiv1beta1.IPAddressType), Value: address},
+			},
+		}
+	}
+	clusterGateways := map[string]dns.ClusterGateway{
+		"test-cluster-1": clusterGateway("test-cluster-1", "1.1.1.1"),
+		"test-cluster-2": clusterGateway("test-cluster-2", "2.2.2.2"),
+	}
+	setProgrammed := func(cluster string, status metav1.ConditionStatus) {
+		name := gateway.ClusterListenerStatusName(cluster, string(listener.Name))
+		for i := range gw.Status.Listeners {
+			if gw.Status.Listeners[i].Name == name {
+				gw.Status.Listeners[i].Conditions[0].Status = status
+				return
+			}
+		}
+		gw.Status.Listeners = append(gw.Status.Listeners, gatewayapiv1beta1.ListenerStatus{
+			Name: name,
+			Conditions: []metav1.Condition{
+				{Type: string(gatewayapiv1beta1.ListenerConditionProgrammed), Status: status},
+			},
+		})
+	}
+
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedZone, gw).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		dnsHelper: dnsHelper{Client: f},
+		Placer:    &testGatewayPlacer{clusterGateways: clusterGateways},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	// reconcile returns the targets published for the listener
+	reconcile := func() sets.Set[string] {
+		t.Helper()
+		recordsStatus := &recordsPublishedStatus{}
+		err := r.reconcileGatewayDNSRecords(ctx, gw, dnsPolicy, sets.New[string](), sets.New[string](), sets.New[string](), sets.New[string](), recordsStatus)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		setListenersNotProgrammedCondition(dnsPolicy, recordsStatus.notProgrammed)
+		dnsRecord, err := r.dnsHelper.getDNSRecordForListener(ctx, listener, gw)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		targets := sets.New[string]()
+		for _, endpoint := range dnsRecord.Spec.Endpoints {
+			targets.Insert(endpoint.Targets...)
+		}
+		return targets
+	}
+
+	setProgrammed("test-cluster-1", metav1.ConditionTrue)
+	setProgrammed("test-cluster-2", metav1.ConditionFalse)
+	targets := reconcile()
+	if !targets.Has("1.1.1.1") || targets.Has("2.2.2.2") {
+		t.Errorf("expected only the cluster with a programmed listener to be published, got %v", sets.List(targets))
+	}
+	cond := meta.FindStatusCondition(dnsPolicy.Status.Conditions, string(DNSPolicyListenersNotProgrammed))
+	if cond == nil || cond.Status != metav1.ConditionTrue || !strings.Contains(cond.Message, "test-cluster-2") {
+		t.Errorf("expected the %s condition to list test-cluster-2, got %v", DNSPolicyListenersNotProgrammed, cond)
+	}
+
+	// the cluster is published again once its listener is programmed
+	setProgrammed("test-cluster-2", metav1.ConditionTrue)
+	targets = reconcile()
+	if !targets.Has("1.1.1.1") || !targets.Has("2.2.2.2") {
+		t.Errorf("expected both clusters to be published, got %v", sets.List(targets))
+	}
+	if cond := meta.FindStatusCondition(dnsPolicy.Status.Conditions, string(DNSPolicyListenersNotProgrammed)); cond != nil {
+		t.Errorf("expected the %s condition to be removed, got %v", DNSPolicyListenersNotProgrammed, cond)
+	}
+
+	// a listener without a Programmed condition is published
+	gw.Status.Listeners = nil
+	if targets := reconcile(); !targets.Has("1.1.1.1") || !targets.Has("2.2.2.2") {
+		t.Errorf("expected the listeners without a Programmed condition to be published, got %v", sets.List(targets))
+	}
+}
+
+func TestDNSPolicyReconciler_reconcileGatewayDNSRecords_hostConflict(t *testing.T) {
+	scheme := testScheme(t)
+
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	testGateway := func(name string) *gatewayapiv1beta1.Gateway {
+		return &gatewayapiv1beta1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: gatewayapiv1beta1.GatewaySpec{
+				Listeners: []gatewayapiv1beta1.Listener{getTestListener("test.example.com")},
+			},
+		}
+	}
+	blue, green := testGateway("blue"), testGateway("green")
+	bluePolicy := &v1alpha1.DNSPolicy{ObjectMeta: metav1.ObjectMeta{Name: "blue-dns", Namespace: "test"}}
+	greenPolicy := &v1alpha1.DNSPolicy{ObjectMeta: metav1.ObjectMeta{Name: "green-dns", Namespace: "test"}}
+	clusterGateways := map[string]dns.ClusterGateway{
+		"test-cluster-1": {
+			Cluster: &testutil.TestResource{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-1"}},
+			GatewayAddresses: []gatewayapiv1beta1.GatewayAddress{
+				{Type: testutil.Pointer(gatewayapiv1beta1.IPAddressType), Value: "1.1.1.1"},
+			},
+		},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedZone, blue, green, bluePolicy, greenPolicy).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		dnsHelper: dnsHelper{Client: f},
+		Placer:    &testGatewayPlacer{clusterGateways: clusterGateways},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	reconcile := func(gw *gatewayapiv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy) *recordsPublishedStatus {
+		t.Helper()
+		recordsStatus := &recordsPublishedStatus{}
+		err := r.reconcileGatewayDNSRecords(ctx, gw, dnsPolicy, sets.New[string](), sets.New[string](), sets.New[string](), sets.New[string](), recordsStatus)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		setHostConflictCondition(dnsPolicy, recordsStatus)
+		return recordsStatus
+	}
+	getRecord := func(gw *gatewayapiv1beta1.Gateway) (*v1alpha1.DNSRecord, error) {
+		dnsRecord := &v1alpha1.DNSRecord{}
+		err := f.Get(ctx, client.ObjectKey{Name: dnsRecordName(gw.Name, "test"), Namespace: "test"}, dnsRecord)
+		return dnsRecord, err
+	}
+
+	// the first policy publishes the host
+	reconcile(blue, bluePolicy)
+	blueRecord, err := getRecord(blue)
+	if err != nil {
+		t.Fatalf("expected a DNSRecord for the first policy, got %v", err)
+	}
+	if blueRecord.Annotations[DNSRecordHostAnnotation] != "test.example.com" || len(blueRecord.Spec.Endpoints) == 0 {
+		t.Fatalf("expected the DNSRecord of the first policy to publish the host, got %v", blueRecord)
+	}
+
+	// the later policy is refused the host
+	status := reconcile(green, greenPolicy)
+	if _, err := getRecord(green); !apierrors.IsNotFound(err) {
+		t.Errorf("expected no DNSRecord for the later policy, got %v", err)
+	}
+	if len(status.unpublished) != 1 || status.unpublished[0].reason != DNSPolicyReasonHostConflict {
+		t.Fatalf("expected the listener of the later policy not to be published for a host conflict, got %v", status.unpublished)
+	}
+	cond := meta.FindStatusCondition(greenPolicy.Status.Conditions, string(DNSPolicyHostConflict))
+	if cond == nil || cond.Status != metav1.ConditionTrue || !strings.Contains(cond.Message, "test/blue-dns") {
+		t.Errorf("expected a %s condition naming the conflicting policy, got %v", DNSPolicyHostConflict, cond)
+	}
+	if err := f.Status().Update(ctx, greenPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if requests := r.policiesWithHostConflict(blueRecord); len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(greenPolicy) {
+		t.Errorf("expected the conflicting policy to be enqueued for the DNSRecord, got %v", requests)
+	}
+
+	// the first policy is left in place
+	reconcile(blue, bluePolicy)
+	got, err := getRecord(blue)
+	if err != nil {
+		t.Fatalf("expected the DNSRecord of the first policy to be kept, got %v", err)
+	}
+	if !reflect.DeepEqual(got.Spec, blueRecord.Spec) {
+		t.Errorf("expected the DNSRecord of the first policy to be unchanged, got %v", got.Spec)
+	}
+	if meta.FindStatusCondition(bluePolicy.Status.Conditions, string(DNSPolicyHostConflict)) != nil {
+		t.Errorf("expected no %s condition on the first policy", DNSPolicyHostConflict)
+	}
+
+	// when both policies raced to create their DNSRecord the one created first keeps the host
+	greenRecord := r.dnsHelper.buildDNSRecordForListener(green, greenPolicy, green.Spec.Listeners[0], managedZone)
+	greenRecord.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	setDNSRecordHost(greenRecord, "test.example.com")
+	if err := f.Create(ctx, greenRecord); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	blueRecord.CreationTimestamp = metav1.Now()
+	if err := f.Update(ctx, blueRecord); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	reconcile(blue, bluePolicy)
+	if _, err := getRecord(blue); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the DNSRecord created last to be removed, got %v", err)
+	}
+	if cond := meta.FindStatusCondition(bluePolicy.Status.Conditions, string(DNSPolicyHostConflict)); cond == nil || !strings.Contains(cond.Message, "test/green-dns") {
+		t.Errorf("expected a %s condition naming the policy of the DNSRecord created first, got %v", DNSPolicyHostConflict, cond)
+	}
+	reconcile(green, greenPolicy)
+	if _, err := getRecord(green); err != nil {
+		t.Errorf("expected the DNSRecord created first to be kept, got %v", err)
+	}
+	if meta.FindStatusCondition(greenPolicy.Status.Conditions, string(DNSPolicyHostConflict)) != nil {
+		t.Errorf("expected the %s condition to be removed from the policy keeping the host", DNSPolicyHostConflict)
+	}
+}
+
+func TestDNSPolicyReconciler_reconcileStaticDNSRecords(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s", err)
+	}
+	if err := gatewayapiv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s", err)
+	}
+
+	gateway := &gatewayapiv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gateway", Namespace: "test"},
+	}
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	srvRecord := v1alpha1.StaticRecord{
+		DNSName:    "_sip._tcp.test.example.com",
+		RecordType: v1alpha1.SRVRecordType,
+		SRVTargets: []v1alpha1.SRVTarget{{Priority: 10, Weight: 5, Port: 5060, Target: "test.example.com"}},
+	}
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+				Group: gatewayapiv1beta1.GroupName,
+				Kind:  "Gateway",
+				Name:  gatewayapiv1beta1.ObjectName(gateway.Name),
+			},
+			StaticRecords: []v1alpha1.StaticRecord{srvRecord},
+		},
+	}
+	if err := dnsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway, managedZone).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	if err := r.reconcileStaticDNSRecords(ctx, gateway, dnsPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	dnsRecord := &v1alpha1.DNSRecord{}
+	if err := f.Get(ctx, client.ObjectKey{Name: staticDNSRecordName(gateway.Name, managedZone.Name), Namespace: "test"}, dnsRecord); err != nil {
+		t.Fatalf("expected the static records DNSRecord to be created, got %v", err)
+	}
+	if dnsRecord.Spec.ManagedZoneRef == nil || dnsRecord.Spec.ManagedZoneRef.Name != managedZone.Name {
+		t.Errorf("expected the DNSRecord to reference managed zone %s, got %v", managedZone.Name, dnsRecord.Spec.ManagedZoneRef)
+	}
+	if len(dnsRecord.Spec.Endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %v", dnsRecord.Spec.Endpoints)
+	}
+	endpoint := dnsRecord.Spec.Endpoints[0]
+	if endpoint.DNSName != srvRecord.DNSName || endpoint.RecordType != "SRV" {
+		t.Errorf("expected an SRV endpoint for %s, got %s", srvRecord.DNSName, endpoint)
+	}
+	if values := endpoint.RecordValues(); len(values) != 1 || values[0] != "10 5 5060 test.example.com" {
+		t.Errorf("expected the SRV target 10 5 5060 test.example.com, got %v", values)
+	}
+
+	// removing the static records deletes the DNSRecord, the listener records are left untouched
+	dnsPolicy.Spec.StaticRecords = nil
+	if err := r.reconcileStaticDNSRecords(ctx, gateway, dnsPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the static records DNSRecord to be deleted, got %v", err)
+	}
+
+	// a static record outside the managed zones is an error
+	dnsPolicy.Spec.StaticRecords = []v1alpha1.StaticRecord{{DNSName: "_sip._tcp.example.org", RecordType: v1alpha1.SRVRecordType, SRVTargets: srvRecord.SRVTargets}}
+	if err := r.reconcileStaticDNSRecords(ctx, gateway, dnsPolicy); !errors.Is(err, ErrNoManagedZoneForHost) {
+		t.Errorf("expected an error wrapping %v, got %v", ErrNoManagedZoneForHost, err)
+	}
+}
+
+func TestDNSPolicyReconciler_reconcileGatewayDNSRecords_listenerWeights(t *testing.T) {
+	scheme := testScheme(t)
+
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+				Group: gatewayapiv1beta1.GroupName,
+				Kind:  "Gateway",
+				Name:  "test-gateway",
+			},
+			LoadBalancing: &v1alpha1.LoadBalancingSpec{
+				Weighted: &v1alpha1.LoadBalancingWeighted{
+					DefaultWeight: 120,
+					Listeners: []v1alpha1.ListenerWeights{
+						{
+							Name:          "api",
+							DefaultWeight: 200,
+							Custom: []*v1alpha1.CustomWeight{
+								{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "canary"}}, Weight: 10},
+							},
+						},
+						// a listener the gateway does not have is ignored
+						{Name: "other", DefaultWeight: 50},
+					},
+				},
+			},
+		},
+	}
+	if err := dnsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	listener := getTestListener("test.example.com")
+	apiListener := getTestListener("api.example.com")
+	apiListener.Name = "api"
+	gw := &gatewayapiv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gateway", Namespace: "test"},
+		Spec: gatewayapiv1beta1.GatewaySpec{
+			Listeners: []gatewayapiv1beta1.Listener{listener, apiListener},
+		},
+	}
+	clusterGateway := func(name, address string, labels map[string]string) dns.ClusterGateway {
+		return dns.ClusterGateway{
+			Cluster: &testutil.TestResource{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}},
+			GatewayAddresses: []gatewayapiv1beta1.GatewayAddress{
+				{Type: testutil.Pointer(gatewayapiv1beta1.IPAddressType), Value: address},
+			},
+		}
+	}
+	placer := &testGatewayPlacer{clusterGateways: map[string]dns.ClusterGateway{
+		"test-cluster-1": clusterGateway("test-cluster-1", "1.1.1.1", map[string]string{"tier": "canary"}),
+		"test-cluster-2": clusterGateway("test-cluster-2", "2.2.2.2", nil),
+	}}
+
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedZone, gw).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		dnsHelper: dnsHelper{Client: f},
+		Placer:    placer,
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	err := r.reconcileGatewayDNSRecords(ctx, gw, dnsPolicy, sets.New[string](), sets.New[string](), sets.New[string](), sets.New[string](), &recordsPublishedStatus{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// publishedWeights returns the sorted weights published in the DNSRecord of the listener
+	publishedWeights := func(listener gatewayapiv1beta1.Listener) []string {
+		t.Helper()
+		dnsRecord, err := r.dnsHelper.getDNSRecordForListener(ctx, listener, gw)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		var weights []string
+		for _, endpoint := range dnsRecord.Spec.Endpoints {
+			if weight, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok {
+				weights = append(weights, weight.Value)
+			}
+		}
+		sort.Strings(weights)
+		return weights
+	}
+	if got := publishedWeights(listener); !reflect.DeepEqual(got, []string{"120", "120"}) {
+		t.Errorf("expected the listener without weights to use the default weight of the policy, got %v", got)
+	}
+	if got := publishedWeights(apiListener); !reflect.DeepEqual(got, []string{"10", "200"}) {
+		t.Errorf("expected the listener to use its own weights, got %v", got)
+	}
+}
+
+func TestDNSPolicy_Validate_listenerWeights(t *testing.T) {
+	testCases := []struct {
+		name     string
+		weighted *v1alpha1.LoadBalancingWeighted
+		wantErr  string
+	}{
+		{
+			name: "valid",
+			weighted: &v1alpha1.LoadBalancingWeighted{
+				DefaultWeight: 120,
+				Listeners: []v1alpha1.ListenerWeights{
+					{Name: "api", DefaultWeight: 0, Custom: []*v1alpha1.CustomWeight{
+						{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "canary"}}, Weight: 10},
+					}},
+					{Name: "web", DefaultWeight: 100},
+				},
+			},
+		},
+		{
+			name: "duplicate listener",
+			weighted: &v1alpha1.LoadBalancingWeighted{
+				DefaultWeight: 120,
+				Listeners:     []v1alpha1.ListenerWeights{{Name: "api", DefaultWeight: 100}, {Name: "api", DefaultWeight: 50}},
+			},
+			wantErr: "listener api is weighted more than once",
+		},
+		{
+			name: "zero weights",
+			weighted: &v1alpha1.LoadBalancingWeighted{
+				DefaultWeight: 120,
+				Listeners:     []v1alpha1.ListenerWeights{{Name: "api", DefaultWeight: 0}},
+			},
+			wantErr: "the weights of listener api are all zero",
+		},
+		{
+			name: "custom weight without selector",
+			weighted: &v1alpha1.LoadBalancingWeighted{
+				DefaultWeight: 120,
+				Listeners:     []v1alpha1.ListenerWeights{{Name: "api", DefaultWeight: 100, Custom: []*v1alpha1.CustomWeight{{Weight: 10}}}},
+			},
+			wantErr: "a custom weight of listener api has no selector",
+		},
+		{
+			name: "ramped",
+			weighted: &v1alpha1.LoadBalancingWeighted{
+				DefaultWeight: 120,
+				RampDuration:  &metav1.Duration{Duration: time.Minute},
+				Listeners:     []v1alpha1.ListenerWeights{{Name: "api", DefaultWeight: 100}},
+			},
+			wantErr: "listener weights cannot be set with rampDuration",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dnsPolicy := &v1alpha1.DNSPolicy{
+				Spec: v1alpha1.DNSPolicySpec{
+					TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+						Group: gatewayapiv1beta1.GroupName,
+						Kind:  "Gateway",
+						Name:  "test-gateway",
+					},
+					LoadBalancing: &v1alpha1.LoadBalancingSpec{Weighted: testCase.weighted},
+				},
+			}
+			err := dnsPolicy.Validate()
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDNSPolicy_Validate_geoproximityBias(t *testing.T) {
+	testCases := []struct {
+		name         string
+		geoproximity *v1alpha1.LoadBalancingGeoproximity
+		wantErr      string
+	}{
+		{
+			name: "valid",
+			geoproximity: &v1alpha1.LoadBalancingGeoproximity{
+				DefaultBias: -99,
+				Custom: []*v1alpha1.CustomBias{
+					{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "primary"}}, Bias: 99},
+				},
+			},
+		},
+		{
+			name:         "default bias too low",
+			geoproximity: &v1alpha1.LoadBalancingGeoproximity{DefaultBias: -100},
+			wantErr:      "invalid loadBalancing.geoproximity.defaultBias, bias -100 must be between -99 and 99",
+		},
+		{
+			name: "custom bias too high",
+			geoproximity: &v1alpha1.LoadBalancingGeoproximity{
+				Custom: []*v1alpha1.CustomBias{
+					{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "primary"}}, Bias: 100},
+				},
+			},
+			wantErr: "invalid loadBalancing.geoproximity.custom[0].bias, bias 100 must be between -99 and 99",
+		},
+		{
+			name: "custom bias without selector",
+			geoproximity: &v1alpha1.LoadBalancingGeoproximity{
+				Custom: []*v1alpha1.CustomBias{{Bias: 10}},
+			},
+			wantErr: "invalid loadBalancing.geoproximity.custom[0], a selector is required",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dnsPolicy := &v1alpha1.DNSPolicy{
+				Spec: v1alpha1.DNSPolicySpec{
+					TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+						Group: gatewayapiv1beta1.GroupName,
+						Kind:  "Gateway",
+						Name:  "test-gateway",
+					},
+					LoadBalancing: &v1alpha1.LoadBalancingSpec{Geoproximity: testCase.geoproximity},
+				},
+			}
+			err := dnsPolicy.Validate()
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
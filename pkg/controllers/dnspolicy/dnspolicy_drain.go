@@ -0,0 +1,136 @@
+package dnspolicy
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+// drainDuration returns the drain duration of the policy, zero when it is not set
+func drainDuration(dnsPolicy *v1alpha1.DNSPolicy) time.Duration {
+	if dnsPolicy.Spec.DNSDrainDuration == nil || dnsPolicy.Spec.DNSDrainDuration.Duration < 0 {
+		return 0
+	}
+	return dnsPolicy.Spec.DNSDrainDuration.Duration
+}
+
+// endpointDrain keeps the weighted endpoints of the clusters no longer targeted by a gateway with a weight of zero
+// for the drain duration, from the endpoints draining at the previous reconcile, so that the resolvers answer with the
+// other clusters of the record set while the clients of the cluster move to them
+type endpointDrain struct {
+	duration time.Duration
+	now      time.Time
+	previous map[string]v1alpha1.DrainingEndpoint
+	draining map[string]v1alpha1.DrainingEndpoint
+}
+
+func newEndpointDrain(duration time.Duration, previous []v1alpha1.DrainingEndpoint, now time.Time) *endpointDrain {
+	drain := &endpointDrain{
+		duration: duration,
+		now:      now,
+		previous: map[string]v1alpha1.DrainingEndpoint{},
+		draining: map[string]v1alpha1.DrainingEndpoint{},
+	}
+	for _, endpoint := range previous {
+		drain.previous[drainKey(endpoint.DNSName, endpoint.SetIdentifier)] = endpoint
+	}
+	return drain
+}
+
+func drainKey(dnsName, setIdentifier string) string {
+	return strings.ToLower(dnsName) + "/" + setIdentifier
+}
+
+// apply returns the endpoints with the weighted endpoints of the previous endpoints that are no longer desired added
+// back with a weight of zero, along with the records of their targets, until they have drained for the drain
+// duration. An endpoint is only drained while another endpoint of its record set has a weight, as the resolvers
+// answer with all the endpoints of a record set when all their weights are zero, and it is removed right away
+// otherwise.
+func (d *endpointDrain) apply(endpoints, previous []*v1alpha1.Endpoint) []*v1alpha1.Endpoint {
+	desired := map[string]struct{}{}
+	weighted := map[string]struct{}{}
+	for _, endpoint := range endpoints {
+		desired[endpointKey(endpoint.DNSName, endpoint.SetIdentifier, v1alpha1.DNSRecordType(endpoint.RecordType))] = struct{}{}
+		if weight, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok && weight.Value != "0" {
+			weighted[strings.ToLower(endpoint.DNSName)] = struct{}{}
+		}
+	}
+	previousByName := map[string][]*v1alpha1.Endpoint{}
+	for _, endpoint := range previous {
+		previousByName[strings.ToLower(endpoint.DNSName)] = append(previousByName[strings.ToLower(endpoint.DNSName)], endpoint)
+	}
+
+	for _, endpoint := range previous {
+		if _, ok := desired[endpointKey(endpoint.DNSName, endpoint.SetIdentifier, v1alpha1.DNSRecordType(endpoint.RecordType))]; ok {
+			continue
+		}
+		if _, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificWeight); !ok || endpoint.SetIdentifier == "" {
+			continue
+		}
+		if _, ok := weighted[strings.ToLower(endpoint.DNSName)]; !ok {
+			continue
+		}
+		key := drainKey(endpoint.DNSName, endpoint.SetIdentifier)
+		draining, ok := d.previous[key]
+		if !ok {
+			draining = v1alpha1.DrainingEndpoint{
+				DNSName:        endpoint.DNSName,
+				SetIdentifier:  endpoint.SetIdentifier,
+				DrainStartTime: metav1.Time{Time: d.now},
+			}
+		}
+		if d.now.Sub(draining.DrainStartTime.Time) >= d.duration {
+			continue
+		}
+		d.draining[key] = draining
+
+		drained := endpoint.DeepCopy()
+		drained.SetProviderSpecific(dns.ProviderSpecificWeight, "0")
+		endpoints = append(endpoints, drained)
+		// the records of the cluster the endpoint targets are kept until it has drained
+		for _, target := range endpoint.Targets {
+			for _, targetEndpoint := range previousByName[strings.ToLower(target)] {
+				targetKey := endpointKey(targetEndpoint.DNSName, targetEndpoint.SetIdentifier, v1alpha1.DNSRecordType(targetEndpoint.RecordType))
+				if _, ok := desired[targetKey]; ok || targetEndpoint.SetIdentifier != "" {
+					continue
+				}
+				desired[targetKey] = struct{}{}
+				endpoints = append(endpoints, targetEndpoint.DeepCopy())
+			}
+		}
+	}
+	return endpoints
+}
+
+// sortedEndpoints returns the endpoints draining, sorted by name and set identifier
+func (d *endpointDrain) sortedEndpoints() []v1alpha1.DrainingEndpoint {
+	var endpoints []v1alpha1.DrainingEndpoint
+	for _, endpoint := range d.draining {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return drainKey(endpoints[i].DNSName, endpoints[i].SetIdentifier) < drainKey(endpoints[j].DNSName, endpoints[j].SetIdentifier)
+	})
+	return endpoints
+}
+
+// drainRequeueAfter returns the time after which the policy should be reconciled again to remove the first endpoint
+// done draining, zero if none is draining
+func drainRequeueAfter(endpoints []v1alpha1.DrainingEndpoint, duration time.Duration, now time.Time) time.Duration {
+	var requeueAfter time.Duration
+	for _, endpoint := range endpoints {
+		after := endpoint.DrainStartTime.Add(duration).Sub(now)
+		if after <= 0 {
+			after = time.Second
+		}
+		if requeueAfter == 0 || after < requeueAfter {
+			requeueAfter = after
+		}
+	}
+	return requeueAfter
+}
@@ -0,0 +1,251 @@
+//go:build unit
+
+package dnspolicy
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+// resolve returns the addresses a resolver answers with for the name, following the CNAMEs of the endpoints. Like
+// Route53, the weighted endpoints of a record set with a weight of zero are only answered with when all the weights
+// of the record set are zero.
+func resolve(endpoints []*v1alpha1.Endpoint, name string) []string {
+	var records, weighted []*v1alpha1.Endpoint
+	for _, endpoint := range endpoints {
+		if !strings.EqualFold(endpoint.DNSName, name) {
+			continue
+		}
+		records = append(records, endpoint)
+		if weight, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok && weight.Value != "0" {
+			weighted = append(weighted, endpoint)
+		}
+	}
+	if len(weighted) > 0 {
+		records = weighted
+	}
+	addresses := sets.New[string]()
+	for _, endpoint := range records {
+		for _, target := range endpoint.Targets {
+			if endpoint.RecordType == string(v1alpha1.CNAMERecordType) {
+				addresses.Insert(resolve(endpoints, target)...)
+				continue
+			}
+			addresses.Insert(target)
+		}
+	}
+	return sets.List(addresses)
+}
+
+func TestDNSPolicyReconciler_reconcileGatewayDNSRecords_drain(t *testing.T) {
+	scheme := testScheme(t)
+
+	drain := 2 * time.Minute
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			DNSDrainDuration: &metav1.Duration{Duration: drain},
+		},
+	}
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	listener := getTestListener("test.example.com")
+	gw := &gatewayapiv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gateway", Namespace: "test"},
+		Spec:       gatewayapiv1beta1.GatewaySpec{Listeners: []gatewayapiv1beta1.Listener{listener}},
+	}
+	clusterGateway := func(name, address string) dns.ClusterGateway {
+		return dns.ClusterGateway{
+			Cluster: &testutil.TestResource{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			GatewayAddresses: []gatewayapiv1beta1.GatewayAddress{
+				{Type: testutil.Pointer(gatewayapiv1beta1.IPAddressType), Value: address},
+			},
+		}
+	}
+	placer := &testGatewayPlacer{clusterGateways: map[string]dns.ClusterGateway{
+		"test-cluster-1": clusterGateway("test-cluster-1", "1.1.1.1"),
+		"test-cluster-2": clusterGateway("test-cluster-2", "2.2.2.2"),
+	}}
+
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedZone, gw).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		dnsHelper: dnsHelper{Client: f},
+		Placer:    placer,
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	// reconcile publishes the DNS records at the time and returns the addresses published and the addresses answered
+	// for the listener host, as a reconcile of the policy would
+	reconcile := func(now time.Time) (published, answered []string) {
+		t.Helper()
+		recordsStatus := &recordsPublishedStatus{endpointDrain: newEndpointDrain(drain, dnsPolicy.Status.DrainingEndpoints, now)}
+		err := r.reconcileGatewayDNSRecords(ctx, gw, dnsPolicy, sets.New[string](), sets.New[string](), sets.New[string](), sets.New[string](), recordsStatus)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		dnsPolicy.Status.DrainingEndpoints = recordsStatus.endpointDrain.sortedEndpoints()
+
+		dnsRecord, err := r.dnsHelper.getDNSRecordForListener(ctx, listener, gw)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		addresses := sets.New[string]()
+		for _, endpoint := range dnsRecord.Spec.Endpoints {
+			if endpoint.RecordType == string(v1alpha1.ARecordType) {
+				addresses.Insert(endpoint.Targets...)
+			}
+		}
+		return sets.List(addresses), resolve(dnsRecord.Spec.Endpoints, "test.example.com")
+	}
+
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	published, answered := reconcile(start)
+	if want := []string{"1.1.1.1", "2.2.2.2"}; !reflect.DeepEqual(published, want) || !reflect.DeepEqual(answered, want) {
+		t.Fatalf("expected both clusters to be published and answered, got %v and %v", published, answered)
+	}
+	if len(dnsPolicy.Status.DrainingEndpoints) != 0 {
+		t.Errorf("expected no endpoints draining, got %v", dnsPolicy.Status.DrainingEndpoints)
+	}
+
+	// the records of a cluster leaving the gateway are kept while it drains, but it is no longer answered
+	delete(placer.clusterGateways, "test-cluster-2")
+	left := start.Add(time.Hour)
+	for _, elapsed := range []time.Duration{0, drain / 2} {
+		now := left.Add(elapsed)
+		published, answered = reconcile(now)
+		if want := []string{"1.1.1.1", "2.2.2.2"}; !reflect.DeepEqual(published, want) {
+			t.Errorf("expected the records of the cluster to be kept after %s, got %v", elapsed, published)
+		}
+		if want := []string{"1.1.1.1"}; !reflect.DeepEqual(answered, want) {
+			t.Errorf("expected only the remaining cluster to be answered after %s, got %v", elapsed, answered)
+		}
+		draining := dnsPolicy.Status.DrainingEndpoints
+		if len(draining) != 1 || !draining[0].DrainStartTime.Time.Equal(left) {
+			t.Fatalf("expected a single endpoint draining since the cluster left, got %v", draining)
+		}
+		if after := drainRequeueAfter(draining, drain, now); after != drain-elapsed {
+			t.Errorf("expected the policy to be requeued after %s when the endpoint has drained, got %s", drain-elapsed, after)
+		}
+	}
+
+	// the records are removed once the cluster has drained
+	published, answered = reconcile(left.Add(drain))
+	if want := []string{"1.1.1.1"}; !reflect.DeepEqual(published, want) || !reflect.DeepEqual(answered, want) {
+		t.Errorf("expected the records of the drained cluster to be removed, got %v and %v", published, answered)
+	}
+	if len(dnsPolicy.Status.DrainingEndpoints) != 0 {
+		t.Errorf("expected no endpoints draining, got %v", dnsPolicy.Status.DrainingEndpoints)
+	}
+
+	// the records of the last cluster are removed right away, there is no other cluster to drain to
+	placer.clusterGateways = map[string]dns.ClusterGateway{}
+	if published, _ = reconcile(left.Add(2 * drain)); published != nil {
+		t.Errorf("expected the records of the gateway to be removed right away, got %v", published)
+	}
+}
+
+func TestDrainRequeueAfter(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	draining := func(since time.Duration) v1alpha1.DrainingEndpoint {
+		return v1alpha1.DrainingEndpoint{DNSName: "test.example.com", SetIdentifier: "test", DrainStartTime: metav1.Time{Time: now.Add(-since)}}
+	}
+
+	testCases := []struct {
+		name      string
+		endpoints []v1alpha1.DrainingEndpoint
+		want      time.Duration
+	}{
+		{name: "no endpoint draining", want: 0},
+		{name: "at the end of the drain", endpoints: []v1alpha1.DrainingEndpoint{draining(time.Minute)}, want: 4 * time.Minute},
+		{name: "the soonest end of the endpoints", endpoints: []v1alpha1.DrainingEndpoint{draining(time.Minute), draining(3 * time.Minute)}, want: 2 * time.Minute},
+		{name: "drained endpoints are removed shortly", endpoints: []v1alpha1.DrainingEndpoint{draining(10 * time.Minute)}, want: time.Second},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := drainRequeueAfter(testCase.endpoints, 5*time.Minute, now); got != testCase.want {
+				t.Errorf("expected requeue after %s, got %s", testCase.want, got)
+			}
+		})
+	}
+}
+
+func TestDNSPolicy_Validate_drainDuration(t *testing.T) {
+	testCases := []struct {
+		name          string
+		loadBalancing *v1alpha1.LoadBalancingSpec
+		delegate      *v1alpha1.DelegateToCNAME
+		wantErr       string
+	}{
+		{
+			name:          "weighted",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{Weighted: &v1alpha1.LoadBalancingWeighted{DefaultWeight: 120}},
+		},
+		{
+			name:          "latency",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{Latency: &v1alpha1.LoadBalancingLatency{}},
+			wantErr:       "invalid dnsDrainDuration, requires weighted load balancing",
+		},
+		{
+			name:          "geoproximity",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{Geoproximity: &v1alpha1.LoadBalancingGeoproximity{}},
+			wantErr:       "invalid dnsDrainDuration, requires weighted load balancing",
+		},
+		{
+			name:     "delegate to cname",
+			delegate: &v1alpha1.DelegateToCNAME{Target: "gslb.example.net"},
+			wantErr:  "invalid dnsDrainDuration, cannot be set with delegateToCNAME",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dnsPolicy := &v1alpha1.DNSPolicy{
+				Spec: v1alpha1.DNSPolicySpec{
+					TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+						Group: gatewayapiv1beta1.GroupName,
+						Kind:  "Gateway",
+						Name:  "test-gateway",
+					},
+					LoadBalancing:    testCase.loadBalancing,
+					DelegateToCNAME:  testCase.delegate,
+					DNSDrainDuration: &metav1.Duration{Duration: time.Minute},
+				},
+			}
+			err := dnsPolicy.Validate()
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
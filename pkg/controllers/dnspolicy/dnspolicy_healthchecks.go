@@ -141,9 +141,13 @@ func (r *DNSPolicyReconciler) expectedProbesForGateway(ctx context.Context, gw c
 					Protocol:                 v1alpha1.HealthProtocol(protocol),
 					Interval:                 interval,
 					AdditionalHeadersRef:     dnsPolicy.Spec.HealthCheck.AdditionalHeadersRef,
+					AdditionalHeaders:        dnsPolicy.Spec.HealthCheck.AdditionalHeaders,
 					FailureThreshold:         dnsPolicy.Spec.HealthCheck.FailureThreshold,
+					SuccessThreshold:         dnsPolicy.Spec.HealthCheck.SuccessThreshold,
 					ExpectedResponses:        dnsPolicy.Spec.HealthCheck.ExpectedResponses,
 					AllowInsecureCertificate: dnsPolicy.Spec.HealthCheck.AllowInsecureCertificates,
+					TLSServerName:            dnsPolicy.Spec.HealthCheck.TLSServerName,
+					ExpectedBody:             dnsPolicy.Spec.HealthCheck.ExpectedBody,
 				},
 			}
 			healthChecks = append(healthChecks, withGatewayListener(gw, listener, healthCheck))
@@ -98,6 +98,7 @@ func TestDNSPolicyReconciler_expectedProbesForGateway(t *testing.T) {
 								200, 201,
 							},
 							AllowInsecureCertificates: true,
+							ExpectedBody:              `"status": *"ok"`,
 						},
 					},
 				},
@@ -130,6 +131,7 @@ func TestDNSPolicyReconciler_expectedProbesForGateway(t *testing.T) {
 						},
 						AllowInsecureCertificate: true,
 						ExpectedResponses:        []int{200, 201},
+						ExpectedBody:             `"status": *"ok"`,
 						FailureThreshold:         testutil.Pointer(1),
 					},
 				},
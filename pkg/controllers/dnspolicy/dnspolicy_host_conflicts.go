@@ -0,0 +1,162 @@
+package dnspolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	// DNSRecordHostAnnotation is the host the DNSRecord of a gateway listener publishes, used to find the DNSRecords
+	// of other policies publishing the same host
+	DNSRecordHostAnnotation = "kuadrant.io/dns-record-host"
+
+	DNSPolicyHostConflict       conditions.ConditionType = "HostConflict"
+	DNSPolicyReasonHostConflict                          = "HostConflict"
+)
+
+// hostConflict is a listener host already published in its managed zone by the DNSRecord of another policy
+type hostConflict struct {
+	host        string
+	managedZone string
+	policy      client.ObjectKey
+}
+
+func (c *hostConflict) String() string {
+	return fmt.Sprintf("host %s is already published in managed zone %s by DNSPolicy %s", c.host, c.managedZone, c.policy)
+}
+
+// findHostConflict returns the conflict of the host published for the listener when a DNSRecord of another policy
+// publishes it in the same managed zone, nil otherwise. The DNSRecord created first keeps the host, so the listener
+// does not conflict with the DNSRecords created after its own.
+func (r *DNSPolicyReconciler) findHostConflict(ctx context.Context, gateway *gatewayv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy, listener gatewayv1beta1.Listener, managedZone *v1alpha1.ManagedZone) (*hostConflict, error) {
+	host, _ := dnsPolicy.GetHostOverride(string(*listener.Hostname))
+	host = strings.ToLower(host)
+
+	recordList := &v1alpha1.DNSRecordList{}
+	if err := r.Client().List(ctx, recordList, client.InNamespace(managedZone.Namespace)); err != nil {
+		return nil, err
+	}
+
+	recordName := dnsRecordName(gateway.Name, string(listener.Name))
+	var own *v1alpha1.DNSRecord
+	for i := range recordList.Items {
+		if recordList.Items[i].Name == recordName {
+			own = &recordList.Items[i]
+		}
+	}
+
+	policyKey := client.ObjectKeyFromObject(dnsPolicy)
+	var first *v1alpha1.DNSRecord
+	for i := range recordList.Items {
+		record := &recordList.Items[i]
+		recordPolicy, ok := dnsRecordPolicy(record)
+		if record.Name == recordName || !ok || recordPolicy == policyKey {
+			continue
+		}
+		if record.Spec.ManagedZoneRef == nil || record.Spec.ManagedZoneRef.Name != managedZone.Name || !publishesHost(record, host) {
+			continue
+		}
+		if own != nil && !createdBefore(record, own) {
+			continue
+		}
+		if first == nil || createdBefore(record, first) {
+			first = record
+		}
+	}
+	if first == nil {
+		return nil, nil
+	}
+	firstPolicy, _ := dnsRecordPolicy(first)
+	return &hostConflict{host: host, managedZone: managedZone.Name, policy: firstPolicy}, nil
+}
+
+// dnsRecordPolicy returns the policy the DNSRecord was created for, false for a DNSRecord not created by a policy
+func dnsRecordPolicy(record *v1alpha1.DNSRecord) (client.ObjectKey, bool) {
+	name, ok := record.Labels[DNSPolicyBackRefAnnotation]
+	if !ok {
+		return client.ObjectKey{}, false
+	}
+	return client.ObjectKey{Name: name, Namespace: record.Labels[fmt.Sprintf("%s-namespace", DNSPolicyBackRefAnnotation)]}, true
+}
+
+// publishesHost returns true if the DNSRecord publishes the host, as set in its host annotation or, for the
+// DNSRecords without one, in the name of one of its endpoints
+func publishesHost(record *v1alpha1.DNSRecord, host string) bool {
+	if recordHost, ok := record.Annotations[DNSRecordHostAnnotation]; ok {
+		return recordHost == host
+	}
+	for _, endpoint := range record.Spec.Endpoints {
+		if strings.ToLower(endpoint.DNSName) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// setDNSRecordHost sets the host annotation of the DNSRecord to the host it publishes
+func setDNSRecordHost(dnsRecord *v1alpha1.DNSRecord, host string) {
+	if dnsRecord.Annotations == nil {
+		dnsRecord.Annotations = map[string]string{}
+	}
+	dnsRecord.Annotations[DNSRecordHostAnnotation] = strings.ToLower(host)
+}
+
+// createdBefore returns true if the DNSRecord a was created before b, the name breaks the tie of DNSRecords created
+// in the same second
+func createdBefore(a, b *v1alpha1.DNSRecord) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// setHostConflictCondition sets a condition on the policy listing the listener hosts that are not published because
+// the DNSRecord of another policy already publishes them
+func setHostConflictCondition(dnsPolicy *v1alpha1.DNSPolicy, status *recordsPublishedStatus) {
+	var messages []string
+	for _, unpublished := range status.unpublished {
+		if unpublished.reason == DNSPolicyReasonHostConflict {
+			messages = append(messages, fmt.Sprintf("gateway %s listener %s: %s", unpublished.gateway, unpublished.listener, unpublished.message))
+		}
+	}
+	if len(messages) == 0 {
+		meta.RemoveStatusCondition(&dnsPolicy.Status.Conditions, string(DNSPolicyHostConflict))
+		return
+	}
+	meta.SetStatusCondition(&dnsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(DNSPolicyHostConflict),
+		Status:             metav1.ConditionTrue,
+		Reason:             DNSPolicyReasonHostConflict,
+		Message:            strings.Join(messages, "; "),
+		ObservedGeneration: dnsPolicy.Generation,
+	})
+}
+
+// policiesWithHostConflict returns a request for every policy in the namespace of the DNSRecord with a host conflict,
+// so that a policy publishes its hosts as soon as the DNSRecord of the other policy is deleted
+func (r *DNSPolicyReconciler) policiesWithHostConflict(obj client.Object) []reconcile.Request {
+	policies := &v1alpha1.DNSPolicyList{}
+	if err := r.Client().List(context.TODO(), policies, client.InNamespace(obj.GetNamespace())); err != nil {
+		crlog.Log.Error(err, "failed to list dns policies for dns record", "dnsRecord", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, policy := range policies.Items {
+		if meta.IsStatusConditionTrue(policy.Status.Conditions, string(DNSPolicyHostConflict)) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+		}
+	}
+	return requests
+}
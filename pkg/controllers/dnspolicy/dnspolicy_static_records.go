@@ -0,0 +1,114 @@
+package dnspolicy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// LabelStaticRecords marks the DNSRecords holding the static records of a policy
+const LabelStaticRecords = "kuadrant.io/dns-static-records"
+
+func staticDNSRecordName(gatewayName, managedZoneName string) string {
+	return fmt.Sprintf("%s-static-%s", gatewayName, managedZoneName)
+}
+
+// reconcileStaticDNSRecords publishes the static records of the policy in a DNSRecord for each ManagedZone they
+// belong to, and deletes the DNSRecords of the zones that no longer have static records
+func (r *DNSPolicyReconciler) reconcileStaticDNSRecords(ctx context.Context, gateway *gatewayv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy) error {
+	log := crlog.FromContext(ctx)
+
+	expected := map[string]*v1alpha1.DNSRecord{}
+	if len(dnsPolicy.Spec.StaticRecords) > 0 {
+		var managedZones v1alpha1.ManagedZoneList
+		if err := r.Client().List(ctx, &managedZones, client.InNamespace(gateway.Namespace)); err != nil {
+			return err
+		}
+		for _, staticRecord := range dnsPolicy.Spec.StaticRecords {
+			mz, _, err := findMatchingManagedZone(staticRecord.DNSName, managedZones.Items)
+			if err != nil {
+				return fmt.Errorf("invalid static record %s: %w", staticRecord.DNSName, err)
+			}
+			name := staticDNSRecordName(gateway.Name, mz.Name)
+			dnsRecord, ok := expected[name]
+			if !ok {
+				dnsRecord = buildStaticDNSRecord(gateway, dnsPolicy, mz)
+				if err := controllerutil.SetControllerReference(mz, dnsRecord, r.Scheme()); err != nil {
+					return err
+				}
+				expected[name] = dnsRecord
+			}
+			dnsRecord.Spec.Endpoints = append(dnsRecord.Spec.Endpoints, staticRecord.Endpoint())
+		}
+	}
+
+	for _, dnsRecord := range expected {
+		sort.SliceStable(dnsRecord.Spec.Endpoints, func(i, j int) bool {
+			return dnsRecord.Spec.Endpoints[i].DNSName+dnsRecord.Spec.Endpoints[i].RecordType <
+				dnsRecord.Spec.Endpoints[j].DNSName+dnsRecord.Spec.Endpoints[j].RecordType
+		})
+		if err := r.ReconcileResource(ctx, &v1alpha1.DNSRecord{}, dnsRecord, staticDNSRecordMutator); err != nil {
+			return fmt.Errorf("failed to reconcile static records DNSRecord %s: %w", dnsRecord.Name, err)
+		}
+	}
+
+	staticLabels := commonDNSRecordLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(dnsPolicy))
+	staticLabels[LabelStaticRecords] = "true"
+	recordsList := &v1alpha1.DNSRecordList{}
+	if err := r.Client().List(ctx, recordsList, &client.ListOptions{LabelSelector: labels.SelectorFromSet(staticLabels)}); err != nil {
+		return err
+	}
+	for i := range recordsList.Items {
+		if _, ok := expected[recordsList.Items[i].Name]; ok {
+			continue
+		}
+		log.V(3).Info("deleting static records DNSRecord no longer expected", "dnsRecord", recordsList.Items[i].Name)
+		if err := r.DeleteResource(ctx, &recordsList.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildStaticDNSRecord(gateway *gatewayv1beta1.Gateway, dnsPolicy *v1alpha1.DNSPolicy, managedZone *v1alpha1.ManagedZone) *v1alpha1.DNSRecord {
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      staticDNSRecordName(gateway.Name, managedZone.Name),
+			Namespace: managedZone.Namespace,
+			Labels:    commonDNSRecordLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(dnsPolicy)),
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: managedZone.Name,
+			},
+		},
+	}
+	dnsRecord.Labels[LabelStaticRecords] = "true"
+	return dnsRecord
+}
+
+func staticDNSRecordMutator(existingObj, desiredObj client.Object) (bool, error) {
+	existing, ok := existingObj.(*v1alpha1.DNSRecord)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1alpha1.DNSRecord", existingObj)
+	}
+	desired, ok := desiredObj.(*v1alpha1.DNSRecord)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1alpha1.DNSRecord", desiredObj)
+	}
+	if reflect.DeepEqual(existing.Spec.Endpoints, desired.Spec.Endpoints) {
+		return false, nil
+	}
+	existing.Spec.Endpoints = desired.Spec.Endpoints
+	return true, nil
+}
@@ -0,0 +1,130 @@
+package dnspolicy
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+const (
+	// weightRampSteps is the number of steps the weight of a joining cluster is increased in over the ramp duration
+	weightRampSteps = 10
+	// minWeightRampInterval bounds how often the policy is reconciled to step the weights of short ramps
+	minWeightRampInterval = 5 * time.Second
+)
+
+// weightRampDuration returns the duration over which the weight of a joining cluster ramps up, zero when the weights
+// are not ramped
+func weightRampDuration(dnsPolicy *v1alpha1.DNSPolicy) time.Duration {
+	lb := dnsPolicy.Spec.LoadBalancing
+	if lb == nil || lb.Weighted == nil || lb.Weighted.RampDuration == nil {
+		return 0
+	}
+	return lb.Weighted.RampDuration.Duration
+}
+
+// weightRamp computes the weights published for the target clusters from the weights published at the previous
+// reconcile, so that a cluster keeps ramping from the time it joined
+type weightRamp struct {
+	duration time.Duration
+	now      time.Time
+	previous map[string]v1alpha1.ClusterWeight
+	weights  map[string]v1alpha1.ClusterWeight
+}
+
+func newWeightRamp(duration time.Duration, previous []v1alpha1.ClusterWeight, now time.Time) *weightRamp {
+	ramp := &weightRamp{
+		duration: duration,
+		now:      now,
+		previous: map[string]v1alpha1.ClusterWeight{},
+		weights:  map[string]v1alpha1.ClusterWeight{},
+	}
+	for _, clusterWeight := range previous {
+		ramp.previous[clusterWeight.Cluster] = clusterWeight
+	}
+	return ramp
+}
+
+// apply sets the weight of the targets of the gateway to their ramped weight. A cluster the previous reconcile did not
+// publish a weight for joins now, unless no cluster had a weight published, i.e. the ramp was just set or no other
+// cluster serves the traffic, in which case it gets its weight right away.
+func (r *weightRamp) apply(mcgTarget *dns.MultiClusterGatewayTarget) {
+	for i := range mcgTarget.ClusterGatewayTargets {
+		target := &mcgTarget.ClusterGatewayTargets[i]
+		cluster := target.Cluster.GetName()
+		clusterWeight, ok := r.weights[cluster]
+		if !ok {
+			clusterWeight = r.clusterWeight(cluster, target.GetWeight())
+			r.weights[cluster] = clusterWeight
+		}
+		weight := clusterWeight.Weight
+		target.Weight = &weight
+	}
+}
+
+func (r *weightRamp) clusterWeight(cluster string, targetWeight int) v1alpha1.ClusterWeight {
+	clusterWeight := v1alpha1.ClusterWeight{Cluster: cluster, Weight: targetWeight, TargetWeight: targetWeight}
+	previous, ok := r.previous[cluster]
+	switch {
+	case ok && previous.RampStartTime != nil:
+		clusterWeight.RampStartTime = previous.RampStartTime
+	case !ok && len(r.previous) > 0:
+		clusterWeight.RampStartTime = &metav1.Time{Time: r.now}
+	default:
+		return clusterWeight
+	}
+
+	elapsed := r.now.Sub(clusterWeight.RampStartTime.Time)
+	if elapsed >= r.duration {
+		clusterWeight.RampStartTime = nil
+		return clusterWeight
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	clusterWeight.Weight = int(int64(targetWeight) * int64(elapsed) / int64(r.duration))
+	return clusterWeight
+}
+
+// sortedWeights returns the weights published for the clusters, sorted by cluster name
+func (r *weightRamp) sortedWeights() []v1alpha1.ClusterWeight {
+	var weights []v1alpha1.ClusterWeight
+	for _, clusterWeight := range r.weights {
+		weights = append(weights, clusterWeight)
+	}
+	sort.Slice(weights, func(i, j int) bool {
+		return weights[i].Cluster < weights[j].Cluster
+	})
+	return weights
+}
+
+// weightRampRequeueAfter returns the time after which the policy should be reconciled again to step the weights of
+// the clusters ramping up, zero if none is. The weights are stepped every tenth of the ramp duration, and the last
+// step is at the end of the ramp.
+func weightRampRequeueAfter(weights []v1alpha1.ClusterWeight, duration time.Duration, now time.Time) time.Duration {
+	interval := duration / weightRampSteps
+	if interval < minWeightRampInterval {
+		interval = minWeightRampInterval
+	}
+	var requeueAfter time.Duration
+	for _, clusterWeight := range weights {
+		if clusterWeight.RampStartTime == nil {
+			continue
+		}
+		after := interval
+		if remaining := clusterWeight.RampStartTime.Add(duration).Sub(now); remaining < after {
+			after = remaining
+		}
+		if after <= 0 {
+			after = time.Second
+		}
+		if requeueAfter == 0 || after < requeueAfter {
+			requeueAfter = after
+		}
+	}
+	return requeueAfter
+}
@@ -0,0 +1,230 @@
+//go:build unit
+
+package dnspolicy
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestDNSPolicyReconciler_reconcileGatewayDNSRecords_weightRamp(t *testing.T) {
+	scheme := testScheme(t)
+
+	ramp := 10 * time.Minute
+	dnsPolicy := &v1alpha1.DNSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-dns-policy", Namespace: "test"},
+		Spec: v1alpha1.DNSPolicySpec{
+			LoadBalancing: &v1alpha1.LoadBalancingSpec{
+				Weighted: &v1alpha1.LoadBalancingWeighted{
+					DefaultWeight: 120,
+					RampDuration:  &metav1.Duration{Duration: ramp},
+				},
+			},
+		},
+	}
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	listener := getTestListener("test.example.com")
+	gw := &gatewayapiv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gateway", Namespace: "test"},
+		Spec:       gatewayapiv1beta1.GatewaySpec{Listeners: []gatewayapiv1beta1.Listener{listener}},
+	}
+	clusterGateway := func(name, address string) dns.ClusterGateway {
+		return dns.ClusterGateway{
+			Cluster: &testutil.TestResource{ObjectMeta: metav1.ObjectMeta{Name: name}},
+			GatewayAddresses: []gatewayapiv1beta1.GatewayAddress{
+				{Type: testutil.Pointer(gatewayapiv1beta1.IPAddressType), Value: address},
+			},
+		}
+	}
+	placer := &testGatewayPlacer{clusterGateways: map[string]dns.ClusterGateway{
+		"test-cluster-1": clusterGateway("test-cluster-1", "1.1.1.1"),
+	}}
+
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedZone, gw).Build()
+	r := &DNSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		dnsHelper: dnsHelper{Client: f},
+		Placer:    placer,
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	// reconcile publishes the DNS records at the time and returns the weights reported in the status and published
+	// in the DNSRecord, as a reconcile of the policy would
+	reconcile := func(now time.Time) ([]v1alpha1.ClusterWeight, []string) {
+		t.Helper()
+		recordsStatus := &recordsPublishedStatus{weightRamp: newWeightRamp(ramp, dnsPolicy.Status.ClusterWeights, now)}
+		err := r.reconcileGatewayDNSRecords(ctx, gw, dnsPolicy, sets.New[string](), sets.New[string](), sets.New[string](), sets.New[string](), recordsStatus)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		dnsPolicy.Status.ClusterWeights = recordsStatus.weightRamp.sortedWeights()
+
+		dnsRecord, err := r.dnsHelper.getDNSRecordForListener(ctx, listener, gw)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		var published []string
+		for _, endpoint := range dnsRecord.Spec.Endpoints {
+			if weight, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok {
+				published = append(published, weight.Value)
+			}
+		}
+		sort.Strings(published)
+		return dnsPolicy.Status.ClusterWeights, published
+	}
+	weightOf := func(weights []v1alpha1.ClusterWeight, cluster string) v1alpha1.ClusterWeight {
+		t.Helper()
+		for _, clusterWeight := range weights {
+			if clusterWeight.Cluster == cluster {
+				return clusterWeight
+			}
+		}
+		t.Fatalf("expected a weight for cluster %s, got %v", cluster, weights)
+		return v1alpha1.ClusterWeight{}
+	}
+
+	// the clusters targeted when the ramp is first set get their weight right away
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	weights, published := reconcile(start)
+	want := []v1alpha1.ClusterWeight{{Cluster: "test-cluster-1", Weight: 120, TargetWeight: 120}}
+	if !reflect.DeepEqual(weights, want) {
+		t.Fatalf("expected cluster weights %v, got %v", want, weights)
+	}
+	if !reflect.DeepEqual(published, []string{"120"}) {
+		t.Errorf("expected the weight 120 to be published, got %v", published)
+	}
+	if after := weightRampRequeueAfter(weights, ramp, start); after != 0 {
+		t.Errorf("expected no requeue without a cluster ramping up, got %s", after)
+	}
+
+	// a joining cluster ramps up from 0 to its weight over the ramp duration, the weight increasing on every reconcile
+	placer.clusterGateways["test-cluster-2"] = clusterGateway("test-cluster-2", "2.2.2.2")
+	joined := start.Add(time.Hour)
+	previousWeight := -1
+	for _, step := range []struct {
+		elapsed       time.Duration
+		wantWeight    int
+		wantPublished []string
+	}{
+		{elapsed: 0, wantWeight: 0, wantPublished: []string{"0", "120"}},
+		{elapsed: time.Minute, wantWeight: 12, wantPublished: []string{"12", "120"}},
+		{elapsed: 5 * time.Minute, wantWeight: 60, wantPublished: []string{"120", "60"}},
+		{elapsed: 9 * time.Minute, wantWeight: 108, wantPublished: []string{"108", "120"}},
+		{elapsed: ramp, wantWeight: 120, wantPublished: []string{"120", "120"}},
+	} {
+		now := joined.Add(step.elapsed)
+		weights, published := reconcile(now)
+		ramped := weightOf(weights, "test-cluster-2")
+		if ramped.Weight != step.wantWeight || ramped.TargetWeight != 120 {
+			t.Errorf("expected the weight %d of 120 after %s, got %+v", step.wantWeight, step.elapsed, ramped)
+		}
+		if ramped.Weight <= previousWeight {
+			t.Errorf("expected the weight to increase across reconciles, got %d after %d", ramped.Weight, previousWeight)
+		}
+		previousWeight = ramped.Weight
+		if !reflect.DeepEqual(published, step.wantPublished) {
+			t.Errorf("expected the weights %v to be published after %s, got %v", step.wantPublished, step.elapsed, published)
+		}
+		if weightOf(weights, "test-cluster-1").Weight != 120 {
+			t.Errorf("expected the weight of the serving cluster to be unchanged, got %v", weights)
+		}
+
+		after := weightRampRequeueAfter(weights, ramp, now)
+		switch {
+		case step.elapsed >= ramp:
+			if ramped.RampStartTime != nil || after != 0 {
+				t.Errorf("expected the ramp to be complete, got %+v and requeue after %s", ramped, after)
+			}
+		case ramped.RampStartTime == nil || !ramped.RampStartTime.Time.Equal(joined):
+			t.Errorf("expected the ramp to start when the cluster joined, got %+v", ramped)
+		case after != ramp/weightRampSteps:
+			t.Errorf("expected the policy to be requeued after %s to step the weight, got %s", ramp/weightRampSteps, after)
+		}
+	}
+
+	// a cluster rejoining ramps up again
+	delete(placer.clusterGateways, "test-cluster-2")
+	weights, _ = reconcile(joined.Add(2 * ramp))
+	if len(weights) != 1 {
+		t.Fatalf("expected the weight of the cluster that left to be removed, got %v", weights)
+	}
+	placer.clusterGateways["test-cluster-2"] = clusterGateway("test-cluster-2", "2.2.2.2")
+	weights, _ = reconcile(joined.Add(3 * ramp))
+	if ramped := weightOf(weights, "test-cluster-2"); ramped.Weight != 0 || ramped.RampStartTime == nil {
+		t.Errorf("expected the rejoining cluster to ramp up again, got %+v", ramped)
+	}
+}
+
+func TestWeightRampRequeueAfter(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	ramping := func(since time.Duration) v1alpha1.ClusterWeight {
+		return v1alpha1.ClusterWeight{Cluster: "test-cluster", RampStartTime: &metav1.Time{Time: now.Add(-since)}}
+	}
+
+	testCases := []struct {
+		name     string
+		weights  []v1alpha1.ClusterWeight
+		duration time.Duration
+		want     time.Duration
+	}{
+		{
+			name:     "no cluster ramping up",
+			weights:  []v1alpha1.ClusterWeight{{Cluster: "test-cluster"}},
+			duration: time.Hour,
+			want:     0,
+		},
+		{
+			name:     "steps every tenth of the ramp",
+			weights:  []v1alpha1.ClusterWeight{ramping(time.Minute)},
+			duration: time.Hour,
+			want:     6 * time.Minute,
+		},
+		{
+			name:     "the last step is at the end of the ramp",
+			weights:  []v1alpha1.ClusterWeight{ramping(58 * time.Minute)},
+			duration: time.Hour,
+			want:     2 * time.Minute,
+		},
+		{
+			name:     "short ramps are stepped at the min interval",
+			weights:  []v1alpha1.ClusterWeight{ramping(0)},
+			duration: 20 * time.Second,
+			want:     minWeightRampInterval,
+		},
+		{
+			name:     "the soonest step of the clusters",
+			weights:  []v1alpha1.ClusterWeight{ramping(time.Minute), ramping(57 * time.Minute)},
+			duration: time.Hour,
+			want:     3 * time.Minute,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := weightRampRequeueAfter(testCase.weights, testCase.duration, now); got != testCase.want {
+				t.Errorf("expected requeue after %s, got %s", testCase.want, got)
+			}
+		})
+	}
+}
@@ -18,14 +18,20 @@ package dnsrecord
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -33,12 +39,25 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/logging"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
 )
 
 const (
 	DNSRecordFinalizer = "kuadrant.io/dns-record"
+
+	// zoneNotFoundBaseDelay and zoneNotFoundMaxDelay bound the exponential backoff of a record whose zone no longer
+	// exists in the DNS provider
+	zoneNotFoundBaseDelay = 30 * time.Second
+	zoneNotFoundMaxDelay  = 30 * time.Minute
+	// authenticationFailedBaseDelay and authenticationFailedMaxDelay bound the exponential backoff of a record whose
+	// managed zone credentials are rejected by the DNS provider
+	authenticationFailedBaseDelay = 2 * time.Minute
+	authenticationFailedMaxDelay  = time.Hour
 )
 
 var Clock clock.Clock = clock.RealClock{}
@@ -48,6 +67,17 @@ type DNSRecordReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
 	DNSProvider dns.DNSProviderFactory
+	// ZoneFilter restricts the zones the records are published to and deleted from
+	ZoneFilter dns.ZoneFilter
+	controller.ReconcilerOptions
+	// ChangeSink is sent the changes applied to the DNS providers, no changes are sent when nil
+	ChangeSink dns.ChangeSink
+
+	zoneNotFoundOnce    sync.Once
+	zoneNotFoundBackoff workqueue.RateLimiter
+
+	authenticationFailedOnce    sync.Once
+	authenticationFailedBackoff workqueue.RateLimiter
 }
 
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnsrecords,verbs=get;list;watch;create;update;patch;delete
@@ -55,7 +85,12 @@ type DNSRecordReconciler struct {
 //+kubebuilder:rbac:groups=kuadrant.io,resources=dnsrecords/finalizers,verbs=update
 
 func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	ctx, logger := logging.IntoReconcile(ctx, log.FromContext(ctx), logging.KeyDNSRecord, req.NamespacedName)
+
+	reconcileStart := time.Now()
+	defer func() {
+		reconcileDuration.Observe(time.Since(reconcileStart).Seconds())
+	}()
 
 	previous := &v1alpha1.DNSRecord{}
 	err := r.Client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: req.Name}, previous)
@@ -67,14 +102,50 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 	dnsRecord := previous.DeepCopy()
+	if dnsRecord.Spec.ManagedZoneRef != nil {
+		ctx, logger = logging.With(ctx, logging.KeyManagedZone, client.ObjectKey{Namespace: dnsRecord.Namespace, Name: dnsRecord.Spec.ManagedZoneRef.Name})
+	}
 
-	log.Log.V(3).Info("DNSRecordReconciler Reconcile", "dnsRecord", dnsRecord)
+	logger.V(3).Info("DNSRecordReconciler Reconcile", "record", dnsRecord)
 
 	if dnsRecord.DeletionTimestamp != nil && !dnsRecord.DeletionTimestamp.IsZero() {
-		if err := r.deleteRecord(ctx, dnsRecord); err != nil {
-			log.Log.Error(err, "Failed to delete DNSRecord", "record", dnsRecord)
+		if dnsRecord.Spec.DryRun && len(publishedRecordEndpoints(dnsRecord)) > 0 {
+			// the published records are only deleted from the provider once dry run is disabled, the finalizer keeps
+			// the record, and the published endpoints it tracks, until then
+			logger.Info("Deletion of DNSRecord in dry run mode pending, the record is published to the managed zone")
+			dnsRecord.Status.Plan = dns.Plan(publishedRecordEndpoints(dnsRecord), nil)
+			setDNSRecordCondition(dnsRecord, string(conditions.ConditionTypeReady), metav1.ConditionFalse, "DryRun",
+				fmt.Sprintf("Dry run, deletion pending, %d changes planned and not applied to the managed zone until dry run is disabled", len(dnsRecord.Status.Plan)))
+			if !equality.Semantic.DeepEqual(previous.Status, dnsRecord.Status) {
+				if err := r.Status().Update(ctx, dnsRecord); err != nil {
+					return ctrl.Result{}, err
+				}
+			}
+			return ctrl.Result{}, nil
+		}
+		if dnsRecord.Spec.DryRun {
+			logger.Info("Skipping deletion of DNSRecord in dry run mode, nothing was published to the managed zone")
+		} else if err := r.deleteRecord(ctx, dnsRecord); err != nil {
+			// the record is deleted from the zones frozen by a maintenance window once it is over
+			var window *maintenanceWindowError
+			if errors.As(err, &window) {
+				logger.Info("Deletion of DNSRecord delayed by a maintenance window", "reason", err.Error())
+				return ctrl.Result{RequeueAfter: requeueAfterWindows(0, []*maintenanceWindowError{window})}, nil
+			}
+			logger.Error(err, "Failed to delete DNSRecord", "record", dnsRecord)
 			return ctrl.Result{}, err
 		}
+		publishedEndpoints.DeleteLabelValues(dnsRecord.Name, dnsRecord.Namespace)
+		r.zoneBackoff().Forget(req.NamespacedName)
+		r.authenticationBackoff().Forget(req.NamespacedName)
+		if len(dnsRecord.Status.PublishedEndpoints) > 0 || len(dnsRecord.Status.ManagedZones) > 0 {
+			dnsRecord.Status.PublishedEndpoints = nil
+			dnsRecord.Status.EndpointsPublished = 0
+			dnsRecord.Status.ManagedZones = nil
+			if err := r.Status().Update(ctx, dnsRecord); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		controllerutil.RemoveFinalizer(dnsRecord, DNSRecordFinalizer)
 
 		err = r.Update(ctx, dnsRecord)
@@ -93,20 +164,88 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	var requeueAfter time.Duration
 	var reason, message string
 	status := metav1.ConditionTrue
 	reason = "ProviderSuccess"
 	message = "Provider ensured the managed zone"
 
-	// Publish the record
-	err = r.publishRecord(ctx, dnsRecord)
-	if err != nil {
+	if dnsRecord.Spec.DryRun {
+		// Report the changes against the last published endpoints without calling the provider
+		dnsRecord.Status.ObservedGeneration = dnsRecord.Generation
+		dnsRecord.Status.Plan = dns.Plan(dnsRecord.Status.Endpoints, dnsRecord.Spec.Endpoints)
 		status = metav1.ConditionFalse
-		reason = "ProviderError"
-		message = fmt.Sprintf("The DNS provider failed to ensure the record: %v", dns.SanitizeError(err))
+		reason = "DryRun"
+		message = fmt.Sprintf("Dry run, %d changes planned and not applied to the managed zone", len(dnsRecord.Status.Plan))
 	} else {
-		dnsRecord.Status.ObservedGeneration = dnsRecord.Generation
-		dnsRecord.Status.Endpoints = dnsRecord.Spec.Endpoints
+		dnsRecord.Status.Plan = nil
+
+		// Publish the record to each of its managed zones, a zone failing does not prevent the record from being
+		// published to the others
+		var failed []zoneResult
+		var errs []error
+		// windows are the maintenance windows freezing the changes to some of the zones
+		var windows []*maintenanceWindowError
+		// a changed force sync annotation publishes the record again to the zones it is already published to
+		forceSync := metadata.ForceSyncRequested(dnsRecord, dnsRecord.Status.ObservedForceSync)
+		if forceSync {
+			logger.Info("Force sync requested, publishing the record to all of its managed zones")
+		}
+		zoneNames := managedZoneNames(dnsRecord)
+		for _, zoneName := range zoneNames {
+			result := newZoneResult(zoneName, r.publishRecord(ctx, dnsRecord, zoneName, forceSync))
+			setZoneCondition(dnsRecord, zoneName, result)
+			if result.status == metav1.ConditionTrue {
+				continue
+			}
+			failed = append(failed, result)
+			if result.maintenanceWindow != nil {
+				windows = append(windows, result.maintenanceWindow)
+			}
+			if result.err != nil {
+				errs = append(errs, result.err)
+			}
+			if result.zoneNotFound && requeueAfter == 0 {
+				// the zone is unlikely to reappear soon, back off rather than retrying immediately
+				requeueAfter = r.zoneBackoff().When(req.NamespacedName)
+			}
+			if result.authenticationFailed && requeueAfter == 0 {
+				// the credentials are unlikely to be fixed soon, back off for longer than for a missing zone
+				requeueAfter = r.authenticationBackoff().When(req.NamespacedName)
+			}
+		}
+		removedWindows, unpublishErr := r.unpublishRemovedZones(ctx, dnsRecord, zoneNames)
+		if unpublishErr != nil {
+			errs = append(errs, unpublishErr)
+		}
+		windows = append(windows, removedWindows...)
+		err = errors.Join(errs...)
+		setMaintenanceWindowCondition(dnsRecord, windows)
+		// the record is published right after the end of the maintenance windows
+		requeueAfter = requeueAfterWindows(requeueAfter, windows)
+
+		if len(failed) > 0 {
+			status = metav1.ConditionFalse
+			reason = failed[0].reason
+			message = failed[0].message
+			if len(zoneNames) > 1 {
+				messages := make([]string, 0, len(failed))
+				for _, result := range failed {
+					messages = append(messages, fmt.Sprintf("managed zone %s: %s", result.zone, result.message))
+				}
+				message = strings.Join(messages, "; ")
+			}
+		} else {
+			if len(zoneNames) > 1 {
+				message = "Providers ensured the managed zones"
+			}
+			r.zoneBackoff().Forget(req.NamespacedName)
+			r.authenticationBackoff().Forget(req.NamespacedName)
+			dnsRecord.Status.ObservedGeneration = dnsRecord.Generation
+			dnsRecord.Status.Endpoints = dnsRecord.Spec.Endpoints
+			dnsRecord.Status.ObservedForceSync = metadata.GetAnnotation(dnsRecord, metadata.ForceSyncAnnotation)
+			publishedEndpoints.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace).Set(float64(len(dnsRecord.Status.Endpoints)))
+		}
 	}
 	setDNSRecordCondition(dnsRecord, string(conditions.ConditionTypeReady), status, reason, message)
 
@@ -121,95 +260,356 @@ func (r *DNSRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 
-	return ctrl.Result{}, err
+	return ctrl.Result{RequeueAfter: requeueAfter}, err
+}
+
+// zoneBackoff returns the backoff of the records whose zone was not found in the DNS provider
+func (r *DNSRecordReconciler) zoneBackoff() workqueue.RateLimiter {
+	r.zoneNotFoundOnce.Do(func() {
+		r.zoneNotFoundBackoff = workqueue.NewItemExponentialFailureRateLimiter(zoneNotFoundBaseDelay, zoneNotFoundMaxDelay)
+	})
+	return r.zoneNotFoundBackoff
+}
+
+// authenticationBackoff returns the backoff of the records whose managed zone credentials were rejected by the DNS
+// provider
+func (r *DNSRecordReconciler) authenticationBackoff() workqueue.RateLimiter {
+	r.authenticationFailedOnce.Do(func() {
+		r.authenticationFailedBackoff = workqueue.NewItemExponentialFailureRateLimiter(authenticationFailedBaseDelay, authenticationFailedMaxDelay)
+	})
+	return r.authenticationFailedBackoff
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DNSRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.DNSRecord{}).
-		Complete(r)
+		WithOptions(r.ControllerOptions()).
+		Complete(r.WrapReconciler(r))
 }
 
-// deleteRecord deletes record(s) in the DNSPRovider(i.e. route53) configured by the ManagedZone assigned to this
-// DNSRecord (dnsRecord.Status.ParentManagedZone).
+// deleteRecord deletes record(s) in the DNSPRovider(i.e. route53) configured by the ManagedZones assigned to this
+// DNSRecord and by the zones it is still published to, a zone failing does not prevent the record from being deleted
+// from the others.
 func (r *DNSRecordReconciler) deleteRecord(ctx context.Context, dnsRecord *v1alpha1.DNSRecord) error {
+	zoneNames := managedZoneNames(dnsRecord)
+	for _, zoneStatus := range dnsRecord.Status.ManagedZones {
+		if !slice.ContainsString(zoneNames, zoneStatus.Name) {
+			zoneNames = append(zoneNames, zoneStatus.Name)
+		}
+	}
+
+	var errs []error
+	for _, zoneName := range zoneNames {
+		if err := r.deleteRecordFromZone(ctx, dnsRecord, zoneName); err != nil {
+			errs = append(errs, fmt.Errorf("managed zone %s: %w", zoneName, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// publishedRecordEndpoints returns the endpoints of the record last published to any of its managed zones, nil when
+// the record was never published
+func publishedRecordEndpoints(dnsRecord *v1alpha1.DNSRecord) []*v1alpha1.Endpoint {
+	endpoints := append([]*v1alpha1.Endpoint{}, dnsRecord.Status.Endpoints...)
+	for _, zoneStatus := range dnsRecord.Status.ManagedZones {
+		endpoints = append(endpoints, zoneStatus.Endpoints...)
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+	return endpoints
+}
+
+// deleteRecordFromZone deletes record(s) in the DNSPRovider(i.e. route53) configured by the named ManagedZone
+func (r *DNSRecordReconciler) deleteRecordFromZone(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, zoneName string) error {
 	managedZone := &v1alpha1.ManagedZone{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      dnsRecord.Spec.ManagedZoneRef.Name,
+			Name:      zoneName,
 			Namespace: dnsRecord.Namespace,
 		},
 	}
+	ctx, logger := zoneLogger(ctx, dnsRecord, zoneName)
 	err := r.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone, &client.GetOptions{})
 	if err != nil {
 		// If the Managed Zone isn't found, just continue
 		return client.IgnoreNotFound(err)
 	}
+	if err := r.ZoneFilter.Validate(managedZone); err != nil {
+		logger.Info("Skipping deletion of DNSRecord in a managed zone not allowed by the zone filters")
+		return nil
+	}
 	managedZoneReady := meta.IsStatusConditionTrue(managedZone.Status.Conditions, "Ready")
 
 	if !managedZoneReady {
 		return fmt.Errorf("the managed zone is not in a ready state : %s", managedZone.Name)
 	}
+	if err := checkMaintenanceWindow(dnsRecord, managedZone); err != nil {
+		return err
+	}
 
 	dnsProvider, err := r.DNSProvider(ctx, managedZone)
 	if err != nil {
 		return err
 	}
 
-	err = dnsProvider.Delete(dnsRecord, managedZone)
+	record := zoneRecord(dnsRecord, zoneName)
+	err = dnsProvider.Delete(record, managedZone)
+	observeProviderRequest(operationDelete, err)
 	if err != nil {
-		if strings.Contains(err.Error(), "was not found") || strings.Contains(err.Error(), "notFound") {
-			log.Log.Info("Record not found in managed zone, continuing", "dnsRecord", dnsRecord.Name, "managedZone", managedZone.Name)
+		if errors.Is(err, dns.ErrZoneNotFound) {
+			logger.Info("Zone not found in the DNS provider, the records were deleted with it")
+			return nil
+		} else if strings.Contains(err.Error(), "was not found") || strings.Contains(err.Error(), "notFound") {
+			logger.Info("Record not found in managed zone, continuing")
 			return nil
 		} else if strings.Contains(err.Error(), "no endpoints") {
-			log.Log.Info("DNS record had no endpoint, continuing", "dnsRecord", dnsRecord.Name, "managedZone", managedZone.Name)
+			logger.Info("DNS record had no endpoint, continuing")
 			return nil
 		}
 		return err
 	}
-	log.Log.Info("Deleted DNSRecord in manage zone", "dnsRecord", dnsRecord.Name, "managedZone", managedZone.Name)
+	logger.Info("Deleted DNSRecord in manage zone")
+	r.sendChanges(ctx, dnsRecord, managedZone, dns.Changes(record.Status.PublishedEndpoints, nil))
 
 	return nil
 }
 
-// publishRecord publishes record(s) to the DNSPRovider(i.e. route53) configured by the ManagedZone assigned to this
-// DNSRecord (dnsRecord.Status.ParentManagedZone).
-func (r *DNSRecordReconciler) publishRecord(ctx context.Context, dnsRecord *v1alpha1.DNSRecord) error {
+// unpublishRemovedZones deletes the record from the managed zones it was published to and that it no longer
+// references. The status of a zone is kept until the record is deleted from it. The maintenance windows of the zones
+// the record could not be deleted from yet are returned.
+func (r *DNSRecordReconciler) unpublishRemovedZones(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, zoneNames []string) ([]*maintenanceWindowError, error) {
+	var errs []error
+	var windows []*maintenanceWindowError
+	zoneStatuses := make([]v1alpha1.DNSRecordZoneStatus, 0, len(dnsRecord.Status.ManagedZones))
+	for _, zoneStatus := range dnsRecord.Status.ManagedZones {
+		if !slice.ContainsString(zoneNames, zoneStatus.Name) {
+			err := r.deleteRecordFromZone(ctx, dnsRecord, zoneStatus.Name)
+			var window *maintenanceWindowError
+			switch {
+			case err == nil:
+				continue
+			case errors.As(err, &window):
+				windows = append(windows, window)
+			default:
+				errs = append(errs, fmt.Errorf("failed to delete the record from managed zone %s: %w", zoneStatus.Name, err))
+			}
+		}
+		zoneStatuses = append(zoneStatuses, zoneStatus)
+	}
+	dnsRecord.Status.ManagedZones = zoneStatuses
+	return windows, errors.Join(errs...)
+}
+
+// publishRecord publishes record(s) to the DNSPRovider(i.e. route53) configured by the named ManagedZone assigned to
+// this DNSRecord, and records the endpoints published in the status of the zone. A record already published to the
+// zone at its generation is only published again when forced, or once the resync period has passed since it was last
+// published to the zone so that the changes made to the records in the provider are reverted.
+func (r *DNSRecordReconciler) publishRecord(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, zoneName string, force bool) error {
 
 	managedZone := &v1alpha1.ManagedZone{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      dnsRecord.Spec.ManagedZoneRef.Name,
+			Name:      zoneName,
 			Namespace: dnsRecord.Namespace,
 		},
 	}
+	ctx, logger := zoneLogger(ctx, dnsRecord, zoneName)
 	err := r.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone, &client.GetOptions{})
 	if err != nil {
 		return err
 	}
+	if err := r.ZoneFilter.Validate(managedZone); err != nil {
+		return err
+	}
 	managedZoneReady := meta.IsStatusConditionTrue(managedZone.Status.Conditions, "Ready")
 
 	if !managedZoneReady {
 		return fmt.Errorf("the managed zone is not in a ready state : %s", managedZone.Name)
 	}
 
-	if dnsRecord.Generation == dnsRecord.Status.ObservedGeneration {
-		log.Log.V(3).Info("Skipping managed zone to which the DNS dnsRecord is already published", "dnsRecord", dnsRecord.Name, "managedZone", managedZone.Name)
+	if !force && dnsRecord.Generation == zoneStatus(dnsRecord, zoneName).ObservedGeneration && !r.resyncDue(dnsRecord, zoneName) {
+		logger.V(3).Info("Skipping managed zone to which the DNS dnsRecord is already published")
 		return nil
 	}
+	// the record is published once the maintenance window is over, with the endpoints desired by then
+	if err := checkMaintenanceWindow(dnsRecord, managedZone); err != nil {
+		return err
+	}
 	dnsProvider, err := r.DNSProvider(ctx, managedZone)
 	if err != nil {
 		return err
 	}
 
-	err = dnsProvider.Ensure(dnsRecord, managedZone)
+	// the provider is sent the endpoints with the defaults resolved so that they can be reported as published
+	record := zoneRecord(dnsRecord, zoneName)
+	record.Spec.Endpoints = dns.ResolveEndpoints(dnsRecord.Spec.Endpoints)
+	err = dnsProvider.Ensure(record, managedZone)
+	observeProviderRequest(operationEnsure, err)
 	if err != nil {
 		return err
 	}
-	log.Log.Info("Published DNSRecord to manage zone", "dnsRecord", dnsRecord.Name, "managedZone", managedZone.Name)
+	published := zoneStatus(dnsRecord, zoneName)
+	r.sendChanges(ctx, dnsRecord, managedZone, dns.Changes(published.PublishedEndpoints, record.Spec.Endpoints))
+	published.ObservedGeneration = dnsRecord.Generation
+	published.Endpoints = dnsRecord.Spec.Endpoints
+	published.PublishedEndpoints = record.Spec.Endpoints
+	if zoneName == dnsRecord.Spec.ManagedZoneRef.Name {
+		dnsRecord.Status.PublishedEndpoints = record.Spec.Endpoints
+	}
+	dnsRecord.Status.EndpointsPublished = int64(len(record.Spec.Endpoints))
+	publishedTime := metav1.NewTime(Clock.Now())
+	published.LastPublishedTime = &publishedTime
+	dnsRecord.Status.LastPublishedTime = &publishedTime
+	logger.Info("Published DNSRecord to manage zone")
 
 	return nil
 }
 
+// resyncDue returns true if the resync period has passed since the record was last published to the zone, or when the
+// publish time of the zone is unknown. It is always false when the records are not resynced.
+func (r *DNSRecordReconciler) resyncDue(dnsRecord *v1alpha1.DNSRecord, zoneName string) bool {
+	if r.ResyncPeriod <= 0 {
+		return false
+	}
+	lastPublished := zoneStatus(dnsRecord, zoneName).LastPublishedTime
+	return lastPublished == nil || Clock.Since(lastPublished.Time) >= r.ResyncPeriod
+}
+
+// sendChanges sends the changes applied to the managed zone to the change sink. Failing to send them is logged and
+// does not fail the reconcile, as the changes are already applied.
+func (r *DNSRecordReconciler) sendChanges(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone, changes []dns.RecordChange) {
+	if r.ChangeSink == nil || len(changes) == 0 {
+		return
+	}
+	event := dns.ChangeEvent{
+		Time:        Clock.Now().UTC(),
+		Zone:        managedZone.Spec.DomainName,
+		ZoneID:      managedZone.Status.ID,
+		ManagedZone: dns.ObjectReference{Namespace: managedZone.Namespace, Name: managedZone.Name},
+		DNSRecord:   dns.ObjectReference{Namespace: dnsRecord.Namespace, Name: dnsRecord.Name},
+		Changes:     changes,
+	}
+	if err := r.ChangeSink.Send(ctx, event); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send the DNS changes to the change sink", "changes", len(changes))
+	}
+}
+
+// zoneLogger returns the context and logger used for a zone of the record, with the zone field added for the
+// additional managed zones as the managed zone of the record is already a field of the reconcile logger
+func zoneLogger(ctx context.Context, dnsRecord *v1alpha1.DNSRecord, zoneName string) (context.Context, logr.Logger) {
+	if zoneName == dnsRecord.Spec.ManagedZoneRef.Name {
+		return ctx, log.FromContext(ctx)
+	}
+	return logging.With(ctx, logging.KeyManagedZone, client.ObjectKey{Namespace: dnsRecord.Namespace, Name: zoneName})
+}
+
+// managedZoneNames returns the names of the managed zones the record is published to, its managed zone first
+func managedZoneNames(dnsRecord *v1alpha1.DNSRecord) []string {
+	zoneNames := []string{dnsRecord.Spec.ManagedZoneRef.Name}
+	for _, zoneRef := range dnsRecord.Spec.AdditionalManagedZoneRefs {
+		if !slice.ContainsString(zoneNames, zoneRef.Name) {
+			zoneNames = append(zoneNames, zoneRef.Name)
+		}
+	}
+	return zoneNames
+}
+
+// zoneStatus returns the status of the record in the named managed zone, added to the status of the record when
+// missing. The status of the managed zone of a record published before the zones had a status is the status of the
+// record itself.
+func zoneStatus(dnsRecord *v1alpha1.DNSRecord, zoneName string) *v1alpha1.DNSRecordZoneStatus {
+	for i := range dnsRecord.Status.ManagedZones {
+		if dnsRecord.Status.ManagedZones[i].Name == zoneName {
+			return &dnsRecord.Status.ManagedZones[i]
+		}
+	}
+	status := v1alpha1.DNSRecordZoneStatus{Name: zoneName}
+	if zoneName == dnsRecord.Spec.ManagedZoneRef.Name {
+		status.ObservedGeneration = dnsRecord.Status.ObservedGeneration
+		status.Endpoints = dnsRecord.Status.Endpoints
+		status.PublishedEndpoints = dnsRecord.Status.PublishedEndpoints
+		status.LastPublishedTime = dnsRecord.Status.LastPublishedTime
+	}
+	dnsRecord.Status.ManagedZones = append(dnsRecord.Status.ManagedZones, status)
+	return &dnsRecord.Status.ManagedZones[len(dnsRecord.Status.ManagedZones)-1]
+}
+
+// zoneRecord returns a copy of the record holding the endpoints last published to the named managed zone, so that
+// the provider of each zone deletes its own stale records
+func zoneRecord(dnsRecord *v1alpha1.DNSRecord, zoneName string) *v1alpha1.DNSRecord {
+	record := dnsRecord.DeepCopy()
+	published := zoneStatus(record, zoneName)
+	record.Status.Endpoints = published.Endpoints
+	record.Status.PublishedEndpoints = published.PublishedEndpoints
+	return record
+}
+
+// zoneResult is the outcome of publishing the record to one of its managed zones
+type zoneResult struct {
+	zone    string
+	status  metav1.ConditionStatus
+	reason  string
+	message string
+	// err is the error to retry publishing the record with, nil when retrying immediately cannot succeed
+	err          error
+	zoneNotFound bool
+	// authenticationFailed is true if the DNS provider rejected the credentials of the zone
+	authenticationFailed bool
+	// maintenanceWindow is the maintenance window freezing the changes to the zone
+	maintenanceWindow *maintenanceWindowError
+}
+
+func newZoneResult(zoneName string, err error) zoneResult {
+	result := zoneResult{zone: zoneName, status: metav1.ConditionFalse}
+	var window *maintenanceWindowError
+	var authErr *dns.AuthenticationError
+	if errors.As(err, &window) {
+		result.reason = "MaintenanceWindow"
+		result.message = fmt.Sprintf("The changes to the record are published once the maintenance window is over: %v", err)
+		result.maintenanceWindow = window
+	} else if errors.Is(err, errInvalidMaintenanceWindow) {
+		result.reason = "InvalidMaintenanceWindow"
+		result.message = fmt.Sprintf("The changes to the record are not published while the maintenance window is invalid: %v", err)
+		result.err = err
+	} else if errors.Is(err, dns.ErrZoneNotAllowed) {
+		result.reason = "ZoneNotAllowed"
+		result.message = fmt.Sprintf("The record is in a zone not managed by this controller: %v", err)
+		// retrying cannot succeed until the controller is restarted with other zone filters
+	} else if errors.Is(err, dns.ErrZoneNotFound) {
+		result.reason = "ZoneNotFound"
+		result.message = fmt.Sprintf("The zone of the record no longer exists in the DNS provider: %v", dns.SanitizeError(err))
+		result.zoneNotFound = true
+	} else if errors.As(err, &authErr) {
+		result.reason = "ProviderAuthenticationFailed"
+		result.message = fmt.Sprintf("The DNS provider failed to authenticate with the credentials of the managed zone: %v", dns.SanitizeError(authErr))
+		result.authenticationFailed = true
+	} else if errors.Is(err, dns.ErrOwnershipConflict) {
+		result.reason = "OwnershipConflict"
+		result.message = fmt.Sprintf("The DNS provider did not change records owned by another controller: %v", dns.SanitizeError(err))
+		result.err = err
+	} else if err != nil {
+		result.reason = "ProviderError"
+		result.message = fmt.Sprintf("The DNS provider failed to ensure the record: %v", dns.SanitizeError(err))
+		result.err = err
+	} else {
+		result.status = metav1.ConditionTrue
+		result.reason = "ProviderSuccess"
+		result.message = "Provider ensured the managed zone"
+	}
+	return result
+}
+
+// setZoneCondition sets the Ready condition of the record in the managed zone of the result
+func setZoneCondition(dnsRecord *v1alpha1.DNSRecord, zoneName string, result zoneResult) {
+	meta.SetStatusCondition(&zoneStatus(dnsRecord, zoneName).Conditions, metav1.Condition{
+		Type:               string(conditions.ConditionTypeReady),
+		Status:             result.status,
+		Reason:             result.reason,
+		Message:            result.message,
+		ObservedGeneration: dnsRecord.Generation,
+	})
+}
+
 // setDNSRecordCondition adds or updates a given condition in the DNSRecord status..
 func setDNSRecordCondition(dnsRecord *v1alpha1.DNSRecord, conditionType string, status metav1.ConditionStatus, reason, message string) {
 	cond := metav1.Condition{
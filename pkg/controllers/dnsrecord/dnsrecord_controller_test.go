@@ -0,0 +1,1500 @@
+//go:build unit
+
+package dnsrecord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/logging"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+// mutationRecordingProvider counts the calls made to the mutating provider APIs and records the endpoints last ensured
+type mutationRecordingProvider struct {
+	dns.FakeProvider
+	ensureCalls      int
+	deleteCalls      int
+	ensuredEndpoints []*v1alpha1.Endpoint
+}
+
+func (p *mutationRecordingProvider) Ensure(record *v1alpha1.DNSRecord, _ *v1alpha1.ManagedZone) error {
+	p.ensureCalls++
+	p.ensuredEndpoints = record.Spec.Endpoints
+	return nil
+}
+
+func (p *mutationRecordingProvider) Delete(_ *v1alpha1.DNSRecord, _ *v1alpha1.ManagedZone) error {
+	p.deleteCalls++
+	return nil
+}
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s ", err)
+	}
+	return scheme
+}
+
+func TestDNSRecordReconciler_Reconcile_metrics(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   "Ready",
+					Status: metav1.ConditionTrue,
+				},
+			},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: "example.com",
+			},
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:    "test.example.com",
+					Targets:    []string{"1.1.1.1"},
+					RecordType: "A",
+					RecordTTL:  60,
+				},
+			},
+		},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return &dns.FakeProvider{}, nil
+		},
+	}
+
+	before := testutil.ToFloat64(providerRequestTotal.WithLabelValues(operationEnsure, resultSuccess))
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if got := testutil.ToFloat64(providerRequestTotal.WithLabelValues(operationEnsure, resultSuccess)); got != before+1 {
+		t.Errorf("expected %v successful ensure requests, got %v", before+1, got)
+	}
+	if got := testutil.ToFloat64(providerRequestTotal.WithLabelValues(operationEnsure, resultError)); got != 0 {
+		t.Errorf("expected no failed ensure requests, got %v", got)
+	}
+	if got := testutil.ToFloat64(publishedEndpoints.WithLabelValues(dnsRecord.Name, dnsRecord.Namespace)); got != 1 {
+		t.Errorf("expected 1 published endpoint, got %v", got)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_dryRun(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   "Ready",
+					Status: metav1.ConditionTrue,
+				},
+			},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 2,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: "example.com",
+			},
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:    "test.example.com",
+					Targets:    []string{"2.2.2.2"},
+					RecordType: "A",
+					RecordTTL:  60,
+				},
+			},
+			DryRun: true,
+		},
+		Status: v1alpha1.DNSRecordStatus{
+			ObservedGeneration: 1,
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:    "test.example.com",
+					Targets:    []string{"1.1.1.1"},
+					RecordType: "A",
+					RecordTTL:  60,
+				},
+			},
+		},
+	}
+
+	provider := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if provider.ensureCalls != 0 || provider.deleteCalls != 0 {
+		t.Errorf("expected no provider mutations, got %v ensure and %v delete calls", provider.ensureCalls, provider.deleteCalls)
+	}
+
+	got := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	wantPlan := []string{"UPDATE test.example.com 60 IN A  [2.2.2.2] []"}
+	if !reflect.DeepEqual(got.Status.Plan, wantPlan) {
+		t.Errorf("expected plan %v, got %v", wantPlan, got.Status.Plan)
+	}
+	if !reflect.DeepEqual(got.Status.Endpoints, dnsRecord.Status.Endpoints) {
+		t.Errorf("expected published endpoints to be unchanged, got %v", got.Status.Endpoints)
+	}
+	ready := meta.FindStatusCondition(got.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != "DryRun" {
+		t.Errorf("expected Ready condition with reason DryRun, got %v", ready)
+	}
+
+	// deleting a published record in dry run mode does not delete the provider records, the record is kept until
+	// dry run is disabled
+	if err := f.Delete(context.TODO(), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if provider.ensureCalls != 0 || provider.deleteCalls != 0 {
+		t.Errorf("expected no provider mutations on deletion, got %v ensure and %v delete calls", provider.ensureCalls, provider.deleteCalls)
+	}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), got); err != nil {
+		t.Fatalf("expected the record to be kept, got %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(got, DNSRecordFinalizer) {
+		t.Errorf("expected the finalizer to be kept")
+	}
+	wantPlan = []string{"DELETE test.example.com 60 IN A  [1.1.1.1] []"}
+	if !reflect.DeepEqual(got.Status.Plan, wantPlan) {
+		t.Errorf("expected plan %v, got %v", wantPlan, got.Status.Plan)
+	}
+	if !reflect.DeepEqual(got.Status.Endpoints, dnsRecord.Status.Endpoints) {
+		t.Errorf("expected published endpoints to be unchanged, got %v", got.Status.Endpoints)
+	}
+	ready = meta.FindStatusCondition(got.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != "DryRun" {
+		t.Errorf("expected Ready condition with reason DryRun, got %v", ready)
+	}
+
+	// the provider records are deleted once dry run is disabled
+	got.Spec.DryRun = false
+	if err := f.Update(context.TODO(), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if provider.deleteCalls != 1 {
+		t.Errorf("expected the provider records to be deleted, got %v delete calls", provider.deleteCalls)
+	}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), got); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the record to be deleted, got %v", err)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_dryRunDeletion(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	newRecord := func(name string, dryRun bool) *v1alpha1.DNSRecord {
+		return &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Generation: 1, Finalizers: []string{DNSRecordFinalizer}},
+			Spec: v1alpha1.DNSRecordSpec{
+				ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "example.com"},
+				Endpoints: []*v1alpha1.Endpoint{
+					{DNSName: name, Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60},
+				},
+				DryRun: dryRun,
+			},
+		}
+	}
+	published := newRecord("published.example.com", false)
+	unpublished := newRecord("unpublished.example.com", true)
+
+	provider := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, published, unpublished).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+	reconcile := func(dnsRecord *v1alpha1.DNSRecord) {
+		t.Helper()
+		if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	// the record is published, then switched to dry run and deleted
+	reconcile(published)
+	if provider.ensureCalls != 1 {
+		t.Fatalf("expected the record to be published, got %v ensure calls", provider.ensureCalls)
+	}
+	got := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(published), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got.Spec.DryRun = true
+	if err := f.Update(context.TODO(), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := f.Delete(context.TODO(), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	reconcile(published)
+	if provider.deleteCalls != 0 {
+		t.Errorf("expected no provider deletion in dry run mode, got %v delete calls", provider.deleteCalls)
+	}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(published), got); err != nil {
+		t.Fatalf("expected the published record to be kept, got %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(got, DNSRecordFinalizer) {
+		t.Errorf("expected the finalizer to be kept while the record is published")
+	}
+	if len(got.Status.Endpoints) != 1 || got.Status.Endpoints[0].DNSName != "published.example.com" {
+		t.Errorf("expected the published endpoints to still be tracked, got %v", got.Status.Endpoints)
+	}
+
+	// a record never published is deleted right away
+	if err := f.Delete(context.TODO(), unpublished); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	reconcile(unpublished)
+	if provider.deleteCalls != 0 {
+		t.Errorf("expected no provider deletion in dry run mode, got %v delete calls", provider.deleteCalls)
+	}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(unpublished), got); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the unpublished record to be deleted, got %v", err)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_publishedEndpoints(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   "Ready",
+					Status: metav1.ConditionTrue,
+				},
+			},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: "example.com",
+			},
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:       "test.example.com",
+					Targets:       []string{"lb-1.test.example.com"},
+					RecordType:    "CNAME",
+					SetIdentifier: "lb-1",
+					ProviderSpecific: v1alpha1.ProviderSpecific{
+						{Name: "weight", Value: "120"},
+						{Name: "geo-code", Value: "IE"},
+					},
+				},
+				{
+					DNSName:    "lb-1.test.example.com",
+					Targets:    []string{"1.1.1.1"},
+					RecordType: "A",
+					RecordTTL:  300,
+				},
+			},
+		},
+	}
+
+	provider := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if provider.ensureCalls != 1 {
+		t.Fatalf("expected 1 ensure call, got %v", provider.ensureCalls)
+	}
+
+	got := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), got); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(got.Status.PublishedEndpoints, provider.ensuredEndpoints) {
+		t.Errorf("expected published endpoints %v to match the ensured endpoints %v", got.Status.PublishedEndpoints, provider.ensuredEndpoints)
+	}
+	if len(got.Status.PublishedEndpoints) != 2 {
+		t.Fatalf("expected 2 published endpoints, got %v", got.Status.PublishedEndpoints)
+	}
+	if ttl := got.Status.PublishedEndpoints[0].RecordTTL; ttl != dns.DefaultTTL {
+		t.Errorf("expected the unset TTL to resolve to %v, got %v", dns.DefaultTTL, ttl)
+	}
+	if ttl := got.Status.PublishedEndpoints[1].RecordTTL; ttl != 300 {
+		t.Errorf("expected the explicit TTL to be kept, got %v", ttl)
+	}
+	if weight, _ := got.Status.PublishedEndpoints[0].GetProviderSpecificProperty("weight"); weight.Value != "120" {
+		t.Errorf("expected weight 120, got %v", weight.Value)
+	}
+	if geo, _ := got.Status.PublishedEndpoints[0].GetProviderSpecificProperty("geo-code"); geo.Value != "IE" {
+		t.Errorf("expected geo code IE, got %v", geo.Value)
+	}
+	if got.Spec.Endpoints[0].RecordTTL != 0 {
+		t.Errorf("expected the spec endpoints to be unchanged, got TTL %v", got.Spec.Endpoints[0].RecordTTL)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_publishStatus(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   "Ready",
+					Status: metav1.ConditionTrue,
+				},
+			},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: "example.com",
+			},
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:    "test.example.com",
+					Targets:    []string{"1.1.1.1"},
+					RecordType: "A",
+				},
+			},
+		},
+	}
+
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	Clock = fakeClock
+	defer func() { Clock = clock.RealClock{} }()
+
+	provider := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+	request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}
+
+	expectPublished := func(count int64, publishedTime time.Time) {
+		t.Helper()
+		if _, err := r.Reconcile(context.TODO(), request); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		got := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), request.NamespacedName, got); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if got.Status.EndpointsPublished != count {
+			t.Errorf("expected %d endpoints published, got %d", count, got.Status.EndpointsPublished)
+		}
+		if got.Status.LastPublishedTime == nil || !got.Status.LastPublishedTime.Time.Equal(publishedTime) {
+			t.Errorf("expected the last published time to be %v, got %v", publishedTime, got.Status.LastPublishedTime)
+		}
+	}
+
+	expectPublished(1, fakeClock.Now())
+
+	// changing the endpoints publishes them again
+	firstPublished := fakeClock.Now()
+	fakeClock.Step(time.Hour)
+	updated := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), request.NamespacedName, updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	updated.Generation = 2
+	updated.Spec.Endpoints = append(updated.Spec.Endpoints, &v1alpha1.Endpoint{
+		DNSName:    "test.example.com",
+		Targets:    []string{"2001:db8::1"},
+		RecordType: "AAAA",
+	})
+	if err := f.Update(context.TODO(), updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expectPublished(2, fakeClock.Now())
+
+	// the status is left unchanged while there is nothing to publish
+	fakeClock.Step(time.Hour)
+	expectPublished(2, firstPublished.Add(time.Hour))
+}
+
+// conflictingProvider fails to ensure records owned by another controller
+type conflictingProvider struct {
+	dns.FakeProvider
+}
+
+func (p *conflictingProvider) Ensure(_ *v1alpha1.DNSRecord, _ *v1alpha1.ManagedZone) error {
+	return fmt.Errorf("%w: [test.example.com]", dns.ErrOwnershipConflict)
+}
+
+func TestDNSRecordReconciler_Reconcile_ownershipConflict(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   "Ready",
+					Status: metav1.ConditionTrue,
+				},
+			},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: "example.com",
+			},
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:    "test.example.com",
+					Targets:    []string{"1.1.1.1"},
+					RecordType: "A",
+				},
+			},
+		},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return &conflictingProvider{}, nil
+		},
+	}
+
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err == nil {
+		t.Fatalf("expected the ownership conflict to be returned")
+	}
+
+	updated := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+		t.Fatalf("failed to get dns record %s", err)
+	}
+	cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "OwnershipConflict" {
+		t.Errorf("expected the record not to be ready because of an ownership conflict, got %+v", cond)
+	}
+	if len(updated.Status.Endpoints) != 0 {
+		t.Errorf("expected no endpoints to be reported as published, got %v", updated.Status.Endpoints)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_zoneNotAllowed(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			ID: "production",
+			Conditions: []metav1.Condition{
+				{
+					Type:   "Ready",
+					Status: metav1.ConditionTrue,
+				},
+			},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: "example.com",
+			},
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:    "test.example.com",
+					Targets:    []string{"1.1.1.1"},
+					RecordType: "A",
+				},
+			},
+		},
+	}
+
+	provider := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+		ZoneFilter: dns.NewZoneFilter("staging", ""),
+	}
+
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if provider.ensureCalls != 0 {
+		t.Errorf("expected the provider not to be called, got %d ensure calls", provider.ensureCalls)
+	}
+
+	updated := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+		t.Fatalf("failed to get dns record %s", err)
+	}
+	cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ZoneNotAllowed" {
+		t.Errorf("expected the record not to be ready because its zone is not allowed, got %+v", cond)
+	}
+}
+
+// zoneNotFoundProvider fails to ensure records while its zone is missing
+type zoneNotFoundProvider struct {
+	dns.FakeProvider
+	zoneMissing bool
+}
+
+func (p *zoneNotFoundProvider) Ensure(_ *v1alpha1.DNSRecord, _ *v1alpha1.ManagedZone) error {
+	if p.zoneMissing {
+		return fmt.Errorf("%w: route53 hosted zone test-zone: NoSuchHostedZone", dns.ErrZoneNotFound)
+	}
+	return nil
+}
+
+func TestDNSRecordReconciler_Reconcile_zoneNotFound(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example.com",
+			Namespace: "test",
+		},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:   "Ready",
+					Status: metav1.ConditionTrue,
+				},
+			},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+				Name: "example.com",
+			},
+			Endpoints: []*v1alpha1.Endpoint{
+				{
+					DNSName:    "test.example.com",
+					Targets:    []string{"1.1.1.1"},
+					RecordType: "A",
+				},
+			},
+		},
+	}
+
+	provider := &zoneNotFoundProvider{zoneMissing: true}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}
+	getReady := func() *metav1.Condition {
+		updated := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+			t.Fatalf("failed to get dns record %s", err)
+		}
+		return meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+	}
+
+	// the requeue delay doubles while the zone is missing
+	for _, wantRequeueAfter := range []time.Duration{zoneNotFoundBaseDelay, 2 * zoneNotFoundBaseDelay, 4 * zoneNotFoundBaseDelay} {
+		result, err := r.Reconcile(context.TODO(), req)
+		if err != nil {
+			t.Fatalf("expected the missing zone not to be returned as an error, got %v", err)
+		}
+		if result.RequeueAfter != wantRequeueAfter || result.Requeue {
+			t.Errorf("expected the record to be requeued after %s, got %+v", wantRequeueAfter, result)
+		}
+		if cond := getReady(); cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ZoneNotFound" {
+			t.Errorf("expected the record not to be ready because its zone is not found, got %+v", cond)
+		}
+	}
+
+	// the record is published once the zone reappears and the backoff is reset
+	provider.zoneMissing = false
+	result, err := r.Reconcile(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected the published record not to be requeued, got %+v", result)
+	}
+	if cond := getReady(); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected the record to be ready once its zone is found, got %+v", cond)
+	}
+	if requeues := r.zoneBackoff().NumRequeues(req.NamespacedName); requeues != 0 {
+		t.Errorf("expected the backoff to be reset, got %d requeues", requeues)
+	}
+}
+
+// authenticationFailedProvider fails to ensure records while the credentials of the zone are rejected
+type authenticationFailedProvider struct {
+	dns.FakeProvider
+	rejected bool
+}
+
+func (p *authenticationFailedProvider) Ensure(_ *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
+	if p.rejected {
+		return dns.NewAuthenticationError(managedZone, fmt.Errorf("AccessDenied: not authorized to perform route53:ChangeResourceRecordSets"))
+	}
+	return nil
+}
+
+func TestDNSRecordReconciler_Reconcile_authenticationFailed(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName: "example.com",
+			SecretRef:  &v1alpha1.SecretRef{Namespace: "test", Name: "aws-credentials"},
+		},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "example.com"},
+			Endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"},
+			},
+		},
+	}
+
+	provider := &authenticationFailedProvider{rejected: true}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}
+
+	for _, wantRequeueAfter := range []time.Duration{authenticationFailedBaseDelay, 2 * authenticationFailedBaseDelay} {
+		result, err := r.Reconcile(context.TODO(), req)
+		if err != nil {
+			t.Fatalf("expected the rejected credentials not to be returned as an error, got %v", err)
+		}
+		if result.RequeueAfter != wantRequeueAfter {
+			t.Errorf("expected the record to be requeued after %s, got %+v", wantRequeueAfter, result)
+		}
+		updated := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+			t.Fatalf("failed to get dns record %s", err)
+		}
+		cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready")
+		if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ProviderAuthenticationFailed" {
+			t.Fatalf("expected the record not to be ready because the credentials are rejected, got %+v", cond)
+		}
+		if !strings.Contains(cond.Message, "test/aws-credentials") {
+			t.Errorf("expected the condition message to name the credentials secret, got %q", cond.Message)
+		}
+	}
+
+	// the backoff is reset once the credentials are fixed
+	provider.rejected = false
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if requeues := r.authenticationBackoff().NumRequeues(req.NamespacedName); requeues != 0 {
+		t.Errorf("expected the backoff to be reset, got %d requeues", requeues)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_logFields(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "example.com"},
+			Endpoints:      []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"}},
+		},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return &dns.FakeProvider{}, nil
+		},
+	}
+
+	var lines []map[string]interface{}
+	logger := funcr.NewJSON(func(obj string) {
+		line := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(obj), &line); err != nil {
+			t.Errorf("invalid log line %s: %v", obj, err)
+		}
+		lines = append(lines, line)
+	}, funcr.Options{Verbosity: 3})
+	ctx := logr.NewContext(context.TODO(), logger)
+
+	traceIDs := map[interface{}]struct{}{}
+	for i := 0; i < 2; i++ {
+		lines = nil
+		if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if len(lines) == 0 {
+			t.Fatalf("expected the reconcile to log")
+		}
+		traceID := lines[0][logging.KeyTraceID]
+		if traceID == nil || traceID == "" {
+			t.Fatalf("expected a %s field, got %v", logging.KeyTraceID, lines[0])
+		}
+		traceIDs[traceID] = struct{}{}
+		for _, line := range lines {
+			if line[logging.KeyTraceID] != traceID {
+				t.Errorf("expected every line of the reconcile to have trace ID %v, got %v", traceID, line)
+			}
+			if line[logging.KeyDNSRecord] != "test/test.example.com" {
+				t.Errorf("expected the %s field to be test/test.example.com, got %v", logging.KeyDNSRecord, line)
+			}
+			if line[logging.KeyManagedZone] != "test/example.com" {
+				t.Errorf("expected the %s field to be test/example.com, got %v", logging.KeyManagedZone, line)
+			}
+		}
+	}
+	if len(traceIDs) != 2 {
+		t.Errorf("expected a new trace ID for each reconcile, got %v", traceIDs)
+	}
+}
+
+// failingProvider fails to ensure records until it is fixed
+type failingProvider struct {
+	mutationRecordingProvider
+	failing bool
+}
+
+func (p *failingProvider) Ensure(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
+	if p.failing {
+		return fmt.Errorf("provider unavailable")
+	}
+	return p.mutationRecordingProvider.Ensure(record, managedZone)
+}
+
+func TestDNSRecordReconciler_Reconcile_additionalManagedZones(t *testing.T) {
+	readyZone := func(name string) *v1alpha1.ManagedZone {
+		return &v1alpha1.ManagedZone{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+			Status: v1alpha1.ManagedZoneStatus{
+				Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+			},
+		}
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef:            &v1alpha1.ManagedZoneReference{Name: "route53"},
+			AdditionalManagedZoneRefs: []v1alpha1.ManagedZoneReference{{Name: "cloudflare"}},
+			Endpoints:                 []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"}},
+		},
+	}
+
+	route53 := &failingProvider{}
+	cloudflare := &failingProvider{failing: true}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(readyZone("route53"), readyZone("cloudflare"), dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			if managedZone.Name == "cloudflare" {
+				return cloudflare, nil
+			}
+			return route53, nil
+		},
+	}
+	getRecord := func() *v1alpha1.DNSRecord {
+		updated := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+			t.Fatalf("failed to get dns record %s", err)
+		}
+		return updated
+	}
+	zoneReady := func(record *v1alpha1.DNSRecord, zoneName string) *metav1.Condition {
+		for _, zoneStatus := range record.Status.ManagedZones {
+			if zoneStatus.Name == zoneName {
+				return meta.FindStatusCondition(zoneStatus.Conditions, "Ready")
+			}
+		}
+		return nil
+	}
+
+	// the failing provider does not prevent the record from being published by the other
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err == nil {
+		t.Fatalf("expected the failure of the provider to be returned")
+	}
+	if route53.ensureCalls != 1 {
+		t.Errorf("expected the record to be published to the zone of the other provider, got %d ensure calls", route53.ensureCalls)
+	}
+	updated := getRecord()
+	if cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready"); cond == nil || cond.Status != metav1.ConditionFalse ||
+		cond.Reason != "ProviderError" || cond.Message != "managed zone cloudflare: The DNS provider failed to ensure the record: provider unavailable" {
+		t.Errorf("expected the record not to be ready because of the failing provider, got %+v", cond)
+	}
+	if cond := zoneReady(updated, "route53"); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected the record to be ready in the route53 zone, got %+v", cond)
+	}
+	if cond := zoneReady(updated, "cloudflare"); cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ProviderError" {
+		t.Errorf("expected the record not to be ready in the cloudflare zone, got %+v", cond)
+	}
+	if len(updated.Status.PublishedEndpoints) != 1 || len(updated.Status.Endpoints) != 0 {
+		t.Errorf("expected the endpoints to be published only to the route53 zone, got %v published and %v endpoints",
+			updated.Status.PublishedEndpoints, updated.Status.Endpoints)
+	}
+
+	// once the provider recovers the record is only published to its zone
+	cloudflare.failing = false
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if route53.ensureCalls != 1 || cloudflare.ensureCalls != 1 {
+		t.Errorf("expected the record to be published once to each zone, got %d and %d ensure calls", route53.ensureCalls, cloudflare.ensureCalls)
+	}
+	updated = getRecord()
+	if cond := meta.FindStatusCondition(updated.Status.Conditions, "Ready"); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected the record to be ready, got %+v", cond)
+	}
+	if cond := zoneReady(updated, "cloudflare"); cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Errorf("expected the record to be ready in the cloudflare zone, got %+v", cond)
+	}
+	if len(updated.Status.Endpoints) != 1 || updated.Status.ObservedGeneration != 1 {
+		t.Errorf("expected the endpoints to be published, got %v at generation %d", updated.Status.Endpoints, updated.Status.ObservedGeneration)
+	}
+
+	// the record is deleted from every zone
+	if err := f.Delete(context.TODO(), updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if route53.deleteCalls != 1 || cloudflare.deleteCalls != 1 {
+		t.Errorf("expected the record to be deleted from each zone, got %d and %d delete calls", route53.deleteCalls, cloudflare.deleteCalls)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_removedManagedZone(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloudflare", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	primaryZone := managedZone.DeepCopy()
+	primaryZone.Name = "route53"
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 2,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "route53"},
+			Endpoints:      []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"}},
+		},
+		Status: v1alpha1.DNSRecordStatus{
+			ObservedGeneration: 1,
+			ManagedZones: []v1alpha1.DNSRecordZoneStatus{
+				{Name: "route53", ObservedGeneration: 1},
+				{Name: "cloudflare", ObservedGeneration: 1},
+			},
+		},
+	}
+
+	route53 := &mutationRecordingProvider{}
+	cloudflare := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(primaryZone, managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			if managedZone.Name == "cloudflare" {
+				return cloudflare, nil
+			}
+			return route53, nil
+		},
+	}
+
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if route53.ensureCalls != 1 || cloudflare.ensureCalls != 0 || cloudflare.deleteCalls != 1 {
+		t.Errorf("expected the record to be deleted from the removed zone, got %d and %d ensure calls and %d delete calls",
+			route53.ensureCalls, cloudflare.ensureCalls, cloudflare.deleteCalls)
+	}
+	updated := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+		t.Fatalf("failed to get dns record %s", err)
+	}
+	if len(updated.Status.ManagedZones) != 1 || updated.Status.ManagedZones[0].Name != "route53" {
+		t.Errorf("expected only the status of the route53 zone, got %+v", updated.Status.ManagedZones)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_forceSync(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "example.com"},
+			Endpoints:      []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"}},
+		},
+	}
+
+	provider := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+	reconcile := func() *v1alpha1.DNSRecord {
+		if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		updated := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+			t.Fatalf("failed to get dns record %s", err)
+		}
+		return updated
+	}
+	setForceSync := func(record *v1alpha1.DNSRecord, value string) {
+		record.Annotations = map[string]string{metadata.ForceSyncAnnotation: value}
+		if err := f.Update(context.TODO(), record); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	// the record already published at its generation is not published again
+	reconcile()
+	updated := reconcile()
+	if provider.ensureCalls != 1 {
+		t.Fatalf("expected 1 ensure call, got %d", provider.ensureCalls)
+	}
+
+	testCases := []struct {
+		value     string
+		wantCalls int
+	}{
+		{value: "2023-10-14T10:00:00Z", wantCalls: 2},
+		{value: "2023-10-14T10:00:00Z", wantCalls: 2},
+		{value: "2023-10-14T11:00:00Z", wantCalls: 3},
+	}
+	for _, testCase := range testCases {
+		setForceSync(updated, testCase.value)
+		updated = reconcile()
+		if provider.ensureCalls != testCase.wantCalls {
+			t.Errorf("expected %d ensure calls with force sync %s, got %d", testCase.wantCalls, testCase.value, provider.ensureCalls)
+		}
+		if updated.Status.ObservedForceSync != testCase.value {
+			t.Errorf("expected the force sync %s to be observed, got %q", testCase.value, updated.Status.ObservedForceSync)
+		}
+	}
+
+	// the observed value does not force the following reconciles
+	reconcile()
+	if provider.ensureCalls != 3 {
+		t.Errorf("expected no ensure call once the force sync is observed, got %d", provider.ensureCalls)
+	}
+}
+
+// zoneProvider holds the records of a single zone, so that the tests can change them behind the controller
+type zoneProvider struct {
+	dns.FakeProvider
+	ensureCalls int
+	records     map[string]*v1alpha1.Endpoint
+}
+
+func (p *zoneProvider) Ensure(record *v1alpha1.DNSRecord, _ *v1alpha1.ManagedZone) error {
+	p.ensureCalls++
+	for _, endpoint := range record.Spec.Endpoints {
+		p.records[endpoint.DNSName] = endpoint
+	}
+	return nil
+}
+
+func TestDNSRecordReconciler_Reconcile_resyncPeriod(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, 10, 14, 12, 0, 0, 0, time.UTC))
+	Clock = fakeClock
+	defer func() { Clock = clock.RealClock{} }()
+
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "example.com"},
+			Endpoints:      []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"}},
+		},
+	}
+
+	provider := &zoneProvider{records: map[string]*v1alpha1.Endpoint{}}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+		ReconcilerOptions: controller.ReconcilerOptions{ResyncPeriod: 5 * time.Minute},
+	}
+	reconcile := func() {
+		t.Helper()
+		if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	reconcile()
+	if provider.ensureCalls != 1 || provider.records["test.example.com"] == nil {
+		t.Fatalf("expected the record to be published, got %d ensure calls and records %v", provider.ensureCalls, provider.records)
+	}
+
+	// the record is deleted in the provider, out of band
+	delete(provider.records, "test.example.com")
+
+	// the unchanged record is not published again within the resync period
+	fakeClock.Step(time.Minute)
+	reconcile()
+	if provider.ensureCalls != 1 {
+		t.Errorf("expected no ensure call within the resync period, got %d", provider.ensureCalls-1)
+	}
+
+	// the resync once the period has passed publishes the record again, restoring it in the provider
+	fakeClock.Step(4 * time.Minute)
+	reconcile()
+	if provider.ensureCalls != 2 {
+		t.Fatalf("expected the record to be published again by the resync, got %d ensure calls", provider.ensureCalls)
+	}
+	if provider.records["test.example.com"] == nil {
+		t.Errorf("expected the record deleted in the provider to be restored")
+	}
+	updated := &v1alpha1.DNSRecord{}
+	if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+		t.Fatalf("failed to get dns record %s", err)
+	}
+	if published := updated.Status.ManagedZones[0].LastPublishedTime; published == nil || !published.Time.Equal(fakeClock.Now()) {
+		t.Errorf("expected the zone to be last published at %s, got %v", fakeClock.Now(), published)
+	}
+
+	// the period starts again from the resync
+	fakeClock.Step(time.Minute)
+	reconcile()
+	if provider.ensureCalls != 2 {
+		t.Errorf("expected no ensure call within the resync period after the resync, got %d", provider.ensureCalls-2)
+	}
+}
+
+// recordingChangeSink records the change events it is sent, failing with err if set
+type recordingChangeSink struct {
+	events []dns.ChangeEvent
+	err    error
+}
+
+func (s *recordingChangeSink) Send(_ context.Context, event dns.ChangeEvent) error {
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func TestDNSRecordReconciler_Reconcile_changeSink(t *testing.T) {
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{
+			ID:         "Z123",
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "example.com"},
+			Endpoints:      []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60}},
+		},
+	}
+
+	sink := &recordingChangeSink{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return &mutationRecordingProvider{}, nil
+		},
+		ChangeSink: sink,
+	}
+	reconcile := func() *v1alpha1.DNSRecord {
+		t.Helper()
+		if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		updated := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); client.IgnoreNotFound(err) != nil {
+			t.Fatalf("failed to get dns record %s", err)
+		}
+		return updated
+	}
+
+	// publishing the record sends the created record sets
+	updated := reconcile()
+	reconcile()
+	wantEvent := dns.ChangeEvent{
+		Zone:        "example.com",
+		ZoneID:      "Z123",
+		ManagedZone: dns.ObjectReference{Namespace: "test", Name: "example.com"},
+		DNSRecord:   dns.ObjectReference{Namespace: "test", Name: "test.example.com"},
+		Changes: []dns.RecordChange{
+			{Action: dns.PlanActionCreate, Name: "test.example.com", Type: "A", NewValues: []string{"1.1.1.1"}, NewTTL: 60},
+		},
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 change event once the record is published, got %+v", sink.events)
+	}
+	// the time is set by the clock of the reconciler
+	wantEvent.Time = sink.events[0].Time
+	if !reflect.DeepEqual(sink.events[0], wantEvent) {
+		t.Errorf("expected change event %+v, got %+v", wantEvent, sink.events[0])
+	}
+
+	// failing to send the changes does not fail publishing the record
+	sink.err = fmt.Errorf("connection refused")
+	updated.Generation = 2
+	updated.Spec.Endpoints[0].Targets = []string{"2.2.2.2"}
+	if err := f.Update(context.TODO(), updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	updated = reconcile()
+	if updated.Status.ObservedGeneration != 2 {
+		t.Errorf("expected the record to be published despite the change sink failing, got status %+v", updated.Status)
+	}
+	wantChanges := []dns.RecordChange{
+		{Action: dns.PlanActionUpdate, Name: "test.example.com", Type: "A", OldValues: []string{"1.1.1.1"}, OldTTL: 60, NewValues: []string{"2.2.2.2"}, NewTTL: 60},
+	}
+	if len(sink.events) != 2 || !reflect.DeepEqual(sink.events[1].Changes, wantChanges) {
+		t.Errorf("expected the updated record set to be sent, got %+v", sink.events)
+	}
+
+	// deleting the record sends the deleted record sets
+	sink.err = nil
+	if err := f.Delete(context.TODO(), updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	reconcile()
+	wantChanges = []dns.RecordChange{
+		{Action: dns.PlanActionDelete, Name: "test.example.com", Type: "A", OldValues: []string{"2.2.2.2"}, OldTTL: 60},
+	}
+	if len(sink.events) != 3 || !reflect.DeepEqual(sink.events[2].Changes, wantChanges) {
+		t.Errorf("expected the deleted record set to be sent, got %+v", sink.events)
+	}
+}
+
+func TestDNSRecordReconciler_Reconcile_maintenanceWindow(t *testing.T) {
+	fakeClock := clocktesting.NewFakeClock(time.Date(2023, 12, 24, 12, 0, 0, 0, time.UTC))
+	Clock = fakeClock
+	defer func() { Clock = clock.RealClock{} }()
+
+	managedZone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "example.com",
+			Namespace:   "test",
+			Annotations: map[string]string{metadata.MaintenanceWindowAnnotation: "2023-12-22T18:00:00Z/2023-12-24T13:00:00Z"},
+		},
+		Spec: v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+		},
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test.example.com",
+			Namespace:  "test",
+			Generation: 1,
+			Finalizers: []string{DNSRecordFinalizer},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: "example.com"},
+			Endpoints:      []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"}},
+		},
+	}
+
+	provider := &mutationRecordingProvider{}
+	f := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(managedZone, dnsRecord).Build()
+	r := &DNSRecordReconciler{
+		Client: f,
+		Scheme: testScheme(t),
+		DNSProvider: func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+			return provider, nil
+		},
+	}
+	reconcile := func() (ctrl.Result, *v1alpha1.DNSRecord) {
+		result, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		updated := &v1alpha1.DNSRecord{}
+		if err := f.Get(context.TODO(), client.ObjectKeyFromObject(dnsRecord), updated); err != nil {
+			t.Fatalf("failed to get dns record %s", err)
+		}
+		return result, updated
+	}
+
+	// the provider is not changed during the window, and the record is reconciled again once it is over
+	result, updated := reconcile()
+	if provider.ensureCalls != 0 {
+		t.Fatalf("expected no ensure call during the maintenance window, got %d", provider.ensureCalls)
+	}
+	if result.RequeueAfter != time.Hour+time.Second {
+		t.Errorf("expected the record to be requeued at the end of the window, got %v", result.RequeueAfter)
+	}
+	if condition := meta.FindStatusCondition(updated.Status.Conditions, "MaintenanceWindow"); condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("expected the MaintenanceWindow condition to be true, got %v", condition)
+	}
+	if condition := meta.FindStatusCondition(updated.Status.Conditions, "Ready"); condition == nil || condition.Reason != "MaintenanceWindow" {
+		t.Errorf("expected the record not to be ready during the maintenance window, got %v", condition)
+	}
+
+	// the desired endpoints keep changing during the window
+	updated.Generation = 2
+	updated.Spec.Endpoints = []*v1alpha1.Endpoint{{DNSName: "test.example.com", Targets: []string{"2.2.2.2"}, RecordType: "A"}}
+	if err := f.Update(context.TODO(), updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	reconcile()
+	if provider.ensureCalls != 0 {
+		t.Fatalf("expected no ensure call during the maintenance window, got %d", provider.ensureCalls)
+	}
+
+	// the endpoints desired by the end of the window are published
+	fakeClock.Step(time.Hour)
+	_, updated = reconcile()
+	if provider.ensureCalls != 1 {
+		t.Fatalf("expected the record to be published after the maintenance window, got %d ensure calls", provider.ensureCalls)
+	}
+	if got := provider.ensuredEndpoints[0].Targets; !reflect.DeepEqual(got, v1alpha1.Targets{"2.2.2.2"}) {
+		t.Errorf("expected the latest endpoints to be published, got %v", got)
+	}
+	if condition := meta.FindStatusCondition(updated.Status.Conditions, "MaintenanceWindow"); condition != nil {
+		t.Errorf("expected the MaintenanceWindow condition to be removed, got %v", condition)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, "Ready") {
+		t.Errorf("expected the record to be ready, got %v", updated.Status.Conditions)
+	}
+
+	// the deletion of the record from the provider waits for the window of the record as well
+	updated.Annotations = map[string]string{metadata.MaintenanceWindowAnnotation: "2023-12-24T13:00:00Z/2023-12-24T14:00:00Z"}
+	if err := f.Update(context.TODO(), updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := f.Delete(context.TODO(), updated); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result, _ = reconcile(); provider.deleteCalls != 0 || result.RequeueAfter != time.Hour+time.Second {
+		t.Fatalf("expected the deletion to wait for the end of the window, got %d delete calls and result %v", provider.deleteCalls, result)
+	}
+	fakeClock.Step(time.Hour)
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(dnsRecord)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if provider.deleteCalls != 1 {
+		t.Errorf("expected the record to be deleted after the maintenance window, got %d delete calls", provider.deleteCalls)
+	}
+}
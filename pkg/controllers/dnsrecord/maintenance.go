@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsrecord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+var errInvalidMaintenanceWindow = errors.New("invalid maintenance window")
+
+// maintenanceWindowError is returned instead of changing a managed zone while a maintenance window is active
+type maintenanceWindowError struct {
+	zone string
+	// source is the kind and name of the object the window is set on
+	source string
+	end    time.Time
+}
+
+func (e *maintenanceWindowError) Error() string {
+	return fmt.Sprintf("the changes to managed zone %s are frozen by the maintenance window of %s until %s", e.zone, e.source, e.end.UTC().Format(time.RFC3339))
+}
+
+// checkMaintenanceWindow returns a maintenanceWindowError if a maintenance window of the record, or of the managed
+// zone, is active, the one ending last if both are. An invalid window is an error as well, so that a mistyped
+// annotation does not let the changes through during a change freeze.
+func checkMaintenanceWindow(dnsRecord *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
+	now := Clock.Now()
+	var active *maintenanceWindowError
+	for _, object := range []struct {
+		source string
+		obj    metav1.Object
+	}{
+		{source: "DNSRecord " + dnsRecord.Name, obj: dnsRecord},
+		{source: "ManagedZone " + managedZone.Name, obj: managedZone},
+	} {
+		source := object.source
+		start, end, ok, err := metadata.GetMaintenanceWindow(object.obj)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%w on %s: %v", errInvalidMaintenanceWindow, source, err)
+		}
+		if now.Before(start) || !now.Before(end) {
+			continue
+		}
+		if active == nil || end.After(active.end) {
+			active = &maintenanceWindowError{zone: managedZone.Name, source: source, end: end}
+		}
+	}
+	if active == nil {
+		return nil
+	}
+	return active
+}
+
+// requeueAfterWindows returns the delay until the end of the first of the maintenance windows, or requeueAfter if it
+// is set and shorter
+func requeueAfterWindows(requeueAfter time.Duration, windows []*maintenanceWindowError) time.Duration {
+	for _, window := range windows {
+		// the window is checked again right after its end
+		untilEnd := window.end.Sub(Clock.Now()) + time.Second
+		if requeueAfter == 0 || untilEnd < requeueAfter {
+			requeueAfter = untilEnd
+		}
+	}
+	return requeueAfter
+}
+
+// setMaintenanceWindowCondition sets the MaintenanceWindow condition of the record while the changes to some of its
+// managed zones are frozen, and removes it once they are published
+func setMaintenanceWindowCondition(dnsRecord *v1alpha1.DNSRecord, windows []*maintenanceWindowError) {
+	if len(windows) == 0 {
+		meta.RemoveStatusCondition(&dnsRecord.Status.Conditions, string(conditions.ConditionTypeMaintenanceWindow))
+		return
+	}
+	messages := make([]string, 0, len(windows))
+	for _, window := range windows {
+		messages = append(messages, window.Error())
+	}
+	setDNSRecordCondition(dnsRecord, string(conditions.ConditionTypeMaintenanceWindow), metav1.ConditionTrue, "Active",
+		strings.Join(messages, "; "))
+}
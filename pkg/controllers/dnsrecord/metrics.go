@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnsrecord
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	operationLabel = "operation"
+	resultLabel    = "result"
+
+	operationEnsure = "ensure"
+	operationDelete = "delete"
+
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+var (
+	// providerRequestTotal is a prometheus counter metric which holds the total
+	// number of DNS provider calls made by the DNSRecord controller.
+	providerRequestTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mgc_dns_record_provider_requests_total",
+			Help: "MGC DNSRecord total number of DNS provider requests",
+		},
+		[]string{operationLabel, resultLabel},
+	)
+
+	// reconcileDuration is a prometheus metric which records the duration
+	// of DNSRecord reconciles.
+	reconcileDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "mgc_dns_record_reconcile_duration_seconds",
+			Help:    "MGC DNSRecord reconcile duration",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+
+	// publishedEndpoints is a prometheus metric which holds the number of
+	// endpoints currently published for each DNSRecord.
+	publishedEndpoints = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mgc_dns_record_published_endpoints",
+			Help: "MGC DNSRecord number of endpoints published by the DNS provider",
+		},
+		[]string{"dns_record_name", "dns_record_namespace"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		providerRequestTotal,
+		reconcileDuration,
+		publishedEndpoints,
+	)
+
+	for _, operation := range []string{operationEnsure, operationDelete} {
+		providerRequestTotal.WithLabelValues(operation, resultSuccess).Add(0)
+		providerRequestTotal.WithLabelValues(operation, resultError).Add(0)
+	}
+}
+
+func observeProviderRequest(operation string, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	providerRequestTotal.WithLabelValues(operation, result).Inc()
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+)
+
+// HTTPRouteEventMapper is an EventHandler that maps HTTPRoute object events to policy events.
+type HTTPRouteEventMapper struct {
+	Logger                  logr.Logger
+	PolicyKind              string
+	PolicyBackRefAnnotation string
+}
+
+func NewHTTPRouteEventMapper(logger logr.Logger, policyBackRefAnnotation, policyKind string) *HTTPRouteEventMapper {
+	return &HTTPRouteEventMapper{
+		Logger:                  logger.WithName("HTTPRouteEventMapper"),
+		PolicyKind:              policyKind,
+		PolicyBackRefAnnotation: policyBackRefAnnotation,
+	}
+}
+
+func (m *HTTPRouteEventMapper) MapToPolicy(obj client.Object) []reconcile.Request {
+	return m.mapToPolicyRequest(obj, m.PolicyBackRefAnnotation, m.PolicyKind)
+}
+
+func (m *HTTPRouteEventMapper) mapToPolicyRequest(obj client.Object, policyBackRefAnnotation, policyKind string) []reconcile.Request {
+	logger := m.Logger.V(1).WithValues("object", client.ObjectKeyFromObject(obj))
+
+	route, ok := obj.(*gatewayapiv1beta1.HTTPRoute)
+	if !ok {
+		logger.Info("mapToPolicyRequest:", "error", fmt.Sprintf("%T is not a *gatewayapiv1beta1.HTTPRoute", obj))
+		return []reconcile.Request{}
+	}
+
+	requests := make([]reconcile.Request, 0)
+
+	// the back reference is in the form namespace/name
+	policyRef := metadata.GetAnnotation(route, policyBackRefAnnotation)
+	policyNamespace, policyName, found := strings.Cut(policyRef, string(types.Separator))
+	if !found || policyName == "" {
+		return requests
+	}
+	logger.Info("mapToPolicyRequest", policyKind, policyRef)
+	requests = append(requests, reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      policyName,
+			Namespace: policyNamespace,
+		}})
+
+	return requests
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+)
+
+const (
+	// GatewayClusterStatusAnnotation holds the sync status of the gateway in each cluster it is placed on. The gateway
+	// status has no field for it, so it is kept with the gateway clusters annotation.
+	GatewayClusterStatusAnnotation = "kuadrant.io/gateway-cluster-status"
+
+	GatewayReasonClustersNotSynced gatewayv1beta1.GatewayConditionReason = "ClustersNotSynced"
+)
+
+// ClusterSyncStatus is the sync status of the gateway in a cluster it is placed on
+type ClusterSyncStatus struct {
+	Cluster string `json:"cluster"`
+	// Synced is true when the cluster has applied the gateway and reports it as programmed
+	Synced bool `json:"synced"`
+	// LastSyncTime is the time the cluster last became synced
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// getClusterSyncStatuses returns the sync status of the gateway in each of the clusters. The last sync time of a
+// cluster that is no longer synced is kept from the previous status.
+func (r *GatewayReconciler) getClusterSyncStatuses(ctx context.Context, gateway *gatewayv1beta1.Gateway, clusters []string) ([]ClusterSyncStatus, error) {
+	previous := map[string]ClusterSyncStatus{}
+	if val := metadata.GetAnnotation(gateway, GatewayClusterStatusAnnotation); val != "" {
+		var statuses []ClusterSyncStatus
+		if err := json.Unmarshal([]byte(val), &statuses); err == nil {
+			for _, status := range statuses {
+				previous[status.Cluster] = status
+			}
+		}
+	}
+
+	statuses := []ClusterSyncStatus{}
+	for _, cluster := range clusters {
+		synced, lastSyncTime, err := r.Placement.GetSyncStatus(ctx, gateway, cluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get sync status for cluster %s : %w", cluster, err)
+		}
+		if !synced {
+			lastSyncTime = previous[cluster].LastSyncTime
+		}
+		statuses = append(statuses, ClusterSyncStatus{
+			Cluster:      cluster,
+			Synced:       synced,
+			LastSyncTime: lastSyncTime,
+		})
+	}
+	return statuses, nil
+}
+
+// buildReadyCondition builds a condition that is only true once the gateway is placed on all the targeted clusters and
+// every one of them reports it as synced
+func buildReadyCondition(generation int64, programmedStatus metav1.ConditionStatus, statuses []ClusterSyncStatus) metav1.Condition {
+	notSynced := []string{}
+	for _, status := range statuses {
+		if !status.Synced {
+			notSynced = append(notSynced, status.Cluster)
+		}
+	}
+
+	cond := metav1.Condition{
+		Type:               string(gatewayv1beta1.GatewayConditionReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1beta1.GatewayReasonReady),
+		Message:            fmt.Sprintf("gateway synced on all %d clusters", len(statuses)),
+		ObservedGeneration: generation,
+	}
+	switch {
+	case programmedStatus != metav1.ConditionTrue || len(statuses) == 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(GatewayReasonClustersNotSynced)
+		cond.Message = "waiting for gateway to be placed on all clusters"
+	case len(notSynced) > 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(GatewayReasonClustersNotSynced)
+		cond.Message = fmt.Sprintf("waiting for clusters %v to sync the gateway", notSynced)
+	}
+	return cond
+}
+
+// ClusterListenerStatusName returns the name of the status of a listener of the gateway in a cluster, the upstream
+// gateway has a listener status for each listener in each cluster it is placed on
+func ClusterListenerStatusName(cluster, listenerName string) gatewayv1beta1.SectionName {
+	return gatewayv1beta1.SectionName(fmt.Sprintf("%s.%s", cluster, listenerName))
+}
+
+// clusterListenerConditions returns a copy of the conditions of the named listener status of the gateway, so that
+// the transition times of the conditions are kept across reconciles
+func clusterListenerConditions(gateway *gatewayv1beta1.Gateway, name gatewayv1beta1.SectionName) []metav1.Condition {
+	for _, status := range gateway.Status.Listeners {
+		if status.Name == name {
+			return append([]metav1.Condition{}, status.Conditions...)
+		}
+	}
+	return []metav1.Condition{}
+}
+
+// buildListenerProgrammedCondition builds the Programmed condition of a listener in a cluster from the status
+// reported by the downstream gateway
+func buildListenerProgrammedCondition(generation int64, cluster, listenerName string, programmed metav1.ConditionStatus) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               string(gatewayv1beta1.ListenerConditionProgrammed),
+		Status:             programmed,
+		Reason:             string(gatewayv1beta1.ListenerReasonProgrammed),
+		Message:            fmt.Sprintf("listener %s is programmed in cluster %s", listenerName, cluster),
+		ObservedGeneration: generation,
+	}
+	if programmed != metav1.ConditionTrue {
+		cond.Reason = string(gatewayv1beta1.ListenerReasonPending)
+		cond.Message = fmt.Sprintf("listener %s is not programmed in cluster %s", listenerName, cluster)
+	}
+	return cond
+}
@@ -0,0 +1,181 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestGatewayReconciler_getClusterSyncStatuses(t *testing.T) {
+	scheme := testutil.GetValidTestScheme()
+	if err := workv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme %s", err)
+	}
+	syncedAt := v1.NewTime(time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC))
+	gateway := &gatewayv1beta1.Gateway{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1beta1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      testutil.DummyCRName,
+			Namespace: testutil.Namespace,
+		},
+	}
+	// manifestWork simulates the status reported back by the work agent of a cluster
+	manifestWork := func(cluster string, programmed string) *workv1.ManifestWork {
+		return &workv1.ManifestWork{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      placement.WorkName(gateway),
+				Namespace: cluster,
+			},
+			Status: workv1.ManifestWorkStatus{
+				Conditions: []v1.Condition{
+					{Type: workv1.WorkApplied, Status: v1.ConditionTrue, LastTransitionTime: syncedAt},
+					{Type: workv1.WorkAvailable, Status: v1.ConditionTrue, LastTransitionTime: syncedAt},
+				},
+				ResourceStatus: workv1.ManifestResourceStatus{
+					Manifests: []workv1.ManifestCondition{
+						{
+							ResourceMeta: workv1.ManifestResourceMeta{
+								Group: "gateway.networking.k8s.io",
+								Name:  testutil.DummyCRName,
+							},
+							StatusFeedbacks: workv1.StatusFeedbackResult{
+								Values: []workv1.FeedbackValue{
+									{
+										Name:  "programmed",
+										Value: workv1.FieldValue{Type: workv1.String, String: &programmed},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(manifestWork("cluster-1", "True"), manifestWork("cluster-2", "False")).
+		Build()
+	r := &GatewayReconciler{
+		Client:    c,
+		Scheme:    scheme,
+		Placement: placement.NewOCMPlacer(c),
+	}
+	clusters := []string{"cluster-1", "cluster-2"}
+
+	statuses, err := r.getClusterSyncStatuses(context.TODO(), gateway, clusters)
+	if err != nil {
+		t.Fatalf("did not expect an error but got one %s", err)
+	}
+	if len(statuses) != 2 || !statuses[0].Synced || statuses[1].Synced {
+		t.Fatalf("expected only cluster-1 to be synced, got %+v", statuses)
+	}
+	if statuses[0].LastSyncTime == nil || !statuses[0].LastSyncTime.Equal(&syncedAt) {
+		t.Errorf("expected cluster-1 last sync time %v, got %v", syncedAt, statuses[0].LastSyncTime)
+	}
+	cond := buildReadyCondition(1, v1.ConditionTrue, statuses)
+	if cond.Status != v1.ConditionFalse || cond.Reason != string(GatewayReasonClustersNotSynced) {
+		t.Errorf("expected the ready condition to be false while cluster-2 is not synced, got %+v", cond)
+	}
+
+	// cluster-1 stops reporting the gateway as programmed and cluster-2 catches up
+	serialized, _ := json.Marshal(statuses)
+	gateway.Annotations = map[string]string{GatewayClusterStatusAnnotation: string(serialized)}
+	for cluster, programmed := range map[string]string{"cluster-1": "False", "cluster-2": "True"} {
+		mw := &workv1.ManifestWork{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Name: placement.WorkName(gateway), Namespace: cluster}, mw); err != nil {
+			t.Fatalf("failed to get manifest work %s", err)
+		}
+		mw.Status = manifestWork(cluster, programmed).Status
+		if err := c.Update(context.TODO(), mw); err != nil {
+			t.Fatalf("failed to update manifest work %s", err)
+		}
+	}
+
+	statuses, err = r.getClusterSyncStatuses(context.TODO(), gateway, clusters)
+	if err != nil {
+		t.Fatalf("did not expect an error but got one %s", err)
+	}
+	if statuses[0].Synced || !statuses[1].Synced {
+		t.Fatalf("expected only cluster-2 to be synced, got %+v", statuses)
+	}
+	if statuses[0].LastSyncTime == nil || !statuses[0].LastSyncTime.Equal(&syncedAt) {
+		t.Errorf("expected cluster-1 to keep its last sync time %v, got %v", syncedAt, statuses[0].LastSyncTime)
+	}
+
+	// both clusters synced
+	mw := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: placement.WorkName(gateway), Namespace: "cluster-1"}, mw); err != nil {
+		t.Fatalf("failed to get manifest work %s", err)
+	}
+	mw.Status = manifestWork("cluster-1", "True").Status
+	if err := c.Update(context.TODO(), mw); err != nil {
+		t.Fatalf("failed to update manifest work %s", err)
+	}
+	statuses, err = r.getClusterSyncStatuses(context.TODO(), gateway, clusters)
+	if err != nil {
+		t.Fatalf("did not expect an error but got one %s", err)
+	}
+	if cond := buildReadyCondition(1, v1.ConditionTrue, statuses); cond.Status != v1.ConditionTrue {
+		t.Errorf("expected the ready condition to be true once all clusters are synced, got %+v", cond)
+	}
+}
+
+func Test_buildReadyCondition(t *testing.T) {
+	synced := []ClusterSyncStatus{{Cluster: "cluster-1", Synced: true}, {Cluster: "cluster-2", Synced: true}}
+	testCases := []struct {
+		name             string
+		programmedStatus v1.ConditionStatus
+		statuses         []ClusterSyncStatus
+		wantStatus       v1.ConditionStatus
+	}{
+		{
+			name:             "all clusters synced",
+			programmedStatus: v1.ConditionTrue,
+			statuses:         synced,
+			wantStatus:       v1.ConditionTrue,
+		},
+		{
+			name:             "a cluster is not synced",
+			programmedStatus: v1.ConditionTrue,
+			statuses:         []ClusterSyncStatus{{Cluster: "cluster-1", Synced: true}, {Cluster: "cluster-2"}},
+			wantStatus:       v1.ConditionFalse,
+		},
+		{
+			name:             "not placed on all targeted clusters",
+			programmedStatus: v1.ConditionUnknown,
+			statuses:         synced,
+			wantStatus:       v1.ConditionFalse,
+		},
+		{
+			name:             "not placed on any cluster",
+			programmedStatus: v1.ConditionTrue,
+			wantStatus:       v1.ConditionFalse,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cond := buildReadyCondition(1, testCase.programmedStatus, testCase.statuses)
+			if cond.Type != string(gatewayv1beta1.GatewayConditionReady) || cond.Status != testCase.wantStatus || cond.ObservedGeneration != 1 {
+				t.Errorf("buildReadyCondition() = %+v, want status %s", cond, testCase.wantStatus)
+			}
+		})
+	}
+}
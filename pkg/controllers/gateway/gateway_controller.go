@@ -45,12 +45,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/gracePeriod"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/policy"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 )
 
 const (
@@ -72,10 +74,15 @@ type GatewayPlacer interface {
 	GetClusters(ctx context.Context, gateway *gatewayv1beta1.Gateway) (sets.Set[string], error)
 	// ListenerTotalAttachedRoutes returns the total attached routes for a listener from the downstream gateways
 	ListenerTotalAttachedRoutes(ctx context.Context, gateway *gatewayv1beta1.Gateway, listenerName string, downstream string) (int, error)
+	// ListenerProgrammed returns the status of the Programmed condition of a listener of the downstream gateway, empty
+	// when the downstream gateway does not report it
+	ListenerProgrammed(ctx context.Context, gateway *gatewayv1beta1.Gateway, listenerName string, downstream string) (metav1.ConditionStatus, error)
 	// GetAddresses will look at the downstream view of the gateway and return the LB addresses used for these gateways
 	GetAddresses(ctx context.Context, gateway *gatewayv1beta1.Gateway, downstream string) ([]gatewayv1beta1.GatewayAddress, error)
 	// GetClusterGateway
 	GetClusterGateway(ctx context.Context, gateway *gatewayv1beta1.Gateway, clusterName string) (dns.ClusterGateway, error)
+	// GetSyncStatus returns whether the downstream cluster has synced the gateway and the time it last became synced
+	GetSyncStatus(ctx context.Context, gateway *gatewayv1beta1.Gateway, downstream string) (bool, *metav1.Time, error)
 }
 
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch;create;update;patch;delete
@@ -91,6 +98,10 @@ type GatewayReconciler struct {
 	client.Client
 	Scheme    *runtime.Scheme
 	Placement GatewayPlacer
+	// TLSReadinessGate holds gateways back from being placed while the TLSPolicy controller reports their TLS
+	// certificates as not ready
+	TLSReadinessGate bool
+	controller.ReconcilerOptions
 }
 
 func isDeleting(g *gatewayv1beta1.Gateway) bool {
@@ -165,6 +176,21 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return reconcile.Result{}, r.Status().Update(ctx, upstreamGateway)
 	}
 
+	// placing the gateway before its certificates exist would serve HTTPS listeners without a certificate
+	if notReady := tlsCertificatesNotReady(upstreamGateway); r.TLSReadinessGate && notReady != nil {
+		log.V(3).Info("gateway TLS certificates not ready, not placing the gateway", "message", notReady.Message)
+		listenerStatuses, err := r.pendingListenerStatuses(ctx, upstreamGateway)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		upstreamGateway.Status.Listeners = listenerStatuses
+		meta.SetStatusCondition(&upstreamGateway.Status.Conditions, buildTLSPendingProgrammedCondition(upstreamGateway.Generation, notReady))
+		if !reflect.DeepEqual(upstreamGateway.Status, previous.Status) {
+			return reconcile.Result{}, r.Status().Update(ctx, upstreamGateway)
+		}
+		return ctrl.Result{}, nil
+	}
+
 	log.V(3).Info("gateway pre downstream", "labels", upstreamGateway.Labels)
 	requeue, programmedStatus, clusters, reconcileErr := r.reconcileDownstreamFromUpstreamGateway(ctx, upstreamGateway, params)
 	log.V(3).Info("gateway post downstream", "labels", upstreamGateway.Labels)
@@ -192,6 +218,19 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 	metadata.AddAnnotation(upstreamGateway, GatewayClustersAnnotation, string(serialized))
+	if err := reconcilePlacementDecision(upstreamGateway, clusters, params, metav1.Now()); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	clusterStatuses, err := r.getClusterSyncStatuses(ctx, upstreamGateway, clusters)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	serialized, err = json.Marshal(clusterStatuses)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	metadata.AddAnnotation(upstreamGateway, GatewayClusterStatusAnnotation, string(serialized))
 
 	if reconcileErr == nil && !reflect.DeepEqual(upstreamGateway, previous) {
 		log.Info("updating upstream gateway")
@@ -239,21 +278,51 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 				log.Info("AttachedRoutes unknown for listener. Ignoring", "listener", listener.Name, "cluster", cluster, "message", err)
 				continue
 			}
-			allListenerStatuses = append(allListenerStatuses, gatewayv1beta1.ListenerStatus{
-				Name:           gatewayv1beta1.SectionName(fmt.Sprintf("%s.%s", cluster, string(listener.Name))),
+			listenerStatus := gatewayv1beta1.ListenerStatus{
+				Name:           ClusterListenerStatusName(cluster, string(listener.Name)),
 				AttachedRoutes: int32(attachedRoutes),
 				SupportedKinds: []gatewayv1beta1.RouteGroupKind{},
 				Conditions:     []metav1.Condition{},
-			})
+			}
+			programmed, err := r.Placement.ListenerProgrammed(ctx, upstreamGateway, string(listener.Name), cluster)
+			if err != nil {
+				log.Info("Programmed status unknown for listener. Ignoring", "listener", listener.Name, "cluster", cluster, "message", err)
+			}
+			if programmed != "" {
+				listenerStatus.Conditions = clusterListenerConditions(previous, listenerStatus.Name)
+				meta.SetStatusCondition(&listenerStatus.Conditions, buildListenerProgrammedCondition(upstreamGateway.Generation, cluster, string(listener.Name), programmed))
+			}
+			allListenerStatuses = append(allListenerStatuses, listenerStatus)
 		}
 	}
 	upstreamGateway.Status.Listeners = allListenerStatuses
 
 	acceptedCondition := buildAcceptedCondition(upstreamGateway.Generation, metav1.ConditionTrue)
 	programmedCondition := buildProgrammedCondition(upstreamGateway.Generation, clusters, programmedStatus, err)
+	readyCondition := buildReadyCondition(upstreamGateway.Generation, programmedStatus, clusterStatuses)
 
 	meta.SetStatusCondition(&upstreamGateway.Status.Conditions, acceptedCondition)
 	meta.SetStatusCondition(&upstreamGateway.Status.Conditions, programmedCondition)
+	meta.SetStatusCondition(&upstreamGateway.Status.Conditions, readyCondition)
+
+	// pinned clusters override the placement strategy
+	pinned, isPinned := placement.GetPinnedClusters(upstreamGateway)
+	if isPinned {
+		known, unknown, err := placement.KnownClusters(ctx, r.Client, pinned)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		meta.SetStatusCondition(&upstreamGateway.Status.Conditions, buildClustersPinnedCondition(upstreamGateway.Generation, known, unknown))
+	} else {
+		meta.RemoveStatusCondition(&upstreamGateway.Status.Conditions, string(GatewayConditionClustersPinned))
+	}
+	if !isPinned && isCapacityPlacement(params) {
+		selected, selectErr := r.Placement.GetClusters(ctx, upstreamGateway)
+		clustersSelectedCondition := buildClustersSelectedCondition(upstreamGateway.Generation, params.Placement, sets.List(selected), selectErr)
+		meta.SetStatusCondition(&upstreamGateway.Status.Conditions, clustersSelectedCondition)
+	} else {
+		meta.RemoveStatusCondition(&upstreamGateway.Status.Conditions, string(GatewayConditionClustersSelected))
+	}
 
 	if !isDeleting(upstreamGateway) && !reflect.DeepEqual(upstreamGateway.Status, previous.Status) {
 		return reconcile.Result{}, r.Status().Update(ctx, upstreamGateway)
@@ -285,6 +354,11 @@ func (r *GatewayReconciler) reconcileDownstreamFromUpstreamGateway(ctx context.C
 		downstream.Labels = map[string]string{}
 	}
 	downstream.Labels[ManagedLabel] = "true"
+	// the sync status changes as the downstream gateways are reconciled, syncing it would cause another round of updates
+	delete(downstream.Annotations, GatewayClusterStatusAnnotation)
+	delete(downstream.Annotations, GatewayPlacementDecisionAnnotation)
+	delete(downstream.Annotations, placement.GatewayPlacementAnnotation)
+	delete(downstream.Annotations, placement.GatewayPinnedClustersAnnotation)
 	if isDeleting(upstreamGateway) {
 		log.Info("deleting downstream gateways owned by upstream gateway ", "name", downstream.Name, "namespace", downstream.Namespace)
 		targets, err := r.Placement.Place(ctx, upstreamGateway, downstream)
@@ -309,6 +383,9 @@ func (r *GatewayReconciler) reconcileDownstreamFromUpstreamGateway(ctx context.C
 		if err := r.reconcileParams(ctx, downstream, params); err != nil {
 			return false, metav1.ConditionUnknown, clusters, fmt.Errorf("failed to get reconcileParams : %s", err)
 		}
+		if err := reconcilePlacementParams(upstreamGateway, params); err != nil {
+			return false, metav1.ConditionUnknown, clusters, fmt.Errorf("failed to reconcile placement params : %s", err)
+		}
 	}
 
 	// ensure the gateways are placed into the right target clusters and removed from any that are no longer targeted
@@ -486,5 +563,6 @@ func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Conte
 			}
 			return true
 		})).
-		Complete(r)
+		WithOptions(r.ControllerOptions()).
+		Complete(r.WrapReconciler(r))
 }
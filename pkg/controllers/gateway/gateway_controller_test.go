@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -16,6 +17,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	fakeplacement "github.com/Kuadrant/multicluster-gateway-controller/pkg/placement/fake"
@@ -679,3 +681,68 @@ func buildTestDNSRecord() *v1alpha1.DNSRecordList {
 		},
 	}
 }
+
+func TestGatewayReconciler_Reconcile_tlsReadinessGate(t *testing.T) {
+	for _, gated := range []bool{true, false} {
+		upstream := &gatewayv1beta1.Gateway{
+			ObjectMeta: v1.ObjectMeta{
+				Name:       testutil.DummyCRName,
+				Namespace:  testutil.Namespace,
+				Labels:     getTestGatewayLabels(),
+				Finalizers: []string{GatewayFinalizer},
+			},
+			Spec: gatewayv1beta1.GatewaySpec{
+				GatewayClassName: testutil.DummyCRName,
+				Listeners: []gatewayv1beta1.Listener{
+					{
+						Name:     testutil.ValidTestHostname,
+						Hostname: testutil.Pointer(gatewayv1beta1.Hostname(testutil.ValidTestHostname)),
+						Protocol: gatewayv1beta1.HTTPSProtocolType,
+						TLS: &gatewayv1beta1.GatewayTLSConfig{
+							CertificateRefs: []gatewayv1beta1.SecretObjectReference{{Name: "missing-tls"}},
+						},
+					},
+				},
+			},
+			Status: gatewayv1beta1.GatewayStatus{
+				Conditions: []v1.Condition{
+					{Type: string(gatewayv1beta1.GatewayConditionAccepted), Status: v1.ConditionTrue, Reason: "Accepted"},
+					{Type: string(conditions.ConditionTypeTLSCertificatesReady), Status: v1.ConditionFalse, Reason: "CertificatesNotReady", Message: "waiting"},
+				},
+			},
+		}
+		c := testutil.GetValidTestClient(
+			&gatewayv1beta1.GatewayList{Items: []gatewayv1beta1.Gateway{*upstream}},
+			&gatewayv1beta1.GatewayClassList{Items: []gatewayv1beta1.GatewayClass{{ObjectMeta: v1.ObjectMeta{Name: testutil.DummyCRName}}}},
+		)
+		r := &GatewayReconciler{
+			Client:           c,
+			Scheme:           testutil.GetValidTestScheme(),
+			Placement:        fakeplacement.NewTestGatewayPlacer(),
+			TLSReadinessGate: gated,
+		}
+		if _, err := r.Reconcile(context.TODO(), testutil.BuildValidTestRequest(testutil.DummyCRName, testutil.Namespace)); err != nil && gated {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		updated := &gatewayv1beta1.Gateway{}
+		if err := c.Get(context.TODO(), client.ObjectKeyFromObject(upstream), updated); err != nil {
+			t.Fatalf("failed to get gateway %v", err)
+		}
+		programmed := meta.FindStatusCondition(updated.Status.Conditions, string(gatewayv1beta1.GatewayConditionProgrammed))
+		isPending := programmed != nil && programmed.Status == v1.ConditionFalse && programmed.Reason == string(GatewayReasonTLSCertificatesPending)
+		if isPending != gated {
+			t.Errorf("expected gateway held back %v by the readiness gate, got programmed condition %+v", gated, programmed)
+		}
+		if !gated {
+			continue
+		}
+		if len(updated.Status.Listeners) != 1 || updated.Status.Listeners[0].Name != gatewayv1beta1.SectionName(testutil.ValidTestHostname) {
+			t.Fatalf("expected a pending status for the TLS listener, got %+v", updated.Status.Listeners)
+		}
+		ready := meta.FindStatusCondition(updated.Status.Listeners[0].Conditions, string(gatewayv1beta1.ListenerConditionReady))
+		if ready == nil || ready.Status != v1.ConditionFalse || ready.Reason != string(gatewayv1beta1.ListenerReasonPending) {
+			t.Errorf("expected the listener not to be ready until its secret exists, got %+v", ready)
+		}
+	}
+}
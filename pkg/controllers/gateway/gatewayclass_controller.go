@@ -30,6 +30,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 )
 
@@ -45,6 +46,7 @@ func getSupportedClasses() []string {
 type GatewayClassReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	controller.ReconcilerOptions
 }
 
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses,verbs=get;list;watch;create;update;patch;delete
@@ -139,5 +141,5 @@ func (r *GatewayClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			gatewayClass := object.(*gatewayv1beta1.GatewayClass)
 			return gatewayClass.Spec.ControllerName == ControllerName
 		})).
-		Complete(r)
+		Complete(r.WrapReconciler(r))
 }
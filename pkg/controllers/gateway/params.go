@@ -11,12 +11,19 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 )
 
 type Params struct {
 	// DownstreamClass specifies what GatewayClassName to set in the
 	// downstream clusters. For example:
 	DownstreamClass string `json:"downstreamClass,omitempty"`
+
+	// Placement specifies the strategy selecting the clusters the gateways
+	// of the class are placed on. For example:
+	// {"type": "Capacity", "clusters": 2}
+	Placement *placement.Strategy `json:"placement,omitempty"`
 }
 
 func (p *Params) GetDownstreamClass() string {
@@ -82,6 +89,11 @@ func fromConfigMap(configMap *corev1.ConfigMap) (*Params, error) {
 	if err := json.Unmarshal([]byte(paramsRaw), result); err != nil {
 		return nil, &InvalidParamsError{fmt.Sprintf("Failed to unmarshal params: %v", err)}
 	}
+	if result.Placement != nil {
+		if err := result.Placement.Validate(); err != nil {
+			return nil, &InvalidParamsError{fmt.Sprintf("Invalid placement params: %v", err)}
+		}
+	}
 
 	return result, nil
 }
@@ -15,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
 )
 
@@ -79,6 +80,61 @@ func TestGetParams(t *testing.T) {
 				}),
 			),
 		},
+		{
+			name: "ConfigMap found with placement strategy",
+			gatewayClass: &gatewayv1beta1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: gatewayv1beta1.GatewayClassSpec{
+					ParametersRef: &gatewayv1beta1.ParametersReference{
+						Group:     "",
+						Kind:      "ConfigMap",
+						Name:      testutil.DummyCRName,
+						Namespace: testutil.Pointer(gatewayv1beta1.Namespace(testutil.Namespace)),
+					},
+				},
+			},
+			paramsObj: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Namespace,
+				},
+				Data: map[string]string{
+					"params": `{"downstreamClass": "istio", "placement": {"type": "Capacity", "clusters": 2}}`,
+				},
+			},
+			assertParams: and(
+				noError,
+				paramsEqual(Params{
+					DownstreamClass: "istio",
+					Placement:       &placement.Strategy{Type: placement.StrategyCapacity, Clusters: 2},
+				}),
+			),
+		},
+		{
+			name: "Unsupported placement strategy",
+			gatewayClass: &gatewayv1beta1.GatewayClass{
+				Spec: gatewayv1beta1.GatewayClassSpec{
+					ParametersRef: &gatewayv1beta1.ParametersReference{
+						Group:     "",
+						Kind:      "ConfigMap",
+						Name:      testutil.DummyCRName,
+						Namespace: testutil.Pointer(gatewayv1beta1.Namespace(testutil.Namespace)),
+					},
+				},
+			},
+			paramsObj: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Namespace,
+				},
+				Data: map[string]string{
+					"params": `{"downstreamClass": "istio", "placement": {"type": "RoundRobin"}}`,
+				},
+			},
+			assertParams: assertError(IsInvalidParamsError),
+		},
 		{
 			name: "Misconfigured ConfigMap",
 			gatewayClass: &gatewayv1beta1.GatewayClass{
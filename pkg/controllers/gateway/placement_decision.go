@@ -0,0 +1,73 @@
+package gateway
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+const (
+	// GatewayPlacementDecisionAnnotation holds the clusters the gateway is placed on and the strategy that placed it
+	// there. The gateway status has no field for it, so it is kept with the gateway clusters annotation.
+	GatewayPlacementDecisionAnnotation = "kuadrant.io/gateway-placement-decision"
+
+	// PlacementStrategyPinned is the strategy of the gateways placed on the clusters they are pinned to by annotation
+	PlacementStrategyPinned = "Pinned"
+)
+
+// PlacementDecision is the placement of the gateway
+type PlacementDecision struct {
+	// Clusters are the sorted names of the clusters the gateway is placed on
+	Clusters []string `json:"clusters"`
+	// Strategy is the placement strategy of the gateway class, or Pinned for a gateway pinned to its clusters
+	Strategy string `json:"strategy"`
+	// LastChangeTime is the time the clusters or the strategy last changed
+	LastChangeTime metav1.Time `json:"lastChangeTime"`
+}
+
+// getPlacementDecision returns the placement decision recorded on the gateway, nil if there is none or it is invalid
+func getPlacementDecision(gateway *gatewayv1beta1.Gateway) *PlacementDecision {
+	val := metadata.GetAnnotation(gateway, GatewayPlacementDecisionAnnotation)
+	if val == "" {
+		return nil
+	}
+	decision := &PlacementDecision{}
+	if err := json.Unmarshal([]byte(val), decision); err != nil {
+		return nil
+	}
+	return decision
+}
+
+// reconcilePlacementDecision records the placement of the gateway on the clusters in its placement decision
+// annotation. The last change time is kept from the previous decision while the clusters and strategy are unchanged.
+func reconcilePlacementDecision(gateway *gatewayv1beta1.Gateway, clusters []string, params *Params, now metav1.Time) error {
+	decision := PlacementDecision{
+		Clusters:       append([]string{}, clusters...),
+		Strategy:       placement.StrategyStatic,
+		LastChangeTime: now,
+	}
+	sort.Strings(decision.Clusters)
+	if _, isPinned := placement.GetPinnedClusters(gateway); isPinned {
+		decision.Strategy = PlacementStrategyPinned
+	} else if params != nil && params.Placement != nil && params.Placement.Type != "" {
+		decision.Strategy = params.Placement.Type
+	}
+
+	previous := getPlacementDecision(gateway)
+	if previous != nil && previous.Strategy == decision.Strategy && reflect.DeepEqual(previous.Clusters, decision.Clusters) {
+		decision.LastChangeTime = previous.LastChangeTime
+	}
+
+	serialized, err := json.Marshal(decision)
+	if err != nil {
+		return err
+	}
+	metadata.AddAnnotation(gateway, GatewayPlacementDecisionAnnotation, string(serialized))
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build unit
+
+package gateway
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestReconcilePlacementDecision(t *testing.T) {
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: testutil.DummyCRName, Namespace: testutil.Namespace},
+	}
+	params := &Params{Placement: &placement.Strategy{Type: placement.StrategyCapacity, Clusters: 2}}
+	placedAt := metav1.NewTime(time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC))
+
+	// reconcile records the placement at the time and returns the decision read back from the gateway
+	reconcile := func(clusters []string, params *Params, now metav1.Time) *PlacementDecision {
+		t.Helper()
+		if err := reconcilePlacementDecision(gateway, clusters, params, now); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		decision := getPlacementDecision(gateway)
+		if decision == nil {
+			t.Fatalf("expected a placement decision, got annotation %q", gateway.Annotations[GatewayPlacementDecisionAnnotation])
+		}
+		return decision
+	}
+
+	decision := reconcile([]string{"cluster-2", "cluster-1"}, params, placedAt)
+	if !reflect.DeepEqual(decision.Clusters, []string{"cluster-1", "cluster-2"}) {
+		t.Errorf("expected the gateway to be placed on clusters cluster-1 and cluster-2, got %v", decision.Clusters)
+	}
+	if decision.Strategy != placement.StrategyCapacity {
+		t.Errorf("expected the strategy %s, got %s", placement.StrategyCapacity, decision.Strategy)
+	}
+	if !decision.LastChangeTime.Equal(&placedAt) {
+		t.Errorf("expected the placement to change at %v, got %v", placedAt, decision.LastChangeTime)
+	}
+
+	// an unchanged placement keeps its last change time
+	annotation := gateway.Annotations[GatewayPlacementDecisionAnnotation]
+	decision = reconcile([]string{"cluster-1", "cluster-2"}, params, metav1.NewTime(placedAt.Add(time.Hour)))
+	if !decision.LastChangeTime.Equal(&placedAt) || gateway.Annotations[GatewayPlacementDecisionAnnotation] != annotation {
+		t.Errorf("expected the unchanged placement not to be updated, got %v", gateway.Annotations[GatewayPlacementDecisionAnnotation])
+	}
+
+	// the gateway moving to another cluster is a change
+	movedAt := metav1.NewTime(placedAt.Add(2 * time.Hour))
+	decision = reconcile([]string{"cluster-1", "cluster-3"}, params, movedAt)
+	if !reflect.DeepEqual(decision.Clusters, []string{"cluster-1", "cluster-3"}) || !decision.LastChangeTime.Equal(&movedAt) {
+		t.Errorf("expected the placement on clusters cluster-1 and cluster-3 to change at %v, got %+v", movedAt, decision)
+	}
+
+	// pinned clusters override the strategy of the gateway class
+	gateway.Annotations[placement.GatewayPinnedClustersAnnotation] = "cluster-1,cluster-3"
+	if decision = reconcile([]string{"cluster-1", "cluster-3"}, params, movedAt); decision.Strategy != PlacementStrategyPinned {
+		t.Errorf("expected the strategy %s, got %s", PlacementStrategyPinned, decision.Strategy)
+	}
+	delete(gateway.Annotations, placement.GatewayPinnedClustersAnnotation)
+	if decision = reconcile([]string{"cluster-1", "cluster-3"}, &Params{}, movedAt); decision.Strategy != placement.StrategyStatic {
+		t.Errorf("expected the default strategy %s, got %s", placement.StrategyStatic, decision.Strategy)
+	}
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+const (
+	// GatewayConditionClustersSelected reports the clusters selected by the placement strategy of the gateway class
+	GatewayConditionClustersSelected gatewayv1beta1.GatewayConditionType = "ClustersSelected"
+
+	GatewayReasonClustersSelected  gatewayv1beta1.GatewayConditionReason = "ClustersSelected"
+	GatewayReasonSelectionFailed   gatewayv1beta1.GatewayConditionReason = "SelectionFailed"
+	GatewayReasonNoClusterSelected gatewayv1beta1.GatewayConditionReason = "NoClusterSelected"
+
+	// GatewayConditionClustersPinned reports the clusters the gateway is pinned to by annotation
+	GatewayConditionClustersPinned gatewayv1beta1.GatewayConditionType = "ClustersPinned"
+
+	GatewayReasonClustersPinned  gatewayv1beta1.GatewayConditionReason = "ClustersPinned"
+	GatewayReasonUnknownClusters gatewayv1beta1.GatewayConditionReason = "UnknownClusters"
+)
+
+// reconcilePlacementParams sets the placement strategy of the gateway class parameters on the upstream gateway, where
+// the placer reads it when selecting the clusters
+func reconcilePlacementParams(gateway *gatewayv1beta1.Gateway, params *Params) error {
+	if params.Placement == nil {
+		metadata.RemoveAnnotation(gateway, placement.GatewayPlacementAnnotation)
+		return nil
+	}
+	serialized, err := json.Marshal(params.Placement)
+	if err != nil {
+		return err
+	}
+	metadata.AddAnnotation(gateway, placement.GatewayPlacementAnnotation, string(serialized))
+	return nil
+}
+
+// isCapacityPlacement returns true if the gateway class places gateways with the Capacity strategy
+func isCapacityPlacement(params *Params) bool {
+	return params != nil && params.Placement != nil && params.Placement.Type == placement.StrategyCapacity
+}
+
+// buildClustersSelectedCondition builds a condition listing the clusters selected by the placement strategy
+func buildClustersSelectedCondition(generation int64, strategy *placement.Strategy, selected []string, err error) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               string(GatewayConditionClustersSelected),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(GatewayReasonClustersSelected),
+		Message:            fmt.Sprintf("clusters %v selected by the %s placement strategy", selected, strategy.Type),
+		ObservedGeneration: generation,
+	}
+	switch {
+	case err != nil:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(GatewayReasonSelectionFailed)
+		cond.Message = fmt.Sprintf("failed to select clusters with the %s placement strategy: %s", strategy.Type, err)
+	case len(selected) == 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(GatewayReasonNoClusterSelected)
+		cond.Message = fmt.Sprintf("no cluster selected by the %s placement strategy", strategy.Type)
+	}
+	return cond
+}
+
+// buildClustersPinnedCondition builds a condition listing the clusters the gateway is pinned to. It is false as a
+// warning when some of the pinned clusters are unknown, the gateway is still placed on the known ones.
+func buildClustersPinnedCondition(generation int64, known, unknown []string) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               string(GatewayConditionClustersPinned),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(GatewayReasonClustersPinned),
+		Message:            fmt.Sprintf("gateway pinned to clusters %v", known),
+		ObservedGeneration: generation,
+	}
+	if len(unknown) > 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(GatewayReasonUnknownClusters)
+		cond.Message = fmt.Sprintf("pinned clusters %v are unknown, gateway pinned to clusters %v", unknown, known)
+	}
+	return cond
+}
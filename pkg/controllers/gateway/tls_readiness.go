@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+)
+
+// GatewayReasonTLSCertificatesPending is the reason of the Programmed condition of a gateway held back by the TLS
+// readiness gate
+const GatewayReasonTLSCertificatesPending gatewayv1beta1.GatewayConditionReason = "TLSCertificatesPending"
+
+// tlsCertificatesNotReady returns the TLSCertificatesReady condition set on the gateway by the TLSPolicy controller
+// when the certificates are not ready, nil when they are or the gateway has no such condition
+func tlsCertificatesNotReady(gateway *gatewayv1beta1.Gateway) *metav1.Condition {
+	cond := meta.FindStatusCondition(gateway.Status.Conditions, string(conditions.ConditionTypeTLSCertificatesReady))
+	if cond == nil || cond.Status == metav1.ConditionTrue {
+		return nil
+	}
+	return cond
+}
+
+func buildTLSPendingProgrammedCondition(generation int64, notReady *metav1.Condition) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(gatewayv1beta1.GatewayConditionProgrammed),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(GatewayReasonTLSCertificatesPending),
+		Message:            fmt.Sprintf("gateway is not placed until its TLS certificates are ready: %s", notReady.Message),
+		ObservedGeneration: generation,
+	}
+}
+
+// pendingListenerStatuses returns a not ready status for every TLS listener of the gateway whose certificate Secrets
+// do not exist or hold no certificate yet
+func (r *GatewayReconciler) pendingListenerStatuses(ctx context.Context, gateway *gatewayv1beta1.Gateway) ([]gatewayv1beta1.ListenerStatus, error) {
+	statuses := []gatewayv1beta1.ListenerStatus{}
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, secretRef := range listener.TLS.CertificateRefs {
+			ns := gateway.GetNamespace()
+			if secretRef.Namespace != nil {
+				ns = string(*secretRef.Namespace)
+			}
+			secret := &corev1.Secret{}
+			err := r.Client.Get(ctx, client.ObjectKey{Name: string(secretRef.Name), Namespace: ns}, secret)
+			if client.IgnoreNotFound(err) != nil {
+				return nil, err
+			}
+			if err == nil && len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0 {
+				continue
+			}
+			message := fmt.Sprintf("TLS certificate secret %s/%s has no certificate yet", ns, secretRef.Name)
+			if apierrors.IsNotFound(err) {
+				message = fmt.Sprintf("TLS certificate secret %s/%s not found", ns, secretRef.Name)
+			}
+			statuses = append(statuses, gatewayv1beta1.ListenerStatus{
+				Name:           listener.Name,
+				SupportedKinds: []gatewayv1beta1.RouteGroupKind{},
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(gatewayv1beta1.ListenerConditionReady),
+						Status:             metav1.ConditionFalse,
+						Reason:             string(gatewayv1beta1.ListenerReasonPending),
+						Message:            message,
+						ObservedGeneration: gateway.Generation,
+					},
+				},
+			})
+			break
+		}
+	}
+	return statuses, nil
+}
@@ -18,25 +18,41 @@ package managedzone
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
 )
 
 const (
-	ManagedZoneFinalizer = "kuadrant.io/managed-zone"
+	ManagedZoneFinalizer                          = "kuadrant.io/managed-zone"
+	ManagedZoneDelegated conditions.ConditionType = "Delegated"
+
+	// authenticationFailedBaseDelay and authenticationFailedMaxDelay bound the exponential backoff of a managed zone
+	// whose credentials are rejected by the DNS provider
+	authenticationFailedBaseDelay = 2 * time.Minute
+	authenticationFailedMaxDelay  = time.Hour
 )
 
 // ManagedZoneReconciler reconciles a ManagedZone object
@@ -44,6 +60,12 @@ type ManagedZoneReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
 	DNSProvider dns.DNSProviderFactory
+	// ZoneFilter restricts the zones published to and deleted from the DNS provider
+	ZoneFilter dns.ZoneFilter
+	controller.ReconcilerOptions
+
+	authenticationFailedOnce    sync.Once
+	authenticationFailedBackoff workqueue.RateLimiter
 }
 
 //+kubebuilder:rbac:groups=kuadrant.io,resources=managedzones,verbs=get;list;watch;create;update;patch;delete
@@ -66,6 +88,19 @@ func (r *ManagedZoneReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	log.Log.V(3).Info("ManagedZoneReconciler Reconcile", "managedZone", managedZone)
 
 	if managedZone.DeletionTimestamp != nil && !managedZone.DeletionTimestamp.IsZero() {
+		// the CAA records of the TLSPolicies are always deleted with the zone, the other DNSRecords only when
+		// cascading the deletion
+		cascade := managedZone.GetAnnotations()[v1alpha1.ManagedZoneCascadeDeleteAnnotation] == "true"
+		remaining, err := r.deleteDNSRecords(ctx, managedZone, cascade)
+		if err != nil {
+			log.Log.Error(err, "Failed to delete DNSRecords of ManagedZone", "managedZone", managedZone.Name)
+			return ctrl.Result{}, err
+		}
+		// the records must be removed from the provider before the zone is deleted
+		if remaining > 0 {
+			log.Log.V(3).Info("Waiting for DNSRecords to be deleted", "managedZone", managedZone.Name, "dnsRecords", remaining)
+			return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+		}
 		if err := r.deleteParentZoneNSRecord(ctx, managedZone); err != nil {
 			log.Log.Error(err, "Failed to delete parent Zone NS Record", "managedZone", managedZone)
 			return ctrl.Result{}, err
@@ -98,14 +133,34 @@ func (r *ManagedZoneReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	var requeueAfter time.Duration
 	var reason, message string
 	status := metav1.ConditionTrue
 	reason = "ProviderSuccess"
 	message = "Provider ensured the managed zone"
 
+	// Refuse zones outside the allowlist without calling the provider or delegating them
+	if err := r.ZoneFilter.Validate(managedZone); err != nil {
+		managedZone.Status.ObservedGeneration = managedZone.Generation
+		setManagedZoneCondition(managedZone, string(conditions.ConditionTypeReady), metav1.ConditionFalse, "ZoneNotAllowed",
+			fmt.Sprintf("The managed zone is not managed by this controller: %v", err))
+		return ctrl.Result{}, r.Status().Update(ctx, managedZone)
+	}
+
 	// Publish the managed zone
 	err = r.publishManagedZone(ctx, managedZone)
-	if err != nil {
+	var authErr *dns.AuthenticationError
+	if errors.As(err, &authErr) {
+		status = metav1.ConditionFalse
+		reason = "ProviderAuthenticationFailed"
+		message = fmt.Sprintf("The DNS provider failed to authenticate with the credentials of the managed zone: %v", dns.SanitizeError(authErr))
+		// the credentials are unlikely to be fixed soon, back off rather than retrying immediately
+		requeueAfter = r.authenticationBackoff().When(req.NamespacedName)
+	} else if errors.Is(err, dns.ErrDNSSECNotSupported) {
+		status = metav1.ConditionFalse
+		reason = "DNSSECNotSupported"
+		message = fmt.Sprintf("The managed zone cannot be signed: %v", err)
+	} else if err != nil {
 		status = metav1.ConditionFalse
 		reason = "ProviderError"
 		message = fmt.Sprintf("The DNS provider failed to ensure the managed zone: %v", err)
@@ -116,30 +171,12 @@ func (r *ManagedZoneReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
-	// Create the parent zone NS record
-	err = r.createParentZoneNSRecord(ctx, managedZone)
-	if err != nil {
+	// Delegate the managed zone in the parent zone
+	delegated := r.reconcileDelegation(ctx, managedZone)
+	if delegated != nil && delegated.Status == metav1.ConditionFalse {
 		status = metav1.ConditionFalse
-		reason = "ParentZoneNSRecordError"
-		message = fmt.Sprintf("Failed to create the NS record in the parent managed zone: %v", err)
-
-		err = r.Status().Update(ctx, managedZone)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-	}
-
-	// Check the parent zone NS record status
-	err = r.parentZoneNSRecordReady(ctx, managedZone)
-	if err != nil {
-		status = metav1.ConditionFalse
-		reason = "ParentZoneNSRecordNotReady"
-		message = fmt.Sprintf("NS Record ready status check failed: %v", err)
-
-		err = r.Status().Update(ctx, managedZone)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
+		reason = delegated.Reason
+		message = delegated.Message
 	}
 
 	managedZone.Status.ObservedGeneration = managedZone.Generation
@@ -148,8 +185,19 @@ func (r *ManagedZoneReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	if requeueAfter == 0 {
+		r.authenticationBackoff().Forget(req.NamespacedName)
+	}
 	log.Log.Info("Reconciled ManagedZone", "managedZone", managedZone.Name)
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// authenticationBackoff returns the backoff of the managed zones whose credentials were rejected by the DNS provider
+func (r *ManagedZoneReconciler) authenticationBackoff() workqueue.RateLimiter {
+	r.authenticationFailedOnce.Do(func() {
+		r.authenticationFailedBackoff = workqueue.NewItemExponentialFailureRateLimiter(authenticationFailedBaseDelay, authenticationFailedMaxDelay)
+	})
+	return r.authenticationFailedBackoff
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -158,7 +206,39 @@ func (r *ManagedZoneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&v1alpha1.ManagedZone{}).
 		Owns(&v1alpha1.DNSRecord{}).
 		Owns(&v1alpha1.ManagedZone{}).
-		Complete(r)
+		// the NS records are owned by the parent zone, the delegated zone is enqueued so its condition follows them
+		Watches(&source.Kind{Type: &v1alpha1.DNSRecord{}}, handler.EnqueueRequestsFromMapFunc(r.delegatedZonesForDNSRecord)).
+		WithOptions(r.ControllerOptions()).
+		Complete(r.WrapReconciler(r))
+}
+
+// delegatedZonesForDNSRecord returns a request for the managed zone delegated by the given NS record
+func (r *ManagedZoneReconciler) delegatedZonesForDNSRecord(obj client.Object) []reconcile.Request {
+	dnsRecord, ok := obj.(*v1alpha1.DNSRecord)
+	if !ok || dnsRecord.Spec.ManagedZoneRef == nil {
+		return nil
+	}
+	if !slice.Contains(dnsRecord.Spec.Endpoints, func(endpoint *v1alpha1.Endpoint) bool {
+		return endpoint.RecordType == string(v1alpha1.NSRecordType)
+	}) {
+		return nil
+	}
+
+	managedZones := &v1alpha1.ManagedZoneList{}
+	if err := r.Client.List(context.TODO(), managedZones, client.InNamespace(dnsRecord.Namespace)); err != nil {
+		log.Log.Error(err, "failed to list managed zones for NS record", "dnsRecord", client.ObjectKeyFromObject(dnsRecord))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, managedZone := range managedZones.Items {
+		if managedZone.Spec.ParentManagedZone != nil &&
+			managedZone.Spec.ParentManagedZone.Name == dnsRecord.Spec.ManagedZoneRef.Name &&
+			managedZone.Spec.DomainName == dnsRecord.Name {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&managedZone)})
+		}
+	}
+	return requests
 }
 
 func (r *ManagedZoneReconciler) publishManagedZone(ctx context.Context, managedZone *v1alpha1.ManagedZone) error {
@@ -176,6 +256,20 @@ func (r *ManagedZoneReconciler) publishManagedZone(ctx context.Context, managedZ
 	managedZone.Status.RecordCount = mzResp.RecordCount
 	managedZone.Status.NameServers = mzResp.NameServers
 
+	if managedZone.Spec.DNSSEC != v1alpha1.DNSSECEnabled {
+		managedZone.Status.DSRecord = ""
+		return nil
+	}
+	dnssecProvider, ok := dnsProvider.(dns.DNSSECProvider)
+	if !ok {
+		return dns.ErrDNSSECNotSupported
+	}
+	dsRecord, err := dnssecProvider.EnsureDNSSEC(managedZone)
+	if err != nil {
+		return err
+	}
+	managedZone.Status.DSRecord = dsRecord
+
 	return nil
 }
 
@@ -184,6 +278,10 @@ func (r *ManagedZoneReconciler) deleteManagedZone(ctx context.Context, managedZo
 		log.Log.Info("Skipping deletion of managed zone with provider ID specified in spec", "managedZone", managedZone.Name)
 		return nil
 	}
+	if err := r.ZoneFilter.Validate(managedZone); err != nil {
+		log.Log.Info("Skipping deletion of managed zone not allowed by the zone filters", "managedZone", managedZone.Name)
+		return nil
+	}
 
 	dnsProvider, err := r.DNSProvider(ctx, managedZone)
 	if err != nil {
@@ -208,6 +306,48 @@ func (r *ManagedZoneReconciler) deleteManagedZone(ctx context.Context, managedZo
 	return nil
 }
 
+// deleteDNSRecords deletes the DNSRecords targeting the managed zone, only the CAA records of the TLSPolicies unless
+// all is set, and returns the number of those DNSRecords that still target it. The DNSRecords only publishing to the
+// zone as an additional managed zone are not deleted, the zone is removed from their additional managed zones instead
+// so that they are only removed from this zone.
+func (r *ManagedZoneReconciler) deleteDNSRecords(ctx context.Context, managedZone *v1alpha1.ManagedZone, all bool) (int, error) {
+	dnsRecords, err := v1alpha1.DNSRecordsForManagedZone(ctx, r.Client, managedZone)
+	if err != nil {
+		return 0, err
+	}
+	remaining := 0
+	for i := range dnsRecords {
+		dnsRecord := &dnsRecords[i]
+		if !all && dnsRecord.Labels[v1alpha1.TLSPolicyCAALabel] != "true" {
+			continue
+		}
+		remaining++
+		if dnsRecord.DeletionTimestamp != nil {
+			continue
+		}
+		if dnsRecord.Spec.ManagedZoneRef == nil || dnsRecord.Spec.ManagedZoneRef.Name == managedZone.Name {
+			if err := r.Client.Delete(ctx, dnsRecord); client.IgnoreNotFound(err) != nil {
+				return 0, err
+			}
+			continue
+		}
+		zoneRefs := make([]v1alpha1.ManagedZoneReference, 0, len(dnsRecord.Spec.AdditionalManagedZoneRefs))
+		for _, zoneRef := range dnsRecord.Spec.AdditionalManagedZoneRefs {
+			if zoneRef.Name != managedZone.Name {
+				zoneRefs = append(zoneRefs, zoneRef)
+			}
+		}
+		if len(zoneRefs) == len(dnsRecord.Spec.AdditionalManagedZoneRefs) {
+			continue
+		}
+		dnsRecord.Spec.AdditionalManagedZoneRefs = zoneRefs
+		if err := r.Client.Update(ctx, dnsRecord); client.IgnoreNotFound(err) != nil {
+			return 0, err
+		}
+	}
+	return remaining, nil
+}
+
 func (r *ManagedZoneReconciler) getParentZone(ctx context.Context, managedZone *v1alpha1.ManagedZone) (*v1alpha1.ManagedZone, error) {
 	if managedZone.Spec.ParentManagedZone == nil {
 		return nil, nil
@@ -237,13 +377,58 @@ func (r *ManagedZoneReconciler) setParentZoneOwner(ctx context.Context, managedZ
 	return err
 }
 
-func (r *ManagedZoneReconciler) createParentZoneNSRecord(ctx context.Context, managedZone *v1alpha1.ManagedZone) error {
+// reconcileDelegation ensures the NS record for the managed zone exists in its parent zone and sets the Delegated
+// condition accordingly. The condition is returned, or nil when the managed zone has no parent zone.
+func (r *ManagedZoneReconciler) reconcileDelegation(ctx context.Context, managedZone *v1alpha1.ManagedZone) *metav1.Condition {
+	if managedZone.Spec.ParentManagedZone == nil {
+		meta.RemoveStatusCondition(&managedZone.Status.Conditions, string(ManagedZoneDelegated))
+		return nil
+	}
+
+	status := metav1.ConditionTrue
+	reason := "DelegationEstablished"
+	message := fmt.Sprintf("NS record for %s is ready in the parent managed zone %s", managedZone.Spec.DomainName, managedZone.Spec.ParentManagedZone.Name)
+
 	parentZone, err := r.getParentZone(ctx, managedZone)
-	if err != nil {
-		return err
+	if err == nil {
+		if err = validateParentZone(managedZone, parentZone); err != nil {
+			status = metav1.ConditionFalse
+			reason = "InvalidParentZone"
+			message = err.Error()
+		}
+	} else {
+		status = metav1.ConditionFalse
+		reason = "ParentZoneNotFound"
+		message = fmt.Sprintf("Failed to get the parent managed zone: %v", err)
+	}
+
+	if status == metav1.ConditionTrue {
+		if err = r.createParentZoneNSRecord(ctx, managedZone, parentZone); err != nil {
+			status = metav1.ConditionFalse
+			reason = "ParentZoneNSRecordError"
+			message = fmt.Sprintf("Failed to create the NS record in the parent managed zone: %v", err)
+		} else if err = r.parentZoneNSRecordReady(ctx, managedZone, parentZone); err != nil {
+			status = metav1.ConditionFalse
+			reason = "ParentZoneNSRecordNotReady"
+			message = fmt.Sprintf("NS Record ready status check failed: %v", err)
+		}
 	}
-	if parentZone == nil {
-		return nil
+
+	setManagedZoneCondition(managedZone, string(ManagedZoneDelegated), status, reason, message)
+	return meta.FindStatusCondition(managedZone.Status.Conditions, string(ManagedZoneDelegated))
+}
+
+// validateParentZone returns an error if the managed zone cannot be delegated from the parent zone
+func validateParentZone(managedZone, parentZone *v1alpha1.ManagedZone) error {
+	if !strings.HasSuffix(managedZone.Spec.DomainName, "."+parentZone.Spec.DomainName) {
+		return fmt.Errorf("domain %s is not a subdomain of the parent managed zone domain %s", managedZone.Spec.DomainName, parentZone.Spec.DomainName)
+	}
+	return nil
+}
+
+func (r *ManagedZoneReconciler) createParentZoneNSRecord(ctx context.Context, managedZone, parentZone *v1alpha1.ManagedZone) error {
+	if len(managedZone.Status.NameServers) == 0 {
+		return fmt.Errorf("the managed zone has no name servers to delegate to")
 	}
 
 	recordName := managedZone.Spec.DomainName
@@ -274,16 +459,25 @@ func (r *ManagedZoneReconciler) createParentZoneNSRecord(ctx context.Context, ma
 			},
 		},
 	}
-	err = controllerutil.SetControllerReference(parentZone, nsRecord, r.Scheme)
+	err := controllerutil.SetControllerReference(parentZone, nsRecord, r.Scheme)
 	if err != nil {
 		return err
 	}
 	err = r.Client.Create(ctx, nsRecord, &client.CreateOptions{})
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
+	if err == nil || !k8serrors.IsAlreadyExists(err) {
 		return err
 	}
 
-	return nil
+	// the name servers of the managed zone may have changed since the NS record was created
+	existing := &v1alpha1.DNSRecord{}
+	if err = r.Client.Get(ctx, client.ObjectKeyFromObject(nsRecord), existing); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, nsRecord.Spec) {
+		return nil
+	}
+	existing.Spec = nsRecord.Spec
+	return r.Client.Update(ctx, existing)
 }
 
 func (r *ManagedZoneReconciler) deleteParentZoneNSRecord(ctx context.Context, managedZone *v1alpha1.ManagedZone) error {
@@ -315,25 +509,13 @@ func (r *ManagedZoneReconciler) deleteParentZoneNSRecord(ctx context.Context, ma
 	return nil
 }
 
-func (r *ManagedZoneReconciler) parentZoneNSRecordReady(ctx context.Context, managedZone *v1alpha1.ManagedZone) error {
-	parentZone, err := r.getParentZone(ctx, managedZone)
-	if err := client.IgnoreNotFound(err); err != nil {
-		return err
-	}
-	if parentZone == nil {
-		return nil
-	}
-
+func (r *ManagedZoneReconciler) parentZoneNSRecordReady(ctx context.Context, managedZone, parentZone *v1alpha1.ManagedZone) error {
 	recordName := managedZone.Spec.DomainName
 
 	nsRecord := &v1alpha1.DNSRecord{}
-	err = r.Client.Get(ctx, client.ObjectKey{Namespace: parentZone.Namespace, Name: recordName}, nsRecord)
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: parentZone.Namespace, Name: recordName}, nsRecord)
 	if err != nil {
-		if err := client.IgnoreNotFound(err); err == nil {
-			return nil
-		} else {
-			return err
-		}
+		return err
 	}
 
 	nsRecordReady := meta.IsStatusConditionTrue(nsRecord.Status.Conditions, string(conditions.ConditionTypeReady))
@@ -0,0 +1,106 @@
+package tlspolicy
+
+import (
+	"context"
+	"fmt"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// CertificateEventHandler enqueues the TLSPolicy that created a Certificate and records an event on the Gateway
+// when the Certificate becomes ready. For a shared Certificate, every TLSPolicy and Gateway using it is notified.
+type CertificateEventHandler struct {
+	client   client.Client
+	recorder record.EventRecorder
+}
+
+var _ handler.EventHandler = &CertificateEventHandler{}
+
+// Create implements handler.EventHandler
+func (eh *CertificateEventHandler) Create(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	eh.enqueueForObject(e.Object, q)
+}
+
+// Delete implements handler.EventHandler
+func (eh *CertificateEventHandler) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	eh.enqueueForObject(e.Object, q)
+}
+
+// Generic implements handler.EventHandler
+func (eh *CertificateEventHandler) Generic(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	eh.enqueueForObject(e.Object, q)
+}
+
+// Update implements handler.EventHandler
+func (eh *CertificateEventHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldCert, ok := e.ObjectOld.(*certmanv1.Certificate)
+	if !ok {
+		return
+	}
+	newCert, ok := e.ObjectNew.(*certmanv1.Certificate)
+	if !ok {
+		return
+	}
+	if !isCertificateReady(oldCert) && isCertificateReady(newCert) {
+		eh.recordCertificateReady(newCert)
+	}
+	eh.enqueueForObject(e.ObjectNew, q)
+}
+
+func (eh *CertificateEventHandler) enqueueForObject(obj client.Object, q workqueue.RateLimitingInterface) {
+	if obj.GetLabels()[TLSPolicySharedCertificateLabel] == "true" {
+		for user := range parseSharedCertificateUsers(obj.GetAnnotations()[TLSPolicySharedCertificateUsersAnnotation]) {
+			q.Add(ctrl.Request{NamespacedName: user.policy})
+		}
+		return
+	}
+	labels := obj.GetLabels()
+	policyName, ok := labels[TLSPolicyBackRefAnnotation]
+	if !ok {
+		return
+	}
+	q.Add(ctrl.Request{
+		NamespacedName: client.ObjectKey{Name: policyName, Namespace: labels[fmt.Sprintf("%s-namespace", TLSPolicyBackRefAnnotation)]},
+	})
+}
+
+func (eh *CertificateEventHandler) recordCertificateReady(cert *certmanv1.Certificate) {
+	var gatewayKeys []client.ObjectKey
+	if isSharedCertificate(cert) {
+		for user := range parseSharedCertificateUsers(cert.Annotations[TLSPolicySharedCertificateUsersAnnotation]) {
+			gatewayKeys = append(gatewayKeys, user.gateway)
+		}
+	} else {
+		labels := cert.GetLabels()
+		gatewayKeys = append(gatewayKeys, client.ObjectKey{Name: labels["gateway"], Namespace: labels["gateway-namespace"]})
+	}
+	for _, gatewayKey := range gatewayKeys {
+		gateway := &gatewayv1beta1.Gateway{}
+		if err := eh.client.Get(context.TODO(), gatewayKey, gateway); err != nil {
+			log.Log.Error(err, "failed to get gateway when recording certificate ready event", "certificate", client.ObjectKeyFromObject(cert))
+			continue
+		}
+		eh.recorder.Eventf(gateway, corev1.EventTypeNormal, EventReasonCertificateReady,
+			"Certificate %s/%s is ready", cert.Namespace, cert.Name)
+	}
+}
+
+func isCertificateReady(cert *certmanv1.Certificate) bool {
+	for _, cond := range cert.Status.Conditions {
+		if cond.Type == certmanv1.CertificateConditionReady {
+			return cond.Status == cmmeta.ConditionTrue
+		}
+	}
+	return false
+}
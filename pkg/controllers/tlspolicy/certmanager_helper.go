@@ -3,8 +3,10 @@ package tlspolicy
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -14,10 +16,15 @@ import (
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 )
 
+var (
+	ErrSolverZoneMismatch  = fmt.Errorf("solver zone does not match host")
+	ErrCertificateConflict = fmt.Errorf("certificate already managed by another policy")
+)
+
 // https://cert-manager.io/docs/usage/gateway/#supported-annotations
 // Helper functions largely based on cert manager https://github.com/cert-manager/cert-manager/blob/master/pkg/controller/certificate-shim/sync.go
 
-func validateGatewayListenerBlock(path *field.Path, l gatewayv1beta1.Listener, ingLike metav1.Object) field.ErrorList {
+func validateGatewayListenerBlock(path *field.Path, l gatewayv1beta1.Listener, ingLike metav1.Object, sharedNamespace string) field.ErrorList {
 	var errs field.ErrorList
 
 	if l.Hostname == nil || *l.Hostname == "" {
@@ -45,7 +52,7 @@ func validateGatewayListenerBlock(path *field.Path, l gatewayv1beta1.Listener, i
 					*secretRef.Kind, []string{"Secret", ""}))
 			}
 
-			if secretRef.Namespace != nil && string(*secretRef.Namespace) != ingLike.GetNamespace() {
+			if secretRef.Namespace != nil && string(*secretRef.Namespace) != ingLike.GetNamespace() && (sharedNamespace == "" || string(*secretRef.Namespace) != sharedNamespace) {
 				errs = append(errs, field.Invalid(path.Child("tls").Child("certificateRef").Index(i).Child("namespace"),
 					*secretRef.Namespace, "cross-namespace secret references are not allowed in listeners"))
 			}
@@ -81,11 +88,7 @@ func translatePolicy(crt *certmanv1.Certificate, tlsPolicy v1alpha1.TLSPolicySpe
 		crt.Spec.RenewBefore = tlsPolicy.RenewBefore
 	}
 
-	if tlsPolicy.RenewBefore != nil {
-		crt.Spec.RenewBefore = tlsPolicy.RenewBefore
-	}
-
-	if tlsPolicy.Usages != nil {
+	if len(tlsPolicy.Usages) > 0 {
 		crt.Spec.Usages = tlsPolicy.Usages
 	}
 
@@ -93,6 +96,10 @@ func translatePolicy(crt *certmanv1.Certificate, tlsPolicy v1alpha1.TLSPolicySpe
 		crt.Spec.RevisionHistoryLimit = tlsPolicy.RevisionHistoryLimit
 	}
 
+	if len(tlsPolicy.AdditionalOutputFormats) > 0 {
+		crt.Spec.AdditionalOutputFormats = tlsPolicy.AdditionalOutputFormats
+	}
+
 	if tlsPolicy.PrivateKey != nil {
 
 		if crt.Spec.PrivateKey == nil {
@@ -116,20 +123,75 @@ func translatePolicy(crt *certmanv1.Certificate, tlsPolicy v1alpha1.TLSPolicySpe
 		}
 	}
 
+	if tlsPolicy.SecretTemplate != nil {
+
+		if crt.Spec.SecretTemplate == nil {
+			crt.Spec.SecretTemplate = &certmanv1.CertificateSecretTemplate{}
+		}
+
+		// labels already on the template are set by the controller and take precedence
+		for k, v := range tlsPolicy.SecretTemplate.Labels {
+			if _, ok := crt.Spec.SecretTemplate.Labels[k]; ok {
+				continue
+			}
+			if crt.Spec.SecretTemplate.Labels == nil {
+				crt.Spec.SecretTemplate.Labels = map[string]string{}
+			}
+			crt.Spec.SecretTemplate.Labels[k] = v
+		}
+
+		for k, v := range tlsPolicy.SecretTemplate.Annotations {
+			if crt.Spec.SecretTemplate.Annotations == nil {
+				crt.Spec.SecretTemplate.Annotations = map[string]string{}
+			}
+			crt.Spec.SecretTemplate.Annotations[k] = v
+		}
+	}
+
+}
+
+// validateSolverZone validates that the ManagedZone referenced by the policy solverRef can satisfy DNS01 challenges for
+// all the given hosts
+func validateSolverZone(ctx context.Context, k8sClient client.Client, policy *v1alpha1.TLSPolicy, hosts []string) error {
+	if policy.Spec.SolverRef == nil {
+		return nil
+	}
+	zone := &v1alpha1.ManagedZone{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: policy.Spec.SolverRef.Name, Namespace: policy.Namespace}, zone); err != nil {
+		return fmt.Errorf("failed to get solverRef managed zone %s: %w", policy.Spec.SolverRef.Name, err)
+	}
+	for _, host := range hosts {
+		if !zoneCoversHost(zone.Spec.DomainName, host) {
+			return fmt.Errorf("%w: managed zone %s (%s) cannot solve DNS01 challenges for host %s", ErrSolverZoneMismatch, zone.Name, zone.Spec.DomainName, host)
+		}
+	}
+	return nil
+}
+
+// zoneCoversHost returns true if host is the zone domain or any subdomain of it. Wildcard hosts are matched using
+// the domain they are a wildcard of, since the DNS01 challenge record for "*.example.com" is created in example.com.
+func zoneCoversHost(zoneDomain, host string) bool {
+	zoneDomain = strings.ToLower(strings.TrimSuffix(zoneDomain, "."))
+	host = strings.ToLower(strings.TrimPrefix(host, "*."))
+	return host == zoneDomain || strings.HasSuffix(host, "."+zoneDomain)
 }
 
-// validateIssuer validates that the issuer specified exists
-func validateIssuer(ctx context.Context, k8sClient client.Client, policy *v1alpha1.TLSPolicy) error {
+// validateIssuer validates that the issuer specified exists. The issuers of groups other than cert-manager.io are
+// resources of their own signers, they are left for cert-manager to resolve.
+func validateIssuer(ctx context.Context, k8sClient client.Client, namespace string, issuerRef cmmeta.ObjectReference) error {
+	if issuerRef.Group != "" && issuerRef.Group != certmanv1.SchemeGroupVersion.Group {
+		return nil
+	}
 	var issuer client.Object
 	issuerNamespace := ""
-	switch policy.Spec.IssuerRef.Kind {
+	switch issuerRef.Kind {
 	case "", certmanv1.IssuerKind:
 		issuer = &certmanv1.Issuer{}
-		issuerNamespace = policy.Namespace
+		issuerNamespace = namespace
 	case certmanv1.ClusterIssuerKind:
 		issuer = &certmanv1.ClusterIssuer{}
 	default:
-		return fmt.Errorf(`invalid value %q for issuerRef.kind. Must be empty, %q or %q`, policy.Spec.IssuerRef.Kind, certmanv1.IssuerKind, certmanv1.ClusterIssuerKind)
+		return fmt.Errorf(`invalid value %q for issuerRef.kind. Must be empty, %q or %q`, issuerRef.Kind, certmanv1.IssuerKind, certmanv1.ClusterIssuerKind)
 	}
-	return k8sClient.Get(ctx, client.ObjectKey{Name: policy.Spec.IssuerRef.Name, Namespace: issuerNamespace}, issuer)
+	return k8sClient.Get(ctx, client.ObjectKey{Name: issuerRef.Name, Namespace: issuerNamespace}, issuer)
 }
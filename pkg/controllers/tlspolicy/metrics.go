@@ -0,0 +1,91 @@
+package tlspolicy
+
+import (
+	"time"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	operationLabel = "operation"
+
+	operationCreate = "create"
+	operationUpdate = "update"
+	operationDelete = "delete"
+)
+
+var (
+	// policyCertificates is a prometheus metric which holds the number of
+	// Certificates currently managed by each TLSPolicy.
+	policyCertificates = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mgc_tls_policy_certificates",
+			Help: "MGC TLSPolicy number of managed Certificates",
+		},
+		[]string{"tls_policy_name", "tls_policy_namespace"},
+	)
+
+	// certificateOperationTotal is a prometheus counter metric which holds the
+	// total number of Certificates created, updated and deleted by the TLSPolicy controller.
+	certificateOperationTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mgc_tls_policy_certificate_operations_total",
+			Help: "MGC TLSPolicy total number of Certificate operations",
+		},
+		[]string{operationLabel},
+	)
+
+	// certificateExpirySeconds is a prometheus metric which holds the number of
+	// seconds until the soonest expiring Certificate managed by any TLSPolicy expires.
+	certificateExpirySeconds = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mgc_tls_policy_certificate_expiry_seconds",
+			Help: "MGC TLSPolicy seconds until the soonest managed Certificate expiry",
+		},
+	)
+
+	// policyCertificatesExpiringSoon is a prometheus metric which holds the number of
+	// Certificates of each TLSPolicy expiring within the warning window without being renewed.
+	policyCertificatesExpiringSoon = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mgc_tls_policy_certificates_expiring_soon",
+			Help: "MGC TLSPolicy number of managed Certificates expiring within the warning window",
+		},
+		[]string{"tls_policy_name", "tls_policy_namespace"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		policyCertificates,
+		certificateOperationTotal,
+		certificateExpirySeconds,
+		policyCertificatesExpiringSoon,
+	)
+
+	for _, operation := range []string{operationCreate, operationUpdate, operationDelete} {
+		certificateOperationTotal.WithLabelValues(operation).Add(0)
+	}
+}
+
+// observeCertificateExpiry sets the expiry gauge from the soonest status.notAfter of the given Certificates.
+// The gauge is left untouched when none of the Certificates has been issued yet.
+func observeCertificateExpiry(certs []certmanv1.Certificate, now time.Time) {
+	var soonest *time.Time
+	for i := range certs {
+		notAfter := certs[i].Status.NotAfter
+		if notAfter == nil {
+			continue
+		}
+		if soonest == nil || notAfter.Time.Before(*soonest) {
+			soonest = &notAfter.Time
+		}
+	}
+	if soonest == nil {
+		return
+	}
+	certificateExpirySeconds.Set(soonest.Sub(now).Seconds())
+}
@@ -0,0 +1,62 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"testing"
+	"time"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestObserveCertificateExpiry(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := func(d time.Duration) *metav1.Time {
+		notAfter := metav1.NewTime(now.Add(d))
+		return &notAfter
+	}
+
+	testCases := []struct {
+		name  string
+		certs []certmanv1.Certificate
+		start float64
+		want  float64
+	}{
+		{
+			name: "reports the soonest notAfter",
+			certs: []certmanv1.Certificate{
+				{Status: certmanv1.CertificateStatus{NotAfter: notAfter(90 * 24 * time.Hour)}},
+				{Status: certmanv1.CertificateStatus{NotAfter: notAfter(time.Hour)}},
+				{Status: certmanv1.CertificateStatus{}},
+			},
+			want: time.Hour.Seconds(),
+		},
+		{
+			name: "reports negative seconds for an expired certificate",
+			certs: []certmanv1.Certificate{
+				{Status: certmanv1.CertificateStatus{NotAfter: notAfter(-time.Minute)}},
+			},
+			want: -time.Minute.Seconds(),
+		},
+		{
+			name: "leaves the gauge untouched when no certificate is issued",
+			certs: []certmanv1.Certificate{
+				{Status: certmanv1.CertificateStatus{}},
+			},
+			start: 42,
+			want:  42,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			certificateExpirySeconds.Set(testCase.start)
+			observeCertificateExpiry(testCase.certs, now)
+			if got := testutil.ToFloat64(certificateExpirySeconds); got != testCase.want {
+				t.Errorf("observeCertificateExpiry() gauge = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
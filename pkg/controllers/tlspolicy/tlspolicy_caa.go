@@ -0,0 +1,211 @@
+package tlspolicy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+var ErrUnknownCAADomain = fmt.Errorf("unknown CAA domain")
+
+// acmeCAADomains are the CAA domains of the CAs of the known public ACME servers, by the domain of the server
+var acmeCAADomains = map[string]string{
+	"letsencrypt.org": "letsencrypt.org",
+	"pki.goog":        "pki.goog",
+	"zerossl.com":     "sectigo.com",
+	"buypass.com":     "buypass.com",
+	"buypass.no":      "buypass.com",
+	"ssl.com":         "ssl.com",
+}
+
+func caaDNSRecordName(managedZoneName string) string {
+	return fmt.Sprintf("%s-caa", managedZoneName)
+}
+
+// reconcileCAARecords sets the CAA domains of the policy in its status, and publishes the CAA record of each
+// ManagedZone of the policy namespace referenced by the policy or holding a CAA record, from the CAA domains of the
+// policies referencing the zone. The record of a zone no longer referenced by any policy is deleted.
+func (r *TLSPolicyReconciler) reconcileCAARecords(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) error {
+	tlsPolicy.Status.CAAIssuerDomains = nil
+	zones := sets.New[string]()
+	if tlsPolicy.Spec.CAA != nil && tlsPolicy.GetDeletionTimestamp() == nil {
+		domains, err := r.caaIssuerDomains(ctx, tlsPolicy)
+		if err != nil {
+			return err
+		}
+		tlsPolicy.Status.CAAIssuerDomains = domains
+		zones.Insert(tlsPolicy.Spec.SolverRef.Name)
+	}
+
+	caaRecords := &v1alpha1.DNSRecordList{}
+	if err := r.Client().List(ctx, caaRecords, client.InNamespace(tlsPolicy.Namespace), client.MatchingLabels{v1alpha1.TLSPolicyCAALabel: "true"}); err != nil {
+		return err
+	}
+	for _, dnsRecord := range caaRecords.Items {
+		if dnsRecord.Spec.ManagedZoneRef != nil {
+			zones.Insert(dnsRecord.Spec.ManagedZoneRef.Name)
+		}
+	}
+	if zones.Len() == 0 {
+		return nil
+	}
+
+	policies := &v1alpha1.TLSPolicyList{}
+	if err := r.Client().List(ctx, policies, client.InNamespace(tlsPolicy.Namespace)); err != nil {
+		return err
+	}
+	for _, zoneName := range sets.List(zones) {
+		// the other policies are reconciled on their own, their CAA domains are taken from their status
+		domains := sets.New[string]()
+		for i := range policies.Items {
+			policy := &policies.Items[i]
+			if policy.Name == tlsPolicy.Name {
+				policy = tlsPolicy
+			}
+			if policy.GetDeletionTimestamp() != nil || policy.Spec.CAA == nil || policy.Spec.SolverRef == nil || policy.Spec.SolverRef.Name != zoneName {
+				continue
+			}
+			domains.Insert(policy.Status.CAAIssuerDomains...)
+		}
+		if err := r.reconcileCAARecord(ctx, tlsPolicy.Namespace, zoneName, sets.List(domains)); err != nil {
+			return fmt.Errorf("failed to reconcile the CAA record of managed zone %s: %w", zoneName, err)
+		}
+	}
+	return nil
+}
+
+// reconcileCAARecord publishes a CAA record authorising the CAs of the domains at the domain of the ManagedZone, or
+// deletes it when there are no domains
+func (r *TLSPolicyReconciler) reconcileCAARecord(ctx context.Context, namespace, zoneName string, domains []string) error {
+	if len(domains) == 0 {
+		dnsRecord := &v1alpha1.DNSRecord{ObjectMeta: metav1.ObjectMeta{Name: caaDNSRecordName(zoneName), Namespace: namespace}}
+		crlog.FromContext(ctx).V(3).Info("deleting CAA record no longer expected", "dnsRecord", dnsRecord.Name)
+		return client.IgnoreNotFound(r.DeleteResource(ctx, dnsRecord))
+	}
+
+	zone := &v1alpha1.ManagedZone{}
+	if err := r.Client().Get(ctx, client.ObjectKey{Name: zoneName, Namespace: namespace}, zone); err != nil {
+		return err
+	}
+	// the record is deleted with the zone, it is not published again while the zone is being deleted
+	if zone.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	endpoint := &v1alpha1.Endpoint{
+		DNSName:    zone.Spec.DomainName,
+		RecordType: string(v1alpha1.CAARecordType),
+	}
+	for _, domain := range domains {
+		endpoint.CAATargets = append(endpoint.CAATargets, v1alpha1.CAATarget{Tag: "issue", Value: domain})
+	}
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      caaDNSRecordName(zone.Name),
+			Namespace: zone.Namespace,
+			Labels:    map[string]string{v1alpha1.TLSPolicyCAALabel: "true"},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			ManagedZoneRef: &v1alpha1.ManagedZoneReference{Name: zone.Name},
+			Endpoints:      []*v1alpha1.Endpoint{endpoint},
+		},
+	}
+	// the record is shared by the policies referencing the zone, it is deleted with the zone
+	if err := controllerutil.SetControllerReference(zone, dnsRecord, r.BaseReconciler.Scheme()); err != nil {
+		return err
+	}
+	return r.ReconcileResource(ctx, &v1alpha1.DNSRecord{}, dnsRecord, caaDNSRecordMutator)
+}
+
+func caaDNSRecordMutator(existingObj, desiredObj client.Object) (bool, error) {
+	existing, ok := existingObj.(*v1alpha1.DNSRecord)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1alpha1.DNSRecord", existingObj)
+	}
+	desired, ok := desiredObj.(*v1alpha1.DNSRecord)
+	if !ok {
+		return false, fmt.Errorf("%T is not a *v1alpha1.DNSRecord", desiredObj)
+	}
+	if reflect.DeepEqual(existing.Spec.Endpoints, desired.Spec.Endpoints) {
+		return false, nil
+	}
+	existing.Spec.Endpoints = desired.Spec.Endpoints
+	return true, nil
+}
+
+// caaIssuerDomains returns the sorted CAA domains of the CAs of the policy, the domains set in the policy or else
+// the domains of the CAs of all its issuers, including the fallback issuers and the issuers of the issuer rules
+func (r *TLSPolicyReconciler) caaIssuerDomains(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) ([]string, error) {
+	if len(tlsPolicy.Spec.CAA.IssuerDomains) > 0 {
+		return sets.List(sets.New(tlsPolicy.Spec.CAA.IssuerDomains...)), nil
+	}
+
+	issuerRefs := append([]cmmeta.ObjectReference{}, tlsPolicy.Spec.GetIssuerRefs()...)
+	for _, rule := range tlsPolicy.Spec.IssuerRules {
+		issuerRefs = append(issuerRefs, rule.IssuerRef)
+	}
+	domains := sets.New[string]()
+	for _, issuerRef := range issuerRefs {
+		domain, err := r.issuerCAADomain(ctx, issuerNamespace(tlsPolicy), issuerRef)
+		if err != nil {
+			return nil, err
+		}
+		domains.Insert(domain)
+	}
+	return sets.List(domains), nil
+}
+
+// issuerCAADomain returns the CAA domain of the CA of an ACME issuer, from the domain of its ACME server
+func (r *TLSPolicyReconciler) issuerCAADomain(ctx context.Context, namespace string, issuerRef cmmeta.ObjectReference) (string, error) {
+	if issuerRef.Group != "" && issuerRef.Group != certmanv1.SchemeGroupVersion.Group {
+		return "", fmt.Errorf("%w: %s %s is not a cert-manager issuer, spec.caa.issuerDomains must be set", ErrUnknownCAADomain, issuerRef.Kind, issuerRef.Name)
+	}
+	var issuer certmanv1.GenericIssuer
+	issuerNamespace := ""
+	switch issuerRef.Kind {
+	case "", certmanv1.IssuerKind:
+		issuer = &certmanv1.Issuer{}
+		issuerNamespace = namespace
+	case certmanv1.ClusterIssuerKind:
+		issuer = &certmanv1.ClusterIssuer{}
+	default:
+		return "", fmt.Errorf(`invalid value %q for issuerRef.kind. Must be empty, %q or %q`, issuerRef.Kind, certmanv1.IssuerKind, certmanv1.ClusterIssuerKind)
+	}
+	if err := r.Client().Get(ctx, client.ObjectKey{Name: issuerRef.Name, Namespace: issuerNamespace}, issuer); err != nil {
+		return "", err
+	}
+
+	server := acmeServer(issuer)
+	if server == "" {
+		return "", fmt.Errorf("%w: %s %s is not an ACME issuer, spec.caa.issuerDomains must be set", ErrUnknownCAADomain, issuerRef.Kind, issuerRef.Name)
+	}
+	if serverURL, err := url.Parse(server); err == nil {
+		host := strings.ToLower(serverURL.Hostname())
+		for serverDomain, caaDomain := range acmeCAADomains {
+			if host == serverDomain || strings.HasSuffix(host, "."+serverDomain) {
+				return caaDomain, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: the CA of the ACME server %s of %s %s is not known, spec.caa.issuerDomains must be set", ErrUnknownCAADomain, server, issuerRef.Kind, issuerRef.Name)
+}
+
+// acmeServer returns the ACME server of the issuer, empty for the issuers that are not ACME issuers
+func acmeServer(issuer certmanv1.GenericIssuer) string {
+	if acme := issuer.GetSpec().ACME; acme != nil {
+		return acme.Server
+	}
+	return ""
+}
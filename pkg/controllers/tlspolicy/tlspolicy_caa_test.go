@@ -0,0 +1,140 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	acmev1 "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestTLSPolicyReconciler_reconcileCAARecords(t *testing.T) {
+	zone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	letsEncrypt := testutil.NewTestClusterIssuer("letsencrypt")
+	letsEncrypt.Spec.ACME = &acmev1.ACMEIssuer{Server: "https://acme-v02.api.letsencrypt.org/directory"}
+	zeroSSL := testutil.NewTestIssuer("zerossl", "test")
+	zeroSSL.Spec.ACME = &acmev1.ACMEIssuer{Server: "https://acme.zerossl.com/v2/DV90"}
+	internalCA := testutil.NewTestIssuer("internal-ca", "test")
+	internalCA.Spec.CA = &certmanv1.CAIssuer{SecretName: "internal-ca"}
+
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "test").
+		WithTargetGateway("prod-web").
+		WithSolverRef(zone.Name).
+		WithIssuer(letsEncrypt.Name, certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Spec.CAA = &v1alpha1.TLSPolicyCAA{}
+	if err := tlsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+	// another policy of the zone, its CAA domains are taken from its status
+	otherPolicy := testutil.NewTestTLSPolicy("other-tls", "test").
+		WithTargetGateway("other").
+		WithSolverRef(zone.Name).
+		WithIssuer(letsEncrypt.Name, certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+	otherPolicy.Spec.CAA = &v1alpha1.TLSPolicyCAA{IssuerDomains: []string{"pki.goog"}}
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(zone, letsEncrypt, zeroSSL, internalCA, tlsPolicy).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	caaRecord := func() *v1alpha1.DNSRecord {
+		t.Helper()
+		dnsRecord := &v1alpha1.DNSRecord{}
+		if err := f.Get(ctx, client.ObjectKey{Name: "example.com-caa", Namespace: "test"}, dnsRecord); k8serrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return dnsRecord
+	}
+	expectCAA := func(wantValues ...string) {
+		t.Helper()
+		if err := r.reconcileCAARecords(ctx, tlsPolicy); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		dnsRecord := caaRecord()
+		if dnsRecord == nil {
+			t.Fatalf("expected a CAA record to be created for the zone")
+		}
+		if len(dnsRecord.Spec.Endpoints) != 1 {
+			t.Fatalf("expected a single endpoint, got %v", dnsRecord.Spec.Endpoints)
+		}
+		endpoint := dnsRecord.Spec.Endpoints[0]
+		if endpoint.DNSName != "example.com" || endpoint.RecordType != string(v1alpha1.CAARecordType) {
+			t.Errorf("expected a CAA record at the zone domain, got %v", endpoint)
+		}
+		if values := []string(endpoint.RecordValues()); !reflect.DeepEqual(values, wantValues) {
+			t.Errorf("expected CAA values %v, got %v", wantValues, values)
+		}
+		if err := endpoint.Validate(); err != nil {
+			t.Errorf("expected a valid CAA record, got %v", err)
+		}
+		if dnsRecord.Spec.ManagedZoneRef.Name != zone.Name || !metav1.IsControlledBy(dnsRecord, zone) {
+			t.Errorf("expected the CAA record to be published in and owned by the zone, got %v", dnsRecord.ObjectMeta)
+		}
+	}
+
+	// the CA of the ACME server of the issuer is authorised
+	expectCAA(`0 issue "letsencrypt.org"`)
+	if want := []string{"letsencrypt.org"}; !reflect.DeepEqual(tlsPolicy.Status.CAAIssuerDomains, want) {
+		t.Errorf("expected the CAA domains %v in the policy status, got %v", want, tlsPolicy.Status.CAAIssuerDomains)
+	}
+
+	// the record follows the issuer of the policy
+	tlsPolicy.Spec.IssuerRef.Name, tlsPolicy.Spec.IssuerRef.Kind = zeroSSL.Name, certmanv1.IssuerKind
+	expectCAA(`0 issue "sectigo.com"`)
+
+	// the record holds the CAs of every policy of the zone
+	otherPolicy.Status.CAAIssuerDomains = []string{"pki.goog"}
+	if err := f.Create(ctx, otherPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expectCAA(`0 issue "pki.goog"`, `0 issue "sectigo.com"`)
+
+	// the CA of an issuer that is not an ACME issuer must be set in the policy
+	tlsPolicy.Spec.IssuerRef.Name = internalCA.Name
+	if err := r.reconcileCAARecords(ctx, tlsPolicy); !errors.Is(err, ErrUnknownCAADomain) {
+		t.Errorf("expected an unknown CAA domain error, got %v", err)
+	}
+	tlsPolicy.Spec.CAA.IssuerDomains = []string{"internal.example.com"}
+	expectCAA(`0 issue "internal.example.com"`, `0 issue "pki.goog"`)
+
+	// the CAs of a policy without a caa are removed, and the record is deleted once no policy of the zone has a caa
+	tlsPolicy.Spec.CAA = nil
+	expectCAA(`0 issue "pki.goog"`)
+	if tlsPolicy.Status.CAAIssuerDomains != nil {
+		t.Errorf("expected no CAA domains in the policy status, got %v", tlsPolicy.Status.CAAIssuerDomains)
+	}
+	if err := f.Delete(ctx, otherPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := r.reconcileCAARecords(ctx, tlsPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if caaRecord() != nil {
+		t.Errorf("expected the CAA record to be deleted")
+	}
+}
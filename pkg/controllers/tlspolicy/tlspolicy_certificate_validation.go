@@ -0,0 +1,187 @@
+package tlspolicy
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	// CertificateValidated is the condition type reporting whether the certificates issued for a policy with a
+	// certificateValidation passed the validation
+	CertificateValidated               conditions.ConditionType = "CertificateValidated"
+	CertificateValidatedReason                                  = "CertificatesValidated"
+	CertificateValidationPendingReason                          = "CertificateValidationPending"
+	CertificateValidationFailedReason                           = "CertificateValidationFailed"
+
+	// trustAnchorKey is the entry of the trust anchor Secret holding the PEM encoded root certificates
+	trustAnchorKey = "ca.crt"
+)
+
+var errInvalidTrustAnchor = errors.New("invalid trust anchor")
+
+// reconcileCertificateValidation sets the CertificateValidated condition of a policy with a certificateValidation
+// from the certificates in the Secrets of its Certificates, and removes it from the other policies. The condition is
+// False, with the CertificateValidationPending reason, while some Secrets do not hold a certificate yet, and with the
+// CertificateValidationFailed reason once one of them fails the validation.
+func (r *TLSPolicyReconciler) reconcileCertificateValidation(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) error {
+	if tlsPolicy.Spec.CertificateValidation == nil || tlsPolicy.Spec.SecretRef != nil {
+		meta.RemoveStatusCondition(&tlsPolicy.Status.Conditions, string(CertificateValidated))
+		return nil
+	}
+
+	cond := metav1.Condition{
+		Type:               string(CertificateValidated),
+		Status:             metav1.ConditionFalse,
+		Reason:             CertificateValidationFailedReason,
+		ObservedGeneration: tlsPolicy.Generation,
+	}
+	roots, err := r.trustAnchors(ctx, tlsPolicy)
+	if errors.Is(err, errInvalidTrustAnchor) {
+		cond.Message = err.Error()
+		meta.SetStatusCondition(&tlsPolicy.Status.Conditions, cond)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	certs, err := r.listPolicyCertificates(ctx, tlsPolicy)
+	if err != nil {
+		return err
+	}
+	sort.Slice(certs, func(i, j int) bool {
+		return certs[i].Namespace+"/"+certs[i].Name < certs[j].Namespace+"/"+certs[j].Name
+	})
+
+	var pending, failed []string
+	for i := range certs {
+		cert := &certs[i]
+		secret := &corev1.Secret{}
+		if err := r.Client().Get(ctx, client.ObjectKey{Namespace: cert.Namespace, Name: cert.Spec.SecretName}, secret); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if !hasCertificate(secret) {
+			pending = append(pending, fmt.Sprintf("%s/%s", cert.Namespace, cert.Name))
+			continue
+		}
+		if err := validateCertificateChain(secret.Data[corev1.TLSCertKey], cert, roots, time.Now()); err != nil {
+			failed = append(failed, fmt.Sprintf("certificate %s/%s: %v", cert.Namespace, cert.Name, err))
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		cond.Message = strings.Join(failed, "; ")
+	case len(certs) == 0:
+		cond.Reason = CertificateValidationPendingReason
+		cond.Message = "waiting for the certificates of the policy to be created"
+	case len(pending) > 0:
+		cond.Reason = CertificateValidationPendingReason
+		cond.Message = fmt.Sprintf("waiting for certificates %s to be issued", strings.Join(pending, ", "))
+	default:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = CertificateValidatedReason
+		cond.Message = fmt.Sprintf("the %d certificates of the policy are valid", len(certs))
+		if roots != nil {
+			cond.Message += " and chain to a trust anchor"
+		}
+	}
+	meta.SetStatusCondition(&tlsPolicy.Status.Conditions, cond)
+	return nil
+}
+
+// trustAnchors returns the root certificates of the trust anchor Secret of the policy, nil when the policy has none.
+// A Secret that is missing or holds no certificate is an errInvalidTrustAnchor.
+func (r *TLSPolicyReconciler) trustAnchors(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) (*x509.CertPool, error) {
+	ref := tlsPolicy.Spec.CertificateValidation.TrustAnchorSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+	secretKey := client.ObjectKey{Name: ref.Name, Namespace: tlsPolicy.Namespace}
+	secret := &corev1.Secret{}
+	if err := r.Client().Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: secret %s not found", errInvalidTrustAnchor, secretKey)
+		}
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(secret.Data[trustAnchorKey]) {
+		return nil, fmt.Errorf("%w: secret %s has no PEM encoded certificate in %s", errInvalidTrustAnchor, secretKey, trustAnchorKey)
+	}
+	return roots, nil
+}
+
+// validateCertificateChain verifies the PEM encoded chain issued for the Certificate: its leaf, the first
+// certificate, must cover every dnsName of the Certificate and, when roots is set, chain to one of them through the
+// intermediates that follow it
+func validateCertificateChain(chain []byte, cert *certmanv1.Certificate, roots *x509.CertPool, now time.Time) error {
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(chain); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse the certificate chain: %w", err)
+		}
+		certs = append(certs, parsed)
+	}
+	if len(certs) == 0 {
+		return fmt.Errorf("no PEM encoded certificate in %s", corev1.TLSCertKey)
+	}
+
+	leaf := certs[0]
+	var missing []string
+	for _, dnsName := range cert.Spec.DNSNames {
+		if !containsFold(leaf.DNSNames, dnsName) {
+			missing = append(missing, dnsName)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the leaf certificate does not cover the dnsNames %s", strings.Join(missing, ", "))
+	}
+
+	if roots == nil {
+		return nil
+	}
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range certs[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("the certificate chain does not build to a trust anchor: %w", err)
+	}
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
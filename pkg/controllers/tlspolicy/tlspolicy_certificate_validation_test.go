@@ -0,0 +1,227 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+// testChain holds a root, intermediate and leaf certificate, the leaf is signed by the intermediate
+type testChain struct {
+	root, intermediate, leaf []byte
+}
+
+func newTestChain(t *testing.T, dnsNames ...string) testChain {
+	t.Helper()
+	issue := func(template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	template := func(serial int64, name string, ca bool) *x509.Certificate {
+		cert := &x509.Certificate{
+			SerialNumber:          big.NewInt(serial),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			BasicConstraintsValid: true,
+			IsCA:                  ca,
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+		}
+		if ca {
+			cert.KeyUsage |= x509.KeyUsageCertSign
+		}
+		return cert
+	}
+
+	root, rootKey, rootPEM := issue(template(1, "test root", true), nil, nil)
+	intermediate, intermediateKey, intermediatePEM := issue(template(2, "test intermediate", true), root, rootKey)
+	leafTemplate := template(3, "test leaf", false)
+	leafTemplate.DNSNames = dnsNames
+	_, _, leafPEM := issue(leafTemplate, intermediate, intermediateKey)
+	return testChain{root: rootPEM, intermediate: intermediatePEM, leaf: leafPEM}
+}
+
+func TestValidateCertificateChain(t *testing.T) {
+	chain := newTestChain(t, "api.example.com", "www.example.com")
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(chain.root)
+	otherRoots := x509.NewCertPool()
+	otherRoots.AppendCertsFromPEM(newTestChain(t, "api.example.com").root)
+
+	testCases := []struct {
+		name     string
+		chain    []byte
+		dnsNames []string
+		roots    *x509.CertPool
+		wantErr  bool
+	}{
+		{
+			name:     "complete chain",
+			chain:    append(append([]byte{}, chain.leaf...), chain.intermediate...),
+			dnsNames: []string{"api.example.com", "WWW.example.com"},
+			roots:    roots,
+		},
+		{
+			name:     "incomplete chain",
+			chain:    chain.leaf,
+			dnsNames: []string{"api.example.com"},
+			roots:    roots,
+			wantErr:  true,
+		},
+		{
+			name:     "incomplete chain without trust anchor",
+			chain:    chain.leaf,
+			dnsNames: []string{"api.example.com"},
+		},
+		{
+			name:     "chain to another trust anchor",
+			chain:    append(append([]byte{}, chain.leaf...), chain.intermediate...),
+			dnsNames: []string{"api.example.com"},
+			roots:    otherRoots,
+			wantErr:  true,
+		},
+		{
+			name:     "leaf not covering a dnsName",
+			chain:    chain.leaf,
+			dnsNames: []string{"api.example.com", "other.example.com"},
+			wantErr:  true,
+		},
+		{
+			name:    "no certificate",
+			chain:   []byte("not a certificate"),
+			wantErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cert := &certmanv1.Certificate{Spec: certmanv1.CertificateSpec{DNSNames: testCase.dnsNames}}
+			err := validateCertificateChain(testCase.chain, cert, testCase.roots, time.Now())
+			if testCase.wantErr != (err != nil) {
+				t.Errorf("expected error %v, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTLSPolicyReconciler_reconcileCertificateValidation(t *testing.T) {
+	chain := newTestChain(t, "api.example.com")
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "blue").
+		WithTargetGateway("prod-web").
+		WithIssuer("letsencrypt", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Spec.CertificateValidation = &v1alpha1.CertificateValidation{
+		TrustAnchorSecretRef: &corev1.LocalObjectReference{Name: "trust-anchor"},
+	}
+	if err := tlsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+	cert := &certmanv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prod-web-api-tls",
+			Namespace: "blue",
+			Labels: map[string]string{
+				TLSPolicyBackRefAnnotation:                              tlsPolicy.Name,
+				fmt.Sprintf("%s-namespace", TLSPolicyBackRefAnnotation): tlsPolicy.Namespace,
+			},
+		},
+		Spec: certmanv1.CertificateSpec{SecretName: "prod-web-api-tls", DNSNames: []string{"api.example.com"}},
+	}
+	trustAnchor := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "trust-anchor", Namespace: "blue"},
+		Data:       map[string][]byte{trustAnchorKey: chain.root},
+	}
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tlsPolicy, cert, trustAnchor).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	reconcile := func() *metav1.Condition {
+		t.Helper()
+		if err := r.reconcileCertificateValidation(ctx, tlsPolicy); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(CertificateValidated))
+	}
+
+	if cond := reconcile(); cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != CertificateValidationPendingReason {
+		t.Fatalf("expected the validation to be pending until the secret is issued, got %v", cond)
+	}
+
+	// an issuer omitting the intermediate fails the validation
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-web-api-tls", Namespace: "blue"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       chain.leaf,
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+	if err := f.Create(ctx, secret); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cond := reconcile(); cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != CertificateValidationFailedReason {
+		t.Fatalf("expected the validation of an incomplete chain to fail, got %v", cond)
+	}
+
+	secret.Data[corev1.TLSCertKey] = append(append([]byte{}, chain.leaf...), chain.intermediate...)
+	if err := f.Update(ctx, secret); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cond := reconcile(); cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != CertificateValidatedReason {
+		t.Fatalf("expected a complete chain to be validated, got %v", cond)
+	}
+
+	// the trust anchor Secret events are mapped to the policy
+	if err := f.Update(ctx, tlsPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if requests := r.policiesForSecret(trustAnchor); len(requests) != 1 || requests[0].Name != tlsPolicy.Name {
+		t.Errorf("expected a request for the policy, got %v", requests)
+	}
+
+	tlsPolicy.Spec.CertificateValidation = nil
+	if cond := reconcile(); cond != nil {
+		t.Errorf("expected the condition to be removed without a certificate validation, got %v", cond)
+	}
+}
@@ -2,67 +2,155 @@ package tlspolicy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
 	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 )
 
-func (r *TLSPolicyReconciler) reconcileCertificates(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, gwDiffObj *reconcilers.GatewayDiff) error {
+// maxCommonNameLength is the maximum length of a Certificate commonName, see https://tools.ietf.org/html/rfc5280
+const maxCommonNameLength = 64
+
+// reconcileCertificates reconciles the Certificates of the gateways of the diff and returns the certificate status of
+// their listeners. A Certificate failing to reconcile fails the status of its listeners, the other Certificates are
+// still reconciled.
+func (r *TLSPolicyReconciler) reconcileCertificates(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiff) ([]v1alpha1.TLSPolicyListenerStatus, error) {
 	log := crlog.FromContext(ctx)
 
+	// when the policy targets an HTTPRoute, certificates are only issued for the route hostnames
+	route, _ := targetNetworkObject.(*gatewayv1beta1.HTTPRoute)
+
 	for _, gw := range gwDiffObj.GatewaysWithInvalidPolicyRef {
 		log.V(1).Info("reconcileCertificates: gateway with invalid policy ref", "key", gw.Key())
 		if err := r.deleteGatewayCertificates(ctx, gw.Gateway, tlsPolicy); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	// Reconcile Certificates for each gateway directly referred by the policy (existing and new)
+	var listeners []v1alpha1.TLSPolicyListenerStatus
+	var failed []error
+	sharedInUse := sets.New[sharedCertificateUse]()
 	for _, gw := range append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...) {
 		log.V(1).Info("reconcileCertificates: gateway with valid and missing policy ref", "key", gw.Key())
-		if err := r.reconcileGatewayCertificates(ctx, gw.Gateway, tlsPolicy); err != nil {
-			return err
+		gatewayListeners, err := r.reconcileGatewayCertificates(ctx, gw.Gateway, route, tlsPolicy, sharedInUse)
+		var certsErr *listenerCertificatesError
+		if errors.As(err, &certsErr) {
+			failed = append(failed, certsErr.errs...)
+		} else if err != nil {
+			return nil, err
 		}
+		listeners = append(listeners, gatewayListeners...)
 	}
 
-	return nil
+	if err := r.releaseSharedCertificates(ctx, tlsPolicy, sharedInUse); err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		return listeners, &listenerCertificatesError{errs: failed}
+	}
+	return listeners, nil
 }
 
-func (r *TLSPolicyReconciler) reconcileGatewayCertificates(ctx context.Context, gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy) error {
+// reconcileGatewayCertificates reconciles the Certificates of the gateway and returns the certificate status of its
+// listeners, the shared Certificates used by the gateway are added to sharedInUse. The errors of the Certificates
+// failing to reconcile are returned as a listenerCertificatesError along with the status.
+func (r *TLSPolicyReconciler) reconcileGatewayCertificates(ctx context.Context, gateway *gatewayv1beta1.Gateway, route *gatewayv1beta1.HTTPRoute, tlsPolicy *v1alpha1.TLSPolicy, sharedInUse sets.Set[sharedCertificateUse]) ([]v1alpha1.TLSPolicyListenerStatus, error) {
 	log := crlog.FromContext(ctx)
 
 	log.V(1).Info("reconcileGatewayCertificates", "tlsPolicy", tlsPolicy)
 
-	expectedCerts := r.expectedCertificatesForGateway(ctx, gateway, tlsPolicy)
+	// the listeners use the existing secret, any Certificates previously created by the policy are removed
+	if tlsPolicy.Spec.SecretRef != nil {
+		return nil, r.deleteGatewayCertificates(ctx, gateway, tlsPolicy)
+	}
+
+	expectedCerts := r.expectedCertificatesForGateway(ctx, gateway, route, tlsPolicy)
+
+	// the failed Certificates are kept as expected, so that a previously issued Certificate is not deleted
+	failed := map[client.ObjectKey]error{}
+	for _, cert := range expectedCerts {
+		if err := validateSolverZone(ctx, r.Client(), tlsPolicy, cert.Spec.DNSNames); err != nil {
+			failed[client.ObjectKeyFromObject(cert)] = err
+			continue
+		}
+		if err := r.validateCertificateOwner(ctx, cert, tlsPolicy); err != nil {
+			failed[client.ObjectKeyFromObject(cert)] = err
+		}
+	}
 
 	if err := r.deleteUnexpectedGatewayCertificates(ctx, expectedCerts, gateway, tlsPolicy); err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, cert := range expectedCerts {
-		err := r.ReconcileResource(ctx, &certmanv1.Certificate{}, cert, alwaysUpdateCertificate)
+		if _, ok := failed[client.ObjectKeyFromObject(cert)]; ok {
+			continue
+		}
+		mutateFn := alwaysUpdateCertificate
+		if isSharedCertificate(cert) {
+			user := sharedCertificateUser{policy: client.ObjectKeyFromObject(tlsPolicy), gateway: client.ObjectKeyFromObject(gateway)}
+			cert.Annotations[TLSPolicySharedCertificateUsersAnnotation] = user.String()
+			mutateFn = addSharedCertificateUser(user)
+			sharedInUse.Insert(sharedCertificateUse{certificate: client.ObjectKeyFromObject(cert), user: user})
+		}
+		// the mutate func is only called for existing Certificates, so the operation is a create unless it runs
+		operation := operationCreate
+		err := r.ReconcileResource(ctx, &certmanv1.Certificate{}, cert, func(existingObj, desiredObj client.Object) (bool, error) {
+			update, err := mutateFn(existingObj, desiredObj)
+			operation = ""
+			if update {
+				operation = operationUpdate
+			}
+			return update, err
+		})
 		if err != nil && !apierrors.IsAlreadyExists(err) {
 			log.Error(err, "failed to reconcile Certificate resource")
-			return err
+			r.EventRecorder().Eventf(gateway, corev1.EventTypeWarning, EventReasonCertificateCreationFailed,
+				"failed to reconcile Certificate %s/%s for TLSPolicy %s/%s: %v", cert.Namespace, cert.Name, tlsPolicy.Namespace, tlsPolicy.Name, err)
+			failed[client.ObjectKeyFromObject(cert)] = err
+			continue
+		}
+		if err == nil && operation != "" {
+			certificateOperationTotal.WithLabelValues(operation).Inc()
 		}
 	}
 
-	return nil
+	listeners, err := r.gatewayListenerStatuses(ctx, gateway, tlsPolicy, expectedCerts, failed)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for _, cert := range expectedCerts {
+		if err, ok := failed[client.ObjectKeyFromObject(cert)]; ok {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return listeners, &listenerCertificatesError{errs: errs}
+	}
+	return listeners, nil
 }
 
 func (r *TLSPolicyReconciler) deleteGatewayCertificates(ctx context.Context, gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy) error {
@@ -86,23 +174,79 @@ func (r *TLSPolicyReconciler) deleteUnexpectedGatewayCertificates(ctx context.Co
 				log.Error(err, "failed to delete Certificate resource")
 				return err
 			}
+			certificateOperationTotal.WithLabelValues(operationDelete).Inc()
 		}
 	}
 
 	return nil
 }
 
-func (r *TLSPolicyReconciler) expectedCertificatesForGateway(ctx context.Context, gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy) []*certmanv1.Certificate {
+// reconcileCertificateMetrics updates the managed Certificates gauge of the policy and the expiry gauge across the
+// Certificates of all policies.
+func (r *TLSPolicyReconciler) reconcileCertificateMetrics(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) error {
+	policyCerts := &certmanv1.CertificateList{}
+	if err := r.Client().List(ctx, policyCerts, client.MatchingLabels{
+		TLSPolicyBackRefAnnotation:                              tlsPolicy.Name,
+		fmt.Sprintf("%s-namespace", TLSPolicyBackRefAnnotation): tlsPolicy.Namespace,
+	}); err != nil {
+		return err
+	}
+	if tlsPolicy.GetDeletionTimestamp() != nil {
+		policyCertificates.DeleteLabelValues(tlsPolicy.Name, tlsPolicy.Namespace)
+		policyCertificatesExpiringSoon.DeleteLabelValues(tlsPolicy.Name, tlsPolicy.Namespace)
+	} else {
+		policyCertificates.WithLabelValues(tlsPolicy.Name, tlsPolicy.Namespace).Set(float64(len(policyCerts.Items)))
+	}
+
+	managedCerts := &certmanv1.CertificateList{}
+	if err := r.Client().List(ctx, managedCerts, client.HasLabels{TLSPolicyBackRefAnnotation}); err != nil {
+		return err
+	}
+	observeCertificateExpiry(managedCerts.Items, time.Now())
+	return nil
+}
+
+// validateCertificateOwner returns an error if the certificate already exists and was created by a different TLSPolicy,
+// this happens when two policies target overlapping hostnames that share a listener certificateRef, or for a
+// different Gateway, when the Gateways selected by a policy share a listener certificateRef.
+func (r *TLSPolicyReconciler) validateCertificateOwner(ctx context.Context, cert *certmanv1.Certificate, tlsPolicy *v1alpha1.TLSPolicy) error {
+	existing := &certmanv1.Certificate{}
+	if err := r.Client().Get(ctx, client.ObjectKeyFromObject(cert), existing); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	ownerName, ok := existing.Labels[TLSPolicyBackRefAnnotation]
+	if !ok {
+		return nil
+	}
+	ownerNamespace := existing.Labels[fmt.Sprintf("%s-namespace", TLSPolicyBackRefAnnotation)]
+	if ownerName != tlsPolicy.Name || ownerNamespace != tlsPolicy.Namespace {
+		return fmt.Errorf("%w: certificate %s/%s is managed by TLSPolicy %s/%s", ErrCertificateConflict, existing.Namespace, existing.Name, ownerNamespace, ownerName)
+	}
+	if existing.Labels["gateway"] != cert.Labels["gateway"] || existing.Labels["gateway-namespace"] != cert.Labels["gateway-namespace"] {
+		return fmt.Errorf("%w: certificate %s/%s is managed for Gateway %s/%s", ErrCertificateConflict, existing.Namespace, existing.Name, existing.Labels["gateway-namespace"], existing.Labels["gateway"])
+	}
+	return nil
+}
+
+func (r *TLSPolicyReconciler) expectedCertificatesForGateway(ctx context.Context, gateway *gatewayv1beta1.Gateway, route *gatewayv1beta1.HTTPRoute, tlsPolicy *v1alpha1.TLSPolicy) []*certmanv1.Certificate {
 	log := crlog.FromContext(ctx)
 
 	tlsHosts := make(map[corev1.ObjectReference][]string)
 	for i, l := range gateway.Spec.Listeners {
-		err := validateGatewayListenerBlock(field.NewPath("spec", "listeners").Index(i), l, gateway).ToAggregate()
+		if !listenerInSection(gateway, string(l.Name), tlsPolicy) {
+			continue
+		}
+		err := validateGatewayListenerBlock(field.NewPath("spec", "listeners").Index(i), l, gateway, r.listenerSecretNamespace(tlsPolicy)).ToAggregate()
 		if err != nil {
 			log.Info("Skipped a listener block: " + err.Error())
 			continue
 		}
 
+		hostnames := []string{string(*l.Hostname)}
+		if route != nil {
+			hostnames = routeHostnamesForListener(route, gateway, l)
+		}
+
 		for _, certRef := range l.TLS.CertificateRefs {
 			secretRef := corev1.ObjectReference{
 				Name: string(certRef.Name),
@@ -113,8 +257,23 @@ func (r *TLSPolicyReconciler) expectedCertificatesForGateway(ctx context.Context
 				secretRef.Namespace = gateway.GetNamespace()
 			}
 			// Gateway API hostname explicitly disallows IP addresses, so this
-			// should be OK.
-			tlsHosts[secretRef] = append(tlsHosts[secretRef], string(*l.Hostname))
+			// should be OK. Listeners sharing a secret (e.g. a wildcard and its apex) end up in the same Certificate.
+			for _, hostname := range hostnames {
+				if !slice.ContainsString(tlsHosts[secretRef], hostname) {
+					tlsHosts[secretRef] = append(tlsHosts[secretRef], hostname)
+				}
+			}
+		}
+	}
+
+	// the listeners sharing a consolidated Secret are served by a single wildcard Certificate
+	if parent, _ := wildcardConsolidation(gateway, tlsPolicy); parent != "" {
+		secretKey, err := listenerSecretKey(gateway, wildcardListenerName, "*."+parent, tlsPolicy, r.sharedCertificateNamespace(tlsPolicy))
+		secretRef := corev1.ObjectReference{Name: secretKey.Name, Namespace: secretKey.Namespace}
+		if err != nil {
+			log.Info("Skipped wildcard consolidation: " + err.Error())
+		} else if len(tlsHosts[secretRef]) > 0 {
+			tlsHosts[secretRef] = []string{"*." + parent}
 		}
 	}
 
@@ -125,14 +284,43 @@ func (r *TLSPolicyReconciler) expectedCertificatesForGateway(ctx context.Context
 	return certs
 }
 
+// buildCertManagerCertificate builds the Certificate of the gateway for the Secret, a shared Certificate when the
+// Secret is in the shared namespace of the policy
 func (r *TLSPolicyReconciler) buildCertManagerCertificate(gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy, secretRef corev1.ObjectReference, hosts []string) *certmanv1.Certificate {
-	tlsCertLabels := tlsCertificateLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(tlsPolicy))
+	if sharedNamespace := r.sharedCertificateNamespace(tlsPolicy); sharedNamespace != "" && secretRef.Namespace == sharedNamespace && secretRef.Namespace != gateway.Namespace {
+		return buildCertificate(tlsPolicy, secretRef, hosts, sharedCertificateLabels())
+	}
+	return buildCertificate(tlsPolicy, secretRef, hosts, tlsCertificateLabels(client.ObjectKeyFromObject(gateway), client.ObjectKeyFromObject(tlsPolicy)))
+}
+
+// buildCertificate builds the Certificate of the policy for the Secret, the controller labels are set on both the
+// Certificate and its Secret
+func buildCertificate(tlsPolicy *v1alpha1.TLSPolicy, secretRef corev1.ObjectReference, hosts []string, tlsCertLabels map[string]string) *certmanv1.Certificate {
+	crtLabels := map[string]string{}
+	for k, v := range tlsPolicy.Spec.CertificateLabels {
+		crtLabels[k] = v
+	}
+	// controller set labels always take precedence over the ones from the policy
+	for k, v := range tlsCertLabels {
+		crtLabels[k] = v
+	}
+	if tlsPolicy.Spec.SolverRef != nil {
+		crtLabels[TLSPolicySolverZoneLabel] = tlsPolicy.Spec.SolverRef.Name
+	}
+
+	crtAnnotations := map[string]string{}
+	for k, v := range tlsPolicy.Spec.CertificateAnnotations {
+		crtAnnotations[k] = v
+	}
+	crtAnnotations[TLSPolicyCertificateLabelsAnnotation] = strings.Join(sortedKeys(crtLabels), ",")
+	crtAnnotations[TLSPolicyCertificateAnnotationsAnnotation] = strings.Join(sortedKeys(tlsPolicy.Spec.CertificateAnnotations), ",")
 
 	crt := &certmanv1.Certificate{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretRef.Name,
-			Namespace: secretRef.Namespace,
-			Labels:    tlsCertLabels,
+			Name:        secretRef.Name,
+			Namespace:   secretRef.Namespace,
+			Labels:      crtLabels,
+			Annotations: crtAnnotations,
 		},
 		Spec: certmanv1.CertificateSpec{
 			DNSNames:   hosts,
@@ -140,14 +328,63 @@ func (r *TLSPolicyReconciler) buildCertManagerCertificate(gateway *gatewayv1beta
 			SecretTemplate: &certmanv1.CertificateSecretTemplate{
 				Labels: tlsCertLabels,
 			},
-			IssuerRef: tlsPolicy.Spec.IssuerRef,
-			Usages:    certmanv1.DefaultKeyUsages(),
+			IssuerRef: certificateIssuerRef(tlsPolicy, hosts),
+			Usages:    defaultCertificateUsages(),
 		},
 	}
+	if !tlsPolicy.Spec.OmitCommonName {
+		crt.Spec.CommonName = defaultCommonName(hosts)
+	}
 	translatePolicy(crt, tlsPolicy.Spec)
 	return crt
 }
 
+// defaultCertificateUsages returns the usages of the Certificates of a policy that does not set any, the cert-manager
+// default usages with server auth, which the Certificates of a gateway are used for
+func defaultCertificateUsages() []certmanv1.KeyUsage {
+	return append(certmanv1.DefaultKeyUsages(), certmanv1.UsageServerAuth)
+}
+
+// defaultCommonName returns the primary host of a Certificate, used as its commonName for the clients that still
+// require one. An empty commonName is returned when the host is longer than the maximum length of a commonName.
+func defaultCommonName(hosts []string) string {
+	if len(hosts) == 0 || len(hosts[0]) > maxCommonNameLength {
+		return ""
+	}
+	return hosts[0]
+}
+
+// routeHostnamesForListener returns the hostnames of the route that can be served by the gateway listener. An empty
+// slice is returned when the route is not attached to the listener.
+func routeHostnamesForListener(route *gatewayv1beta1.HTTPRoute, gateway *gatewayv1beta1.Gateway, l gatewayv1beta1.Listener) []string {
+	if !slice.Contains(route.Spec.ParentRefs, func(parentRef gatewayv1beta1.ParentReference) bool {
+		parentNamespace := route.Namespace
+		if parentRef.Namespace != nil {
+			parentNamespace = string(*parentRef.Namespace)
+		}
+		return string(parentRef.Name) == gateway.Name && parentNamespace == gateway.Namespace &&
+			(parentRef.SectionName == nil || *parentRef.SectionName == l.Name)
+	}) {
+		return []string{}
+	}
+
+	listenerHost := common.Name(*l.Hostname)
+	if len(route.Spec.Hostnames) == 0 {
+		return []string{listenerHost.String()}
+	}
+
+	var hostnames []string
+	for _, h := range route.Spec.Hostnames {
+		routeHost := common.Name(h)
+		if routeHost.SubsetOf(listenerHost) {
+			hostnames = append(hostnames, routeHost.String())
+		} else if listenerHost.SubsetOf(routeHost) {
+			hostnames = append(hostnames, listenerHost.String())
+		}
+	}
+	return hostnames
+}
+
 func tlsCertificateLabels(gwKey, apKey client.ObjectKey) map[string]string {
 	return map[string]string{
 		TLSPolicyBackRefAnnotation:                              apKey.Name,
@@ -167,10 +404,53 @@ func alwaysUpdateCertificate(existingObj, desiredObj client.Object) (bool, error
 		return false, fmt.Errorf("%T is not an *certmanv1.Certificate", desiredObj)
 	}
 
-	if reflect.DeepEqual(existing.Spec, desired.Spec) {
-		return false, nil
+	update := false
+
+	if !reflect.DeepEqual(existing.Spec, desired.Spec) {
+		existing.Spec = desired.Spec
+		update = true
+	}
+
+	// labels and annotations that were previously set by the controller, but are no longer desired, are removed.
+	// Any set by other means are left untouched.
+	previousLabels := existing.Annotations[TLSPolicyCertificateLabelsAnnotation]
+	previousAnnotations := existing.Annotations[TLSPolicyCertificateAnnotationsAnnotation]
+	mergedLabels, labelsChanged := mergeManagedMetadata(existing.Labels, desired.Labels, previousLabels)
+	mergedAnnotations, annotationsChanged := mergeManagedMetadata(existing.Annotations, desired.Annotations, previousAnnotations)
+	if labelsChanged || annotationsChanged {
+		existing.Labels = mergedLabels
+		existing.Annotations = mergedAnnotations
+		update = true
 	}
-	existing.Spec = desired.Spec
 
-	return true, nil
+	return update, nil
+}
+
+// mergeManagedMetadata sets all desired keys on existing and removes keys listed in previousKeys (comma separated)
+// that are not desired. It returns the resulting map and whether it differs from existing.
+func mergeManagedMetadata(existing, desired map[string]string, previousKeys string) (map[string]string, bool) {
+	merged := map[string]string{}
+	for k, v := range existing {
+		merged[k] = v
+	}
+	if previousKeys != "" {
+		for _, k := range strings.Split(previousKeys, ",") {
+			if _, ok := desired[k]; !ok {
+				delete(merged, k)
+			}
+		}
+	}
+	for k, v := range desired {
+		merged[k] = v
+	}
+	return merged, !equality.Semantic.DeepEqual(existing, merged)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
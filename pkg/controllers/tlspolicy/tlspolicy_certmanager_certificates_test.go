@@ -0,0 +1,335 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestBuildCertManagerCertificate_commonName(t *testing.T) {
+	longHost := strings.Repeat("a", 60) + ".example.com"
+
+	testCases := []struct {
+		name           string
+		spec           v1alpha1.TLSPolicySpec
+		hosts          []string
+		wantCommonName string
+	}{
+		{
+			name:           "defaults to a plain host",
+			hosts:          []string{"api.example.com"},
+			wantCommonName: "api.example.com",
+		},
+		{
+			name:           "defaults to a wildcard host",
+			hosts:          []string{"*.example.com"},
+			wantCommonName: "*.example.com",
+		},
+		{
+			name:           "defaults to the primary host",
+			hosts:          []string{"*.example.com", "example.com"},
+			wantCommonName: "*.example.com",
+		},
+		{
+			name:  "is not set for a host longer than allowed",
+			hosts: []string{longHost},
+		},
+		{
+			name:  "is not set when omitted",
+			spec:  v1alpha1.TLSPolicySpec{CertificateSpec: v1alpha1.CertificateSpec{OmitCommonName: true}},
+			hosts: []string{"api.example.com"},
+		},
+		{
+			name:           "is taken from the policy when set",
+			spec:           v1alpha1.TLSPolicySpec{CertificateSpec: v1alpha1.CertificateSpec{CommonName: "legacy.example.com"}},
+			hosts:          []string{"api.example.com"},
+			wantCommonName: "legacy.example.com",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			gateway := &gatewayv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test"},
+			}
+			tlsPolicy := &v1alpha1.TLSPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-tls-policy", Namespace: "test"},
+				Spec:       testCase.spec,
+			}
+
+			r := &TLSPolicyReconciler{}
+			crt := r.buildCertManagerCertificate(gateway, tlsPolicy, corev1.ObjectReference{Name: "gw-tls", Namespace: "test"}, testCase.hosts)
+			if crt.Spec.CommonName != testCase.wantCommonName {
+				t.Errorf("buildCertManagerCertificate() commonName = %q, want %q", crt.Spec.CommonName, testCase.wantCommonName)
+			}
+		})
+	}
+}
+
+func TestBuildCertManagerCertificate_usages(t *testing.T) {
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test"},
+	}
+	testCases := []struct {
+		name       string
+		usages     []certmanv1.KeyUsage
+		wantUsages []certmanv1.KeyUsage
+	}{
+		{
+			name:       "defaults to server auth usages",
+			wantUsages: []certmanv1.KeyUsage{certmanv1.UsageDigitalSignature, certmanv1.UsageKeyEncipherment, certmanv1.UsageServerAuth},
+		},
+		{
+			name:       "requests the client auth extended key usage",
+			usages:     []certmanv1.KeyUsage{certmanv1.UsageDigitalSignature, certmanv1.UsageKeyEncipherment, certmanv1.UsageServerAuth, certmanv1.UsageClientAuth},
+			wantUsages: []certmanv1.KeyUsage{certmanv1.UsageDigitalSignature, certmanv1.UsageKeyEncipherment, certmanv1.UsageServerAuth, certmanv1.UsageClientAuth},
+		},
+		{
+			name:       "empty usages use the defaults",
+			usages:     []certmanv1.KeyUsage{},
+			wantUsages: []certmanv1.KeyUsage{certmanv1.UsageDigitalSignature, certmanv1.UsageKeyEncipherment, certmanv1.UsageServerAuth},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			tlsPolicy := &v1alpha1.TLSPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-tls-policy", Namespace: "test"},
+				Spec: v1alpha1.TLSPolicySpec{
+					CertificateSpec: v1alpha1.CertificateSpec{Usages: testCase.usages},
+				},
+			}
+			r := &TLSPolicyReconciler{}
+			crt := r.buildCertManagerCertificate(gateway, tlsPolicy, corev1.ObjectReference{Name: "gw-tls", Namespace: "test"}, []string{"api.example.com"})
+			if !reflect.DeepEqual(crt.Spec.Usages, testCase.wantUsages) {
+				t.Errorf("buildCertManagerCertificate() usages = %v, want %v", crt.Spec.Usages, testCase.wantUsages)
+			}
+		})
+	}
+}
+
+func TestBuildCertManagerCertificate_secretTemplate(t *testing.T) {
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test"},
+	}
+	tlsPolicy := &v1alpha1.TLSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tls-policy", Namespace: "test"},
+		Spec: v1alpha1.TLSPolicySpec{
+			CertificateSpec: v1alpha1.CertificateSpec{
+				SecretTemplate: &certmanv1.CertificateSecretTemplate{
+					Labels: map[string]string{
+						"istio.io/managed": "true",
+						"gateway":          "not-allowed",
+					},
+					Annotations: map[string]string{"example.com/owner": "platform"},
+				},
+			},
+		},
+	}
+
+	r := &TLSPolicyReconciler{}
+	secretRef := corev1.ObjectReference{Name: "gw-tls", Namespace: "test"}
+	crt := r.buildCertManagerCertificate(gateway, tlsPolicy, secretRef, []string{"api.example.com"})
+
+	wantLabels := map[string]string{
+		"istio.io/managed":         "true",
+		"gateway":                  "gw",
+		"gateway-namespace":        "test",
+		TLSPolicyBackRefAnnotation: "test-tls-policy",
+		TLSPolicyBackRefAnnotation + "-namespace": "test",
+	}
+	if !reflect.DeepEqual(crt.Spec.SecretTemplate.Labels, wantLabels) {
+		t.Errorf("buildCertManagerCertificate() secretTemplate labels = %v, want %v", crt.Spec.SecretTemplate.Labels, wantLabels)
+	}
+	if crt.Spec.SecretTemplate.Annotations["example.com/owner"] != "platform" {
+		t.Errorf("buildCertManagerCertificate() secretTemplate annotations = %v, want example.com/owner", crt.Spec.SecretTemplate.Annotations)
+	}
+
+	// removing the label from the policy removes it from the existing Certificate template
+	tlsPolicy.Spec.SecretTemplate.Labels = nil
+	desired := r.buildCertManagerCertificate(gateway, tlsPolicy, secretRef, []string{"api.example.com"})
+	update, err := alwaysUpdateCertificate(crt, desired)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !update {
+		t.Fatalf("expected the Certificate to be updated")
+	}
+	if _, ok := crt.Spec.SecretTemplate.Labels["istio.io/managed"]; ok {
+		t.Errorf("expected the istio.io/managed label to be removed from the secretTemplate, got %v", crt.Spec.SecretTemplate.Labels)
+	}
+	if crt.Spec.SecretTemplate.Labels["gateway"] != "gw" {
+		t.Errorf("expected the controller labels to be kept on the secretTemplate, got %v", crt.Spec.SecretTemplate.Labels)
+	}
+}
+
+func TestBuildCertManagerCertificate_rotation(t *testing.T) {
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test"},
+	}
+	revisionHistoryLimit := int32(2)
+	tlsPolicy := &v1alpha1.TLSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tls-policy", Namespace: "test"},
+		Spec: v1alpha1.TLSPolicySpec{
+			CertificateSpec: v1alpha1.CertificateSpec{
+				RevisionHistoryLimit: &revisionHistoryLimit,
+				PrivateKey: &certmanv1.CertificatePrivateKey{
+					RotationPolicy: certmanv1.RotationPolicyAlways,
+				},
+			},
+		},
+	}
+
+	r := &TLSPolicyReconciler{}
+	secretRef := corev1.ObjectReference{Name: "gw-tls", Namespace: "test"}
+	crt := r.buildCertManagerCertificate(gateway, tlsPolicy, secretRef, []string{"api.example.com"})
+	if crt.Spec.PrivateKey == nil || crt.Spec.PrivateKey.RotationPolicy != certmanv1.RotationPolicyAlways {
+		t.Errorf("buildCertManagerCertificate() privateKey = %+v, want rotationPolicy %s", crt.Spec.PrivateKey, certmanv1.RotationPolicyAlways)
+	}
+	if crt.Spec.RevisionHistoryLimit == nil || *crt.Spec.RevisionHistoryLimit != 2 {
+		t.Errorf("buildCertManagerCertificate() revisionHistoryLimit = %v, want 2", crt.Spec.RevisionHistoryLimit)
+	}
+
+	// unsetting the fields in the policy patches the existing Certificate back to the cert-manager defaults
+	tlsPolicy.Spec.RevisionHistoryLimit = nil
+	tlsPolicy.Spec.PrivateKey = nil
+	desired := r.buildCertManagerCertificate(gateway, tlsPolicy, secretRef, []string{"api.example.com"})
+	update, err := alwaysUpdateCertificate(crt, desired)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !update {
+		t.Fatalf("expected the Certificate to be updated")
+	}
+	if crt.Spec.RevisionHistoryLimit != nil || crt.Spec.PrivateKey != nil {
+		t.Errorf("expected the revisionHistoryLimit and privateKey to be removed, got %v and %+v", crt.Spec.RevisionHistoryLimit, crt.Spec.PrivateKey)
+	}
+}
+
+func TestBuildCertManagerCertificate_additionalOutputFormats(t *testing.T) {
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test"},
+	}
+	tlsPolicy := &v1alpha1.TLSPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tls-policy", Namespace: "test"},
+		Spec: v1alpha1.TLSPolicySpec{
+			CertificateSpec: v1alpha1.CertificateSpec{
+				IssuerRef: cmmeta.ObjectReference{Name: "letsencrypt"},
+				AdditionalOutputFormats: []certmanv1.CertificateAdditionalOutputFormat{
+					{Type: certmanv1.CertificateOutputFormatCombinedPEM},
+				},
+			},
+		},
+	}
+	if err := tlsPolicy.Spec.CertificateSpec.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+
+	r := &TLSPolicyReconciler{}
+	crt := r.buildCertManagerCertificate(gateway, tlsPolicy, corev1.ObjectReference{Name: "gw-tls", Namespace: "test"}, []string{"api.example.com"})
+	want := []certmanv1.CertificateAdditionalOutputFormat{{Type: certmanv1.CertificateOutputFormatCombinedPEM}}
+	if !reflect.DeepEqual(crt.Spec.AdditionalOutputFormats, want) {
+		t.Errorf("buildCertManagerCertificate() additionalOutputFormats = %v, want %v", crt.Spec.AdditionalOutputFormats, want)
+	}
+
+	// unsupported and repeated formats are rejected
+	for _, formats := range [][]certmanv1.CertificateAdditionalOutputFormat{
+		{{Type: "PKCS12"}},
+		{{Type: certmanv1.CertificateOutputFormatDER}, {Type: certmanv1.CertificateOutputFormatDER}},
+	} {
+		tlsPolicy.Spec.AdditionalOutputFormats = formats
+		if err := tlsPolicy.Spec.CertificateSpec.Validate(); err == nil {
+			t.Errorf("expected the additionalOutputFormats %v to be rejected", formats)
+		}
+	}
+}
+
+func TestTLSPolicyReconciler_removedListenerCertificates(t *testing.T) {
+	// www and api share the Secret, and so the Certificate, of api
+	gateway := testutil.NewTestGateway("prod-web", "istio", "test").
+		WithHTTPSListener("api.example.com", "api-tls").
+		WithHTTPSListener("www.example.com", "api-tls").
+		WithHTTPSListener("admin.example.com", "admin-tls").Gateway
+	// the group and kind of the references are defaulted by the API server
+	for _, l := range gateway.Spec.Listeners {
+		l.TLS.CertificateRefs[0].Group = testutil.Pointer(gatewayv1beta1.Group(""))
+		l.TLS.CertificateRefs[0].Kind = testutil.Pointer(gatewayv1beta1.Kind("Secret"))
+	}
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "test").
+		WithTargetGateway("prod-web").
+		WithIssuer("letsencrypt", certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway, tlsPolicy).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	// removeListener removes the named listener from the gateway and returns the names of the Certificates left after
+	// reconciling the policy
+	removeListener := func(listenerName string) []string {
+		t.Helper()
+		gw := &gatewayv1beta1.Gateway{}
+		if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		gw.Spec.Listeners = slice.Filter(gw.Spec.Listeners, func(l gatewayv1beta1.Listener) bool {
+			return string(l.Name) != listenerName
+		})
+		if err := f.Update(ctx, gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		wrapper := common.GatewayWrapper{Gateway: gw, PolicyRefsConfig: &TLSPolicyRefsConfig{}}
+		gwDiff := &reconcilers.GatewayDiff{GatewaysWithValidPolicyRef: []common.GatewayWrapper{wrapper}}
+		if _, err := r.reconcileCertificates(ctx, tlsPolicy, gw, gwDiff); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		certList := &certmanv1.CertificateList{}
+		if err := f.List(ctx, certList); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		var names []string
+		for _, cert := range certList.Items {
+			names = append(names, cert.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	if names := removeListener(""); !reflect.DeepEqual(names, []string{"admin-tls", "api-tls"}) {
+		t.Fatalf("expected a Certificate for each listener Secret, got %v", names)
+	}
+	if names := removeListener("admin.example.com"); !reflect.DeepEqual(names, []string{"api-tls"}) {
+		t.Errorf("expected the Certificate of the removed listener to be deleted, got %v", names)
+	}
+	// the Certificate is kept while another listener uses its Secret
+	if names := removeListener("api.example.com"); !reflect.DeepEqual(names, []string{"api-tls"}) {
+		t.Errorf("expected the Certificate still used by a listener to be kept, got %v", names)
+	}
+	if names := removeListener("www.example.com"); len(names) != 0 {
+		t.Errorf("expected the Certificate of the last listener using it to be deleted, got %v", names)
+	}
+}
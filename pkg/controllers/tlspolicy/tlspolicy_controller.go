@@ -21,15 +21,19 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -42,15 +46,27 @@ import (
 	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/controller"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/logging"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/events"
 )
 
 const (
-	TLSPolicyFinalizer                                    = "kuadrant.io/tls-policy"
-	TLSPoliciesBackRefAnnotation                          = "kuadrant.io/tlspolicies"
-	TLSPolicyBackRefAnnotation                            = "kuadrant.io/tlspolicy"
-	TLSPolicyAffected            conditions.ConditionType = "kuadrant.io/TLSPolicyAffected"
+	TLSPolicyFinalizer                                                 = "kuadrant.io/tls-policy"
+	TLSPoliciesBackRefAnnotation                                       = "kuadrant.io/tlspolicies"
+	TLSPolicyBackRefAnnotation                                         = "kuadrant.io/tlspolicy"
+	TLSPolicySolverZoneLabel                                           = "kuadrant.io/tlspolicy-solver-zone"
+	TLSPolicyCertificateLabelsAnnotation                               = "kuadrant.io/tlspolicy-certificate-labels"
+	TLSPolicyCertificateAnnotationsAnnotation                          = "kuadrant.io/tlspolicy-certificate-annotations"
+	TLSPolicyAffected                         conditions.ConditionType = "kuadrant.io/TLSPolicyAffected"
+
+	EventReasonIssuerNotFound            = "IssuerNotFound"
+	EventReasonCertificateCreationFailed = "CertificateCreationFailed"
+	EventReasonCertificateReady          = "CertificateReady"
+	EventReasonTLSPolicyPaused           = "ReconciliationPaused"
+	EventReasonTLSPolicyResumed          = "ReconciliationResumed"
 )
 
 type TLSPolicyRefsConfig struct{}
@@ -59,22 +75,47 @@ func (c *TLSPolicyRefsConfig) PolicyRefsAnnotation() string {
 	return TLSPoliciesBackRefAnnotation
 }
 
+// targetBackRefAnnotation returns the annotation claiming the target of the policy. Policies targeting a listener of
+// a Gateway claim it with a per listener annotation, so that policies targeting different listeners do not conflict.
+func targetBackRefAnnotation(tlsPolicy *v1alpha1.TLSPolicy) string {
+	if sectionName := tlsPolicy.GetSectionName(); sectionName != "" {
+		return listenerBackRefAnnotation(sectionName)
+	}
+	return TLSPolicyBackRefAnnotation
+}
+
+func listenerBackRefAnnotation(listenerName string) string {
+	return fmt.Sprintf("%s.%s", TLSPolicyBackRefAnnotation, listenerName)
+}
+
 // TLSPolicyReconciler reconciles a TLSPolicy object
 type TLSPolicyReconciler struct {
 	reconcilers.TargetRefReconciler
 	Scheme *runtime.Scheme
+	// SharedCertificateNamespace is the namespace of the Certificates shared by the Gateways requesting identical
+	// Certificates, no Certificate is shared when empty
+	SharedCertificateNamespace string
+	// CertificateExpiryWarningWindow is how long before the expiry of a Certificate that was not renewed the policy
+	// reports it as expiring soon, DefaultCertificateExpiryWarningWindow when zero
+	CertificateExpiryWarningWindow time.Duration
+	controller.ReconcilerOptions
 }
 
 //+kubebuilder:rbac:groups=kuadrant.io,resources=tlspolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kuadrant.io,resources=tlspolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kuadrant.io,resources=tlspolicies/finalizers,verbs=update
-//+kubebuilder:rbac:groups="cert-manager.io",resources=issuers,verbs=get;list;
-//+kubebuilder:rbac:groups="cert-manager.io",resources=clusterissuers,verbs=get;list;
+//+kubebuilder:rbac:groups="cert-manager.io",resources=issuers,verbs=get;list;watch
+//+kubebuilder:rbac:groups="cert-manager.io",resources=clusterissuers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=managedzones,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=dnsrecords,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 
 func (r *TLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := r.Logger().WithValues("TLSPolicy", req.NamespacedName)
+	ctx, log := logging.IntoReconcile(ctx, r.Logger(), logging.KeyPolicy, req.NamespacedName, logging.KeyPolicyKind, "TLSPolicy")
 	log.Info("Reconciling TLSPolicy")
-	ctx = crlog.IntoContext(ctx, log)
 
 	previous := &v1alpha1.TLSPolicy{}
 	if err := r.Client().Get(ctx, req.NamespacedName, previous); err != nil {
@@ -86,10 +127,24 @@ func (r *TLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	tlsPolicy := previous.DeepCopy()
+	ctx, log = logging.With(ctx, logging.PolicyTarget(tlsPolicy)...)
 	log.V(3).Info("TLSPolicyReconciler Reconcile", "tlsPolicy", tlsPolicy, "tlsPolicy.Spec", tlsPolicy.Spec)
 
 	markedForDeletion := tlsPolicy.GetDeletionTimestamp() != nil
 
+	// a paused policy is left untouched, the deletion of the policy is still honoured
+	if metadata.IsPaused(tlsPolicy) && !markedForDeletion {
+		return r.reconcilePaused(ctx, tlsPolicy)
+	}
+	if meta.IsStatusConditionTrue(tlsPolicy.Status.Conditions, string(conditions.ConditionTypePaused)) {
+		log.Info("reconciliation resumed")
+		r.EventRecorder().Eventf(tlsPolicy, corev1.EventTypeNormal, EventReasonTLSPolicyResumed,
+			"reconciliation resumed, the %s annotation was removed", metadata.PausedAnnotation)
+	}
+	if metadata.ForceSyncRequested(tlsPolicy, tlsPolicy.Status.ObservedForceSync) {
+		log.Info("force sync requested", "value", metadata.GetAnnotation(tlsPolicy, metadata.ForceSyncAnnotation))
+	}
+
 	targetNetworkObject, err := r.FetchValidTargetRef(ctx, tlsPolicy.GetTargetRef(), tlsPolicy.Namespace)
 	log.V(3).Info("TLSPolicyReconciler targetNetworkObject", "targetNetworkObject", targetNetworkObject)
 	if err != nil {
@@ -112,6 +167,9 @@ func (r *TLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			if err := r.deleteResources(ctx, tlsPolicy, targetNetworkObject); err != nil {
 				return ctrl.Result{}, err
 			}
+			if err := r.reconcileCertificateMetrics(ctx, tlsPolicy); err != nil {
+				log.Error(err, "failed to update certificate metrics")
+			}
 			if err := r.RemoveFinalizer(ctx, tlsPolicy, TLSPolicyFinalizer); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -126,9 +184,27 @@ func (r *TLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 
+	requeueAfter, err := r.reconcileActiveIssuer(ctx, tlsPolicy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	specErr := r.reconcileResources(ctx, tlsPolicy, targetNetworkObject)
 
-	newStatus := r.calculateStatus(tlsPolicy, specErr)
+	if err := r.reconcileCertificateMetrics(ctx, tlsPolicy); err != nil {
+		log.Error(err, "failed to update certificate metrics")
+	}
+
+	expiryRequeueAfter, err := r.reconcileCertificateExpiry(ctx, tlsPolicy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.reconcileCertificateValidation(ctx, tlsPolicy); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	newStatus := r.calculateStatus(tlsPolicy, targetNetworkObject, specErr)
 	tlsPolicy.Status = *newStatus
 
 	if !equality.Semantic.DeepEqual(previous.Status, tlsPolicy.Status) {
@@ -142,10 +218,43 @@ func (r *TLSPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		}
 	}
 
+	// the policy is checked again until listeners are added to its target gateway
+	if errors.Is(specErr, ErrNoListeners) {
+		return ctrl.Result{RequeueAfter: noListenersRequeueAfter}, nil
+	}
 	if specErr != nil {
 		return ctrl.Result{}, specErr
 	}
 
+	pendingRequeueAfter, err := r.pendingCertificatesRequeueAfter(ctx, tlsPolicy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for _, after := range []time.Duration{pendingRequeueAfter, expiryRequeueAfter} {
+		if requeueAfter == 0 || (after != 0 && after < requeueAfter) {
+			requeueAfter = after
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reconcilePaused sets the Paused condition of a policy paused by the paused annotation, none of the resources of the
+// policy are changed while it is paused
+func (r *TLSPolicyReconciler) reconcilePaused(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) (ctrl.Result, error) {
+	if meta.IsStatusConditionTrue(tlsPolicy.Status.Conditions, string(conditions.ConditionTypePaused)) {
+		return ctrl.Result{}, nil
+	}
+	crlog.FromContext(ctx).Info("reconciliation paused")
+	meta.SetStatusCondition(&tlsPolicy.Status.Conditions, conditions.BuildPausedCondition(tlsPolicy.Generation))
+	if err := r.Client().Status().Update(ctx, tlsPolicy); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.EventRecorder().Eventf(tlsPolicy, corev1.EventTypeNormal, EventReasonTLSPolicyPaused,
+		"reconciliation paused by the %s annotation", metadata.PausedAnnotation)
 	return ctrl.Result{}, nil
 }
 
@@ -158,29 +267,76 @@ func (r *TLSPolicyReconciler) reconcileResources(ctx context.Context, tlsPolicy
 		return err
 	}
 
-	err = validateIssuer(ctx, r.Client(), tlsPolicy)
-	if err != nil {
-		return err
+	if tlsPolicy.Spec.SecretRef != nil {
+		if err = validateSecret(ctx, r.Client(), tlsPolicy); err != nil {
+			return err
+		}
+	} else {
+		for _, issuerRef := range policyIssuerRefs(tlsPolicy) {
+			err = validateIssuer(ctx, r.Client(), issuerNamespace(tlsPolicy), issuerRef)
+			if err != nil {
+				if apierrors.IsNotFound(err) && targetNetworkObject != nil {
+					r.EventRecorder().Eventf(targetNetworkObject, corev1.EventTypeWarning, EventReasonIssuerNotFound,
+						"%s %s referenced by TLSPolicy %s/%s not found", issuerRef.Kind, issuerRef.Name, tlsPolicy.Namespace, tlsPolicy.Name)
+				}
+				return err
+			}
+		}
+	}
+
+	if err = r.reconcileCAARecords(ctx, tlsPolicy); err != nil {
+		return fmt.Errorf("reconcile CAA records error %w", err)
+	}
+
+	if gateway, ok := targetNetworkObject.(*gatewayapiv1beta1.Gateway); ok && len(gateway.Spec.Listeners) == 0 {
+		tlsPolicy.Status.Listeners = nil
+		return r.releaseGatewayWithoutListeners(ctx, tlsPolicy, gateway)
 	}
 
 	// reconcile based on gateway diffs
-	gatewayDiffObj, err := r.ComputeGatewayDiffs(ctx, tlsPolicy, targetNetworkObject, &TLSPolicyRefsConfig{})
+	gatewayDiffObj, err := r.computeGatewayDiffs(ctx, tlsPolicy, targetNetworkObject)
 	if err != nil {
 		return err
 	}
 
-	if err = r.reconcileCertificates(ctx, tlsPolicy, gatewayDiffObj); err != nil {
+	if err = r.reconcileListeners(ctx, tlsPolicy, gatewayDiffObj); err != nil {
 		gatewayCondition = conditions.BuildPolicyAffectedCondition(TLSPolicyAffected, tlsPolicy, targetNetworkObject, conditions.PolicyReasonInvalid, err)
 		updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
-		return errors.Join(fmt.Errorf("reconcile Certificates error %w", err), updateErr)
+		return errors.Join(fmt.Errorf("reconcile listeners error %w", err), updateErr)
+	}
+
+	if err = r.reconcileReferenceGrantedCondition(ctx, tlsPolicy, gatewayDiffObj); err != nil {
+		return fmt.Errorf("reconcile ReferenceGrants error %w", err)
+	}
+
+	// the Certificates failing for some listeners do not stop the reconciliation of the other listeners, the policy
+	// readiness is decided from the status of its listeners
+	listeners, err := r.reconcileCertificates(ctx, tlsPolicy, targetNetworkObject, gatewayDiffObj)
+	tlsPolicy.Status.Listeners = listeners
+	var certsErr *listenerCertificatesError
+	if err != nil {
+		reason := conditions.PolicyReasonInvalid
+		if errors.Is(err, ErrCertificateConflict) {
+			reason = conditions.PolicyReasonConflicted
+		}
+		gatewayCondition = conditions.BuildPolicyAffectedCondition(TLSPolicyAffected, tlsPolicy, targetNetworkObject, reason, err)
+		if !errors.As(err, &certsErr) {
+			updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
+			return errors.Join(fmt.Errorf("reconcile Certificates error %w", err), updateErr)
+		}
 	}
 
 	// set direct back ref - i.e. claim the target network object as taken asap
-	if err = r.ReconcileTargetBackReference(ctx, client.ObjectKeyFromObject(tlsPolicy), targetNetworkObject, TLSPolicyBackRefAnnotation); err != nil {
+	if err = r.ReconcileTargetBackReference(ctx, client.ObjectKeyFromObject(tlsPolicy), targetNetworkObject, targetBackRefAnnotation(tlsPolicy)); err != nil {
 		gatewayCondition = conditions.BuildPolicyAffectedCondition(TLSPolicyAffected, tlsPolicy, targetNetworkObject, conditions.PolicyReasonConflicted, err)
 		updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
 		return errors.Join(fmt.Errorf("reconcile TargetBackReference error %w", err), updateErr)
 	}
+	if err = r.reconcileSelectedBackReferences(ctx, tlsPolicy, targetNetworkObject, gatewayDiffObj); err != nil {
+		gatewayCondition = conditions.BuildPolicyAffectedCondition(TLSPolicyAffected, tlsPolicy, targetNetworkObject, conditions.PolicyReasonConflicted, err)
+		updateErr := r.updateGatewayCondition(ctx, gatewayCondition, gatewayDiffObj)
+		return errors.Join(fmt.Errorf("reconcile selected gateways TargetBackReference error %w", err), updateErr)
+	}
 
 	// set annotation of policies affecting the gateway
 	if err = r.ReconcileGatewayPolicyReferences(ctx, tlsPolicy, gatewayDiffObj); err != nil {
@@ -195,44 +351,84 @@ func (r *TLSPolicyReconciler) reconcileResources(ctx context.Context, tlsPolicy
 		return fmt.Errorf("failed to update gateway conditions %w ", updateErr)
 	}
 
+	if err = r.reconcileCertificatesReadyCondition(ctx, gatewayDiffObj); err != nil {
+		return fmt.Errorf("failed to update gateway certificates ready condition %w ", err)
+	}
+
+	if certsErr != nil {
+		return certsErr
+	}
 	return nil
 }
 
 func (r *TLSPolicyReconciler) deleteResources(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object) error {
 	// delete based on gateway diffs
 
-	gatewayDiffObj, err := r.ComputeGatewayDiffs(ctx, tlsPolicy, targetNetworkObject, &TLSPolicyRefsConfig{})
+	gatewayDiffObj, err := r.computeGatewayDiffs(ctx, tlsPolicy, targetNetworkObject)
 	if err != nil {
 		return err
 	}
 
-	if err := r.reconcileCertificates(ctx, tlsPolicy, gatewayDiffObj); err != nil {
+	if err := r.reconcileListeners(ctx, tlsPolicy, gatewayDiffObj); err != nil {
+		return err
+	}
+
+	if _, err := r.reconcileCertificates(ctx, tlsPolicy, targetNetworkObject, gatewayDiffObj); err != nil {
 		return err
 	}
 
 	// remove direct back ref
 	if targetNetworkObject != nil {
-		if err := r.DeleteTargetBackReference(ctx, client.ObjectKeyFromObject(tlsPolicy), targetNetworkObject, TLSPolicyBackRefAnnotation); err != nil {
+		if err := r.DeleteTargetBackReference(ctx, client.ObjectKeyFromObject(tlsPolicy), targetNetworkObject, targetBackRefAnnotation(tlsPolicy)); err != nil {
 			return err
 		}
 	}
+	if err := r.reconcileSelectedBackReferences(ctx, tlsPolicy, targetNetworkObject, gatewayDiffObj); err != nil {
+		return err
+	}
 
 	// update annotation of policies affecting the gateway
 	if err := r.ReconcileGatewayPolicyReferences(ctx, tlsPolicy, gatewayDiffObj); err != nil {
 		return err
 	}
 
+	// the listeners of the policy are no longer managed
+	if err := r.reconcileCertificatesReadyCondition(ctx, gatewayDiffObj); err != nil {
+		return err
+	}
+
+	// the CAs of a deleted policy are removed from the CAA record of its zone
+	if err := r.reconcileCAARecords(ctx, tlsPolicy); err != nil {
+		return err
+	}
+
 	// remove gateway policy affected condition status
 	return r.updateGatewayCondition(ctx, metav1.Condition{Type: string(TLSPolicyAffected)}, gatewayDiffObj)
 }
 
-func (r *TLSPolicyReconciler) calculateStatus(tlsPolicy *v1alpha1.TLSPolicy, specErr error) *v1alpha1.TLSPolicyStatus {
+func (r *TLSPolicyReconciler) calculateStatus(tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object, specErr error) *v1alpha1.TLSPolicyStatus {
 	newStatus := tlsPolicy.Status.DeepCopy()
 	if specErr != nil {
 		newStatus.ObservedGeneration = tlsPolicy.Generation
+	} else {
+		newStatus.ObservedForceSync = metadata.GetAnnotation(tlsPolicy, metadata.ForceSyncAnnotation)
 	}
 	readyCond := r.readyCondition(string(tlsPolicy.Spec.TargetRef.Kind), specErr)
+	var certsErr *listenerCertificatesError
+	if specErr == nil || errors.As(specErr, &certsErr) {
+		readyCond = listenersReadyCondition(string(tlsPolicy.Spec.TargetRef.Kind), tlsPolicy.Spec.ReadinessPolicy, newStatus.Listeners)
+	}
 	meta.SetStatusCondition(&newStatus.Conditions, *readyCond)
+	meta.RemoveStatusCondition(&newStatus.Conditions, string(conditions.ConditionTypePaused))
+
+	if gateway, ok := targetNetworkObject.(*gatewayapiv1beta1.Gateway); ok && tlsPolicy.Spec.ConsolidateWildcard {
+		meta.SetStatusCondition(&newStatus.Conditions, wildcardConsolidatedCondition(gateway, tlsPolicy))
+	} else {
+		meta.RemoveStatusCondition(&newStatus.Conditions, string(WildcardConsolidated))
+	}
+	if certificateNamespace(tlsPolicy) == "" {
+		meta.RemoveStatusCondition(&newStatus.Conditions, string(ReferenceGranted))
+	}
 	return newStatus
 }
 
@@ -248,6 +444,15 @@ func (r *TLSPolicyReconciler) readyCondition(targetNetworkObjectectKind string,
 		cond.Status = metav1.ConditionFalse
 		cond.Reason = "ReconciliationError"
 		cond.Message = specErr.Error()
+		if errors.Is(specErr, ErrCertificateConflict) {
+			cond.Reason = string(conditions.PolicyReasonConflicted)
+		}
+		if errors.Is(specErr, ErrInvalidSecret) {
+			cond.Reason = "InvalidSecret"
+		}
+		if errors.Is(specErr, ErrNoListeners) {
+			cond.Reason = "NoListeners"
+		}
 	}
 
 	return cond
@@ -283,13 +488,45 @@ func (r *TLSPolicyReconciler) updateGatewayCondition(ctx context.Context, condit
 // SetupWithManager sets up the controller with the Manager.
 func (r *TLSPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	gatewayEventMapper := events.NewGatewayEventMapper(r.Logger(), &TLSPolicyRefsConfig{}, "tlspolicy")
+	httpRouteEventMapper := events.NewHTTPRouteEventMapper(r.Logger(), TLSPolicyBackRefAnnotation, "tlspolicy")
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.TLSPolicy{}).
 		Watches(
 			&source.Kind{Type: &gatewayapiv1beta1.Gateway{}},
 			handler.EnqueueRequestsFromMapFunc(gatewayEventMapper.MapToPolicy),
 		).
-		Complete(r)
+		Watches(
+			&source.Kind{Type: &gatewayapiv1beta1.Gateway{}},
+			handler.EnqueueRequestsFromMapFunc(r.policiesForSelectedGateway),
+		).
+		Watches(
+			&source.Kind{Type: &gatewayapiv1beta1.HTTPRoute{}},
+			handler.EnqueueRequestsFromMapFunc(httpRouteEventMapper.MapToPolicy),
+		).
+		Watches(
+			&source.Kind{Type: &certmanv1.Certificate{}},
+			&CertificateEventHandler{client: r.Client(), recorder: r.EventRecorder()},
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.policiesForSecret),
+		).
+		Watches(
+			&source.Kind{Type: &gatewayapiv1beta1.ReferenceGrant{}},
+			handler.EnqueueRequestsFromMapFunc(r.policiesForReferenceGrant),
+		).
+		Watches(
+			&source.Kind{Type: &certmanv1.Issuer{}},
+			handler.EnqueueRequestsFromMapFunc(r.policiesForIssuer),
+			builder.WithPredicates(issuerReadyPredicate()),
+		).
+		Watches(
+			&source.Kind{Type: &certmanv1.ClusterIssuer{}},
+			handler.EnqueueRequestsFromMapFunc(r.policiesForIssuer),
+			builder.WithPredicates(issuerReadyPredicate()),
+		).
+		WithOptions(r.ControllerOptions()).
+		Complete(r.WrapReconciler(r))
 }
 
 // The following methods are here temporarily and copied from the kuadrant-operator https://github.com/Kuadrant/kuadrant-operator/blob/main/pkg/reconcilers/targetref_reconciler.go#L45
@@ -0,0 +1,271 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/logging"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestTLSPolicyReconciler_paused(t *testing.T) {
+	tlsPolicy := testutil.NewTestTLSPolicy("test-tls-policy", "test").
+		WithTargetGateway("test-gateway").
+		WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Annotations = map[string]string{metadata.PausedAnnotation: "true"}
+	// a certificate fixed by hand, which the reconciliation would otherwise update
+	cert := &certmanv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-tls-secret",
+			Namespace: "test",
+			Labels:    tlsCertificateLabels(client.ObjectKey{Name: "test-gateway", Namespace: "test"}, client.ObjectKeyFromObject(tlsPolicy)),
+		},
+		Spec: certmanv1.CertificateSpec{DNSNames: []string{"manually.fixed.example.com"}, SecretName: "test-tls-secret"},
+	}
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tlsPolicy, cert).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), recorder),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+	request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tlsPolicy)}
+
+	// reconciling twice does not change anything, and only reports the pause once
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(ctx, request); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	existing := &certmanv1.Certificate{}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(cert), existing); err != nil {
+		t.Fatalf("expected the certificate to be left in place while paused, got %v", err)
+	}
+	if existing.ResourceVersion != cert.ResourceVersion || existing.Spec.DNSNames[0] != "manually.fixed.example.com" {
+		t.Errorf("expected the certificate to be unchanged while paused, got %v", existing.Spec)
+	}
+	certs := &certmanv1.CertificateList{}
+	if err := f.List(ctx, certs); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(certs.Items) != 1 {
+		t.Errorf("expected no certificate to be created while paused, got %d certificates", len(certs.Items))
+	}
+
+	paused := &v1alpha1.TLSPolicy{}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), paused); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if controllerutil.ContainsFinalizer(paused, TLSPolicyFinalizer) {
+		t.Errorf("expected no finalizer to be added while paused")
+	}
+	if !meta.IsStatusConditionTrue(paused.Status.Conditions, string(conditions.ConditionTypePaused)) {
+		t.Errorf("expected the %s condition to be true, got %v", conditions.ConditionTypePaused, paused.Status.Conditions)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected a single paused event, got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, EventReasonTLSPolicyPaused) {
+		t.Errorf("expected a %s event, got %s", EventReasonTLSPolicyPaused, event)
+	}
+
+	// removing the annotation resumes the reconciliation
+	delete(paused.Annotations, metadata.PausedAnnotation)
+	if err := f.Update(ctx, paused); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// the target gateway does not exist, which the resumed reconciliation reports
+	if _, err := r.Reconcile(ctx, request); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the reconciliation to resume and report the missing gateway, got %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected a resumed event, got %d events", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, EventReasonTLSPolicyResumed) {
+		t.Errorf("expected a %s event, got %s", EventReasonTLSPolicyResumed, event)
+	}
+}
+
+func TestTLSPolicyReconciler_logFields(t *testing.T) {
+	tlsPolicy := testutil.NewTestTLSPolicy("test-tls-policy", "test").
+		WithTargetGateway("test-gateway").
+		WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Annotations = map[string]string{metadata.PausedAnnotation: "true"}
+
+	var lines []map[string]interface{}
+	logger := funcr.NewJSON(func(obj string) {
+		line := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(obj), &line); err != nil {
+			t.Errorf("invalid log line %s: %v", obj, err)
+		}
+		lines = append(lines, line)
+	}, funcr.Options{})
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tlsPolicy).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logger, record.NewFakeRecorder(10)),
+		},
+	}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tlsPolicy)}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(lines) < 2 {
+		t.Fatalf("expected the reconcile to log, got %v", lines)
+	}
+	traceID := lines[0][logging.KeyTraceID]
+	if traceID == nil || traceID == "" {
+		t.Fatalf("expected a %s field, got %v", logging.KeyTraceID, lines[0])
+	}
+	for _, line := range lines {
+		if line[logging.KeyTraceID] != traceID {
+			t.Errorf("expected every line of the reconcile to have trace ID %v, got %v", traceID, line)
+		}
+		if line[logging.KeyPolicy] != "test/test-tls-policy" || line[logging.KeyPolicyKind] != "TLSPolicy" {
+			t.Errorf("expected the policy fields to be set, got %v", line)
+		}
+	}
+	// the target is known once the policy is fetched
+	if last := lines[len(lines)-1]; last[logging.KeyGateway] != "test/test-gateway" {
+		t.Errorf("expected the %s field to be test/test-gateway, got %v", logging.KeyGateway, last)
+	}
+}
+
+func TestTLSPolicyReconciler_noListeners(t *testing.T) {
+	gateway := testutil.NewTestGateway("test-gateway", "istio", "test").Gateway
+	tlsPolicy := testutil.NewTestTLSPolicy("test-tls-policy", "test").
+		WithTargetGateway("test-gateway").
+		WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Spec.AutoConfigureListeners = true
+	tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+	issuer := testutil.NewTestIssuer("testissuer", "test")
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway, tlsPolicy, issuer).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+	request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tlsPolicy)}
+
+	certificates := func() []certmanv1.Certificate {
+		t.Helper()
+		certList := &certmanv1.CertificateList{}
+		if err := f.List(ctx, certList); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return certList.Items
+	}
+	expectNoListeners := func() {
+		t.Helper()
+		result, err := r.Reconcile(ctx, request)
+		if err != nil {
+			t.Fatalf("expected a gateway without listeners not to fail the reconciliation, got %v", err)
+		}
+		if result.RequeueAfter != noListenersRequeueAfter {
+			t.Errorf("expected the policy to be requeued after %s, got %v", noListenersRequeueAfter, result)
+		}
+		policy := &v1alpha1.TLSPolicy{}
+		if err := f.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), policy); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		ready := meta.FindStatusCondition(policy.Status.Conditions, string(conditions.ConditionTypeReady))
+		if ready == nil || ready.Status != metav1.ConditionFalse || ready.Reason != "NoListeners" {
+			t.Errorf("expected the policy not to be ready with reason NoListeners, got %v", ready)
+		}
+		if certs := certificates(); len(certs) != 0 {
+			t.Errorf("expected no certificate for a gateway without listeners, got %d certificates", len(certs))
+		}
+		gw := &gatewayv1beta1.Gateway{}
+		if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if _, ok := gw.Annotations[TLSPolicyBackRefAnnotation]; ok {
+			t.Errorf("expected no back reference on a gateway without listeners, got %v", gw.Annotations)
+		}
+		if refs := (common.GatewayWrapper{Gateway: gw, PolicyRefsConfig: &TLSPolicyRefsConfig{}}).PolicyRefs(); len(refs) != 0 {
+			t.Errorf("expected no policy reference on a gateway without listeners, got %v", refs)
+		}
+	}
+	setListeners := func(listeners []gatewayv1beta1.Listener) {
+		t.Helper()
+		gw := &gatewayv1beta1.Gateway{}
+		if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		gw.Spec.Listeners = listeners
+		if err := f.Update(ctx, gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	expectNoListeners()
+
+	// the policy recovers once a listener is added
+	setListeners([]gatewayv1beta1.Listener{
+		{Name: "api", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("api.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+	})
+	// every step updating the gateway conflicts with the next ones within a reconciliation, which are retried
+	var err error
+	for i := 0; i < 5; i++ {
+		if _, err = r.Reconcile(ctx, request); !apierrors.IsConflict(err) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	policy := &v1alpha1.TLSPolicy{}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), policy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !meta.IsStatusConditionTrue(policy.Status.Conditions, string(conditions.ConditionTypeReady)) {
+		t.Errorf("expected the policy to be ready once the gateway has a listener, got %v", policy.Status.Conditions)
+	}
+	if certs := certificates(); len(certs) != 1 {
+		t.Errorf("expected a certificate for the added listener, got %d certificates", len(certs))
+	}
+	gw := &gatewayv1beta1.Gateway{}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if gw.Annotations[TLSPolicyBackRefAnnotation] != request.String() {
+		t.Errorf("expected the gateway to be claimed by the policy, got %v", gw.Annotations)
+	}
+
+	// removing the listeners again leaves nothing behind
+	setListeners(nil)
+	expectNoListeners()
+}
@@ -0,0 +1,219 @@
+package tlspolicy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// reconcileActiveIssuer sets the active issuer in the policy status. The active issuer moves to the next issuer in the
+// list when a Certificate using it has been failing for longer than the fallback threshold. The returned duration is
+// the time until the threshold is reached for a failing Certificate, or zero if nothing is failing.
+func (r *TLSPolicyReconciler) reconcileActiveIssuer(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) (time.Duration, error) {
+	log := crlog.FromContext(ctx)
+
+	// no certificates are issued for a policy using an existing secret
+	if tlsPolicy.Spec.SecretRef != nil {
+		tlsPolicy.Status.ActiveIssuer = nil
+		return 0, nil
+	}
+
+	issuerRefs := tlsPolicy.Spec.GetIssuerRefs()
+	active := -1
+	if tlsPolicy.Status.ActiveIssuer != nil {
+		for i := range issuerRefs {
+			if issuerRefs[i] == *tlsPolicy.Status.ActiveIssuer {
+				active = i
+				break
+			}
+		}
+	}
+	if active == -1 {
+		// first reconcile or the issuers have changed, start again with the first issuer
+		setActiveIssuer(tlsPolicy, issuerRefs[0])
+		return 0, nil
+	}
+
+	threshold := v1alpha1.DefaultIssuerFallbackThreshold
+	if tlsPolicy.Spec.IssuerFallbackThreshold != nil {
+		threshold = tlsPolicy.Spec.IssuerFallbackThreshold.Duration
+	}
+
+	listOptions := &client.ListOptions{LabelSelector: labels.SelectorFromSet(map[string]string{
+		TLSPolicyBackRefAnnotation:                              tlsPolicy.Name,
+		fmt.Sprintf("%s-namespace", TLSPolicyBackRefAnnotation): tlsPolicy.Namespace,
+	})}
+	certList := &certmanv1.CertificateList{}
+	if err := r.Client().List(ctx, certList, listOptions); err != nil {
+		return 0, err
+	}
+
+	var requeueAfter time.Duration
+	for _, cert := range certList.Items {
+		if active >= len(issuerRefs)-1 {
+			break
+		}
+		if cert.Spec.IssuerRef != issuerRefs[active] {
+			continue
+		}
+		failingSince := certificateFailingSince(&cert)
+		if failingSince == nil {
+			continue
+		}
+		// a certificate that was already failing with the previous issuer has only been failing with the active
+		// issuer since it was switched
+		if tlsPolicy.Status.LastIssuerTransitionTime != nil && failingSince.Before(tlsPolicy.Status.LastIssuerTransitionTime.Time) {
+			failingSince = &tlsPolicy.Status.LastIssuerTransitionTime.Time
+		}
+		remaining := threshold - time.Since(*failingSince)
+		if remaining <= 0 {
+			log.Info("certificate failing for longer than the fallback threshold, moving to next issuer",
+				"certificate", client.ObjectKeyFromObject(&cert), "issuer", issuerRefs[active].Name, "nextIssuer", issuerRefs[active+1].Name)
+			setActiveIssuer(tlsPolicy, issuerRefs[active+1])
+			return 0, nil
+		}
+		if requeueAfter == 0 || remaining < requeueAfter {
+			requeueAfter = remaining
+		}
+	}
+
+	return requeueAfter, nil
+}
+
+func setActiveIssuer(tlsPolicy *v1alpha1.TLSPolicy, issuerRef cmmeta.ObjectReference) {
+	now := metav1.Now()
+	tlsPolicy.Status.ActiveIssuer = &issuerRef
+	tlsPolicy.Status.LastIssuerTransitionTime = &now
+}
+
+// activeIssuerRef returns the issuer that Certificates created by the policy should use
+func activeIssuerRef(tlsPolicy *v1alpha1.TLSPolicy) cmmeta.ObjectReference {
+	if tlsPolicy.Status.ActiveIssuer != nil {
+		return *tlsPolicy.Status.ActiveIssuer
+	}
+	return tlsPolicy.Spec.GetIssuerRefs()[0]
+}
+
+// certificateIssuerRef returns the issuer of the Certificate for the hosts, the issuer of the first issuer rule
+// matching them or the active issuer of the policy
+func certificateIssuerRef(tlsPolicy *v1alpha1.TLSPolicy, hosts []string) cmmeta.ObjectReference {
+	if issuerRef, ok := tlsPolicy.Spec.IssuerRefForHosts(hosts); ok {
+		return issuerRef
+	}
+	return activeIssuerRef(tlsPolicy)
+}
+
+// policyIssuerRefs returns the issuers the Certificates of the policy are currently issued from, the active issuer
+// and the issuers of the issuer rules
+func policyIssuerRefs(tlsPolicy *v1alpha1.TLSPolicy) []cmmeta.ObjectReference {
+	issuerRefs := []cmmeta.ObjectReference{activeIssuerRef(tlsPolicy)}
+	for _, rule := range tlsPolicy.Spec.IssuerRules {
+		issuerRefs = append(issuerRefs, rule.IssuerRef)
+	}
+	return issuerRefs
+}
+
+// certificateFailingSince returns the time the Certificate became not ready if the last issuance attempt failed
+func certificateFailingSince(cert *certmanv1.Certificate) *time.Time {
+	if cert.Status.LastFailureTime == nil {
+		return nil
+	}
+	for _, cond := range cert.Status.Conditions {
+		if cond.Type == certmanv1.CertificateConditionReady && cond.Status == cmmeta.ConditionFalse && cond.LastTransitionTime != nil {
+			return &cond.LastTransitionTime.Time
+		}
+	}
+	return nil
+}
+
+// policiesForIssuer returns a request for every policy with the Issuer or ClusterIssuer in its issuer list or issuer
+// rules
+func (r *TLSPolicyReconciler) policiesForIssuer(obj client.Object) []reconcile.Request {
+	issuer, ok := obj.(certmanv1.GenericIssuer)
+	if !ok {
+		return nil
+	}
+
+	// the Issuers of a policy with a certificate namespace are in that namespace, so policies are listed in every
+	// namespace
+	policies := &v1alpha1.TLSPolicyList{}
+	if err := r.Client().List(context.TODO(), policies); err != nil {
+		crlog.Log.Error(err, "failed to list tls policies for issuer", "issuer", client.ObjectKeyFromObject(issuer))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, policy := range policies.Items {
+		if _, ok := issuer.(*certmanv1.Issuer); ok && issuerNamespace(&policy) != issuer.GetNamespace() {
+			continue
+		}
+		issuerRefs := append([]cmmeta.ObjectReference{}, policy.Spec.GetIssuerRefs()...)
+		for _, rule := range policy.Spec.IssuerRules {
+			issuerRefs = append(issuerRefs, rule.IssuerRef)
+		}
+		for _, issuerRef := range issuerRefs {
+			if issuerRefMatches(issuerRef, issuer) {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+func issuerRefMatches(issuerRef cmmeta.ObjectReference, issuer certmanv1.GenericIssuer) bool {
+	if issuerRef.Name != issuer.GetName() {
+		return false
+	}
+	switch issuer.(type) {
+	case *certmanv1.Issuer:
+		return issuerRef.Kind == "" || issuerRef.Kind == certmanv1.IssuerKind
+	case *certmanv1.ClusterIssuer:
+		return issuerRef.Kind == certmanv1.ClusterIssuerKind
+	}
+	return false
+}
+
+// issuerReadyPredicate filters the issuer events to the ones that can change the readiness of a policy: an issuer is
+// created or deleted, or its Ready condition becomes true. A change of the ACME server of an issuer is passed too,
+// as it changes the CA of the CAA records of the policy.
+func issuerReadyPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldIssuer, ok := e.ObjectOld.(certmanv1.GenericIssuer)
+			if !ok {
+				return false
+			}
+			newIssuer, ok := e.ObjectNew.(certmanv1.GenericIssuer)
+			if !ok {
+				return false
+			}
+			return (!isIssuerReady(oldIssuer) && isIssuerReady(newIssuer)) || acmeServer(oldIssuer) != acmeServer(newIssuer)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+func isIssuerReady(issuer certmanv1.GenericIssuer) bool {
+	for _, cond := range issuer.GetStatus().Conditions {
+		if cond.Type == certmanv1.IssuerConditionReady {
+			return cond.Status == cmmeta.ConditionTrue
+		}
+	}
+	return false
+}
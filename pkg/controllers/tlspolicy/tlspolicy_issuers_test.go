@@ -0,0 +1,272 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	acmev1 "github.com/jetstack/cert-manager/pkg/apis/acme/v1"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestTLSPolicyReconciler_policiesForIssuer(t *testing.T) {
+	issuerPolicy := testutil.NewTestTLSPolicy("issuer-policy", "test").
+		WithTargetGateway("gw").
+		WithIssuer("issuer", "", "cert-manager.io").TLSPolicy
+	fallbackPolicy := testutil.NewTestTLSPolicy("fallback-policy", "test").
+		WithTargetGateway("gw").TLSPolicy
+	fallbackPolicy.Spec.IssuerRefs = []cmmeta.ObjectReference{
+		{Name: "other", Kind: certmanv1.IssuerKind},
+		{Name: "issuer", Kind: certmanv1.IssuerKind},
+	}
+	clusterIssuerPolicy := testutil.NewTestTLSPolicy("cluster-issuer-policy", "other").
+		WithTargetGateway("gw").
+		WithIssuer("issuer", certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+	otherNamespacePolicy := testutil.NewTestTLSPolicy("other-namespace-policy", "other").
+		WithTargetGateway("gw").
+		WithIssuer("issuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	// the Issuers of a policy with a certificate namespace are in that namespace
+	certificateNamespacePolicy := testutil.NewTestTLSPolicy("certificate-namespace-policy", "blue").
+		WithTargetGateway("gw").
+		WithIssuer("issuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	certificateNamespacePolicy.Spec.AutoConfigureListeners = true
+	certificateNamespacePolicy.Spec.CertificateNamespace = "test"
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(issuerPolicy, fallbackPolicy, clusterIssuerPolicy, otherNamespacePolicy, certificateNamespacePolicy).
+		Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+
+	requests := r.policiesForIssuer(testutil.NewTestIssuer("issuer", "test"))
+	if len(requests) != 3 {
+		t.Fatalf("expected requests for the 3 policies referencing the issuer, got %v", requests)
+	}
+	for _, request := range requests {
+		if request.Name != issuerPolicy.Name && request.Name != fallbackPolicy.Name && request.Name != certificateNamespacePolicy.Name {
+			t.Errorf("unexpected request %v for issuer", request)
+		}
+	}
+
+	requests = r.policiesForIssuer(testutil.NewTestClusterIssuer("issuer"))
+	if len(requests) != 1 || requests[0].Name != clusterIssuerPolicy.Name || requests[0].Namespace != clusterIssuerPolicy.Namespace {
+		t.Errorf("expected a request for %s only, got %v", clusterIssuerPolicy.Name, requests)
+	}
+}
+
+func TestTLSPolicyReconciler_issuerRules(t *testing.T) {
+	internalCA := cmmeta.ObjectReference{Name: "internal-ca", Kind: certmanv1.IssuerKind, Group: "cert-manager.io"}
+	gateway := testutil.NewTestGateway("prod-web", "istio", "test").Gateway
+	gateway.Spec.Listeners = []gatewayv1beta1.Listener{
+		{Name: "api", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("api.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+		{Name: "db", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("db.internal.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+	}
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "test").
+		WithTargetGateway("prod-web").
+		WithIssuer("letsencrypt", certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Spec.AutoConfigureListeners = true
+	tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com", "*.internal.example.com"}
+	tlsPolicy.Spec.IssuerRules = []v1alpha1.IssuerRule{{Hostname: "*.internal.example.com", IssuerRef: internalCA}}
+	if err := tlsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway, tlsPolicy).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	gwDiff := &reconcilers.GatewayDiff{GatewaysMissingPolicyRef: []common.GatewayWrapper{{Gateway: gateway, PolicyRefsConfig: &TLSPolicyRefsConfig{}}}}
+	if err := r.reconcileListeners(ctx, tlsPolicy, gwDiff); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := r.reconcileCertificates(ctx, tlsPolicy, gateway, gwDiff); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	wantIssuers := map[string]cmmeta.ObjectReference{
+		"prod-web-api-tls": {Name: "letsencrypt", Kind: certmanv1.ClusterIssuerKind, Group: "cert-manager.io"},
+		"prod-web-db-tls":  internalCA,
+	}
+	for name, wantIssuer := range wantIssuers {
+		cert := &certmanv1.Certificate{}
+		if err := f.Get(ctx, client.ObjectKey{Name: name, Namespace: "test"}, cert); err != nil {
+			t.Fatalf("expected Certificate %s, got %v", name, err)
+		}
+		if cert.Spec.IssuerRef != wantIssuer {
+			t.Errorf("expected Certificate %s to be issued by %v, got %v", name, wantIssuer, cert.Spec.IssuerRef)
+		}
+	}
+
+	// the policy is reconciled when the issuer of a rule changes
+	requests := r.policiesForIssuer(testutil.NewTestIssuer("internal-ca", "test"))
+	if len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(tlsPolicy) {
+		t.Errorf("expected a request for the policy with the issuer rule, got %v", requests)
+	}
+
+	invalidRules := map[string]v1alpha1.IssuerRule{
+		"invalid value for spec.issuerRules[0].hostname":  {Hostname: "db.*.example.com", IssuerRef: internalCA},
+		"invalid value for spec.issuerRules[0].issuerRef": {Hostname: "*.internal.example.com"},
+		"invalid value for issuerRef.kind Secret":         {Hostname: "*.internal.example.com", IssuerRef: cmmeta.ObjectReference{Name: "internal-ca", Kind: "Secret"}},
+	}
+	for wantErr, rule := range invalidRules {
+		invalid := tlsPolicy.DeepCopy()
+		invalid.Spec.IssuerRules = []v1alpha1.IssuerRule{rule}
+		if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("expected error %q for issuer rule %v, got %v", wantErr, rule, err)
+		}
+	}
+}
+
+func TestTLSPolicyReconciler_allowedIssuerGroups(t *testing.T) {
+	// the groups allowed by the --allowed-issuer-groups flag of the controller
+	v1alpha1.SetAllowedIssuerGroups("cert-manager.io", "awspca.cert-manager.io")
+	defer v1alpha1.SetAllowedIssuerGroups()
+
+	pca := cmmeta.ObjectReference{Name: "pca", Kind: "AWSPCAClusterIssuer", Group: "awspca.cert-manager.io"}
+	gateway := testutil.NewTestGateway("prod-web", "istio", "test").Gateway
+	gateway.Spec.Listeners = []gatewayv1beta1.Listener{
+		{Name: "api", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("api.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+	}
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "test").
+		WithTargetGateway("prod-web").
+		WithIssuer(pca.Name, pca.Kind, pca.Group).TLSPolicy
+	tlsPolicy.Spec.AutoConfigureListeners = true
+	tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+	if err := tlsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway, tlsPolicy).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	// the issuer of another group is a resource of its signer, it is not looked up
+	if err := validateIssuer(ctx, f, tlsPolicy.Namespace, pca); err != nil {
+		t.Errorf("unexpected error validating the issuer %v", err)
+	}
+	gwDiff := &reconcilers.GatewayDiff{GatewaysMissingPolicyRef: []common.GatewayWrapper{{Gateway: gateway, PolicyRefsConfig: &TLSPolicyRefsConfig{}}}}
+	if err := r.reconcileListeners(ctx, tlsPolicy, gwDiff); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := r.reconcileCertificates(ctx, tlsPolicy, gateway, gwDiff); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cert := &certmanv1.Certificate{}
+	if err := f.Get(ctx, client.ObjectKey{Name: "prod-web-api-tls", Namespace: "test"}, cert); err != nil {
+		t.Fatalf("expected Certificate prod-web-api-tls, got %v", err)
+	}
+	if cert.Spec.IssuerRef != pca {
+		t.Errorf("expected the Certificate to be issued by %v, got %v", pca, cert.Spec.IssuerRef)
+	}
+
+	invalidIssuers := map[string]cmmeta.ObjectReference{
+		"invalid value for issuerRef.group example.com, the supported groups are awspca.cert-manager.io, cert-manager.io": {Name: "issuer", Kind: "Issuer", Group: "example.com"},
+		"invalid value for issuerRef.kind, it must be set for the issuers of group awspca.cert-manager.io":                {Name: "pca", Group: pca.Group},
+	}
+	for wantErr, issuerRef := range invalidIssuers {
+		invalid := tlsPolicy.DeepCopy()
+		invalid.Spec.IssuerRef = issuerRef
+		if err := invalid.Validate(); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("expected error %q for issuer %v, got %v", wantErr, issuerRef, err)
+		}
+	}
+
+	// only cert-manager.io is allowed by default
+	v1alpha1.SetAllowedIssuerGroups()
+	if err := tlsPolicy.Validate(); err == nil || !strings.Contains(err.Error(), "the only supported group is cert-manager.io") {
+		t.Errorf("expected the issuer group to be rejected once it is no longer allowed, got %v", err)
+	}
+}
+
+func TestIssuerReadyPredicate(t *testing.T) {
+	issuer := func(status cmmeta.ConditionStatus) *certmanv1.Issuer {
+		issuer := testutil.NewTestIssuer("issuer", "test")
+		if status != "" {
+			issuer.Status.Conditions = []certmanv1.IssuerCondition{{Type: certmanv1.IssuerConditionReady, Status: status}}
+		}
+		return issuer
+	}
+
+	testCases := []struct {
+		name      string
+		oldStatus cmmeta.ConditionStatus
+		newStatus cmmeta.ConditionStatus
+		want      bool
+	}{
+		{
+			name:      "issuer becomes ready",
+			newStatus: cmmeta.ConditionTrue,
+			want:      true,
+		},
+		{
+			name:      "issuer recovers",
+			oldStatus: cmmeta.ConditionFalse,
+			newStatus: cmmeta.ConditionTrue,
+			want:      true,
+		},
+		{
+			name:      "issuer stays ready",
+			oldStatus: cmmeta.ConditionTrue,
+			newStatus: cmmeta.ConditionTrue,
+		},
+		{
+			name:      "issuer is not ready",
+			newStatus: cmmeta.ConditionFalse,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := issuerReadyPredicate().Update(event.UpdateEvent{
+				ObjectOld: issuer(testCase.oldStatus),
+				ObjectNew: issuer(testCase.newStatus),
+			})
+			if got != testCase.want {
+				t.Errorf("issuerReadyPredicate() update = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+
+	// a change of the ACME server changes the CA of the CAA records
+	oldIssuer, newIssuer := issuer(cmmeta.ConditionTrue), issuer(cmmeta.ConditionTrue)
+	oldIssuer.Spec.ACME = &acmev1.ACMEIssuer{Server: "https://acme-v02.api.letsencrypt.org/directory"}
+	newIssuer.Spec.ACME = &acmev1.ACMEIssuer{Server: "https://acme.zerossl.com/v2/DV90"}
+	if !issuerReadyPredicate().Update(event.UpdateEvent{ObjectOld: oldIssuer, ObjectNew: newIssuer}) {
+		t.Errorf("expected a change of the ACME server to be accepted")
+	}
+
+	if !issuerReadyPredicate().Create(event.CreateEvent{Object: &certmanv1.ClusterIssuer{ObjectMeta: metav1.ObjectMeta{Name: "issuer"}}}) {
+		t.Errorf("expected issuer creation to be accepted")
+	}
+}
@@ -0,0 +1,353 @@
+package tlspolicy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	// TLSPolicyListenersAnnotation lists, as comma separated name=secret pairs, the Gateway listeners whose TLS config
+	// was set by the policy
+	TLSPolicyListenersAnnotation = "kuadrant.io/tlspolicy-listeners"
+
+	EventReasonListenerConflict = "ListenerConflict"
+
+	// WildcardConsolidated is the condition type reporting whether the selected listeners share a wildcard Certificate
+	WildcardConsolidated conditions.ConditionType = "WildcardConsolidated"
+
+	wildcardListenerName = "wildcard"
+
+	// noListenersRequeueAfter is the interval at which a policy targeting a Gateway without listeners is checked again
+	noListenersRequeueAfter = 30 * time.Second
+)
+
+// ErrNoListeners is reported while the Gateway targeted by a policy has no listeners
+var ErrNoListeners = fmt.Errorf("gateway has no listeners")
+
+// releaseGatewayWithoutListeners removes the Certificates, back references and conditions of the policy from the
+// Gateways it applies to while its target Gateway has no listeners. They are set again once listeners are added.
+func (r *TLSPolicyReconciler) releaseGatewayWithoutListeners(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, gateway *gatewayv1beta1.Gateway) error {
+	crlog.FromContext(ctx).V(1).Info("target gateway has no listeners", "gateway", client.ObjectKeyFromObject(gateway))
+	if err := r.deleteResources(ctx, tlsPolicy, nil); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: gateway %s/%s", ErrNoListeners, gateway.Namespace, gateway.Name)
+}
+
+// reconcileListeners sets the TLS config of the listeners selected by the policy on every Gateway the policy applies
+// to, and stops managing the listeners of Gateways the policy no longer applies to.
+func (r *TLSPolicyReconciler) reconcileListeners(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, gwDiffObj *reconcilers.GatewayDiff) error {
+	log := crlog.FromContext(ctx)
+
+	for _, gw := range gwDiffObj.GatewaysWithInvalidPolicyRef {
+		if _, ok := gw.Annotations[TLSPolicyListenersAnnotation]; !ok {
+			continue
+		}
+		previous := gw.Annotations[TLSPolicyListenersAnnotation]
+		setManagedListeners(gw.Gateway, otherManagedListeners(gw.Gateway, tlsPolicy))
+		if gw.Annotations[TLSPolicyListenersAnnotation] == previous {
+			continue
+		}
+		if err := r.Client().Update(ctx, gw.Gateway); err != nil {
+			return err
+		}
+	}
+
+	for _, gw := range append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...) {
+		previous := gw.DeepCopy()
+		conflicts, err := configureListeners(gw.Gateway, tlsPolicy, r.sharedCertificateNamespace(tlsPolicy))
+		if err != nil {
+			return err
+		}
+		for _, listenerName := range conflicts {
+			log.Info("listener TLS config was set by other means, not configuring it", "gateway", gw.Key(), "listener", listenerName)
+			r.EventRecorder().Eventf(gw.Gateway, corev1.EventTypeWarning, EventReasonListenerConflict,
+				"listener %s TLS config was not set by TLSPolicy %s/%s and is left untouched", listenerName, tlsPolicy.Namespace, tlsPolicy.Name)
+		}
+		if equality.Semantic.DeepEqual(previous.Spec, gw.Spec) && equality.Semantic.DeepEqual(previous.Annotations, gw.Annotations) {
+			continue
+		}
+		if err := r.Client().Update(ctx, gw.Gateway); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configureListeners sets the TLS config of the gateway listeners selected by the policy. A listener is only
+// configured when it has no TLS config, or the TLS config was previously set by the policy and not changed since.
+// The listeners use the shared Secrets of the shared namespace when it is set. The names of the selected listeners
+// left untouched are returned.
+func configureListeners(gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy, sharedNamespace string) ([]string, error) {
+	managed := parseManagedListeners(gateway.Annotations[TLSPolicyListenersAnnotation])
+	configured := otherManagedListeners(gateway, tlsPolicy)
+	var conflicts []string
+
+	if tlsPolicy.Spec.AutoConfigureListeners {
+		parent, _ := wildcardConsolidation(gateway, tlsPolicy)
+		for i := range gateway.Spec.Listeners {
+			l := &gateway.Spec.Listeners[i]
+			if !listenerInSection(gateway, string(l.Name), tlsPolicy) || !listenerSelected(*l, tlsPolicy.Spec.ListenerHostnames) {
+				continue
+			}
+			name, hostname := string(l.Name), string(*l.Hostname)
+			if parent != "" {
+				name, hostname = wildcardListenerName, "*."+parent
+			}
+			secretKey, err := listenerSecretKey(gateway, name, hostname, tlsPolicy, sharedNamespace)
+			if err != nil {
+				return nil, err
+			}
+			managedSecret, isManaged := managed[string(l.Name)]
+			if l.TLS != nil && !isListenerTLSConfig(l.TLS, gateway.Namespace, secretKey) && !(isManaged && isListenerTLSConfig(l.TLS, gateway.Namespace, managedSecretKey(gateway.Namespace, managedSecret))) {
+				conflicts = append(conflicts, string(l.Name))
+				continue
+			}
+			if l.TLS == nil || !isListenerTLSConfig(l.TLS, gateway.Namespace, secretKey) {
+				l.TLS = listenerTLSConfig(secretKey.Name)
+				if secretKey.Namespace != gateway.Namespace {
+					namespace := gatewayv1beta1.Namespace(secretKey.Namespace)
+					l.TLS.CertificateRefs[0].Namespace = &namespace
+				}
+			}
+			configured[string(l.Name)] = formatManagedSecret(gateway.Namespace, secretKey)
+		}
+	}
+
+	setManagedListeners(gateway, configured)
+	return conflicts, nil
+}
+
+// listenerInSection returns true if the listener is in the section of the gateway the policy applies to. A policy
+// with a sectionName only applies to the listener of that name, other policies apply to every listener not claimed
+// by a policy targeting it by name.
+func listenerInSection(gateway *gatewayv1beta1.Gateway, listenerName string, tlsPolicy *v1alpha1.TLSPolicy) bool {
+	if sectionName := tlsPolicy.GetSectionName(); sectionName != "" {
+		return listenerName == sectionName
+	}
+	_, claimed := gateway.Annotations[listenerBackRefAnnotation(listenerName)]
+	return !claimed
+}
+
+// otherManagedListeners returns the managed listeners of the gateway outside the section of the policy, which are
+// left to the policies they belong to
+func otherManagedListeners(gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy) map[string]string {
+	managed := parseManagedListeners(gateway.Annotations[TLSPolicyListenersAnnotation])
+	for name := range managed {
+		if listenerInSection(gateway, name, tlsPolicy) {
+			delete(managed, name)
+		}
+	}
+	return managed
+}
+
+func setManagedListeners(gateway *gatewayv1beta1.Gateway, managed map[string]string) {
+	if len(managed) == 0 {
+		delete(gateway.Annotations, TLSPolicyListenersAnnotation)
+		return
+	}
+	if gateway.Annotations == nil {
+		gateway.Annotations = map[string]string{}
+	}
+	gateway.Annotations[TLSPolicyListenersAnnotation] = formatManagedListeners(managed)
+}
+
+// listenerSelected returns true if the listener is a HTTPS listener and its hostname is selected by the hostnames
+func listenerSelected(l gatewayv1beta1.Listener, hostnames []gatewayv1beta1.Hostname) bool {
+	if l.Protocol != gatewayv1beta1.HTTPSProtocolType || l.Hostname == nil {
+		return false
+	}
+	for _, hostname := range hostnames {
+		if common.Name(*l.Hostname).SubsetOf(common.Name(hostname)) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardConsolidation returns the parent domain of the listeners selected by the policy when consolidateWildcard is
+// enabled and they can all be served by a single wildcard Certificate for it, i.e. there are at least two selected
+// listeners and every hostname is a direct subdomain of the parent, or its wildcard. An empty parent is returned
+// otherwise. The message describes the decision.
+func wildcardConsolidation(gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy) (string, string) {
+	if !tlsPolicy.Spec.ConsolidateWildcard {
+		return "", ""
+	}
+
+	parents := map[string][]string{}
+	var listenerNames []string
+	for _, l := range gateway.Spec.Listeners {
+		if !listenerInSection(gateway, string(l.Name), tlsPolicy) || !listenerSelected(l, tlsPolicy.Spec.ListenerHostnames) {
+			continue
+		}
+		_, parent, _ := strings.Cut(string(*l.Hostname), ".")
+		parents[parent] = append(parents[parent], string(l.Name))
+		listenerNames = append(listenerNames, string(l.Name))
+	}
+
+	if len(listenerNames) < 2 {
+		return "", fmt.Sprintf("%d listeners selected on gateway %s/%s, at least 2 are required to share a wildcard certificate", len(listenerNames), gateway.Namespace, gateway.Name)
+	}
+	if len(parents) > 1 {
+		domains := make([]string, 0, len(parents))
+		for parent := range parents {
+			domains = append(domains, parent)
+		}
+		sort.Strings(domains)
+		return "", fmt.Sprintf("listeners on gateway %s/%s have hostnames under different parent domains (%s), issuing individual certificates", gateway.Namespace, gateway.Name, strings.Join(domains, ", "))
+	}
+	for parent := range parents {
+		// a wildcard for a top level domain is never issued
+		if !strings.Contains(parent, ".") {
+			return "", fmt.Sprintf("listeners on gateway %s/%s share the top level domain %s, issuing individual certificates", gateway.Namespace, gateway.Name, parent)
+		}
+		return parent, fmt.Sprintf("listeners %s on gateway %s/%s share the wildcard certificate *.%s", strings.Join(listenerNames, ", "), gateway.Namespace, gateway.Name, parent)
+	}
+	return "", ""
+}
+
+// wildcardConsolidatedCondition returns the WildcardConsolidated condition of the policy for the gateway
+func wildcardConsolidatedCondition(gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy) metav1.Condition {
+	parent, message := wildcardConsolidation(gateway, tlsPolicy)
+	cond := metav1.Condition{
+		Type:    string(WildcardConsolidated),
+		Status:  metav1.ConditionTrue,
+		Reason:  "Consolidated",
+		Message: message,
+	}
+	if parent == "" {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "NotConsolidated"
+	}
+	return cond
+}
+
+// listenerSecretKey returns the Secret of a listener configured by the policy, the shared Secret for the hostname in
+// the shared namespace when it is set, and the Secret named by the listener secret name template otherwise, in the
+// certificate namespace of the policy or the namespace of the gateway
+func listenerSecretKey(gateway *gatewayv1beta1.Gateway, name, hostname string, tlsPolicy *v1alpha1.TLSPolicy, sharedNamespace string) (client.ObjectKey, error) {
+	if sharedNamespace != "" {
+		secretName, err := sharedSecretName(tlsPolicy, hostname)
+		return client.ObjectKey{Name: secretName, Namespace: sharedNamespace}, err
+	}
+	namespace := gateway.Namespace
+	if certNamespace := certificateNamespace(tlsPolicy); certNamespace != "" {
+		namespace = certNamespace
+	}
+	secretName, err := listenerSecretName(gateway, name, hostname, tlsPolicy)
+	return client.ObjectKey{Name: secretName, Namespace: namespace}, err
+}
+
+func listenerSecretName(gateway *gatewayv1beta1.Gateway, name, hostname string, tlsPolicy *v1alpha1.TLSPolicy) (string, error) {
+	if tlsPolicy.Spec.SecretRef != nil {
+		return tlsPolicy.Spec.SecretRef.Name, nil
+	}
+
+	tmpl, err := template.New("listenerSecretName").Parse(tlsPolicy.Spec.GetListenerSecretNameTemplate())
+	if err != nil {
+		return "", err
+	}
+	var secretName bytes.Buffer
+	if err := tmpl.Execute(&secretName, map[string]string{
+		"Gateway":  gateway.Name,
+		"Name":     name,
+		"Hostname": strings.ReplaceAll(hostname, "*", "wildcard"),
+	}); err != nil {
+		return "", err
+	}
+	if errs := validation.IsDNS1123Subdomain(secretName.String()); len(errs) > 0 {
+		return "", fmt.Errorf("invalid secret name %q for listener %s: %s", secretName.String(), name, strings.Join(errs, ", "))
+	}
+	return secretName.String(), nil
+}
+
+func listenerTLSConfig(secretName string) *gatewayv1beta1.GatewayTLSConfig {
+	mode := gatewayv1beta1.TLSModeTerminate
+	group := gatewayv1beta1.Group("")
+	kind := gatewayv1beta1.Kind("Secret")
+	return &gatewayv1beta1.GatewayTLSConfig{
+		Mode: &mode,
+		CertificateRefs: []gatewayv1beta1.SecretObjectReference{
+			{
+				Group: &group,
+				Kind:  &kind,
+				Name:  gatewayv1beta1.ObjectName(secretName),
+			},
+		},
+	}
+}
+
+// isListenerTLSConfig returns true if the TLS config of a listener of a gateway in the namespace terminates TLS with
+// only the given secret
+func isListenerTLSConfig(tls *gatewayv1beta1.GatewayTLSConfig, gatewayNamespace string, secretKey client.ObjectKey) bool {
+	if tls.Mode != nil && *tls.Mode != gatewayv1beta1.TLSModeTerminate {
+		return false
+	}
+	if len(tls.CertificateRefs) != 1 || len(tls.Options) > 0 {
+		return false
+	}
+	ref := tls.CertificateRefs[0]
+	refNamespace := gatewayNamespace
+	if ref.Namespace != nil {
+		refNamespace = string(*ref.Namespace)
+	}
+	return refNamespace == secretKey.Namespace && string(ref.Name) == secretKey.Name &&
+		(ref.Group == nil || *ref.Group == "") && (ref.Kind == nil || *ref.Kind == "Secret")
+}
+
+// managedSecretKey returns the Secret of a managed listener of a gateway in the namespace. The managed listeners
+// annotation holds the name of a Secret in the namespace of the gateway, and namespace/name for any other Secret.
+func managedSecretKey(gatewayNamespace, managedSecret string) client.ObjectKey {
+	if namespace, name, ok := strings.Cut(managedSecret, "/"); ok {
+		return client.ObjectKey{Name: name, Namespace: namespace}
+	}
+	return client.ObjectKey{Name: managedSecret, Namespace: gatewayNamespace}
+}
+
+func formatManagedSecret(gatewayNamespace string, secretKey client.ObjectKey) string {
+	if secretKey.Namespace == gatewayNamespace {
+		return secretKey.Name
+	}
+	return secretKey.String()
+}
+
+func parseManagedListeners(annotation string) map[string]string {
+	managed := map[string]string{}
+	if annotation == "" {
+		return managed
+	}
+	for _, pair := range strings.Split(annotation, ",") {
+		if name, secretName, ok := strings.Cut(pair, "="); ok {
+			managed[name] = secretName
+		}
+	}
+	return managed
+}
+
+func formatManagedListeners(managed map[string]string) string {
+	pairs := make([]string, 0, len(managed))
+	for _, name := range sortedKeys(managed) {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, managed[name]))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,399 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestConfigureListeners(t *testing.T) {
+	hostname := func(h string) *gatewayv1beta1.Hostname {
+		hostname := gatewayv1beta1.Hostname(h)
+		return &hostname
+	}
+	listener := func(name, host string, protocol gatewayv1beta1.ProtocolType, tls *gatewayv1beta1.GatewayTLSConfig) gatewayv1beta1.Listener {
+		return gatewayv1beta1.Listener{
+			Name:     gatewayv1beta1.SectionName(name),
+			Hostname: hostname(host),
+			Port:     443,
+			Protocol: protocol,
+			TLS:      tls,
+		}
+	}
+	passthrough := gatewayv1beta1.TLSModePassthrough
+	userTLS := &gatewayv1beta1.GatewayTLSConfig{Mode: &passthrough}
+
+	testCases := []struct {
+		name           string
+		spec           v1alpha1.TLSPolicySpec
+		listeners      []gatewayv1beta1.Listener
+		annotations    map[string]string
+		wantTLS        map[string]*gatewayv1beta1.GatewayTLSConfig
+		wantAnnotation string
+		wantConflicts  []string
+		wantErr        bool
+	}{
+		{
+			name: "configures selected https listeners only",
+			spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners: true,
+				ListenerHostnames:      []gatewayv1beta1.Hostname{"*.example.com"},
+			},
+			listeners: []gatewayv1beta1.Listener{
+				listener("api", "api.example.com", gatewayv1beta1.HTTPSProtocolType, nil),
+				listener("wildcard", "*.example.com", gatewayv1beta1.HTTPSProtocolType, nil),
+				listener("http", "www.example.com", gatewayv1beta1.HTTPProtocolType, nil),
+				listener("other", "api.other.com", gatewayv1beta1.HTTPSProtocolType, nil),
+			},
+			wantTLS: map[string]*gatewayv1beta1.GatewayTLSConfig{
+				"api":      listenerTLSConfig("gw-api-tls"),
+				"wildcard": listenerTLSConfig("gw-wildcard-tls"),
+				"http":     nil,
+				"other":    nil,
+			},
+			wantAnnotation: "api=gw-api-tls,wildcard=gw-wildcard-tls",
+		},
+		{
+			name: "uses the secret name template",
+			spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners:     true,
+				ListenerHostnames:          []gatewayv1beta1.Hostname{"*.example.com"},
+				ListenerSecretNameTemplate: "{{ .Hostname }}",
+			},
+			listeners: []gatewayv1beta1.Listener{
+				listener("wildcard", "*.example.com", gatewayv1beta1.HTTPSProtocolType, nil),
+			},
+			wantTLS: map[string]*gatewayv1beta1.GatewayTLSConfig{
+				"wildcard": listenerTLSConfig("wildcard.example.com"),
+			},
+			wantAnnotation: "wildcard=wildcard.example.com",
+		},
+		{
+			name: "references the policy secret",
+			spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners: true,
+				ListenerHostnames:      []gatewayv1beta1.Hostname{"api.example.com"},
+				SecretRef:              &corev1.LocalObjectReference{Name: "existing-tls"},
+			},
+			listeners: []gatewayv1beta1.Listener{
+				listener("api", "api.example.com", gatewayv1beta1.HTTPSProtocolType, nil),
+			},
+			wantTLS: map[string]*gatewayv1beta1.GatewayTLSConfig{
+				"api": listenerTLSConfig("existing-tls"),
+			},
+			wantAnnotation: "api=existing-tls",
+		},
+		{
+			name: "updates a listener previously configured by the policy",
+			spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners:     true,
+				ListenerHostnames:          []gatewayv1beta1.Hostname{"api.example.com"},
+				ListenerSecretNameTemplate: "{{ .Name }}-cert",
+			},
+			listeners: []gatewayv1beta1.Listener{
+				listener("api", "api.example.com", gatewayv1beta1.HTTPSProtocolType, listenerTLSConfig("gw-api-tls")),
+			},
+			annotations: map[string]string{TLSPolicyListenersAnnotation: "api=gw-api-tls"},
+			wantTLS: map[string]*gatewayv1beta1.GatewayTLSConfig{
+				"api": listenerTLSConfig("api-cert"),
+			},
+			wantAnnotation: "api=api-cert",
+		},
+		{
+			name: "backs off from a listener configured by other means",
+			spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners: true,
+				ListenerHostnames:      []gatewayv1beta1.Hostname{"*.example.com"},
+			},
+			listeners: []gatewayv1beta1.Listener{
+				listener("api", "api.example.com", gatewayv1beta1.HTTPSProtocolType, userTLS),
+				listener("www", "www.example.com", gatewayv1beta1.HTTPSProtocolType, listenerTLSConfig("user-tls")),
+			},
+			annotations: map[string]string{TLSPolicyListenersAnnotation: "www=gw-www-tls"},
+			wantTLS: map[string]*gatewayv1beta1.GatewayTLSConfig{
+				"api": userTLS,
+				"www": listenerTLSConfig("user-tls"),
+			},
+			wantConflicts: []string{"api", "www"},
+		},
+		{
+			name: "stops managing listeners when disabled",
+			spec: v1alpha1.TLSPolicySpec{},
+			listeners: []gatewayv1beta1.Listener{
+				listener("api", "api.example.com", gatewayv1beta1.HTTPSProtocolType, listenerTLSConfig("gw-api-tls")),
+			},
+			annotations: map[string]string{TLSPolicyListenersAnnotation: "api=gw-api-tls"},
+			wantTLS: map[string]*gatewayv1beta1.GatewayTLSConfig{
+				"api": listenerTLSConfig("gw-api-tls"),
+			},
+		},
+		{
+			name: "points listeners sharing a parent domain at the wildcard secret",
+			spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners: true,
+				ConsolidateWildcard:    true,
+				ListenerHostnames:      []gatewayv1beta1.Hostname{"*.example.com"},
+			},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com", gatewayv1beta1.HTTPSProtocolType, nil),
+				listener("b", "b.example.com", gatewayv1beta1.HTTPSProtocolType, listenerTLSConfig("gw-b-tls")),
+			},
+			annotations: map[string]string{TLSPolicyListenersAnnotation: "b=gw-b-tls"},
+			wantTLS: map[string]*gatewayv1beta1.GatewayTLSConfig{
+				"a": listenerTLSConfig("gw-wildcard-tls"),
+				"b": listenerTLSConfig("gw-wildcard-tls"),
+			},
+			wantAnnotation: "a=gw-wildcard-tls,b=gw-wildcard-tls",
+		},
+		{
+			name: "rejects an invalid secret name",
+			spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners:     true,
+				ListenerHostnames:          []gatewayv1beta1.Hostname{"api.example.com"},
+				ListenerSecretNameTemplate: "{{ .Name }}_tls",
+			},
+			listeners: []gatewayv1beta1.Listener{
+				listener("api", "api.example.com", gatewayv1beta1.HTTPSProtocolType, nil),
+			},
+			wantErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			gateway := &gatewayv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test", Annotations: testCase.annotations},
+				Spec:       gatewayv1beta1.GatewaySpec{Listeners: testCase.listeners},
+			}
+			tlsPolicy := &v1alpha1.TLSPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-tls-policy", Namespace: "test"},
+				Spec:       testCase.spec,
+			}
+
+			conflicts, err := configureListeners(gateway, tlsPolicy, "")
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("configureListeners() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if testCase.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(conflicts, testCase.wantConflicts) {
+				t.Errorf("configureListeners() conflicts = %v, want %v", conflicts, testCase.wantConflicts)
+			}
+			for _, l := range gateway.Spec.Listeners {
+				if !reflect.DeepEqual(l.TLS, testCase.wantTLS[string(l.Name)]) {
+					t.Errorf("listener %s TLS = %+v, want %+v", l.Name, l.TLS, testCase.wantTLS[string(l.Name)])
+				}
+			}
+			if got := gateway.Annotations[TLSPolicyListenersAnnotation]; got != testCase.wantAnnotation {
+				t.Errorf("%s annotation = %q, want %q", TLSPolicyListenersAnnotation, got, testCase.wantAnnotation)
+			}
+		})
+	}
+}
+
+func TestConfigureListeners_sectionName(t *testing.T) {
+	listener := func(name, host string) gatewayv1beta1.Listener {
+		hostname := gatewayv1beta1.Hostname(host)
+		return gatewayv1beta1.Listener{
+			Name:     gatewayv1beta1.SectionName(name),
+			Hostname: &hostname,
+			Port:     443,
+			Protocol: gatewayv1beta1.HTTPSProtocolType,
+		}
+	}
+	policy := func(name, sectionName string) *v1alpha1.TLSPolicy {
+		tlsPolicy := &v1alpha1.TLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: v1alpha1.TLSPolicySpec{
+				AutoConfigureListeners: true,
+				ListenerHostnames:      []gatewayv1beta1.Hostname{"*.example.com"},
+			},
+		}
+		if sectionName != "" {
+			section := gatewayv1beta1.SectionName(sectionName)
+			tlsPolicy.Spec.TargetRef.SectionName = &section
+		}
+		return tlsPolicy
+	}
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gw",
+			Namespace: "test",
+			Annotations: map[string]string{
+				listenerBackRefAnnotation("api"):   "test/api-policy",
+				listenerBackRefAnnotation("admin"): "test/admin-policy",
+			},
+		},
+		Spec: gatewayv1beta1.GatewaySpec{Listeners: []gatewayv1beta1.Listener{
+			listener("api", "api.example.com"),
+			listener("admin", "admin.example.com"),
+			listener("www", "www.example.com"),
+		}},
+	}
+	apiPolicy, adminPolicy, gatewayPolicy := policy("api-policy", "api"), policy("admin-policy", "admin"), policy("gateway-policy", "")
+
+	// every policy only configures the listeners in its section and keeps the listeners managed by the others
+	for _, tlsPolicy := range []*v1alpha1.TLSPolicy{apiPolicy, adminPolicy, gatewayPolicy, apiPolicy} {
+		if _, err := configureListeners(gateway, tlsPolicy, ""); err != nil {
+			t.Fatalf("configureListeners() error = %v", err)
+		}
+	}
+	for _, l := range gateway.Spec.Listeners {
+		if want := listenerTLSConfig("gw-" + string(l.Name) + "-tls"); !reflect.DeepEqual(l.TLS, want) {
+			t.Errorf("listener %s TLS = %+v, want %+v", l.Name, l.TLS, want)
+		}
+	}
+	if got, want := gateway.Annotations[TLSPolicyListenersAnnotation], "admin=gw-admin-tls,api=gw-api-tls,www=gw-www-tls"; got != want {
+		t.Errorf("%s annotation = %q, want %q", TLSPolicyListenersAnnotation, got, want)
+	}
+
+	// the certificates of a listener are only expected for the policy targeting it
+	r := &TLSPolicyReconciler{}
+	for tlsPolicy, want := range map[*v1alpha1.TLSPolicy][]string{
+		apiPolicy:     {"gw-api-tls"},
+		adminPolicy:   {"gw-admin-tls"},
+		gatewayPolicy: {"gw-www-tls"},
+	} {
+		var got []string
+		for _, crt := range r.expectedCertificatesForGateway(context.TODO(), gateway, nil, tlsPolicy) {
+			got = append(got, crt.Name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected certificates of policy %s = %v, want %v", tlsPolicy.Name, got, want)
+		}
+	}
+
+	// disabling auto configuration of a policy stops managing its listener only
+	adminPolicy.Spec.AutoConfigureListeners = false
+	if _, err := configureListeners(gateway, adminPolicy, ""); err != nil {
+		t.Fatalf("configureListeners() error = %v", err)
+	}
+	if got, want := gateway.Annotations[TLSPolicyListenersAnnotation], "api=gw-api-tls,www=gw-www-tls"; got != want {
+		t.Errorf("%s annotation = %q, want %q", TLSPolicyListenersAnnotation, got, want)
+	}
+}
+
+func TestWildcardConsolidation(t *testing.T) {
+	listener := func(name, host string) gatewayv1beta1.Listener {
+		hostname := gatewayv1beta1.Hostname(host)
+		return gatewayv1beta1.Listener{
+			Name:     gatewayv1beta1.SectionName(name),
+			Hostname: &hostname,
+			Port:     443,
+			Protocol: gatewayv1beta1.HTTPSProtocolType,
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		disabled   bool
+		hostnames  []gatewayv1beta1.Hostname
+		listeners  []gatewayv1beta1.Listener
+		wantParent string
+	}{
+		{
+			name:      "consolidates listeners sharing a parent domain",
+			hostnames: []gatewayv1beta1.Hostname{"*.example.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com"),
+				listener("b", "b.example.com"),
+				listener("c", "c.example.com"),
+			},
+			wantParent: "example.com",
+		},
+		{
+			name:      "consolidates a wildcard listener with its subdomains",
+			hostnames: []gatewayv1beta1.Hostname{"*.example.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com"),
+				listener("wildcard", "*.example.com"),
+			},
+			wantParent: "example.com",
+		},
+		{
+			name:      "ignores listeners that are not selected",
+			hostnames: []gatewayv1beta1.Hostname{"*.example.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com"),
+				listener("b", "b.example.com"),
+				listener("other", "api.other.com"),
+			},
+			wantParent: "example.com",
+		},
+		{
+			name:      "does not consolidate mixed domains",
+			hostnames: []gatewayv1beta1.Hostname{"*.example.com", "*.other.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com"),
+				listener("b", "b.example.com"),
+				listener("other", "api.other.com"),
+			},
+		},
+		{
+			name:      "does not consolidate deeper subdomains",
+			hostnames: []gatewayv1beta1.Hostname{"*.example.com", "*.eu.example.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com"),
+				listener("eu", "a.eu.example.com"),
+			},
+		},
+		{
+			name:      "does not consolidate a single listener",
+			hostnames: []gatewayv1beta1.Hostname{"*.example.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com"),
+			},
+		},
+		{
+			name:      "does not consolidate a top level domain",
+			hostnames: []gatewayv1beta1.Hostname{"example.com", "other.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("example", "example.com"),
+				listener("other", "other.com"),
+			},
+		},
+		{
+			name:      "does nothing when disabled",
+			disabled:  true,
+			hostnames: []gatewayv1beta1.Hostname{"*.example.com"},
+			listeners: []gatewayv1beta1.Listener{
+				listener("a", "a.example.com"),
+				listener("b", "b.example.com"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			gateway := &gatewayv1beta1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test"},
+				Spec:       gatewayv1beta1.GatewaySpec{Listeners: testCase.listeners},
+			}
+			tlsPolicy := &v1alpha1.TLSPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-tls-policy", Namespace: "test"},
+				Spec: v1alpha1.TLSPolicySpec{
+					AutoConfigureListeners: true,
+					ConsolidateWildcard:    !testCase.disabled,
+					ListenerHostnames:      testCase.hostnames,
+				},
+			}
+
+			parent, message := wildcardConsolidation(gateway, tlsPolicy)
+			if parent != testCase.wantParent {
+				t.Errorf("wildcardConsolidation() parent = %q, want %q", parent, testCase.wantParent)
+			}
+			if !testCase.disabled && message == "" {
+				t.Errorf("wildcardConsolidation() message is empty")
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package tlspolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// Preview is the plan of a TLSPolicy, the Certificates reconciling it would issue for the listeners of the Gateways
+// it applies to, computed without changing any resource
+type Preview struct {
+	Policy client.ObjectKey
+	// Issuer is the issuer of the Certificates not matching any issuer rule, empty when the listeners use the
+	// Secret of the policy
+	Issuer string
+	// Secret is the Secret of the policy used by the listeners, empty when Certificates are issued
+	Secret    string
+	Listeners []ListenerPreview
+	// Errors are the validation errors reconciling the policy would report
+	Errors []string
+}
+
+// ListenerPreview is the plan of a Gateway listener the policy applies to
+type ListenerPreview struct {
+	Gateway  client.ObjectKey
+	Listener string
+	Hostname string
+	// Certificate is the Certificate issued for the listener, empty when none is
+	Certificate string
+	DNSNames    []string
+	// Issuer is the issuer of the Certificate, which differs from the issuer of the policy when an issuer rule
+	// matches its hosts
+	Issuer string
+	// Reason is why no Certificate is issued for the listener, or why its TLS config is left untouched
+	Reason string
+}
+
+// Preview computes the plan of the policy with the same logic as its reconciliation. Only the errors reading the
+// resources of the plan are returned, the errors reconciling the policy would report are part of the plan.
+func (r *TLSPolicyReconciler) Preview(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) (*Preview, error) {
+	preview := &Preview{Policy: client.ObjectKeyFromObject(tlsPolicy)}
+
+	if err := tlsPolicy.Validate(); err != nil {
+		preview.Errors = append(preview.Errors, err.Error())
+	}
+	if tlsPolicy.Spec.SecretRef != nil {
+		preview.Secret = client.ObjectKey{Name: tlsPolicy.Spec.SecretRef.Name, Namespace: tlsPolicy.Namespace}.String()
+		if err := validateSecret(ctx, r.Client(), tlsPolicy); err != nil {
+			preview.Errors = append(preview.Errors, err.Error())
+		}
+	} else {
+		preview.Issuer = formatIssuerRef(activeIssuerRef(tlsPolicy))
+		for _, issuerRef := range policyIssuerRefs(tlsPolicy) {
+			if err := validateIssuer(ctx, r.Client(), issuerNamespace(tlsPolicy), issuerRef); err != nil {
+				preview.Errors = append(preview.Errors, err.Error())
+			}
+		}
+	}
+
+	targetNetworkObject, err := r.FetchValidTargetRef(ctx, tlsPolicy.GetTargetRef(), tlsPolicy.Namespace)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		preview.Errors = append(preview.Errors, err.Error())
+		return preview, nil
+	}
+	if gateway, ok := targetNetworkObject.(*gatewayv1beta1.Gateway); ok && len(gateway.Spec.Listeners) == 0 {
+		preview.Errors = append(preview.Errors, fmt.Errorf("%w: gateway %s/%s", ErrNoListeners, gateway.Namespace, gateway.Name).Error())
+		return preview, nil
+	}
+	route, _ := targetNetworkObject.(*gatewayv1beta1.HTTPRoute)
+
+	gwDiffObj, err := r.computeGatewayDiffs(ctx, tlsPolicy, targetNetworkObject)
+	if err != nil {
+		return nil, err
+	}
+	gateways := append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...)
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].Key().String() < gateways[j].Key().String()
+	})
+
+	for _, gw := range gateways {
+		// the listeners are configured on a copy of the gateway, as reconciling the policy would before issuing
+		// the Certificates
+		gateway := gw.Gateway.DeepCopy()
+		conflicts, err := configureListeners(gateway, tlsPolicy, r.sharedCertificateNamespace(tlsPolicy))
+		if err != nil {
+			preview.Errors = append(preview.Errors, err.Error())
+			continue
+		}
+
+		var certs []*certmanv1.Certificate
+		if tlsPolicy.Spec.SecretRef == nil {
+			certs = r.expectedCertificatesForGateway(ctx, gateway, route, tlsPolicy)
+			for _, cert := range certs {
+				if err := validateSolverZone(ctx, r.Client(), tlsPolicy, cert.Spec.DNSNames); err != nil {
+					preview.Errors = append(preview.Errors, err.Error())
+				}
+				if err := r.validateCertificateOwner(ctx, cert, tlsPolicy); err != nil {
+					preview.Errors = append(preview.Errors, err.Error())
+				}
+			}
+		}
+
+		for i, l := range gateway.Spec.Listeners {
+			if !listenerInSection(gateway, string(l.Name), tlsPolicy) {
+				continue
+			}
+			listenerPreview := ListenerPreview{Gateway: client.ObjectKeyFromObject(gateway), Listener: string(l.Name)}
+			if l.Hostname != nil {
+				listenerPreview.Hostname = string(*l.Hostname)
+			}
+			err := validateGatewayListenerBlock(field.NewPath("spec", "listeners").Index(i), l, gateway, r.listenerSecretNamespace(tlsPolicy)).ToAggregate()
+			switch {
+			case err != nil:
+				listenerPreview.Reason = err.Error()
+			case tlsPolicy.Spec.SecretRef != nil:
+				listenerPreview.Reason = fmt.Sprintf("the listener uses the Secret %s of the policy", preview.Secret)
+			default:
+				if cert := listenerCertificate(gateway, l, certs); cert != nil {
+					listenerPreview.Certificate = client.ObjectKeyFromObject(cert).String()
+					listenerPreview.DNSNames = cert.Spec.DNSNames
+					listenerPreview.Issuer = formatIssuerRef(cert.Spec.IssuerRef)
+				} else if route != nil {
+					listenerPreview.Reason = fmt.Sprintf("no hostname of HTTPRoute %s/%s matches the listener", route.Namespace, route.Name)
+				}
+			}
+			if slice.ContainsString(conflicts, string(l.Name)) {
+				listenerPreview.Reason = "the listener TLS config was not set by the policy and is left untouched"
+			}
+			preview.Listeners = append(preview.Listeners, listenerPreview)
+		}
+	}
+
+	return preview, nil
+}
+
+// listenerCertificate returns the Certificate of the Secret referenced by the listener, nil when none is issued
+func listenerCertificate(gateway *gatewayv1beta1.Gateway, l gatewayv1beta1.Listener, certs []*certmanv1.Certificate) *certmanv1.Certificate {
+	for _, certRef := range l.TLS.CertificateRefs {
+		namespace := gateway.Namespace
+		if certRef.Namespace != nil {
+			namespace = string(*certRef.Namespace)
+		}
+		for _, cert := range certs {
+			if cert.Namespace == namespace && cert.Spec.SecretName == string(certRef.Name) {
+				return cert
+			}
+		}
+	}
+	return nil
+}
+
+func formatIssuerRef(issuerRef cmmeta.ObjectReference) string {
+	kind := issuerRef.Kind
+	if kind == "" {
+		kind = certmanv1.IssuerKind
+	}
+	return fmt.Sprintf("%s %s", kind, issuerRef.Name)
+}
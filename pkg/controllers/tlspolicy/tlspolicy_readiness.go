@@ -0,0 +1,391 @@
+package tlspolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	CertificatesReadyReason    = "CertificatesReady"
+	CertificatesNotReadyReason = "CertificatesNotReady"
+
+	// the reasons of the Ready condition of a listener certificate, a listener failing to reconcile its Certificate
+	// because another policy manages it has the Conflicted reason
+	ListenerCertificateReadyReason          = "CertificateReady"
+	ListenerCertificatePendingReason        = "CertificatePending"
+	ListenerCertificateFailedReason         = "CertificateFailed"
+	ListenerCertificateIssuanceFailedReason = "CertificateIssuanceFailed"
+
+	// CertificateExpiringSoon is the condition type reporting the Certificates of the policy expiring within the
+	// warning window without being renewed
+	CertificateExpiringSoon       conditions.ConditionType = "CertificateExpiringSoon"
+	CertificateExpiringSoonReason                          = "CertificateExpiringSoon"
+
+	// DefaultCertificateExpiryWarningWindow is the warning window of the CertificateExpiringSoon condition when none
+	// is configured
+	DefaultCertificateExpiryWarningWindow = 7 * 24 * time.Hour
+
+	// pendingCertificateMinRequeue and pendingCertificateMaxRequeue bound the interval at which a policy is
+	// reconciled while one of its Certificates is being issued
+	pendingCertificateMinRequeue = 5 * time.Second
+	pendingCertificateMaxRequeue = 5 * time.Minute
+)
+
+// reconcileCertificatesReadyCondition sets the TLSCertificatesReady condition on the gateways of the diff from the
+// listeners configured by TLSPolicies, and removes it from the gateways without such listeners. The condition covers
+// the listeners of every policy affecting the gateway, so that policies targeting different listeners agree on it.
+func (r *TLSPolicyReconciler) reconcileCertificatesReadyCondition(ctx context.Context, gwDiffObj *reconcilers.GatewayDiff) error {
+	var gateways []common.GatewayWrapper
+	gateways = append(gateways, gwDiffObj.GatewaysWithValidPolicyRef...)
+	gateways = append(gateways, gwDiffObj.GatewaysMissingPolicyRef...)
+	gateways = append(gateways, gwDiffObj.GatewaysWithInvalidPolicyRef...)
+	for _, gw := range gateways {
+		previous := gw.DeepCopy()
+		managed := parseManagedListeners(gw.Annotations[TLSPolicyListenersAnnotation])
+		if len(managed) == 0 {
+			meta.RemoveStatusCondition(&gw.Status.Conditions, string(conditions.ConditionTypeTLSCertificatesReady))
+		} else {
+			cond, err := certificatesReadyCondition(ctx, r.Client(), gw.Gateway, managed)
+			if err != nil {
+				return err
+			}
+			meta.SetStatusCondition(&gw.Status.Conditions, cond)
+		}
+		if !reflect.DeepEqual(previous.Status.Conditions, gw.Status.Conditions) {
+			if err := r.Client().Status().Update(ctx, gw.Gateway); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// certificatesReadyCondition returns the TLSCertificatesReady condition of the gateway for its managed listeners, as
+// listener name to secret name pairs. A listener is ready once its Secret exists and holds a certificate and key.
+func certificatesReadyCondition(ctx context.Context, k8sClient client.Client, gateway *gatewayv1beta1.Gateway, managed map[string]string) (metav1.Condition, error) {
+	var notReady []string
+	for _, listenerName := range sortedKeys(managed) {
+		secret := &corev1.Secret{}
+		if err := k8sClient.Get(ctx, managedSecretKey(gateway.Namespace, managed[listenerName]), secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return metav1.Condition{}, err
+			}
+			notReady = append(notReady, fmt.Sprintf("%s (secret %s not found)", listenerName, managed[listenerName]))
+			continue
+		}
+		if !hasCertificate(secret) {
+			notReady = append(notReady, fmt.Sprintf("%s (secret %s has no certificate)", listenerName, managed[listenerName]))
+		}
+	}
+
+	cond := metav1.Condition{
+		Type:               string(conditions.ConditionTypeTLSCertificatesReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             CertificatesReadyReason,
+		Message:            fmt.Sprintf("TLS certificates of listeners %s are ready", strings.Join(sortedKeys(managed), ", ")),
+		ObservedGeneration: gateway.Generation,
+	}
+	if len(notReady) > 0 {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = CertificatesNotReadyReason
+		cond.Message = fmt.Sprintf("waiting for the TLS certificates of listeners %s", strings.Join(notReady, ", "))
+	}
+	return cond, nil
+}
+
+// listenerCertificatesError holds the errors of the Certificates that failed to reconcile, the Certificates of the
+// other listeners are still reconciled and the policy readiness is decided from the status of its listeners
+type listenerCertificatesError struct {
+	errs []error
+}
+
+func (e *listenerCertificatesError) Error() string {
+	return errors.Join(e.errs...).Error()
+}
+
+func (e *listenerCertificatesError) Unwrap() []error {
+	return e.errs
+}
+
+// gatewayListenerStatuses returns the certificate status of the gateway listeners in the section of the policy that
+// reference the Secret of one of the Certificates, failed holds the errors of the Certificates that failed to
+// reconcile. A listener certificate is ready once its Secret holds a certificate and key, and failed when cert-manager
+// failed to issue it.
+func (r *TLSPolicyReconciler) gatewayListenerStatuses(ctx context.Context, gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy, certs []*certmanv1.Certificate, failed map[client.ObjectKey]error) ([]v1alpha1.TLSPolicyListenerStatus, error) {
+	var listeners []v1alpha1.TLSPolicyListenerStatus
+	for _, l := range gateway.Spec.Listeners {
+		if l.TLS == nil || !listenerInSection(gateway, string(l.Name), tlsPolicy) {
+			continue
+		}
+		cert := listenerCertificate(gateway, l, certs)
+		if cert == nil {
+			continue
+		}
+		cond, err := r.listenerCertificateCondition(ctx, cert, failed[client.ObjectKeyFromObject(cert)])
+		if err != nil {
+			return nil, err
+		}
+		cond.ObservedGeneration = tlsPolicy.Generation
+		listener := v1alpha1.TLSPolicyListenerStatus{
+			Gateway: client.ObjectKeyFromObject(gateway).String(),
+			Name:    string(l.Name),
+		}
+		// the transition time of the condition is kept while its status does not change
+		for _, previous := range tlsPolicy.Status.Listeners {
+			if previous.Gateway == listener.Gateway && previous.Name == listener.Name {
+				listener.Conditions = previous.Conditions
+			}
+		}
+		meta.SetStatusCondition(&listener.Conditions, cond)
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// listenerCertificateCondition returns the Ready condition of a listener certificate, reconcileErr is the error
+// reconciling the Certificate, nil if it reconciled
+func (r *TLSPolicyReconciler) listenerCertificateCondition(ctx context.Context, cert *certmanv1.Certificate, reconcileErr error) (metav1.Condition, error) {
+	cond := metav1.Condition{
+		Type:    string(conditions.ConditionTypeReady),
+		Status:  metav1.ConditionFalse,
+		Reason:  ListenerCertificatePendingReason,
+		Message: fmt.Sprintf("waiting for certificate %s/%s to be issued", cert.Namespace, cert.Name),
+	}
+	if reconcileErr != nil {
+		cond.Reason = ListenerCertificateFailedReason
+		if errors.Is(reconcileErr, ErrCertificateConflict) {
+			cond.Reason = string(conditions.PolicyReasonConflicted)
+		}
+		cond.Message = reconcileErr.Error()
+		return cond, nil
+	}
+
+	existing := &certmanv1.Certificate{}
+	if err := r.Client().Get(ctx, client.ObjectKeyFromObject(cert), existing); client.IgnoreNotFound(err) != nil {
+		return cond, err
+	}
+	if existing.Status.LastFailureTime != nil && !certificateReady(existing) {
+		cond.Reason = ListenerCertificateIssuanceFailedReason
+		cond.Message = fmt.Sprintf("certificate %s/%s failed to be issued", cert.Namespace, cert.Name)
+		for _, c := range existing.Status.Conditions {
+			if c.Type == certmanv1.CertificateConditionReady && c.Message != "" {
+				cond.Message = fmt.Sprintf("%s: %s", cond.Message, c.Message)
+			}
+		}
+		return cond, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client().Get(ctx, client.ObjectKey{Namespace: cert.Namespace, Name: cert.Spec.SecretName}, secret); err != nil {
+		return cond, client.IgnoreNotFound(err)
+	}
+	if hasCertificate(secret) {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ListenerCertificateReadyReason
+		cond.Message = fmt.Sprintf("certificate %s/%s is ready", cert.Namespace, cert.Name)
+	}
+	return cond, nil
+}
+
+// certificateReady returns true if cert-manager reports the Certificate as ready
+func certificateReady(cert *certmanv1.Certificate) bool {
+	for _, c := range cert.Status.Conditions {
+		if c.Type == certmanv1.CertificateConditionReady {
+			return c.Status == cmmeta.ConditionTrue
+		}
+	}
+	return false
+}
+
+// listenerCertificateFailed returns true if the listener certificate failed, a listener waiting for its certificate
+// has not failed
+func listenerCertificateFailed(listener v1alpha1.TLSPolicyListenerStatus) bool {
+	cond := meta.FindStatusCondition(listener.Conditions, string(conditions.ConditionTypeReady))
+	return cond != nil && cond.Status == metav1.ConditionFalse && cond.Reason != ListenerCertificatePendingReason
+}
+
+// listenersReadyCondition returns the Ready condition of the policy from the certificate status of its listeners. With
+// the All readiness policy any failed listener makes the policy not ready, with Any the policy is only not ready once
+// every listener failed, and is partially enabled while some did.
+func listenersReadyCondition(kind string, readinessPolicy v1alpha1.TLSPolicyReadinessPolicy, listeners []v1alpha1.TLSPolicyListenerStatus) *metav1.Condition {
+	cond := &metav1.Condition{
+		Type:    string(conditions.ConditionTypeReady),
+		Status:  metav1.ConditionTrue,
+		Reason:  fmt.Sprintf("%sTLSEnabled", kind),
+		Message: fmt.Sprintf("%s is TLS Enabled", kind),
+	}
+
+	var reason string
+	var failed []string
+	for _, listener := range listeners {
+		if !listenerCertificateFailed(listener) {
+			continue
+		}
+		listenerCond := meta.FindStatusCondition(listener.Conditions, string(conditions.ConditionTypeReady))
+		if reason == "" {
+			reason = listenerCond.Reason
+		}
+		failed = append(failed, fmt.Sprintf("gateway %s listener %s: %s", listener.Gateway, listener.Name, listenerCond.Message))
+	}
+	if len(failed) == 0 {
+		return cond
+	}
+
+	cond.Message = fmt.Sprintf("certificates of %d of %d listeners failed: %s", len(failed), len(listeners), strings.Join(failed, "; "))
+	if readinessPolicy == v1alpha1.TLSPolicyReadinessAny && len(failed) < len(listeners) {
+		cond.Reason = fmt.Sprintf("%sTLSPartiallyEnabled", kind)
+		return cond
+	}
+	cond.Status = metav1.ConditionFalse
+	cond.Reason = reason
+	return cond
+}
+
+// hasCertificate returns true if the Secret holds a certificate and private key
+func hasCertificate(secret *corev1.Secret) bool {
+	return len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0
+}
+
+// pendingCertificatesRequeueAfter returns the time after which the policy should be reconciled again while one of its
+// Certificates is being issued, or zero if none is. The policy is still reconciled as soon as a Certificate changes.
+func (r *TLSPolicyReconciler) pendingCertificatesRequeueAfter(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) (time.Duration, error) {
+	certs, err := r.listPolicyCertificates(ctx, tlsPolicy)
+	if err != nil {
+		return 0, err
+	}
+	return pendingCertificatesBackoff(certs, time.Now()), nil
+}
+
+// listPolicyCertificates returns the Certificates of the policy, including the shared Certificates it uses
+func (r *TLSPolicyReconciler) listPolicyCertificates(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) ([]certmanv1.Certificate, error) {
+	certList := &certmanv1.CertificateList{}
+	if err := r.Client().List(ctx, certList, client.MatchingLabels{
+		TLSPolicyBackRefAnnotation:                              tlsPolicy.Name,
+		fmt.Sprintf("%s-namespace", TLSPolicyBackRefAnnotation): tlsPolicy.Namespace,
+	}); err != nil {
+		return nil, err
+	}
+	sharedCerts, err := r.sharedCertificatesForPolicy(ctx, tlsPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return append(certList.Items, sharedCerts...), nil
+}
+
+// reconcileCertificateExpiry sets the CertificateExpiringSoon condition of the policy and its expiring Certificates
+// gauge from the Certificates expiring within the warning window. A Certificate cert-manager renewed has a later
+// notAfter, so one still inside the window has not been renewed. It returns the time after which the policy should
+// be reconciled again for its next Certificate to enter the window, zero if none will.
+func (r *TLSPolicyReconciler) reconcileCertificateExpiry(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) (time.Duration, error) {
+	certs, err := r.listPolicyCertificates(ctx, tlsPolicy)
+	if err != nil {
+		return 0, err
+	}
+	expiring, requeueAfter := certificatesExpiringSoon(certs, time.Now(), r.certificateExpiryWarningWindow())
+	policyCertificatesExpiringSoon.WithLabelValues(tlsPolicy.Name, tlsPolicy.Namespace).Set(float64(len(expiring)))
+
+	if len(expiring) == 0 {
+		meta.RemoveStatusCondition(&tlsPolicy.Status.Conditions, string(CertificateExpiringSoon))
+		return requeueAfter, nil
+	}
+	messages := make([]string, 0, len(expiring))
+	for _, cert := range expiring {
+		messages = append(messages, fmt.Sprintf("certificate %s/%s expires at %s", cert.Namespace, cert.Name, cert.Status.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	meta.SetStatusCondition(&tlsPolicy.Status.Conditions, metav1.Condition{
+		Type:               string(CertificateExpiringSoon),
+		Status:             metav1.ConditionTrue,
+		Reason:             CertificateExpiringSoonReason,
+		Message:            fmt.Sprintf("%s without being renewed", strings.Join(messages, ", ")),
+		ObservedGeneration: tlsPolicy.Generation,
+	})
+	return requeueAfter, nil
+}
+
+func (r *TLSPolicyReconciler) certificateExpiryWarningWindow() time.Duration {
+	if r.CertificateExpiryWarningWindow == 0 {
+		return DefaultCertificateExpiryWarningWindow
+	}
+	return r.CertificateExpiryWarningWindow
+}
+
+// certificatesExpiringSoon returns the issued Certificates whose notAfter is within the window from now, and the time
+// until the soonest notAfter of the other Certificates enters the window, zero if there is none
+func certificatesExpiringSoon(certs []certmanv1.Certificate, now time.Time, window time.Duration) ([]*certmanv1.Certificate, time.Duration) {
+	var expiring []*certmanv1.Certificate
+	var requeueAfter time.Duration
+	for i := range certs {
+		notAfter := certs[i].Status.NotAfter
+		if notAfter == nil {
+			continue
+		}
+		untilWindow := notAfter.Time.Add(-window).Sub(now)
+		if untilWindow <= 0 {
+			expiring = append(expiring, &certs[i])
+			continue
+		}
+		if requeueAfter == 0 || untilWindow < requeueAfter {
+			requeueAfter = untilWindow
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		return expiring[i].Namespace+"/"+expiring[i].Name < expiring[j].Namespace+"/"+expiring[j].Name
+	})
+	return expiring, requeueAfter
+}
+
+// pendingCertificatesBackoff returns the requeue interval for the Certificates being issued: the min interval plus
+// the time the most recently started Certificate has been issuing for, capped by the max interval. As each requeue
+// adds its interval to the elapsed time, the interval doubles on every reconcile, so slow issuances such as pending
+// ACME orders are checked less and less often.
+func pendingCertificatesBackoff(certs []certmanv1.Certificate, now time.Time) time.Duration {
+	var requeueAfter time.Duration
+	for i := range certs {
+		issuingSince := certificateIssuingSince(&certs[i])
+		if issuingSince == nil {
+			continue
+		}
+		backoff := pendingCertificateMinRequeue
+		if elapsed := now.Sub(*issuingSince); elapsed > 0 {
+			backoff += elapsed
+		}
+		if backoff > pendingCertificateMaxRequeue {
+			backoff = pendingCertificateMaxRequeue
+		}
+		if requeueAfter == 0 || backoff < requeueAfter {
+			requeueAfter = backoff
+		}
+	}
+	return requeueAfter
+}
+
+// certificateIssuingSince returns the time cert-manager started issuing the Certificate, nil if it is not issuing
+func certificateIssuingSince(cert *certmanv1.Certificate) *time.Time {
+	for _, cond := range cert.Status.Conditions {
+		if cond.Type == certmanv1.CertificateConditionIssuing && cond.Status == cmmeta.ConditionTrue && cond.LastTransitionTime != nil {
+			return &cond.LastTransitionTime.Time
+		}
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestCertificatesReadyCondition(t *testing.T) {
+	gateway := &gatewayv1beta1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "test", Generation: 2},
+	}
+	managed := map[string]string{"api": "api-tls"}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-tls", Namespace: "test"},
+	}
+	f := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).Build()
+
+	assertCondition := func(wantStatus metav1.ConditionStatus, wantReason string) {
+		t.Helper()
+		cond, err := certificatesReadyCondition(context.TODO(), f, gateway, managed)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if cond.Type != string(conditions.ConditionTypeTLSCertificatesReady) || cond.Status != wantStatus || cond.Reason != wantReason {
+			t.Errorf("expected condition status %s with reason %s, got %+v", wantStatus, wantReason, cond)
+		}
+		if cond.ObservedGeneration != gateway.Generation {
+			t.Errorf("expected observed generation %d, got %d", gateway.Generation, cond.ObservedGeneration)
+		}
+	}
+
+	// the Certificate is not issued yet
+	assertCondition(metav1.ConditionFalse, CertificatesNotReadyReason)
+
+	// the Secret is created before the certificate is written to it
+	if err := f.Create(context.TODO(), secret); err != nil {
+		t.Fatalf("failed to create secret %v", err)
+	}
+	assertCondition(metav1.ConditionFalse, CertificatesNotReadyReason)
+
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       []byte("cert"),
+		corev1.TLSPrivateKeyKey: []byte("key"),
+	}
+	if err := f.Update(context.TODO(), secret); err != nil {
+		t.Fatalf("failed to update secret %v", err)
+	}
+	assertCondition(metav1.ConditionTrue, CertificatesReadyReason)
+}
+
+func TestPendingCertificatesBackoff(t *testing.T) {
+	issuingSince := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	issuingCert := func(conditionStatus cmmeta.ConditionStatus, since time.Time) certmanv1.Certificate {
+		return certmanv1.Certificate{
+			Status: certmanv1.CertificateStatus{
+				Conditions: []certmanv1.CertificateCondition{
+					{
+						Type:               certmanv1.CertificateConditionIssuing,
+						Status:             conditionStatus,
+						LastTransitionTime: &metav1.Time{Time: since},
+					},
+				},
+			},
+		}
+	}
+	pending := []certmanv1.Certificate{issuingCert(cmmeta.ConditionTrue, issuingSince)}
+
+	if got := pendingCertificatesBackoff(nil, issuingSince); got != 0 {
+		t.Errorf("expected no requeue without certificates, got %v", got)
+	}
+	if got := pendingCertificatesBackoff([]certmanv1.Certificate{issuingCert(cmmeta.ConditionFalse, issuingSince)}, issuingSince.Add(time.Minute)); got != 0 {
+		t.Errorf("expected no requeue for an issued certificate, got %v", got)
+	}
+	if got := pendingCertificatesBackoff(pending, issuingSince); got != pendingCertificateMinRequeue {
+		t.Errorf("expected the minimum requeue of %v for a certificate that just started issuing, got %v", pendingCertificateMinRequeue, got)
+	}
+
+	// each reconcile happens after the previous interval, which keeps growing while the certificate is pending
+	now := issuingSince
+	var previous time.Duration
+	for i := 0; i < 6; i++ {
+		requeueAfter := pendingCertificatesBackoff(pending, now)
+		if requeueAfter <= previous {
+			t.Fatalf("expected the requeue interval to grow past %v after %v pending, got %v", previous, now.Sub(issuingSince), requeueAfter)
+		}
+		previous = requeueAfter
+		now = now.Add(requeueAfter)
+	}
+	if previous != 160*time.Second {
+		t.Errorf("expected the requeue interval to double on every reconcile, got %v after 6 reconciles", previous)
+	}
+
+	if got := pendingCertificatesBackoff(pending, issuingSince.Add(time.Hour)); got != pendingCertificateMaxRequeue {
+		t.Errorf("expected the requeue interval to be capped at %v, got %v", pendingCertificateMaxRequeue, got)
+	}
+
+	// the certificate that started issuing most recently is checked first
+	mixed := append(pending, issuingCert(cmmeta.ConditionTrue, issuingSince.Add(50*time.Second)))
+	if got := pendingCertificatesBackoff(mixed, issuingSince.Add(time.Minute)); got != 15*time.Second {
+		t.Errorf("expected the shortest requeue interval of the pending certificates, got %v", got)
+	}
+}
+
+func TestTLSPolicyReconciler_reconcileCertificateExpiry(t *testing.T) {
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "test").
+		WithTargetGateway("prod-web").
+		WithIssuer("letsencrypt", certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+	policyCert := func(name string, notAfter time.Duration) *certmanv1.Certificate {
+		cert := &certmanv1.Certificate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+				Labels: map[string]string{
+					TLSPolicyBackRefAnnotation:                              tlsPolicy.Name,
+					fmt.Sprintf("%s-namespace", TLSPolicyBackRefAnnotation): tlsPolicy.Namespace,
+				},
+			},
+		}
+		if notAfter != 0 {
+			cert.Status.NotAfter = &metav1.Time{Time: time.Now().Add(notAfter)}
+		}
+		return cert
+	}
+
+	testCases := []struct {
+		name          string
+		certs         []client.Object
+		window        time.Duration
+		wantExpiring  []string
+		wantRequeueGt time.Duration
+	}{
+		{
+			name:         "a certificate expiring in 3 days is inside the default window",
+			certs:        []client.Object{policyCert("api-tls", 3*24*time.Hour), policyCert("db-tls", 0)},
+			wantExpiring: []string{"test/api-tls"},
+		},
+		{
+			name:          "a certificate expiring in 30 days is outside the default window",
+			certs:         []client.Object{policyCert("api-tls", 30*24*time.Hour)},
+			wantRequeueGt: 22 * 24 * time.Hour,
+		},
+		{
+			name:         "the window is configurable",
+			certs:        []client.Object{policyCert("api-tls", 3*24*time.Hour), policyCert("db-tls", 30*24*time.Hour)},
+			window:       31 * 24 * time.Hour,
+			wantExpiring: []string{"test/api-tls", "test/db-tls"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			scheme := testutil.GetValidTestScheme()
+			f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(testCase.certs...).Build()
+			r := &TLSPolicyReconciler{
+				TargetRefReconciler: reconcilers.TargetRefReconciler{
+					BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+				},
+				CertificateExpiryWarningWindow: testCase.window,
+			}
+			policy := tlsPolicy.DeepCopy()
+
+			requeueAfter, err := r.reconcileCertificateExpiry(context.TODO(), policy)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got := promtestutil.ToFloat64(policyCertificatesExpiringSoon.WithLabelValues(policy.Name, policy.Namespace)); got != float64(len(testCase.wantExpiring)) {
+				t.Errorf("expected %d expiring certificates reported by the gauge, got %v", len(testCase.wantExpiring), got)
+			}
+			cond := meta.FindStatusCondition(policy.Status.Conditions, string(CertificateExpiringSoon))
+			if len(testCase.wantExpiring) == 0 {
+				if cond != nil {
+					t.Errorf("expected no %s condition, got %+v", CertificateExpiringSoon, cond)
+				}
+				if requeueAfter <= testCase.wantRequeueGt || requeueAfter > 23*24*time.Hour {
+					t.Errorf("expected a requeue once the certificate enters the window, got %v", requeueAfter)
+				}
+				return
+			}
+			if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != CertificateExpiringSoonReason {
+				t.Fatalf("expected a true %s condition, got %+v", CertificateExpiringSoon, cond)
+			}
+			for _, cert := range testCase.wantExpiring {
+				if !strings.Contains(cond.Message, fmt.Sprintf("certificate %s expires at", cert)) {
+					t.Errorf("expected the condition message to report certificate %s, got %q", cert, cond.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestTLSPolicyReconciler_listenerStatuses(t *testing.T) {
+	gateway := testutil.NewTestGateway("test-gateway", "istio", "test").Gateway
+	gateway.Spec.Listeners = []gatewayv1beta1.Listener{
+		{Name: "api", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("api.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+		{Name: "web", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("web.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+		{Name: "other", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("other.example.org")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+	}
+	// the solver zone cannot solve the challenges of the other listener, whose Certificate fails
+	zone := &v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+	}
+	issuer := testutil.NewTestIssuer("testissuer", "test")
+	tlsSecret := func(name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       []byte("cert"),
+				corev1.TLSPrivateKeyKey: []byte("key"),
+			},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		readinessPolicy v1alpha1.TLSPolicyReadinessPolicy
+		wantStatus      metav1.ConditionStatus
+		wantReason      string
+	}{
+		{
+			name:       "any failed listener fails the policy by default",
+			wantStatus: metav1.ConditionFalse,
+			wantReason: ListenerCertificateFailedReason,
+		},
+		{
+			name:            "any failed listener fails the policy with the All readiness policy",
+			readinessPolicy: v1alpha1.TLSPolicyReadinessAll,
+			wantStatus:      metav1.ConditionFalse,
+			wantReason:      ListenerCertificateFailedReason,
+		},
+		{
+			name:            "the policy is partially enabled with the Any readiness policy",
+			readinessPolicy: v1alpha1.TLSPolicyReadinessAny,
+			wantStatus:      metav1.ConditionTrue,
+			wantReason:      "GatewayTLSPartiallyEnabled",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			tlsPolicy := testutil.NewTestTLSPolicy("test-tls-policy", "test").
+				WithTargetGateway("test-gateway").
+				WithSolverRef("example.com").
+				WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+			tlsPolicy.Spec.AutoConfigureListeners = true
+			tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com", "other.example.org"}
+			tlsPolicy.Spec.ReadinessPolicy = testCase.readinessPolicy
+
+			scheme := testutil.GetValidTestScheme()
+			f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway.DeepCopy(), tlsPolicy, issuer, zone,
+				tlsSecret("test-gateway-api-tls"), tlsSecret("test-gateway-web-tls")).Build()
+			r := &TLSPolicyReconciler{
+				TargetRefReconciler: reconcilers.TargetRefReconciler{
+					BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+				},
+			}
+			ctx := logr.NewContext(context.TODO(), logr.Discard())
+			request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(tlsPolicy)}
+
+			// every step updating the gateway conflicts with the next ones within a reconciliation, which are retried
+			var err error
+			for i := 0; i < 5; i++ {
+				if _, err = r.Reconcile(ctx, request); !apierrors.IsConflict(err) {
+					break
+				}
+			}
+			if !errors.Is(err, ErrSolverZoneMismatch) {
+				t.Fatalf("expected the failed certificate to be retried, got %v", err)
+			}
+
+			policy := &v1alpha1.TLSPolicy{}
+			if err := f.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), policy); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			wantListeners := map[string]string{
+				"api":   ListenerCertificateReadyReason,
+				"web":   ListenerCertificateReadyReason,
+				"other": ListenerCertificateFailedReason,
+			}
+			if len(policy.Status.Listeners) != len(wantListeners) {
+				t.Fatalf("expected %d listener statuses, got %+v", len(wantListeners), policy.Status.Listeners)
+			}
+			for _, listener := range policy.Status.Listeners {
+				cond := meta.FindStatusCondition(listener.Conditions, string(conditions.ConditionTypeReady))
+				if listener.Gateway != "test/test-gateway" || cond == nil || cond.Reason != wantListeners[listener.Name] {
+					t.Errorf("expected listener %s to have reason %s, got %+v", listener.Name, wantListeners[listener.Name], listener)
+				}
+			}
+
+			ready := meta.FindStatusCondition(policy.Status.Conditions, string(conditions.ConditionTypeReady))
+			if ready == nil || ready.Status != testCase.wantStatus || ready.Reason != testCase.wantReason {
+				t.Fatalf("expected the policy ready condition status %s with reason %s, got %+v", testCase.wantStatus, testCase.wantReason, ready)
+			}
+			if !strings.Contains(ready.Message, "certificates of 1 of 3 listeners failed: gateway test/test-gateway listener other") {
+				t.Errorf("expected the ready condition message to report the failed listener, got %q", ready.Message)
+			}
+
+			// the Certificates of the other listeners are still issued
+			certList := &certmanv1.CertificateList{}
+			if err := f.List(ctx, certList); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(certList.Items) != 2 {
+				t.Errorf("expected the certificates of the succeeding listeners, got %d certificates", len(certList.Items))
+			}
+		})
+	}
+}
+
+func TestListenersReadyCondition(t *testing.T) {
+	listener := func(name, reason string) v1alpha1.TLSPolicyListenerStatus {
+		status := metav1.ConditionFalse
+		if reason == ListenerCertificateReadyReason {
+			status = metav1.ConditionTrue
+		}
+		return v1alpha1.TLSPolicyListenerStatus{
+			Gateway: "test/gw",
+			Name:    name,
+			Conditions: []metav1.Condition{
+				{Type: string(conditions.ConditionTypeReady), Status: status, Reason: reason, Message: "message"},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		readinessPolicy v1alpha1.TLSPolicyReadinessPolicy
+		listeners       []v1alpha1.TLSPolicyListenerStatus
+		wantStatus      metav1.ConditionStatus
+		wantReason      string
+	}{
+		{
+			name:       "no listeners",
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "GatewayTLSEnabled",
+		},
+		{
+			name:       "pending listeners do not fail the policy",
+			listeners:  []v1alpha1.TLSPolicyListenerStatus{listener("api", ListenerCertificateReadyReason), listener("web", ListenerCertificatePendingReason)},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "GatewayTLSEnabled",
+		},
+		{
+			name:       "a failed listener fails the policy",
+			listeners:  []v1alpha1.TLSPolicyListenerStatus{listener("api", ListenerCertificateReadyReason), listener("web", string(conditions.PolicyReasonConflicted))},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: string(conditions.PolicyReasonConflicted),
+		},
+		{
+			name:            "a failed listener partially enables the policy with the Any readiness policy",
+			readinessPolicy: v1alpha1.TLSPolicyReadinessAny,
+			listeners:       []v1alpha1.TLSPolicyListenerStatus{listener("api", ListenerCertificatePendingReason), listener("web", ListenerCertificateIssuanceFailedReason)},
+			wantStatus:      metav1.ConditionTrue,
+			wantReason:      "GatewayTLSPartiallyEnabled",
+		},
+		{
+			name:            "all the listeners failed with the Any readiness policy",
+			readinessPolicy: v1alpha1.TLSPolicyReadinessAny,
+			listeners:       []v1alpha1.TLSPolicyListenerStatus{listener("api", ListenerCertificateIssuanceFailedReason), listener("web", ListenerCertificateFailedReason)},
+			wantStatus:      metav1.ConditionFalse,
+			wantReason:      ListenerCertificateIssuanceFailedReason,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cond := listenersReadyCondition("Gateway", testCase.readinessPolicy, testCase.listeners)
+			if cond.Status != testCase.wantStatus || cond.Reason != testCase.wantReason {
+				t.Errorf("expected condition status %s with reason %s, got %+v", testCase.wantStatus, testCase.wantReason, cond)
+			}
+		})
+	}
+}
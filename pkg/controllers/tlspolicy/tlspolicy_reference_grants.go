@@ -0,0 +1,180 @@
+package tlspolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// ReferenceGranted is the condition type reporting whether the Gateways are permitted to reference the Secrets
+// created in the certificate namespace of the policy
+const ReferenceGranted conditions.ConditionType = "ReferenceGranted"
+
+// certificateNamespace returns the namespace the policy creates the Certificates of the configured listeners in
+// instead of the namespace of the Gateway, empty when it creates them in the namespace of the Gateway
+func certificateNamespace(tlsPolicy *v1alpha1.TLSPolicy) string {
+	if !tlsPolicy.Spec.AutoConfigureListeners || tlsPolicy.Spec.SecretRef != nil {
+		return ""
+	}
+	return tlsPolicy.Spec.CertificateNamespace
+}
+
+// issuerNamespace returns the namespace the Issuers of the policy are in, the namespace its Certificates are
+// created in
+func issuerNamespace(tlsPolicy *v1alpha1.TLSPolicy) string {
+	if namespace := certificateNamespace(tlsPolicy); namespace != "" {
+		return namespace
+	}
+	return tlsPolicy.Namespace
+}
+
+// listenerSecretNamespace returns the namespace, other than the namespace of the Gateway, the listeners of the
+// policy may reference Secrets in, empty when there is none
+func (r *TLSPolicyReconciler) listenerSecretNamespace(tlsPolicy *v1alpha1.TLSPolicy) string {
+	if namespace := certificateNamespace(tlsPolicy); namespace != "" {
+		return namespace
+	}
+	return r.sharedCertificateNamespace(tlsPolicy)
+}
+
+// reconcileReferenceGrantedCondition sets the ReferenceGranted condition of a policy with a certificate namespace
+// from the ReferenceGrants in that namespace, and removes it when no listener references a Secret there
+func (r *TLSPolicyReconciler) reconcileReferenceGrantedCondition(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, gwDiffObj *reconcilers.GatewayDiff) error {
+	var gateways []*gatewayv1beta1.Gateway
+	for _, gw := range append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...) {
+		gateways = append(gateways, gw.Gateway)
+	}
+	cond, err := r.referenceGrantedCondition(ctx, tlsPolicy, gateways)
+	if err != nil {
+		return err
+	}
+	if cond == nil {
+		meta.RemoveStatusCondition(&tlsPolicy.Status.Conditions, string(ReferenceGranted))
+		return nil
+	}
+	meta.SetStatusCondition(&tlsPolicy.Status.Conditions, *cond)
+	return nil
+}
+
+// referenceGrantedCondition returns the ReferenceGranted condition of the policy for the gateways, nil when none of
+// their listeners references a Secret in the certificate namespace of the policy. The condition is False, listing
+// the Secrets not permitted, until a ReferenceGrant in the certificate namespace permits every Gateway to reference
+// its Secrets.
+func (r *TLSPolicyReconciler) referenceGrantedCondition(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, gateways []*gatewayv1beta1.Gateway) (*metav1.Condition, error) {
+	namespace := certificateNamespace(tlsPolicy)
+	if namespace == "" {
+		return nil, nil
+	}
+
+	var grants *gatewayv1beta1.ReferenceGrantList
+	var notPermitted []string
+	for _, gateway := range gateways {
+		if gateway.Namespace == namespace {
+			continue
+		}
+		for _, secretName := range listenerSecretsInNamespace(gateway, tlsPolicy, namespace) {
+			if grants == nil {
+				grants = &gatewayv1beta1.ReferenceGrantList{}
+				if err := r.Client().List(ctx, grants, client.InNamespace(namespace)); err != nil {
+					return nil, err
+				}
+			}
+			if !referenceGranted(grants.Items, gateway.Namespace, secretName) {
+				notPermitted = append(notPermitted, fmt.Sprintf("gateway %s/%s to secret %s/%s", gateway.Namespace, gateway.Name, namespace, secretName))
+			}
+		}
+	}
+	if grants == nil {
+		return nil, nil
+	}
+
+	if len(notPermitted) > 0 {
+		return &metav1.Condition{
+			Type:    string(ReferenceGranted),
+			Status:  metav1.ConditionFalse,
+			Reason:  "RefNotPermitted",
+			Message: fmt.Sprintf("no ReferenceGrant in namespace %s permits the references of %s", namespace, strings.Join(notPermitted, ", ")),
+		}, nil
+	}
+	return &metav1.Condition{
+		Type:    string(ReferenceGranted),
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReferenceGranted",
+		Message: fmt.Sprintf("the references to the secrets in namespace %s are permitted by ReferenceGrants", namespace),
+	}, nil
+}
+
+// listenerSecretsInNamespace returns the sorted names of the Secrets in the namespace referenced by the listeners of
+// the gateway the policy applies to
+func listenerSecretsInNamespace(gateway *gatewayv1beta1.Gateway, tlsPolicy *v1alpha1.TLSPolicy, namespace string) []string {
+	var names []string
+	for _, l := range gateway.Spec.Listeners {
+		if l.TLS == nil || !listenerInSection(gateway, string(l.Name), tlsPolicy) {
+			continue
+		}
+		for _, ref := range l.TLS.CertificateRefs {
+			if ref.Namespace == nil || string(*ref.Namespace) != namespace {
+				continue
+			}
+			if name := string(ref.Name); !slice.ContainsString(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// referenceGranted returns true if one of the ReferenceGrants permits the Gateways of the namespace to reference the
+// Secret
+func referenceGranted(grants []gatewayv1beta1.ReferenceGrant, gatewayNamespace, secretName string) bool {
+	for _, grant := range grants {
+		var fromGateway, toSecret bool
+		for _, from := range grant.Spec.From {
+			if from.Group == gatewayv1beta1.GroupName && from.Kind == "Gateway" && string(from.Namespace) == gatewayNamespace {
+				fromGateway = true
+			}
+		}
+		for _, to := range grant.Spec.To {
+			if to.Group == "" && to.Kind == "Secret" && (to.Name == nil || string(*to.Name) == secretName) {
+				toSecret = true
+			}
+		}
+		if fromGateway && toSecret {
+			return true
+		}
+	}
+	return false
+}
+
+// policiesForReferenceGrant returns a request for every policy creating its Certificates in the namespace of the
+// ReferenceGrant
+func (r *TLSPolicyReconciler) policiesForReferenceGrant(obj client.Object) []reconcile.Request {
+	policies := &v1alpha1.TLSPolicyList{}
+	if err := r.Client().List(context.TODO(), policies); err != nil {
+		crlog.Log.Error(err, "failed to list tls policies for reference grant", "referenceGrant", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, policy := range policies.Items {
+		if certificateNamespace(&policy) == obj.GetNamespace() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+		}
+	}
+	return requests
+}
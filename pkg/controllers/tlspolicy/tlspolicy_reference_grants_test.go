@@ -0,0 +1,185 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestTLSPolicyReconciler_certificateNamespace(t *testing.T) {
+	tlsPolicy := testutil.NewTestTLSPolicy("prod-web-tls", "blue").
+		WithTargetGateway("prod-web").
+		WithIssuer("letsencrypt", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	tlsPolicy.Spec.AutoConfigureListeners = true
+	tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+	tlsPolicy.Spec.CertificateNamespace = "certs"
+	if err := tlsPolicy.Validate(); err != nil {
+		t.Fatalf("unexpected validation error %v", err)
+	}
+	gateway := testutil.NewTestGateway("prod-web", "istio", "blue").Gateway
+	gateway.Spec.Listeners = []gatewayv1beta1.Listener{
+		{Name: "api", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("api.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+	}
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway, tlsPolicy).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		// a policy with a certificate namespace does not share its Certificates
+		SharedCertificateNamespace: "shared-certs",
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	reconcile := func() *metav1.Condition {
+		t.Helper()
+		gw := &gatewayv1beta1.Gateway{}
+		if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		wrapper := common.GatewayWrapper{Gateway: gw, PolicyRefsConfig: &TLSPolicyRefsConfig{}}
+		gwDiff := &reconcilers.GatewayDiff{GatewaysMissingPolicyRef: []common.GatewayWrapper{wrapper}}
+		if err := r.reconcileListeners(ctx, tlsPolicy, gwDiff); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if err := r.reconcileReferenceGrantedCondition(ctx, tlsPolicy, gwDiff); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if _, err := r.reconcileCertificates(ctx, tlsPolicy, gw, gwDiff); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(ReferenceGranted))
+	}
+
+	cond := reconcile()
+
+	gw := &gatewayv1beta1.Gateway{}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ref := gw.Spec.Listeners[0].TLS.CertificateRefs[0]
+	if ref.Namespace == nil || *ref.Namespace != "certs" || ref.Name != "prod-web-api-tls" {
+		t.Errorf("expected the listener to reference the Secret certs/prod-web-api-tls, got %v/%s", ref.Namespace, ref.Name)
+	}
+	cert := &certmanv1.Certificate{}
+	if err := f.Get(ctx, client.ObjectKey{Name: "prod-web-api-tls", Namespace: "certs"}, cert); err != nil {
+		t.Fatalf("expected a Certificate in the certificate namespace, got %v", err)
+	}
+	if cert.Spec.SecretName != "prod-web-api-tls" {
+		t.Errorf("expected the Certificate to issue the Secret referenced by the listener, got %s", cert.Spec.SecretName)
+	}
+	if isSharedCertificate(cert) {
+		t.Errorf("expected the Certificate not to be shared")
+	}
+
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "RefNotPermitted" {
+		t.Fatalf("expected the ReferenceGranted condition to be False without a ReferenceGrant, got %v", cond)
+	}
+
+	// a ReferenceGrant for another namespace does not permit the reference
+	grant := &gatewayv1beta1.ReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway-certificates", Namespace: "certs"},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Namespace: "green"}},
+			To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: "Secret"}},
+		},
+	}
+	if err := f.Create(ctx, grant); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cond := reconcile(); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("expected the ReferenceGranted condition to be False with a ReferenceGrant for another namespace, got %v", cond)
+	}
+
+	grant.Spec.From[0].Namespace = "blue"
+	if err := f.Update(ctx, grant); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if cond := reconcile(); cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "ReferenceGranted" {
+		t.Errorf("expected the ReferenceGranted condition to be True with a ReferenceGrant, got %v", cond)
+	}
+
+	// the ReferenceGrant events are mapped to the policies with Certificates in its namespace
+	if err := f.Update(ctx, tlsPolicy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if requests := r.policiesForReferenceGrant(grant); len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(tlsPolicy) {
+		t.Errorf("expected a request for the policy, got %v", requests)
+	}
+}
+
+func TestReferenceGranted(t *testing.T) {
+	secretName := gatewayv1beta1.ObjectName("prod-web-api-tls")
+	otherName := gatewayv1beta1.ObjectName("other-tls")
+	testCases := []struct {
+		name  string
+		grant gatewayv1beta1.ReferenceGrantSpec
+		want  bool
+	}{
+		{
+			name: "all secrets",
+			grant: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Namespace: "blue"}},
+				To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: "Secret"}},
+			},
+			want: true,
+		},
+		{
+			name: "named secret",
+			grant: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Namespace: "blue"}},
+				To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: "Secret", Name: &secretName}},
+			},
+			want: true,
+		},
+		{
+			name: "other secret",
+			grant: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Namespace: "blue"}},
+				To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: "Secret", Name: &otherName}},
+			},
+			want: false,
+		},
+		{
+			name: "from routes",
+			grant: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1beta1.GroupName, Kind: "HTTPRoute", Namespace: "blue"}},
+				To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: "Secret"}},
+			},
+			want: false,
+		},
+		{
+			name: "to services",
+			grant: gatewayv1beta1.ReferenceGrantSpec{
+				From: []gatewayv1beta1.ReferenceGrantFrom{{Group: gatewayv1beta1.GroupName, Kind: "Gateway", Namespace: "blue"}},
+				To:   []gatewayv1beta1.ReferenceGrantTo{{Group: "", Kind: "Service"}},
+			},
+			want: false,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			grants := []gatewayv1beta1.ReferenceGrant{{Spec: testCase.grant}}
+			if got := referenceGranted(grants, "blue", string(secretName)); got != testCase.want {
+				t.Errorf("expected %v, got %v", testCase.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tlspolicy
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+var ErrInvalidSecret = fmt.Errorf("invalid TLS secret")
+
+// validateSecret ensures the Secret referenced by the policy exists and holds a valid certificate and private key
+// pair in its tls.crt and tls.key entries.
+func validateSecret(ctx context.Context, k8sClient client.Client, tlsPolicy *v1alpha1.TLSPolicy) error {
+	secretKey := client.ObjectKey{Name: tlsPolicy.Spec.SecretRef.Name, Namespace: tlsPolicy.Namespace}
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, secretKey, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: secret %s not found", ErrInvalidSecret, secretKey)
+		}
+		return err
+	}
+
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return fmt.Errorf("%w: secret %s has no %s", ErrInvalidSecret, secretKey, key)
+		}
+	}
+
+	if _, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return fmt.Errorf("%w: secret %s: %v", ErrInvalidSecret, secretKey, err)
+	}
+
+	return nil
+}
+
+// policiesForSecret returns a request for every policy in the namespace of the Secret that references it, as its
+// secretRef or as its trust anchor
+func (r *TLSPolicyReconciler) policiesForSecret(obj client.Object) []reconcile.Request {
+	policies := &v1alpha1.TLSPolicyList{}
+	if err := r.Client().List(context.TODO(), policies, client.InNamespace(obj.GetNamespace())); err != nil {
+		crlog.Log.Error(err, "failed to list tls policies for secret", "secret", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, policy := range policies.Items {
+		if (policy.Spec.SecretRef != nil && policy.Spec.SecretRef.Name == obj.GetName()) || trustAnchorSecretName(&policy) == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+		}
+	}
+	return requests
+}
+
+// trustAnchorSecretName returns the name of the trust anchor Secret of the policy, empty when it has none
+func trustAnchorSecretName(tlsPolicy *v1alpha1.TLSPolicy) string {
+	if tlsPolicy.Spec.CertificateValidation == nil || tlsPolicy.Spec.CertificateValidation.TrustAnchorSecretRef == nil {
+		return ""
+	}
+	return tlsPolicy.Spec.CertificateValidation.TrustAnchorSecretRef.Name
+}
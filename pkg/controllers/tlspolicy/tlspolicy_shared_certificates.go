@@ -0,0 +1,234 @@
+package tlspolicy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	// TLSPolicySharedCertificateLabel marks the Certificates, and their Secrets, shared by the Gateways requesting
+	// identical Certificates
+	TLSPolicySharedCertificateLabel = "kuadrant.io/tlspolicy-shared-certificate"
+	// TLSPolicySharedCertificateUsersAnnotation lists, comma separated, the TLSPolicy and Gateway pairs using a shared
+	// Certificate, as policy-namespace/policy-name:gateway-namespace/gateway-name
+	TLSPolicySharedCertificateUsersAnnotation = "kuadrant.io/tlspolicy-shared-certificate-users"
+
+	sharedSecretNameHashLength = 10
+)
+
+// sharedCertificateUser is a Gateway using a shared Certificate for the listeners configured by a TLSPolicy
+type sharedCertificateUser struct {
+	policy  client.ObjectKey
+	gateway client.ObjectKey
+}
+
+func (u sharedCertificateUser) String() string {
+	return fmt.Sprintf("%s:%s", u.policy, u.gateway)
+}
+
+// sharedCertificateUse is the use of a shared Certificate by a Gateway
+type sharedCertificateUse struct {
+	certificate client.ObjectKey
+	user        sharedCertificateUser
+}
+
+// sharedCertificateNamespace returns the namespace the Certificates of the policy are shared in, empty when they are
+// not shared. Certificates are only shared for the listeners configured by a policy issuing them from
+// ClusterIssuers only, as an Issuer can not issue Certificates outside its namespace, and without a certificate
+// namespace of its own.
+func (r *TLSPolicyReconciler) sharedCertificateNamespace(tlsPolicy *v1alpha1.TLSPolicy) string {
+	if r.SharedCertificateNamespace == "" || !tlsPolicy.Spec.AutoConfigureListeners || tlsPolicy.Spec.SecretRef != nil || tlsPolicy.Spec.CertificateNamespace != "" {
+		return ""
+	}
+	for _, issuerRef := range policyIssuerRefs(tlsPolicy) {
+		if issuerRef.Kind != certmanv1.ClusterIssuerKind {
+			return ""
+		}
+	}
+	return r.SharedCertificateNamespace
+}
+
+// sharedSecretName returns the name of the shared Secret for the hostname. The name is derived from the Certificate
+// the policy would issue for the hostname, so that the Gateways requesting identical Certificates share a Secret,
+// and Gateways requesting Certificates that differ in any way, e.g. their issuer, do not.
+func sharedSecretName(tlsPolicy *v1alpha1.TLSPolicy, hostname string) (string, error) {
+	cert := buildCertificate(tlsPolicy, corev1.ObjectReference{}, []string{hostname}, sharedCertificateLabels())
+	data, err := json.Marshal(struct {
+		Labels      map[string]string
+		Annotations map[string]string
+		Spec        certmanv1.CertificateSpec
+	}{cert.Labels, cert.Annotations, cert.Spec})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return fmt.Sprintf("%s-%s", strings.ToLower(strings.ReplaceAll(hostname, "*", "wildcard")), hex.EncodeToString(hash[:])[:sharedSecretNameHashLength]), nil
+}
+
+func sharedCertificateLabels() map[string]string {
+	return map[string]string{
+		TLSPolicySharedCertificateLabel: "true",
+	}
+}
+
+func isSharedCertificate(cert *certmanv1.Certificate) bool {
+	return cert.Labels[TLSPolicySharedCertificateLabel] == "true"
+}
+
+// addSharedCertificateUser returns a mutate func updating a shared Certificate and adding the user to it, the other
+// users of the Certificate are left in place
+func addSharedCertificateUser(user sharedCertificateUser) func(existingObj, desiredObj client.Object) (bool, error) {
+	return func(existingObj, desiredObj client.Object) (bool, error) {
+		existing, ok := existingObj.(*certmanv1.Certificate)
+		if !ok {
+			return false, fmt.Errorf("%T is not a *certmanv1.Certificate", existingObj)
+		}
+		desired, ok := desiredObj.(*certmanv1.Certificate)
+		if !ok {
+			return false, fmt.Errorf("%T is not an *certmanv1.Certificate", desiredObj)
+		}
+
+		update := false
+		if !reflect.DeepEqual(existing.Spec, desired.Spec) {
+			existing.Spec = desired.Spec
+			update = true
+		}
+
+		users := parseSharedCertificateUsers(existing.Annotations[TLSPolicySharedCertificateUsersAnnotation])
+		if !users.Has(user) {
+			users.Insert(user)
+			if existing.Annotations == nil {
+				existing.Annotations = map[string]string{}
+			}
+			existing.Annotations[TLSPolicySharedCertificateUsersAnnotation] = formatSharedCertificateUsers(users)
+			update = true
+		}
+		return update, nil
+	}
+}
+
+// releaseSharedCertificates removes the Gateways of the policy that no longer use the shared Certificates from
+// their users, along with the users whose Gateway was deleted. The shared Certificates left without users are
+// deleted.
+func (r *TLSPolicyReconciler) releaseSharedCertificates(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, inUse sets.Set[sharedCertificateUse]) error {
+	log := crlog.FromContext(ctx)
+
+	if r.SharedCertificateNamespace == "" {
+		return nil
+	}
+	certList := &certmanv1.CertificateList{}
+	if err := r.Client().List(ctx, certList, client.InNamespace(r.SharedCertificateNamespace), client.MatchingLabels(sharedCertificateLabels())); err != nil {
+		return err
+	}
+
+	policyKey := client.ObjectKeyFromObject(tlsPolicy)
+	for i := range certList.Items {
+		cert := &certList.Items[i]
+		previous := cert.Annotations[TLSPolicySharedCertificateUsersAnnotation]
+		users := parseSharedCertificateUsers(previous)
+		for _, user := range users.UnsortedList() {
+			if user.policy == policyKey && !inUse.Has(sharedCertificateUse{certificate: client.ObjectKeyFromObject(cert), user: user}) {
+				users.Delete(user)
+				continue
+			}
+			if err := r.Client().Get(ctx, user.gateway, &gatewayv1beta1.Gateway{}); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return err
+				}
+				users.Delete(user)
+			}
+		}
+
+		if users.Len() == 0 {
+			log.V(1).Info("deleting shared Certificate without users", "certificate", client.ObjectKeyFromObject(cert))
+			if err := r.DeleteResource(ctx, cert); client.IgnoreNotFound(err) != nil {
+				log.Error(err, "failed to delete shared Certificate resource")
+				return err
+			}
+			certificateOperationTotal.WithLabelValues(operationDelete).Inc()
+			continue
+		}
+		if formatSharedCertificateUsers(users) == previous {
+			continue
+		}
+		cert.Annotations[TLSPolicySharedCertificateUsersAnnotation] = formatSharedCertificateUsers(users)
+		if err := r.Client().Update(ctx, cert); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		certificateOperationTotal.WithLabelValues(operationUpdate).Inc()
+	}
+	return nil
+}
+
+// sharedCertificatesForPolicy returns the shared Certificates used by the Gateways of the policy
+func (r *TLSPolicyReconciler) sharedCertificatesForPolicy(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) ([]certmanv1.Certificate, error) {
+	if r.SharedCertificateNamespace == "" {
+		return nil, nil
+	}
+	certList := &certmanv1.CertificateList{}
+	if err := r.Client().List(ctx, certList, client.InNamespace(r.SharedCertificateNamespace), client.MatchingLabels(sharedCertificateLabels())); err != nil {
+		return nil, err
+	}
+	var certs []certmanv1.Certificate
+	for _, cert := range certList.Items {
+		for _, user := range parseSharedCertificateUsers(cert.Annotations[TLSPolicySharedCertificateUsersAnnotation]).UnsortedList() {
+			if user.policy == client.ObjectKeyFromObject(tlsPolicy) {
+				certs = append(certs, cert)
+				break
+			}
+		}
+	}
+	return certs, nil
+}
+
+func parseSharedCertificateUsers(annotation string) sets.Set[sharedCertificateUser] {
+	users := sets.New[sharedCertificateUser]()
+	if annotation == "" {
+		return users
+	}
+	for _, entry := range strings.Split(annotation, ",") {
+		policy, gateway, ok := strings.Cut(entry, ":")
+		if !ok {
+			continue
+		}
+		policyNamespace, policyName, ok := strings.Cut(policy, "/")
+		if !ok {
+			continue
+		}
+		gatewayNamespace, gatewayName, ok := strings.Cut(gateway, "/")
+		if !ok {
+			continue
+		}
+		users.Insert(sharedCertificateUser{
+			policy:  client.ObjectKey{Name: policyName, Namespace: policyNamespace},
+			gateway: client.ObjectKey{Name: gatewayName, Namespace: gatewayNamespace},
+		})
+	}
+	return users
+}
+
+func formatSharedCertificateUsers(users sets.Set[sharedCertificateUser]) string {
+	entries := make([]string, 0, users.Len())
+	for user := range users {
+		entries = append(entries, user.String())
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
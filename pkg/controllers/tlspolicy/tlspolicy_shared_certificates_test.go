@@ -0,0 +1,142 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestTLSPolicyReconciler_sharedCertificates(t *testing.T) {
+	sharedPolicy := func(name, namespace, issuer string) *v1alpha1.TLSPolicy {
+		tlsPolicy := testutil.NewTestTLSPolicy(name, namespace).
+			WithTargetGateway("prod-web").
+			WithIssuer(issuer, certmanv1.ClusterIssuerKind, "cert-manager.io").TLSPolicy
+		tlsPolicy.Spec.AutoConfigureListeners = true
+		tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+		return tlsPolicy
+	}
+	httpsGateway := func(namespace string) *gatewayv1beta1.Gateway {
+		gw := testutil.NewTestGateway("prod-web", "istio", namespace).Gateway
+		gw.Spec.Listeners = []gatewayv1beta1.Listener{
+			{Name: "api", Hostname: testutil.Pointer(gatewayv1beta1.Hostname("api.example.com")), Port: 443, Protocol: gatewayv1beta1.HTTPSProtocolType},
+		}
+		return gw
+	}
+	blue, green, other := httpsGateway("blue"), httpsGateway("green"), httpsGateway("other")
+	bluePolicy, greenPolicy := sharedPolicy("blue-tls", "blue", "letsencrypt"), sharedPolicy("green-tls", "green", "letsencrypt")
+	// a policy using another issuer does not share the Certificate of the others
+	otherPolicy := sharedPolicy("other-tls", "other", "other-ca")
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(blue, green, other, bluePolicy, greenPolicy, otherPolicy).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+		SharedCertificateNamespace: "shared-certs",
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	reconcile := func(tlsPolicy *v1alpha1.TLSPolicy, gateway *gatewayv1beta1.Gateway, applies bool) {
+		t.Helper()
+		gw := &gatewayv1beta1.Gateway{}
+		if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		wrapper := common.GatewayWrapper{Gateway: gw, PolicyRefsConfig: &TLSPolicyRefsConfig{}}
+		gwDiff := &reconcilers.GatewayDiff{GatewaysMissingPolicyRef: []common.GatewayWrapper{wrapper}}
+		if !applies {
+			gwDiff = &reconcilers.GatewayDiff{GatewaysWithInvalidPolicyRef: []common.GatewayWrapper{wrapper}}
+		}
+		if err := r.reconcileListeners(ctx, tlsPolicy, gwDiff); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if _, err := r.reconcileCertificates(ctx, tlsPolicy, gw, gwDiff); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	listenerSecret := func(gateway *gatewayv1beta1.Gateway) client.ObjectKey {
+		t.Helper()
+		gw := &gatewayv1beta1.Gateway{}
+		if err := f.Get(ctx, client.ObjectKeyFromObject(gateway), gw); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		ref := gw.Spec.Listeners[0].TLS.CertificateRefs[0]
+		if ref.Namespace == nil {
+			return client.ObjectKey{Name: string(ref.Name), Namespace: gw.Namespace}
+		}
+		return client.ObjectKey{Name: string(ref.Name), Namespace: string(*ref.Namespace)}
+	}
+	certificates := func() []certmanv1.Certificate {
+		t.Helper()
+		certList := &certmanv1.CertificateList{}
+		if err := f.List(ctx, certList); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return certList.Items
+	}
+
+	reconcile(bluePolicy, blue, true)
+	reconcile(greenPolicy, green, true)
+	reconcile(otherPolicy, other, true)
+
+	certs := certificates()
+	if len(certs) != 2 {
+		t.Fatalf("expected a single Certificate for the gateways requesting identical certificates, got %d Certificates", len(certs))
+	}
+	sharedSecret := listenerSecret(blue)
+	if sharedSecret.Namespace != "shared-certs" || listenerSecret(green) != sharedSecret {
+		t.Errorf("expected both listeners to use a Secret in the shared namespace, got %s and %s", sharedSecret, listenerSecret(green))
+	}
+	if listenerSecret(other) == sharedSecret {
+		t.Errorf("expected the gateway using another issuer not to share the Secret %s", sharedSecret)
+	}
+	shared := &certmanv1.Certificate{}
+	if err := f.Get(ctx, sharedSecret, shared); err != nil {
+		t.Fatalf("expected a shared Certificate %s, got %v", sharedSecret, err)
+	}
+	if users := parseSharedCertificateUsers(shared.Annotations[TLSPolicySharedCertificateUsersAnnotation]); users.Len() != 2 {
+		t.Errorf("expected the shared Certificate to have 2 users, got %v", shared.Annotations[TLSPolicySharedCertificateUsersAnnotation])
+	}
+
+	// the shared Certificate is kept while a gateway still uses it
+	reconcile(bluePolicy, blue, false)
+	if err := f.Get(ctx, sharedSecret, shared); err != nil {
+		t.Fatalf("expected the shared Certificate to be kept for the remaining gateway, got %v", err)
+	}
+	if got := shared.Annotations[TLSPolicySharedCertificateUsersAnnotation]; got != "green/green-tls:green/prod-web" {
+		t.Errorf("expected the green gateway to be the only user, got %s", got)
+	}
+
+	// the shared Certificate is deleted with the last gateway using it
+	gw := &gatewayv1beta1.Gateway{}
+	if err := f.Get(ctx, client.ObjectKeyFromObject(green), gw); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := f.Delete(ctx, gw); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := r.reconcileCertificates(ctx, greenPolicy, nil, &reconcilers.GatewayDiff{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, cert := range certificates() {
+		if isSharedCertificate(&cert) && cert.Name == sharedSecret.Name {
+			t.Errorf("expected the shared Certificate to be deleted with its last gateway")
+		}
+	}
+}
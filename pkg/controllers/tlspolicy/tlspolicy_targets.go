@@ -0,0 +1,131 @@
+package tlspolicy
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// computeGatewayDiffs computes the gateway diffs of the policy, like ComputeGatewayDiffs, for the Gateways of the
+// target network object and the Gateways selected by the target selector. No Gateway is targeted when the target
+// network object is nil or the policy is being deleted.
+func (r *TLSPolicyReconciler) computeGatewayDiffs(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object) (*reconcilers.GatewayDiff, error) {
+	var gwKeys []client.ObjectKey
+	if tlsPolicy.GetDeletionTimestamp() == nil && targetNetworkObject != nil {
+		gwKeys = r.TargetedGatewayKeys(ctx, targetNetworkObject)
+		selected, err := r.selectedGateways(ctx, tlsPolicy)
+		if err != nil {
+			return nil, err
+		}
+		for _, gw := range selected {
+			key := client.ObjectKeyFromObject(&gw)
+			if !slice.Contains(gwKeys, func(k client.ObjectKey) bool { return k == key }) {
+				gwKeys = append(gwKeys, key)
+			}
+		}
+	}
+
+	allGwList := &gatewayv1beta1.GatewayList{}
+	if err := r.Client().List(ctx, allGwList); err != nil {
+		return nil, err
+	}
+
+	policyKey := client.ObjectKeyFromObject(tlsPolicy)
+	gwDiff := &reconcilers.GatewayDiff{
+		GatewaysMissingPolicyRef:     common.GatewaysMissingPolicyRef(allGwList, policyKey, gwKeys, &TLSPolicyRefsConfig{}),
+		GatewaysWithValidPolicyRef:   common.GatewaysWithValidPolicyRef(allGwList, policyKey, gwKeys, &TLSPolicyRefsConfig{}),
+		GatewaysWithInvalidPolicyRef: common.GatewaysWithInvalidPolicyRef(allGwList, policyKey, gwKeys, &TLSPolicyRefsConfig{}),
+	}
+
+	crlog.FromContext(ctx).V(1).Info("computeGatewayDiffs",
+		"#missing-policy-ref", len(gwDiff.GatewaysMissingPolicyRef),
+		"#valid-policy-ref", len(gwDiff.GatewaysWithValidPolicyRef),
+		"#invalid-policy-ref", len(gwDiff.GatewaysWithInvalidPolicyRef),
+	)
+
+	return gwDiff, nil
+}
+
+// selectedGateways returns the Gateways in the namespace of the policy matching its target selector
+func (r *TLSPolicyReconciler) selectedGateways(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy) ([]gatewayv1beta1.Gateway, error) {
+	if tlsPolicy.Spec.TargetSelector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(tlsPolicy.Spec.TargetSelector)
+	if err != nil {
+		return nil, err
+	}
+	gwList := &gatewayv1beta1.GatewayList{}
+	if err := r.Client().List(ctx, gwList, client.InNamespace(tlsPolicy.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	return gwList.Items, nil
+}
+
+// reconcileSelectedBackReferences sets the back reference on the Gateways the policy applies to other than its
+// targetRef, and removes it from the Gateways the policy no longer applies to. The back reference of the targetRef
+// is reconciled separately.
+func (r *TLSPolicyReconciler) reconcileSelectedBackReferences(ctx context.Context, tlsPolicy *v1alpha1.TLSPolicy, targetNetworkObject client.Object, gwDiffObj *reconcilers.GatewayDiff) error {
+	policyKey := client.ObjectKeyFromObject(tlsPolicy)
+	annotationName := targetBackRefAnnotation(tlsPolicy)
+	isTarget := func(gw common.GatewayWrapper) bool {
+		return targetNetworkObject != nil && gw.Key() == client.ObjectKeyFromObject(targetNetworkObject)
+	}
+
+	for _, gw := range gwDiffObj.GatewaysWithInvalidPolicyRef {
+		if isTarget(gw) || gw.Annotations[annotationName] != policyKey.String() {
+			continue
+		}
+		if err := r.DeleteTargetBackReference(ctx, policyKey, gw.Gateway, annotationName); err != nil {
+			return err
+		}
+	}
+
+	// the Gateways of an HTTPRoute target are not direct targets of the policy
+	if _, ok := targetNetworkObject.(*gatewayv1beta1.Gateway); !ok {
+		return nil
+	}
+	for _, gw := range append(gwDiffObj.GatewaysWithValidPolicyRef, gwDiffObj.GatewaysMissingPolicyRef...) {
+		if isTarget(gw) {
+			continue
+		}
+		if err := r.ReconcileTargetBackReference(ctx, policyKey, gw.Gateway, annotationName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// policiesForSelectedGateway returns a request for every policy in the namespace of the Gateway with a target
+// selector matching its labels, so that a Gateway is targeted as soon as it is labelled
+func (r *TLSPolicyReconciler) policiesForSelectedGateway(obj client.Object) []reconcile.Request {
+	policies := &v1alpha1.TLSPolicyList{}
+	if err := r.Client().List(context.TODO(), policies, client.InNamespace(obj.GetNamespace())); err != nil {
+		crlog.Log.Error(err, "failed to list tls policies for gateway", "gateway", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, policy := range policies.Items {
+		if policy.Spec.TargetSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.TargetSelector)
+		if err != nil || !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+	}
+	return requests
+}
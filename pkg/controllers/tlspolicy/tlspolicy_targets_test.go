@@ -0,0 +1,85 @@
+//go:build unit
+
+package tlspolicy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/common"
+	"github.com/kuadrant/kuadrant-operator/pkg/reconcilers"
+
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestTLSPolicyReconciler_computeGatewayDiffs(t *testing.T) {
+	labelledGateway := func(name string, labels map[string]string) *gatewayv1beta1.Gateway {
+		gw := testutil.NewTestGateway(name, "istio", "test").WithHTTPSListener(name+".example.com", name+"-tls").Gateway
+		gw.Labels = labels
+		return gw
+	}
+	blue := labelledGateway("blue", map[string]string{"app": "prod-web"})
+	green := labelledGateway("green", map[string]string{"app": "prod-web"})
+	other := labelledGateway("other", map[string]string{"app": "other"})
+	tlsPolicy := testutil.NewTestTLSPolicy("test-tls-policy", "test").
+		WithTargetGateway("blue").
+		WithTargetSelector(map[string]string{"app": "prod-web"}).
+		WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+	// a gateway previously selected by the policy, which is no longer labelled
+	formerlySelected := labelledGateway("former", nil)
+	formerlySelected.Annotations = map[string]string{TLSPoliciesBackRefAnnotation: `[{"Namespace":"test","Name":"test-tls-policy"}]`}
+
+	scheme := testutil.GetValidTestScheme()
+	f := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tlsPolicy, blue, green, other, formerlySelected).Build()
+	r := &TLSPolicyReconciler{
+		TargetRefReconciler: reconcilers.TargetRefReconciler{
+			BaseReconciler: reconcilers.NewBaseReconciler(f, scheme, f, logr.Discard(), record.NewFakeRecorder(10)),
+		},
+	}
+	ctx := logr.NewContext(context.TODO(), logr.Discard())
+
+	gatewayNames := func(gws []common.GatewayWrapper) []string {
+		var names []string
+		for _, gw := range gws {
+			names = append(names, gw.Name)
+		}
+		return names
+	}
+
+	diff, err := r.computeGatewayDiffs(ctx, tlsPolicy, blue)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if names := gatewayNames(diff.GatewaysMissingPolicyRef); !sets.New(names...).Equal(sets.New("blue", "green")) {
+		t.Errorf("expected the targetRef and the selected gateway to be targeted, got %v", names)
+	}
+	if names := gatewayNames(diff.GatewaysWithInvalidPolicyRef); len(names) != 1 || names[0] != "former" {
+		t.Errorf("expected the gateway no longer selected to have an invalid policy ref, got %v", names)
+	}
+
+	// no gateway is targeted by a policy without a target
+	diff, err = r.computeGatewayDiffs(ctx, tlsPolicy, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(diff.GatewaysMissingPolicyRef) != 0 || len(diff.GatewaysWithValidPolicyRef) != 0 {
+		t.Errorf("expected no gateway to be targeted, got %v", gatewayNames(append(diff.GatewaysMissingPolicyRef, diff.GatewaysWithValidPolicyRef...)))
+	}
+
+	requests := r.policiesForSelectedGateway(green)
+	if len(requests) != 1 || requests[0].NamespacedName != client.ObjectKeyFromObject(tlsPolicy) {
+		t.Errorf("expected the policy to be enqueued for a selected gateway, got %v", requests)
+	}
+	if requests := r.policiesForSelectedGateway(other); len(requests) != 0 {
+		t.Errorf("expected no policy to be enqueued for a gateway that is not selected, got %v", requests)
+	}
+}
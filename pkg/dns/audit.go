@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	// DefaultChangeActor is the actor of the changes reported to a change sink when none is configured
+	DefaultChangeActor = "multicluster-gateway-controller"
+
+	changeWebhookTimeout = 10 * time.Second
+)
+
+// ObjectReference identifies a namespaced resource in a ChangeEvent
+type ObjectReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// RecordChange is a change applied to a record set of a DNS provider
+type RecordChange struct {
+	// Action is one of CREATE, UPDATE or DELETE
+	Action        string `json:"action"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	SetIdentifier string `json:"setIdentifier,omitempty"`
+	// OldValues and OldTTL are the values of the record set before the change, unset for a created record set
+	OldValues []string `json:"oldValues,omitempty"`
+	OldTTL    int64    `json:"oldTTL,omitempty"`
+	// NewValues and NewTTL are the values of the record set after the change, unset for a deleted record set
+	NewValues []string `json:"newValues,omitempty"`
+	NewTTL    int64    `json:"newTTL,omitempty"`
+}
+
+// ChangeEvent describes the changes applied to a managed zone by publishing, or deleting, a DNSRecord
+type ChangeEvent struct {
+	Time  time.Time `json:"time"`
+	Actor string    `json:"actor"`
+	// Zone is the domain name of the managed zone and ZoneID its ID in the DNS provider
+	Zone        string          `json:"zone"`
+	ZoneID      string          `json:"zoneID,omitempty"`
+	ManagedZone ObjectReference `json:"managedZone"`
+	DNSRecord   ObjectReference `json:"dnsRecord"`
+	Changes     []RecordChange  `json:"changes"`
+}
+
+// ChangeSink receives the changes applied to the DNS providers, e.g. to keep an audit trail of the DNS mutations
+type ChangeSink interface {
+	Send(ctx context.Context, event ChangeEvent) error
+}
+
+// Changes returns the changes applied to the record sets of a zone going from the current endpoints to the desired
+// endpoints, sorted by record name, type and set identifier
+func Changes(current, desired []*v1alpha1.Endpoint) []RecordChange {
+	var changes []RecordChange
+	for _, change := range diffEndpoints(current, desired) {
+		endpoint := change.desired
+		if endpoint == nil {
+			endpoint = change.current
+		}
+		recordChange := RecordChange{
+			Action:        change.action,
+			Name:          endpoint.DNSName,
+			Type:          endpoint.RecordType,
+			SetIdentifier: endpoint.SetIdentifier,
+		}
+		if change.current != nil {
+			recordChange.OldValues = change.current.RecordValues()
+			recordChange.OldTTL = int64(change.current.RecordTTL)
+		}
+		if change.desired != nil {
+			recordChange.NewValues = change.desired.RecordValues()
+			recordChange.NewTTL = int64(change.desired.RecordTTL)
+		}
+		changes = append(changes, recordChange)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Name != changes[j].Name {
+			return changes[i].Name < changes[j].Name
+		}
+		if changes[i].Type != changes[j].Type {
+			return changes[i].Type < changes[j].Type
+		}
+		return changes[i].SetIdentifier < changes[j].SetIdentifier
+	})
+	return changes
+}
+
+// WebhookChangeSink posts each ChangeEvent as JSON to a URL
+type WebhookChangeSink struct {
+	url    string
+	actor  string
+	client *http.Client
+}
+
+var _ ChangeSink = &WebhookChangeSink{}
+
+// NewWebhookChangeSink returns a sink posting the changes to the url, reported as made by the actor
+func NewWebhookChangeSink(url, actor string) *WebhookChangeSink {
+	if actor == "" {
+		actor = DefaultChangeActor
+	}
+	return &WebhookChangeSink{
+		url:    url,
+		actor:  actor,
+		client: &http.Client{Timeout: changeWebhookTimeout},
+	}
+}
+
+// Send posts the event to the webhook, any response other than a 2xx is an error
+func (s *WebhookChangeSink) Send(ctx context.Context, event ChangeEvent) error {
+	if event.Actor == "" {
+		event.Actor = s.actor
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the DNS changes to the change webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the change webhook responded to the DNS changes with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+//go:build unit
+
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestChanges(t *testing.T) {
+	current := []*v1alpha1.Endpoint{
+		{DNSName: "a.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60},
+		{DNSName: "b.example.com", Targets: []string{"2.2.2.2"}, RecordType: "A", RecordTTL: 60},
+		{DNSName: "c.example.com", Targets: []string{"3.3.3.3"}, RecordType: "A", RecordTTL: 60},
+	}
+	desired := []*v1alpha1.Endpoint{
+		{DNSName: "a.example.com", Targets: []string{"1.1.1.1", "4.4.4.4"}, RecordType: "A", RecordTTL: 300},
+		{DNSName: "c.example.com", Targets: []string{"3.3.3.3"}, RecordType: "A", RecordTTL: 60},
+		{DNSName: "d.example.com", Targets: []string{"a.example.com"}, RecordType: "CNAME", SetIdentifier: "eu", RecordTTL: 300},
+	}
+	want := []RecordChange{
+		{Action: PlanActionUpdate, Name: "a.example.com", Type: "A", OldValues: []string{"1.1.1.1"}, OldTTL: 60, NewValues: []string{"1.1.1.1", "4.4.4.4"}, NewTTL: 300},
+		{Action: PlanActionDelete, Name: "b.example.com", Type: "A", OldValues: []string{"2.2.2.2"}, OldTTL: 60},
+		{Action: PlanActionCreate, Name: "d.example.com", Type: "CNAME", SetIdentifier: "eu", NewValues: []string{"a.example.com"}, NewTTL: 300},
+	}
+	if got := Changes(current, desired); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changes() = %+v, want %+v", got, want)
+	}
+	if got := Changes(current, current); len(got) != 0 {
+		t.Errorf("expected no changes between identical endpoints, got %+v", got)
+	}
+}
+
+func TestWebhookChangeSink_Send(t *testing.T) {
+	var gotContentType string
+	var gotPayload map[string]interface{}
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			t.Errorf("expected a POST request, got %s", req.Method)
+		}
+		gotContentType = req.Header.Get("Content-Type")
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if err := json.Unmarshal(body, &gotPayload); err != nil {
+			t.Fatalf("expected a JSON payload, got %s: %v", body, err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	event := ChangeEvent{
+		Time:        time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+		Zone:        "example.com",
+		ZoneID:      "Z123",
+		ManagedZone: ObjectReference{Namespace: "test", Name: "example.com"},
+		DNSRecord:   ObjectReference{Namespace: "test", Name: "test.example.com"},
+		Changes: []RecordChange{
+			{Action: PlanActionUpdate, Name: "test.example.com", Type: "A", OldValues: []string{"1.1.1.1"}, OldTTL: 60, NewValues: []string{"2.2.2.2"}, NewTTL: 60},
+			{Action: PlanActionCreate, Name: "www.example.com", Type: "CNAME", NewValues: []string{"test.example.com"}, NewTTL: 300},
+		},
+	}
+	sink := NewWebhookChangeSink(server.URL, "")
+	if err := sink.Send(context.TODO(), event); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if gotContentType != "application/json" {
+		t.Errorf("expected a JSON content type, got %q", gotContentType)
+	}
+	var wantPayload map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"time": "2023-06-01T12:00:00Z",
+		"actor": "multicluster-gateway-controller",
+		"zone": "example.com",
+		"zoneID": "Z123",
+		"managedZone": {"namespace": "test", "name": "example.com"},
+		"dnsRecord": {"namespace": "test", "name": "test.example.com"},
+		"changes": [
+			{"action": "UPDATE", "name": "test.example.com", "type": "A", "oldValues": ["1.1.1.1"], "oldTTL": 60, "newValues": ["2.2.2.2"], "newTTL": 60},
+			{"action": "CREATE", "name": "www.example.com", "type": "CNAME", "newValues": ["test.example.com"], "newTTL": 300}
+		]
+	}`), &wantPayload); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotPayload, wantPayload) {
+		t.Errorf("expected payload %v, got %v", wantPayload, gotPayload)
+	}
+
+	// a webhook not accepting the changes is an error
+	status = http.StatusInternalServerError
+	if err := sink.Send(context.TODO(), event); err == nil {
+		t.Errorf("expected an error when the webhook responds with status %d", status)
+	}
+}
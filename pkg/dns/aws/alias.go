@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// canonicalHostedZones maps the hostname suffix of AWS load balancers to the ID of the hosted zone they are served
+// from, used as the hosted zone of the alias target.
+// https://docs.aws.amazon.com/general/latest/gr/elb.html
+var canonicalHostedZones = map[string]string{
+	// Application and Classic Load Balancers
+	"us-east-1.elb.amazonaws.com":      "Z35SXDOTRQ7X7K",
+	"us-east-2.elb.amazonaws.com":      "Z3AADJGX6KTTL2",
+	"us-west-1.elb.amazonaws.com":      "Z368ELLRRE2KJ0",
+	"us-west-2.elb.amazonaws.com":      "Z1H1FL5HABSF5",
+	"ca-central-1.elb.amazonaws.com":   "ZQSVJUPU6J1EY",
+	"ap-south-1.elb.amazonaws.com":     "ZP97RAFLXTNZK",
+	"ap-northeast-1.elb.amazonaws.com": "Z14GRHDCWA56QT",
+	"ap-northeast-2.elb.amazonaws.com": "ZWKZPGTI48KDX",
+	"ap-southeast-1.elb.amazonaws.com": "Z1LMS91P8CMLE5",
+	"ap-southeast-2.elb.amazonaws.com": "Z1GM3OXH4ZPM65",
+	"eu-central-1.elb.amazonaws.com":   "Z215JYRZR1TBD5",
+	"eu-west-1.elb.amazonaws.com":      "Z32O12XQLNTSW2",
+	"eu-west-2.elb.amazonaws.com":      "ZHURV8PSTC4K8",
+	"eu-west-3.elb.amazonaws.com":      "Z3Q77PNBQS71R4",
+	"eu-north-1.elb.amazonaws.com":     "Z23TAZ7KAW6PD8",
+	"sa-east-1.elb.amazonaws.com":      "Z2P70J7HTTTPLU",
+	// Network Load Balancers
+	"elb.us-east-1.amazonaws.com":      "Z26RNL4JYFTOTI",
+	"elb.us-east-2.amazonaws.com":      "ZLMOA37VPKANP",
+	"elb.us-west-1.amazonaws.com":      "Z24FKFUX50B4VW",
+	"elb.us-west-2.amazonaws.com":      "Z18D5FSROUN65G",
+	"elb.ca-central-1.amazonaws.com":   "Z2EPGBW3API2WT",
+	"elb.ap-south-1.amazonaws.com":     "ZVDDRBQ08TROA",
+	"elb.ap-northeast-1.amazonaws.com": "Z31USIVHYNEOWT",
+	"elb.ap-northeast-2.amazonaws.com": "ZIBE1TIR4HY56",
+	"elb.ap-southeast-1.amazonaws.com": "ZKVM4W9LS7TM",
+	"elb.ap-southeast-2.amazonaws.com": "ZCT6FZBF4DROD",
+	"elb.eu-central-1.amazonaws.com":   "Z3F0SRJ5LGBH90",
+	"elb.eu-west-1.amazonaws.com":      "Z2IFOLAFXWLO4F",
+	"elb.eu-west-2.amazonaws.com":      "ZD4D7Y8KGAS4G",
+	"elb.eu-west-3.amazonaws.com":      "Z1CMS0P5QUZ6D5",
+	"elb.eu-north-1.amazonaws.com":     "Z1UDT6IFJ4EJM",
+	"elb.sa-east-1.amazonaws.com":      "ZTK26PT1VY4CU",
+}
+
+// canonicalHostedZone returns the ID of the hosted zone serving the AWS load balancer hostname, or an empty string
+// if the hostname is not a known load balancer hostname
+func canonicalHostedZone(hostname string) string {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	for suffix, zoneID := range canonicalHostedZones {
+		if strings.HasSuffix(hostname, "."+suffix) {
+			return zoneID
+		}
+	}
+	return ""
+}
+
+// aliasTarget returns the alias target of the endpoint, or nil if the endpoint is not written as an ALIAS record.
+// An endpoint is written as an ALIAS A record when the aws/alias property is "true", or, unless the property is set
+// to another value, when it is a CNAME at the apex of the zone targeting an AWS load balancer, which a CNAME cannot
+// be used for. The hosted zone of the target is taken from the aws/alias-hosted-zone-id property, or looked up from
+// the load balancer hostname.
+func aliasTarget(endpoint *v1alpha1.Endpoint, managedZone *v1alpha1.ManagedZone) (*route53.AliasTarget, error) {
+	prop, explicit := endpoint.GetProviderSpecificProperty(ProviderSpecificAlias)
+	if explicit && prop.Value != "true" {
+		return nil, nil
+	}
+	if !explicit && (endpoint.RecordType != string(v1alpha1.CNAMERecordType) || len(endpoint.Targets) != 1 ||
+		!isZoneApex(endpoint.DNSName, managedZone) || canonicalHostedZone(endpoint.Targets[0]) == "") {
+		return nil, nil
+	}
+	if len(endpoint.Targets) != 1 {
+		return nil, fmt.Errorf("invalid alias record %s, it must have a single target", endpoint.DNSName)
+	}
+	target := endpoint.Targets[0]
+
+	hostedZoneID := canonicalHostedZone(target)
+	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificAliasHostedZoneID); ok {
+		hostedZoneID = prop.Value
+	}
+	if hostedZoneID == "" {
+		return nil, fmt.Errorf("invalid alias record %s, the hosted zone of target %s is unknown and %s is not set", endpoint.DNSName, target, ProviderSpecificAliasHostedZoneID)
+	}
+
+	evaluateTargetHealth := false
+	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificEvaluateTargetHealth); ok {
+		evaluateTargetHealth = prop.Value == "true"
+	}
+
+	return &route53.AliasTarget{
+		DNSName:              aws.String(target),
+		HostedZoneId:         aws.String(hostedZoneID),
+		EvaluateTargetHealth: aws.Bool(evaluateTargetHealth),
+	}, nil
+}
+
+func isZoneApex(dnsName string, managedZone *v1alpha1.ManagedZone) bool {
+	return strings.EqualFold(strings.TrimSuffix(dnsName, "."), strings.TrimSuffix(managedZone.Spec.DomainName, "."))
+}
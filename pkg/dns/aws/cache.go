@@ -0,0 +1,107 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+// DefaultRecordSetCacheTTL is the default time the record sets listed from a hosted zone are reused for
+const DefaultRecordSetCacheTTL = 15 * time.Second
+
+// RecordSetCache keeps the record sets listed from each hosted zone for a short TTL, shared by every provider created
+// with it so that the DNSRecords of a zone reconciled in a row list its record sets once. The record sets of a zone
+// are dropped whenever a provider changes them. The zones are only listed for their zoneOwnership, when the providers
+// have an owner ID or publish CAA records.
+type RecordSetCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex sync.Mutex
+	zones map[string]*cachedRecordSets
+}
+
+type cachedRecordSets struct {
+	recordSets []*route53.ResourceRecordSet
+	expires    time.Time
+	// generation is increased when the zone is invalidated, so that a listing started before a change is not cached
+	generation int
+}
+
+// NewRecordSetCache returns a RecordSetCache keeping the record sets of a hosted zone for ttl. A ttl of zero
+// disables the cache.
+func NewRecordSetCache(ttl time.Duration) *RecordSetCache {
+	return &RecordSetCache{
+		ttl:   ttl,
+		now:   time.Now,
+		zones: map[string]*cachedRecordSets{},
+	}
+}
+
+// List returns the cached record sets of the zone, or the record sets returned by list while they are not cached or
+// have expired. The record sets returned are shared and must not be modified. A nil RecordSetCache calls list.
+func (c *RecordSetCache) List(zoneID string, list func() ([]*route53.ResourceRecordSet, error)) ([]*route53.ResourceRecordSet, error) {
+	if c == nil || c.ttl <= 0 {
+		return list()
+	}
+
+	c.mutex.Lock()
+	zone, ok := c.zones[zoneID]
+	if !ok {
+		zone = &cachedRecordSets{}
+		c.zones[zoneID] = zone
+	}
+	if zone.recordSets != nil && c.now().Before(zone.expires) {
+		recordSets := zone.recordSets
+		c.mutex.Unlock()
+		return recordSets, nil
+	}
+	generation := zone.generation
+	c.mutex.Unlock()
+
+	recordSets, err := list()
+	if err != nil {
+		return nil, err
+	}
+	if recordSets == nil {
+		recordSets = []*route53.ResourceRecordSet{}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if zone.generation == generation {
+		zone.recordSets = recordSets
+		zone.expires = c.now().Add(c.ttl)
+	}
+	return recordSets, nil
+}
+
+// Invalidate drops the record sets of the zone, to be called when they are changed
+func (c *RecordSetCache) Invalidate(zoneID string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if zone, ok := c.zones[zoneID]; ok {
+		zone.recordSets = nil
+		zone.generation++
+	}
+}
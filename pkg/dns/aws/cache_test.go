@@ -0,0 +1,171 @@
+//go:build unit
+
+package aws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/go-logr/logr"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestRecordSetCache_List(t *testing.T) {
+	now := time.Now()
+	cache := NewRecordSetCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	lists := 0
+	list := func() ([]*route53.ResourceRecordSet, error) {
+		lists++
+		return []*route53.ResourceRecordSet{{Name: aws.String("www.example.com.")}}, nil
+	}
+	expectLists := func(want int) {
+		t.Helper()
+		if _, err := cache.List("test-zone", list); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if lists != want {
+			t.Fatalf("expected the zone to be listed %d times, got %d", want, lists)
+		}
+	}
+
+	expectLists(1)
+	expectLists(1)
+	// zones are cached separately
+	if _, err := cache.List("other-zone", list); err != nil || lists != 2 {
+		t.Fatalf("expected the other zone to be listed, got %d lists and error %v", lists, err)
+	}
+
+	cache.Invalidate("test-zone")
+	expectLists(3)
+
+	now = now.Add(time.Minute)
+	expectLists(4)
+
+	// a failed listing is not cached
+	if _, err := cache.List("failed-zone", func() ([]*route53.ResourceRecordSet, error) {
+		return nil, errors.New("throttled")
+	}); err == nil {
+		t.Fatal("expected the listing error to be returned")
+	}
+	if _, err := cache.List("failed-zone", list); err != nil || lists != 5 {
+		t.Fatalf("expected the zone to be listed after a failure, got %d lists and error %v", lists, err)
+	}
+
+	// a listing started before the zone is invalidated is not cached
+	if _, err := cache.List("changed-zone", func() ([]*route53.ResourceRecordSet, error) {
+		cache.Invalidate("changed-zone")
+		return list()
+	}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := cache.List("changed-zone", list); err != nil || lists != 7 {
+		t.Fatalf("expected the zone changed while listed to be listed again, got %d lists and error %v", lists, err)
+	}
+}
+
+func TestRecordSetCache_disabled(t *testing.T) {
+	lists := 0
+	list := func() ([]*route53.ResourceRecordSet, error) {
+		lists++
+		return nil, nil
+	}
+	for _, cache := range []*RecordSetCache{nil, NewRecordSetCache(0)} {
+		lists = 0
+		for i := 0; i < 2; i++ {
+			if _, err := cache.List("test-zone", list); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		}
+		if lists != 2 {
+			t.Errorf("expected a disabled cache to list the zone every time, got %d lists", lists)
+		}
+	}
+}
+
+func TestRoute53DNSProvider_recordSetCache(t *testing.T) {
+	recordSet := func(name, value string) *route53.ResourceRecordSet {
+		return &route53.ResourceRecordSet{
+			Name:            aws.String(name + "."),
+			Type:            aws.String(route53.RRTypeA),
+			TTL:             aws.Int64(60),
+			ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(value)}},
+		}
+	}
+	ownerRecordSet := func(name string) *route53.ResourceRecordSet {
+		return &route53.ResourceRecordSet{
+			Name:            aws.String("_kuadrant-owner-a." + name + "."),
+			Type:            aws.String(route53.RRTypeTxt),
+			ResourceRecords: []*route53.ResourceRecord{{Value: aws.String(`"heritage=kuadrant,kuadrant/owner=test-owner"`)}},
+		}
+	}
+	record := func(name, value string) *v1alpha1.DNSRecord {
+		endpoint := &v1alpha1.Endpoint{DNSName: name, RecordType: "A", RecordTTL: 60, Targets: []string{value}}
+		return &v1alpha1.DNSRecord{
+			Spec:   v1alpha1.DNSRecordSpec{Endpoints: []*v1alpha1.Endpoint{endpoint}},
+			Status: v1alpha1.DNSRecordStatus{Endpoints: []*v1alpha1.Endpoint{endpoint}},
+		}
+	}
+
+	mockClient := &mockZoneRoute53API{recordSets: []*route53.ResourceRecordSet{
+		recordSet("api.example.com", "1.1.1.1"),
+		ownerRecordSet("api.example.com"),
+		recordSet("www.example.com", "1.1.1.1"),
+		ownerRecordSet("www.example.com"),
+	}}
+	now := time.Now()
+	cache := NewRecordSetCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	// the providers are created for each reconcile, and share the cache
+	newTestProvider := func() *Route53DNSProvider {
+		return &Route53DNSProvider{
+			client:         &InstrumentedRoute53{mockClient},
+			logger:         logr.Discard(),
+			ownerID:        "test-owner",
+			recordSetCache: cache,
+		}
+	}
+	zone := &v1alpha1.ManagedZone{
+		Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+	}
+	ensure := func(record *v1alpha1.DNSRecord, wantLists, wantChanges int) {
+		t.Helper()
+		if err := newTestProvider().Ensure(record, zone); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if mockClient.lists != wantLists {
+			t.Errorf("expected the zone to be listed %d times, got %d", wantLists, mockClient.lists)
+		}
+		if len(mockClient.changes) != wantChanges {
+			t.Errorf("expected %d changes, got %v", wantChanges, mockClient.changes)
+		}
+	}
+
+	ensure(record("www.example.com", "1.1.1.1"), 1, 0)
+	// the unchanged records of the zone are reconciled from the cache, without writes
+	ensure(record("www.example.com", "1.1.1.1"), 1, 0)
+	ensure(record("api.example.com", "1.1.1.1"), 1, 0)
+
+	// a write drops the cache of the zone
+	ensure(record("www.example.com", "2.2.2.2"), 1, 1)
+	ensure(record("api.example.com", "1.1.1.1"), 2, 1)
+
+	// the cache expires after the TTL
+	now = now.Add(time.Minute)
+	ensure(record("api.example.com", "1.1.1.1"), 3, 1)
+
+	// without an owner ID the zone is not listed, the records are upserted without using the cache
+	provider := newTestProvider()
+	provider.ownerID = ""
+	if err := provider.Ensure(record("api.example.com", "1.1.1.1"), zone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if mockClient.lists != 3 || len(mockClient.changes) != 2 {
+		t.Errorf("expected the record to be upserted without listing the zone, got %d lists and changes %v", mockClient.lists, mockClient.changes)
+	}
+}
@@ -24,10 +24,11 @@ import (
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
 )
 
 type InstrumentedRoute53 struct {
-	route53 *route53.Route53
+	route53 route53iface.Route53API
 }
 
 func observe(operation string, f func() error) {
@@ -69,6 +70,14 @@ func (c *InstrumentedRoute53) ChangeResourceRecordSets(input *route53.ChangeReso
 	return
 }
 
+func (c *InstrumentedRoute53) ListResourceRecordSets(input *route53.ListResourceRecordSetsInput) (output *route53.ListResourceRecordSetsOutput, err error) {
+	observe("ListResourceRecordSets", func() error {
+		output, err = c.route53.ListResourceRecordSets(input)
+		return err
+	})
+	return
+}
+
 func (c *InstrumentedRoute53) CreateHealthCheck(input *route53.CreateHealthCheckInput) (output *route53.CreateHealthCheckOutput, err error) {
 	observe("CreateHealthCheck", func() error {
 		output, err = c.route53.CreateHealthCheck(input)
@@ -139,3 +148,35 @@ func (c *InstrumentedRoute53) ChangeTagsForResourceWithContext(ctx aws.Context,
 	})
 	return
 }
+
+func (c *InstrumentedRoute53) GetDNSSEC(input *route53.GetDNSSECInput) (output *route53.GetDNSSECOutput, err error) {
+	observe("GetDNSSEC", func() error {
+		output, err = c.route53.GetDNSSEC(input)
+		return err
+	})
+	return
+}
+
+func (c *InstrumentedRoute53) CreateKeySigningKey(input *route53.CreateKeySigningKeyInput) (output *route53.CreateKeySigningKeyOutput, err error) {
+	observe("CreateKeySigningKey", func() error {
+		output, err = c.route53.CreateKeySigningKey(input)
+		return err
+	})
+	return
+}
+
+func (c *InstrumentedRoute53) ActivateKeySigningKey(input *route53.ActivateKeySigningKeyInput) (output *route53.ActivateKeySigningKeyOutput, err error) {
+	observe("ActivateKeySigningKey", func() error {
+		output, err = c.route53.ActivateKeySigningKey(input)
+		return err
+	})
+	return
+}
+
+func (c *InstrumentedRoute53) EnableHostedZoneDNSSEC(input *route53.EnableHostedZoneDNSSECInput) (output *route53.EnableHostedZoneDNSSECOutput, err error) {
+	observe("EnableHostedZoneDNSSEC", func() error {
+		output, err = c.route53.EnableHostedZoneDNSSEC(input)
+		return err
+	})
+	return
+}
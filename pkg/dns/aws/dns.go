@@ -17,11 +17,16 @@ limitations under the License.
 package aws
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/route53"
@@ -31,6 +36,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
 )
@@ -42,44 +48,117 @@ const (
 	ProviderSpecificGeolocationSubdivisionCode = "aws/geolocation-subdivision-code"
 	ProviderSpecificMultiValueAnswer           = "aws/multi-value-answer"
 	ProviderSpecificHealthCheckID              = "aws/health-check-id"
+	ProviderSpecificAlias                      = "aws/alias"
+	ProviderSpecificAliasHostedZoneID          = "aws/alias-hosted-zone-id"
 )
 
+// continentCodes are the continent codes supported by Route53 geolocation routing
+// https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/resource-record-sets-values-geo.html
+var continentCodes = []string{"AF", "AN", "AS", "EU", "OC", "NA", "SA"}
+
+// IsContinentCode returns true if code is a continent code supported by Route53
+func IsContinentCode(code string) bool {
+	return slice.ContainsString(continentCodes, code)
+}
+
+// Route53 limits a ChangeResourceRecordSets request to 1000 resource records, and 32000 characters in their values
+// https://docs.aws.amazon.com/Route53/latest/DeveloperGuide/DNSLimitations.html#limits-api-requests-changeresourcerecordsets
+const (
+	maxBatchRecords     = 1000
+	maxBatchValueLength = 32000
+)
+
+// MinimumRecordTTL is the lowest TTL, in seconds, accepted for a Route53 record set.
+// A zero TTL cannot be distinguished from an unset one and is replaced by dns.DefaultTTL.
+const MinimumRecordTTL = 1
+
 type Route53DNSProvider struct {
 	client *InstrumentedRoute53
 	logger logr.Logger
+	// ownerID identifies the controller in the TXT owner records of the record sets it manages. The records are
+	// published without checking their owner when empty.
+	ownerID string
+	// recordPolicy sets whether the record sets existing at the names of a DNSRecord are adopted, and deleted when
+	// they are not among its endpoints
+	recordPolicy RecordPolicy
+	// zoneFilter restricts the hosted zones the provider changes
+	zoneFilter dns.ZoneFilter
+	// recordSetCache keeps the record sets listed from the hosted zones, shared by the providers
+	recordSetCache *RecordSetCache
 
 	healthCheckReconciler dns.HealthCheckReconciler
 }
 
 var _ dns.Provider = &Route53DNSProvider{}
+var _ dns.ConnectivityChecker = &Route53DNSProvider{}
+var _ dns.DNSSECProvider = &Route53DNSProvider{}
+
+// NewProviderFromSecret returns a Route53DNSProvider using the credentials in the secret. The requests are sent
+// through the rate limiter, and the record sets listed from the hosted zones are kept in the record set cache, which
+// should both be shared by all providers so that they apply across DNSRecords.
+// When ownerID is set, the record sets are only changed if they are owned by it, as recorded in their TXT owner
+// records, and the record policy sets whether the existing record sets are adopted or deleted. Hosted zones that are
+// not allowed by the zone filter are never changed.
+func NewProviderFromSecret(s *v1.Secret, rateLimiter *RateLimiter, recordSetCache *RecordSetCache, ownerID string, recordPolicy RecordPolicy, zoneFilter dns.ZoneFilter) (*Route53DNSProvider, error) {
+	sessionOpts, err := sessionOptions(s)
+	if err != nil {
+		return nil, err
+	}
+	return newProvider(sessionOpts, string(s.Data["REGION"]), rateLimiter, recordSetCache, ownerID, recordPolicy, zoneFilter)
+}
 
-func NewProviderFromSecret(s *v1.Secret) (*Route53DNSProvider, error) {
+// NewProviderFromDefaultCredentials returns a Route53DNSProvider, like NewProviderFromSecret, using the credentials
+// of the default AWS SDK credential chain, e.g. the IAM role of the service account (IRSA) or of the instance
+func NewProviderFromDefaultCredentials(rateLimiter *RateLimiter, recordSetCache *RecordSetCache, ownerID string, recordPolicy RecordPolicy, zoneFilter dns.ZoneFilter) (*Route53DNSProvider, error) {
+	sessionOpts, err := sessionOptions(nil)
+	if err != nil {
+		return nil, err
+	}
+	return newProvider(sessionOpts, "", rateLimiter, recordSetCache, ownerID, recordPolicy, zoneFilter)
+}
 
-	config := aws.NewConfig()
+// sessionOptions returns the options of a session using the static credentials in the secret, or the default AWS
+// SDK credential chain and shared config when the secret is nil. The default chain looks up the credentials in the
+// environment, the web identity token of IRSA, the shared credentials file and the container or instance role, in
+// that order.
+func sessionOptions(s *v1.Secret) (session.Options, error) {
 	sessionOpts := session.Options{
-		Config: *config,
+		Config: *aws.NewConfig(),
+	}
+	if s == nil {
+		sessionOpts.SharedConfigState = session.SharedConfigEnable
+		return sessionOpts, nil
 	}
 	if string(s.Data["AWS_ACCESS_KEY_ID"]) == "" || string(s.Data["AWS_SECRET_ACCESS_KEY"]) == "" {
-		return nil, fmt.Errorf("AWS Provider credentials is empty")
+		return sessionOpts, fmt.Errorf("AWS Provider credentials is empty")
 	}
 
 	sessionOpts.Config.Credentials = credentials.NewStaticCredentials(string(s.Data["AWS_ACCESS_KEY_ID"]), string(s.Data["AWS_SECRET_ACCESS_KEY"]), "")
 	sessionOpts.SharedConfigState = session.SharedConfigDisable
+	return sessionOpts, nil
+}
+
+func newProvider(sessionOpts session.Options, region string, rateLimiter *RateLimiter, recordSetCache *RecordSetCache, ownerID string, recordPolicy RecordPolicy, zoneFilter dns.ZoneFilter) (*Route53DNSProvider, error) {
+	config := aws.NewConfig()
 	sess, err := session.NewSessionWithOptions(sessionOpts)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create aws session: %s", err)
 	}
-	if string(s.Data["REGION"]) != "" {
-		sess.Config.WithRegion(string(s.Data["REGION"]))
+	if region != "" {
+		sess.Config.WithRegion(region)
 	}
 
 	p := &Route53DNSProvider{
-		client: &InstrumentedRoute53{route53.New(sess, config)},
-		logger: log.Log.WithName("aws-route53").WithValues("region", config.Region),
+		client:         &InstrumentedRoute53{&rateLimitedRoute53{Route53API: route53.New(sess, config), limiter: rateLimiter}},
+		logger:         log.Log.WithName("aws-route53").WithValues("region", config.Region),
+		ownerID:        ownerID,
+		recordPolicy:   recordPolicy,
+		zoneFilter:     zoneFilter,
+		recordSetCache: recordSetCache,
 	}
 
-	if err := validateServiceEndpoints(p); err != nil {
-		return nil, fmt.Errorf("failed to validate AWS provider service endpoints: %v", err)
+	if err := p.CheckConnectivity(); err != nil {
+		return nil, fmt.Errorf("failed to validate AWS provider service endpoints: %w", err)
 	}
 
 	return p, nil
@@ -92,15 +171,57 @@ const (
 	deleteAction action = "DELETE"
 )
 
+// Ensure publishes the endpoints of the record. The health checks of the record are created or updated before the
+// record sets using them, and the health checks no longer used are deleted after the record sets. The IDs of the
+// health checks are set on the endpoints of the record.
 func (p *Route53DNSProvider) Ensure(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
-	return p.change(record, managedZone, upsertAction)
+	if len(record.Spec.Endpoints) == 0 {
+		return nil
+	}
+	if err := p.zoneFilter.Validate(managedZone); err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	restoreHealthCheckIDs(record)
+	unchecked, err := p.ensureHealthChecks(ctx, record)
+	if isAuthenticationFailure(err) {
+		return dns.NewAuthenticationError(managedZone, err)
+	}
+	if err != nil {
+		return err
+	}
+	if err := p.change(record, managedZone, upsertAction); err != nil {
+		return err
+	}
+
+	expected := make(map[string]struct{}, len(record.Spec.Endpoints))
+	for _, endpoint := range record.Spec.Endpoints {
+		expected[endpointKey(endpoint)] = struct{}{}
+	}
+	for _, endpoint := range record.Status.Endpoints {
+		if _, found := expected[endpointKey(endpoint)]; !found {
+			unchecked = append(unchecked, endpoint)
+		}
+	}
+	return p.deleteHealthChecks(ctx, unchecked)
 }
 
+// Delete deletes the record sets of the record, and then their health checks
 func (p *Route53DNSProvider) Delete(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
-	return p.change(record, managedZone, deleteAction)
+	// the record sets are matched with their health check IDs, which are not kept in the record
+	record = record.DeepCopy()
+	restoreHealthCheckIDs(record)
+	if err := p.change(record, managedZone, deleteAction); err != nil {
+		return err
+	}
+	return p.deleteHealthChecks(context.TODO(), record.Spec.Endpoints)
 }
 
 func (p *Route53DNSProvider) EnsureManagedZone(zone *v1alpha1.ManagedZone) (dns.ManagedZoneOutput, error) {
+	if err := p.zoneFilter.Validate(zone); err != nil {
+		return dns.ManagedZoneOutput{}, err
+	}
+
 	var zoneID string
 	if zone.Spec.ID != "" {
 		zoneID = zone.Spec.ID
@@ -114,6 +235,9 @@ func (p *Route53DNSProvider) EnsureManagedZone(zone *v1alpha1.ManagedZone) (dns.
 		getResp, err := p.client.GetHostedZone(&route53.GetHostedZoneInput{
 			Id: &zoneID,
 		})
+		if isAuthenticationFailure(err) {
+			return managedZoneOutput, dns.NewAuthenticationError(zone, err)
+		}
 		if err != nil {
 			log.Log.Error(err, "failed to get hosted zone")
 			return managedZoneOutput, err
@@ -147,6 +271,9 @@ func (p *Route53DNSProvider) EnsureManagedZone(zone *v1alpha1.ManagedZone) (dns.
 			PrivateZone: aws.Bool(false),
 		},
 	})
+	if isAuthenticationFailure(err) {
+		return managedZoneOutput, dns.NewAuthenticationError(zone, err)
+	}
 	if err != nil {
 		log.Log.Error(err, "failed to create hosted zone")
 		return managedZoneOutput, err
@@ -158,9 +285,15 @@ func (p *Route53DNSProvider) EnsureManagedZone(zone *v1alpha1.ManagedZone) (dns.
 }
 
 func (p *Route53DNSProvider) DeleteManagedZone(zone *v1alpha1.ManagedZone) error {
+	if err := p.zoneFilter.Validate(zone); err != nil {
+		return err
+	}
 	_, err := p.client.DeleteHostedZone(&route53.DeleteHostedZoneInput{
 		Id: &zone.Status.ID,
 	})
+	if isAuthenticationFailure(err) {
+		return dns.NewAuthenticationError(zone, err)
+	}
 	if err != nil {
 		log.Log.Error(err, "failed to delete hosted zone")
 		return err
@@ -168,6 +301,96 @@ func (p *Route53DNSProvider) DeleteManagedZone(zone *v1alpha1.ManagedZone) error
 	return nil
 }
 
+// dnssecKeySigningKeyName is the name of the key-signing key created for the hosted zones signed by the provider
+const dnssecKeySigningKeyName = "mgc"
+
+// EnsureDNSSEC implements dns.DNSSECProvider. The hosted zone is signed with its active key-signing key, an
+// inactive one is activated, and one is created with the KMS key of the managed zone when it has none. Signing is
+// only enabled when the zone is not already signed.
+func (p *Route53DNSProvider) EnsureDNSSEC(zone *v1alpha1.ManagedZone) (string, error) {
+	if err := p.zoneFilter.Validate(zone); err != nil {
+		return "", err
+	}
+	zoneID := zone.Spec.ID
+	if zoneID == "" {
+		zoneID = zone.Status.ID
+	}
+	if zoneID == "" {
+		return "", fmt.Errorf("the hosted zone of managed zone %s has not been created", zone.Name)
+	}
+
+	dnssec, err := p.client.GetDNSSEC(&route53.GetDNSSECInput{HostedZoneId: &zoneID})
+	if isAuthenticationFailure(err) {
+		return "", dns.NewAuthenticationError(zone, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get the DNSSEC status of hosted zone %s: %w", zoneID, err)
+	}
+
+	ksk, err := p.ensureKeySigningKey(zone, zoneID, dnssec.KeySigningKeys)
+	if err != nil {
+		return "", err
+	}
+
+	if aws.StringValue(dnssec.Status.ServeSignature) != "SIGNING" {
+		_, err = p.client.EnableHostedZoneDNSSEC(&route53.EnableHostedZoneDNSSECInput{HostedZoneId: &zoneID})
+		if isAuthenticationFailure(err) {
+			return "", dns.NewAuthenticationError(zone, err)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to enable DNSSEC signing of hosted zone %s: %w", zoneID, err)
+		}
+		p.logger.Info("enabled DNSSEC signing", "zoneID", zoneID, "keySigningKey", aws.StringValue(ksk.Name))
+	}
+	return aws.StringValue(ksk.DSRecord), nil
+}
+
+// ensureKeySigningKey returns the active key-signing key of the hosted zone, activating or creating one when there is
+// none
+func (p *Route53DNSProvider) ensureKeySigningKey(zone *v1alpha1.ManagedZone, zoneID string, keys []*route53.KeySigningKey) (*route53.KeySigningKey, error) {
+	var inactive *route53.KeySigningKey
+	for _, key := range keys {
+		switch aws.StringValue(key.Status) {
+		case "ACTIVE":
+			return key, nil
+		case "INACTIVE":
+			if inactive == nil {
+				inactive = key
+			}
+		}
+	}
+
+	if inactive != nil {
+		_, err := p.client.ActivateKeySigningKey(&route53.ActivateKeySigningKeyInput{HostedZoneId: &zoneID, Name: inactive.Name})
+		if isAuthenticationFailure(err) {
+			return nil, dns.NewAuthenticationError(zone, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to activate key-signing key %s of hosted zone %s: %w", aws.StringValue(inactive.Name), zoneID, err)
+		}
+		return inactive, nil
+	}
+
+	if zone.Spec.DNSSECKMSKeyARN == "" {
+		return nil, fmt.Errorf("hosted zone %s has no key-signing key, dnssecKMSKeyARN must be set to create one", zoneID)
+	}
+	callerRef := time.Now().Format("20060102150405")
+	createResp, err := p.client.CreateKeySigningKey(&route53.CreateKeySigningKeyInput{
+		CallerReference:         &callerRef,
+		HostedZoneId:            &zoneID,
+		KeyManagementServiceArn: &zone.Spec.DNSSECKMSKeyARN,
+		Name:                    aws.String(dnssecKeySigningKeyName),
+		Status:                  aws.String("ACTIVE"),
+	})
+	if isAuthenticationFailure(err) {
+		return nil, dns.NewAuthenticationError(zone, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the key-signing key of hosted zone %s: %w", zoneID, err)
+	}
+	return createResp.KeySigningKey, nil
+}
+
 func (p *Route53DNSProvider) HealthCheckReconciler() dns.HealthCheckReconciler {
 	if p.healthCheckReconciler == nil {
 		p.healthCheckReconciler = dns.NewCachedHealthCheckReconciler(
@@ -191,9 +414,18 @@ func (p *Route53DNSProvider) change(record *v1alpha1.DNSRecord, managedZone *v1a
 	if len(record.Spec.Endpoints) == 0 {
 		return nil
 	}
-	err := p.updateRecord(record, managedZone.Status.ID, string(action))
+	if err := p.zoneFilter.Validate(managedZone); err != nil {
+		return err
+	}
+	err := p.updateRecord(record, managedZone, string(action))
+	if isAuthenticationFailure(err) {
+		return dns.NewAuthenticationError(managedZone, err)
+	}
+	if isNoSuchHostedZone(err) {
+		return fmt.Errorf("%w: route53 hosted zone %s: %v", dns.ErrZoneNotFound, managedZone.Status.ID, err)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update record in route53 hosted zone %s: %v", managedZone.Status.ID, err)
+		return fmt.Errorf("failed to update record in route53 hosted zone %s: %w", managedZone.Status.ID, err)
 	}
 	switch action {
 	case upsertAction:
@@ -204,19 +436,19 @@ func (p *Route53DNSProvider) change(record *v1alpha1.DNSRecord, managedZone *v1a
 	return nil
 }
 
-func (p *Route53DNSProvider) updateRecord(record *v1alpha1.DNSRecord, zoneID, action string) error {
+func (p *Route53DNSProvider) updateRecord(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone, action string) error {
 
 	if len(record.Spec.Endpoints) == 0 {
 		return fmt.Errorf("no endpoints")
 	}
 
-	input := route53.ChangeResourceRecordSetsInput{HostedZoneId: aws.String(zoneID)}
+	zoneID := managedZone.Status.ID
 
 	expectedEndpointsMap := make(map[string]struct{})
 	var changes []*route53.Change
 	for _, endpoint := range record.Spec.Endpoints {
-		expectedEndpointsMap[endpoint.SetID()] = struct{}{}
-		change, err := p.changeForEndpoint(endpoint, action)
+		expectedEndpointsMap[endpointKey(endpoint)] = struct{}{}
+		change, err := p.changeForEndpoint(endpoint, managedZone, action)
 		if err != nil {
 			return err
 		}
@@ -227,8 +459,8 @@ func (p *Route53DNSProvider) updateRecord(record *v1alpha1.DNSRecord, zoneID, ac
 	if action != string(deleteAction) {
 		lastPublishedEndpoints := record.Status.Endpoints
 		for _, endpoint := range lastPublishedEndpoints {
-			if _, found := expectedEndpointsMap[endpoint.SetID()]; !found {
-				change, err := p.changeForEndpoint(endpoint, string(deleteAction))
+			if _, found := expectedEndpointsMap[endpointKey(endpoint)]; !found {
+				change, err := p.changeForEndpoint(endpoint, managedZone, string(deleteAction))
 				if err != nil {
 					return err
 				}
@@ -237,44 +469,191 @@ func (p *Route53DNSProvider) updateRecord(record *v1alpha1.DNSRecord, zoneID, ac
 		}
 	}
 
-	if len(changes) == 0 {
+	if action != string(deleteAction) && p.recordPolicy == RecordPolicyStrictDelete {
+		deletes, err := p.foreignDeletes(managedZone, changes)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, deletes...)
+	}
+
+	var conflicts []string
+	if action != string(deleteAction) && p.recordPolicy == RecordPolicyNone {
+		var err error
+		changes, conflicts, err = p.caaConflicts(record, managedZone, changes)
+		if err != nil {
+			return err
+		}
+	}
+	if p.ownerID != "" {
+		owned, ownerConflicts, err := p.ownedChanges(record, managedZone, changes)
+		if err != nil {
+			return err
+		}
+		changes, conflicts = owned, append(conflicts, ownerConflicts...)
+	}
+
+	if err := p.applyChanges(record, zoneID, changes); err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
 		return nil
 	}
-	input.ChangeBatch = &route53.ChangeBatch{
-		Changes: changes,
+	// the record sets of another owner are left behind on delete, so that the DNSRecord can still be removed
+	if action == string(deleteAction) {
+		p.logger.Info("Skipped deleting DNS records owned by another controller", "record", record.Name, "zone", zoneID, "names", conflicts)
+		return nil
 	}
-	resp, err := p.client.ChangeResourceRecordSets(&input)
-	if err != nil {
-		return fmt.Errorf("couldn't update DNS record %s in zone %s: %v", record.Name, zoneID, err)
+	return fmt.Errorf("%w: %v", dns.ErrOwnershipConflict, conflicts)
+}
+
+func (p *Route53DNSProvider) applyChanges(record *v1alpha1.DNSRecord, zoneID string, changes []*route53.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	// the record sets cached are dropped even when a batch fails, as the batches before it were applied
+	defer p.recordSetCache.Invalidate(zoneID)
+	input := route53.ChangeResourceRecordSetsInput{HostedZoneId: aws.String(zoneID)}
+	batches := changeBatches(changes, maxBatchRecords, maxBatchValueLength)
+	for i, batch := range batches {
+		input.ChangeBatch = &route53.ChangeBatch{
+			Changes: batch,
+		}
+		resp, err := p.client.ChangeResourceRecordSets(&input)
+		if err != nil {
+			return fmt.Errorf("couldn't update DNS record %s in zone %s (batch %d of %d): %w", record.Name, zoneID, i+1, len(batches), err)
+		}
+		p.logger.Info("Updated DNS record", "record", record, "zone", zoneID, "batch", i+1, "batches", len(batches), "response", resp)
 	}
-	p.logger.Info("Updated DNS record", "record", record, "zone", zoneID, "response", resp)
 	return nil
 }
 
-func (p *Route53DNSProvider) changeForEndpoint(endpoint *v1alpha1.Endpoint, action string) (*route53.Change, error) {
-	if endpoint.RecordType != string(v1alpha1.ARecordType) && endpoint.RecordType != string(v1alpha1.CNAMERecordType) && endpoint.RecordType != string(v1alpha1.NSRecordType) {
+// isNoSuchHostedZone returns true if the error was returned by Route53 for a hosted zone that does not exist, e.g.
+// one deleted outside of the controller
+func isNoSuchHostedZone(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == route53.ErrCodeNoSuchHostedZone
+}
+
+// authenticationFailureCodes are the codes of the errors returned by AWS for credentials that are invalid, expired,
+// or not permitted to call Route53
+var authenticationFailureCodes = []string{
+	"AccessDenied",
+	"AccessDeniedException",
+	"ExpiredToken",
+	"ExpiredTokenException",
+	"IncompleteSignature",
+	"InvalidAccessKeyId",
+	"InvalidClientTokenId",
+	"SignatureDoesNotMatch",
+	"UnrecognizedClientException",
+}
+
+// isAuthenticationFailure returns true if the error was returned by AWS for credentials it rejected
+func isAuthenticationFailure(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && slice.ContainsString(authenticationFailureCodes, awsErr.Code())
+}
+
+// changeBatches returns the changes split into as few batches as possible without exceeding the Route53 limits on
+// the number of resource records, and the total length of their values, in a single request. Deletes are placed
+// before the creates and updates, so that the names they free up can be reused within the same request.
+// A change exceeding the limits on its own is placed in its own batch.
+func changeBatches(changes []*route53.Change, maxRecords, maxValueLength int) [][]*route53.Change {
+	ordered := make([]*route53.Change, len(changes))
+	copy(ordered, changes)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return aws.StringValue(ordered[i].Action) == route53.ChangeActionDelete && aws.StringValue(ordered[j].Action) != route53.ChangeActionDelete
+	})
+
+	var batches [][]*route53.Change
+	var batch []*route53.Change
+	records, valueLength := 0, 0
+	for _, change := range ordered {
+		changeRecords, changeValueLength := changeSize(change)
+		if len(batch) > 0 && (records+changeRecords > maxRecords || valueLength+changeValueLength > maxValueLength) {
+			batches = append(batches, batch)
+			batch, records, valueLength = nil, 0, 0
+		}
+		batch = append(batch, change)
+		records += changeRecords
+		valueLength += changeValueLength
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// changeSize returns the number of resource records and the length of their values counted by Route53 towards the
+// request limits. UPSERT changes are counted twice.
+func changeSize(change *route53.Change) (int, int) {
+	records, valueLength := 0, 0
+	for _, resourceRecord := range change.ResourceRecordSet.ResourceRecords {
+		records++
+		valueLength += len(aws.StringValue(resourceRecord.Value))
+	}
+	if aws.StringValue(change.Action) == route53.ChangeActionUpsert {
+		return 2 * records, 2 * valueLength
+	}
+	return records, valueLength
+}
+
+// endpointKey identifies the record set of an endpoint, the A and AAAA record sets of a dual stack host share their
+// name and set identifier
+func endpointKey(endpoint *v1alpha1.Endpoint) string {
+	return endpoint.SetID() + "/" + endpoint.RecordType
+}
+
+func (p *Route53DNSProvider) changeForEndpoint(endpoint *v1alpha1.Endpoint, managedZone *v1alpha1.ManagedZone, action string) (*route53.Change, error) {
+	switch v1alpha1.DNSRecordType(endpoint.RecordType) {
+	case v1alpha1.ARecordType, v1alpha1.AAAARecordType, v1alpha1.CNAMERecordType, v1alpha1.NSRecordType, v1alpha1.SRVRecordType, v1alpha1.MXRecordType, v1alpha1.CAARecordType:
+	default:
 		return nil, fmt.Errorf("unsupported record type %s", endpoint.RecordType)
 	}
-	domain, targets := endpoint.DNSName, endpoint.Targets
+	domain, targets := endpoint.DNSName, endpoint.RecordValues()
 	if len(domain) == 0 {
 		return nil, fmt.Errorf("domain is required")
 	}
 	if len(targets) == 0 {
 		return nil, fmt.Errorf("targets is required")
 	}
+	if err := endpoint.Validate(); err != nil {
+		return nil, err
+	}
+
+	ttl := int64(endpoint.RecordTTL)
+	if ttl == 0 {
+		ttl = dns.DefaultTTL
+	}
+	if ttl < MinimumRecordTTL {
+		return nil, fmt.Errorf("invalid TTL %d for %s, it cannot be lower than %d", ttl, domain, MinimumRecordTTL)
+	}
 
 	var resourceRecords []*route53.ResourceRecord
-	for _, target := range endpoint.Targets {
+	for _, target := range targets {
 		resourceRecords = append(resourceRecords, &route53.ResourceRecord{Value: aws.String(target)})
 	}
 
 	resourceRecordSet := &route53.ResourceRecordSet{
 		Name:            aws.String(endpoint.DNSName),
 		Type:            aws.String(endpoint.RecordType),
-		TTL:             aws.Int64(int64(endpoint.RecordTTL)),
+		TTL:             aws.Int64(ttl),
 		ResourceRecords: resourceRecords,
 	}
 
+	alias, err := aliasTarget(endpoint, managedZone)
+	if err != nil {
+		return nil, err
+	}
+	if alias != nil {
+		// alias records are always A records, and take the TTL of the target
+		resourceRecordSet.Type = aws.String(route53.RRTypeA)
+		resourceRecordSet.TTL = nil
+		resourceRecordSet.ResourceRecords = nil
+		resourceRecordSet.AliasTarget = alias
+	}
+
 	if endpoint.SetIdentifier != "" {
 		resourceRecordSet.SetIdentifier = aws.String(endpoint.SetIdentifier)
 	}
@@ -286,9 +665,16 @@ func (p *Route53DNSProvider) changeForEndpoint(endpoint *v1alpha1.Endpoint, acti
 		}
 		resourceRecordSet.Weight = aws.Int64(weight)
 	}
-	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificRegion); ok {
+	if prop, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificRegion); ok {
 		resourceRecordSet.Region = aws.String(prop.Value)
+	} else if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificRegion); ok {
+		resourceRecordSet.Region = aws.String(prop.Value)
+	}
+	geoProximityLocation, err := geoProximityLocation(endpoint)
+	if err != nil {
+		return nil, err
 	}
+	resourceRecordSet.GeoProximityLocation = geoProximityLocation
 	if prop, ok := endpoint.GetProviderSpecificProperty(ProviderSpecificFailover); ok {
 		resourceRecordSet.Failover = aws.String(prop.Value)
 	}
@@ -302,8 +688,10 @@ func (p *Route53DNSProvider) changeForEndpoint(endpoint *v1alpha1.Endpoint, acti
 	if prop, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificGeoCode); ok {
 		if dns.IsISO3166Alpha2Code(prop.Value) || dns.GeoCode(prop.Value).IsWildcard() {
 			geolocation.CountryCode = aws.String(prop.Value)
-		} else {
+		} else if IsContinentCode(prop.Value) {
 			geolocation.ContinentCode = aws.String(prop.Value)
+		} else {
+			return nil, fmt.Errorf("unsupported geo code %s for %s, it must be a continent or ISO 3166 country code", prop.Value, domain)
 		}
 		useGeolocation = true
 	}
@@ -329,6 +717,48 @@ func (p *Route53DNSProvider) changeForEndpoint(endpoint *v1alpha1.Endpoint, acti
 	return change, nil
 }
 
+// geoProximityLocation returns the geoproximity location of the record set of the endpoint, from its region or
+// coordinates and its bias, nil if the endpoint is not a geoproximity endpoint
+func geoProximityLocation(endpoint *v1alpha1.Endpoint) (*route53.GeoProximityLocation, error) {
+	location := &route53.GeoProximityLocation{}
+	if prop, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificGeoproximityCoordinates); ok {
+		latitude, longitude, found := strings.Cut(prop.Value, ",")
+		if !found {
+			return nil, fmt.Errorf("invalid geoproximity coordinates %q for %s, they must be a latitude,longitude pair", prop.Value, endpoint.DNSName)
+		}
+		location.Coordinates = &route53.Coordinates{
+			Latitude:  aws.String(strings.TrimSpace(latitude)),
+			Longitude: aws.String(strings.TrimSpace(longitude)),
+		}
+	} else if prop, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificGeoproximityRegion); ok {
+		location.AWSRegion = aws.String(prop.Value)
+	} else {
+		return nil, nil
+	}
+	if prop, ok := endpoint.GetProviderSpecificProperty(dns.ProviderSpecificGeoproximityBias); ok {
+		bias, err := strconv.ParseInt(prop.Value, 10, 64)
+		if err != nil || bias < int64(v1alpha1.MinimumBias) || bias > int64(v1alpha1.MaximumBias) {
+			return nil, fmt.Errorf("invalid geoproximity bias %q for %s, it must be between %d and %d", prop.Value, endpoint.DNSName, v1alpha1.MinimumBias, v1alpha1.MaximumBias)
+		}
+		location.Bias = aws.Int64(bias)
+	}
+	return location, nil
+}
+
+// CheckConnectivity implements dns.ConnectivityChecker by listing a single hosted zone
+func (p *Route53DNSProvider) CheckConnectivity() error {
+	if err := validateServiceEndpoints(p); err != nil {
+		return fmt.Errorf("%w: %v", dns.ErrProviderUnreachable, err)
+	}
+	return nil
+}
+
+// ConnectivityCheckedOnCreation implements dns.CreationConnectivityChecker, the connectivity of the provider is
+// checked when it is created
+func (p *Route53DNSProvider) ConnectivityCheckedOnCreation() bool {
+	return true
+}
+
 // validateServiceEndpoints validates that provider clients can communicate with
 // associated API endpoints by having each client make a list/describe/get call.
 func validateServiceEndpoints(provider *Route53DNSProvider) error {
@@ -0,0 +1,1120 @@
+//go:build unit
+
+package aws
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+func TestRoute53DNSProvider_Ensure(t *testing.T) {
+	testCases := []struct {
+		name       string
+		endpoint   *v1alpha1.Endpoint
+		wantRegion *string
+		wantWeight *int64
+		// wantGeoProximity is the geoproximity location of the record set, nil for the other routing policies
+		wantGeoProximity *route53.GeoProximityLocation
+		wantTTL          int64
+		wantErr          bool
+	}{
+		{
+			name: "latency record set has region",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "default.lb-ocnswx.test.example.com",
+				Targets:       []string{"mylb.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "mylb.example.com",
+				RecordTTL:     dns.DefaultTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificRegion, Value: "eu-west-1"},
+				},
+			},
+			wantRegion: ptrTo("eu-west-1"),
+			wantTTL:    dns.DefaultTTL,
+		},
+		{
+			name: "weighted record set has no region",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "default.lb-ocnswx.test.example.com",
+				Targets:       []string{"mylb.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "mylb.example.com",
+				RecordTTL:     dns.DefaultTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificWeight, Value: "120"},
+				},
+			},
+			wantWeight: ptrTo(int64(120)),
+			wantTTL:    dns.DefaultTTL,
+		},
+		{
+			name: "geoproximity record set has region and bias",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "default.lb-ocnswx.test.example.com",
+				Targets:       []string{"mylb.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "mylb.example.com",
+				RecordTTL:     dns.DefaultTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificGeoproximityRegion, Value: "us-east-1"},
+					{Name: dns.ProviderSpecificGeoproximityBias, Value: "-20"},
+				},
+			},
+			wantGeoProximity: &route53.GeoProximityLocation{AWSRegion: aws.String("us-east-1"), Bias: aws.Int64(-20)},
+			wantTTL:          dns.DefaultTTL,
+		},
+		{
+			name: "geoproximity record set has coordinates and bias",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "default.lb-ocnswx.test.example.com",
+				Targets:       []string{"mylb.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "mylb.example.com",
+				RecordTTL:     dns.DefaultTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificGeoproximityCoordinates, Value: "53.35,-6.26"},
+					{Name: dns.ProviderSpecificGeoproximityBias, Value: "99"},
+				},
+			},
+			wantGeoProximity: &route53.GeoProximityLocation{
+				Coordinates: &route53.Coordinates{Latitude: aws.String("53.35"), Longitude: aws.String("-6.26")},
+				Bias:        aws.Int64(99),
+			},
+			wantTTL: dns.DefaultTTL,
+		},
+		{
+			name: "geoproximity record set with bias out of range is rejected",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "default.lb-ocnswx.test.example.com",
+				Targets:       []string{"mylb.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "mylb.example.com",
+				RecordTTL:     dns.DefaultTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificGeoproximityRegion, Value: "us-east-1"},
+					{Name: dns.ProviderSpecificGeoproximityBias, Value: "100"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "geoproximity record set with invalid coordinates is rejected",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "default.lb-ocnswx.test.example.com",
+				Targets:       []string{"mylb.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "mylb.example.com",
+				RecordTTL:     dns.DefaultTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificGeoproximityCoordinates, Value: "53.35"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "record set uses endpoint TTL",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "test.example.com",
+				Targets:    []string{"1.1.1.1"},
+				RecordType: "A",
+				RecordTTL:  30,
+			},
+			wantTTL: 30,
+		},
+		{
+			name: "record set uses default TTL when unset",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "test.example.com",
+				Targets:    []string{"1.1.1.1"},
+				RecordType: "A",
+			},
+			wantTTL: dns.DefaultTTL,
+		},
+		{
+			name: "geo record set with continent code",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "lb-ocnswx.test.example.com",
+				Targets:       []string{"eu.lb-ocnswx.test.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "EU",
+				RecordTTL:     dns.DefaultCnameTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificGeoCode, Value: "EU"},
+				},
+			},
+			wantTTL: dns.DefaultCnameTTL,
+		},
+		{
+			name: "geo record set with unsupported geo code is rejected",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:       "lb-ocnswx.test.example.com",
+				Targets:       []string{"europe.lb-ocnswx.test.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "EUROPE",
+				RecordTTL:     dns.DefaultCnameTTL,
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: dns.ProviderSpecificGeoCode, Value: "EUROPE"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "record set with TTL below minimum is rejected",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "test.example.com",
+				Targets:    []string{"1.1.1.1"},
+				RecordType: "A",
+				RecordTTL:  -1,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockClient := &mockChangeRoute53API{}
+			provider := &Route53DNSProvider{
+				client: &InstrumentedRoute53{mockClient},
+				logger: logr.Discard(),
+			}
+			record := &v1alpha1.DNSRecord{
+				Spec: v1alpha1.DNSRecordSpec{
+					Endpoints: []*v1alpha1.Endpoint{testCase.endpoint},
+				},
+			}
+			zone := &v1alpha1.ManagedZone{
+				Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+			}
+
+			err := provider.Ensure(record, zone)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+				if len(mockClient.changes) != 0 {
+					t.Fatalf("expected no changes, got %d", len(mockClient.changes))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if len(mockClient.changes) != 1 {
+				t.Fatalf("expected 1 change, got %d", len(mockClient.changes))
+			}
+			recordSet := mockClient.changes[0].ResourceRecordSet
+			if !equalPtr(recordSet.Region, testCase.wantRegion) {
+				t.Errorf("expected region %q, got %q", aws.StringValue(testCase.wantRegion), aws.StringValue(recordSet.Region))
+			}
+			if !equalPtr(recordSet.Weight, testCase.wantWeight) {
+				t.Errorf("expected weight %d, got %d", aws.Int64Value(testCase.wantWeight), aws.Int64Value(recordSet.Weight))
+			}
+			if !reflect.DeepEqual(recordSet.GeoProximityLocation, testCase.wantGeoProximity) {
+				t.Errorf("expected geoproximity location %v, got %v", testCase.wantGeoProximity, recordSet.GeoProximityLocation)
+			}
+			if aws.Int64Value(recordSet.TTL) != testCase.wantTTL {
+				t.Errorf("expected TTL %d, got %d", testCase.wantTTL, aws.Int64Value(recordSet.TTL))
+			}
+		})
+	}
+}
+
+func TestRoute53DNSProvider_Ensure_ipv6(t *testing.T) {
+	endpoint := func(recordType string, targets ...string) *v1alpha1.Endpoint {
+		return &v1alpha1.Endpoint{DNSName: "test.example.com", RecordType: recordType, Targets: targets}
+	}
+
+	testCases := []struct {
+		name      string
+		endpoints []*v1alpha1.Endpoint
+		published []*v1alpha1.Endpoint
+		// wantChanges are the expected changes as action and record type
+		wantChanges [][2]string
+	}{
+		{
+			name:        "IPv6 endpoint produces an AAAA record set",
+			endpoints:   []*v1alpha1.Endpoint{endpoint("AAAA", "2001:db8::1")},
+			wantChanges: [][2]string{{route53.ChangeActionUpsert, route53.RRTypeAaaa}},
+		},
+		{
+			name:      "dual stack endpoints produce A and AAAA record sets",
+			endpoints: []*v1alpha1.Endpoint{endpoint("A", "1.1.1.1"), endpoint("AAAA", "2001:db8::1")},
+			wantChanges: [][2]string{
+				{route53.ChangeActionUpsert, route53.RRTypeA},
+				{route53.ChangeActionUpsert, route53.RRTypeAaaa},
+			},
+		},
+		{
+			name:      "removed AAAA endpoint of a dual stack host is deleted",
+			endpoints: []*v1alpha1.Endpoint{endpoint("A", "1.1.1.1")},
+			published: []*v1alpha1.Endpoint{endpoint("A", "1.1.1.1"), endpoint("AAAA", "2001:db8::1")},
+			wantChanges: [][2]string{
+				{route53.ChangeActionDelete, route53.RRTypeAaaa},
+				{route53.ChangeActionUpsert, route53.RRTypeA},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockClient := &mockChangeRoute53API{}
+			provider := &Route53DNSProvider{
+				client: &InstrumentedRoute53{mockClient},
+				logger: logr.Discard(),
+			}
+			record := &v1alpha1.DNSRecord{
+				Spec:   v1alpha1.DNSRecordSpec{Endpoints: testCase.endpoints},
+				Status: v1alpha1.DNSRecordStatus{Endpoints: testCase.published},
+			}
+			zone := &v1alpha1.ManagedZone{
+				Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+			}
+
+			if err := provider.Ensure(record, zone); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(mockClient.changes) != len(testCase.wantChanges) {
+				t.Fatalf("expected %d changes, got %d: %v", len(testCase.wantChanges), len(mockClient.changes), mockClient.changes)
+			}
+			for i, change := range mockClient.changes {
+				action, recordType := aws.StringValue(change.Action), aws.StringValue(change.ResourceRecordSet.Type)
+				if action != testCase.wantChanges[i][0] || recordType != testCase.wantChanges[i][1] {
+					t.Errorf("expected change %d to be %v, got %s %s", i, testCase.wantChanges[i], action, recordType)
+				}
+			}
+		})
+	}
+}
+
+func TestRoute53DNSProvider_Ensure_srv(t *testing.T) {
+	srvEndpoint := func(name string, targets ...v1alpha1.SRVTarget) *v1alpha1.Endpoint {
+		return &v1alpha1.Endpoint{DNSName: name, RecordType: "SRV", SRVTargets: targets}
+	}
+
+	testCases := []struct {
+		name       string
+		endpoint   *v1alpha1.Endpoint
+		wantValues []string
+		wantErr    bool
+	}{
+		{
+			name: "SRV endpoint produces an SRV record set",
+			endpoint: srvEndpoint("_sip._tcp.example.com",
+				v1alpha1.SRVTarget{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+				v1alpha1.SRVTarget{Priority: 20, Weight: 0, Port: 5060, Target: "backup.example.com"},
+			),
+			wantValues: []string{"10 5 5060 sip.example.com", "20 0 5060 backup.example.com"},
+		},
+		{
+			name:       "MX endpoint produces an MX record set",
+			endpoint:   &v1alpha1.Endpoint{DNSName: "example.com", RecordType: "MX", MXTargets: []v1alpha1.MXTarget{{Priority: 10, Host: "mail.example.com"}}},
+			wantValues: []string{"10 mail.example.com"},
+		},
+		{
+			name: "CAA endpoint produces a CAA record set",
+			endpoint: &v1alpha1.Endpoint{DNSName: "example.com", RecordType: "CAA", CAATargets: []v1alpha1.CAATarget{
+				{Tag: "issue", Value: "letsencrypt.org"},
+				{Flags: 128, Tag: "iodef", Value: "mailto:security@example.com"},
+			}},
+			wantValues: []string{`0 issue "letsencrypt.org"`, `128 iodef "mailto:security@example.com"`},
+		},
+		{
+			name:     "CAA unknown tag is rejected",
+			endpoint: &v1alpha1.Endpoint{DNSName: "example.com", RecordType: "CAA", CAATargets: []v1alpha1.CAATarget{{Tag: "issuer", Value: "letsencrypt.org"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "CAA value with quotes is rejected",
+			endpoint: &v1alpha1.Endpoint{DNSName: "example.com", RecordType: "CAA", CAATargets: []v1alpha1.CAATarget{{Tag: "issue", Value: `letsencrypt.org" 0 issue "other.example`}}},
+			wantErr:  true,
+		},
+		{
+			name:     "SRV name without service and protocol is rejected",
+			endpoint: srvEndpoint("sip.example.com", v1alpha1.SRVTarget{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"}),
+			wantErr:  true,
+		},
+		{
+			name:     "SRV port out of range is rejected",
+			endpoint: srvEndpoint("_sip._tcp.example.com", v1alpha1.SRVTarget{Priority: 10, Weight: 5, Port: 70000, Target: "sip.example.com"}),
+			wantErr:  true,
+		},
+		{
+			name:     "SRV target IP address is rejected",
+			endpoint: srvEndpoint("_sip._tcp.example.com", v1alpha1.SRVTarget{Priority: 10, Weight: 5, Port: 5060, Target: "1.1.1.1"}),
+			wantErr:  true,
+		},
+		{
+			name:     "SRV endpoint with plain targets is rejected",
+			endpoint: &v1alpha1.Endpoint{DNSName: "_sip._tcp.example.com", RecordType: "SRV", Targets: []string{"10 5 5060 sip.example.com"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// the zone is listed for the CAA record sets existing in it
+			mockClient := &mockZoneRoute53API{}
+			provider := &Route53DNSProvider{
+				client: &InstrumentedRoute53{mockClient},
+				logger: logr.Discard(),
+			}
+			record := &v1alpha1.DNSRecord{
+				Spec: v1alpha1.DNSRecordSpec{Endpoints: []*v1alpha1.Endpoint{testCase.endpoint}},
+			}
+			zone := &v1alpha1.ManagedZone{
+				Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+			}
+
+			err := provider.Ensure(record, zone)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got changes %v", mockClient.changes)
+				}
+				if len(mockClient.changes) != 0 {
+					t.Errorf("expected no changes, got %v", mockClient.changes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(mockClient.changes) != 1 {
+				t.Fatalf("expected 1 change, got %d: %v", len(mockClient.changes), mockClient.changes)
+			}
+			recordSet := mockClient.changes[0].ResourceRecordSet
+			if aws.StringValue(recordSet.Type) != testCase.endpoint.RecordType {
+				t.Errorf("expected record type %s, got %s", testCase.endpoint.RecordType, aws.StringValue(recordSet.Type))
+			}
+			var values []string
+			for _, resourceRecord := range recordSet.ResourceRecords {
+				values = append(values, aws.StringValue(resourceRecord.Value))
+			}
+			if !reflect.DeepEqual(values, testCase.wantValues) {
+				t.Errorf("expected values %v, got %v", testCase.wantValues, values)
+			}
+		})
+	}
+}
+
+func TestRoute53DNSProvider_Ensure_alias(t *testing.T) {
+	testCases := []struct {
+		name      string
+		endpoint  *v1alpha1.Endpoint
+		wantAlias *route53.AliasTarget
+		wantType  string
+		wantErr   bool
+	}{
+		{
+			name: "apex CNAME to a load balancer is an alias record",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "example.com",
+				Targets:    []string{"my-lb-1234.eu-west-1.elb.amazonaws.com"},
+				RecordType: "CNAME",
+			},
+			wantAlias: &route53.AliasTarget{
+				DNSName:              aws.String("my-lb-1234.eu-west-1.elb.amazonaws.com"),
+				HostedZoneId:         aws.String("Z32O12XQLNTSW2"),
+				EvaluateTargetHealth: aws.Bool(false),
+			},
+			wantType: "A",
+		},
+		{
+			name: "apex CNAME to a network load balancer evaluates the target health",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "example.com",
+				Targets:    []string{"my-nlb-1234.elb.us-east-1.amazonaws.com"},
+				RecordType: "CNAME",
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: ProviderSpecificEvaluateTargetHealth, Value: "true"},
+				},
+			},
+			wantAlias: &route53.AliasTarget{
+				DNSName:              aws.String("my-nlb-1234.elb.us-east-1.amazonaws.com"),
+				HostedZoneId:         aws.String("Z26RNL4JYFTOTI"),
+				EvaluateTargetHealth: aws.Bool(true),
+			},
+			wantType: "A",
+		},
+		{
+			name: "subdomain CNAME to a load balancer is a CNAME record",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "www.example.com",
+				Targets:    []string{"my-lb-1234.eu-west-1.elb.amazonaws.com"},
+				RecordType: "CNAME",
+			},
+			wantType: "CNAME",
+		},
+		{
+			name: "apex CNAME with alias disabled is a CNAME record",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "example.com",
+				Targets:    []string{"my-lb-1234.eu-west-1.elb.amazonaws.com"},
+				RecordType: "CNAME",
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: ProviderSpecificAlias, Value: "false"},
+				},
+			},
+			wantType: "CNAME",
+		},
+		{
+			name: "explicit alias uses the given hosted zone",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "www.example.com",
+				Targets:    []string{"d111111abcdef8.cloudfront.net"},
+				RecordType: "CNAME",
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: ProviderSpecificAlias, Value: "true"},
+					{Name: ProviderSpecificAliasHostedZoneID, Value: "Z2FDTNDATAQYW2"},
+				},
+			},
+			wantAlias: &route53.AliasTarget{
+				DNSName:              aws.String("d111111abcdef8.cloudfront.net"),
+				HostedZoneId:         aws.String("Z2FDTNDATAQYW2"),
+				EvaluateTargetHealth: aws.Bool(false),
+			},
+			wantType: "A",
+		},
+		{
+			name: "explicit alias to an unknown hosted zone is rejected",
+			endpoint: &v1alpha1.Endpoint{
+				DNSName:    "www.example.com",
+				Targets:    []string{"d111111abcdef8.cloudfront.net"},
+				RecordType: "CNAME",
+				ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+					{Name: ProviderSpecificAlias, Value: "true"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockClient := &mockChangeRoute53API{}
+			provider := &Route53DNSProvider{
+				client: &InstrumentedRoute53{mockClient},
+				logger: logr.Discard(),
+			}
+			record := &v1alpha1.DNSRecord{
+				Spec: v1alpha1.DNSRecordSpec{
+					Endpoints: []*v1alpha1.Endpoint{testCase.endpoint},
+				},
+			}
+			zone := &v1alpha1.ManagedZone{
+				Spec:   v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+				Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+			}
+
+			err := provider.Ensure(record, zone)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Ensure() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if testCase.wantErr {
+				return
+			}
+			if len(mockClient.changes) != 1 {
+				t.Fatalf("expected 1 change, got %d", len(mockClient.changes))
+			}
+			recordSet := mockClient.changes[0].ResourceRecordSet
+			if aws.StringValue(recordSet.Type) != testCase.wantType {
+				t.Errorf("expected type %s, got %s", testCase.wantType, aws.StringValue(recordSet.Type))
+			}
+			if !reflect.DeepEqual(recordSet.AliasTarget, testCase.wantAlias) {
+				t.Errorf("expected alias target %v, got %v", testCase.wantAlias, recordSet.AliasTarget)
+			}
+			if testCase.wantAlias != nil && (recordSet.TTL != nil || len(recordSet.ResourceRecords) != 0) {
+				t.Errorf("expected an alias record without TTL and resource records, got %v", recordSet)
+			}
+		})
+	}
+}
+
+func TestRoute53DNSProvider_Ensure_batches(t *testing.T) {
+	endpoint := func(name string) *v1alpha1.Endpoint {
+		return &v1alpha1.Endpoint{
+			DNSName:    name,
+			Targets:    []string{"1.1.1.1"},
+			RecordType: "A",
+			RecordTTL:  60,
+		}
+	}
+
+	mockClient := &mockChangeRoute53API{}
+	provider := &Route53DNSProvider{
+		client: &InstrumentedRoute53{mockClient},
+		logger: logr.Discard(),
+	}
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*v1alpha1.Endpoint{
+				endpoint("a.example.com"),
+				endpoint("b.example.com"),
+				endpoint("c.example.com"),
+				endpoint("d.example.com"),
+				endpoint("e.example.com"),
+			},
+		},
+		Status: v1alpha1.DNSRecordStatus{
+			Endpoints: []*v1alpha1.Endpoint{
+				endpoint("a.example.com"),
+				endpoint("stale.example.com"),
+			},
+		},
+	}
+	zone := &v1alpha1.ManagedZone{
+		Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+	}
+
+	if err := provider.Ensure(record, zone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(mockClient.batches) != 1 {
+		t.Fatalf("expected 1 batch, got %d", len(mockClient.batches))
+	}
+	batch := mockClient.batches[0]
+	if len(batch) != 6 {
+		t.Fatalf("expected 6 changes, got %d", len(batch))
+	}
+	if aws.StringValue(batch[0].Action) != route53.ChangeActionDelete || aws.StringValue(batch[0].ResourceRecordSet.Name) != "stale.example.com" {
+		t.Errorf("expected the stale record to be deleted first, got %s %s", aws.StringValue(batch[0].Action), aws.StringValue(batch[0].ResourceRecordSet.Name))
+	}
+	for _, change := range batch[1:] {
+		if aws.StringValue(change.Action) != route53.ChangeActionUpsert {
+			t.Errorf("expected %s to be upserted, got %s", aws.StringValue(change.ResourceRecordSet.Name), aws.StringValue(change.Action))
+		}
+	}
+}
+
+func TestRoute53DNSProvider_Ensure_healthChecks(t *testing.T) {
+	endpoints := func() []*v1alpha1.Endpoint {
+		return []*v1alpha1.Endpoint{
+			{
+				DNSName:       "default.lb-a1b2.api.example.com",
+				Targets:       []string{"c1.lb-a1b2.api.example.com"},
+				RecordType:    "CNAME",
+				SetIdentifier: "c1.lb-a1b2.api.example.com",
+				RecordTTL:     60,
+				ProviderSpecific: v1alpha1.ProviderSpecific{
+					{Name: dns.ProviderSpecificWeight, Value: "120"},
+				},
+			},
+			{
+				DNSName:    "c1.lb-a1b2.api.example.com",
+				Targets:    []string{"1.1.1.1"},
+				RecordType: "A",
+				RecordTTL:  60,
+			},
+		}
+	}
+	healthCheckIDs := func(changes []*route53.Change) map[string]string {
+		ids := map[string]string{}
+		for _, change := range changes {
+			ids[aws.StringValue(change.Action)+" "+aws.StringValue(change.ResourceRecordSet.Type)] = aws.StringValue(change.ResourceRecordSet.HealthCheckId)
+		}
+		return ids
+	}
+
+	mockClient := &mockHealthCheckRoute53API{}
+	provider := &Route53DNSProvider{
+		client: &InstrumentedRoute53{mockClient},
+		logger: logr.Discard(),
+	}
+	zone := &v1alpha1.ManagedZone{
+		Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+	}
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints:   endpoints(),
+			HealthCheck: &v1alpha1.ProviderHealthCheck{Endpoint: "/healthz"},
+		},
+	}
+
+	// a health check is created for the weighted record set and attached to it
+	if err := provider.Ensure(record, zone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(mockClient.healthChecks) != 1 {
+		t.Fatalf("expected 1 health check, got %d", len(mockClient.healthChecks))
+	}
+	config := mockClient.healthChecks[0].HealthCheckConfig
+	if aws.StringValue(config.FullyQualifiedDomainName) != "c1.lb-a1b2.api.example.com" || config.IPAddress != nil ||
+		aws.StringValue(config.ResourcePath) != "/healthz" || aws.StringValue(config.Type) != route53.HealthCheckTypeHttps || aws.Int64Value(config.Port) != 443 {
+		t.Errorf("unexpected health check config %v", config)
+	}
+	if ids := healthCheckIDs(mockClient.changes); ids["UPSERT CNAME"] != "test-0" || ids["UPSERT A"] != "" {
+		t.Errorf("expected the health check to be attached to the weighted record set only, got %v", ids)
+	}
+	if id, _ := record.Spec.Endpoints[0].GetProviderSpecific(ProviderSpecificHealthCheckID); id != "test-0" {
+		t.Errorf("expected the health check ID to be set on the published endpoint, got %q", id)
+	}
+
+	// the health check of the published endpoint is reused
+	published := record.Spec.Endpoints
+	record = &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints:   endpoints(),
+			HealthCheck: &v1alpha1.ProviderHealthCheck{Endpoint: "/healthz"},
+		},
+		Status: v1alpha1.DNSRecordStatus{Endpoints: endpoints(), PublishedEndpoints: published},
+	}
+	mockClient.changes = nil
+	if err := provider.Ensure(record, zone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(mockClient.healthChecks) != 1 {
+		t.Fatalf("expected the health check to be reused, got %d health checks", len(mockClient.healthChecks))
+	}
+	if ids := healthCheckIDs(mockClient.changes); ids["UPSERT CNAME"] != "test-0" {
+		t.Errorf("expected the health check to stay attached to the weighted record set, got %v", ids)
+	}
+
+	// the record sets are deleted with their health check, which is then deleted
+	published = record.Spec.Endpoints
+	record = &v1alpha1.DNSRecord{
+		Spec:   v1alpha1.DNSRecordSpec{Endpoints: endpoints(), HealthCheck: &v1alpha1.ProviderHealthCheck{}},
+		Status: v1alpha1.DNSRecordStatus{Endpoints: endpoints(), PublishedEndpoints: published},
+	}
+	mockClient.changes = nil
+	if err := provider.Delete(record, zone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if ids := healthCheckIDs(mockClient.changes); ids["DELETE CNAME"] != "test-0" {
+		t.Errorf("expected the weighted record set to be deleted with its health check ID, got %v", ids)
+	}
+	if len(mockClient.healthChecks) != 0 {
+		t.Errorf("expected the health check to be deleted, got %d health checks", len(mockClient.healthChecks))
+	}
+	if _, ok := record.Spec.Endpoints[0].GetProviderSpecific(ProviderSpecificHealthCheckID); ok {
+		t.Errorf("expected the endpoints of the deleted record to be left untouched")
+	}
+}
+
+func TestRoute53DNSProvider_Ensure_healthChecksDisabled(t *testing.T) {
+	endpoint := &v1alpha1.Endpoint{
+		DNSName:       "default.lb-a1b2.api.example.com",
+		Targets:       []string{"1.1.1.1"},
+		RecordType:    "A",
+		SetIdentifier: "1.1.1.1",
+		ProviderSpecific: v1alpha1.ProviderSpecific{
+			{Name: dns.ProviderSpecificWeight, Value: "120"},
+		},
+	}
+	published := endpoint.DeepCopy()
+	published.SetProviderSpecific(ProviderSpecificHealthCheckID, "test-0")
+
+	mockClient := &mockHealthCheckRoute53API{}
+	mockClient.healthChecks = []*mockHealthCheck{{HealthCheck: &route53.HealthCheck{Id: aws.String("test-0"), HealthCheckConfig: &route53.HealthCheckConfig{}}}}
+	provider := &Route53DNSProvider{
+		client: &InstrumentedRoute53{mockClient},
+		logger: logr.Discard(),
+	}
+	record := &v1alpha1.DNSRecord{
+		Spec:   v1alpha1.DNSRecordSpec{Endpoints: []*v1alpha1.Endpoint{endpoint}},
+		Status: v1alpha1.DNSRecordStatus{Endpoints: []*v1alpha1.Endpoint{endpoint.DeepCopy()}, PublishedEndpoints: []*v1alpha1.Endpoint{published}},
+	}
+	zone := &v1alpha1.ManagedZone{
+		Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+	}
+
+	if err := provider.Ensure(record, zone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(mockClient.changes) != 1 || mockClient.changes[0].ResourceRecordSet.HealthCheckId != nil {
+		t.Errorf("expected the record set to be published without its health check, got %v", mockClient.changes)
+	}
+	if len(mockClient.healthChecks) != 0 {
+		t.Errorf("expected the health check to be deleted, got %d health checks", len(mockClient.healthChecks))
+	}
+}
+
+func TestChangeBatches(t *testing.T) {
+	change := func(action, name string, values ...string) *route53.Change {
+		var resourceRecords []*route53.ResourceRecord
+		for _, value := range values {
+			resourceRecords = append(resourceRecords, &route53.ResourceRecord{Value: aws.String(value)})
+		}
+		return &route53.Change{
+			Action: aws.String(action),
+			ResourceRecordSet: &route53.ResourceRecordSet{
+				Name:            aws.String(name),
+				ResourceRecords: resourceRecords,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		changes        []*route53.Change
+		maxRecords     int
+		maxValueLength int
+		want           [][]string
+	}{
+		{
+			name: "keeps changes within the limits in a single batch",
+			changes: []*route53.Change{
+				change(route53.ChangeActionUpsert, "a", "1.1.1.1"),
+				change(route53.ChangeActionUpsert, "b", "1.1.1.1"),
+			},
+			maxRecords:     1000,
+			maxValueLength: 32000,
+			want:           [][]string{{"a", "b"}},
+		},
+		{
+			name: "orders deletes before creates and updates",
+			changes: []*route53.Change{
+				change(route53.ChangeActionUpsert, "a", "1.1.1.1"),
+				change(route53.ChangeActionCreate, "b", "1.1.1.1"),
+				change(route53.ChangeActionDelete, "b", "2.2.2.2"),
+				change(route53.ChangeActionDelete, "c", "1.1.1.1"),
+			},
+			maxRecords:     1000,
+			maxValueLength: 32000,
+			want:           [][]string{{"b", "c", "a", "b"}},
+		},
+		{
+			name: "splits on the number of records, counting upserts twice",
+			changes: []*route53.Change{
+				change(route53.ChangeActionUpsert, "a", "1.1.1.1", "2.2.2.2"),
+				change(route53.ChangeActionDelete, "b", "1.1.1.1"),
+				change(route53.ChangeActionUpsert, "c", "1.1.1.1"),
+				change(route53.ChangeActionCreate, "d", "1.1.1.1"),
+			},
+			maxRecords:     4,
+			maxValueLength: 32000,
+			want:           [][]string{{"b"}, {"a"}, {"c", "d"}},
+		},
+		{
+			name: "splits on the length of the values",
+			changes: []*route53.Change{
+				change(route53.ChangeActionCreate, "a", "1.1.1.1"),
+				change(route53.ChangeActionCreate, "b", "1.1.1.1"),
+				change(route53.ChangeActionCreate, "c", "1.1.1.1"),
+			},
+			maxRecords:     1000,
+			maxValueLength: 14,
+			want:           [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name: "places a change exceeding the limits in its own batch",
+			changes: []*route53.Change{
+				change(route53.ChangeActionCreate, "a", "1.1.1.1", "2.2.2.2", "3.3.3.3"),
+				change(route53.ChangeActionCreate, "b", "1.1.1.1"),
+			},
+			maxRecords:     2,
+			maxValueLength: 32000,
+			want:           [][]string{{"a"}, {"b"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got [][]string
+			for _, batch := range changeBatches(testCase.changes, testCase.maxRecords, testCase.maxValueLength) {
+				var names []string
+				for _, change := range batch {
+					names = append(names, aws.StringValue(change.ResourceRecordSet.Name))
+				}
+				got = append(got, names)
+			}
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("changeBatches() = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestRoute53DNSProvider_zoneFilter(t *testing.T) {
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*v1alpha1.Endpoint{{DNSName: "test.example.com", RecordType: "A", Targets: []string{"1.1.1.1"}}},
+		},
+	}
+	allowedZone := &v1alpha1.ManagedZone{
+		Spec:   v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{ID: "/hostedzone/allowed"},
+	}
+	refusedZone := &v1alpha1.ManagedZone{
+		Spec:   v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{ID: "/hostedzone/production"},
+	}
+
+	mockClient := &mockChangeRoute53API{}
+	provider := &Route53DNSProvider{
+		client:     &InstrumentedRoute53{mockClient},
+		logger:     logr.Discard(),
+		zoneFilter: dns.NewZoneFilter("allowed", "example.com"),
+	}
+
+	// the mock panics if the zone is requested from Route53
+	if err := provider.Ensure(record, refusedZone); !errors.Is(err, dns.ErrZoneNotAllowed) {
+		t.Errorf("expected ensuring a record in a refused zone to fail with %v, got %v", dns.ErrZoneNotAllowed, err)
+	}
+	if err := provider.Delete(record, refusedZone); !errors.Is(err, dns.ErrZoneNotAllowed) {
+		t.Errorf("expected deleting a record in a refused zone to fail with %v, got %v", dns.ErrZoneNotAllowed, err)
+	}
+	if _, err := provider.EnsureManagedZone(refusedZone); !errors.Is(err, dns.ErrZoneNotAllowed) {
+		t.Errorf("expected ensuring a refused zone to fail with %v, got %v", dns.ErrZoneNotAllowed, err)
+	}
+	if err := provider.DeleteManagedZone(refusedZone); !errors.Is(err, dns.ErrZoneNotAllowed) {
+		t.Errorf("expected deleting a refused zone to fail with %v, got %v", dns.ErrZoneNotAllowed, err)
+	}
+	if len(mockClient.changes) != 0 {
+		t.Fatalf("expected no changes to a refused zone, got %v", mockClient.changes)
+	}
+
+	if err := provider.Ensure(record, allowedZone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(mockClient.changes) != 1 {
+		t.Errorf("expected 1 change to the allowed zone, got %d", len(mockClient.changes))
+	}
+}
+
+type mockChangeRoute53API struct {
+	unimplementedRoute53
+	changes []*route53.Change
+	batches [][]*route53.Change
+}
+
+func (m *mockChangeRoute53API) ChangeResourceRecordSets(input *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	m.changes = append(m.changes, input.ChangeBatch.Changes...)
+	m.batches = append(m.batches, input.ChangeBatch.Changes)
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+// mockHealthCheckRoute53API records the changes to the record sets and manages health checks
+type mockHealthCheckRoute53API struct {
+	mockRoute53API
+	changes []*route53.Change
+}
+
+func (m *mockHealthCheckRoute53API) ChangeResourceRecordSets(input *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	m.changes = append(m.changes, input.ChangeBatch.Changes...)
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// mockNoSuchHostedZoneRoute53API fails every request as if the hosted zone was deleted
+type mockNoSuchHostedZoneRoute53API struct {
+	unimplementedRoute53
+}
+
+func (m *mockNoSuchHostedZoneRoute53API) ChangeResourceRecordSets(_ *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return nil, awserr.New(route53.ErrCodeNoSuchHostedZone, "No hosted zone found with ID: test-zone", nil)
+}
+
+func (m *mockNoSuchHostedZoneRoute53API) ListResourceRecordSets(_ *route53.ListResourceRecordSetsInput) (*route53.ListResourceRecordSetsOutput, error) {
+	return nil, awserr.New(route53.ErrCodeNoSuchHostedZone, "No hosted zone found with ID: test-zone", nil)
+}
+
+func TestRoute53DNSProvider_noSuchHostedZone(t *testing.T) {
+	for _, ownerID := range []string{"", "test-owner"} {
+		provider := &Route53DNSProvider{
+			client:  &InstrumentedRoute53{&mockNoSuchHostedZoneRoute53API{}},
+			logger:  logr.Discard(),
+			ownerID: ownerID,
+		}
+		record := &v1alpha1.DNSRecord{
+			Spec: v1alpha1.DNSRecordSpec{
+				Endpoints: []*v1alpha1.Endpoint{{DNSName: "test.example.com", RecordType: "A", Targets: []string{"1.1.1.1"}}},
+			},
+		}
+		zone := &v1alpha1.ManagedZone{
+			Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+		}
+
+		if err := provider.Ensure(record, zone); !errors.Is(err, dns.ErrZoneNotFound) {
+			t.Errorf("expected the zone not to be found with owner %q, got %v", ownerID, err)
+		}
+		if err := provider.Delete(record, zone); !errors.Is(err, dns.ErrZoneNotFound) {
+			t.Errorf("expected the zone not to be found on delete with owner %q, got %v", ownerID, err)
+		}
+	}
+}
+
+// mockAccessDeniedRoute53API fails every request as if the credentials were not permitted to call Route53
+type mockAccessDeniedRoute53API struct {
+	unimplementedRoute53
+}
+
+func (m *mockAccessDeniedRoute53API) ChangeResourceRecordSets(_ *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	return nil, awserr.New("AccessDenied", "User: arn:aws:iam::123456789012:user/test is not authorized to perform: route53:ChangeResourceRecordSets", nil)
+}
+
+func (m *mockAccessDeniedRoute53API) GetHostedZone(_ *route53.GetHostedZoneInput) (*route53.GetHostedZoneOutput, error) {
+	return nil, awserr.New("InvalidClientTokenId", "The security token included in the request is invalid.", nil)
+}
+
+func TestRoute53DNSProvider_authenticationFailure(t *testing.T) {
+	provider := &Route53DNSProvider{
+		client: &InstrumentedRoute53{&mockAccessDeniedRoute53API{}},
+		logger: logr.Discard(),
+	}
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*v1alpha1.Endpoint{{DNSName: "test.example.com", RecordType: "A", Targets: []string{"1.1.1.1"}}},
+		},
+	}
+	zone := &v1alpha1.ManagedZone{
+		Spec: v1alpha1.ManagedZoneSpec{
+			SecretRef: &v1alpha1.SecretRef{Namespace: "test", Name: "aws-credentials"},
+		},
+		Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+	}
+
+	var authErr *dns.AuthenticationError
+	if err := provider.Ensure(record, zone); !errors.As(err, &authErr) || authErr.Secret != "test/aws-credentials" {
+		t.Errorf("expected an authentication error for the secret test/aws-credentials, got %v", err)
+	}
+	if _, err := provider.EnsureManagedZone(zone); !errors.As(err, &authErr) {
+		t.Errorf("expected an authentication error ensuring the zone, got %v", err)
+	}
+}
+
+// mockDNSSECRoute53API serves the DNSSEC status of a hosted zone, recording the key-signing keys created and the
+// zones signing is enabled for
+type mockDNSSECRoute53API struct {
+	unimplementedRoute53
+	serveSignature string
+	keySigningKeys []*route53.KeySigningKey
+	createdKeys    []*route53.CreateKeySigningKeyInput
+	enabledZones   []string
+}
+
+func (m *mockDNSSECRoute53API) GetDNSSEC(_ *route53.GetDNSSECInput) (*route53.GetDNSSECOutput, error) {
+	return &route53.GetDNSSECOutput{
+		Status:         &route53.DNSSECStatus{ServeSignature: aws.String(m.serveSignature)},
+		KeySigningKeys: m.keySigningKeys,
+	}, nil
+}
+
+func (m *mockDNSSECRoute53API) CreateKeySigningKey(input *route53.CreateKeySigningKeyInput) (*route53.CreateKeySigningKeyOutput, error) {
+	m.createdKeys = append(m.createdKeys, input)
+	key := &route53.KeySigningKey{
+		Name:     input.Name,
+		Status:   input.Status,
+		DSRecord: aws.String("example.com. 3600 IN DS 12345 13 2 ABCDEF"),
+	}
+	m.keySigningKeys = append(m.keySigningKeys, key)
+	return &route53.CreateKeySigningKeyOutput{KeySigningKey: key}, nil
+}
+
+func (m *mockDNSSECRoute53API) EnableHostedZoneDNSSEC(input *route53.EnableHostedZoneDNSSECInput) (*route53.EnableHostedZoneDNSSECOutput, error) {
+	m.enabledZones = append(m.enabledZones, aws.StringValue(input.HostedZoneId))
+	m.serveSignature = "SIGNING"
+	return &route53.EnableHostedZoneDNSSECOutput{}, nil
+}
+
+func TestRoute53DNSProvider_EnsureDNSSEC(t *testing.T) {
+	api := &mockDNSSECRoute53API{serveSignature: "NOT_SIGNING"}
+	provider := &Route53DNSProvider{
+		client: &InstrumentedRoute53{api},
+		logger: logr.Discard(),
+	}
+	zone := &v1alpha1.ManagedZone{
+		Spec: v1alpha1.ManagedZoneSpec{
+			DomainName:      "example.com",
+			DNSSEC:          v1alpha1.DNSSECEnabled,
+			DNSSECKMSKeyARN: "arn:aws:kms:us-east-1:123456789012:key/test",
+		},
+		Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+	}
+
+	dsRecord, err := provider.EnsureDNSSEC(zone)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if dsRecord != "example.com. 3600 IN DS 12345 13 2 ABCDEF" {
+		t.Errorf("expected the DS record of the key-signing key to be returned, got %q", dsRecord)
+	}
+	if len(api.createdKeys) != 1 || aws.StringValue(api.createdKeys[0].KeyManagementServiceArn) != zone.Spec.DNSSECKMSKeyARN {
+		t.Errorf("expected a key-signing key to be created with the KMS key of the zone, got %v", api.createdKeys)
+	}
+	if !reflect.DeepEqual(api.enabledZones, []string{"test-zone"}) {
+		t.Errorf("expected DNSSEC signing to be enabled for the zone, got %v", api.enabledZones)
+	}
+
+	// a zone already signed is left unchanged
+	dsRecord, err = provider.EnsureDNSSEC(zone)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if dsRecord != "example.com. 3600 IN DS 12345 13 2 ABCDEF" {
+		t.Errorf("expected the DS record of the key-signing key to be returned, got %q", dsRecord)
+	}
+	if len(api.createdKeys) != 1 || len(api.enabledZones) != 1 {
+		t.Errorf("expected no change to the signed zone, got %d keys created and signing enabled %d times", len(api.createdKeys), len(api.enabledZones))
+	}
+
+	// a key-signing key cannot be created without a KMS key
+	provider.client = &InstrumentedRoute53{&mockDNSSECRoute53API{serveSignature: "NOT_SIGNING"}}
+	zone.Spec.DNSSECKMSKeyARN = ""
+	if _, err := provider.EnsureDNSSEC(zone); err == nil {
+		t.Errorf("expected an error without a key-signing key or KMS key")
+	}
+}
+
+func TestSessionOptions(t *testing.T) {
+	testCases := []struct {
+		name            string
+		secret          *v1.Secret
+		wantStatic      bool
+		wantSharedState session.SharedConfigState
+		wantErr         bool
+	}{
+		{
+			name:            "no secret uses the default credential chain",
+			wantSharedState: session.SharedConfigEnable,
+		},
+		{
+			name: "secret with keys uses static credentials",
+			secret: &v1.Secret{Data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("id"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+			}},
+			wantStatic:      true,
+			wantSharedState: session.SharedConfigDisable,
+		},
+		{
+			name:    "secret without keys",
+			secret:  &v1.Secret{Data: map[string][]byte{"REGION": []byte("eu-west-1")}},
+			wantErr: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := sessionOptions(testCase.secret)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("sessionOptions() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if testCase.wantErr {
+				return
+			}
+			if (got.Config.Credentials != nil) != testCase.wantStatic {
+				t.Errorf("expected static credentials %v, got %v", testCase.wantStatic, got.Config.Credentials)
+			}
+			if got.SharedConfigState != testCase.wantSharedState {
+				t.Errorf("expected shared config state %v, got %v", testCase.wantSharedState, got.SharedConfigState)
+			}
+		})
+	}
+}
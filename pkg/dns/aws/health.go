@@ -2,9 +2,12 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/route53"
 	"github.com/aws/aws-sdk-go/service/route53/route53iface"
 	"github.com/rs/xid"
@@ -98,6 +101,10 @@ func (c *Route53HealthCheckReconciler) findHealthCheck(ctx context.Context, endp
 	response, err := c.client.GetHealthCheckWithContext(ctx, &route53.GetHealthCheckInput{
 		HealthCheckId: &id,
 	})
+	// a health check deleted outside of the controller is created again
+	if isNoSuchHealthCheck(err) || (err == nil && response.HealthCheck == nil) {
+		return nil, false, nil
+	}
 	if err != nil {
 		return nil, false, err
 	}
@@ -107,8 +114,7 @@ func (c *Route53HealthCheckReconciler) findHealthCheck(ctx context.Context, endp
 }
 
 func (c *Route53HealthCheckReconciler) createHealthCheck(ctx context.Context, spec dns.HealthCheckSpec, endpoint *v1alpha1.Endpoint) (*route53.HealthCheck, error) {
-	address, _ := endpoint.GetAddress()
-	host := endpoint.DNSName
+	address, host := healthCheckTarget(endpoint)
 
 	// Create the health check
 	output, err := c.client.CreateHealthCheck(&route53.CreateHealthCheckInput{
@@ -116,8 +122,8 @@ func (c *Route53HealthCheckReconciler) createHealthCheck(ctx context.Context, sp
 		CallerReference: callerReference(spec.Id),
 
 		HealthCheckConfig: &route53.HealthCheckConfig{
-			IPAddress:                &address,
-			FullyQualifiedDomainName: &host,
+			IPAddress:                address,
+			FullyQualifiedDomainName: host,
 			Port:                     spec.Port,
 			ResourcePath:             &spec.Path,
 			Type:                     healthCheckType(spec.Protocol),
@@ -184,13 +190,12 @@ func healthCheckDiff(healthCheck *route53.HealthCheck, spec dns.HealthCheckSpec,
 		return result
 	}
 
-	if !valuesEqual(&endpoint.DNSName, healthCheck.HealthCheckConfig.FullyQualifiedDomainName) {
-		diff().FullyQualifiedDomainName = &endpoint.DNSName
+	address, host := healthCheckTarget(endpoint)
+	if !valuesEqual(host, healthCheck.HealthCheckConfig.FullyQualifiedDomainName) {
+		diff().FullyQualifiedDomainName = host
 	}
-
-	address, _ := endpoint.GetAddress()
-	if !valuesEqual(&address, healthCheck.HealthCheckConfig.IPAddress) {
-		diff().IPAddress = &address
+	if !valuesEqual(address, healthCheck.HealthCheckConfig.IPAddress) {
+		diff().IPAddress = address
 	}
 	if !valuesEqualWithDefault(&spec.Path, healthCheck.HealthCheckConfig.ResourcePath, defaultHealthCheckPath) {
 		diff().ResourcePath = &spec.Path
@@ -205,6 +210,18 @@ func healthCheckDiff(healthCheck *route53.HealthCheck, spec dns.HealthCheckSpec,
 	return result
 }
 
+// healthCheckTarget returns the IP address and the host requested by the health check of the endpoint. The health
+// check of an endpoint targeting an IP address requests it with the name of the endpoint as host, the health check of
+// an endpoint targeting a hostname requests the hostname, which Route53 resolves.
+func healthCheckTarget(endpoint *v1alpha1.Endpoint) (*string, *string) {
+	address, _ := endpoint.GetAddress()
+	if net.ParseIP(address) == nil {
+		return nil, &address
+	}
+	host := endpoint.DNSName
+	return &address, &host
+}
+
 func init() {
 	sid := xid.New()
 	callerReference = func(s string) *string {
@@ -256,3 +273,110 @@ func valuesEqualWithDefault[T comparable](ptr1, ptr2 *T, defaultValue T) bool {
 func getHealthCheckId(endpoint *v1alpha1.Endpoint) (string, bool) {
 	return endpoint.GetProviderSpecific(ProviderSpecificHealthCheckID)
 }
+
+// isNoSuchHealthCheck returns true if the error was returned by Route53 for a health check that does not exist
+func isNoSuchHealthCheck(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == route53.ErrCodeNoSuchHealthCheck
+}
+
+// healthCheckedEndpoint returns true if the endpoint is a weighted, latency, geoproximity or failover record set, which
+// Route53 leaves out of its answers when its health check fails
+func healthCheckedEndpoint(endpoint *v1alpha1.Endpoint) bool {
+	if endpoint.SetIdentifier == "" || len(endpoint.Targets) == 0 {
+		return false
+	}
+	for _, name := range []string{dns.ProviderSpecificWeight, dns.ProviderSpecificRegion, dns.ProviderSpecificGeoproximityRegion, dns.ProviderSpecificGeoproximityCoordinates, ProviderSpecificRegion, ProviderSpecificFailover} {
+		if _, ok := endpoint.GetProviderSpecificProperty(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// healthCheckSpec returns the spec of the health check of the endpoint of the record
+func healthCheckSpec(record *v1alpha1.DNSRecord, endpoint *v1alpha1.Endpoint) dns.HealthCheckSpec {
+	healthCheck := record.Spec.HealthCheck
+	protocol := dns.HealthCheckProtocolHTTPS
+	if healthCheck.Protocol != nil && *healthCheck.Protocol == v1alpha1.HttpProtocol {
+		protocol = dns.HealthCheckProtocolHTTP
+	}
+	port := int64(443)
+	if protocol == dns.HealthCheckProtocolHTTP {
+		port = defaultHealthCheckPort
+	}
+	if healthCheck.Port != nil {
+		port = int64(*healthCheck.Port)
+	}
+	failureThreshold := int64(defaultHealthCheckFailureThreshold)
+	if healthCheck.FailureThreshold != nil {
+		failureThreshold = int64(*healthCheck.FailureThreshold)
+	}
+	path := healthCheck.Endpoint
+	if path == "" {
+		path = defaultHealthCheckPath
+	}
+	return dns.HealthCheckSpec{
+		Id:               dns.ToBase36hash(fmt.Sprintf("%s/%s/%s/%s", record.Namespace, record.Name, endpoint.SetID(), endpoint.RecordType)),
+		Name:             endpoint.SetID(),
+		Port:             &port,
+		FailureThreshold: &failureThreshold,
+		Protocol:         &protocol,
+		Path:             path,
+	}
+}
+
+// restoreHealthCheckIDs sets the IDs of the health checks created for the published endpoints of the record on its
+// endpoints, as the endpoints of the record spec do not keep them. A record set is deleted with its health check ID.
+func restoreHealthCheckIDs(record *v1alpha1.DNSRecord) {
+	ids := map[string]string{}
+	for _, endpoint := range record.Status.PublishedEndpoints {
+		if id, ok := getHealthCheckId(endpoint); ok {
+			ids[endpointKey(endpoint)] = id
+		}
+	}
+	for _, endpoint := range append(record.Spec.Endpoints, record.Status.Endpoints...) {
+		if id, ok := ids[endpointKey(endpoint)]; ok {
+			if _, hasId := getHealthCheckId(endpoint); !hasId {
+				endpoint.SetProviderSpecific(ProviderSpecificHealthCheckID, id)
+			}
+		}
+	}
+}
+
+// ensureHealthChecks creates or updates the health checks of the endpoints of the record configuring provider health
+// checks, and sets their IDs on the endpoints so that the record sets are published with them. The IDs are removed
+// from the endpoints that no longer have a health check, the endpoints are returned so that their health checks are
+// deleted once the record sets no longer use them.
+func (p *Route53DNSProvider) ensureHealthChecks(ctx context.Context, record *v1alpha1.DNSRecord) ([]*v1alpha1.Endpoint, error) {
+	var unchecked []*v1alpha1.Endpoint
+	for _, endpoint := range record.Spec.Endpoints {
+		if record.Spec.HealthCheck == nil || !healthCheckedEndpoint(endpoint) {
+			if _, ok := getHealthCheckId(endpoint); ok {
+				unchecked = append(unchecked, endpoint.DeepCopy())
+				endpoint.DeleteProviderSpecific(ProviderSpecificHealthCheckID)
+			}
+			continue
+		}
+		result, err := p.HealthCheckReconciler().Reconcile(ctx, healthCheckSpec(record, endpoint), endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile the health check of %s: %w", endpoint.SetID(), err)
+		}
+		p.logger.V(1).Info("Reconciled health check", "endpoint", endpoint.SetID(), "result", result.Result)
+	}
+	return unchecked, nil
+}
+
+// deleteHealthChecks deletes the health checks of the endpoints. Health checks already deleted are ignored.
+func (p *Route53DNSProvider) deleteHealthChecks(ctx context.Context, endpoints []*v1alpha1.Endpoint) error {
+	var errs []error
+	for _, endpoint := range endpoints {
+		if _, ok := getHealthCheckId(endpoint); !ok {
+			continue
+		}
+		if _, err := p.HealthCheckReconciler().Delete(ctx, endpoint); err != nil && !isNoSuchHealthCheck(err) {
+			errs = append(errs, fmt.Errorf("failed to delete the health check of %s: %w", endpoint.SetID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
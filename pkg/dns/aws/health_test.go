@@ -327,7 +327,7 @@ func (m *mockRoute53API) DeleteHealthCheck(i *route53.DeleteHealthCheckInput) (*
 
 func (m *mockRoute53API) DeleteHealthCheckWithContext(_ context.Context, i *route53.DeleteHealthCheckInput, _ ...request.Option) (*route53.DeleteHealthCheckOutput, error) {
 	m.healthChecks = slice.Filter(m.healthChecks, func(h *mockHealthCheck) bool {
-		return *h.Id == *i.HealthCheckId
+		return *h.Id != *i.HealthCheckId
 	})
 	return &route53.DeleteHealthCheckOutput{}, nil
 }
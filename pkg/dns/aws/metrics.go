@@ -78,6 +78,16 @@ var (
 		},
 		[]string{operationLabel, returnCodeLabel},
 	)
+
+	// route53RequestThrottled is a prometheus counter metrics which holds the total
+	// number of requests to Route53 that were throttled and retried.
+	route53RequestThrottled = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mgc_aws_route53_request_throttled_total",
+			Help: "MGC AWS Route53 total number of throttled requests that were retried",
+		},
+		[]string{operationLabel},
+	)
 )
 
 var operationLabelValues []string
@@ -89,6 +99,7 @@ func init() {
 		route53RequestTotal,
 		route53RequestErrors,
 		route53RequestDuration,
+		route53RequestThrottled,
 	)
 
 	monitoredRoute53 := reflect.PtrTo(reflect.TypeOf(InstrumentedRoute53{}))
@@ -101,5 +112,6 @@ func init() {
 		route53RequestCount.WithLabelValues(operation).Set(0)
 		route53RequestTotal.WithLabelValues(operation, returnCodeLabelDefault).Add(0)
 		route53RequestErrors.WithLabelValues(operation, returnCodeLabelDefault).Add(0)
+		route53RequestThrottled.WithLabelValues(operation).Add(0)
 	}
 }
@@ -0,0 +1,391 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+const (
+	// ownerRecordPrefix is prepended to the name of the records to get the name of the TXT record holding their
+	// owner. A name starting with an underscore cannot clash with a hostname.
+	ownerRecordPrefix   = "_kuadrant-owner"
+	ownerRecordHeritage = "heritage=kuadrant"
+	ownerRecordOwnerKey = "kuadrant/owner="
+)
+
+// RecordPolicy sets how the provider treats the record sets that already exist in a hosted zone at the names and
+// types of a DNSRecord, e.g. records created by hand before the zone is managed by the controller
+type RecordPolicy string
+
+const (
+	// RecordPolicyNone only claims the record sets without an owner that were previously published for the DNSRecord,
+	// the other existing record sets are an ownership conflict
+	RecordPolicyNone RecordPolicy = ""
+	// RecordPolicyAdoptOnly claims the record sets without an owner at the names and types of the DNSRecord on first
+	// sight. The record sets it does not publish are never deleted.
+	RecordPolicyAdoptOnly RecordPolicy = "adopt-only"
+	// RecordPolicyStrictDelete claims the record sets like RecordPolicyAdoptOnly, and deletes the record sets at the
+	// names and types of the DNSRecord whose set identifiers are not among its endpoints
+	RecordPolicyStrictDelete RecordPolicy = "strict-delete"
+)
+
+// ParseRecordPolicy returns the record policy of the value, an error if it is not a known policy
+func ParseRecordPolicy(value string) (RecordPolicy, error) {
+	switch policy := RecordPolicy(value); policy {
+	case RecordPolicyNone, RecordPolicyAdoptOnly, RecordPolicyStrictDelete:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown record policy %q, expected one of %q or %q", value, RecordPolicyAdoptOnly, RecordPolicyStrictDelete)
+}
+
+// ValidateOwner returns an error when the policy is set without an owner ID. The record sets of other controllers are
+// only told apart by their owner, without one the record sets they own would be claimed, and deleted by
+// RecordPolicyStrictDelete, while adopted record sets could not be recorded as owned.
+func (p RecordPolicy) ValidateOwner(ownerID string) error {
+	if p != RecordPolicyNone && ownerID == "" {
+		return fmt.Errorf("the record policy %q requires an owner ID", p)
+	}
+	return nil
+}
+
+// recordKey identifies the record sets of a name and type, which share an owner
+type recordKey struct {
+	name       string
+	recordType string
+}
+
+// newRecordKey returns the key of a record set, Route53 names are returned with a trailing dot and an escaped
+// wildcard
+func newRecordKey(name, recordType string) recordKey {
+	name = strings.ToLower(strings.TrimSuffix(strings.ReplaceAll(name, `\052`, "*"), "."))
+	return recordKey{name: name, recordType: strings.ToUpper(recordType)}
+}
+
+// ownerRecordName returns the name of the TXT record holding the owner of the record sets, e.g.
+// _kuadrant-owner-a.www.example.com for the A records of www.example.com. The wildcard label of a wildcard name is
+// replaced so that the TXT record is not a wildcard itself.
+func (k recordKey) ownerRecordName() string {
+	name := k.name
+	if strings.HasPrefix(name, "*.") {
+		name = "_wildcard" + strings.TrimPrefix(name, "*")
+	}
+	return fmt.Sprintf("%s-%s.%s", ownerRecordPrefix, strings.ToLower(k.recordType), name)
+}
+
+// zoneOwnership holds the record sets of a hosted zone relevant to their ownership
+type zoneOwnership struct {
+	// ownerRecords are the TXT record sets by name
+	ownerRecords map[string]*route53.ResourceRecordSet
+	// recordSets are the record sets by key and set identifier, empty for a simple record set
+	recordSets map[recordKey]map[string]*route53.ResourceRecordSet
+}
+
+func (p *Route53DNSProvider) zoneOwnership(zoneID string) (*zoneOwnership, error) {
+	recordSets, err := p.recordSetCache.List(zoneID, func() ([]*route53.ResourceRecordSet, error) {
+		return p.listRecordSets(zoneID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ownership := &zoneOwnership{
+		ownerRecords: map[string]*route53.ResourceRecordSet{},
+		recordSets:   map[recordKey]map[string]*route53.ResourceRecordSet{},
+	}
+	for _, recordSet := range recordSets {
+		key := newRecordKey(aws.StringValue(recordSet.Name), aws.StringValue(recordSet.Type))
+		if key.recordType == route53.RRTypeTxt {
+			ownership.ownerRecords[key.name] = recordSet
+		}
+		if _, ok := ownership.recordSets[key]; !ok {
+			ownership.recordSets[key] = map[string]*route53.ResourceRecordSet{}
+		}
+		ownership.recordSets[key][aws.StringValue(recordSet.SetIdentifier)] = recordSet
+	}
+	return ownership, nil
+}
+
+// listRecordSets returns all the record sets of the hosted zone, following the pages of the listing
+func (p *Route53DNSProvider) listRecordSets(zoneID string) ([]*route53.ResourceRecordSet, error) {
+	var recordSets []*route53.ResourceRecordSet
+	input := &route53.ListResourceRecordSetsInput{HostedZoneId: aws.String(zoneID)}
+	for {
+		output, err := p.client.ListResourceRecordSets(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list record sets in zone %s: %w", zoneID, err)
+		}
+		recordSets = append(recordSets, output.ResourceRecordSets...)
+		if !aws.BoolValue(output.IsTruncated) {
+			return recordSets, nil
+		}
+		input.StartRecordName = output.NextRecordName
+		input.StartRecordType = output.NextRecordType
+		input.StartRecordIdentifier = output.NextRecordIdentifier
+	}
+}
+
+// owner returns the owner of the record sets of the key and true, or false if there is no TXT record for them. The
+// owner is empty when the TXT record was not written by an owner.
+func (o *zoneOwnership) owner(key recordKey) (string, bool) {
+	recordSet, ok := o.ownerRecords[key.ownerRecordName()]
+	if !ok {
+		return "", false
+	}
+	for _, resourceRecord := range recordSet.ResourceRecords {
+		fields := strings.Split(strings.Trim(aws.StringValue(resourceRecord.Value), `"`), ",")
+		if len(fields) != 2 || fields[0] != ownerRecordHeritage || !strings.HasPrefix(fields[1], ownerRecordOwnerKey) {
+			continue
+		}
+		return strings.TrimPrefix(fields[1], ownerRecordOwnerKey), true
+	}
+	return "", true
+}
+
+func (o *zoneOwnership) exists(key recordKey) bool {
+	return len(o.recordSets[key]) > 0
+}
+
+// unchanged returns true if the record set exists in the zone with the same values, so that upserting it is a no-op
+func (o *zoneOwnership) unchanged(key recordKey, recordSet *route53.ResourceRecordSet) bool {
+	existing, ok := o.recordSets[key][aws.StringValue(recordSet.SetIdentifier)]
+	if !ok {
+		return false
+	}
+	return reflect.DeepEqual(normalizedRecordSet(existing), normalizedRecordSet(recordSet))
+}
+
+// normalizedRecordSet returns a copy of the record set comparable with the record sets listed from Route53, which
+// returns the names with a trailing dot and an escaped wildcard
+func normalizedRecordSet(recordSet *route53.ResourceRecordSet) *route53.ResourceRecordSet {
+	normalized := *recordSet
+	key := newRecordKey(aws.StringValue(recordSet.Name), aws.StringValue(recordSet.Type))
+	normalized.Name, normalized.Type = aws.String(key.name), aws.String(key.recordType)
+	if normalized.SetIdentifier != nil && *normalized.SetIdentifier == "" {
+		normalized.SetIdentifier = nil
+	}
+	if recordSet.AliasTarget != nil {
+		alias := *recordSet.AliasTarget
+		alias.DNSName = aws.String(strings.ToLower(strings.TrimSuffix(aws.StringValue(alias.DNSName), ".")))
+		normalized.AliasTarget = &alias
+	}
+	values := make([]string, 0, len(recordSet.ResourceRecords))
+	for _, resourceRecord := range recordSet.ResourceRecords {
+		values = append(values, aws.StringValue(resourceRecord.Value))
+	}
+	sort.Strings(values)
+	normalized.ResourceRecords = nil
+	for _, value := range values {
+		normalized.ResourceRecords = append(normalized.ResourceRecords, &route53.ResourceRecord{Value: aws.String(value)})
+	}
+	return &normalized
+}
+
+func (p *Route53DNSProvider) ownerRecordSet(key recordKey) *route53.ResourceRecordSet {
+	return &route53.ResourceRecordSet{
+		Name: aws.String(key.ownerRecordName()),
+		Type: aws.String(route53.RRTypeTxt),
+		TTL:  aws.Int64(dns.DefaultTTL),
+		ResourceRecords: []*route53.ResourceRecord{
+			{Value: aws.String(fmt.Sprintf(`"%s,%s%s"`, ownerRecordHeritage, ownerRecordOwnerKey, p.ownerID))},
+		},
+	}
+}
+
+// ownedChanges returns the changes to the record sets owned by the provider, with the changes to their TXT owner
+// records, and the names of the record sets left untouched because they are owned by another controller.
+// Record sets without an owner are claimed when they do not exist yet, were previously published for the record, or
+// the record policy adopts them.
+// The TXT owner record is deleted with the last record set it owns. Deletes of record sets that do not exist, and
+// upserts of record sets that already exist with the same values, are dropped so that an unchanged record does not
+// write to the zone.
+func (p *Route53DNSProvider) ownedChanges(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone, changes []*route53.Change) ([]*route53.Change, []string, error) {
+	ownership, err := p.zoneOwnership(managedZone.Status.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	published := sets.New[recordKey]()
+	for _, endpoint := range record.Status.Endpoints {
+		change, err := p.changeForEndpoint(endpoint, managedZone, string(deleteAction))
+		if err != nil {
+			continue
+		}
+		published.Insert(newRecordKey(aws.StringValue(change.ResourceRecordSet.Name), aws.StringValue(change.ResourceRecordSet.Type)))
+	}
+
+	var keys []recordKey
+	keyChanges := map[recordKey][]*route53.Change{}
+	for _, change := range changes {
+		key := newRecordKey(aws.StringValue(change.ResourceRecordSet.Name), aws.StringValue(change.ResourceRecordSet.Type))
+		if _, ok := keyChanges[key]; !ok {
+			keys = append(keys, key)
+		}
+		keyChanges[key] = append(keyChanges[key], change)
+	}
+
+	var owned []*route53.Change
+	conflicts := sets.New[string]()
+	for _, key := range keys {
+		owner, hasOwner := ownership.owner(key)
+		if (hasOwner && owner != p.ownerID) || (!hasOwner && ownership.exists(key) && !published.Has(key) && p.recordPolicy == RecordPolicyNone) {
+			conflicts.Insert(key.name)
+			continue
+		}
+
+		// the record sets of the key once the changes are applied
+		remaining := sets.New[string]()
+		for setIdentifier := range ownership.recordSets[key] {
+			remaining.Insert(setIdentifier)
+		}
+		for _, change := range keyChanges[key] {
+			setIdentifier := aws.StringValue(change.ResourceRecordSet.SetIdentifier)
+			if aws.StringValue(change.Action) == route53.ChangeActionDelete {
+				if !remaining.Has(setIdentifier) {
+					continue
+				}
+				remaining.Delete(setIdentifier)
+			} else {
+				remaining.Insert(setIdentifier)
+				if ownership.unchanged(key, change.ResourceRecordSet) {
+					continue
+				}
+			}
+			owned = append(owned, change)
+		}
+
+		switch {
+		case remaining.Len() > 0 && !hasOwner:
+			owned = append(owned, &route53.Change{Action: aws.String(route53.ChangeActionUpsert), ResourceRecordSet: p.ownerRecordSet(key)})
+		case remaining.Len() == 0 && hasOwner:
+			owned = append(owned, &route53.Change{Action: aws.String(route53.ChangeActionDelete), ResourceRecordSet: ownership.ownerRecords[key.ownerRecordName()]})
+		}
+	}
+
+	names := conflicts.UnsortedList()
+	sort.Strings(names)
+	return owned, names, nil
+}
+
+// caaConflicts returns the changes without the upserts of the CAA record sets that hold values in the zone not
+// previously published for the record, e.g. CAs authorised by hand at the apex of the zone, and the names of those
+// record sets. Upserting them would drop the values, and stop their CAs from issuing certificates for the domain.
+func (p *Route53DNSProvider) caaConflicts(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone, changes []*route53.Change) ([]*route53.Change, []string, error) {
+	hasCAA := false
+	for _, change := range changes {
+		if aws.StringValue(change.Action) != route53.ChangeActionDelete && aws.StringValue(change.ResourceRecordSet.Type) == route53.RRTypeCaa {
+			hasCAA = true
+			break
+		}
+	}
+	if !hasCAA {
+		return changes, nil, nil
+	}
+	ownership, err := p.zoneOwnership(managedZone.Status.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	published := map[recordKey]sets.Set[string]{}
+	for _, endpoint := range record.Status.Endpoints {
+		if endpoint.RecordType != string(v1alpha1.CAARecordType) {
+			continue
+		}
+		change, err := p.changeForEndpoint(endpoint, managedZone, string(deleteAction))
+		if err != nil {
+			continue
+		}
+		key := newRecordKey(aws.StringValue(change.ResourceRecordSet.Name), aws.StringValue(change.ResourceRecordSet.Type))
+		published[key] = recordSetValues(change.ResourceRecordSet).Union(published[key])
+	}
+
+	var allowed []*route53.Change
+	conflicts := sets.New[string]()
+	for _, change := range changes {
+		recordSet := change.ResourceRecordSet
+		key := newRecordKey(aws.StringValue(recordSet.Name), aws.StringValue(recordSet.Type))
+		if existing, ok := ownership.recordSets[key][aws.StringValue(recordSet.SetIdentifier)]; ok &&
+			aws.StringValue(change.Action) != route53.ChangeActionDelete && key.recordType == route53.RRTypeCaa {
+			if foreign := recordSetValues(existing).Difference(recordSetValues(recordSet)).Difference(published[key]); foreign.Len() > 0 {
+				conflicts.Insert(key.name)
+				continue
+			}
+		}
+		allowed = append(allowed, change)
+	}
+	return allowed, sets.List(conflicts), nil
+}
+
+// recordSetValues returns the values of the resource records of the record set
+func recordSetValues(recordSet *route53.ResourceRecordSet) sets.Set[string] {
+	values := sets.New[string]()
+	for _, resourceRecord := range recordSet.ResourceRecords {
+		values.Insert(aws.StringValue(resourceRecord.Value))
+	}
+	return values
+}
+
+// foreignDeletes returns the deletes of the record sets that exist in the zone at the names and types upserted by the
+// changes, but whose set identifiers are neither upserted nor already deleted by them. Only the strict-delete record
+// policy deletes them.
+func (p *Route53DNSProvider) foreignDeletes(managedZone *v1alpha1.ManagedZone, changes []*route53.Change) ([]*route53.Change, error) {
+	ownership, err := p.zoneOwnership(managedZone.Status.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []recordKey
+	upserted := sets.New[recordKey]()
+	changed := map[recordKey]sets.Set[string]{}
+	for _, change := range changes {
+		key := newRecordKey(aws.StringValue(change.ResourceRecordSet.Name), aws.StringValue(change.ResourceRecordSet.Type))
+		if _, ok := changed[key]; !ok {
+			changed[key] = sets.New[string]()
+		}
+		if aws.StringValue(change.Action) != route53.ChangeActionDelete && !upserted.Has(key) {
+			upserted.Insert(key)
+			keys = append(keys, key)
+		}
+		changed[key].Insert(aws.StringValue(change.ResourceRecordSet.SetIdentifier))
+	}
+
+	var deletes []*route53.Change
+	for _, key := range keys {
+		setIdentifiers := make([]string, 0, len(ownership.recordSets[key]))
+		for setIdentifier := range ownership.recordSets[key] {
+			setIdentifiers = append(setIdentifiers, setIdentifier)
+		}
+		sort.Strings(setIdentifiers)
+		for _, setIdentifier := range setIdentifiers {
+			if changed[key].Has(setIdentifier) {
+				continue
+			}
+			deletes = append(deletes, &route53.Change{Action: aws.String(route53.ChangeActionDelete), ResourceRecordSet: ownership.recordSets[key][setIdentifier]})
+		}
+	}
+	return deletes, nil
+}
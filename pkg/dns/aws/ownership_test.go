@@ -0,0 +1,446 @@
+//go:build unit
+
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/go-logr/logr"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+// mockZoneRoute53API lists the record sets of a hosted zone one per page, and records the changes sent
+type mockZoneRoute53API struct {
+	mockChangeRoute53API
+	recordSets []*route53.ResourceRecordSet
+	// lists is the number of listings of the zone, counting their first page
+	lists int
+}
+
+func (m *mockZoneRoute53API) ListResourceRecordSets(input *route53.ListResourceRecordSetsInput) (*route53.ListResourceRecordSetsOutput, error) {
+	start := 0
+	if input.StartRecordName == nil {
+		m.lists++
+	} else {
+		for i, recordSet := range m.recordSets {
+			if aws.StringValue(recordSet.Name) == aws.StringValue(input.StartRecordName) &&
+				aws.StringValue(recordSet.Type) == aws.StringValue(input.StartRecordType) &&
+				aws.StringValue(recordSet.SetIdentifier) == aws.StringValue(input.StartRecordIdentifier) {
+				start = i
+				break
+			}
+		}
+	}
+	output := &route53.ListResourceRecordSetsOutput{}
+	if start < len(m.recordSets) {
+		output.ResourceRecordSets = m.recordSets[start : start+1]
+	}
+	if start+1 < len(m.recordSets) {
+		output.IsTruncated = aws.Bool(true)
+		output.NextRecordName = m.recordSets[start+1].Name
+		output.NextRecordType = m.recordSets[start+1].Type
+		output.NextRecordIdentifier = m.recordSets[start+1].SetIdentifier
+	}
+	return output, nil
+}
+
+func TestRoute53DNSProvider_ownership(t *testing.T) {
+	endpoint := func(name string) *v1alpha1.Endpoint {
+		return &v1alpha1.Endpoint{DNSName: name, RecordType: "A", Targets: []string{"1.1.1.1"}}
+	}
+	recordSet := func(name, recordType string) *route53.ResourceRecordSet {
+		return &route53.ResourceRecordSet{Name: aws.String(name), Type: aws.String(recordType)}
+	}
+	ownerRecordSet := func(name, owner string) *route53.ResourceRecordSet {
+		txt := recordSet(name, route53.RRTypeTxt)
+		txt.ResourceRecords = []*route53.ResourceRecord{{Value: aws.String(`"heritage=kuadrant,kuadrant/owner=` + owner + `"`)}}
+		return txt
+	}
+
+	testCases := []struct {
+		name       string
+		delete     bool
+		endpoints  []*v1alpha1.Endpoint
+		published  []*v1alpha1.Endpoint
+		recordSets []*route53.ResourceRecordSet
+		// wantChanges are the expected changes as action and name
+		wantChanges  [][2]string
+		wantConflict bool
+	}{
+		{
+			name:      "creates the owner record for a new record",
+			endpoints: []*v1alpha1.Endpoint{endpoint("new.example.com")},
+			wantChanges: [][2]string{
+				{route53.ChangeActionUpsert, "new.example.com"},
+				{route53.ChangeActionUpsert, "_kuadrant-owner-a.new.example.com"},
+			},
+		},
+		{
+			name:      "updates an owned record",
+			endpoints: []*v1alpha1.Endpoint{endpoint("owned.example.com")},
+			recordSets: []*route53.ResourceRecordSet{
+				ownerRecordSet("_kuadrant-owner-a.owned.example.com.", "test-owner"),
+				recordSet("owned.example.com.", route53.RRTypeA),
+			},
+			wantChanges: [][2]string{
+				{route53.ChangeActionUpsert, "owned.example.com"},
+			},
+		},
+		{
+			name:      "leaves a record owned by another controller untouched",
+			endpoints: []*v1alpha1.Endpoint{endpoint("foreign.example.com"), endpoint("new.example.com")},
+			recordSets: []*route53.ResourceRecordSet{
+				ownerRecordSet("_kuadrant-owner-a.foreign.example.com.", "other-owner"),
+				recordSet("foreign.example.com.", route53.RRTypeA),
+			},
+			wantChanges: [][2]string{
+				{route53.ChangeActionUpsert, "new.example.com"},
+				{route53.ChangeActionUpsert, "_kuadrant-owner-a.new.example.com"},
+			},
+			wantConflict: true,
+		},
+		{
+			name:      "leaves an existing record without owner untouched",
+			endpoints: []*v1alpha1.Endpoint{endpoint("manual.example.com")},
+			recordSets: []*route53.ResourceRecordSet{
+				recordSet("manual.example.com.", route53.RRTypeA),
+			},
+			wantConflict: true,
+		},
+		{
+			name:      "claims a previously published record without owner",
+			endpoints: []*v1alpha1.Endpoint{endpoint("published.example.com")},
+			published: []*v1alpha1.Endpoint{endpoint("published.example.com")},
+			recordSets: []*route53.ResourceRecordSet{
+				recordSet("published.example.com.", route53.RRTypeA),
+			},
+			wantChanges: [][2]string{
+				{route53.ChangeActionUpsert, "published.example.com"},
+				{route53.ChangeActionUpsert, "_kuadrant-owner-a.published.example.com"},
+			},
+		},
+		{
+			name:      "deletes the owner record with the record",
+			delete:    true,
+			endpoints: []*v1alpha1.Endpoint{endpoint("owned.example.com")},
+			recordSets: []*route53.ResourceRecordSet{
+				ownerRecordSet("_kuadrant-owner-a.owned.example.com.", "test-owner"),
+				recordSet("owned.example.com.", route53.RRTypeA),
+			},
+			wantChanges: [][2]string{
+				{route53.ChangeActionDelete, "owned.example.com"},
+				{route53.ChangeActionDelete, "_kuadrant-owner-a.owned.example.com."},
+			},
+		},
+		{
+			name:      "does not delete a record owned by another controller",
+			delete:    true,
+			endpoints: []*v1alpha1.Endpoint{endpoint("foreign.example.com")},
+			recordSets: []*route53.ResourceRecordSet{
+				ownerRecordSet("_kuadrant-owner-a.foreign.example.com.", "other-owner"),
+				recordSet("foreign.example.com.", route53.RRTypeA),
+			},
+		},
+		{
+			name:      "keeps the owner record of a wildcard while a record set remains",
+			delete:    true,
+			endpoints: []*v1alpha1.Endpoint{{DNSName: "*.example.com", RecordType: "A", SetIdentifier: "a", Targets: []string{"1.1.1.1"}}},
+			recordSets: []*route53.ResourceRecordSet{
+				ownerRecordSet("_kuadrant-owner-a._wildcard.example.com.", "test-owner"),
+				{Name: aws.String(`\052.example.com.`), Type: aws.String(route53.RRTypeA), SetIdentifier: aws.String("a")},
+				{Name: aws.String(`\052.example.com.`), Type: aws.String(route53.RRTypeA), SetIdentifier: aws.String("b")},
+			},
+			wantChanges: [][2]string{
+				{route53.ChangeActionDelete, "*.example.com"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockClient := &mockZoneRoute53API{recordSets: testCase.recordSets}
+			provider := &Route53DNSProvider{
+				client:  &InstrumentedRoute53{mockClient},
+				logger:  logr.Discard(),
+				ownerID: "test-owner",
+			}
+			record := &v1alpha1.DNSRecord{
+				Spec:   v1alpha1.DNSRecordSpec{Endpoints: testCase.endpoints},
+				Status: v1alpha1.DNSRecordStatus{Endpoints: testCase.published},
+			}
+			zone := &v1alpha1.ManagedZone{
+				Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+			}
+
+			var err error
+			if testCase.delete {
+				err = provider.Delete(record, zone)
+			} else {
+				err = provider.Ensure(record, zone)
+			}
+			if testCase.wantConflict != errors.Is(err, dns.ErrOwnershipConflict) {
+				t.Fatalf("expected ownership conflict %v, got error %v", testCase.wantConflict, err)
+			}
+			if !testCase.wantConflict && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if len(mockClient.changes) != len(testCase.wantChanges) {
+				t.Fatalf("expected %d changes, got %d: %v", len(testCase.wantChanges), len(mockClient.changes), mockClient.changes)
+			}
+			for i, change := range mockClient.changes {
+				action, name := aws.StringValue(change.Action), aws.StringValue(change.ResourceRecordSet.Name)
+				if action != testCase.wantChanges[i][0] || name != testCase.wantChanges[i][1] {
+					t.Errorf("expected change %d to be %v, got %s %s", i, testCase.wantChanges[i], action, name)
+				}
+			}
+		})
+	}
+}
+
+func TestRoute53DNSProvider_ownershipDisabled(t *testing.T) {
+	mockClient := &mockChangeRoute53API{}
+	provider := &Route53DNSProvider{
+		client: &InstrumentedRoute53{mockClient},
+		logger: logr.Discard(),
+	}
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*v1alpha1.Endpoint{{DNSName: "new.example.com", RecordType: "A", Targets: []string{"1.1.1.1"}}},
+		},
+	}
+	zone := &v1alpha1.ManagedZone{
+		Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+	}
+
+	// the zone is not listed, the mock panics on ListResourceRecordSets
+	if err := provider.Ensure(record, zone); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(mockClient.changes) != 1 {
+		t.Fatalf("expected no owner record to be created, got changes %v", mockClient.changes)
+	}
+}
+
+func TestRoute53DNSProvider_recordPolicy(t *testing.T) {
+	recordSet := func(name, setIdentifier string) *route53.ResourceRecordSet {
+		return &route53.ResourceRecordSet{
+			Name:            aws.String(name),
+			Type:            aws.String(route53.RRTypeA),
+			SetIdentifier:   aws.String(setIdentifier),
+			ResourceRecords: []*route53.ResourceRecord{{Value: aws.String("2.2.2.2")}},
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		ownerID      string
+		recordPolicy RecordPolicy
+		// wantChanges are the expected changes as action, name and set identifier
+		wantChanges  [][3]string
+		wantConflict bool
+	}{
+		{
+			name:         "does not adopt the foreign records by default",
+			ownerID:      "test-owner",
+			wantConflict: true,
+		},
+		{
+			name:         "adopts the foreign records at the names of the record",
+			ownerID:      "test-owner",
+			recordPolicy: RecordPolicyAdoptOnly,
+			wantChanges: [][3]string{
+				{route53.ChangeActionUpsert, "app.example.com", "a"},
+				{route53.ChangeActionUpsert, "_kuadrant-owner-a.app.example.com", ""},
+			},
+		},
+		{
+			name:         "deletes the foreign records at the names of the record",
+			ownerID:      "test-owner",
+			recordPolicy: RecordPolicyStrictDelete,
+			wantChanges: [][3]string{
+				{route53.ChangeActionDelete, "app.example.com.", "manual"},
+				{route53.ChangeActionUpsert, "app.example.com", "a"},
+				{route53.ChangeActionUpsert, "_kuadrant-owner-a.app.example.com", ""},
+			},
+		},
+		{
+			name:         "keeps the foreign records without an owner ID",
+			recordPolicy: RecordPolicyAdoptOnly,
+			wantChanges: [][3]string{
+				{route53.ChangeActionUpsert, "app.example.com", "a"},
+			},
+		},
+		{
+			name:         "deletes the foreign records without an owner ID",
+			recordPolicy: RecordPolicyStrictDelete,
+			wantChanges: [][3]string{
+				{route53.ChangeActionDelete, "app.example.com.", "manual"},
+				{route53.ChangeActionUpsert, "app.example.com", "a"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockClient := &mockZoneRoute53API{recordSets: []*route53.ResourceRecordSet{
+				recordSet("app.example.com.", "a"),
+				recordSet("app.example.com.", "manual"),
+				recordSet("other.example.com.", ""),
+			}}
+			provider := &Route53DNSProvider{
+				client:       &InstrumentedRoute53{mockClient},
+				logger:       logr.Discard(),
+				ownerID:      testCase.ownerID,
+				recordPolicy: testCase.recordPolicy,
+			}
+			record := &v1alpha1.DNSRecord{
+				Spec: v1alpha1.DNSRecordSpec{
+					Endpoints: []*v1alpha1.Endpoint{{DNSName: "app.example.com", RecordType: "A", SetIdentifier: "a", Targets: []string{"1.1.1.1"}}},
+				},
+			}
+			zone := &v1alpha1.ManagedZone{
+				Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"},
+			}
+
+			err := provider.Ensure(record, zone)
+			if testCase.wantConflict != errors.Is(err, dns.ErrOwnershipConflict) {
+				t.Fatalf("expected ownership conflict %v, got error %v", testCase.wantConflict, err)
+			}
+			if !testCase.wantConflict && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if len(mockClient.changes) != len(testCase.wantChanges) {
+				t.Fatalf("expected %d changes, got %d: %v", len(testCase.wantChanges), len(mockClient.changes), mockClient.changes)
+			}
+			for i, change := range mockClient.changes {
+				action, name, setIdentifier := aws.StringValue(change.Action), aws.StringValue(change.ResourceRecordSet.Name), aws.StringValue(change.ResourceRecordSet.SetIdentifier)
+				if action != testCase.wantChanges[i][0] || name != testCase.wantChanges[i][1] || setIdentifier != testCase.wantChanges[i][2] {
+					t.Errorf("expected change %d to be %v, got %s %s %s", i, testCase.wantChanges[i], action, name, setIdentifier)
+				}
+				// the records at other names are never deleted
+				if name == "other.example.com." {
+					t.Errorf("expected the foreign record at another name to survive, got change %v", change)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRecordPolicy(t *testing.T) {
+	for _, value := range []string{"", "adopt-only", "strict-delete"} {
+		if policy, err := ParseRecordPolicy(value); err != nil || string(policy) != value {
+			t.Errorf("expected policy %q, got %q with error %v", value, policy, err)
+		}
+	}
+	if _, err := ParseRecordPolicy("delete-all"); err == nil {
+		t.Errorf("expected an error for an unknown policy")
+	}
+}
+
+func TestRecordPolicy_ValidateOwner(t *testing.T) {
+	for _, policy := range []RecordPolicy{RecordPolicyNone, RecordPolicyAdoptOnly, RecordPolicyStrictDelete} {
+		if err := policy.ValidateOwner("test-owner"); err != nil {
+			t.Errorf("expected policy %q to be valid with an owner ID, got %v", policy, err)
+		}
+	}
+	if err := RecordPolicyNone.ValidateOwner(""); err != nil {
+		t.Errorf("expected no policy to be valid without an owner ID, got %v", err)
+	}
+	for _, policy := range []RecordPolicy{RecordPolicyAdoptOnly, RecordPolicyStrictDelete} {
+		if err := policy.ValidateOwner(""); err == nil {
+			t.Errorf("expected an error for policy %q without an owner ID", policy)
+		}
+	}
+}
+
+func TestRoute53DNSProvider_caaConflicts(t *testing.T) {
+	endpoint := func(domains ...string) *v1alpha1.Endpoint {
+		endpoint := &v1alpha1.Endpoint{DNSName: "example.com", RecordType: "CAA"}
+		for _, domain := range domains {
+			endpoint.CAATargets = append(endpoint.CAATargets, v1alpha1.CAATarget{Tag: "issue", Value: domain})
+		}
+		return endpoint
+	}
+	recordSet := func(domains ...string) *route53.ResourceRecordSet {
+		recordSet := &route53.ResourceRecordSet{Name: aws.String("example.com."), Type: aws.String(route53.RRTypeCaa), TTL: aws.Int64(300)}
+		for _, domain := range domains {
+			recordSet.ResourceRecords = append(recordSet.ResourceRecords, &route53.ResourceRecord{Value: aws.String(`0 issue "` + domain + `"`)})
+		}
+		return recordSet
+	}
+
+	testCases := []struct {
+		name         string
+		recordPolicy RecordPolicy
+		endpoint     *v1alpha1.Endpoint
+		published    *v1alpha1.Endpoint
+		recordSets   []*route53.ResourceRecordSet
+		wantUpsert   bool
+		wantConflict bool
+	}{
+		{
+			name:       "creates a new CAA record",
+			endpoint:   endpoint("letsencrypt.org"),
+			wantUpsert: true,
+		},
+		{
+			name:         "does not drop the CAs authorised outside of the controller",
+			endpoint:     endpoint("letsencrypt.org"),
+			recordSets:   []*route53.ResourceRecordSet{recordSet("amazon.com")},
+			wantConflict: true,
+		},
+		{
+			name:       "keeps the CAs authorised outside of the controller that are in the record",
+			endpoint:   endpoint("amazon.com", "letsencrypt.org"),
+			recordSets: []*route53.ResourceRecordSet{recordSet("amazon.com")},
+			wantUpsert: true,
+		},
+		{
+			name:       "replaces the CAs previously published for the record",
+			endpoint:   endpoint("sectigo.com"),
+			published:  endpoint("letsencrypt.org"),
+			recordSets: []*route53.ResourceRecordSet{recordSet("letsencrypt.org")},
+			wantUpsert: true,
+		},
+		{
+			name:         "replaces the existing CAs adopted by the record policy",
+			recordPolicy: RecordPolicyAdoptOnly,
+			endpoint:     endpoint("letsencrypt.org"),
+			recordSets:   []*route53.ResourceRecordSet{recordSet("amazon.com")},
+			wantUpsert:   true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockClient := &mockZoneRoute53API{recordSets: testCase.recordSets}
+			provider := &Route53DNSProvider{
+				client:       &InstrumentedRoute53{mockClient},
+				logger:       logr.Discard(),
+				recordPolicy: testCase.recordPolicy,
+			}
+			record := &v1alpha1.DNSRecord{Spec: v1alpha1.DNSRecordSpec{Endpoints: []*v1alpha1.Endpoint{testCase.endpoint}}}
+			if testCase.published != nil {
+				record.Status.Endpoints = []*v1alpha1.Endpoint{testCase.published}
+			}
+			zone := &v1alpha1.ManagedZone{Status: v1alpha1.ManagedZoneStatus{ID: "test-zone"}}
+
+			err := provider.Ensure(record, zone)
+			if testCase.wantConflict != errors.Is(err, dns.ErrOwnershipConflict) {
+				t.Fatalf("expected conflict %v, got error %v", testCase.wantConflict, err)
+			}
+			if !testCase.wantConflict && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if upserted := len(mockClient.changes) > 0; upserted != testCase.wantUpsert {
+				t.Errorf("expected the CAA record to be upserted %v, got changes %v", testCase.wantUpsert, mockClient.changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/aws/aws-sdk-go/service/route53/route53iface"
+	"golang.org/x/time/rate"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultRoute53RequestsPerSecond is the default rate of requests sent to Route53 for each hosted zone. The Route53 API
+// limit of five requests per second applies to the whole account, so the requests to several zones of an account can
+// still be throttled, and are then retried with a backoff.
+const DefaultRoute53RequestsPerSecond = 5
+
+// accountScope is the rate limiter key of the requests that do not target a hosted zone
+const accountScope = ""
+
+// RateLimiter limits the rate of Route53 requests with a token bucket per hosted zone, shared by every provider
+// created with it, and retries throttled requests with an exponential backoff and jitter.
+type RateLimiter struct {
+	limit   rate.Limit
+	burst   int
+	backoff wait.Backoff
+
+	mutex    sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond requests per hosted zone
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	return &RateLimiter{
+		limit: rate.Limit(requestsPerSecond),
+		burst: int(math.Max(1, math.Ceil(requestsPerSecond))),
+		backoff: wait.Backoff{
+			Duration: 200 * time.Millisecond,
+			Factor:   2,
+			Jitter:   0.5,
+			Steps:    5,
+			Cap:      10 * time.Second,
+		},
+		limiters: map[string]*rate.Limiter{},
+	}
+}
+
+func (l *RateLimiter) limiter(zoneID string) *rate.Limiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	limiter, ok := l.limiters[zoneID]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[zoneID] = limiter
+	}
+	return limiter
+}
+
+// Do waits for the rate limiter of the zone and calls f, retrying while f returns a throttling error and the
+// backoff steps are not exhausted. A nil RateLimiter calls f once.
+func (l *RateLimiter) Do(ctx context.Context, operation, zoneID string, f func() error) error {
+	if l == nil {
+		return f()
+	}
+
+	backoff := l.backoff
+	for {
+		if err := l.limiter(zoneID).Wait(ctx); err != nil {
+			return err
+		}
+		err := f()
+		if !request.IsErrorThrottle(err) || backoff.Steps <= 1 {
+			return err
+		}
+		route53RequestThrottled.WithLabelValues(operation).Inc()
+
+		timer := time.NewTimer(backoff.Step())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// zoneScope returns the rate limiter key of the hosted zone, the ID is returned by Route53 with a /hostedzone/ prefix
+// that may be omitted in the ManagedZone spec
+func zoneScope(zoneID *string) string {
+	return strings.TrimPrefix(aws.StringValue(zoneID), "/hostedzone/")
+}
+
+// rateLimitedRoute53 sends the requests used by the Route53DNSProvider through a RateLimiter
+type rateLimitedRoute53 struct {
+	route53iface.Route53API
+	limiter *RateLimiter
+}
+
+func (c *rateLimitedRoute53) ListHostedZones(input *route53.ListHostedZonesInput) (output *route53.ListHostedZonesOutput, err error) {
+	err = c.limiter.Do(context.Background(), "ListHostedZones", accountScope, func() error {
+		output, err = c.Route53API.ListHostedZones(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) ChangeResourceRecordSets(input *route53.ChangeResourceRecordSetsInput) (output *route53.ChangeResourceRecordSetsOutput, err error) {
+	err = c.limiter.Do(context.Background(), "ChangeResourceRecordSets", zoneScope(input.HostedZoneId), func() error {
+		output, err = c.Route53API.ChangeResourceRecordSets(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) ListResourceRecordSets(input *route53.ListResourceRecordSetsInput) (output *route53.ListResourceRecordSetsOutput, err error) {
+	err = c.limiter.Do(context.Background(), "ListResourceRecordSets", zoneScope(input.HostedZoneId), func() error {
+		output, err = c.Route53API.ListResourceRecordSets(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) CreateHealthCheck(input *route53.CreateHealthCheckInput) (output *route53.CreateHealthCheckOutput, err error) {
+	err = c.limiter.Do(context.Background(), "CreateHealthCheck", accountScope, func() error {
+		output, err = c.Route53API.CreateHealthCheck(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) GetHostedZone(input *route53.GetHostedZoneInput) (output *route53.GetHostedZoneOutput, err error) {
+	err = c.limiter.Do(context.Background(), "GetHostedZone", zoneScope(input.Id), func() error {
+		output, err = c.Route53API.GetHostedZone(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) UpdateHostedZoneComment(input *route53.UpdateHostedZoneCommentInput) (output *route53.UpdateHostedZoneCommentOutput, err error) {
+	err = c.limiter.Do(context.Background(), "UpdateHostedZoneComment", zoneScope(input.Id), func() error {
+		output, err = c.Route53API.UpdateHostedZoneComment(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) CreateHostedZone(input *route53.CreateHostedZoneInput) (output *route53.CreateHostedZoneOutput, err error) {
+	err = c.limiter.Do(context.Background(), "CreateHostedZone", accountScope, func() error {
+		output, err = c.Route53API.CreateHostedZone(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) DeleteHostedZone(input *route53.DeleteHostedZoneInput) (output *route53.DeleteHostedZoneOutput, err error) {
+	err = c.limiter.Do(context.Background(), "DeleteHostedZone", zoneScope(input.Id), func() error {
+		output, err = c.Route53API.DeleteHostedZone(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) GetHealthCheckWithContext(ctx aws.Context, input *route53.GetHealthCheckInput, opts ...request.Option) (output *route53.GetHealthCheckOutput, err error) {
+	err = c.limiter.Do(ctx, "GetHealthCheckWithContext", accountScope, func() error {
+		output, err = c.Route53API.GetHealthCheckWithContext(ctx, input, opts...)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) UpdateHealthCheckWithContext(ctx aws.Context, input *route53.UpdateHealthCheckInput, opts ...request.Option) (output *route53.UpdateHealthCheckOutput, err error) {
+	err = c.limiter.Do(ctx, "UpdateHealthCheckWithContext", accountScope, func() error {
+		output, err = c.Route53API.UpdateHealthCheckWithContext(ctx, input, opts...)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) DeleteHealthCheckWithContext(ctx aws.Context, input *route53.DeleteHealthCheckInput, opts ...request.Option) (output *route53.DeleteHealthCheckOutput, err error) {
+	err = c.limiter.Do(ctx, "DeleteHealthCheckWithContext", accountScope, func() error {
+		output, err = c.Route53API.DeleteHealthCheckWithContext(ctx, input, opts...)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) ChangeTagsForResourceWithContext(ctx aws.Context, input *route53.ChangeTagsForResourceInput, opts ...request.Option) (output *route53.ChangeTagsForResourceOutput, err error) {
+	err = c.limiter.Do(ctx, "ChangeTagsForResourceWithContext", accountScope, func() error {
+		output, err = c.Route53API.ChangeTagsForResourceWithContext(ctx, input, opts...)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) GetDNSSEC(input *route53.GetDNSSECInput) (output *route53.GetDNSSECOutput, err error) {
+	err = c.limiter.Do(context.Background(), "GetDNSSEC", zoneScope(input.HostedZoneId), func() error {
+		output, err = c.Route53API.GetDNSSEC(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) CreateKeySigningKey(input *route53.CreateKeySigningKeyInput) (output *route53.CreateKeySigningKeyOutput, err error) {
+	err = c.limiter.Do(context.Background(), "CreateKeySigningKey", zoneScope(input.HostedZoneId), func() error {
+		output, err = c.Route53API.CreateKeySigningKey(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) ActivateKeySigningKey(input *route53.ActivateKeySigningKeyInput) (output *route53.ActivateKeySigningKeyOutput, err error) {
+	err = c.limiter.Do(context.Background(), "ActivateKeySigningKey", zoneScope(input.HostedZoneId), func() error {
+		output, err = c.Route53API.ActivateKeySigningKey(input)
+		return err
+	})
+	return
+}
+
+func (c *rateLimitedRoute53) EnableHostedZoneDNSSEC(input *route53.EnableHostedZoneDNSSECInput) (output *route53.EnableHostedZoneDNSSECOutput, err error) {
+	err = c.limiter.Do(context.Background(), "EnableHostedZoneDNSSEC", zoneScope(input.HostedZoneId), func() error {
+		output, err = c.Route53API.EnableHostedZoneDNSSEC(input)
+		return err
+	})
+	return
+}
@@ -0,0 +1,260 @@
+//go:build unit
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/go-logr/logr"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestRateLimiter_Ensure(t *testing.T) {
+	throttlingErr := awserr.New("Throttling", "Rate exceeded", nil)
+
+	testCases := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "retries throttled requests until they succeed",
+			errs:      []error{throttlingErr, throttlingErr, throttlingErr},
+			wantCalls: 4,
+		},
+		{
+			name:      "gives up once the backoff steps are exhausted",
+			errs:      []error{throttlingErr, throttlingErr, throttlingErr, throttlingErr, throttlingErr},
+			wantCalls: 5,
+			wantErr:   true,
+		},
+		{
+			name:      "does not retry other errors",
+			errs:      []error{awserr.New(route53.ErrCodeInvalidInput, "invalid input", nil)},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			requestsPerSecond := 20.0
+			limiter := NewRateLimiter(requestsPerSecond)
+			limiter.burst = 1
+			limiter.backoff.Duration = time.Millisecond
+
+			mockClient := &mockThrottledRoute53API{errs: testCase.errs}
+			provider := &Route53DNSProvider{
+				client: &InstrumentedRoute53{&rateLimitedRoute53{Route53API: mockClient, limiter: limiter}},
+				logger: logr.Discard(),
+			}
+			record := &v1alpha1.DNSRecord{
+				Spec: v1alpha1.DNSRecordSpec{
+					Endpoints: []*v1alpha1.Endpoint{
+						{DNSName: "test.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60},
+					},
+				},
+			}
+			zone := &v1alpha1.ManagedZone{
+				Status: v1alpha1.ManagedZoneStatus{ID: "/hostedzone/test-zone"},
+			}
+
+			err := provider.Ensure(record, zone)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Ensure() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if len(mockClient.calls) != testCase.wantCalls {
+				t.Fatalf("expected %d calls, got %d", testCase.wantCalls, len(mockClient.calls))
+			}
+			// allow for the timer resolution, the limiter reserves a token every 1/requestsPerSecond
+			minInterval := time.Duration(float64(time.Second)/requestsPerSecond) - 5*time.Millisecond
+			for i := 1; i < len(mockClient.calls); i++ {
+				if interval := mockClient.calls[i].Sub(mockClient.calls[i-1]); interval < minInterval {
+					t.Errorf("call %d sent %v after the previous one, exceeding %v requests per second", i, interval, requestsPerSecond)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_zones(t *testing.T) {
+	limiter := NewRateLimiter(DefaultRoute53RequestsPerSecond)
+
+	if limiter.limiter(zoneScope(aws.String("/hostedzone/zone-a"))) != limiter.limiter(zoneScope(aws.String("zone-a"))) {
+		t.Errorf("expected requests to the same zone to share a limiter")
+	}
+	if limiter.limiter("zone-a") == limiter.limiter("zone-b") {
+		t.Errorf("expected requests to different zones to use different limiters")
+	}
+
+	var nilLimiter *RateLimiter
+	calls := 0
+	err := nilLimiter.Do(context.TODO(), "ChangeResourceRecordSets", "zone-a", func() error {
+		calls++
+		return errors.New("failed")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a nil limiter to call f once, got %d calls and error %v", calls, err)
+	}
+}
+
+type mockThrottledRoute53API struct {
+	unimplementedRoute53
+	errs  []error
+	calls []time.Time
+}
+
+func (m *mockThrottledRoute53API) ChangeResourceRecordSets(_ *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	m.calls = append(m.calls, time.Now())
+	if len(m.calls) <= len(m.errs) {
+		return nil, m.errs[len(m.calls)-1]
+	}
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+// mockConcurrentRoute53API answers each request after the latency, listing no record sets, and records the time the
+// requests are sent to each hosted zone, and the number of changes of each zone. It is safe for concurrent use.
+type mockConcurrentRoute53API struct {
+	unimplementedRoute53
+	latency time.Duration
+
+	mutex   sync.Mutex
+	calls   map[string][]time.Time
+	changes map[string]int
+}
+
+func (m *mockConcurrentRoute53API) call(zoneID *string, change bool) {
+	m.mutex.Lock()
+	if m.calls == nil {
+		m.calls = map[string][]time.Time{}
+		m.changes = map[string]int{}
+	}
+	m.calls[aws.StringValue(zoneID)] = append(m.calls[aws.StringValue(zoneID)], time.Now())
+	if change {
+		m.changes[aws.StringValue(zoneID)]++
+	}
+	m.mutex.Unlock()
+	time.Sleep(m.latency)
+}
+
+func (m *mockConcurrentRoute53API) ChangeResourceRecordSets(input *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	m.call(input.HostedZoneId, true)
+	return &route53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func (m *mockConcurrentRoute53API) ListResourceRecordSets(input *route53.ListResourceRecordSetsInput) (*route53.ListResourceRecordSetsOutput, error) {
+	m.call(input.HostedZoneId, false)
+	return &route53.ListResourceRecordSetsOutput{}, nil
+}
+
+// ensureConcurrently ensures the records of each zone with the given number of workers, as the DNSRecord controller
+// reconciles them with as many concurrent reconciles, each reconcile creating its provider from the shared rate
+// limiter and record set cache. It returns how long the records took to be ensured.
+func ensureConcurrently(t testing.TB, mockClient *mockConcurrentRoute53API, limiter *RateLimiter, zones, recordsPerZone, workers int) time.Duration {
+	cache := NewRecordSetCache(time.Minute)
+	type item struct {
+		record *v1alpha1.DNSRecord
+		zone   *v1alpha1.ManagedZone
+	}
+	items := make(chan item, zones*recordsPerZone)
+	for i := 0; i < recordsPerZone; i++ {
+		for z := 0; z < zones; z++ {
+			name := fmt.Sprintf("record-%d.zone-%d.example.com", i, z)
+			items <- item{
+				record: &v1alpha1.DNSRecord{
+					Spec: v1alpha1.DNSRecordSpec{
+						Endpoints: []*v1alpha1.Endpoint{
+							{DNSName: name, Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60},
+						},
+					},
+				},
+				zone: &v1alpha1.ManagedZone{
+					Status: v1alpha1.ManagedZoneStatus{ID: fmt.Sprintf("/hostedzone/zone-%d", z)},
+				},
+			}
+		}
+	}
+	close(items)
+
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				provider := &Route53DNSProvider{
+					client:         &InstrumentedRoute53{&rateLimitedRoute53{Route53API: mockClient, limiter: limiter}},
+					logger:         logr.Discard(),
+					ownerID:        "test-owner",
+					recordSetCache: cache,
+				}
+				if err := provider.Ensure(item.record, item.zone); err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+func TestRateLimiter_concurrentEnsure(t *testing.T) {
+	requestsPerSecond := 50.0
+	newLimiter := func() *RateLimiter {
+		limiter := NewRateLimiter(requestsPerSecond)
+		limiter.burst = 1
+		return limiter
+	}
+	zones, recordsPerZone := 4, 4
+
+	sequential := ensureConcurrently(t, &mockConcurrentRoute53API{latency: 20 * time.Millisecond}, newLimiter(), zones, recordsPerZone, 1)
+	mockClient := &mockConcurrentRoute53API{latency: 20 * time.Millisecond}
+	concurrent := ensureConcurrently(t, mockClient, newLimiter(), zones, recordsPerZone, zones)
+
+	// the records of different zones are published in parallel
+	if concurrent > sequential/2 {
+		t.Errorf("expected %d workers to ensure the records at least twice as fast as 1, took %v and %v", zones, concurrent, sequential)
+	}
+	if len(mockClient.changes) != zones {
+		t.Errorf("expected changes to %d zones, got %v", zones, mockClient.changes)
+	}
+	for zoneID, changes := range mockClient.changes {
+		if changes != recordsPerZone {
+			t.Errorf("expected %d changes to %s, got %d", recordsPerZone, zoneID, changes)
+		}
+	}
+	// while the requests to each zone, its listings and changes, stay within its rate. Allow for the timer resolution,
+	// the limiter reserves a token every 1/requestsPerSecond.
+	minInterval := time.Duration(float64(time.Second)/requestsPerSecond) - 5*time.Millisecond
+	for zoneID, calls := range mockClient.calls {
+		sort.Slice(calls, func(i, j int) bool { return calls[i].Before(calls[j]) })
+		for i := 1; i < len(calls); i++ {
+			if interval := calls[i].Sub(calls[i-1]); interval < minInterval {
+				t.Errorf("request %d to %s sent %v after the previous one, exceeding %v requests per second", i, zoneID, interval, requestsPerSecond)
+			}
+		}
+	}
+}
+
+func BenchmarkRateLimiter_concurrentEnsure(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			mockClient := &mockConcurrentRoute53API{latency: 5 * time.Millisecond}
+			// the records are spread over as many zones as workers, each zone allowing 100 requests per second
+			elapsed := ensureConcurrently(b, mockClient, NewRateLimiter(100), workers, b.N, workers)
+			b.ReportMetric(float64(b.N*workers)/elapsed.Seconds(), "records/s")
+		})
+	}
+}
@@ -0,0 +1,423 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/oauth2/clientcredentials"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+type action string
+
+const (
+	upsertAction action = "UPSERT"
+	deleteAction action = "DELETE"
+
+	dnsAPIVersion            = "2018-05-01"
+	trafficManagerAPIVersion = "2022-04-01"
+
+	defaultManagementEndpoint = "https://management.azure.com"
+	defaultAuthorityHost      = "https://login.microsoftonline.com"
+
+	globalLocation              = "global"
+	trafficManagerDomain        = "trafficmanager.net"
+	trafficManagerEndpointType  = "Microsoft.Network/trafficManagerProfiles/externalEndpoints"
+	trafficManagerMinimumWeight = 1
+	trafficManagerMaximumWeight = 1000
+)
+
+// AzureDNSProvider manages zones and record sets in Azure DNS. Weighted record sets are published as a CNAME to an
+// Azure Traffic Manager profile using the weighted routing method.
+type AzureDNSProvider struct {
+	client         azureClient
+	logger         logr.Logger
+	subscriptionID string
+	resourceGroup  string
+	// The context parameter to be passed for Azure API calls.
+	ctx context.Context
+}
+
+var _ dns.Provider = &AzureDNSProvider{}
+var _ dns.ConnectivityChecker = &AzureDNSProvider{}
+
+func NewProviderFromSecret(ctx context.Context, s *v1.Secret) (*AzureDNSProvider, error) {
+	for _, key := range []string{"AZURE_TENANT_ID", "AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_SUBSCRIPTION_ID", "AZURE_RESOURCE_GROUP"} {
+		if string(s.Data[key]) == "" {
+			return nil, fmt.Errorf("Azure Provider credentials is empty, %s is required", key)
+		}
+	}
+
+	config := clientcredentials.Config{
+		ClientID:     string(s.Data["AZURE_CLIENT_ID"]),
+		ClientSecret: string(s.Data["AZURE_CLIENT_SECRET"]),
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", defaultAuthorityHost, string(s.Data["AZURE_TENANT_ID"])),
+		Scopes:       []string{defaultManagementEndpoint + "/.default"},
+	}
+
+	subscriptionID := string(s.Data["AZURE_SUBSCRIPTION_ID"])
+	resourceGroup := string(s.Data["AZURE_RESOURCE_GROUP"])
+
+	return &AzureDNSProvider{
+		client: &restClient{
+			httpClient: config.Client(ctx),
+			endpoint:   defaultManagementEndpoint,
+		},
+		logger:         log.Log.WithName("azure-dns").WithValues("subscription", subscriptionID, "resourceGroup", resourceGroup),
+		subscriptionID: subscriptionID,
+		resourceGroup:  resourceGroup,
+		ctx:            ctx,
+	}, nil
+}
+
+// ManagedZones
+
+func (a *AzureDNSProvider) EnsureManagedZone(managedZone *v1alpha1.ManagedZone) (dns.ManagedZoneOutput, error) {
+	var zoneID string
+
+	if managedZone.Spec.ID != "" {
+		zoneID = managedZone.Spec.ID
+	} else {
+		zoneID = managedZone.Status.ID
+	}
+
+	z := &zone{}
+	if zoneID != "" {
+		//Get existing managed zone
+		if err := a.client.Get(a.ctx, a.zonePath(zoneID), dnsAPIVersion, z); err != nil {
+			return dns.ManagedZoneOutput{}, err
+		}
+		return toManagedZoneOutput(zoneID, z), nil
+	}
+
+	//Create new managed zone, the zone resource is named after its domain
+	desired := &zone{
+		Location: globalLocation,
+		Tags:     map[string]string{"description": managedZone.Spec.Description},
+	}
+	if err := a.client.Put(a.ctx, a.zonePath(managedZone.Spec.DomainName), dnsAPIVersion, desired, z); err != nil {
+		return dns.ManagedZoneOutput{}, err
+	}
+	return toManagedZoneOutput(managedZone.Spec.DomainName, z), nil
+}
+
+func (a *AzureDNSProvider) DeleteManagedZone(managedZone *v1alpha1.ManagedZone) error {
+	return a.client.Delete(a.ctx, a.zonePath(managedZone.Status.ID), dnsAPIVersion)
+}
+
+func toManagedZoneOutput(zoneID string, z *zone) dns.ManagedZoneOutput {
+	var nameservers []*string
+	for i := range z.Properties.NameServers {
+		nameservers = append(nameservers, &z.Properties.NameServers[i])
+	}
+	return dns.ManagedZoneOutput{
+		ID:          zoneID,
+		NameServers: nameservers,
+		RecordCount: z.Properties.NumberOfRecordSets,
+	}
+}
+
+//DNSRecords
+
+func (a *AzureDNSProvider) Ensure(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
+	return a.updateRecord(record, managedZone, upsertAction)
+}
+
+func (a *AzureDNSProvider) Delete(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
+	return a.updateRecord(record, managedZone, deleteAction)
+}
+
+func (a *AzureDNSProvider) HealthCheckReconciler() dns.HealthCheckReconciler {
+	// This can be ignored and likely removed as part of the provider-agnostic health check work
+	return &dns.FakeHealthCheckReconciler{}
+}
+
+func (a *AzureDNSProvider) ProviderSpecific() dns.ProviderSpecificLabels {
+	return dns.ProviderSpecificLabels{}
+}
+
+// CheckConnectivity implements dns.ConnectivityChecker by listing the zones of the resource group
+func (a *AzureDNSProvider) CheckConnectivity() error {
+	if err := a.client.Get(a.ctx, a.resourceGroupPath()+"/dnsZones", dnsAPIVersion, nil); err != nil {
+		return fmt.Errorf("%w: failed to list the zones of resource group %s: %v", dns.ErrProviderUnreachable, a.resourceGroup, err)
+	}
+	return nil
+}
+
+func (a *AzureDNSProvider) updateRecord(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone, action action) error {
+	zoneID := managedZone.Status.ID
+	desired := groupEndpoints(record.Spec.Endpoints)
+
+	if action == deleteAction {
+		for _, key := range sortedKeys(desired) {
+			if err := a.deleteRecordSet(zoneID, managedZone.Spec.DomainName, desired[key]); err != nil {
+				return err
+			}
+		}
+		a.logger.Info("Deleted DNS record", "record", record.Spec, "zone", zoneID)
+		return nil
+	}
+
+	for _, key := range sortedKeys(desired) {
+		if err := a.ensureRecordSet(zoneID, managedZone.Spec.DomainName, desired[key]); err != nil {
+			return fmt.Errorf("failed to update record in azure dns zone %s: %v", zoneID, err)
+		}
+	}
+
+	// Delete any previously published record sets that are no longer present in record.Spec.Endpoints
+	published := groupEndpoints(record.Status.Endpoints)
+	for _, key := range sortedKeys(published) {
+		if _, found := desired[key]; found {
+			continue
+		}
+		if err := a.deleteRecordSet(zoneID, managedZone.Spec.DomainName, published[key]); err != nil {
+			return err
+		}
+	}
+
+	a.logger.Info("Upserted DNS record", "record", record.Spec, "zone", zoneID)
+	return nil
+}
+
+func (a *AzureDNSProvider) ensureRecordSet(zoneID, domain string, rs *endpointRecordSet) error {
+	if rs.isWeighted() {
+		profileName := a.trafficManagerProfileName(zoneID, rs.dnsName)
+		profile := toTrafficManagerProfile(profileName, rs.endpoints)
+		if err := a.client.Put(a.ctx, a.trafficManagerProfilePath(profileName), trafficManagerAPIVersion, profile, nil); err != nil {
+			return fmt.Errorf("couldn't update traffic manager profile %s for %s: %v", profileName, rs.dnsName, err)
+		}
+
+		cname := &recordSet{Properties: recordSetProperties{
+			TTL:         rs.ttl(),
+			CNAMERecord: &cnameRecord{CNAME: fmt.Sprintf("%s.%s", profileName, trafficManagerDomain)},
+		}}
+		return a.client.Put(a.ctx, a.recordSetPath(zoneID, v1alpha1.CNAMERecordType, relativeRecordSetName(rs.dnsName, domain)), dnsAPIVersion, cname, nil)
+	}
+
+	if len(rs.endpoints) > 1 {
+		return fmt.Errorf("record set %s %s has %d endpoints with a routing policy that is not supported by the Azure provider", rs.dnsName, rs.recordType, len(rs.endpoints))
+	}
+
+	desired, err := toRecordSet(rs)
+	if err != nil {
+		return err
+	}
+	return a.client.Put(a.ctx, a.recordSetPath(zoneID, v1alpha1.DNSRecordType(rs.recordType), relativeRecordSetName(rs.dnsName, domain)), dnsAPIVersion, desired, nil)
+}
+
+func (a *AzureDNSProvider) deleteRecordSet(zoneID, domain string, rs *endpointRecordSet) error {
+	recordType := v1alpha1.DNSRecordType(rs.recordType)
+	if rs.isWeighted() {
+		recordType = v1alpha1.CNAMERecordType
+	}
+	err := a.client.Delete(a.ctx, a.recordSetPath(zoneID, recordType, relativeRecordSetName(rs.dnsName, domain)), dnsAPIVersion)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("couldn't delete record set %s %s in zone %s: %v", rs.dnsName, recordType, zoneID, err)
+	}
+	if !rs.isWeighted() {
+		return nil
+	}
+	profileName := a.trafficManagerProfileName(zoneID, rs.dnsName)
+	err = a.client.Delete(a.ctx, a.trafficManagerProfilePath(profileName), trafficManagerAPIVersion)
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("couldn't delete traffic manager profile %s for %s: %v", profileName, rs.dnsName, err)
+	}
+	return nil
+}
+
+func (a *AzureDNSProvider) resourceGroupPath() string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Network", a.subscriptionID, a.resourceGroup)
+}
+
+func (a *AzureDNSProvider) zonePath(zoneID string) string {
+	return fmt.Sprintf("%s/dnsZones/%s", a.resourceGroupPath(), zoneID)
+}
+
+func (a *AzureDNSProvider) recordSetPath(zoneID string, recordType v1alpha1.DNSRecordType, relativeName string) string {
+	return fmt.Sprintf("%s/%s/%s", a.zonePath(zoneID), recordType, url.PathEscape(relativeName))
+}
+
+func (a *AzureDNSProvider) trafficManagerProfilePath(profileName string) string {
+	return fmt.Sprintf("%s/trafficmanagerprofiles/%s", a.resourceGroupPath(), profileName)
+}
+
+// trafficManagerProfileName returns the name of the profile used for a weighted record set. The name is also used as
+// the profile DNS relative name, which must be unique across all of trafficmanager.net.
+func (a *AzureDNSProvider) trafficManagerProfileName(zoneID, dnsName string) string {
+	hash := sha256.Sum224([]byte(fmt.Sprintf("%s/%s/%s/%s", a.subscriptionID, a.resourceGroup, zoneID, dnsName)))
+	return fmt.Sprintf("mgc-%x", hash[:10])
+}
+
+// relativeRecordSetName returns the name of the record set relative to the zone domain
+func relativeRecordSetName(dnsName, domain string) string {
+	dnsName = strings.TrimSuffix(dnsName, ".")
+	if dnsName == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(dnsName, "."+domain)
+}
+
+// endpointRecordSet is the set of endpoints sharing the same name and type
+type endpointRecordSet struct {
+	dnsName    string
+	recordType string
+	endpoints  []*v1alpha1.Endpoint
+}
+
+func (rs *endpointRecordSet) isWeighted() bool {
+	for _, ep := range rs.endpoints {
+		if _, ok := ep.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *endpointRecordSet) ttl() int64 {
+	return int64(rs.endpoints[0].RecordTTL)
+}
+
+func groupEndpoints(endpoints []*v1alpha1.Endpoint) map[string]*endpointRecordSet {
+	recordSets := map[string]*endpointRecordSet{}
+	for _, ep := range endpoints {
+		key := fmt.Sprintf("%s/%s", ep.DNSName, ep.RecordType)
+		rs, ok := recordSets[key]
+		if !ok {
+			rs = &endpointRecordSet{dnsName: ep.DNSName, recordType: ep.RecordType}
+			recordSets[key] = rs
+		}
+		rs.endpoints = append(rs.endpoints, ep)
+	}
+	return recordSets
+}
+
+func sortedKeys(recordSets map[string]*endpointRecordSet) []string {
+	keys := make([]string, 0, len(recordSets))
+	for k := range recordSets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toRecordSet(rs *endpointRecordSet) (*recordSet, error) {
+	ep := rs.endpoints[0]
+	if len(ep.Targets) == 0 {
+		return nil, fmt.Errorf("targets is required")
+	}
+
+	properties := recordSetProperties{TTL: rs.ttl()}
+	switch v1alpha1.DNSRecordType(rs.recordType) {
+	case v1alpha1.ARecordType:
+		for _, target := range ep.Targets {
+			properties.ARecords = append(properties.ARecords, aRecord{IPv4Address: target})
+		}
+	case v1alpha1.AAAARecordType:
+		for _, target := range ep.Targets {
+			properties.AAAARecords = append(properties.AAAARecords, aaaaRecord{IPv6Address: target})
+		}
+	case v1alpha1.CNAMERecordType:
+		properties.CNAMERecord = &cnameRecord{CNAME: ep.Targets[0]}
+	case v1alpha1.TXTRecordType:
+		for _, target := range ep.Targets {
+			properties.TXTRecords = append(properties.TXTRecords, txtRecord{Value: []string{target}})
+		}
+	case v1alpha1.NSRecordType:
+		for _, target := range ep.Targets {
+			properties.NSRecords = append(properties.NSRecords, nsRecord{NSDName: target})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported record type %s", rs.recordType)
+	}
+	return &recordSet{Properties: properties}, nil
+}
+
+func toTrafficManagerProfile(profileName string, endpoints []*v1alpha1.Endpoint) *trafficManagerProfile {
+	profile := &trafficManagerProfile{
+		Location: globalLocation,
+		Properties: trafficManagerProfileProperties{
+			TrafficRoutingMethod: "Weighted",
+			DNSConfig: trafficManagerDNSConfig{
+				RelativeName: profileName,
+				TTL:          int64(endpoints[0].RecordTTL),
+			},
+			// health checks are handled by the controller, the monitor is required but every endpoint is always served
+			MonitorConfig: trafficManagerMonitorConfig{
+				Protocol: "TCP",
+				Port:     443,
+			},
+		},
+	}
+
+	for _, ep := range endpoints {
+		weight := int64(trafficManagerMinimumWeight)
+		status := "Enabled"
+		if prop, ok := ep.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok {
+			w, err := strconv.ParseInt(prop.Value, 10, 64)
+			if err != nil {
+				w = 0
+			}
+			weight = w
+		}
+		if weight < trafficManagerMinimumWeight {
+			// a weight of 0 means the endpoint should not receive any traffic
+			weight = trafficManagerMinimumWeight
+			status = "Disabled"
+		}
+		if weight > trafficManagerMaximumWeight {
+			weight = trafficManagerMaximumWeight
+		}
+
+		for _, target := range ep.Targets {
+			profile.Properties.Endpoints = append(profile.Properties.Endpoints, trafficManagerEndpoint{
+				Name: trafficManagerEndpointName(ep.SetIdentifier, target),
+				Type: trafficManagerEndpointType,
+				Properties: trafficManagerEndpointProperties{
+					Target:         target,
+					Weight:         weight,
+					EndpointStatus: status,
+					AlwaysServe:    "Enabled",
+				},
+			})
+		}
+	}
+	sort.Slice(profile.Properties.Endpoints, func(i, j int) bool {
+		return profile.Properties.Endpoints[i].Name < profile.Properties.Endpoints[j].Name
+	})
+	return profile
+}
+
+func trafficManagerEndpointName(setIdentifier, target string) string {
+	name := target
+	if setIdentifier != "" && setIdentifier != target {
+		name = fmt.Sprintf("%s-%s", setIdentifier, target)
+	}
+	return strings.NewReplacer(".", "-", "*", "wildcard").Replace(name)
+}
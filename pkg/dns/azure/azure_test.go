@@ -0,0 +1,332 @@
+//go:build unit
+
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+const (
+	testZonePath    = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/dnsZones/example.com"
+	testProfilePath = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Network/trafficmanagerprofiles/"
+)
+
+// mockAzureClient stores the resources put in a map keyed by path
+type mockAzureClient struct {
+	resources map[string][]byte
+}
+
+var _ azureClient = &mockAzureClient{}
+
+func newMockAzureClient() *mockAzureClient {
+	return &mockAzureClient{resources: map[string][]byte{}}
+}
+
+func (m *mockAzureClient) Get(_ context.Context, path, _ string, out interface{}) error {
+	data, ok := m.resources[path]
+	if !ok {
+		return &responseError{StatusCode: http.StatusNotFound}
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (m *mockAzureClient) Put(_ context.Context, path, _ string, in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	m.resources[path] = data
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (m *mockAzureClient) Delete(_ context.Context, path, _ string) error {
+	if _, ok := m.resources[path]; !ok {
+		return &responseError{StatusCode: http.StatusNotFound}
+	}
+	delete(m.resources, path)
+	return nil
+}
+
+func (m *mockAzureClient) profilePaths() []string {
+	var paths []string
+	for path := range m.resources {
+		if strings.HasPrefix(path, testProfilePath) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func testProvider(client azureClient) *AzureDNSProvider {
+	return &AzureDNSProvider{
+		client:         client,
+		logger:         logr.Discard(),
+		subscriptionID: "sub",
+		resourceGroup:  "rg",
+		ctx:            context.TODO(),
+	}
+}
+
+func testManagedZone() *v1alpha1.ManagedZone {
+	return &v1alpha1.ManagedZone{
+		Spec:   v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{ID: "example.com"},
+	}
+}
+
+func weightedEndpoint(dnsName, target, weight string) *v1alpha1.Endpoint {
+	return &v1alpha1.Endpoint{
+		DNSName:       dnsName,
+		Targets:       []string{target},
+		RecordType:    "CNAME",
+		SetIdentifier: target,
+		RecordTTL:     dns.DefaultTTL,
+		ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+			{Name: dns.ProviderSpecificWeight, Value: weight},
+		},
+	}
+}
+
+func TestAzureDNSProvider_Ensure(t *testing.T) {
+	testCases := []struct {
+		name      string
+		endpoints []*v1alpha1.Endpoint
+		published []*v1alpha1.Endpoint
+		assert    func(*mockAzureClient) error
+		wantErr   bool
+	}{
+		{
+			name: "creates A, CNAME and TXT record sets",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "a.example.com", Targets: []string{"1.1.1.1", "2.2.2.2"}, RecordType: "A", RecordTTL: 60},
+				{DNSName: "www.example.com", Targets: []string{"a.example.com"}, RecordType: "CNAME", RecordTTL: 300},
+				{DNSName: "example.com", Targets: []string{"owner=mgc"}, RecordType: "TXT", RecordTTL: 300},
+			},
+			assert: func(m *mockAzureClient) error {
+				a := &recordSet{}
+				if err := m.Get(context.TODO(), testZonePath+"/A/a", "", a); err != nil {
+					return err
+				}
+				if len(a.Properties.ARecords) != 2 || a.Properties.ARecords[0].IPv4Address != "1.1.1.1" || a.Properties.TTL != 60 {
+					return fmt.Errorf("unexpected A record set %+v", a.Properties)
+				}
+				cname := &recordSet{}
+				if err := m.Get(context.TODO(), testZonePath+"/CNAME/www", "", cname); err != nil {
+					return err
+				}
+				if cname.Properties.CNAMERecord == nil || cname.Properties.CNAMERecord.CNAME != "a.example.com" {
+					return fmt.Errorf("unexpected CNAME record set %+v", cname.Properties)
+				}
+				txt := &recordSet{}
+				if err := m.Get(context.TODO(), testZonePath+"/TXT/@", "", txt); err != nil {
+					return err
+				}
+				if len(txt.Properties.TXTRecords) != 1 || txt.Properties.TXTRecords[0].Value[0] != "owner=mgc" {
+					return fmt.Errorf("unexpected TXT record set %+v", txt.Properties)
+				}
+				return nil
+			},
+		},
+		{
+			name: "creates traffic manager profile for weighted record set",
+			endpoints: []*v1alpha1.Endpoint{
+				weightedEndpoint("default.lb.example.com", "cluster1.lb.example.com", "120"),
+				weightedEndpoint("default.lb.example.com", "mylb.example.net", "0"),
+			},
+			assert: func(m *mockAzureClient) error {
+				profiles := m.profilePaths()
+				if len(profiles) != 1 {
+					return fmt.Errorf("expected 1 traffic manager profile, got %v", profiles)
+				}
+				profile := &trafficManagerProfile{}
+				if err := m.Get(context.TODO(), profiles[0], "", profile); err != nil {
+					return err
+				}
+				if profile.Properties.TrafficRoutingMethod != "Weighted" {
+					return fmt.Errorf("expected weighted routing method, got %s", profile.Properties.TrafficRoutingMethod)
+				}
+				if len(profile.Properties.Endpoints) != 2 {
+					return fmt.Errorf("expected 2 traffic manager endpoints, got %+v", profile.Properties.Endpoints)
+				}
+				for _, ep := range profile.Properties.Endpoints {
+					switch ep.Properties.Target {
+					case "cluster1.lb.example.com":
+						if ep.Properties.Weight != 120 || ep.Properties.EndpointStatus != "Enabled" {
+							return fmt.Errorf("unexpected endpoint %+v", ep.Properties)
+						}
+					case "mylb.example.net":
+						if ep.Properties.Weight != 1 || ep.Properties.EndpointStatus != "Disabled" {
+							return fmt.Errorf("expected endpoint with weight 0 to be disabled, got %+v", ep.Properties)
+						}
+					default:
+						return fmt.Errorf("unexpected endpoint target %s", ep.Properties.Target)
+					}
+				}
+
+				cname := &recordSet{}
+				if err := m.Get(context.TODO(), testZonePath+"/CNAME/default.lb", "", cname); err != nil {
+					return err
+				}
+				want := fmt.Sprintf("%s.trafficmanager.net", profile.Properties.DNSConfig.RelativeName)
+				if cname.Properties.CNAMERecord == nil || cname.Properties.CNAMERecord.CNAME != want {
+					return fmt.Errorf("expected CNAME to %s, got %+v", want, cname.Properties)
+				}
+				return nil
+			},
+		},
+		{
+			name: "removes record sets no longer present",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "www.example.com", Targets: []string{"a.example.com"}, RecordType: "CNAME", RecordTTL: 300},
+			},
+			published: []*v1alpha1.Endpoint{
+				{DNSName: "www.example.com", Targets: []string{"a.example.com"}, RecordType: "CNAME", RecordTTL: 300},
+				weightedEndpoint("default.lb.example.com", "cluster1.lb.example.com", "120"),
+			},
+			assert: func(m *mockAzureClient) error {
+				if len(m.profilePaths()) != 0 {
+					return fmt.Errorf("expected traffic manager profile to be deleted, got %v", m.profilePaths())
+				}
+				if _, ok := m.resources[testZonePath+"/CNAME/default.lb"]; ok {
+					return fmt.Errorf("expected weighted CNAME record set to be deleted")
+				}
+				if _, ok := m.resources[testZonePath+"/CNAME/www"]; !ok {
+					return fmt.Errorf("expected CNAME record set to be kept")
+				}
+				return nil
+			},
+		},
+		{
+			name: "unsupported routing policy",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "lb.example.com", Targets: []string{"ie.lb.example.com"}, RecordType: "CNAME", SetIdentifier: "IE", RecordTTL: 300,
+					ProviderSpecific: []v1alpha1.ProviderSpecificProperty{{Name: dns.ProviderSpecificGeoCode, Value: "IE"}}},
+				{DNSName: "lb.example.com", Targets: []string{"us.lb.example.com"}, RecordType: "CNAME", SetIdentifier: "US", RecordTTL: 300,
+					ProviderSpecific: []v1alpha1.ProviderSpecificProperty{{Name: dns.ProviderSpecificGeoCode, Value: "US"}}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := newMockAzureClient()
+			provider := testProvider(client)
+			record := &v1alpha1.DNSRecord{}
+
+			// publish the previous endpoints first
+			if testCase.published != nil {
+				record.Spec.Endpoints = testCase.published
+				if err := provider.Ensure(record, testManagedZone()); err != nil {
+					t.Fatalf("unexpected error publishing endpoints %v", err)
+				}
+				record.Status.Endpoints = testCase.published
+			}
+
+			record.Spec.Endpoints = testCase.endpoints
+			err := provider.Ensure(record, testManagedZone())
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Ensure() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if testCase.assert != nil {
+				if err := testCase.assert(client); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
+
+func TestAzureDNSProvider_Delete(t *testing.T) {
+	client := newMockAzureClient()
+	provider := testProvider(client)
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "www.example.com", Targets: []string{"default.lb.example.com"}, RecordType: "CNAME", RecordTTL: 300},
+				weightedEndpoint("default.lb.example.com", "cluster1.lb.example.com", "120"),
+			},
+		},
+	}
+	if err := provider.Ensure(record, testManagedZone()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := provider.Delete(record, testManagedZone()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(client.resources) != 0 {
+		t.Errorf("expected all resources to be deleted, got %v", client.resources)
+	}
+}
+
+func TestAzureDNSProvider_EnsureManagedZone(t *testing.T) {
+	client := newMockAzureClient()
+	provider := testProvider(client)
+	managedZone := &v1alpha1.ManagedZone{
+		Spec: v1alpha1.ManagedZoneSpec{DomainName: "example.com", Description: "test zone"},
+	}
+
+	output, err := provider.EnsureManagedZone(managedZone)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	z := &zone{}
+	if err := client.Get(context.TODO(), testZonePath, "", z); err != nil {
+		t.Fatalf("expected zone to be created %v", err)
+	}
+	if z.Location != "global" || z.Tags["description"] != "test zone" {
+		t.Errorf("unexpected zone %+v", z)
+	}
+
+	if output.ID != "example.com" {
+		t.Errorf("expected zone id example.com, got %s", output.ID)
+	}
+
+	// the name servers are assigned by azure
+	z.Properties.NameServers = []string{"ns1-01.azure-dns.com."}
+	z.Properties.NumberOfRecordSets = 2
+	if err := client.Put(context.TODO(), testZonePath, "", z, nil); err != nil {
+		t.Fatal(err)
+	}
+	managedZone.Status.ID = "example.com"
+
+	output, err = provider.EnsureManagedZone(managedZone)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if output.ID != "example.com" || output.RecordCount != 2 || len(output.NameServers) != 1 || *output.NameServers[0] != "ns1-01.azure-dns.com." {
+		t.Errorf("unexpected managed zone output %+v", output)
+	}
+}
+
+func TestRelativeRecordSetName(t *testing.T) {
+	testCases := []struct {
+		dnsName string
+		want    string
+	}{
+		{dnsName: "example.com", want: "@"},
+		{dnsName: "www.example.com", want: "www"},
+		{dnsName: "*.example.com", want: "*"},
+		{dnsName: "default.lb-1ab1.www.example.com.", want: "default.lb-1ab1.www"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.dnsName, func(t *testing.T) {
+			if got := relativeRecordSetName(testCase.dnsName, "example.com"); got != testCase.want {
+				t.Errorf("relativeRecordSetName() got = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// azureClient is the subset of the Azure Resource Manager REST API used by the provider
+type azureClient interface {
+	Get(ctx context.Context, path, apiVersion string, out interface{}) error
+	Put(ctx context.Context, path, apiVersion string, in, out interface{}) error
+	Delete(ctx context.Context, path, apiVersion string) error
+}
+
+// responseError is returned when the Azure Resource Manager API responds with an unexpected status code
+type responseError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprintf("azure request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+func isNotFound(err error) bool {
+	var respErr *responseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+type restClient struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+var _ azureClient = &restClient{}
+
+func (c *restClient) Get(ctx context.Context, path, apiVersion string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, apiVersion, nil, out)
+}
+
+func (c *restClient) Put(ctx context.Context, path, apiVersion string, in, out interface{}) error {
+	return c.do(ctx, http.MethodPut, path, apiVersion, in, out)
+}
+
+func (c *restClient) Delete(ctx context.Context, path, apiVersion string) error {
+	return c.do(ctx, http.MethodDelete, path, apiVersion, nil, nil)
+}
+
+func (c *restClient) do(ctx context.Context, method, path, apiVersion string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s%s?api-version=%s", c.endpoint, path, apiVersion), body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &responseError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
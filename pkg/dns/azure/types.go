@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+// Azure Resource Manager representations of the DNS and Traffic Manager resources used by the provider.
+// See https://learn.microsoft.com/en-us/rest/api/dns/ and https://learn.microsoft.com/en-us/rest/api/trafficmanager/
+
+type zone struct {
+	Location   string            `json:"location"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Properties zoneProperties    `json:"properties"`
+}
+
+type zoneProperties struct {
+	NumberOfRecordSets int64    `json:"numberOfRecordSets,omitempty"`
+	NameServers        []string `json:"nameServers,omitempty"`
+}
+
+type recordSet struct {
+	Properties recordSetProperties `json:"properties"`
+}
+
+type recordSetProperties struct {
+	TTL         int64        `json:"TTL"`
+	ARecords    []aRecord    `json:"ARecords,omitempty"`
+	AAAARecords []aaaaRecord `json:"AAAARecords,omitempty"`
+	CNAMERecord *cnameRecord `json:"CNAMERecord,omitempty"`
+	TXTRecords  []txtRecord  `json:"TXTRecords,omitempty"`
+	NSRecords   []nsRecord   `json:"NSRecords,omitempty"`
+}
+
+type aRecord struct {
+	IPv4Address string `json:"ipv4Address"`
+}
+
+type aaaaRecord struct {
+	IPv6Address string `json:"ipv6Address"`
+}
+
+type cnameRecord struct {
+	CNAME string `json:"cname"`
+}
+
+type txtRecord struct {
+	Value []string `json:"value"`
+}
+
+type nsRecord struct {
+	NSDName string `json:"nsdname"`
+}
+
+type trafficManagerProfile struct {
+	Location   string                          `json:"location"`
+	Properties trafficManagerProfileProperties `json:"properties"`
+}
+
+type trafficManagerProfileProperties struct {
+	TrafficRoutingMethod string                      `json:"trafficRoutingMethod"`
+	DNSConfig            trafficManagerDNSConfig     `json:"dnsConfig"`
+	MonitorConfig        trafficManagerMonitorConfig `json:"monitorConfig"`
+	Endpoints            []trafficManagerEndpoint    `json:"endpoints"`
+}
+
+type trafficManagerDNSConfig struct {
+	RelativeName string `json:"relativeName"`
+	TTL          int64  `json:"ttl"`
+}
+
+type trafficManagerMonitorConfig struct {
+	Protocol string `json:"protocol"`
+	Port     int64  `json:"port"`
+}
+
+type trafficManagerEndpoint struct {
+	Name       string                           `json:"name"`
+	Type       string                           `json:"type"`
+	Properties trafficManagerEndpointProperties `json:"properties"`
+}
+
+type trafficManagerEndpointProperties struct {
+	Target         string `json:"target"`
+	Weight         int64  `json:"weight"`
+	EndpointStatus string `json:"endpointStatus"`
+	AlwaysServe    string `json:"alwaysServe"`
+}
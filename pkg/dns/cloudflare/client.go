@@ -0,0 +1,173 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// cloudflareClient is the subset of the Cloudflare API v4 used by the provider. The results are unwrapped from the
+// response envelope of the API.
+type cloudflareClient interface {
+	Get(ctx context.Context, path string, query url.Values, out interface{}) error
+	Post(ctx context.Context, path string, in, out interface{}) error
+	Put(ctx context.Context, path string, in, out interface{}) error
+	Delete(ctx context.Context, path string) error
+	// Count returns the total number of results of a list request
+	Count(ctx context.Context, path string, query url.Values) (int64, error)
+}
+
+// responseError is returned when the Cloudflare API responds with an unexpected status code or an unsuccessful result
+type responseError struct {
+	StatusCode int
+	Errors     []apiError
+}
+
+func (e *responseError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, apiErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%d: %s", apiErr.Code, apiErr.Message))
+	}
+	return fmt.Sprintf("cloudflare request failed with status %d: %s", e.StatusCode, strings.Join(messages, ", "))
+}
+
+// invalidZoneErrorCode is the error code of requests to a zone that does not exist
+const invalidZoneErrorCode = 7003
+
+func isNotFound(err error) bool {
+	var respErr *responseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+func isZoneNotFound(err error) bool {
+	var respErr *responseError
+	if !errors.As(err, &respErr) {
+		return false
+	}
+	for _, apiErr := range respErr.Errors {
+		if apiErr.Code == invalidZoneErrorCode {
+			return true
+		}
+	}
+	return respErr.StatusCode == http.StatusNotFound
+}
+
+// isForbidden returns true when the API token is not allowed to make the request, or the account is not entitled to
+// the feature requested
+func isForbidden(err error) bool {
+	var respErr *responseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden
+}
+
+type restClient struct {
+	httpClient *http.Client
+	endpoint   string
+	apiToken   string
+}
+
+var _ cloudflareClient = &restClient{}
+
+func (c *restClient) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	_, err := c.do(ctx, http.MethodGet, path, query, nil, out)
+	return err
+}
+
+func (c *restClient) Post(ctx context.Context, path string, in, out interface{}) error {
+	_, err := c.do(ctx, http.MethodPost, path, nil, in, out)
+	return err
+}
+
+func (c *restClient) Put(ctx context.Context, path string, in, out interface{}) error {
+	_, err := c.do(ctx, http.MethodPut, path, nil, in, out)
+	return err
+}
+
+func (c *restClient) Delete(ctx context.Context, path string) error {
+	_, err := c.do(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+func (c *restClient) Count(ctx context.Context, path string, query url.Values) (int64, error) {
+	q := url.Values{"per_page": []string{"1"}}
+	for k, v := range query {
+		q[k] = v
+	}
+	info, err := c.do(ctx, http.MethodGet, path, q, nil, nil)
+	if err != nil || info == nil {
+		return 0, err
+	}
+	return info.TotalCount, nil
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) (*resultInfo, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	reqURL := c.endpoint + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	envelope := &struct {
+		response
+		Result json.RawMessage `json:"result"`
+	}{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, envelope); err != nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return nil, err
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || (len(data) > 0 && !envelope.Success) {
+		return nil, &responseError{StatusCode: resp.StatusCode, Errors: envelope.Errors}
+	}
+	if out == nil || len(envelope.Result) == 0 {
+		return envelope.ResultInfo, nil
+	}
+	return envelope.ResultInfo, json.Unmarshal(envelope.Result, out)
+}
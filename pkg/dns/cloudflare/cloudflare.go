@@ -0,0 +1,567 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+type action string
+
+const (
+	upsertAction action = "UPSERT"
+	deleteAction action = "DELETE"
+
+	defaultEndpoint = "https://api.cloudflare.com/client/v4"
+
+	// automaticTTL is the TTL Cloudflare resolves to its default
+	automaticTTL = 1
+	// listPageSize is the size of the pages of the records listed by name, large enough for any record set
+	listPageSize = "100"
+)
+
+// CloudflareDNSProvider manages zones and DNS records in Cloudflare. Weighted record sets are published as a Cloudflare
+// load balancer to a pool of the weighted targets, or as round-robin records when load balancing is not available.
+type CloudflareDNSProvider struct {
+	client cloudflareClient
+	logger logr.Logger
+	// accountID is the account zones and load balancer pools are created in, load balancing is not used without one
+	accountID string
+	// The context parameter to be passed for Cloudflare API calls.
+	ctx context.Context
+}
+
+var _ dns.Provider = &CloudflareDNSProvider{}
+var _ dns.ConnectivityChecker = &CloudflareDNSProvider{}
+
+func NewProviderFromSecret(ctx context.Context, s *v1.Secret) (*CloudflareDNSProvider, error) {
+	apiToken := string(s.Data["CLOUDFLARE_API_TOKEN"])
+	if apiToken == "" {
+		return nil, fmt.Errorf("Cloudflare Provider credentials is empty, CLOUDFLARE_API_TOKEN is required")
+	}
+	accountID := string(s.Data["CLOUDFLARE_ACCOUNT_ID"])
+
+	return &CloudflareDNSProvider{
+		client: &restClient{
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+			endpoint:   defaultEndpoint,
+			apiToken:   apiToken,
+		},
+		logger:    log.Log.WithName("cloudflare-dns").WithValues("account", accountID),
+		accountID: accountID,
+		ctx:       ctx,
+	}, nil
+}
+
+// ManagedZones
+
+func (c *CloudflareDNSProvider) EnsureManagedZone(managedZone *v1alpha1.ManagedZone) (dns.ManagedZoneOutput, error) {
+	var zoneID string
+
+	if managedZone.Spec.ID != "" {
+		zoneID = managedZone.Spec.ID
+	} else {
+		zoneID = managedZone.Status.ID
+	}
+
+	z := &zone{}
+	if zoneID != "" {
+		//Get existing managed zone
+		if err := c.client.Get(c.ctx, zonePath(zoneID), nil, z); err != nil {
+			return dns.ManagedZoneOutput{}, err
+		}
+		return c.toManagedZoneOutput(z)
+	}
+
+	//Use the zone of the domain if it already exists in the account, zone names are unique per account
+	query := url.Values{"name": []string{managedZone.Spec.DomainName}}
+	if c.accountID != "" {
+		query.Set("account.id", c.accountID)
+	}
+	var zones []zone
+	if err := c.client.Get(c.ctx, "/zones", query, &zones); err != nil {
+		return dns.ManagedZoneOutput{}, err
+	}
+	if len(zones) > 0 {
+		return c.toManagedZoneOutput(&zones[0])
+	}
+
+	//Create new managed zone
+	if c.accountID == "" {
+		return dns.ManagedZoneOutput{}, fmt.Errorf("CLOUDFLARE_ACCOUNT_ID is required to create the zone %s", managedZone.Spec.DomainName)
+	}
+	desired := &zone{
+		Name:    managedZone.Spec.DomainName,
+		Type:    "full",
+		Account: &account{ID: c.accountID},
+	}
+	if err := c.client.Post(c.ctx, "/zones", desired, z); err != nil {
+		return dns.ManagedZoneOutput{}, err
+	}
+	return c.toManagedZoneOutput(z)
+}
+
+func (c *CloudflareDNSProvider) DeleteManagedZone(managedZone *v1alpha1.ManagedZone) error {
+	return c.client.Delete(c.ctx, zonePath(managedZone.Status.ID))
+}
+
+func (c *CloudflareDNSProvider) toManagedZoneOutput(z *zone) (dns.ManagedZoneOutput, error) {
+	recordCount, err := c.client.Count(c.ctx, recordsPath(z.ID), nil)
+	if err != nil {
+		return dns.ManagedZoneOutput{}, err
+	}
+	var nameservers []*string
+	for i := range z.NameServers {
+		nameservers = append(nameservers, &z.NameServers[i])
+	}
+	return dns.ManagedZoneOutput{
+		ID:          z.ID,
+		NameServers: nameservers,
+		RecordCount: recordCount,
+	}, nil
+}
+
+//DNSRecords
+
+func (c *CloudflareDNSProvider) Ensure(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
+	return c.updateRecord(record, managedZone, upsertAction)
+}
+
+func (c *CloudflareDNSProvider) Delete(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone) error {
+	return c.updateRecord(record, managedZone, deleteAction)
+}
+
+func (c *CloudflareDNSProvider) HealthCheckReconciler() dns.HealthCheckReconciler {
+	// This can be ignored and likely removed as part of the provider-agnostic health check work
+	return &dns.FakeHealthCheckReconciler{}
+}
+
+func (c *CloudflareDNSProvider) ProviderSpecific() dns.ProviderSpecificLabels {
+	return dns.ProviderSpecificLabels{}
+}
+
+// CheckConnectivity implements dns.ConnectivityChecker by counting the zones of the account
+func (c *CloudflareDNSProvider) CheckConnectivity() error {
+	if _, err := c.client.Count(c.ctx, "/zones", nil); err != nil {
+		return fmt.Errorf("%w: failed to list zones: %v", dns.ErrProviderUnreachable, err)
+	}
+	return nil
+}
+
+func (c *CloudflareDNSProvider) updateRecord(record *v1alpha1.DNSRecord, managedZone *v1alpha1.ManagedZone, action action) error {
+	zoneID := managedZone.Status.ID
+	desired := groupEndpoints(record.Spec.Endpoints)
+
+	if action == deleteAction {
+		for _, key := range sortedKeys(desired) {
+			if err := c.deleteRecordSet(zoneID, desired[key]); err != nil {
+				return zoneError(zoneID, err)
+			}
+		}
+		c.logger.Info("Deleted DNS record", "record", record.Spec, "zone", zoneID)
+		return nil
+	}
+
+	for _, key := range sortedKeys(desired) {
+		if err := c.ensureRecordSet(zoneID, desired[key]); err != nil {
+			return zoneError(zoneID, fmt.Errorf("failed to update record in cloudflare zone %s: %w", zoneID, err))
+		}
+	}
+
+	// Delete any previously published record sets that are no longer present in record.Spec.Endpoints
+	published := groupEndpoints(record.Status.Endpoints)
+	for _, key := range sortedKeys(published) {
+		if _, found := desired[key]; found {
+			continue
+		}
+		if err := c.deleteRecordSet(zoneID, published[key]); err != nil {
+			return zoneError(zoneID, err)
+		}
+	}
+
+	c.logger.Info("Upserted DNS record", "record", record.Spec, "zone", zoneID)
+	return nil
+}
+
+// zoneError returns dns.ErrZoneNotFound when the error is caused by the zone no longer existing in Cloudflare
+func zoneError(zoneID string, err error) error {
+	if err != nil && isZoneNotFound(err) {
+		return fmt.Errorf("%w: zone %s: %v", dns.ErrZoneNotFound, zoneID, err)
+	}
+	return err
+}
+
+func (c *CloudflareDNSProvider) ensureRecordSet(zoneID string, rs *endpointRecordSet) error {
+	if rs.isWeighted() {
+		if c.accountID == "" {
+			c.logger.V(1).Info("Publishing weighted record set as round-robin records, load balancing requires CLOUDFLARE_ACCOUNT_ID", "dnsName", rs.dnsName)
+			return c.syncRecords(zoneID, rs.dnsName, rs.recordType, roundRobinTargets(rs), rs.ttl())
+		}
+		err := c.ensureLoadBalancer(zoneID, rs)
+		if isForbidden(err) {
+			c.logger.Info("Publishing weighted record set as round-robin records, load balancing is not available", "dnsName", rs.dnsName, "error", err.Error())
+			return c.syncRecords(zoneID, rs.dnsName, rs.recordType, roundRobinTargets(rs), rs.ttl())
+		}
+		if err != nil {
+			return err
+		}
+		// the records published while load balancing was not available are replaced by the load balancer
+		return c.syncRecords(zoneID, rs.dnsName, rs.recordType, nil, rs.ttl())
+	}
+
+	if len(rs.endpoints) > 1 {
+		return fmt.Errorf("record set %s %s has %d endpoints with a routing policy that is not supported by the Cloudflare provider", rs.dnsName, rs.recordType, len(rs.endpoints))
+	}
+	if len(rs.endpoints[0].Targets) == 0 {
+		return fmt.Errorf("targets is required")
+	}
+	if !supportedRecordType(rs.recordType) {
+		return fmt.Errorf("unsupported record type %s", rs.recordType)
+	}
+	return c.syncRecords(zoneID, rs.dnsName, rs.recordType, rs.endpoints[0].Targets, rs.ttl())
+}
+
+func (c *CloudflareDNSProvider) deleteRecordSet(zoneID string, rs *endpointRecordSet) error {
+	if err := c.syncRecords(zoneID, rs.dnsName, rs.recordType, nil, rs.ttl()); err != nil {
+		return fmt.Errorf("couldn't delete records %s %s in zone %s: %w", rs.dnsName, rs.recordType, zoneID, err)
+	}
+	if !rs.isWeighted() {
+		return nil
+	}
+	if err := c.deleteLoadBalancer(zoneID, rs.dnsName); err != nil {
+		return fmt.Errorf("couldn't delete load balancer %s in zone %s: %w", rs.dnsName, zoneID, err)
+	}
+	return nil
+}
+
+// syncRecords makes the records of the name and type in the zone the records of the targets, a record per target.
+// The records of targets no longer desired are deleted, no targets deletes all the records.
+func (c *CloudflareDNSProvider) syncRecords(zoneID, dnsName, recordType string, targets []string, ttl int64) error {
+	var existing []dnsRecord
+	query := url.Values{"name": []string{dnsName}, "type": []string{recordType}, "per_page": []string{listPageSize}}
+	if err := c.client.Get(c.ctx, recordsPath(zoneID), query, &existing); err != nil {
+		return err
+	}
+
+	remaining := map[string]bool{}
+	for _, target := range targets {
+		remaining[target] = true
+	}
+	for _, r := range existing {
+		if !remaining[r.Content] {
+			if err := c.client.Delete(c.ctx, recordPath(zoneID, r.ID)); err != nil && !isNotFound(err) {
+				return err
+			}
+			continue
+		}
+		delete(remaining, r.Content)
+		if r.TTL != ttl || r.Proxied {
+			r.TTL = ttl
+			r.Proxied = false
+			if err := c.client.Put(c.ctx, recordPath(zoneID, r.ID), r, nil); err != nil {
+				return err
+			}
+		}
+	}
+	for _, target := range targets {
+		if !remaining[target] {
+			continue
+		}
+		delete(remaining, target)
+		desired := dnsRecord{Type: recordType, Name: dnsName, Content: target, TTL: ttl}
+		if err := c.client.Post(c.ctx, recordsPath(zoneID), desired, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureLoadBalancer publishes the weighted record set as a load balancer with a single pool of the weighted targets
+func (c *CloudflareDNSProvider) ensureLoadBalancer(zoneID string, rs *endpointRecordSet) error {
+	desiredPool := toPool(c.poolName(zoneID, rs.dnsName), rs)
+	var pools []pool
+	if err := c.client.Get(c.ctx, c.poolsPath(), nil, &pools); err != nil {
+		return err
+	}
+	poolID := ""
+	for _, p := range pools {
+		if p.Name == desiredPool.Name {
+			poolID = p.ID
+		}
+	}
+	if poolID == "" {
+		created := &pool{}
+		if err := c.client.Post(c.ctx, c.poolsPath(), desiredPool, created); err != nil {
+			return fmt.Errorf("couldn't create load balancer pool %s for %s: %w", desiredPool.Name, rs.dnsName, err)
+		}
+		poolID = created.ID
+	} else if err := c.client.Put(c.ctx, c.poolsPath()+"/"+poolID, desiredPool, nil); err != nil {
+		return fmt.Errorf("couldn't update load balancer pool %s for %s: %w", desiredPool.Name, rs.dnsName, err)
+	}
+
+	desired := &loadBalancer{
+		Name:           rs.dnsName,
+		Description:    "Managed by the multicluster gateway controller",
+		TTL:            rs.ttl(),
+		FallbackPool:   poolID,
+		DefaultPools:   []string{poolID},
+		Proxied:        false,
+		SteeringPolicy: "off",
+	}
+	existing, err := c.findLoadBalancer(zoneID, rs.dnsName)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return c.client.Post(c.ctx, loadBalancersPath(zoneID), desired, nil)
+	}
+	return c.client.Put(c.ctx, loadBalancersPath(zoneID)+"/"+existing.ID, desired, nil)
+}
+
+func (c *CloudflareDNSProvider) deleteLoadBalancer(zoneID, dnsName string) error {
+	existing, err := c.findLoadBalancer(zoneID, dnsName)
+	if isForbidden(err) {
+		// without load balancing no load balancer was created
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if err := c.client.Delete(c.ctx, loadBalancersPath(zoneID)+"/"+existing.ID); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+	if c.accountID == "" {
+		return nil
+	}
+
+	// the pool is deleted once the load balancer using it is
+	var pools []pool
+	if err := c.client.Get(c.ctx, c.poolsPath(), nil, &pools); err != nil {
+		return err
+	}
+	poolName := c.poolName(zoneID, dnsName)
+	for _, p := range pools {
+		if p.Name != poolName {
+			continue
+		}
+		if err := c.client.Delete(c.ctx, c.poolsPath()+"/"+p.ID); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CloudflareDNSProvider) findLoadBalancer(zoneID, dnsName string) (*loadBalancer, error) {
+	var loadBalancers []loadBalancer
+	if err := c.client.Get(c.ctx, loadBalancersPath(zoneID), nil, &loadBalancers); err != nil {
+		return nil, err
+	}
+	for i := range loadBalancers {
+		if strings.EqualFold(loadBalancers[i].Name, dnsName) {
+			return &loadBalancers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func zonePath(zoneID string) string {
+	return fmt.Sprintf("/zones/%s", zoneID)
+}
+
+func recordsPath(zoneID string) string {
+	return fmt.Sprintf("%s/dns_records", zonePath(zoneID))
+}
+
+func recordPath(zoneID, recordID string) string {
+	return fmt.Sprintf("%s/%s", recordsPath(zoneID), recordID)
+}
+
+func loadBalancersPath(zoneID string) string {
+	return fmt.Sprintf("%s/load_balancers", zonePath(zoneID))
+}
+
+func (c *CloudflareDNSProvider) poolsPath() string {
+	return fmt.Sprintf("/accounts/%s/load_balancers/pools", c.accountID)
+}
+
+// poolName returns the name of the pool used for a weighted record set, pools are shared by all the zones of the
+// account
+func (c *CloudflareDNSProvider) poolName(zoneID, dnsName string) string {
+	hash := sha256.Sum224([]byte(fmt.Sprintf("%s/%s/%s", c.accountID, zoneID, dnsName)))
+	return fmt.Sprintf("mgc-%x", hash[:10])
+}
+
+func supportedRecordType(recordType string) bool {
+	switch v1alpha1.DNSRecordType(recordType) {
+	case v1alpha1.ARecordType, v1alpha1.AAAARecordType, v1alpha1.CNAMERecordType, v1alpha1.TXTRecordType:
+		return true
+	}
+	return false
+}
+
+// endpointRecordSet is the set of endpoints sharing the same name and type
+type endpointRecordSet struct {
+	dnsName    string
+	recordType string
+	endpoints  []*v1alpha1.Endpoint
+}
+
+func (rs *endpointRecordSet) isWeighted() bool {
+	for _, ep := range rs.endpoints {
+		if _, ok := ep.GetProviderSpecificProperty(dns.ProviderSpecificWeight); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *endpointRecordSet) ttl() int64 {
+	if rs.endpoints[0].RecordTTL == 0 {
+		return automaticTTL
+	}
+	return int64(rs.endpoints[0].RecordTTL)
+}
+
+func groupEndpoints(endpoints []*v1alpha1.Endpoint) map[string]*endpointRecordSet {
+	recordSets := map[string]*endpointRecordSet{}
+	for _, ep := range endpoints {
+		key := fmt.Sprintf("%s/%s", ep.DNSName, ep.RecordType)
+		rs, ok := recordSets[key]
+		if !ok {
+			rs = &endpointRecordSet{dnsName: ep.DNSName, recordType: ep.RecordType}
+			recordSets[key] = rs
+		}
+		rs.endpoints = append(rs.endpoints, ep)
+	}
+	return recordSets
+}
+
+func sortedKeys(recordSets map[string]*endpointRecordSet) []string {
+	keys := make([]string, 0, len(recordSets))
+	for k := range recordSets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// endpointWeight returns the weight of the endpoint, 0 when it is not set or invalid
+func endpointWeight(ep *v1alpha1.Endpoint) int64 {
+	prop, ok := ep.GetProviderSpecificProperty(dns.ProviderSpecificWeight)
+	if !ok {
+		return 0
+	}
+	weight, err := strconv.ParseInt(prop.Value, 10, 64)
+	if err != nil || weight < 0 {
+		return 0
+	}
+	return weight
+}
+
+// roundRobinTargets returns the targets a weighted record set is published to without load balancing, the targets of
+// the endpoints with a weight, or of all the endpoints if none has one. A CNAME has a single target, the target of the
+// endpoint with the greatest weight.
+func roundRobinTargets(rs *endpointRecordSet) []string {
+	endpoints := make([]*v1alpha1.Endpoint, 0, len(rs.endpoints))
+	for _, ep := range rs.endpoints {
+		if endpointWeight(ep) > 0 {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	if len(endpoints) == 0 {
+		endpoints = rs.endpoints
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpointWeight(endpoints[i]) != endpointWeight(endpoints[j]) {
+			return endpointWeight(endpoints[i]) > endpointWeight(endpoints[j])
+		}
+		return endpoints[i].SetIdentifier < endpoints[j].SetIdentifier
+	})
+
+	var targets []string
+	for _, ep := range endpoints {
+		targets = append(targets, ep.Targets...)
+	}
+	if v1alpha1.DNSRecordType(rs.recordType) == v1alpha1.CNAMERecordType && len(targets) > 1 {
+		return targets[:1]
+	}
+	return targets
+}
+
+// toPool returns the pool of the weighted targets, the weight of a target is relative to the greatest weight as
+// Cloudflare origin weights range from 0 to 1
+func toPool(poolName string, rs *endpointRecordSet) *pool {
+	var maxWeight int64
+	for _, ep := range rs.endpoints {
+		if w := endpointWeight(ep); w > maxWeight {
+			maxWeight = w
+		}
+	}
+
+	p := &pool{
+		Name:           poolName,
+		Description:    fmt.Sprintf("Weighted targets of %s", rs.dnsName),
+		Enabled:        true,
+		OriginSteering: &originSteering{Policy: "random"},
+	}
+	for _, ep := range rs.endpoints {
+		weight := 0.0
+		if maxWeight > 0 {
+			weight = math.Round(float64(endpointWeight(ep))/float64(maxWeight)*100) / 100
+		}
+		for _, target := range ep.Targets {
+			p.Origins = append(p.Origins, origin{
+				Name:    originName(ep.SetIdentifier, target),
+				Address: target,
+				Weight:  weight,
+				// a weight of 0 means the target should not receive any traffic
+				Enabled: weight > 0,
+			})
+		}
+	}
+	sort.Slice(p.Origins, func(i, j int) bool {
+		return p.Origins[i].Name < p.Origins[j].Name
+	})
+	return p
+}
+
+func originName(setIdentifier, target string) string {
+	name := target
+	if setIdentifier != "" && setIdentifier != target {
+		name = fmt.Sprintf("%s-%s", setIdentifier, target)
+	}
+	return strings.NewReplacer(".", "-", "*", "wildcard").Replace(name)
+}
@@ -0,0 +1,513 @@
+//go:build unit
+
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+const (
+	testZoneID    = "zone1"
+	testAccountID = "account1"
+	testPoolsPath = "/accounts/account1/load_balancers/pools"
+)
+
+// mockCloudflareClient stores the resources posted in collections keyed by path, each resource keyed by the ID it is
+// given when posted, unless it already has one
+type mockCloudflareClient struct {
+	collections map[string]map[string][]byte
+	nextID      int
+	// forbidden are the path prefixes the requests to are forbidden
+	forbidden []string
+}
+
+var _ cloudflareClient = &mockCloudflareClient{}
+
+func newMockCloudflareClient() *mockCloudflareClient {
+	m := &mockCloudflareClient{collections: map[string]map[string][]byte{}}
+	m.add("/zones", &zone{ID: testZoneID, Name: "example.com", NameServers: []string{"ns1.cloudflare.com"}})
+	return m
+}
+
+func (m *mockCloudflareClient) add(collection string, in interface{}) string {
+	data, _ := json.Marshal(in)
+	resource := map[string]interface{}{}
+	_ = json.Unmarshal(data, &resource)
+	id, _ := resource["id"].(string)
+	if id == "" {
+		m.nextID++
+		id = fmt.Sprintf("id%d", m.nextID)
+		resource["id"] = id
+	}
+	data, _ = json.Marshal(resource)
+	if m.collections[collection] == nil {
+		m.collections[collection] = map[string][]byte{}
+	}
+	m.collections[collection][id] = data
+	return id
+}
+
+func (m *mockCloudflareClient) check(p string) error {
+	for _, prefix := range m.forbidden {
+		if strings.HasPrefix(p, prefix) {
+			return &responseError{StatusCode: http.StatusForbidden, Errors: []apiError{{Code: 10000, Message: "Authentication error"}}}
+		}
+	}
+	return nil
+}
+
+// item returns the collection and ID of the resource of the path, false when the path is not a resource
+func (m *mockCloudflareClient) item(p string) (string, string, bool) {
+	collection, id := path.Dir(p), path.Base(p)
+	_, ok := m.collections[collection][id]
+	return collection, id, ok
+}
+
+func (m *mockCloudflareClient) list(p string, query url.Values) []json.RawMessage {
+	var ids []string
+	for id := range m.collections[p] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var results []json.RawMessage
+	for _, id := range ids {
+		data := m.collections[p][id]
+		resource := map[string]interface{}{}
+		_ = json.Unmarshal(data, &resource)
+		if (query.Get("name") != "" && resource["name"] != query.Get("name")) || (query.Get("type") != "" && resource["type"] != query.Get("type")) {
+			continue
+		}
+		results = append(results, data)
+	}
+	return results
+}
+
+func (m *mockCloudflareClient) Get(_ context.Context, p string, query url.Values, out interface{}) error {
+	if err := m.check(p); err != nil {
+		return err
+	}
+	if collection, id, ok := m.item(p); ok {
+		return json.Unmarshal(m.collections[collection][id], out)
+	}
+	if strings.HasPrefix(p, "/zones/") && m.collections["/zones"][strings.Split(p, "/")[2]] == nil {
+		return &responseError{StatusCode: http.StatusBadRequest, Errors: []apiError{{Code: invalidZoneErrorCode, Message: "Invalid zone identifier"}}}
+	}
+	data, _ := json.Marshal(m.list(p, query))
+	return json.Unmarshal(data, out)
+}
+
+func (m *mockCloudflareClient) Post(_ context.Context, p string, in, out interface{}) error {
+	if err := m.check(p); err != nil {
+		return err
+	}
+	id := m.add(p, in)
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(m.collections[p][id], out)
+}
+
+func (m *mockCloudflareClient) Put(_ context.Context, p string, in, out interface{}) error {
+	if err := m.check(p); err != nil {
+		return err
+	}
+	collection, id, ok := m.item(p)
+	if !ok {
+		return &responseError{StatusCode: http.StatusNotFound}
+	}
+	data, _ := json.Marshal(in)
+	resource := map[string]interface{}{}
+	_ = json.Unmarshal(data, &resource)
+	resource["id"] = id
+	m.collections[collection][id], _ = json.Marshal(resource)
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(m.collections[collection][id], out)
+}
+
+func (m *mockCloudflareClient) Delete(_ context.Context, p string) error {
+	if err := m.check(p); err != nil {
+		return err
+	}
+	collection, id, ok := m.item(p)
+	if !ok {
+		return &responseError{StatusCode: http.StatusNotFound}
+	}
+	delete(m.collections[collection], id)
+	return nil
+}
+
+func (m *mockCloudflareClient) Count(_ context.Context, p string, query url.Values) (int64, error) {
+	return int64(len(m.list(p, query))), nil
+}
+
+func (m *mockCloudflareClient) records(dnsName, recordType string) []dnsRecord {
+	var records []dnsRecord
+	data, _ := json.Marshal(m.list(recordsPath(testZoneID), url.Values{"name": []string{dnsName}, "type": []string{recordType}}))
+	_ = json.Unmarshal(data, &records)
+	return records
+}
+
+func (m *mockCloudflareClient) pools() []pool {
+	var pools []pool
+	data, _ := json.Marshal(m.list(testPoolsPath, nil))
+	_ = json.Unmarshal(data, &pools)
+	return pools
+}
+
+func (m *mockCloudflareClient) loadBalancers() []loadBalancer {
+	var loadBalancers []loadBalancer
+	data, _ := json.Marshal(m.list(loadBalancersPath(testZoneID), nil))
+	_ = json.Unmarshal(data, &loadBalancers)
+	return loadBalancers
+}
+
+func recordContents(records []dnsRecord) []string {
+	var contents []string
+	for _, r := range records {
+		contents = append(contents, r.Content)
+	}
+	sort.Strings(contents)
+	return contents
+}
+
+func testProvider(client cloudflareClient, accountID string) *CloudflareDNSProvider {
+	return &CloudflareDNSProvider{
+		client:    client,
+		logger:    logr.Discard(),
+		accountID: accountID,
+		ctx:       context.TODO(),
+	}
+}
+
+func testManagedZone() *v1alpha1.ManagedZone {
+	return &v1alpha1.ManagedZone{
+		Spec:   v1alpha1.ManagedZoneSpec{DomainName: "example.com"},
+		Status: v1alpha1.ManagedZoneStatus{ID: testZoneID},
+	}
+}
+
+func weightedEndpoint(dnsName, target, weight string) *v1alpha1.Endpoint {
+	return &v1alpha1.Endpoint{
+		DNSName:       dnsName,
+		Targets:       []string{target},
+		RecordType:    "CNAME",
+		SetIdentifier: target,
+		RecordTTL:     dns.DefaultTTL,
+		ProviderSpecific: []v1alpha1.ProviderSpecificProperty{
+			{Name: dns.ProviderSpecificWeight, Value: weight},
+		},
+	}
+}
+
+func TestCloudflareDNSProvider_Ensure(t *testing.T) {
+	weighted := []*v1alpha1.Endpoint{
+		weightedEndpoint("lb.example.com", "cluster1.example.com", "120"),
+		weightedEndpoint("lb.example.com", "cluster2.example.com", "60"),
+	}
+
+	testCases := []struct {
+		name      string
+		accountID string
+		forbidden []string
+		endpoints []*v1alpha1.Endpoint
+		published []*v1alpha1.Endpoint
+		existing  []dnsRecord
+		assert    func(*mockCloudflareClient) error
+		wantErr   error
+	}{
+		{
+			name: "creates A, AAAA, CNAME and TXT records",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "a.example.com", Targets: []string{"1.1.1.1", "2.2.2.2"}, RecordType: "A", RecordTTL: 60},
+				{DNSName: "a.example.com", Targets: []string{"2001:db8::1"}, RecordType: "AAAA", RecordTTL: 60},
+				{DNSName: "www.example.com", Targets: []string{"a.example.com"}, RecordType: "CNAME", RecordTTL: 300},
+				{DNSName: "example.com", Targets: []string{"owner=mgc"}, RecordType: "TXT"},
+			},
+			assert: func(m *mockCloudflareClient) error {
+				if got := recordContents(m.records("a.example.com", "A")); strings.Join(got, ",") != "1.1.1.1,2.2.2.2" {
+					return fmt.Errorf("unexpected A records %v", got)
+				}
+				if got := recordContents(m.records("a.example.com", "AAAA")); strings.Join(got, ",") != "2001:db8::1" {
+					return fmt.Errorf("unexpected AAAA records %v", got)
+				}
+				if got := m.records("www.example.com", "CNAME"); len(got) != 1 || got[0].Content != "a.example.com" || got[0].TTL != 300 || got[0].Proxied {
+					return fmt.Errorf("unexpected CNAME records %+v", got)
+				}
+				if got := m.records("example.com", "TXT"); len(got) != 1 || got[0].Content != "owner=mgc" || got[0].TTL != automaticTTL {
+					return fmt.Errorf("unexpected TXT records %+v", got)
+				}
+				return nil
+			},
+		},
+		{
+			name: "updates the existing records to the targets",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "a.example.com", Targets: []string{"1.1.1.1", "3.3.3.3"}, RecordType: "A", RecordTTL: 120},
+			},
+			existing: []dnsRecord{
+				{Name: "a.example.com", Type: "A", Content: "1.1.1.1", TTL: 60, Proxied: true},
+				{Name: "a.example.com", Type: "A", Content: "2.2.2.2", TTL: 60},
+			},
+			assert: func(m *mockCloudflareClient) error {
+				records := m.records("a.example.com", "A")
+				if got := recordContents(records); strings.Join(got, ",") != "1.1.1.1,3.3.3.3" {
+					return fmt.Errorf("unexpected A records %v", got)
+				}
+				for _, r := range records {
+					if r.TTL != 120 || r.Proxied {
+						return fmt.Errorf("expected the records to be updated, got %+v", r)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name: "deletes the records no longer published",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "a.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60},
+			},
+			published: []*v1alpha1.Endpoint{
+				{DNSName: "a.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60},
+				{DNSName: "old.example.com", Targets: []string{"2.2.2.2"}, RecordType: "A", RecordTTL: 60},
+			},
+			existing: []dnsRecord{
+				{Name: "old.example.com", Type: "A", Content: "2.2.2.2", TTL: 60},
+			},
+			assert: func(m *mockCloudflareClient) error {
+				if got := m.records("old.example.com", "A"); len(got) != 0 {
+					return fmt.Errorf("expected the old records to be deleted, got %+v", got)
+				}
+				return nil
+			},
+		},
+		{
+			name:      "publishes weighted endpoints as a load balancer",
+			accountID: testAccountID,
+			endpoints: weighted,
+			existing: []dnsRecord{
+				{Name: "lb.example.com", Type: "CNAME", Content: "cluster1.example.com", TTL: 60},
+			},
+			assert: func(m *mockCloudflareClient) error {
+				pools := m.pools()
+				if len(pools) != 1 || len(pools[0].Origins) != 2 {
+					return fmt.Errorf("expected a pool of the two targets, got %+v", pools)
+				}
+				for _, o := range pools[0].Origins {
+					want := 1.0
+					if o.Address == "cluster2.example.com" {
+						want = 0.5
+					}
+					if o.Weight != want || !o.Enabled {
+						return fmt.Errorf("expected origin %s to have weight %v, got %+v", o.Address, want, o)
+					}
+				}
+				lbs := m.loadBalancers()
+				if len(lbs) != 1 || lbs[0].Name != "lb.example.com" || lbs[0].FallbackPool != pools[0].ID ||
+					len(lbs[0].DefaultPools) != 1 || lbs[0].DefaultPools[0] != pools[0].ID || lbs[0].Proxied || lbs[0].TTL != 60 {
+					return fmt.Errorf("unexpected load balancers %+v", lbs)
+				}
+				if got := m.records("lb.example.com", "CNAME"); len(got) != 0 {
+					return fmt.Errorf("expected the round-robin records to be replaced by the load balancer, got %+v", got)
+				}
+				return nil
+			},
+		},
+		{
+			name:      "publishes weighted endpoints as round-robin records without load balancing",
+			accountID: testAccountID,
+			forbidden: []string{testPoolsPath},
+			endpoints: []*v1alpha1.Endpoint{
+				weightedEndpoint("lb.example.com", "cluster1.example.com", "60"),
+				weightedEndpoint("lb.example.com", "cluster2.example.com", "120"),
+			},
+			assert: func(m *mockCloudflareClient) error {
+				if got := m.records("lb.example.com", "CNAME"); len(got) != 1 || got[0].Content != "cluster2.example.com" {
+					return fmt.Errorf("expected a CNAME to the target with the greatest weight, got %+v", got)
+				}
+				if len(m.loadBalancers()) != 0 {
+					return fmt.Errorf("expected no load balancer")
+				}
+				return nil
+			},
+		},
+		{
+			name: "publishes weighted A endpoints as round-robin records without an account",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "a.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A", SetIdentifier: "1", RecordTTL: 60,
+					ProviderSpecific: []v1alpha1.ProviderSpecificProperty{{Name: dns.ProviderSpecificWeight, Value: "120"}}},
+				{DNSName: "a.example.com", Targets: []string{"2.2.2.2"}, RecordType: "A", SetIdentifier: "2", RecordTTL: 60,
+					ProviderSpecific: []v1alpha1.ProviderSpecificProperty{{Name: dns.ProviderSpecificWeight, Value: "60"}}},
+				{DNSName: "a.example.com", Targets: []string{"3.3.3.3"}, RecordType: "A", SetIdentifier: "3", RecordTTL: 60,
+					ProviderSpecific: []v1alpha1.ProviderSpecificProperty{{Name: dns.ProviderSpecificWeight, Value: "0"}}},
+			},
+			assert: func(m *mockCloudflareClient) error {
+				if got := recordContents(m.records("a.example.com", "A")); strings.Join(got, ",") != "1.1.1.1,2.2.2.2" {
+					return fmt.Errorf("expected the targets with a weight, got %v", got)
+				}
+				return nil
+			},
+		},
+		{
+			name: "unsupported routing policy",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "geo.example.com", Targets: []string{"a.example.com"}, RecordType: "CNAME", SetIdentifier: "IE"},
+				{DNSName: "geo.example.com", Targets: []string{"b.example.com"}, RecordType: "CNAME", SetIdentifier: "US"},
+			},
+			wantErr: errors.New("routing policy"),
+		},
+		{
+			name: "unsupported record type",
+			endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "example.com", Targets: []string{"ns1.example.com"}, RecordType: "NS"},
+			},
+			wantErr: errors.New("unsupported record type NS"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m := newMockCloudflareClient()
+			m.forbidden = testCase.forbidden
+			for _, r := range testCase.existing {
+				m.add(recordsPath(testZoneID), r)
+			}
+			p := testProvider(m, testCase.accountID)
+			record := &v1alpha1.DNSRecord{
+				Spec:   v1alpha1.DNSRecordSpec{Endpoints: testCase.endpoints},
+				Status: v1alpha1.DNSRecordStatus{Endpoints: testCase.published},
+			}
+
+			err := p.Ensure(record, testManagedZone())
+			if testCase.wantErr != nil {
+				if err == nil || !strings.Contains(err.Error(), testCase.wantErr.Error()) {
+					t.Fatalf("expected error %v, got %v", testCase.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if err := testCase.assert(m); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCloudflareDNSProvider_Ensure_zoneNotFound(t *testing.T) {
+	p := testProvider(newMockCloudflareClient(), "")
+	managedZone := testManagedZone()
+	managedZone.Status.ID = "missing"
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{Endpoints: []*v1alpha1.Endpoint{
+			{DNSName: "a.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A"},
+		}},
+	}
+	if err := p.Ensure(record, managedZone); !errors.Is(err, dns.ErrZoneNotFound) {
+		t.Errorf("expected a zone not found error, got %v", err)
+	}
+}
+
+func TestCloudflareDNSProvider_Delete(t *testing.T) {
+	m := newMockCloudflareClient()
+	p := testProvider(m, testAccountID)
+	record := &v1alpha1.DNSRecord{
+		Spec: v1alpha1.DNSRecordSpec{Endpoints: []*v1alpha1.Endpoint{
+			{DNSName: "a.example.com", Targets: []string{"1.1.1.1"}, RecordType: "A", RecordTTL: 60},
+			weightedEndpoint("lb.example.com", "cluster1.example.com", "120"),
+			weightedEndpoint("lb.example.com", "cluster2.example.com", "120"),
+		}},
+	}
+	if err := p.Ensure(record, testManagedZone()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// a record of the zone not published by the record is left untouched
+	m.add(recordsPath(testZoneID), dnsRecord{Name: "other.example.com", Type: "A", Content: "9.9.9.9", TTL: 60})
+
+	if err := p.Delete(record, testManagedZone()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := m.records("a.example.com", "A"); len(got) != 0 {
+		t.Errorf("expected the records to be deleted, got %+v", got)
+	}
+	if len(m.loadBalancers()) != 0 || len(m.pools()) != 0 {
+		t.Errorf("expected the load balancer and its pool to be deleted, got %+v and %+v", m.loadBalancers(), m.pools())
+	}
+	if got := m.records("other.example.com", "A"); len(got) != 1 {
+		t.Errorf("expected the other records to be left untouched, got %+v", got)
+	}
+
+	// deleting records already deleted succeeds
+	if err := p.Delete(record, testManagedZone()); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestCloudflareDNSProvider_EnsureManagedZone(t *testing.T) {
+	testCases := []struct {
+		name        string
+		accountID   string
+		managedZone *v1alpha1.ManagedZone
+		wantID      string
+		wantErr     bool
+	}{
+		{
+			name:        "gets the zone of the ID",
+			managedZone: &v1alpha1.ManagedZone{Spec: v1alpha1.ManagedZoneSpec{DomainName: "example.com", ID: testZoneID}},
+			wantID:      testZoneID,
+		},
+		{
+			name:        "finds the zone of the domain",
+			managedZone: &v1alpha1.ManagedZone{Spec: v1alpha1.ManagedZoneSpec{DomainName: "example.com"}},
+			wantID:      testZoneID,
+		},
+		{
+			name:        "creates the zone of the domain in the account",
+			accountID:   testAccountID,
+			managedZone: &v1alpha1.ManagedZone{Spec: v1alpha1.ManagedZoneSpec{DomainName: "example.org"}},
+			wantID:      "id2",
+		},
+		{
+			name:        "creating a zone requires an account",
+			managedZone: &v1alpha1.ManagedZone{Spec: v1alpha1.ManagedZoneSpec{DomainName: "example.org"}},
+			wantErr:     true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			m := newMockCloudflareClient()
+			m.add(recordsPath(testZoneID), dnsRecord{Name: "a.example.com", Type: "A", Content: "1.1.1.1"})
+			p := testProvider(m, testCase.accountID)
+
+			output, err := p.EnsureManagedZone(testCase.managedZone)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("expected error %v, got %v", testCase.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if output.ID != testCase.wantID {
+				t.Errorf("expected zone %s, got %s", testCase.wantID, output.ID)
+			}
+			if testCase.wantID == testZoneID && (len(output.NameServers) != 1 || output.RecordCount != 1) {
+				t.Errorf("expected the name servers and record count of the zone, got %+v", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudflare
+
+// Cloudflare API v4 representations of the zones, DNS records and load balancers used by the provider.
+// See https://developers.cloudflare.com/api/
+
+type response struct {
+	Success    bool        `json:"success"`
+	Errors     []apiError  `json:"errors"`
+	ResultInfo *resultInfo `json:"result_info,omitempty"`
+}
+
+type apiError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type resultInfo struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	TotalCount int64 `json:"total_count"`
+}
+
+type account struct {
+	ID string `json:"id"`
+}
+
+type zone struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`
+	Account     *account `json:"account,omitempty"`
+	NameServers []string `json:"name_servers,omitempty"`
+}
+
+type dnsRecord struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	// TTL is the time to live of the record in seconds, 1 for automatic
+	TTL     int64 `json:"ttl"`
+	Proxied bool  `json:"proxied"`
+}
+
+type pool struct {
+	ID             string          `json:"id,omitempty"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Enabled        bool            `json:"enabled"`
+	Origins        []origin        `json:"origins"`
+	OriginSteering *originSteering `json:"origin_steering,omitempty"`
+}
+
+type origin struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	// Weight is the share of the traffic of the pool sent to the origin, between 0 and 1
+	Weight  float64 `json:"weight"`
+	Enabled bool    `json:"enabled"`
+}
+
+type originSteering struct {
+	Policy string `json:"policy"`
+}
+
+type loadBalancer struct {
+	ID             string   `json:"id,omitempty"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	TTL            int64    `json:"ttl,omitempty"`
+	FallbackPool   string   `json:"fallback_pool"`
+	DefaultPools   []string `json:"default_pools"`
+	Proxied        bool     `json:"proxied"`
+	SteeringPolicy string   `json:"steering_policy,omitempty"`
+}
@@ -19,18 +19,64 @@ package dns
 import (
 	"context"
 	"errors"
+	"fmt"
 	"regexp"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 )
 
 const (
-	DefaultTTL              = 60
-	DefaultCnameTTL         = 300
-	ProviderSpecificWeight  = "weight"
-	ProviderSpecificGeoCode = "geo-code"
+	DefaultTTL                              = 60
+	DefaultCnameTTL                         = 300
+	ProviderSpecificWeight                  = "weight"
+	ProviderSpecificGeoCode                 = "geo-code"
+	ProviderSpecificRegion                  = "region"
+	ProviderSpecificGeoproximityRegion      = "geoproximity-region"
+	ProviderSpecificGeoproximityCoordinates = "geoproximity-coordinates"
+	ProviderSpecificGeoproximityBias        = "geoproximity-bias"
 )
 
+// ErrOwnershipConflict is returned by a provider when records could not be changed because they are owned by another
+// controller
+var ErrOwnershipConflict = errors.New("records are owned by another controller")
+
+// ErrZoneNotFound is returned by a provider when the zone backing a managed zone no longer exists in the provider
+var ErrZoneNotFound = errors.New("zone not found in the DNS provider")
+
+// ErrProviderUnreachable is returned when the API of a DNS provider cannot be reached with the configured credentials
+var ErrProviderUnreachable = errors.New("DNS provider unreachable")
+
+// ErrDNSSECNotSupported is returned when DNSSEC is enabled on a managed zone whose provider cannot sign zones
+var ErrDNSSECNotSupported = errors.New("DNSSEC is not supported by the DNS provider")
+
+// AuthenticationError is returned by a provider when its API rejects the credentials of a managed zone, retrying
+// cannot succeed until the credentials are fixed
+type AuthenticationError struct {
+	// Secret is the namespace/name of the secret holding the rejected credentials, empty for the default credentials
+	Secret string
+	Err    error
+}
+
+// NewAuthenticationError returns an AuthenticationError for the credentials of the managed zone
+func NewAuthenticationError(managedZone *v1alpha1.ManagedZone, err error) *AuthenticationError {
+	authErr := &AuthenticationError{Err: err}
+	if managedZone.Spec.SecretRef != nil {
+		authErr.Secret = managedZone.Spec.SecretRef.Namespace + "/" + managedZone.Spec.SecretRef.Name
+	}
+	return authErr
+}
+
+func (e *AuthenticationError) Error() string {
+	if e.Secret == "" {
+		return fmt.Sprintf("the DNS provider rejected the default credentials: %v", e.Err)
+	}
+	return fmt.Sprintf("the DNS provider rejected the credentials of secret %s: %v", e.Secret, e.Err)
+}
+
+func (e *AuthenticationError) Unwrap() error {
+	return e.Err
+}
+
 type DNSProviderFactory func(ctx context.Context, managedZone *v1alpha1.ManagedZone) (Provider, error)
 
 // Provider knows how to manage DNS zones only as pertains to routing.
@@ -54,6 +100,26 @@ type Provider interface {
 	ProviderSpecific() ProviderSpecificLabels
 }
 
+// ConnectivityChecker is implemented by the providers able to check they can reach their API with a lightweight call
+type ConnectivityChecker interface {
+	// CheckConnectivity returns an error wrapping ErrProviderUnreachable if the API of the provider cannot be reached
+	CheckConnectivity() error
+}
+
+// CreationConnectivityChecker is implemented by the providers checking they can reach their API when they are
+// created, a provider created without error is reachable and is not checked again
+type CreationConnectivityChecker interface {
+	// ConnectivityCheckedOnCreation returns whether the connectivity of the provider was checked when it was created
+	ConnectivityCheckedOnCreation() bool
+}
+
+// DNSSECProvider is implemented by the providers able to sign their zones with DNSSEC
+type DNSSECProvider interface {
+	// EnsureDNSSEC enables the DNSSEC signing of the managed zone unless it is already signed, and returns the DS
+	// record of its key-signing key
+	EnsureDNSSEC(managedZone *v1alpha1.ManagedZone) (string, error)
+}
+
 type ProviderSpecificLabels struct {
 	Weight        string
 	HealthCheckID string
@@ -76,7 +142,8 @@ func (*FakeProvider) Delete(dnsRecord *v1alpha1.DNSRecord, managedZone *v1alpha1
 	return nil
 }
 func (*FakeProvider) EnsureManagedZone(managedZone *v1alpha1.ManagedZone) (ManagedZoneOutput, error) {
-	return ManagedZoneOutput{}, nil
+	nameServer := "ns1." + managedZone.Spec.DomainName
+	return ManagedZoneOutput{NameServers: []*string{&nameServer}}, nil
 }
 func (*FakeProvider) DeleteManagedZone(managedZone *v1alpha1.ManagedZone) error { return nil }
 
@@ -90,6 +157,20 @@ func (*FakeProvider) ProviderSpecific() ProviderSpecificLabels {
 	}
 }
 
+// ResolveEndpoints returns copies of the endpoints with the provider defaults applied, i.e. as they are published by
+// the provider. Weights and geo codes are already resolved into the provider specific properties of the endpoints.
+func ResolveEndpoints(endpoints []*v1alpha1.Endpoint) []*v1alpha1.Endpoint {
+	resolved := make([]*v1alpha1.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		resolvedEndpoint := endpoint.DeepCopy()
+		if resolvedEndpoint.RecordTTL == 0 {
+			resolvedEndpoint.RecordTTL = DefaultTTL
+		}
+		resolved = append(resolved, resolvedEndpoint)
+	}
+	return resolved
+}
+
 // SanitizeError removes request specific data from error messages in order to make them consistent across multiple similar requests to the provider.  e.g AWS SDK Request ids `request id: 051c860b-9b30-4c19-be1a-1280c3e9fdc4`
 func SanitizeError(err error) error {
 	regexp := regexp.MustCompile(`request id: [^\s]+`)
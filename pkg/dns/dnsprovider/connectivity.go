@@ -0,0 +1,165 @@
+package dnsprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+const (
+	// DefaultConnectivityCheckInterval is how often the connectivity of the DNS providers is checked when no interval
+	// is configured
+	DefaultConnectivityCheckInterval = time.Minute
+	// connectivityFailureThreshold is the number of consecutive failed checks after which a provider is reported
+	// unreachable, so that a single failed call does not fail the readiness of the controller
+	connectivityFailureThreshold = 3
+)
+
+// ConnectivityCheck periodically checks that the DNS providers configured by the managed zones can be reached, and
+// reports the providers persistently unreachable through the readiness probe of the manager. The checks run in the
+// background, the probe only reads their last results.
+type ConnectivityCheck struct {
+	client          client.Reader
+	providerFactory dns.DNSProviderFactory
+	interval        time.Duration
+
+	mux sync.Mutex
+	// failures are the consecutive failed checks of the unreachable providers, keyed by provider
+	failures map[string]int
+	errs     map[string]error
+}
+
+// NewConnectivityCheck returns a check of the providers of the managed zones, created by the provider factory, every
+// interval
+func NewConnectivityCheck(c client.Reader, providerFactory dns.DNSProviderFactory, interval time.Duration) *ConnectivityCheck {
+	return &ConnectivityCheck{
+		client:          c,
+		providerFactory: providerFactory,
+		interval:        interval,
+		failures:        map[string]int{},
+		errs:            map[string]error{},
+	}
+}
+
+// Start implements manager.Runnable, checking the providers right away and then every interval until the context is
+// done
+func (c *ConnectivityCheck) Start(ctx context.Context) error {
+	c.check(ctx)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.check(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every replica checks the providers, so that the
+// readiness of the standby replicas reflects whether they could take over.
+func (c *ConnectivityCheck) NeedLeaderElection() bool {
+	return false
+}
+
+// Checker implements healthz.Checker, failing while a provider has been unreachable for the failure threshold
+func (c *ConnectivityCheck) Checker(_ *http.Request) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	var unreachable []string
+	for key, failures := range c.failures {
+		if failures >= connectivityFailureThreshold {
+			unreachable = append(unreachable, fmt.Sprintf("%s: %v", key, c.errs[key]))
+		}
+	}
+	if len(unreachable) == 0 {
+		return nil
+	}
+	sort.Strings(unreachable)
+	return fmt.Errorf("%d DNS providers unreachable: %s", len(unreachable), strings.Join(unreachable, "; "))
+}
+
+// check checks the connectivity of each provider once. The managed zones using the same credentials share a
+// provider, only the first zone of each provider is used to check it.
+func (c *ConnectivityCheck) check(ctx context.Context) {
+	logger := log.FromContext(ctx).WithName("dns-provider-connectivity")
+
+	managedZones := &v1alpha1.ManagedZoneList{}
+	if err := c.client.List(ctx, managedZones); err != nil {
+		logger.Error(err, "unable to list managed zones")
+		return
+	}
+	providers := map[string]*v1alpha1.ManagedZone{}
+	for i := range managedZones.Items {
+		key := providerKey(&managedZones.Items[i])
+		if _, ok := providers[key]; !ok {
+			providers[key] = &managedZones.Items[i]
+		}
+	}
+
+	errs := map[string]error{}
+	for key, managedZone := range providers {
+		if err := c.checkProvider(ctx, managedZone); err != nil {
+			logger.Info("DNS provider unreachable", "provider", key, "error", err.Error())
+			errs[key] = err
+		}
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	// the providers no longer used, or reachable again, are reset
+	for key := range c.failures {
+		if _, ok := errs[key]; !ok {
+			delete(c.failures, key)
+			delete(c.errs, key)
+		}
+	}
+	for key, err := range errs {
+		c.failures[key]++
+		c.errs[key] = err
+	}
+}
+
+// checkProvider returns an error if the provider of the managed zone is unreachable. Other errors creating the
+// provider, e.g. a missing secret, are reported on the managed zone and not considered a connectivity failure. The
+// providers checking their connectivity when they are created are not checked again, so that each check makes a
+// single call to their API.
+func (c *ConnectivityCheck) checkProvider(ctx context.Context, managedZone *v1alpha1.ManagedZone) error {
+	provider, err := c.providerFactory(ctx, managedZone)
+	if err != nil {
+		if errors.Is(err, dns.ErrProviderUnreachable) {
+			return err
+		}
+		return nil
+	}
+	if created, ok := provider.(dns.CreationConnectivityChecker); ok && created.ConnectivityCheckedOnCreation() {
+		return nil
+	}
+	checker, ok := provider.(dns.ConnectivityChecker)
+	if !ok {
+		return nil
+	}
+	return checker.CheckConnectivity()
+}
+
+// providerKey identifies the provider of the managed zone by its dnsprovider secret, or by its provider type when it
+// uses the default credentials of the provider
+func providerKey(managedZone *v1alpha1.ManagedZone) string {
+	if managedZone.Spec.SecretRef == nil || managedZone.Spec.SecretRef.Name == "" {
+		return fmt.Sprintf("%s default credentials", managedZone.Spec.Provider)
+	}
+	return fmt.Sprintf("secret %s/%s", managedZone.Spec.SecretRef.Namespace, managedZone.Spec.SecretRef.Name)
+}
@@ -0,0 +1,157 @@
+//go:build unit
+
+package dnsprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+)
+
+type connectivityTestProvider struct {
+	dns.FakeProvider
+	err    error
+	checks int
+}
+
+func (p *connectivityTestProvider) CheckConnectivity() error {
+	p.checks++
+	return p.err
+}
+
+type checkedOnCreationProvider struct {
+	connectivityTestProvider
+}
+
+func (p *checkedOnCreationProvider) ConnectivityCheckedOnCreation() bool {
+	return true
+}
+
+func TestConnectivityCheck(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	managedZone := func(name, secretName string) *v1alpha1.ManagedZone {
+		return &v1alpha1.ManagedZone{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: v1alpha1.ManagedZoneSpec{
+				DomainName: name,
+				SecretRef:  &v1alpha1.SecretRef{Name: secretName, Namespace: "test"},
+			},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		managedZone("example.com", "aws-credentials"),
+		managedZone("example.net", "aws-credentials"),
+		managedZone("example.org", "missing-credentials"),
+	).Build()
+
+	provider := &connectivityTestProvider{}
+	factory := func(_ context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+		if managedZone.Spec.SecretRef.Name == "missing-credentials" {
+			// a misconfigured managed zone is not a connectivity failure
+			return nil, errors.New("secret not found")
+		}
+		return provider, nil
+	}
+	check := NewConnectivityCheck(c, factory, time.Minute)
+
+	readyz := &healthz.Handler{Checks: map[string]healthz.Checker{"dns-providers": check.Checker}}
+	ready := func() bool {
+		rec := httptest.NewRecorder()
+		readyz.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code == http.StatusOK
+	}
+
+	check.check(context.TODO())
+	if !ready() {
+		t.Fatalf("expected the controller to be ready while the providers are reachable, got %v", check.Checker(nil))
+	}
+	if provider.checks != 1 {
+		t.Errorf("expected the zones sharing the credentials to be checked once, got %d checks", provider.checks)
+	}
+
+	// the provider failing is only reported once it is persistently unreachable
+	provider.err = fmt.Errorf("%w: failed to list route53 hosted zones: timeout", dns.ErrProviderUnreachable)
+	for i := 1; i < connectivityFailureThreshold; i++ {
+		check.check(context.TODO())
+		if !ready() {
+			t.Fatalf("expected the controller to be ready after %d failed checks", i)
+		}
+	}
+	check.check(context.TODO())
+	if ready() {
+		t.Fatalf("expected the controller not to be ready after %d failed checks", connectivityFailureThreshold)
+	}
+	if err := check.Checker(nil); err == nil || !strings.Contains(err.Error(), "secret test/aws-credentials: DNS provider unreachable") {
+		t.Errorf("expected the unreachable provider to be reported, got %v", err)
+	}
+
+	// a single successful check resets the failures
+	provider.err = nil
+	check.check(context.TODO())
+	if !ready() {
+		t.Errorf("expected the controller to be ready once the provider is reachable again, got %v", check.Checker(nil))
+	}
+}
+
+func TestConnectivityCheck_providerCreation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com", Provider: v1alpha1.DNSProviderTypeAWS},
+	}).Build()
+
+	// the AWS provider checks its connectivity when it is created
+	factory := func(_ context.Context, _ *v1alpha1.ManagedZone) (dns.Provider, error) {
+		return nil, fmt.Errorf("unable to create AWS dns provider from the default credentials: %w", dns.ErrProviderUnreachable)
+	}
+	check := NewConnectivityCheck(c, factory, time.Minute)
+	for i := 0; i < connectivityFailureThreshold; i++ {
+		check.check(context.TODO())
+	}
+	if err := check.Checker(nil); err == nil || !strings.Contains(err.Error(), "aws default credentials") {
+		t.Errorf("expected the provider failing to be created to be reported unreachable, got %v", err)
+	}
+}
+
+func TestConnectivityCheck_checkedOnCreation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&v1alpha1.ManagedZone{
+		ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+		Spec:       v1alpha1.ManagedZoneSpec{DomainName: "example.com", Provider: v1alpha1.DNSProviderTypeAWS},
+	}).Build()
+
+	provider := &checkedOnCreationProvider{}
+	factory := func(_ context.Context, _ *v1alpha1.ManagedZone) (dns.Provider, error) {
+		return provider, nil
+	}
+	check := NewConnectivityCheck(c, factory, time.Minute)
+	check.check(context.TODO())
+	if provider.checks != 0 {
+		t.Errorf("expected a provider checked when it is created not to be checked again, got %d checks", provider.checks)
+	}
+	if err := check.Checker(nil); err != nil {
+		t.Errorf("expected the provider created to be reachable, got %v", err)
+	}
+}
@@ -12,24 +12,64 @@ import (
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/aws"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/azure"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/cloudflare"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/google"
 )
 
-var errUnsupportedProvider = fmt.Errorf("provider type given is not supported")
+var (
+	errUnsupportedProvider = fmt.Errorf("provider type given is not supported")
+	errMissingCredentials  = fmt.Errorf("no dns provider credentials")
+)
+
+const (
+	ProviderSecretTypeAWS        v1.SecretType = "kuadrant.io/aws"
+	ProviderSecretTypeGoogle     v1.SecretType = "kuadrant.io/gcp"
+	ProviderSecretTypeAzure      v1.SecretType = "kuadrant.io/azure"
+	ProviderSecretTypeCloudflare v1.SecretType = "kuadrant.io/cloudflare"
+)
+
+// providerSecretTypes maps the ManagedZone provider to the type of secret holding its credentials
+var providerSecretTypes = map[v1alpha1.DNSProviderType]v1.SecretType{
+	v1alpha1.DNSProviderTypeAWS:        ProviderSecretTypeAWS,
+	v1alpha1.DNSProviderTypeGoogle:     ProviderSecretTypeGoogle,
+	v1alpha1.DNSProviderTypeAzure:      ProviderSecretTypeAzure,
+	v1alpha1.DNSProviderTypeCloudflare: ProviderSecretTypeCloudflare,
+}
 
 type providerFactory struct {
 	client.Client
+
+	route53RateLimiter    *aws.RateLimiter
+	route53RecordSetCache *aws.RecordSetCache
+	route53OwnerID        string
+	route53RecordPolicy   aws.RecordPolicy
+	zoneFilter            dns.ZoneFilter
 }
 
-func NewProvider(c client.Client) *providerFactory {
+// NewProvider returns a provider factory. The Route53 rate limiter and record set cache are shared by all the AWS
+// providers it creates, and the owner ID, when set, restricts them to the record sets owned by this controller. The record policy sets
+// whether they adopt, or delete, the record sets existing at the names of the DNSRecords. The AWS providers only change the zones
+// allowed by the zone filter.
+func NewProvider(c client.Client, route53RateLimiter *aws.RateLimiter, route53RecordSetCache *aws.RecordSetCache, route53OwnerID string, route53RecordPolicy aws.RecordPolicy, zoneFilter dns.ZoneFilter) *providerFactory {
 
 	return &providerFactory{
-		Client: c,
+		Client:                c,
+		route53RateLimiter:    route53RateLimiter,
+		route53RecordSetCache: route53RecordSetCache,
+		route53OwnerID:        route53OwnerID,
+		route53RecordPolicy:   route53RecordPolicy,
+		zoneFilter:            zoneFilter,
 	}
 }
 
-// depending on the provider type specified in the form of a custom secret type https://kubernetes.io/docs/concepts/configuration/secret/#secret-types in the dnsprovider secret it returns a dnsprovider.
+// depending on the provider set in the managed zone, or if not set the provider type specified in the form of a custom secret type https://kubernetes.io/docs/concepts/configuration/secret/#secret-types in the dnsprovider secret, it returns a dnsprovider.
+// A managed zone without a dnsprovider secret uses the default credentials of its provider.
 func (p *providerFactory) DNSProviderFactory(ctx context.Context, managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+	if managedZone.Spec.SecretRef == nil || managedZone.Spec.SecretRef.Name == "" {
+		return p.defaultCredentialsProvider(managedZone)
+	}
+
 	providerSecret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      managedZone.Spec.SecretRef.Name,
@@ -40,22 +80,43 @@ func (p *providerFactory) DNSProviderFactory(ctx context.Context, managedZone *v
 		return nil, err
 	}
 
-	switch providerSecret.Type {
-	case "kuadrant.io/aws":
-		dnsProvider, err := aws.NewProviderFromSecret(providerSecret)
+	providerType, err := providerSecretType(managedZone, providerSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch providerType {
+	case ProviderSecretTypeAWS:
+		dnsProvider, err := aws.NewProviderFromSecret(providerSecret, p.route53RateLimiter, p.route53RecordSetCache, p.route53OwnerID, p.route53RecordPolicy, p.zoneFilter)
 		if err != nil {
-			return nil, fmt.Errorf("unable to create AWS dns provider from secret: %v", err)
+			return nil, fmt.Errorf("unable to create AWS dns provider from secret: %w", err)
 		}
 		log.Log.V(1).Info("Route53 provider created", "managed zone:", managedZone.Name)
 
 		return dnsProvider, nil
-	case "kuadrant.io/gcp":
+	case ProviderSecretTypeGoogle:
 		dnsProvider, err := google.NewProviderFromSecret(ctx, providerSecret)
 		if err != nil {
-			return nil, fmt.Errorf("unable to create GCP dns provider from secret: %v", err)
+			return nil, fmt.Errorf("unable to create GCP dns provider from secret: %w", err)
 		}
 		log.Log.V(1).Info("Google provider created", "managed zone:", managedZone.Name)
 
+		return dnsProvider, nil
+	case ProviderSecretTypeAzure:
+		dnsProvider, err := azure.NewProviderFromSecret(ctx, providerSecret)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create Azure dns provider from secret: %w", err)
+		}
+		log.Log.V(1).Info("Azure provider created", "managed zone:", managedZone.Name)
+
+		return dnsProvider, nil
+	case ProviderSecretTypeCloudflare:
+		dnsProvider, err := cloudflare.NewProviderFromSecret(ctx, providerSecret)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create Cloudflare dns provider from secret: %w", err)
+		}
+		log.Log.V(1).Info("Cloudflare provider created", "managed zone:", managedZone.Name)
+
 		return dnsProvider, nil
 
 	default:
@@ -63,3 +124,40 @@ func (p *providerFactory) DNSProviderFactory(ctx context.Context, managedZone *v
 	}
 
 }
+
+// defaultCredentialsProvider returns the provider of a managed zone without a dnsprovider secret, which must set its
+// provider. Only the AWS provider supports credentials other than the ones in a secret, from the default AWS SDK
+// credential chain, e.g. IRSA on EKS.
+func (p *providerFactory) defaultCredentialsProvider(managedZone *v1alpha1.ManagedZone) (dns.Provider, error) {
+	switch managedZone.Spec.Provider {
+	case v1alpha1.DNSProviderTypeAWS:
+		dnsProvider, err := aws.NewProviderFromDefaultCredentials(p.route53RateLimiter, p.route53RecordSetCache, p.route53OwnerID, p.route53RecordPolicy, p.zoneFilter)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create AWS dns provider from the default credentials: %w", err)
+		}
+		log.Log.V(1).Info("Route53 provider created from the default credentials", "managed zone:", managedZone.Name)
+
+		return dnsProvider, nil
+	case "":
+		return nil, fmt.Errorf("%w: the provider must be set when no dnsProviderSecretRef is set", errMissingCredentials)
+	default:
+		return nil, fmt.Errorf("%w: provider %s requires a dnsProviderSecretRef", errMissingCredentials, managedZone.Spec.Provider)
+	}
+}
+
+// providerSecretType returns the provider secret type to use for the managed zone. The provider set in the managed
+// zone takes precedence over the type of the secret, in which case the secret must be of the matching type or the
+// default Opaque type.
+func providerSecretType(managedZone *v1alpha1.ManagedZone, providerSecret *v1.Secret) (v1.SecretType, error) {
+	if managedZone.Spec.Provider == "" {
+		return providerSecret.Type, nil
+	}
+	secretType, ok := providerSecretTypes[managedZone.Spec.Provider]
+	if !ok {
+		return "", errUnsupportedProvider
+	}
+	if providerSecret.Type != secretType && providerSecret.Type != v1.SecretTypeOpaque && providerSecret.Type != "" {
+		return "", fmt.Errorf("provider %s does not match dns provider secret type %s", managedZone.Spec.Provider, providerSecret.Type)
+	}
+	return secretType, nil
+}
@@ -0,0 +1,124 @@
+//go:build unit
+
+package dnsprovider
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/aws"
+)
+
+func TestProviderSecretType(t *testing.T) {
+	testCases := []struct {
+		name       string
+		provider   v1alpha1.DNSProviderType
+		secretType v1.SecretType
+		want       v1.SecretType
+		wantErr    bool
+	}{
+		{
+			name:       "provider not set uses secret type",
+			secretType: ProviderSecretTypeGoogle,
+			want:       ProviderSecretTypeGoogle,
+		},
+		{
+			name:       "google provider with opaque secret",
+			provider:   v1alpha1.DNSProviderTypeGoogle,
+			secretType: v1.SecretTypeOpaque,
+			want:       ProviderSecretTypeGoogle,
+		},
+		{
+			name:       "aws provider with matching secret type",
+			provider:   v1alpha1.DNSProviderTypeAWS,
+			secretType: ProviderSecretTypeAWS,
+			want:       ProviderSecretTypeAWS,
+		},
+		{
+			name:       "provider does not match secret type",
+			provider:   v1alpha1.DNSProviderTypeGoogle,
+			secretType: ProviderSecretTypeAWS,
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported provider",
+			provider:   "unknown",
+			secretType: v1.SecretTypeOpaque,
+			wantErr:    true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			managedZone := &v1alpha1.ManagedZone{
+				Spec: v1alpha1.ManagedZoneSpec{Provider: testCase.provider},
+			}
+			secret := &v1.Secret{Type: testCase.secretType}
+			got, err := providerSecretType(managedZone, secret)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("providerSecretType() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if got != testCase.want {
+				t.Errorf("providerSecretType() got = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestDNSProviderFactory_defaultCredentials(t *testing.T) {
+	// isolate the default AWS credential chain from the environment running the test, so that it finds no credentials
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE",
+		"AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	factory := NewProvider(fake.NewClientBuilder().Build(), aws.NewRateLimiter(aws.DefaultRoute53RequestsPerSecond), aws.NewRecordSetCache(aws.DefaultRecordSetCacheTTL), "", aws.RecordPolicyNone, dns.ZoneFilter{})
+
+	testCases := []struct {
+		name      string
+		provider  v1alpha1.DNSProviderType
+		secretRef *v1alpha1.SecretRef
+		wantErr   string
+	}{
+		{
+			name:     "aws provider without secret ref uses the default credential chain",
+			provider: v1alpha1.DNSProviderTypeAWS,
+			wantErr:  "NoCredentialProviders",
+		},
+		{
+			name:      "aws provider with empty secret ref uses the default credential chain",
+			provider:  v1alpha1.DNSProviderTypeAWS,
+			secretRef: &v1alpha1.SecretRef{},
+			wantErr:   "NoCredentialProviders",
+		},
+		{
+			name:    "provider not set without secret ref",
+			wantErr: errMissingCredentials.Error(),
+		},
+		{
+			name:     "google provider without secret ref",
+			provider: v1alpha1.DNSProviderTypeGoogle,
+			wantErr:  errMissingCredentials.Error(),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			managedZone := &v1alpha1.ManagedZone{
+				Spec: v1alpha1.ManagedZoneSpec{Provider: testCase.provider, SecretRef: testCase.secretRef},
+			}
+			_, err := factory.DNSProviderFactory(context.TODO(), managedZone)
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package dnsprovider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/aws"
+)
+
+// googleLocation matches the locations of Cloud DNS geo routing, which are Google Cloud regions, e.g. europe-west1
+var googleLocation = regexp.MustCompile(`^[a-z]+(-[a-z]+)+[0-9]+$`)
+
+// NewGeoCodeValidator returns a validator of geo codes against the provider of the managed zones, resolved as it is
+// when the provider is created. Any geo code is accepted for a managed zone whose provider cannot be resolved yet,
+// e.g. while its dnsprovider secret does not exist.
+func NewGeoCodeValidator(c client.Reader) v1alpha1.GeoCodeValidator {
+	return func(ctx context.Context, managedZone *v1alpha1.ManagedZone, geoCode string) error {
+		providerType, err := managedZoneProviderType(ctx, c, managedZone)
+		if err != nil {
+			return err
+		}
+		return validateGeoCode(providerType, geoCode)
+	}
+}
+
+// managedZoneProviderType returns the provider secret type of the managed zone, empty if it cannot be resolved
+func managedZoneProviderType(ctx context.Context, c client.Reader, managedZone *v1alpha1.ManagedZone) (v1.SecretType, error) {
+	if managedZone.Spec.SecretRef == nil || managedZone.Spec.SecretRef.Name == "" {
+		return providerSecretTypes[managedZone.Spec.Provider], nil
+	}
+	providerSecret := &v1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: managedZone.Spec.SecretRef.Namespace, Name: managedZone.Spec.SecretRef.Name}, providerSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	// a provider not matching its secret is reported on the managed zone
+	providerType, err := providerSecretType(managedZone, providerSecret)
+	if err != nil {
+		return "", nil
+	}
+	return providerType, nil
+}
+
+// validateGeoCode returns an error if the geo code is not accepted by the geo routing of the provider
+func validateGeoCode(providerType v1.SecretType, geoCode string) error {
+	switch providerType {
+	case ProviderSecretTypeAWS:
+		if dns.IsISO3166Alpha2Code(geoCode) || aws.IsContinentCode(geoCode) || dns.GeoCode(geoCode).IsWildcard() {
+			return nil
+		}
+		return fmt.Errorf("the geo codes of the AWS provider must be a continent or ISO 3166 country code")
+	case ProviderSecretTypeGoogle:
+		if googleLocation.MatchString(geoCode) || dns.GeoCode(geoCode).IsWildcard() {
+			return nil
+		}
+		return fmt.Errorf("the geo codes of the Google provider must be a Google Cloud region, e.g. europe-west1")
+	case ProviderSecretTypeAzure:
+		return fmt.Errorf("geo routing is not supported by the Azure provider")
+	case ProviderSecretTypeCloudflare:
+		return fmt.Errorf("geo routing is not supported by the Cloudflare provider")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+//go:build unit
+
+package dnsprovider
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestNewGeoCodeValidator(t *testing.T) {
+	secret := func(name string, secretType v1.SecretType) *v1.Secret {
+		return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}, Type: secretType}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		secret("aws-credentials", ProviderSecretTypeAWS),
+		secret("gcp-credentials", ProviderSecretTypeGoogle),
+		secret("azure-credentials", ProviderSecretTypeAzure),
+	).Build()
+
+	testCases := []struct {
+		name      string
+		provider  v1alpha1.DNSProviderType
+		secretRef string
+		geoCode   string
+		wantErr   bool
+	}{
+		{
+			name:      "aws country code",
+			secretRef: "aws-credentials",
+			geoCode:   "IE",
+		},
+		{
+			name:      "aws continent code",
+			secretRef: "aws-credentials",
+			geoCode:   "EU",
+		},
+		{
+			name:      "aws wildcard",
+			secretRef: "aws-credentials",
+			geoCode:   "*",
+		},
+		{
+			name:      "aws unrecognized code",
+			secretRef: "aws-credentials",
+			geoCode:   "europe-west1",
+			wantErr:   true,
+		},
+		{
+			name:     "aws from the provider of the managed zone",
+			provider: v1alpha1.DNSProviderTypeAWS,
+			geoCode:  "XX",
+			wantErr:  true,
+		},
+		{
+			name:      "google region",
+			secretRef: "gcp-credentials",
+			geoCode:   "europe-west1",
+		},
+		{
+			name:      "google country code",
+			secretRef: "gcp-credentials",
+			geoCode:   "IE",
+			wantErr:   true,
+		},
+		{
+			name:      "azure has no geo routing",
+			secretRef: "azure-credentials",
+			geoCode:   "IE",
+			wantErr:   true,
+		},
+		{
+			name:      "secret not created yet",
+			secretRef: "missing-credentials",
+			geoCode:   "europe-west1",
+		},
+		{
+			name:     "unknown provider",
+			provider: "unknown",
+			geoCode:  "europe-west1",
+		},
+	}
+	validate := NewGeoCodeValidator(c)
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			managedZone := &v1alpha1.ManagedZone{
+				ObjectMeta: metav1.ObjectMeta{Name: "example.com", Namespace: "test"},
+				Spec:       v1alpha1.ManagedZoneSpec{Provider: testCase.provider},
+			}
+			if testCase.secretRef != "" {
+				managedZone.Spec.SecretRef = &v1alpha1.SecretRef{Name: testCase.secretRef, Namespace: "test"}
+			}
+			err := validate(context.TODO(), managedZone, testCase.geoCode)
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("expected error %v, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
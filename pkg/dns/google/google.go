@@ -18,6 +18,7 @@ package google
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"sort"
@@ -146,6 +147,7 @@ type GoogleDNSProvider struct {
 }
 
 var _ dns.Provider = &GoogleDNSProvider{}
+var _ dns.ConnectivityChecker = &GoogleDNSProvider{}
 
 func NewProviderFromSecret(ctx context.Context, s *v1.Secret) (*GoogleDNSProvider, error) {
 
@@ -259,6 +261,20 @@ func (g *GoogleDNSProvider) ProviderSpecific() dns.ProviderSpecificLabels {
 	return dns.ProviderSpecificLabels{}
 }
 
+// errStopPaging stops listing the pages of a list call after the first page
+var errStopPaging = errors.New("stop paging")
+
+// CheckConnectivity implements dns.ConnectivityChecker by listing the first page of the managed zones of the project
+func (g *GoogleDNSProvider) CheckConnectivity() error {
+	err := g.managedZonesClient.List(g.project).Pages(g.ctx, func(*dnsv1.ManagedZonesListResponse) error {
+		return errStopPaging
+	})
+	if err != nil && !errors.Is(err, errStopPaging) {
+		return fmt.Errorf("%w: failed to list managed zones of project %s: %v", dns.ErrProviderUnreachable, g.project, err)
+	}
+	return nil
+}
+
 func (g *GoogleDNSProvider) updateRecord(dnsRecord *v1alpha1.DNSRecord, zoneID string, action action) error {
 	// When updating records the Google DNS API expects you to delete any existing record and add the new one as part of
 	// the same change request. The record to be deleted must match exactly what currently exists in the provider or the
@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const (
+	PlanActionCreate = "CREATE"
+	PlanActionUpdate = "UPDATE"
+	PlanActionDelete = "DELETE"
+)
+
+// Plan returns the record changes required to go from the current endpoints to the desired endpoints, one human
+// readable line per change (e.g. "CREATE test.example.com 60 IN A  [1.1.1.1] []"). Endpoints are matched by their
+// name, set identifier and record type. The changes are sorted so the plan is stable across reconciles.
+func Plan(current, desired []*v1alpha1.Endpoint) []string {
+	var plan []string
+	for _, change := range diffEndpoints(current, desired) {
+		if change.action == PlanActionDelete {
+			plan = append(plan, fmt.Sprintf("%s %s", change.action, change.current))
+			continue
+		}
+		plan = append(plan, fmt.Sprintf("%s %s", change.action, change.desired))
+	}
+	sort.Strings(plan)
+	return plan
+}
+
+// endpointChange is a change between the current and desired endpoints, current is nil for a created endpoint and
+// desired is nil for a deleted endpoint
+type endpointChange struct {
+	action  string
+	current *v1alpha1.Endpoint
+	desired *v1alpha1.Endpoint
+}
+
+// diffEndpoints returns the changes required to go from the current endpoints to the desired endpoints, in no
+// particular order
+func diffEndpoints(current, desired []*v1alpha1.Endpoint) []endpointChange {
+	currentEndpoints := map[string]*v1alpha1.Endpoint{}
+	for _, endpoint := range current {
+		currentEndpoints[planKey(endpoint)] = endpoint
+	}
+	desiredEndpoints := map[string]*v1alpha1.Endpoint{}
+	for _, endpoint := range desired {
+		desiredEndpoints[planKey(endpoint)] = endpoint
+	}
+
+	var changes []endpointChange
+	for key, endpoint := range desiredEndpoints {
+		currentEndpoint, ok := currentEndpoints[key]
+		if !ok {
+			changes = append(changes, endpointChange{action: PlanActionCreate, desired: endpoint})
+			continue
+		}
+		if !reflect.DeepEqual(currentEndpoint, endpoint) {
+			changes = append(changes, endpointChange{action: PlanActionUpdate, current: currentEndpoint, desired: endpoint})
+		}
+	}
+	for key, endpoint := range currentEndpoints {
+		if _, ok := desiredEndpoints[key]; !ok {
+			changes = append(changes, endpointChange{action: PlanActionDelete, current: endpoint})
+		}
+	}
+	return changes
+}
+
+func planKey(endpoint *v1alpha1.Endpoint) string {
+	return endpoint.SetID() + "/" + endpoint.RecordType
+}
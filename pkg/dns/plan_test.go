@@ -0,0 +1,66 @@
+//go:build unit
+
+package dns
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestPlan(t *testing.T) {
+	endpoint := func(dnsName, setIdentifier string, targets ...string) *v1alpha1.Endpoint {
+		return &v1alpha1.Endpoint{
+			DNSName:       dnsName,
+			Targets:       targets,
+			RecordType:    string(v1alpha1.ARecordType),
+			SetIdentifier: setIdentifier,
+			RecordTTL:     60,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		current  []*v1alpha1.Endpoint
+		desired  []*v1alpha1.Endpoint
+		wantPlan []string
+	}{
+		{
+			name:    "new endpoints are created",
+			desired: []*v1alpha1.Endpoint{endpoint("test.example.com", "", "1.1.1.1")},
+			wantPlan: []string{
+				"CREATE test.example.com 60 IN A  [1.1.1.1] []",
+			},
+		},
+		{
+			name:     "unchanged endpoints are not planned",
+			current:  []*v1alpha1.Endpoint{endpoint("test.example.com", "", "1.1.1.1")},
+			desired:  []*v1alpha1.Endpoint{endpoint("test.example.com", "", "1.1.1.1")},
+			wantPlan: nil,
+		},
+		{
+			name: "changed, removed and added endpoints are planned",
+			current: []*v1alpha1.Endpoint{
+				endpoint("test.example.com", "a", "1.1.1.1"),
+				endpoint("test.example.com", "b", "2.2.2.2"),
+			},
+			desired: []*v1alpha1.Endpoint{
+				endpoint("test.example.com", "a", "3.3.3.3"),
+				endpoint("test.example.com", "c", "4.4.4.4"),
+			},
+			wantPlan: []string{
+				"CREATE test.example.com 60 IN A c [4.4.4.4] []",
+				"DELETE test.example.com 60 IN A b [2.2.2.2] []",
+				"UPDATE test.example.com 60 IN A a [3.3.3.3] []",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := Plan(testCase.current, testCase.desired); !reflect.DeepEqual(got, testCase.wantPlan) {
+				t.Errorf("Plan() = %v, want %v", got, testCase.wantPlan)
+			}
+		})
+	}
+}
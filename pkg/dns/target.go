@@ -3,10 +3,13 @@ package dns
 import (
 	"crypto/sha256"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/martinlindhe/base36"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
@@ -15,12 +18,18 @@ import (
 )
 
 const (
-	DefaultWeight                   = int(v1alpha1.DefaultWeight)
-	DefaultGeo              GeoCode = "default"
-	WildcardGeo             GeoCode = "*"
-	LabelLBAttributeGeoCode         = "kuadrant.io/lb-attribute-geo-code"
+	DefaultWeight                            = int(v1alpha1.DefaultWeight)
+	DefaultGeo                       GeoCode = "default"
+	WildcardGeo                      GeoCode = "*"
+	LabelLBAttributeGeoCode                  = "kuadrant.io/lb-attribute-geo-code"
+	AnnotationLBAttributeGeoCode             = "kuadrant.io/lb-attribute-geo-code"
+	AnnotationLBAttributeRegion              = "kuadrant.io/lb-attribute-region"
+	AnnotationLBAttributeCoordinates         = "kuadrant.io/lb-attribute-coordinates"
 )
 
+// coordinateRegex matches a latitude or longitude in decimal degrees with at most two decimals, as accepted by Route53
+var coordinateRegex = regexp.MustCompile(`^-?[0-9]{1,3}(\.[0-9]{1,2})?$`)
+
 // MultiClusterGatewayTarget represents a Gateway that is placed on multiple clusters (ClusterGateway).
 type MultiClusterGatewayTarget struct {
 	Gateway               *gatewayv1beta1.Gateway
@@ -58,6 +67,22 @@ func (t *MultiClusterGatewayTarget) GetDefaultGeo() GeoCode {
 	return DefaultGeo
 }
 
+// GetRegionGeoCodes returns the mapping of cluster regions to geo codes, empty when geo load balancing is not requested
+func (t *MultiClusterGatewayTarget) GetRegionGeoCodes() []v1alpha1.RegionGeoCode {
+	if t.LoadBalancing != nil && t.LoadBalancing.Geo != nil {
+		return t.LoadBalancing.Geo.RegionGeoCodes
+	}
+	return nil
+}
+
+// GetGeoFallback returns the ordered fallback geo codes of each geo code, empty when geo load balancing is not requested
+func (t *MultiClusterGatewayTarget) GetGeoFallback() map[string][]string {
+	if t.LoadBalancing != nil && t.LoadBalancing.Geo != nil {
+		return t.LoadBalancing.Geo.GeoFallback
+	}
+	return nil
+}
+
 func (t *MultiClusterGatewayTarget) GetDefaultWeight() int {
 	if t.LoadBalancing != nil && t.LoadBalancing.Weighted != nil {
 		return int(t.LoadBalancing.Weighted.DefaultWeight)
@@ -65,6 +90,77 @@ func (t *MultiClusterGatewayTarget) GetDefaultWeight() int {
 	return DefaultWeight
 }
 
+// IsLatencyRouting returns true if latency routing is requested and every target cluster has a region set. If any
+// target cluster is missing a region, weighted routing is used instead.
+func (t *MultiClusterGatewayTarget) IsLatencyRouting() bool {
+	if t.LoadBalancing == nil || t.LoadBalancing.Latency == nil {
+		return false
+	}
+	return len(t.ClustersWithoutRegion()) == 0
+}
+
+// ClustersWithoutRegion returns the names of the target clusters that have no region set.
+func (t *MultiClusterGatewayTarget) ClustersWithoutRegion() []string {
+	var clusters []string
+	for _, target := range t.ClusterGatewayTargets {
+		if target.GetRegion() == "" {
+			clusters = append(clusters, target.GetName())
+		}
+	}
+	return clusters
+}
+
+// IsGeoproximityRouting returns true if geoproximity routing is requested and every target cluster has a location
+// set. If any target cluster is missing a location, weighted routing is used instead.
+func (t *MultiClusterGatewayTarget) IsGeoproximityRouting() bool {
+	if t.LoadBalancing == nil || t.LoadBalancing.Geoproximity == nil {
+		return false
+	}
+	return len(t.ClustersWithoutLocation()) == 0
+}
+
+// ClustersWithoutLocation returns the names of the target clusters that have neither valid coordinates nor a region
+// set.
+func (t *MultiClusterGatewayTarget) ClustersWithoutLocation() []string {
+	var clusters []string
+	for _, target := range t.ClusterGatewayTargets {
+		if _, _, ok := target.GetCoordinates(); !ok && target.GetRegion() == "" {
+			clusters = append(clusters, target.GetName())
+		}
+	}
+	return clusters
+}
+
+// ClustersWithDefaultGeo returns the names of the target clusters that have no geo code set or mapped from their
+// region and were assigned the default geo. It is empty when geo load balancing is not requested.
+func (t *MultiClusterGatewayTarget) ClustersWithDefaultGeo() []string {
+	var clusters []string
+	for _, clusterGeo := range t.ClusterGeos() {
+		if clusterGeo.Source == v1alpha1.GeoCodeSourceDefault {
+			clusters = append(clusters, clusterGeo.Cluster)
+		}
+	}
+	return clusters
+}
+
+// ClusterGeos returns the geo code of each target cluster and where it is resolved from. It is empty when geo load
+// balancing is not requested.
+func (t *MultiClusterGatewayTarget) ClusterGeos() []v1alpha1.ClusterGeo {
+	if t.GetDefaultGeo().IsDefaultCode() {
+		return nil
+	}
+	var clusterGeos []v1alpha1.ClusterGeo
+	for _, target := range t.ClusterGatewayTargets {
+		geoCode, source := resolveGeo(target.Cluster, t.GetDefaultGeo(), t.GetRegionGeoCodes())
+		clusterGeos = append(clusterGeos, v1alpha1.ClusterGeo{
+			Cluster: target.GetName(),
+			GeoCode: string(geoCode),
+			Source:  source,
+		})
+	}
+	return clusterGeos
+}
+
 func (t *MultiClusterGatewayTarget) setClusterGatewayTargets(clusterGateways []ClusterGateway) error {
 	var cgTargets []ClusterGatewayTarget
 	for _, cg := range clusterGateways {
@@ -72,10 +168,15 @@ func (t *MultiClusterGatewayTarget) setClusterGatewayTargets(clusterGateways []C
 		if t.LoadBalancing != nil && t.LoadBalancing.Weighted != nil {
 			customWeights = t.LoadBalancing.Weighted.Custom
 		}
-		cgt, err := NewClusterGatewayTarget(cg, t.GetDefaultGeo(), t.GetDefaultWeight(), customWeights)
+		cgt, err := NewClusterGatewayTarget(cg, t.GetDefaultGeo(), t.GetRegionGeoCodes(), t.GetDefaultWeight(), customWeights)
 		if err != nil {
 			return err
 		}
+		if t.LoadBalancing != nil && t.LoadBalancing.Geoproximity != nil {
+			if err := cgt.setBias(t.LoadBalancing.Geoproximity.DefaultBias, t.LoadBalancing.Geoproximity.Custom); err != nil {
+				return err
+			}
+		}
 		cgTargets = append(cgTargets, cgt)
 	}
 	t.ClusterGatewayTargets = cgTargets
@@ -111,13 +212,15 @@ type ClusterGatewayTarget struct {
 	*ClusterGateway
 	Geo    *GeoCode
 	Weight *int
+	// Bias is the geoproximity bias of the cluster, nil when geoproximity load balancing is not requested
+	Bias *int
 }
 
-func NewClusterGatewayTarget(cg ClusterGateway, defaultGeoCode GeoCode, defaultWeight int, customWeights []*v1alpha1.CustomWeight) (ClusterGatewayTarget, error) {
+func NewClusterGatewayTarget(cg ClusterGateway, defaultGeoCode GeoCode, regionGeoCodes []v1alpha1.RegionGeoCode, defaultWeight int, customWeights []*v1alpha1.CustomWeight) (ClusterGatewayTarget, error) {
 	target := ClusterGatewayTarget{
 		ClusterGateway: &cg,
 	}
-	target.setGeo(defaultGeoCode)
+	target.setGeo(defaultGeoCode, regionGeoCodes)
 	err := target.setWeight(defaultWeight, customWeights)
 	if err != nil {
 		return ClusterGatewayTarget{}, err
@@ -133,6 +236,33 @@ func (t *ClusterGatewayTarget) GetWeight() int {
 	return *t.Weight
 }
 
+// GetRegion returns the region of the target cluster, or an empty string if it has none.
+func (t *ClusterGatewayTarget) GetRegion() string {
+	return t.Cluster.GetAnnotations()[AnnotationLBAttributeRegion]
+}
+
+// GetCoordinates returns the latitude and longitude of the target cluster, ok is false if it has none or they are not
+// valid coordinates.
+func (t *ClusterGatewayTarget) GetCoordinates() (latitude, longitude string, ok bool) {
+	coordinates, found := t.Cluster.GetAnnotations()[AnnotationLBAttributeCoordinates]
+	if !found {
+		return "", "", false
+	}
+	latitude, longitude, found = strings.Cut(strings.ReplaceAll(coordinates, " ", ""), ",")
+	if !found || !validCoordinate(latitude, 90) || !validCoordinate(longitude, 180) {
+		return "", "", false
+	}
+	return latitude, longitude, true
+}
+
+// GetBias returns the geoproximity bias of the target cluster, 0 when geoproximity load balancing is not requested.
+func (t *ClusterGatewayTarget) GetBias() int {
+	if t.Bias == nil {
+		return 0
+	}
+	return *t.Bias
+}
+
 func (t *ClusterGatewayTarget) GetName() string {
 	return t.Cluster.GetName()
 }
@@ -141,16 +271,32 @@ func (t *ClusterGatewayTarget) GetShortCode() string {
 	return ToBase36hash(t.GetName())
 }
 
-func (t *ClusterGatewayTarget) setGeo(defaultGeo GeoCode) {
-	geoCode := defaultGeo
-	if geoCode == DefaultGeo {
-		t.Geo = &geoCode
-		return
+func (t *ClusterGatewayTarget) setGeo(defaultGeo GeoCode, regionGeoCodes []v1alpha1.RegionGeoCode) {
+	geoCode, _ := resolveGeo(t.Cluster, defaultGeo, regionGeoCodes)
+	t.Geo = &geoCode
+}
+
+// resolveGeo returns the geo code of the cluster and where it is resolved from. A geo code set explicitly on the
+// cluster, by annotation first and label second, takes precedence over the geo code mapped from the region label of
+// the cluster, and the default geo is used when there is neither.
+func resolveGeo(cluster metav1.Object, defaultGeo GeoCode, regionGeoCodes []v1alpha1.RegionGeoCode) (GeoCode, v1alpha1.GeoCodeSource) {
+	if defaultGeo.IsDefaultCode() {
+		return defaultGeo, v1alpha1.GeoCodeSourceDefault
 	}
-	if gc, ok := t.Cluster.GetLabels()[LabelLBAttributeGeoCode]; ok {
-		geoCode = GeoCode(gc)
+	if gc, ok := cluster.GetAnnotations()[AnnotationLBAttributeGeoCode]; ok {
+		return GeoCode(gc), v1alpha1.GeoCodeSourceAnnotation
 	}
-	t.Geo = &geoCode
+	if gc, ok := cluster.GetLabels()[LabelLBAttributeGeoCode]; ok {
+		return GeoCode(gc), v1alpha1.GeoCodeSourceLabel
+	}
+	if region, ok := cluster.GetLabels()[corev1.LabelTopologyRegion]; ok {
+		for _, regionGeoCode := range regionGeoCodes {
+			if regionGeoCode.Region == region {
+				return GeoCode(regionGeoCode.GeoCode), v1alpha1.GeoCodeSourceRegion
+			}
+		}
+	}
+	return defaultGeo, v1alpha1.GeoCodeSourceDefault
 }
 
 func (t *ClusterGatewayTarget) setWeight(defaultWeight int, customWeights []*v1alpha1.CustomWeight) error {
@@ -171,6 +317,31 @@ func (t *ClusterGatewayTarget) setWeight(defaultWeight int, customWeights []*v1a
 	return nil
 }
 
+func (t *ClusterGatewayTarget) setBias(defaultBias v1alpha1.Bias, customBiases []*v1alpha1.CustomBias) error {
+	bias := int(defaultBias)
+	for _, cb := range customBiases {
+		selector, err := metav1.LabelSelectorAsSelector(cb.Selector)
+		if err != nil {
+			return err
+		}
+		if selector.Matches(labels.Set(t.Cluster.GetLabels())) {
+			bias = int(cb.Bias)
+			break
+		}
+	}
+	t.Bias = &bias
+	return nil
+}
+
+// validCoordinate returns true if the value is a number of decimal degrees within the limit
+func validCoordinate(value string, limit float64) bool {
+	if !coordinateRegex.MatchString(value) {
+		return false
+	}
+	degrees, err := strconv.ParseFloat(value, 64)
+	return err == nil && degrees >= -limit && degrees <= limit
+}
+
 func ToBase36hash(s string) string {
 	hash := sha256.Sum224([]byte(s))
 	// convert the hash to base36 (alphanumeric) to decrease collision probabilities
@@ -114,7 +114,7 @@ func TestNewClusterGatewayTarget(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			t.Run(testCase.name, func(t *testing.T) {
-				got, err := NewClusterGatewayTarget(testCase.args.clusterGateway, testCase.args.defaultGeoCode, testCase.args.defaultWeight, testCase.args.customWeights)
+				got, err := NewClusterGatewayTarget(testCase.args.clusterGateway, testCase.args.defaultGeoCode, nil, testCase.args.defaultWeight, testCase.args.customWeights)
 				if (err != nil) != testCase.wantErr {
 					t.Errorf("NewClusterGatewayTarget() error = %v, wantErr %v", err, testCase.wantErr)
 					return
@@ -414,17 +414,25 @@ func TestToBase36hash(t *testing.T) {
 }
 
 func TestClusterGatewayTarget_setGeo(t *testing.T) {
+	regionGeoCodes := []v1alpha1.RegionGeoCode{
+		{Region: "eu-west-1", GeoCode: "IE"},
+		{Region: "us-east-1", GeoCode: "US"},
+	}
 	testCases := []struct {
-		name          string
-		defaultGeo    GeoCode
-		clusterLabels map[string]string
-		want          GeoCode
+		name               string
+		defaultGeo         GeoCode
+		regionGeoCodes     []v1alpha1.RegionGeoCode
+		clusterLabels      map[string]string
+		clusterAnnotations map[string]string
+		want               GeoCode
+		wantSource         v1alpha1.GeoCodeSource
 	}{
 		{
 			name:          "sets geo from default",
 			defaultGeo:    "IE",
 			clusterLabels: nil,
 			want:          "IE",
+			wantSource:    v1alpha1.GeoCodeSourceDefault,
 		},
 		{
 			name:       "sets geo from label",
@@ -432,7 +440,8 @@ func TestClusterGatewayTarget_setGeo(t *testing.T) {
 			clusterLabels: map[string]string{
 				"kuadrant.io/lb-attribute-geo-code": "EU",
 			},
-			want: "EU",
+			want:       "EU",
+			wantSource: v1alpha1.GeoCodeSourceLabel,
 		},
 		{
 			name:       "sets geo to default for default geo value",
@@ -440,7 +449,53 @@ func TestClusterGatewayTarget_setGeo(t *testing.T) {
 			clusterLabels: map[string]string{
 				"kuadrant.io/lb-attribute-geo-code": "EU",
 			},
-			want: "default",
+			want:       "default",
+			wantSource: v1alpha1.GeoCodeSourceDefault,
+		},
+		{
+			name:           "sets geo from region label",
+			defaultGeo:     "IE",
+			regionGeoCodes: regionGeoCodes,
+			clusterLabels: map[string]string{
+				"topology.kubernetes.io/region": "us-east-1",
+			},
+			want:       "US",
+			wantSource: v1alpha1.GeoCodeSourceRegion,
+		},
+		{
+			name:           "sets geo from default for unmapped region",
+			defaultGeo:     "IE",
+			regionGeoCodes: regionGeoCodes,
+			clusterLabels: map[string]string{
+				"topology.kubernetes.io/region": "ap-south-1",
+			},
+			want:       "IE",
+			wantSource: v1alpha1.GeoCodeSourceDefault,
+		},
+		{
+			name:           "sets geo from label over region label",
+			defaultGeo:     "IE",
+			regionGeoCodes: regionGeoCodes,
+			clusterLabels: map[string]string{
+				"topology.kubernetes.io/region":     "us-east-1",
+				"kuadrant.io/lb-attribute-geo-code": "EU",
+			},
+			want:       "EU",
+			wantSource: v1alpha1.GeoCodeSourceLabel,
+		},
+		{
+			name:           "sets geo from annotation over label and region label",
+			defaultGeo:     "IE",
+			regionGeoCodes: regionGeoCodes,
+			clusterLabels: map[string]string{
+				"topology.kubernetes.io/region":     "us-east-1",
+				"kuadrant.io/lb-attribute-geo-code": "EU",
+			},
+			clusterAnnotations: map[string]string{
+				"kuadrant.io/lb-attribute-geo-code": "ES",
+			},
+			want:       "ES",
+			wantSource: v1alpha1.GeoCodeSourceAnnotation,
 		},
 	}
 	for _, testCase := range testCases {
@@ -449,17 +504,21 @@ func TestClusterGatewayTarget_setGeo(t *testing.T) {
 				ClusterGateway: &ClusterGateway{
 					Cluster: &testutil.TestResource{
 						ObjectMeta: v1.ObjectMeta{
-							Name:   clusterName1,
-							Labels: testCase.clusterLabels,
+							Name:        clusterName1,
+							Labels:      testCase.clusterLabels,
+							Annotations: testCase.clusterAnnotations,
 						},
 					},
 					GatewayAddresses: buildGatewayAddress(testAddress1),
 				},
 			}
-			cgt.setGeo(testCase.defaultGeo)
+			cgt.setGeo(testCase.defaultGeo, testCase.regionGeoCodes)
 			if got := *cgt.Geo; got != testCase.want {
 				t.Errorf("setGeo() got = %v, want %v", got, testCase.want)
 			}
+			if _, source := resolveGeo(cgt.Cluster, testCase.defaultGeo, testCase.regionGeoCodes); source != testCase.wantSource {
+				t.Errorf("resolveGeo() got source = %v, want %v", source, testCase.wantSource)
+			}
 		})
 	}
 }
@@ -596,3 +655,248 @@ func buildGatewayAddress(value string) []gatewayv1beta1.GatewayAddress {
 		},
 	}
 }
+
+func TestMultiClusterGatewayTarget_IsLatencyRouting(t *testing.T) {
+	testCases := []struct {
+		name                      string
+		loadBalancing             *v1alpha1.LoadBalancingSpec
+		clusterAnnotations        []map[string]string
+		want                      bool
+		wantClustersWithoutRegion []string
+	}{
+		{
+			name:          "latency not requested",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{},
+			clusterAnnotations: []map[string]string{
+				{"kuadrant.io/lb-attribute-region": "eu-west-1"},
+				{"kuadrant.io/lb-attribute-region": "us-east-1"},
+			},
+			want: false,
+		},
+		{
+			name: "latency requested and all clusters have a region",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{
+				Latency: &v1alpha1.LoadBalancingLatency{},
+			},
+			clusterAnnotations: []map[string]string{
+				{"kuadrant.io/lb-attribute-region": "eu-west-1"},
+				{"kuadrant.io/lb-attribute-region": "us-east-1"},
+			},
+			want: true,
+		},
+		{
+			name: "latency requested and a cluster has no region",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{
+				Latency: &v1alpha1.LoadBalancingLatency{},
+			},
+			clusterAnnotations: []map[string]string{
+				{"kuadrant.io/lb-attribute-region": "eu-west-1"},
+				nil,
+			},
+			want:                      false,
+			wantClustersWithoutRegion: []string{clusterName2},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mcg := &MultiClusterGatewayTarget{LoadBalancing: testCase.loadBalancing}
+			for i, name := range []string{clusterName1, clusterName2} {
+				mcg.ClusterGatewayTargets = append(mcg.ClusterGatewayTargets, ClusterGatewayTarget{
+					ClusterGateway: &ClusterGateway{
+						Cluster: &testutil.TestResource{
+							ObjectMeta: v1.ObjectMeta{
+								Name:        name,
+								Annotations: testCase.clusterAnnotations[i],
+							},
+						},
+						GatewayAddresses: buildGatewayAddress(testAddress1),
+					},
+				})
+			}
+			if got := mcg.IsLatencyRouting(); got != testCase.want {
+				t.Errorf("IsLatencyRouting() got = %v, want %v", got, testCase.want)
+			}
+			if got := mcg.ClustersWithoutRegion(); !reflect.DeepEqual(got, testCase.wantClustersWithoutRegion) {
+				t.Errorf("ClustersWithoutRegion() got = %v, want %v", got, testCase.wantClustersWithoutRegion)
+			}
+		})
+	}
+}
+
+func TestMultiClusterGatewayTarget_IsGeoproximityRouting(t *testing.T) {
+	geoproximity := &v1alpha1.LoadBalancingSpec{
+		Geoproximity: &v1alpha1.LoadBalancingGeoproximity{
+			DefaultBias: -10,
+			Custom: []*v1alpha1.CustomBias{
+				{Selector: &v1.LabelSelector{MatchLabels: map[string]string{"tier": "primary"}}, Bias: 50},
+			},
+		},
+	}
+	testCases := []struct {
+		name                        string
+		loadBalancing               *v1alpha1.LoadBalancingSpec
+		clusterAnnotations          []map[string]string
+		want                        bool
+		wantClustersWithoutLocation []string
+		wantBiases                  []int
+	}{
+		{
+			name:          "geoproximity not requested",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{},
+			clusterAnnotations: []map[string]string{
+				{"kuadrant.io/lb-attribute-region": "eu-west-1"},
+				{"kuadrant.io/lb-attribute-region": "us-east-1"},
+			},
+			want:       false,
+			wantBiases: []int{0, 0},
+		},
+		{
+			name:          "geoproximity requested and all clusters have a location",
+			loadBalancing: geoproximity,
+			clusterAnnotations: []map[string]string{
+				{"kuadrant.io/lb-attribute-coordinates": "53.35, -6.26"},
+				{"kuadrant.io/lb-attribute-region": "us-east-1"},
+			},
+			want:       true,
+			wantBiases: []int{50, -10},
+		},
+		{
+			name:          "geoproximity requested and a cluster has no location",
+			loadBalancing: geoproximity,
+			clusterAnnotations: []map[string]string{
+				{"kuadrant.io/lb-attribute-region": "eu-west-1"},
+				nil,
+			},
+			want:                        false,
+			wantClustersWithoutLocation: []string{clusterName2},
+			wantBiases:                  []int{50, -10},
+		},
+		{
+			name:          "geoproximity requested and a cluster has invalid coordinates",
+			loadBalancing: geoproximity,
+			clusterAnnotations: []map[string]string{
+				{"kuadrant.io/lb-attribute-region": "eu-west-1"},
+				{"kuadrant.io/lb-attribute-coordinates": "95.00,-6.26"},
+			},
+			want:                        false,
+			wantClustersWithoutLocation: []string{clusterName2},
+			wantBiases:                  []int{50, -10},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var clusterGateways []ClusterGateway
+			for i, name := range []string{clusterName1, clusterName2} {
+				var clusterLabels map[string]string
+				if i == 0 {
+					clusterLabels = map[string]string{"tier": "primary"}
+				}
+				clusterGateways = append(clusterGateways, ClusterGateway{
+					Cluster: &testutil.TestResource{
+						ObjectMeta: v1.ObjectMeta{
+							Name:        name,
+							Labels:      clusterLabels,
+							Annotations: testCase.clusterAnnotations[i],
+						},
+					},
+					GatewayAddresses: buildGatewayAddress(testAddress1),
+				})
+			}
+			mcg, err := NewMultiClusterGatewayTarget(&gatewayv1beta1.Gateway{}, clusterGateways, testCase.loadBalancing)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got := mcg.IsGeoproximityRouting(); got != testCase.want {
+				t.Errorf("IsGeoproximityRouting() got = %v, want %v", got, testCase.want)
+			}
+			if got := mcg.ClustersWithoutLocation(); !reflect.DeepEqual(got, testCase.wantClustersWithoutLocation) {
+				t.Errorf("ClustersWithoutLocation() got = %v, want %v", got, testCase.wantClustersWithoutLocation)
+			}
+			var biases []int
+			for _, target := range mcg.ClusterGatewayTargets {
+				biases = append(biases, target.GetBias())
+			}
+			if !reflect.DeepEqual(biases, testCase.wantBiases) {
+				t.Errorf("GetBias() got = %v, want %v", biases, testCase.wantBiases)
+			}
+		})
+	}
+}
+
+func TestMultiClusterGatewayTarget_ClustersWithDefaultGeo(t *testing.T) {
+	testCases := []struct {
+		name          string
+		loadBalancing *v1alpha1.LoadBalancingSpec
+		clusterLabels []map[string]string
+		want          []string
+	}{
+		{
+			name:          "geo not requested",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{},
+			clusterLabels: []map[string]string{nil, nil},
+		},
+		{
+			name: "geo requested and all clusters have a geo code",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{DefaultGeo: "IE"},
+			},
+			clusterLabels: []map[string]string{
+				{"kuadrant.io/lb-attribute-geo-code": "IE"},
+				{"kuadrant.io/lb-attribute-geo-code": "EU"},
+			},
+		},
+		{
+			name: "geo requested and a cluster has no geo code",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{DefaultGeo: "IE"},
+			},
+			clusterLabels: []map[string]string{
+				{"kuadrant.io/lb-attribute-geo-code": "EU"},
+				nil,
+			},
+			want: []string{clusterName2},
+		},
+		{
+			name: "geo requested and a cluster geo code is mapped from its region",
+			loadBalancing: &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo:     "IE",
+					RegionGeoCodes: []v1alpha1.RegionGeoCode{{Region: "us-east-1", GeoCode: "US"}},
+				},
+			},
+			clusterLabels: []map[string]string{
+				{"topology.kubernetes.io/region": "us-east-1"},
+				{"topology.kubernetes.io/region": "ap-south-1"},
+			},
+			want: []string{clusterName2},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var clusterGateways []ClusterGateway
+			for i, name := range []string{clusterName1, clusterName2} {
+				clusterGateways = append(clusterGateways, ClusterGateway{
+					Cluster: &testutil.TestResource{
+						ObjectMeta: v1.ObjectMeta{
+							Name:   name,
+							Labels: testCase.clusterLabels[i],
+						},
+					},
+					GatewayAddresses: buildGatewayAddress(testAddress1),
+				})
+			}
+			mcg, err := NewMultiClusterGatewayTarget(&gatewayv1beta1.Gateway{}, clusterGateways, testCase.loadBalancing)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got := mcg.ClustersWithDefaultGeo(); !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("ClustersWithDefaultGeo() got = %v, want %v", got, testCase.want)
+			}
+			for _, target := range mcg.ClusterGatewayTargets {
+				if target.GetGeo() == "" {
+					t.Errorf("expected cluster %s to have a geo code", target.GetName())
+				}
+			}
+		})
+	}
+}
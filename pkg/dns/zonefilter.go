@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// ErrZoneNotAllowed is returned when a zone is outside the zones the controller is allowed to manage
+var ErrZoneNotAllowed = errors.New("zone is not allowed by the zone filters")
+
+// ZoneFilter restricts the zones managed by the controller to an allowlist of zone IDs and domains. A zone is allowed
+// if it matches both lists, an empty list matches any zone.
+type ZoneFilter struct {
+	ZoneIDs []string
+	Domains []string
+}
+
+// NewZoneFilter returns a ZoneFilter from comma separated lists of zone IDs and domains
+func NewZoneFilter(zoneIDs, domains string) ZoneFilter {
+	return ZoneFilter{
+		ZoneIDs: splitFilterList(zoneIDs, func(id string) string { return strings.TrimPrefix(id, "/hostedzone/") }),
+		Domains: splitFilterList(domains, func(domain string) string { return strings.ToLower(strings.Trim(domain, ".")) }),
+	}
+}
+
+func splitFilterList(list string, normalize func(string) string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, normalize(value))
+		}
+	}
+	return values
+}
+
+// IsEmpty returns true if the filter allows any zone
+func (f ZoneFilter) IsEmpty() bool {
+	return len(f.ZoneIDs) == 0 && len(f.Domains) == 0
+}
+
+// Match returns true if the zone ID and domain are allowed. A zone without an ID, e.g. one that is not created yet,
+// is not allowed by a zone ID filter.
+func (f ZoneFilter) Match(zoneID, domain string) bool {
+	return f.matchZoneID(zoneID) && f.matchDomain(domain)
+}
+
+func (f ZoneFilter) matchZoneID(zoneID string) bool {
+	if len(f.ZoneIDs) == 0 {
+		return true
+	}
+	zoneID = strings.TrimPrefix(zoneID, "/hostedzone/")
+	for _, id := range f.ZoneIDs {
+		if id == zoneID {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDomain returns true if the domain is one of the filter domains, or a subdomain of one of them
+func (f ZoneFilter) matchDomain(domain string) bool {
+	if len(f.Domains) == 0 {
+		return true
+	}
+	domain = strings.ToLower(strings.Trim(domain, "."))
+	for _, filterDomain := range f.Domains {
+		if domain == filterDomain || strings.HasSuffix(domain, "."+filterDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error wrapping ErrZoneNotAllowed if the managed zone is not allowed by the filter. The zone ID
+// is taken from the spec, or from the status once the zone is created.
+func (f ZoneFilter) Validate(managedZone *v1alpha1.ManagedZone) error {
+	zoneID := managedZone.Spec.ID
+	if zoneID == "" {
+		zoneID = managedZone.Status.ID
+	}
+	if !f.Match(zoneID, managedZone.Spec.DomainName) {
+		return fmt.Errorf("%w: managed zone %s (id %q, domain %s)", ErrZoneNotAllowed, managedZone.Name, zoneID, managedZone.Spec.DomainName)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+//go:build unit
+
+package dns
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestZoneFilter_Validate(t *testing.T) {
+	managedZone := func(specID, statusID, domain string) *v1alpha1.ManagedZone {
+		return &v1alpha1.ManagedZone{
+			Spec:   v1alpha1.ManagedZoneSpec{ID: specID, DomainName: domain},
+			Status: v1alpha1.ManagedZoneStatus{ID: statusID},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		zoneIDs     string
+		domains     string
+		managedZone *v1alpha1.ManagedZone
+		wantAllowed bool
+	}{
+		{
+			name:        "empty filter allows any zone",
+			managedZone: managedZone("", "", "example.com"),
+			wantAllowed: true,
+		},
+		{
+			name:        "allows a listed zone ID",
+			zoneIDs:     "Z1, Z2",
+			managedZone: managedZone("", "/hostedzone/Z2", "example.com"),
+			wantAllowed: true,
+		},
+		{
+			name:        "allows a listed zone ID from the spec",
+			zoneIDs:     "/hostedzone/Z1",
+			managedZone: managedZone("Z1", "", "example.com"),
+			wantAllowed: true,
+		},
+		{
+			name:        "refuses an unlisted zone ID",
+			zoneIDs:     "Z1",
+			managedZone: managedZone("", "Z3", "example.com"),
+		},
+		{
+			name:        "refuses a zone without an ID",
+			zoneIDs:     "Z1",
+			managedZone: managedZone("", "", "example.com"),
+		},
+		{
+			name:        "allows a listed domain",
+			domains:     "example.com.,example.org",
+			managedZone: managedZone("", "", "Example.com"),
+			wantAllowed: true,
+		},
+		{
+			name:        "allows a subdomain of a listed domain",
+			domains:     "example.com",
+			managedZone: managedZone("", "", "dev.example.com"),
+			wantAllowed: true,
+		},
+		{
+			name:        "refuses a domain sharing a suffix",
+			domains:     "example.com",
+			managedZone: managedZone("", "", "myexample.com"),
+		},
+		{
+			name:        "refuses a zone matching only one of the lists",
+			zoneIDs:     "Z1",
+			domains:     "example.com",
+			managedZone: managedZone("Z1", "", "example.org"),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := NewZoneFilter(testCase.zoneIDs, testCase.domains).Validate(testCase.managedZone)
+			if testCase.wantAllowed && err != nil {
+				t.Errorf("expected zone to be allowed, got %v", err)
+			}
+			if !testCase.wantAllowed && !errors.Is(err, ErrZoneNotAllowed) {
+				t.Errorf("expected zone not to be allowed, got %v", err)
+			}
+		})
+	}
+}
@@ -36,7 +36,13 @@ func (m *Monitor) Start(ctx context.Context) error {
 	return nil
 }
 
+// NeedLeaderElection makes the monitor wait for the leader election, so that only the leader probes the endpoints
+func (m *Monitor) NeedLeaderElection() bool {
+	return true
+}
+
 var _ manager.Runnable = &Monitor{}
+var _ manager.LeaderElectionRunnable = &Monitor{}
 
 func (m *Monitor) HasProbe(id string) bool {
 	m.mux.Lock()
@@ -23,6 +23,8 @@ type ProbeQueuer struct {
 	AdditionalHeaders        v1alpha1.AdditionalHeaders
 	ExpectedResponses        []int
 	AllowInsecureCertificate bool
+	TLSServerName            string
+	ExpectedBody             string
 
 	Notifier ProbeNotifier
 	Queue    *QueuedProbeWorker
@@ -33,10 +35,11 @@ type ProbeQueuer struct {
 }
 
 type ProbeResult struct {
-	CheckedAt time.Time
-	Reason    string
-	Status    int
-	Healthy   bool
+	CheckedAt   time.Time
+	Reason      string
+	Status      int
+	Healthy     bool
+	FailureType v1alpha1.ProbeFailureType
 }
 
 type ProbeNotifier interface {
@@ -61,6 +64,7 @@ func (p *ProbeQueuer) Start() {
 			select {
 			case <-time.After(p.Interval):
 				p.Queue.EnqueueCheck(HealthRequest{
+					ID:                       p.ID,
 					Host:                     p.Host,
 					Path:                     p.Path,
 					Protocol:                 p.Protocol,
@@ -70,6 +74,8 @@ func (p *ProbeQueuer) Start() {
 					ExpectedResponses:        p.ExpectedResponses,
 					Notifier:                 p.Notifier,
 					AllowInsecureCertificate: p.AllowInsecureCertificate,
+					TLSServerName:            p.TLSServerName,
+					ExpectedBody:             p.ExpectedBody,
 				})
 			case <-ctx.Done():
 				return
@@ -87,4 +93,8 @@ func (p *ProbeQueuer) Stop() {
 
 	p.logger.V(3).Info("stopping probe", "id", p.ID)
 	p.cancel()
+	// the requests of the probe are not needed anymore, including the one in flight
+	if p.Queue != nil {
+		p.Queue.CancelProbe(p.ID)
+	}
 }
@@ -4,8 +4,11 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"regexp"
 	"runtime"
 	"sync"
 	"time"
@@ -24,26 +27,41 @@ type QueuedProbeWorker struct {
 	Throttle time.Duration
 
 	requests []HealthRequest
+	// probes holds the context of the requests of each probe, cancelled when the probe is removed
+	probes   map[string]*probeContext
+	inFlight sync.WaitGroup
 	logger   logr.Logger
 
 	mux sync.Mutex
 }
 
+type probeContext struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
 func NewRequestQueue(throttle time.Duration) *QueuedProbeWorker {
 	return &QueuedProbeWorker{
 		Throttle: throttle,
 		requests: make([]HealthRequest, 0),
+		probes:   map[string]*probeContext{},
 	}
 }
 
 type HealthRequest struct {
+	// ID is the ID of the probe the request is for, the requests of a probe are cancelled together
+	ID string
+
 	Host, Path, Address      string
 	Protocol                 v1alpha1.HealthProtocol
 	Port                     int
 	AdditionalHeaders        v1alpha1.AdditionalHeaders
 	ExpectedResponses        []int
 	AllowInsecureCertificate bool
-	Notifier                 ProbeNotifier
+	TLSServerName            string
+	// ExpectedBody is a regular expression the body of the response must match, the body is not read when empty
+	ExpectedBody string
+	Notifier     ProbeNotifier
 }
 
 func (q *QueuedProbeWorker) EnqueueCheck(req HealthRequest) {
@@ -53,6 +71,42 @@ func (q *QueuedProbeWorker) EnqueueCheck(req HealthRequest) {
 	q.requests = append(q.requests, req)
 }
 
+// CancelProbe cancels the in-flight requests of the probe and drops its queued requests
+func (q *QueuedProbeWorker) CancelProbe(id string) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if probe, ok := q.probes[id]; ok {
+		probe.cancel()
+		delete(q.probes, id)
+	}
+
+	requests := make([]HealthRequest, 0, len(q.requests))
+	for _, req := range q.requests {
+		if req.ID != id {
+			requests = append(requests, req)
+		}
+	}
+	q.requests = requests
+}
+
+// contextFor returns the context of the requests of the probe, derived from the worker context
+func (q *QueuedProbeWorker) contextFor(ctx context.Context, id string) context.Context {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	if q.probes == nil {
+		q.probes = map[string]*probeContext{}
+	}
+	probe, ok := q.probes[id]
+	if !ok {
+		probeCtx, cancel := context.WithCancel(ctx)
+		probe = &probeContext{ctx: probeCtx, cancel: cancel}
+		q.probes[id] = probe
+	}
+	return probe.ctx
+}
+
 // deqeue takes the next element of the queue and returns it. It blocks
 // if the queue is empty, and returns false if the context is cancelled
 func (q *QueuedProbeWorker) dequeue(ctx context.Context) (HealthRequest, bool) {
@@ -88,9 +142,16 @@ func (q *QueuedProbeWorker) dequeue(ctx context.Context) (HealthRequest, bool) {
 	return req, ok
 }
 
+// NeedLeaderElection makes the queue wait for the leader election, so that only the leader processes the probes
+func (q *QueuedProbeWorker) NeedLeaderElection() bool {
+	return true
+}
+
 func (q *QueuedProbeWorker) Start(ctx context.Context) error {
 	q.logger = log.FromContext(ctx)
 	defer q.logger.Info("Stopping health check queue")
+	// the in-flight requests are cancelled with the context
+	defer q.inFlight.Wait()
 
 	for {
 		select {
@@ -111,8 +172,17 @@ func (q *QueuedProbeWorker) Start(ctx context.Context) error {
 }
 
 func (q *QueuedProbeWorker) process(ctx context.Context, req HealthRequest) {
+	probeCtx := q.contextFor(ctx, req.ID)
+
+	q.inFlight.Add(1)
 	go func() {
-		result := q.performRequest(ctx, req)
+		defer q.inFlight.Done()
+
+		result := q.performRequest(probeCtx, req)
+		// the probe was removed, or the worker stopped, while the request was in flight
+		if probeCtx.Err() != nil {
+			return
+		}
 		notificationResult, err := req.Notifier.Notify(ctx, result)
 		if err != nil {
 			q.logger.Error(err, "failed to notify health check result")
@@ -130,9 +200,14 @@ func (q *QueuedProbeWorker) performRequest(ctx context.Context, req HealthReques
 	probeClient := &http.Client{
 		Transport: TransportWithDNSResponse(map[string]string{req.Host: req.Address}),
 	}
+	// the transport is not reused, close its connection so that it does not outlive the request
+	defer probeClient.CloseIdleConnections()
 
-	if req.AllowInsecureCertificate {
-		probeClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	if req.AllowInsecureCertificate || req.TLSServerName != "" {
+		probeClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: req.AllowInsecureCertificate,
+			ServerName:         req.TLSServerName,
+		}
 	}
 
 	// Default port to 80
@@ -147,8 +222,20 @@ func (q *QueuedProbeWorker) performRequest(ctx context.Context, req HealthReques
 		return ProbeResult{CheckedAt: time.Now(), Healthy: false, Reason: err.Error()}
 	}
 
-	// add any user-defined additional headers
+	// record the handshake error to report TLS failures apart from the other request failures
+	var tlsErr error
+	httpReq = httpReq.WithContext(httptrace.WithClientTrace(httpReq.Context(), &httptrace.ClientTrace{
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			tlsErr = err
+		},
+	}))
+
+	// add any user-defined additional headers, the Host header is set on the request itself as it is otherwise ignored
 	for _, h := range req.AdditionalHeaders {
+		if http.CanonicalHeaderKey(h.Name) == "Host" {
+			httpReq.Host = h.Value
+			continue
+		}
 		httpReq.Header.Add(h.Name, h.Value)
 	}
 
@@ -156,8 +243,13 @@ func (q *QueuedProbeWorker) performRequest(ctx context.Context, req HealthReques
 	res, err := probeClient.Do(httpReq)
 	if utilnet.IsConnectionReset(err) {
 		res = &http.Response{StatusCode: 104}
+	} else if err != nil && tlsErr != nil {
+		return ProbeResult{CheckedAt: time.Now(), Healthy: false, FailureType: v1alpha1.ProbeFailureTLSHandshake, Reason: fmt.Sprintf("TLS handshake failed: %s", tlsErr.Error())}
 	} else if err != nil {
-		return ProbeResult{CheckedAt: time.Now(), Healthy: false, Reason: fmt.Sprintf("error: %s, response: %+v", err.Error(), res)}
+		return ProbeResult{CheckedAt: time.Now(), Healthy: false, FailureType: v1alpha1.ProbeFailureRequest, Reason: fmt.Sprintf("error: %s, response: %+v", err.Error(), res)}
+	}
+	if res.Body != nil {
+		defer res.Body.Close()
 	}
 
 	// Create the result based on the response
@@ -166,18 +258,49 @@ func (q *QueuedProbeWorker) performRequest(ctx context.Context, req HealthReques
 	}
 	healthy := true
 	reason := ""
+	var failureType v1alpha1.ProbeFailureType
 
 	if !checkResponse(res.StatusCode, req.ExpectedResponses) {
 		healthy = false
 		reason = fmt.Sprintf("Status code: %d", res.StatusCode)
+		failureType = v1alpha1.ProbeFailureHTTPStatus
+	} else if req.ExpectedBody != "" {
+		if bodyReason := checkBody(res, req.ExpectedBody); bodyReason != "" {
+			healthy = false
+			reason = bodyReason
+			failureType = v1alpha1.ProbeFailureResponseBody
+		}
 	}
 
 	return ProbeResult{
-		CheckedAt: time.Now(),
-		Healthy:   healthy,
-		Status:    res.StatusCode,
-		Reason:    reason,
+		CheckedAt:   time.Now(),
+		Healthy:     healthy,
+		Status:      res.StatusCode,
+		Reason:      reason,
+		FailureType: failureType,
+	}
+}
+
+// maxProbeBodySize is the number of bytes of the response body read to match the expected body
+const maxProbeBodySize = 64 * 1024
+
+// checkBody returns the reason the body of the response does not match the expected body, empty when it matches
+func checkBody(res *http.Response, expected string) string {
+	expectedBody, err := regexp.Compile(expected)
+	if err != nil {
+		return fmt.Sprintf("invalid expected body %q: %s", expected, err)
+	}
+	if res.Body == nil {
+		return fmt.Sprintf("Response body does not match %q", expected)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxProbeBodySize))
+	if err != nil {
+		return fmt.Sprintf("failed to read response body: %s", err)
+	}
+	if !expectedBody.Match(body) {
+		return fmt.Sprintf("Response body does not match %q", expected)
 	}
+	return ""
 }
 
 func checkResponse(response int, expected []int) bool {
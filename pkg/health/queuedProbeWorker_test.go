@@ -0,0 +1,386 @@
+//go:build unit
+
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestQueuedProbeWorker_performRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "app.example.com" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	address, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	serverPort, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	testCases := []struct {
+		name              string
+		additionalHeaders v1alpha1.AdditionalHeaders
+		wantHealthy       bool
+		wantStatus        int
+	}{
+		{
+			name:        "unhealthy without headers",
+			wantHealthy: false,
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name: "healthy with headers",
+			additionalHeaders: v1alpha1.AdditionalHeaders{
+				{Name: "Host", Value: "app.example.com"},
+				{Name: "Authorization", Value: "Bearer token"},
+			},
+			wantHealthy: true,
+			wantStatus:  http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			q := &QueuedProbeWorker{logger: logr.Discard()}
+			result := q.performRequest(context.Background(), HealthRequest{
+				Host:              "probe.example.com",
+				Address:           address,
+				Port:              serverPort,
+				Protocol:          v1alpha1.HttpProtocol,
+				AdditionalHeaders: testCase.additionalHeaders,
+			})
+			if result.Healthy != testCase.wantHealthy {
+				t.Errorf("expected healthy %v, got %v: %s", testCase.wantHealthy, result.Healthy, result.Reason)
+			}
+			if result.Status != testCase.wantStatus {
+				t.Errorf("expected status %d, got %d", testCase.wantStatus, result.Status)
+			}
+		})
+	}
+}
+
+func TestQueuedProbeWorker_performRequest_expectedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/healthz":
+			_, _ = w.Write([]byte(`{"status": "ok"}`))
+		case "/large":
+			// the match is bounded to the start of the body
+			_, _ = w.Write([]byte(strings.Repeat(" ", maxProbeBodySize) + `{"status": "ok"}`))
+		case "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"status": "ok"}`))
+		default:
+			// the application answers 200 while failing
+			_, _ = w.Write([]byte(`{"status": "error", "message": "database unreachable"}`))
+		}
+	}))
+	defer server.Close()
+
+	address, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	serverPort, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	testCases := []struct {
+		name              string
+		path              string
+		expectedResponses []int
+		expectedBody      string
+		wantHealthy       bool
+		wantFailureType   v1alpha1.ProbeFailureType
+	}{
+		{
+			name:         "200 with the expected body",
+			path:         "/healthz",
+			expectedBody: `"status": *"ok"`,
+			wantHealthy:  true,
+		},
+		{
+			name:            "200 with the wrong body",
+			path:            "/error",
+			expectedBody:    `"status": *"ok"`,
+			wantHealthy:     false,
+			wantFailureType: v1alpha1.ProbeFailureResponseBody,
+		},
+		{
+			name:        "200 with the wrong body without an expected body",
+			path:        "/error",
+			wantHealthy: true,
+		},
+		{
+			name:            "expected body beyond the bound",
+			path:            "/large",
+			expectedBody:    `"status": *"ok"`,
+			wantHealthy:     false,
+			wantFailureType: v1alpha1.ProbeFailureResponseBody,
+		},
+		{
+			name:              "unexpected status with the expected body",
+			path:              "/unavailable",
+			expectedResponses: []int{200},
+			expectedBody:      `"status": *"ok"`,
+			wantHealthy:       false,
+			wantFailureType:   v1alpha1.ProbeFailureHTTPStatus,
+		},
+		{
+			name:              "expected status and body",
+			path:              "/unavailable",
+			expectedResponses: []int{200, 503},
+			expectedBody:      "ok",
+			wantHealthy:       true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			q := &QueuedProbeWorker{logger: logr.Discard()}
+			result := q.performRequest(context.Background(), HealthRequest{
+				Host:              "probe.example.com",
+				Address:           address,
+				Port:              serverPort,
+				Path:              testCase.path,
+				Protocol:          v1alpha1.HttpProtocol,
+				ExpectedResponses: testCase.expectedResponses,
+				ExpectedBody:      testCase.expectedBody,
+			})
+			if result.Healthy != testCase.wantHealthy {
+				t.Errorf("expected healthy %v, got %v: %s", testCase.wantHealthy, result.Healthy, result.Reason)
+			}
+			if result.FailureType != testCase.wantFailureType {
+				t.Errorf("expected failure type %q, got %q: %s", testCase.wantFailureType, result.FailureType, result.Reason)
+			}
+		})
+	}
+}
+
+func TestQueuedProbeWorker_performRequest_tls(t *testing.T) {
+	var serverName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unhealthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, nil
+		},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	address, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	serverPort, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	testCases := []struct {
+		name                     string
+		path                     string
+		tlsServerName            string
+		allowInsecureCertificate bool
+		wantHealthy              bool
+		wantFailureType          v1alpha1.ProbeFailureType
+		wantServerName           string
+	}{
+		{
+			name:            "handshake fails with an untrusted certificate",
+			wantFailureType: v1alpha1.ProbeFailureTLSHandshake,
+			wantServerName:  "probe.example.com",
+		},
+		{
+			name:                     "healthy when skipping the certificate verification",
+			allowInsecureCertificate: true,
+			wantHealthy:              true,
+			wantServerName:           "probe.example.com",
+		},
+		{
+			name:                     "sends the configured server name",
+			tlsServerName:            "gateway.example.com",
+			allowInsecureCertificate: true,
+			wantHealthy:              true,
+			wantServerName:           "gateway.example.com",
+		},
+		{
+			name:                     "unhealthy status after a successful handshake",
+			path:                     "/unhealthy",
+			allowInsecureCertificate: true,
+			wantFailureType:          v1alpha1.ProbeFailureHTTPStatus,
+			wantServerName:           "probe.example.com",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			serverName = ""
+			q := &QueuedProbeWorker{logger: logr.Discard()}
+			result := q.performRequest(context.Background(), HealthRequest{
+				Host:                     "probe.example.com",
+				Address:                  address,
+				Port:                     serverPort,
+				Path:                     testCase.path,
+				Protocol:                 v1alpha1.HttpsProtocol,
+				TLSServerName:            testCase.tlsServerName,
+				AllowInsecureCertificate: testCase.allowInsecureCertificate,
+			})
+			if result.Healthy != testCase.wantHealthy {
+				t.Errorf("expected healthy %v, got %v: %s", testCase.wantHealthy, result.Healthy, result.Reason)
+			}
+			if result.FailureType != testCase.wantFailureType {
+				t.Errorf("expected failure type %q, got %q: %s", testCase.wantFailureType, result.FailureType, result.Reason)
+			}
+			if serverName != testCase.wantServerName {
+				t.Errorf("expected server name %q, got %q", testCase.wantServerName, serverName)
+			}
+		})
+	}
+}
+
+// countingNotifier counts the results it is notified of
+type countingNotifier struct {
+	count *int32
+}
+
+func (n countingNotifier) Notify(_ context.Context, _ ProbeResult) (NotificationResult, error) {
+	atomic.AddInt32(n.count, 1)
+	return NotificationResult{}, nil
+}
+
+func TestQueuedProbeWorker_cancel(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		// hang until the probe request is cancelled
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	address, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	serverPort, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		cancel func(q *QueuedProbeWorker, stop context.CancelFunc)
+	}{
+		{
+			name: "removing the probe cancels its request",
+			cancel: func(q *QueuedProbeWorker, _ context.CancelFunc) {
+				q.CancelProbe("test-probe")
+			},
+		},
+		{
+			name: "stopping the worker cancels the requests",
+			cancel: func(_ *QueuedProbeWorker, stop context.CancelFunc) {
+				stop()
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			goroutines := runtime.NumGoroutine()
+
+			var notified int32
+			req := HealthRequest{
+				ID:       "test-probe",
+				Host:     "probe.example.com",
+				Address:  address,
+				Port:     serverPort,
+				Protocol: v1alpha1.HttpProtocol,
+				Notifier: countingNotifier{count: &notified},
+			}
+			q := NewRequestQueue(time.Second)
+			q.logger = logr.Discard()
+			ctx, stop := context.WithCancel(context.Background())
+			defer stop()
+
+			q.process(ctx, req)
+			select {
+			case <-entered:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for the probe request")
+			}
+
+			testCase.cancel(q, stop)
+
+			done := make(chan struct{})
+			go func() {
+				q.inFlight.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for the probe request to be cancelled")
+			}
+			if n := atomic.LoadInt32(&notified); n != 0 {
+				t.Errorf("expected the cancelled request not to be notified, got %d notifications", n)
+			}
+
+			// the goroutines of the request, the client connection and the server handler are gone
+			deadline := time.Now().Add(5 * time.Second)
+			for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if n := runtime.NumGoroutine(); n > goroutines {
+				t.Errorf("expected at most %d goroutines, got %d", goroutines, n)
+			}
+		})
+	}
+
+	q := NewRequestQueue(time.Second)
+	q.contextFor(context.Background(), "test-probe")
+	q.EnqueueCheck(HealthRequest{ID: "test-probe"})
+	q.EnqueueCheck(HealthRequest{ID: "other-probe"})
+	q.CancelProbe("test-probe")
+	if _, ok := q.probes["test-probe"]; ok {
+		t.Errorf("expected the probe to be removed from the worker")
+	}
+	if len(q.requests) != 1 || q.requests[0].ID != "other-probe" {
+		t.Errorf("expected only the requests of the other probe to be queued, got %v", q.requests)
+	}
+}
@@ -0,0 +1,153 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/dnspolicy"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
+)
+
+// Inventory is the set of resources managed by the controller
+type Inventory struct {
+	Gateways []Gateway `json:"gateways"`
+}
+
+// Gateway is a Gateway placed by the controller, with the DNS names published for it and the certificates of its
+// listeners
+type Gateway struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Clusters are the sorted names of the clusters the Gateway is placed on
+	Clusters []string `json:"clusters"`
+	// DNSNames are the sorted names of the endpoints of the DNSRecords of the Gateway
+	DNSNames []string `json:"dnsNames"`
+	// Certificates are the Certificates issuing the Secrets referenced by the listeners of the Gateway
+	Certificates []Certificate `json:"certificates"`
+}
+
+// Certificate is a cert-manager Certificate issuing the Secret of a Gateway listener
+type Certificate struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	DNSNames  []string `json:"dnsNames"`
+	// NotAfter is the expiry of the issued certificate, unset until the certificate is issued
+	NotAfter *time.Time `json:"notAfter,omitempty"`
+}
+
+// Build returns the inventory of the Gateways placed by the controller, the Gateways holding the clusters annotation of
+// the Gateway controller, sorted by namespace and name. The reader is expected to be backed by the informer caches of
+// the manager, so that serving the inventory does not query the API server.
+func Build(ctx context.Context, c client.Reader) (*Inventory, error) {
+	gateways := &gatewayv1beta1.GatewayList{}
+	if err := c.List(ctx, gateways); err != nil {
+		return nil, err
+	}
+	dnsRecords := &v1alpha1.DNSRecordList{}
+	if err := c.List(ctx, dnsRecords); err != nil {
+		return nil, err
+	}
+	certificates := &certmanv1.CertificateList{}
+	if err := c.List(ctx, certificates); err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{Gateways: []Gateway{}}
+	for i := range gateways.Items {
+		gw := &gateways.Items[i]
+		val := metadata.GetAnnotation(gw, gateway.GatewayClustersAnnotation)
+		if val == "" {
+			continue
+		}
+		clusters := []string{}
+		if err := json.Unmarshal([]byte(val), &clusters); err != nil {
+			continue
+		}
+		sort.Strings(clusters)
+		inventory.Gateways = append(inventory.Gateways, Gateway{
+			Namespace:    gw.Namespace,
+			Name:         gw.Name,
+			Clusters:     clusters,
+			DNSNames:     gatewayDNSNames(gw, dnsRecords.Items),
+			Certificates: gatewayCertificates(gw, certificates.Items),
+		})
+	}
+
+	sort.Slice(inventory.Gateways, func(i, j int) bool {
+		if inventory.Gateways[i].Namespace != inventory.Gateways[j].Namespace {
+			return inventory.Gateways[i].Namespace < inventory.Gateways[j].Namespace
+		}
+		return inventory.Gateways[i].Name < inventory.Gateways[j].Name
+	})
+	return inventory, nil
+}
+
+// gatewayDNSNames returns the names of the endpoints of the DNSRecords labelled for the Gateway by the DNSPolicy
+// controller
+func gatewayDNSNames(gw *gatewayv1beta1.Gateway, dnsRecords []v1alpha1.DNSRecord) []string {
+	dnsNames := sets.New[string]()
+	for _, dnsRecord := range dnsRecords {
+		if dnsRecord.Labels[dnspolicy.LabelGatewayNSRef] != gw.Namespace || dnsRecord.Labels[dnspolicy.LabelGatewayReference] != gw.Name {
+			continue
+		}
+		for _, endpoint := range dnsRecord.Spec.Endpoints {
+			dnsNames.Insert(endpoint.DNSName)
+		}
+	}
+	return sets.List(dnsNames)
+}
+
+// gatewayCertificates returns the Certificates whose Secret is referenced by a listener of the Gateway, sorted by
+// namespace and name
+func gatewayCertificates(gw *gatewayv1beta1.Gateway, certificates []certmanv1.Certificate) []Certificate {
+	secrets := sets.New[client.ObjectKey]()
+	for _, listener := range gw.Spec.Listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, ref := range listener.TLS.CertificateRefs {
+			if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != "Secret") {
+				continue
+			}
+			namespace := gw.Namespace
+			if ref.Namespace != nil {
+				namespace = string(*ref.Namespace)
+			}
+			secrets.Insert(client.ObjectKey{Namespace: namespace, Name: string(ref.Name)})
+		}
+	}
+
+	result := []Certificate{}
+	for _, cert := range certificates {
+		if !secrets.Has(client.ObjectKey{Namespace: cert.Namespace, Name: cert.Spec.SecretName}) {
+			continue
+		}
+		certificate := Certificate{
+			Namespace: cert.Namespace,
+			Name:      cert.Name,
+			DNSNames:  append([]string{}, cert.Spec.DNSNames...),
+		}
+		if cert.Status.NotAfter != nil {
+			notAfter := cert.Status.NotAfter.UTC()
+			certificate.NotAfter = &notAfter
+		}
+		result = append(result, certificate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,131 @@
+//go:build unit
+
+package inventory
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/dnspolicy"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestHandler(t *testing.T) {
+	placed := testutil.NewTestGateway("prod-web", "kuadrant-multi-cluster-gateway-instance-per-cluster", "multi-cluster-gateways").
+		WithHTTPSListener("api.example.com", "api-tls").Gateway
+	placed.Annotations = map[string]string{gateway.GatewayClustersAnnotation: `["spoke-2","spoke-1"]`}
+	// a gateway that is not placed by the controller
+	unplaced := testutil.NewTestGateway("other", "istio", "multi-cluster-gateways").
+		WithHTTPSListener("other.example.com", "other-tls").Gateway
+
+	dnsRecord := &v1alpha1.DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prod-web-api",
+			Namespace: "multi-cluster-gateways",
+			Labels: map[string]string{
+				dnspolicy.LabelGatewayNSRef:     "multi-cluster-gateways",
+				dnspolicy.LabelGatewayReference: "prod-web",
+			},
+		},
+		Spec: v1alpha1.DNSRecordSpec{
+			Endpoints: []*v1alpha1.Endpoint{
+				{DNSName: "api.example.com", RecordType: "CNAME"},
+				{DNSName: "lb.example.com", RecordType: "A"},
+				{DNSName: "api.example.com", RecordType: "TXT"},
+			},
+		},
+	}
+	notAfter := metav1.NewTime(time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC))
+	issued := &certmanv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-tls", Namespace: "multi-cluster-gateways"},
+		Spec:       certmanv1.CertificateSpec{SecretName: "api-tls", DNSNames: []string{"api.example.com"}},
+		Status:     certmanv1.CertificateStatus{NotAfter: &notAfter},
+	}
+	other := &certmanv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-tls", Namespace: "multi-cluster-gateways"},
+		Spec:       certmanv1.CertificateSpec{SecretName: "other-tls", DNSNames: []string{"other.example.com"}},
+	}
+
+	f := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).
+		WithObjects(placed, unplaced, dnsRecord, issued, other).Build()
+	handler := NewHandler(f, "s3cr3t")
+
+	testCases := []struct {
+		name       string
+		method     string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "inventory",
+			method:     http.MethodGet,
+			token:      "s3cr3t",
+			wantStatus: http.StatusOK,
+			wantBody: `{"gateways":[{"namespace":"multi-cluster-gateways","name":"prod-web","clusters":["spoke-1","spoke-2"],` +
+				`"dnsNames":["api.example.com","lb.example.com"],` +
+				`"certificates":[{"namespace":"multi-cluster-gateways","name":"api-tls","dnsNames":["api.example.com"],"notAfter":"2024-03-01T12:00:00Z"}]}]}`,
+		},
+		{
+			name:       "missing token",
+			method:     http.MethodGet,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong token",
+			method:     http.MethodGet,
+			token:      "guess",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "read-only",
+			method:     http.MethodPost,
+			token:      "s3cr3t",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req := httptest.NewRequest(testCase.method, Path, nil)
+			if testCase.token != "" {
+				req.Header.Set("Authorization", "Bearer "+testCase.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != testCase.wantStatus {
+				t.Fatalf("expected status %d, got %d", testCase.wantStatus, rec.Code)
+			}
+			if testCase.wantBody == "" {
+				return
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != testCase.wantBody {
+				t.Errorf("expected body\n%s\ngot\n%s", testCase.wantBody, got)
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("expected a JSON content type, got %q", contentType)
+			}
+		})
+	}
+}
+
+func TestHandler_emptyToken(t *testing.T) {
+	f := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).Build()
+	req := httptest.NewRequest(http.MethodGet, Path, nil)
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	NewHandler(f, "").ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected an empty token to authorize no request, got status %d", rec.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package inventory
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// Path is the path the inventory is served at
+	Path = "/inventory"
+	// CertName and KeyName are the names of the serving certificate and key in the certificate directory, as for the
+	// webhook server of the manager
+	CertName = "tls.crt"
+	KeyName  = "tls.key"
+
+	shutdownTimeout = 5 * time.Second
+)
+
+// NewHandler returns a read-only handler serving the inventory built from the reader as JSON to the GET requests
+// carrying the token as a bearer token
+func NewHandler(c client.Reader, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if !authorized(req, token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		inventory, err := Build(req.Context(), c)
+		if err != nil {
+			log.Log.Error(err, "failed to build the inventory")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(inventory); err != nil {
+			log.Log.Error(err, "failed to write the inventory")
+		}
+	})
+}
+
+// authorized returns whether the request carries the token, an empty token authorizes no request
+func authorized(req *http.Request, token string) bool {
+	bearer, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
+
+// Server serves the inventory at Path on its address
+type Server struct {
+	addr    string
+	certDir string
+	handler http.Handler
+}
+
+// NewServer returns a server of the inventory on the address, built from the reader for the requests carrying the
+// token. The inventory is served over HTTPS with the certificate in the certificate directory, reloaded when it
+// changes, and over plain HTTP when the directory is empty, the token is then sent in the clear.
+func NewServer(addr, certDir string, c client.Reader, token string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle(Path, NewHandler(c, token))
+	return &Server{addr: addr, certDir: certDir, handler: mux}
+}
+
+// Start implements manager.Runnable, serving the inventory until the context is done
+func (s *Server) Start(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Log.Error(err, "failed to shut down the inventory server")
+		}
+	}()
+
+	if s.certDir == "" {
+		log.Log.Info("serving the inventory", "address", s.addr, "path", Path)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+
+	certWatcher, err := certwatcher.New(filepath.Join(s.certDir, CertName), filepath.Join(s.certDir, KeyName))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := certWatcher.Start(ctx); err != nil {
+			log.Log.Error(err, "failed to watch the inventory serving certificate")
+		}
+	}()
+	server.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certWatcher.GetCertificate,
+	}
+
+	log.Log.Info("serving the inventory over TLS", "address", s.addr, "path", Path, "certDir", s.certDir)
+	if err := server.ListenAndServeTLS("", ""); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The inventory is read from the caches, which every
+// replica keeps, so the standby replicas serve it too.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,140 @@
+package placement
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	k8smeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	// ClusterCapacityAnnotation is a numeric annotation on a ManagedCluster giving its spare capacity. The Capacity
+	// strategy places gateways on clusters in proportion to their capacity.
+	ClusterCapacityAnnotation = "kuadrant.io/cluster-capacity"
+	// GatewayPlacementAnnotation holds the placement strategy of the gateway, set from the gateway class parameters
+	GatewayPlacementAnnotation = "kuadrant.io/gateway-placement"
+
+	// StrategyStatic places the gateway on all the clusters of the placement decision
+	StrategyStatic = "Static"
+	// StrategyCapacity places the gateway on a subset of the clusters of the placement decision, weighted by their
+	// capacity
+	StrategyCapacity = "Capacity"
+
+	defaultClusterCapacity = 1
+)
+
+// Strategy selects the clusters a gateway is placed on among the clusters of its placement decision
+type Strategy struct {
+	// Type is the strategy, Static by default
+	Type string `json:"type,omitempty"`
+	// Clusters is the number of clusters the Capacity strategy places the gateway on, 1 by default
+	Clusters int `json:"clusters,omitempty"`
+}
+
+// Validate returns an error if the strategy is not supported
+func (s *Strategy) Validate() error {
+	if s.Type != "" && s.Type != StrategyStatic && s.Type != StrategyCapacity {
+		return fmt.Errorf("unsupported placement strategy %q, must be %s or %s", s.Type, StrategyStatic, StrategyCapacity)
+	}
+	if s.Clusters < 0 {
+		return fmt.Errorf("placement clusters must not be negative, got %d", s.Clusters)
+	}
+	return nil
+}
+
+// GetStrategy returns the placement strategy set on the gateway, nil if there is none
+func GetStrategy(gateway *gatewayv1beta1.Gateway) (*Strategy, error) {
+	val, ok := gateway.GetAnnotations()[GatewayPlacementAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	strategy := &Strategy{}
+	if err := json.Unmarshal([]byte(val), strategy); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", GatewayPlacementAnnotation, err)
+	}
+	return strategy, nil
+}
+
+// selectClusters returns the clusters the gateway is placed on among the targeted clusters, according to the
+// placement strategy of the gateway
+func (op *ocmPlacer) selectClusters(ctx context.Context, gateway *gatewayv1beta1.Gateway, targetClusters sets.Set[string]) (sets.Set[string], error) {
+	strategy, err := GetStrategy(gateway)
+	if err != nil {
+		return targetClusters, err
+	}
+	if strategy == nil || strategy.Type != StrategyCapacity {
+		return targetClusters, nil
+	}
+
+	capacities := map[string]float64{}
+	for _, cluster := range targetClusters.UnsortedList() {
+		managedCluster := &clusterv1.ManagedCluster{}
+		if err := op.c.Get(ctx, client.ObjectKey{Name: cluster}, managedCluster); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return targetClusters, err
+		}
+		capacities[cluster] = clusterCapacity(managedCluster)
+	}
+
+	rootMeta, _ := k8smeta.Accessor(gateway)
+	count := strategy.Clusters
+	if count == 0 {
+		count = 1
+	}
+	return selectByCapacity(rootMeta.GetNamespace()+"/"+rootMeta.GetName(), capacities, count), nil
+}
+
+// clusterCapacity returns the capacity of the cluster from its annotation, the default capacity when it is missing
+// or invalid
+func clusterCapacity(cluster *clusterv1.ManagedCluster) float64 {
+	val, ok := cluster.GetAnnotations()[ClusterCapacityAnnotation]
+	if !ok {
+		return defaultClusterCapacity
+	}
+	capacity, err := strconv.ParseFloat(val, 64)
+	if err != nil || capacity < 0 || math.IsNaN(capacity) || math.IsInf(capacity, 0) {
+		log.Log.Info("invalid cluster capacity, using the default", "cluster", cluster.Name, "capacity", val)
+		return defaultClusterCapacity
+	}
+	return capacity
+}
+
+// selectByCapacity selects count clusters for the key using weighted rendezvous hashing. Each cluster is selected for
+// a share of the keys proportional to its capacity, and the selection of a key only changes when the clusters or
+// their capacities change.
+func selectByCapacity(key string, capacities map[string]float64, count int) sets.Set[string] {
+	clusters := make([]string, 0, len(capacities))
+	scores := map[string]float64{}
+	for cluster, capacity := range capacities {
+		clusters = append(clusters, cluster)
+		sum := sha256.Sum256([]byte(key + "/" + cluster))
+		// a uniform hash in (0, 1)
+		u := (float64(binary.BigEndian.Uint64(sum[:8])>>11) + 0.5) / (1 << 53)
+		scores[cluster] = -capacity / math.Log(u)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if scores[clusters[i]] != scores[clusters[j]] {
+			return scores[clusters[i]] > scores[clusters[j]]
+		}
+		return clusters[i] < clusters[j]
+	})
+	if count > len(clusters) {
+		count = len(clusters)
+	}
+	return sets.New(clusters[:count]...)
+}
@@ -45,6 +45,10 @@ func (p *FakeGatewayPlacer) GetPlacedClusters(_ context.Context, gateway *v1beta
 	return placedClusters, nil
 }
 
+func (p *FakeGatewayPlacer) GetSyncStatus(_ context.Context, gateway *v1beta1.Gateway, _ string) (bool, *metav1.Time, error) {
+	return gateway.Labels != nil, nil, nil
+}
+
 func (p *FakeGatewayPlacer) GetClusters(_ context.Context, _ *v1beta1.Gateway) (sets.Set[string], error) {
 	return nil, nil
 }
@@ -56,6 +60,10 @@ func (p *FakeGatewayPlacer) ListenerTotalAttachedRoutes(_ context.Context, _ *v1
 	return 0, nil
 }
 
+func (p *FakeGatewayPlacer) ListenerProgrammed(_ context.Context, _ *v1beta1.Gateway, _ string, _ string) (metav1.ConditionStatus, error) {
+	return "", nil
+}
+
 func (p *FakeGatewayPlacer) GetAddresses(_ context.Context, _ *v1beta1.Gateway, _ string) ([]v1beta1.GatewayAddress, error) {
 	t := v1beta1.IPAddressType
 	return []v1beta1.GatewayAddress{
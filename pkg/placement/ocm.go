@@ -100,6 +100,78 @@ func (op *ocmPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway *g
 
 }
 
+// ListenerProgrammed returns the status of the Programmed condition of the listener of the downstream gateway, empty
+// while the downstream gateway reports no such condition for the listener
+func (op *ocmPlacer) ListenerProgrammed(ctx context.Context, gateway *gatewayv1beta1.Gateway, listenerName string, downstream string) (metav1.ConditionStatus, error) {
+	workname := WorkName(gateway)
+	rootMeta, _ := k8smeta.Accessor(gateway)
+	mw := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workname,
+			Namespace: downstream,
+		},
+	}
+	if err := op.c.Get(ctx, client.ObjectKeyFromObject(mw), mw, &client.GetOptions{}); err != nil {
+		return "", err
+	}
+	for _, m := range mw.Status.ResourceStatus.Manifests {
+		if m.ResourceMeta.Group == gateway.GetObjectKind().GroupVersionKind().Group && m.ResourceMeta.Name == rootMeta.GetName() {
+			for _, value := range m.StatusFeedbacks.Values {
+				programmedStatusKey := strings.ToLower(fmt.Sprintf("listener%sProgrammed", listenerName))
+				if strings.ToLower(value.Name) == programmedStatusKey && value.Value.String != nil {
+					return metav1.ConditionStatus(*value.Value.String), nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// GetSyncStatus returns whether the downstream cluster has applied the gateway manifests and reports the synced gateway
+// as programmed, along with the time it last became synced
+func (op *ocmPlacer) GetSyncStatus(ctx context.Context, gateway *gatewayv1beta1.Gateway, downstream string) (bool, *metav1.Time, error) {
+	workname := WorkName(gateway)
+	rootMeta, _ := k8smeta.Accessor(gateway)
+	mw := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workname,
+			Namespace: downstream,
+		},
+	}
+	if err := op.c.Get(ctx, client.ObjectKeyFromObject(mw), mw, &client.GetOptions{}); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+
+	applied := meta.FindStatusCondition(mw.Status.Conditions, workv1.WorkApplied)
+	available := meta.FindStatusCondition(mw.Status.Conditions, workv1.WorkAvailable)
+	if applied == nil || applied.Status != metav1.ConditionTrue || available == nil || available.Status != metav1.ConditionTrue {
+		return false, nil, nil
+	}
+
+	programmed := false
+	for _, m := range mw.Status.ResourceStatus.Manifests {
+		if m.ResourceMeta.Group == gateway.GetObjectKind().GroupVersionKind().Group && m.ResourceMeta.Name == rootMeta.GetName() {
+			for _, value := range m.StatusFeedbacks.Values {
+				if value.Name == "programmed" && value.Value.String != nil {
+					programmed = *value.Value.String == string(metav1.ConditionTrue)
+				}
+			}
+		}
+	}
+	if !programmed {
+		return false, nil, nil
+	}
+
+	lastSyncTime := applied.LastTransitionTime
+	if available.LastTransitionTime.After(lastSyncTime.Time) {
+		lastSyncTime = available.LastTransitionTime
+	}
+	return true, &lastSyncTime, nil
+}
+
 func WorkName(rootObj runtime.Object) string {
 	kind := rootObj.GetObjectKind().GroupVersionKind().Kind
 	rootMeta, _ := k8smeta.Accessor(rootObj)
@@ -235,12 +307,23 @@ func (op *ocmPlacer) GetPlacedClusters(ctx context.Context, gateway *gatewayv1be
 	return existingClusters, nil
 }
 
-// GetClusters will return the set of clusters this gateway is targeted to be placed on. It does not check the placement has happened
+// GetClusters will return the set of clusters this gateway is targeted to be placed on, selected by the placement strategy
+// of the gateway among the clusters of its placement decision, or the clusters it is pinned to. It does not check the
+// placement has happened
 func (op *ocmPlacer) GetClusters(ctx context.Context, gateway *gatewayv1beta1.Gateway) (sets.Set[string], error) {
+	targetClusters := sets.Set[string](sets.NewString())
+	// a pinned gateway is placed on the known pinned clusters only
+	if pinned, ok := GetPinnedClusters(gateway); ok {
+		known, _, err := KnownClusters(ctx, op.c, pinned)
+		if err != nil {
+			return targetClusters, err
+		}
+		return targetClusters.Insert(known...), nil
+	}
+
 	rootMeta, _ := k8smeta.Accessor(gateway)
 	labels := rootMeta.GetLabels()
 	selectedPlacement := labels[OCMPlacementLabel]
-	targetClusters := sets.Set[string](sets.NewString())
 	if selectedPlacement == "" {
 		return targetClusters, nil
 	}
@@ -263,7 +346,7 @@ func (op *ocmPlacer) GetClusters(ctx context.Context, gateway *gatewayv1beta1.Ga
 			targetClusters.Insert(d.ClusterName)
 		}
 	}
-	return targetClusters, nil
+	return op.selectClusters(ctx, gateway, targetClusters)
 }
 
 func (op *ocmPlacer) GetClusterGateway(ctx context.Context, gateway *gatewayv1beta1.Gateway, clusterName string) (dns.ClusterGateway, error) {
@@ -339,11 +422,18 @@ func (op *ocmPlacer) createUpdateClusterManifests(ctx context.Context, manifestN
 			Name: "addresses",
 			Path: ".status.addresses",
 		},
+		{
+			Name: "programmed",
+			Path: fmt.Sprintf(".status.conditions[?(@.type==\"%s\")].status", gatewayv1beta1.GatewayConditionProgrammed),
+		},
 	}
 	for _, l := range upstream.Spec.Listeners {
 		jsonPaths = append(jsonPaths, workv1.JsonPath{
 			Name: fmt.Sprintf("listener%sAttachedRoutes", l.Name),
 			Path: fmt.Sprintf(".status.listeners[?(@.name==\"%s\")].attachedRoutes", l.Name),
+		}, workv1.JsonPath{
+			Name: fmt.Sprintf("listener%sProgrammed", l.Name),
+			Path: fmt.Sprintf(".status.listeners[?(@.name==\"%s\")].conditions[?(@.type==\"%s\")].status", l.Name, gatewayv1beta1.ListenerConditionProgrammed),
 		})
 	}
 
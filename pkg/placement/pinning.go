@@ -0,0 +1,49 @@
+package placement
+
+import (
+	"context"
+	"strings"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// GatewayPinnedClustersAnnotation is a comma separated list of the clusters the gateway is placed on. When present
+// it overrides the placement decision and the placement strategy of the gateway.
+const GatewayPinnedClustersAnnotation = "kuadrant.io/gateway-pinned-clusters"
+
+// GetPinnedClusters returns the clusters the gateway is pinned to and true, or false if the gateway is not pinned
+func GetPinnedClusters(gateway *gatewayv1beta1.Gateway) ([]string, bool) {
+	val, ok := gateway.GetAnnotations()[GatewayPinnedClustersAnnotation]
+	if !ok {
+		return nil, false
+	}
+	pinned := sets.New[string]()
+	for _, cluster := range strings.Split(val, ",") {
+		if cluster = strings.TrimSpace(cluster); cluster != "" {
+			pinned.Insert(cluster)
+		}
+	}
+	return sets.List(pinned), true
+}
+
+// KnownClusters splits the clusters between the known ones, which have a ManagedCluster, and the unknown ones
+func KnownClusters(ctx context.Context, c client.Client, clusters []string) ([]string, []string, error) {
+	known, unknown := []string{}, []string{}
+	for _, cluster := range clusters {
+		managedCluster := &clusterv1.ManagedCluster{}
+		if err := c.Get(ctx, client.ObjectKey{Name: cluster}, managedCluster); err != nil {
+			if k8serrors.IsNotFound(err) {
+				unknown = append(unknown, cluster)
+				continue
+			}
+			return nil, nil, err
+		}
+		known = append(known, cluster)
+	}
+	return known, unknown, nil
+}
@@ -5,8 +5,12 @@ package placement_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math"
 	"testing"
+	"time"
 
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	pd "open-cluster-management.io/api/cluster/v1beta1"
 	workv1 "open-cluster-management.io/api/work/v1"
 
@@ -30,6 +34,9 @@ func init() {
 	if err := pd.AddToScheme(scheme.Scheme); err != nil {
 		panic(err)
 	}
+	if err := clusterv1.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
 }
 
 func TestGetAddresses(t *testing.T) {
@@ -330,6 +337,78 @@ func TestListenerTotalAttachedRoutes(t *testing.T) {
 	}
 }
 
+func TestListenerProgrammed(t *testing.T) {
+	gateway := &v1beta1.Gateway{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1beta1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test",
+		},
+	}
+	manifestWork := func(values ...workv1.FeedbackValue) *workv1.ManifestWork {
+		return &workv1.ManifestWork{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      placement.WorkName(gateway),
+				Namespace: "test",
+			},
+			Status: workv1.ManifestWorkStatus{
+				ResourceStatus: workv1.ManifestResourceStatus{
+					Manifests: []workv1.ManifestCondition{
+						{
+							ResourceMeta: workv1.ManifestResourceMeta{
+								Group: "gateway.networking.k8s.io",
+								Name:  "test",
+							},
+							StatusFeedbacks: workv1.StatusFeedbackResult{Values: values},
+						},
+					},
+				},
+			},
+		}
+	}
+	programmed := func(status string) workv1.FeedbackValue {
+		return workv1.FeedbackValue{Name: "listenerapiProgrammed", Value: workv1.FieldValue{String: &status}}
+	}
+
+	testCases := []struct {
+		Name         string
+		ManifestWork *workv1.ManifestWork
+		Expected     v1.ConditionStatus
+	}{
+		{
+			Name:         "test listener programmed",
+			ManifestWork: manifestWork(programmed("True")),
+			Expected:     v1.ConditionTrue,
+		},
+		{
+			Name:         "test listener not programmed",
+			ManifestWork: manifestWork(programmed("False")),
+			Expected:     v1.ConditionFalse,
+		},
+		{
+			Name:         "test listener programmed status not reported",
+			ManifestWork: manifestWork(),
+			Expected:     "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			f := fake.NewClientBuilder().WithObjects(testCase.ManifestWork).Build()
+			p := placement.NewOCMPlacer(f)
+			status, err := p.ListenerProgrammed(context.TODO(), gateway, "api", "test")
+			if err != nil {
+				t.Fatalf("did not expect an error but got one %s ", err)
+			}
+			if status != testCase.Expected {
+				t.Fatalf("the expected status %q did not match the actual status %q", testCase.Expected, status)
+			}
+		})
+	}
+}
+
 func TestGetPlacedClusters(t *testing.T) {
 	testCases := []struct {
 		Name               string
@@ -465,6 +544,101 @@ func TestGetPlacedClusters(t *testing.T) {
 
 }
 
+func TestGetSyncStatus(t *testing.T) {
+	appliedAt := v1.NewTime(time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC))
+	availableAt := v1.NewTime(time.Date(2023, 6, 1, 10, 0, 5, 0, time.UTC))
+	gateway := &v1beta1.Gateway{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1beta1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test",
+		},
+	}
+	manifestWork := func(conditions []v1.Condition, programmed string) *workv1.ManifestWork {
+		return &workv1.ManifestWork{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      placement.WorkName(gateway),
+				Namespace: "test",
+			},
+			Status: workv1.ManifestWorkStatus{
+				Conditions: conditions,
+				ResourceStatus: workv1.ManifestResourceStatus{
+					Manifests: []workv1.ManifestCondition{
+						{
+							ResourceMeta: workv1.ManifestResourceMeta{
+								Group: "gateway.networking.k8s.io",
+								Name:  "test",
+							},
+							StatusFeedbacks: workv1.StatusFeedbackResult{
+								Values: []workv1.FeedbackValue{
+									{
+										Name:  "programmed",
+										Value: workv1.FieldValue{Type: workv1.String, String: &programmed},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	syncedConditions := []v1.Condition{
+		{Type: workv1.WorkApplied, Status: v1.ConditionTrue, LastTransitionTime: appliedAt},
+		{Type: workv1.WorkAvailable, Status: v1.ConditionTrue, LastTransitionTime: availableAt},
+	}
+
+	testCases := []struct {
+		Name         string
+		ManifestWork *workv1.ManifestWork
+		WantSynced   bool
+		WantLastSync *v1.Time
+	}{
+		{
+			Name:         "test synced when applied, available and programmed",
+			ManifestWork: manifestWork(syncedConditions, "True"),
+			WantSynced:   true,
+			WantLastSync: &availableAt,
+		},
+		{
+			Name:         "test not synced when the downstream gateway is not programmed",
+			ManifestWork: manifestWork(syncedConditions, "False"),
+		},
+		{
+			Name: "test not synced when not yet applied",
+			ManifestWork: manifestWork([]v1.Condition{
+				{Type: workv1.WorkApplied, Status: v1.ConditionFalse, LastTransitionTime: appliedAt},
+				{Type: workv1.WorkAvailable, Status: v1.ConditionTrue, LastTransitionTime: availableAt},
+			}, "True"),
+		},
+		{
+			Name: "test not synced when there is no manifest work",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			f := fake.NewClientBuilder()
+			if testCase.ManifestWork != nil {
+				f = f.WithObjects(testCase.ManifestWork)
+			}
+			p := placement.NewOCMPlacer(f.Build())
+			synced, lastSync, err := p.GetSyncStatus(context.TODO(), gateway, "test")
+			if err != nil {
+				t.Fatalf("did not expect an error but got one %s", err)
+			}
+			if synced != testCase.WantSynced {
+				t.Fatalf("expected synced to be %v but got %v", testCase.WantSynced, synced)
+			}
+			if (lastSync == nil) != (testCase.WantLastSync == nil) || (lastSync != nil && !lastSync.Equal(testCase.WantLastSync)) {
+				t.Fatalf("expected last sync time %v but got %v", testCase.WantLastSync, lastSync)
+			}
+		})
+	}
+}
+
 func TestGetClusters(t *testing.T) {
 	testCases := []struct {
 		Name              string
@@ -548,6 +722,133 @@ func TestGetClusters(t *testing.T) {
 	}
 }
 
+func TestGetClusters_capacityStrategy(t *testing.T) {
+	capacities := map[string]string{"c1": "1", "c2": "3", "c3": "6"}
+	objects := []client.Object{}
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+		},
+	}
+	for cluster, capacity := range capacities {
+		objects = append(objects, &clusterv1.ManagedCluster{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        cluster,
+				Annotations: map[string]string{placement.ClusterCapacityAnnotation: capacity},
+			},
+		})
+		decision.Status.Decisions = append(decision.Status.Decisions, pd.ClusterDecision{ClusterName: cluster})
+	}
+	p := placement.NewOCMPlacer(fake.NewClientBuilder().WithObjects(append(objects, decision)...).Build())
+
+	gateway := func(name, strategy string) *v1beta1.Gateway {
+		return &v1beta1.Gateway{
+			ObjectMeta: v1.ObjectMeta{
+				Name:        name,
+				Namespace:   "test",
+				Labels:      map[string]string{placement.OCMPlacementLabel: "test"},
+				Annotations: map[string]string{placement.GatewayPlacementAnnotation: strategy},
+			},
+		}
+	}
+
+	t.Run("places gateways in proportion to the cluster capacity", func(t *testing.T) {
+		gateways := 3000
+		placed := map[string]int{}
+		for i := 0; i < gateways; i++ {
+			clusters, err := p.GetClusters(context.TODO(), gateway(fmt.Sprintf("gw-%d", i), `{"type":"Capacity"}`))
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if clusters.Len() != 1 {
+				t.Fatalf("expected a single cluster to be selected, got %v", clusters.UnsortedList())
+			}
+			placed[clusters.UnsortedList()[0]]++
+		}
+		for cluster, want := range map[string]float64{"c1": 0.1, "c2": 0.3, "c3": 0.6} {
+			got := float64(placed[cluster]) / float64(gateways)
+			if math.Abs(got-want) > 0.03 {
+				t.Errorf("expected cluster %s to get %.2f of the gateways, got %.2f", cluster, want, got)
+			}
+		}
+	})
+
+	t.Run("selects the same clusters for a gateway", func(t *testing.T) {
+		first, err := p.GetClusters(context.TODO(), gateway("gw", `{"type":"Capacity","clusters":2}`))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if first.Len() != 2 {
+			t.Fatalf("expected 2 clusters to be selected, got %v", first.UnsortedList())
+		}
+		second, err := p.GetClusters(context.TODO(), gateway("gw", `{"type":"Capacity","clusters":2}`))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !first.Equal(second) {
+			t.Errorf("expected the selection to be stable, got %v and %v", first.UnsortedList(), second.UnsortedList())
+		}
+	})
+
+	t.Run("static strategy selects all clusters", func(t *testing.T) {
+		clusters, err := p.GetClusters(context.TODO(), gateway("gw", `{"type":"Static"}`))
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !clusters.Equal(sets.New[string]("c1", "c2", "c3")) {
+			t.Errorf("expected all clusters to be selected, got %v", clusters.UnsortedList())
+		}
+	})
+}
+
+func TestGetClusters_pinned(t *testing.T) {
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}, {ClusterName: "c2"}},
+		},
+	}
+	f := fake.NewClientBuilder().WithObjects(
+		decision,
+		&clusterv1.ManagedCluster{ObjectMeta: v1.ObjectMeta{Name: "c1"}},
+		&clusterv1.ManagedCluster{ObjectMeta: v1.ObjectMeta{Name: "c3"}},
+	).Build()
+	p := placement.NewOCMPlacer(f)
+
+	gateway := &v1beta1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "gw",
+			Namespace: "test",
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Annotations: map[string]string{
+				placement.GatewayPinnedClustersAnnotation: "c3, unknown,c3",
+				placement.GatewayPlacementAnnotation:      `{"type":"Capacity"}`,
+			},
+		},
+	}
+	clusters, err := p.GetClusters(context.TODO(), gateway)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !clusters.Equal(sets.New[string]("c3")) {
+		t.Errorf("expected the gateway to be pinned to the known cluster c3 only, got %v", clusters.UnsortedList())
+	}
+
+	known, unknown, err := placement.KnownClusters(context.TODO(), f, []string{"c1", "unknown"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(known) != 1 || known[0] != "c1" || len(unknown) != 1 || unknown[0] != "unknown" {
+		t.Errorf("expected known cluster c1 and unknown cluster unknown, got %v and %v", known, unknown)
+	}
+}
+
 func TestDeschedule(t *testing.T) {
 	var manifestWorkFunc = func(downstream, name string) *workv1.ManifestWork {
 
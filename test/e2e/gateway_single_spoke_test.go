@@ -94,11 +94,13 @@ var _ = Describe("Gateway single target cluster", func() {
 				Namespace: tconfig.HubNamespace(),
 			},
 			Spec: mgcv1alpha1.TLSPolicySpec{
-				TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
-					Group:     "gateway.networking.k8s.io",
-					Kind:      "Gateway",
-					Name:      gatewayapi.ObjectName(testID),
-					Namespace: Pointer(gatewayapi.Namespace(tconfig.HubNamespace())),
+				TargetRef: mgcv1alpha1.PolicyTargetReferenceWithSectionName{
+					PolicyTargetReference: gatewayapiv1alpha2.PolicyTargetReference{
+						Group:     "gateway.networking.k8s.io",
+						Kind:      "Gateway",
+						Name:      gatewayapi.ObjectName(testID),
+						Namespace: Pointer(gatewayapi.Namespace(tconfig.HubNamespace())),
+					},
 				},
 				CertificateSpec: mgcv1alpha1.CertificateSpec{
 					IssuerRef: cmmetav1.ObjectReference{
@@ -5,10 +5,12 @@ package integration
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	ocmclusterv1 "open-cluster-management.io/api/cluster/v1"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -483,6 +485,30 @@ var _ = Describe("DNSPolicy", Ordered, func() {
 				}, time.Second*10, time.Second).Should(BeNil())
 			})
 
+			It("should remove the dns records before the gateway is deleted", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					if !metadata.HasFinalizer(gateway, DNSPolicyGatewayFinalizer) {
+						return fmt.Errorf("expected gateway finalizer %s", DNSPolicyGatewayFinalizer)
+					}
+					return k8sClient.Get(ctx, client.ObjectKey{Name: dnsRecordName, Namespace: testNamespace}, &v1alpha1.DNSRecord{})
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+
+				Expect(k8sClient.Delete(ctx, gateway)).To(BeNil())
+
+				// the gateway is only gone once its dns records have been removed
+				Eventually(func() bool {
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), &gatewayv1beta1.Gateway{})
+					return k8serrors.IsNotFound(err)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+				for _, name := range []string{dnsRecordName, wildcardDNSRecordName} {
+					err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: testNamespace}, &v1alpha1.DNSRecord{})
+					Expect(k8serrors.IsNotFound(err)).To(BeTrue(), "expected dns record %s to be deleted, got %v", name, err)
+				}
+			})
+
 			It("should remove gateway back reference on policy deletion", func() {
 				existingGateway := &gatewayv1beta1.Gateway{}
 				policyBackRefValue := testNamespace + "/" + dnsPolicy.Name
@@ -545,6 +571,128 @@ var _ = Describe("DNSPolicy", Ordered, func() {
 			})
 		})
 
+		Context("dnspolicy excluding clusters", func() {
+			var dnsPolicy *v1alpha1.DNSPolicy
+			var managedCluster *ocmclusterv1.ManagedCluster
+
+			BeforeEach(func() {
+				managedCluster = &ocmclusterv1.ManagedCluster{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: TestPlaceClusterWorkloadName,
+					},
+				}
+				Expect(k8sClient.Create(ctx, managedCluster)).To(BeNil())
+
+				// the cluster annotation maps events of the managed cluster to the policy
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					clusters, _ := json.Marshal([]string{TestPlacedClusterControlName, TestPlaceClusterWorkloadName})
+					metadata.AddAnnotation(gateway, mgcgateway.GatewayClustersAnnotation, string(clusters))
+					return k8sClient.Update(ctx, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+
+				dnsPolicy = testBuildDNSPolicyWithHealthCheck("test-dns-policy", TestPlacedGatewayName, testNamespace, nil)
+				dnsPolicy.Spec.ExcludeClusters = &metav1.LabelSelector{
+					MatchLabels: map[string]string{"maintenance": "true"},
+				}
+				Expect(k8sClient.Create(ctx, dnsPolicy)).To(BeNil())
+				Eventually(func() error { //dns policy exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: dnsPolicy.Name, Namespace: dnsPolicy.Namespace}, dnsPolicy)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				err := k8sClient.Delete(ctx, managedCluster)
+				Expect(client.IgnoreNotFound(err)).ToNot(HaveOccurred())
+			})
+
+			It("should remove and restore a cluster in the dns record", func() {
+				recordTargets := func() ([]string, error) {
+					record := &v1alpha1.DNSRecord{}
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: dnsRecordName, Namespace: testNamespace}, record); err != nil {
+						return nil, err
+					}
+					var targets []string
+					for _, endpoint := range record.Spec.Endpoints {
+						targets = append(targets, endpoint.Targets...)
+					}
+					return targets, nil
+				}
+				setMaintenance := func(value string) {
+					Eventually(func() error {
+						if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(managedCluster), managedCluster); err != nil {
+							return err
+						}
+						managedCluster.SetLabels(map[string]string{"maintenance": value})
+						return k8sClient.Update(ctx, managedCluster)
+					}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+				}
+
+				Eventually(recordTargets, TestTimeoutMedium, TestRetryIntervalMedium).Should(ContainElements(TestAttachedRouteAddressOne, TestAttachedRouteAddressTwo))
+
+				By("labelling the cluster for maintenance")
+				setMaintenance("true")
+				Eventually(recordTargets, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(ContainElement(TestAttachedRouteAddressTwo))
+				Expect(recordTargets()).To(ContainElement(TestAttachedRouteAddressOne))
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsPolicy), dnsPolicy); err != nil {
+						return err
+					}
+					cond := meta.FindStatusCondition(dnsPolicy.Status.Conditions, string(DNSPolicyClustersExcluded))
+					if cond == nil || cond.Status != metav1.ConditionTrue {
+						return fmt.Errorf("expected status condition %s to be True", DNSPolicyClustersExcluded)
+					}
+					if !strings.Contains(cond.Message, TestPlaceClusterWorkloadName) {
+						return fmt.Errorf("expected status condition %s to list %s, got %q", DNSPolicyClustersExcluded, TestPlaceClusterWorkloadName, cond.Message)
+					}
+					return nil
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+
+				By("ending the cluster maintenance")
+				setMaintenance("false")
+				Eventually(recordTargets, TestTimeoutMedium, TestRetryIntervalMedium).Should(ContainElements(TestAttachedRouteAddressOne, TestAttachedRouteAddressTwo))
+				Eventually(func() *metav1.Condition {
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsPolicy), dnsPolicy)).To(BeNil())
+					return meta.FindStatusCondition(dnsPolicy.Status.Conditions, string(DNSPolicyClustersExcluded))
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+			})
+		})
+
+		Context("dnspolicy with host overrides", func() {
+			var dnsPolicy *v1alpha1.DNSPolicy
+
+			BeforeEach(func() {
+				dnsPolicy = testBuildDNSPolicyWithHealthCheck("test-dns-policy", TestPlacedGatewayName, testNamespace, nil)
+				dnsPolicy.Spec.HostOverrides = []v1alpha1.HostOverride{
+					{ListenerHostname: TestAttachedRouteName, Hostname: "public.example.com"},
+				}
+				Expect(k8sClient.Create(ctx, dnsPolicy)).To(BeNil())
+				Eventually(func() error { //dns policy exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: dnsPolicy.Name, Namespace: dnsPolicy.Namespace}, dnsPolicy)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+			})
+
+			It("should publish the dns record under the host override", func() {
+				Eventually(func() ([]string, error) {
+					record := &v1alpha1.DNSRecord{}
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: dnsRecordName, Namespace: testNamespace}, record); err != nil {
+						return nil, err
+					}
+					var dnsNames []string
+					for _, endpoint := range record.Spec.Endpoints {
+						dnsNames = append(dnsNames, endpoint.DNSName)
+					}
+					return dnsNames, nil
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(And(
+					ContainElement("public.example.com"),
+					ContainElement("lb-"+lbHash+".public.example.com"),
+					Not(ContainElement(TestAttachedRouteName)),
+				))
+			})
+		})
+
 		Context("geo dnspolicy", func() {
 			var dnsPolicy *v1alpha1.DNSPolicy
 
@@ -840,6 +988,22 @@ var _ = Describe("DNSPolicy", Ordered, func() {
 			}, time.Second*15, time.Second).Should(BeNil())
 		})
 
+		It("should report no records published for the gateway", func() {
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsPolicy), dnsPolicy); err != nil {
+					return err
+				}
+				cond := meta.FindStatusCondition(dnsPolicy.Status.Conditions, string(DNSPolicyRecordsPublished))
+				if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != DNSPolicyReasonNoReadyClusters {
+					return fmt.Errorf("expected status condition %s to be False with reason %s, got %v", DNSPolicyRecordsPublished, DNSPolicyReasonNoReadyClusters, cond)
+				}
+				if !strings.Contains(cond.Message, testGatewayName) {
+					return fmt.Errorf("expected status condition %s to name gateway %s, got %q", DNSPolicyRecordsPublished, testGatewayName, cond.Message)
+				}
+				return nil
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+		})
+
 		It("should set gateway back reference", func() {
 			existingGateway := &gatewayv1beta1.Gateway{}
 			policyBackRefValue := testNamespace + "/" + dnsPolicy.Name
@@ -0,0 +1,214 @@
+//go:build integration
+
+package integration
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("DNSPolicy webhook", func() {
+
+	var testNamespace string
+	var dnsPolicy *v1alpha1.DNSPolicy
+
+	BeforeEach(func() {
+		CreateNamespace(&testNamespace)
+		dnsPolicy = &v1alpha1.DNSPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-dns-policy",
+				Namespace: testNamespace,
+			},
+			Spec: v1alpha1.DNSPolicySpec{
+				TargetRef: gatewayapiv1alpha2.PolicyTargetReference{
+					Group: "gateway.networking.k8s.io",
+					Kind:  "Gateway",
+					Name:  "test-gateway",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		policyList := v1alpha1.DNSPolicyList{}
+		Expect(k8sClient.List(ctx, &policyList, client.InNamespace(testNamespace))).To(BeNil())
+		for _, policy := range policyList.Items {
+			k8sClient.Delete(ctx, &policy)
+		}
+	})
+
+	It("should default to weighted routing when load balancing is unset", func() {
+		Expect(k8sClient.Create(ctx, dnsPolicy)).To(Succeed())
+		Expect(dnsPolicy.Spec.LoadBalancing).NotTo(BeNil())
+		Expect(dnsPolicy.Spec.LoadBalancing.Weighted).NotTo(BeNil())
+		Expect(dnsPolicy.Spec.LoadBalancing.Weighted.DefaultWeight).To(Equal(v1alpha1.DefaultWeight))
+		Expect(dnsPolicy.Spec.LoadBalancing.Geo).To(BeNil())
+		Expect(dnsPolicy.Spec.HealthCheck).To(BeNil())
+	})
+
+	It("should default the health check interval and protocol when health checks are enabled", func() {
+		dnsPolicy.Spec.HealthCheck = &v1alpha1.HealthCheckSpec{
+			Endpoint: "/",
+		}
+		Expect(k8sClient.Create(ctx, dnsPolicy)).To(Succeed())
+		Expect(dnsPolicy.Spec.HealthCheck.Interval).To(Equal(&metav1.Duration{Duration: 30 * time.Second}))
+		Expect(dnsPolicy.Spec.HealthCheck.Protocol).NotTo(BeNil())
+		Expect(*dnsPolicy.Spec.HealthCheck.Protocol).To(Equal(v1alpha1.HttpsProtocol))
+	})
+
+	It("should leave explicitly set values untouched", func() {
+		protocol := v1alpha1.HttpProtocol
+		dnsPolicy.Spec.LoadBalancing = &v1alpha1.LoadBalancingSpec{
+			Geo: &v1alpha1.LoadBalancingGeo{
+				DefaultGeo: "IE",
+			},
+		}
+		dnsPolicy.Spec.HealthCheck = &v1alpha1.HealthCheckSpec{
+			Endpoint: "/",
+			Protocol: &protocol,
+			Interval: &metav1.Duration{Duration: time.Minute},
+		}
+		Expect(k8sClient.Create(ctx, dnsPolicy)).To(Succeed())
+		Expect(dnsPolicy.Spec.LoadBalancing.Weighted).To(BeNil())
+		Expect(dnsPolicy.Spec.LoadBalancing.Geo.DefaultGeo).To(Equal("IE"))
+		Expect(dnsPolicy.Spec.HealthCheck.Interval).To(Equal(&metav1.Duration{Duration: time.Minute}))
+		Expect(*dnsPolicy.Spec.HealthCheck.Protocol).To(Equal(v1alpha1.HttpProtocol))
+	})
+
+	It("should not change an already defaulted policy on update", func() {
+		Expect(k8sClient.Create(ctx, dnsPolicy)).To(Succeed())
+		defaulted := dnsPolicy.Spec.DeepCopy()
+
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsPolicy), dnsPolicy); err != nil {
+				return err
+			}
+			return k8sClient.Update(ctx, dnsPolicy)
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+		Expect(dnsPolicy.Spec).To(Equal(*defaulted))
+	})
+
+	Context("geo load balancing", func() {
+		var gateway *gatewayv1beta1.Gateway
+		var managedZone *v1alpha1.ManagedZone
+
+		BeforeEach(func() {
+			managedZone = testBuildManagedZone("example.com", testNamespace)
+			managedZone.Spec.SecretRef = nil
+			managedZone.Spec.Provider = v1alpha1.DNSProviderTypeAWS
+			Expect(k8sClient.Create(ctx, managedZone)).To(Succeed())
+			gateway = testBuildGateway("test-gateway", "kuadrant-multi-cluster-gateway-instance-per-cluster", "test.example.com", testNamespace, dnsPolicy.Name)
+			Expect(k8sClient.Create(ctx, gateway)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, gateway))).To(Succeed())
+			k8sClient.Delete(ctx, managedZone)
+		})
+
+		// createEventuallyRejected creates the policy until the webhook rejects it, the webhook reads the gateway and
+		// managed zone from the cache of the manager which may not have them yet
+		createEventuallyRejected := func() error {
+			var err error
+			Eventually(func() error {
+				policy := dnsPolicy.DeepCopy()
+				policy.Name = ""
+				policy.GenerateName = dnsPolicy.Name + "-"
+				err = k8sClient.Create(ctx, policy)
+				return err
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(HaveOccurred())
+			return err
+		}
+
+		It("should reject a geo code not recognized by the provider of the target hosts", func() {
+			dnsPolicy.Spec.LoadBalancing = &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo: "europe-west1",
+				},
+			}
+			err := createEventuallyRejected()
+			Expect(err.Error()).To(ContainSubstring("geo code europe-west1 is not supported by managed zone example.com"))
+			Expect(err.Error()).To(ContainSubstring("must be a continent or ISO 3166 country code"))
+		})
+
+		It("should reject an unrecognized geo code mapped from a region", func() {
+			dnsPolicy.Spec.LoadBalancing = &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo: "IE",
+					RegionGeoCodes: []v1alpha1.RegionGeoCode{
+						{Region: "us-east-1", GeoCode: "US-EAST"},
+					},
+				},
+			}
+			err := createEventuallyRejected()
+			Expect(err.Error()).To(ContainSubstring("geo code US-EAST is not supported by managed zone example.com"))
+		})
+
+		It("should allow the geo codes recognized by the provider", func() {
+			dnsPolicy.Spec.LoadBalancing = &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo: "IE",
+					RegionGeoCodes: []v1alpha1.RegionGeoCode{
+						{Region: "us-east-1", GeoCode: "US"},
+						{Region: "ap-southeast-1", GeoCode: "AS"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, dnsPolicy)).To(Succeed())
+		})
+
+		It("should reject regions sharing a geo code without weights", func() {
+			dnsPolicy.Spec.LoadBalancing = &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo: "IE",
+					RegionGeoCodes: []v1alpha1.RegionGeoCode{
+						{Region: "us-east-1", GeoCode: "US"},
+						{Region: "us-west-2", GeoCode: "US"},
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, dnsPolicy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("regions us-east-1, us-west-2 are all mapped to geo code US"))
+		})
+
+		It("should reject a region mapped to the default geo without weights", func() {
+			dnsPolicy.Spec.LoadBalancing = &v1alpha1.LoadBalancingSpec{
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo: "IE",
+					RegionGeoCodes: []v1alpha1.RegionGeoCode{
+						{Region: "eu-west-1", GeoCode: "IE"},
+					},
+				},
+			}
+			err := k8sClient.Create(ctx, dnsPolicy)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("region eu-west-1 is mapped to the defaultGeo IE"))
+		})
+
+		It("should allow regions sharing a geo code with explicit weights", func() {
+			dnsPolicy.Spec.LoadBalancing = &v1alpha1.LoadBalancingSpec{
+				Weighted: &v1alpha1.LoadBalancingWeighted{
+					DefaultWeight: 120,
+				},
+				Geo: &v1alpha1.LoadBalancingGeo{
+					DefaultGeo: "IE",
+					RegionGeoCodes: []v1alpha1.RegionGeoCode{
+						{Region: "us-east-1", GeoCode: "US"},
+						{Region: "us-west-2", GeoCode: "US"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, dnsPolicy)).To(Succeed())
+		})
+	})
+})
@@ -18,9 +18,11 @@ package integration
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	ocmclusterv1 "open-cluster-management.io/api/cluster/v1"
 	ocmclusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
 	ocmworkv1 "open-cluster-management.io/api/work/v1"
 
@@ -35,6 +37,8 @@ import (
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	mgcgateway "github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	. "github.com/Kuadrant/multicluster-gateway-controller/test/util"
 	//+kubebuilder:scaffold:imports
 )
@@ -579,6 +583,55 @@ var _ = Describe("GatewayController", func() {
 			listener2 := listeners[1]
 			Expect(listener2.Name).To(BeEquivalentTo(fmt.Sprintf("%s.default", nsSpoke2Name)))
 			Expect(listener2.AttachedRoutes).To(BeEquivalentTo(0))
+
+			// Test the gateway is not ready until both clusters report the gateway as programmed
+			clusterSyncStatuses := func() ([]mgcgateway.ClusterSyncStatus, error) {
+				if err := k8sClient.Get(ctx, upstreamGatewayType, upstreamGateway); err != nil {
+					return nil, err
+				}
+				statuses := []mgcgateway.ClusterSyncStatus{}
+				err := json.Unmarshal([]byte(upstreamGateway.Annotations[mgcgateway.GatewayClusterStatusAnnotation]), &statuses)
+				return statuses, err
+			}
+			Eventually(clusterSyncStatuses, TestTimeoutMedium, TestRetryIntervalMedium).Should(ConsistOf(
+				HaveField("Synced", false),
+				HaveField("Synced", false),
+			))
+			Expect(meta.IsStatusConditionTrue(upstreamGateway.Status.Conditions, string(gatewayv1beta1.GatewayConditionReady))).To(BeFalse())
+
+			//Mock: Make both downstream gateways report as programmed
+			programmed := string(metav1.ConditionTrue)
+			for _, manifest := range []*ocmworkv1.ManifestWork{manifest1, manifest2} {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(manifest), manifest); err != nil {
+						return err
+					}
+					meta.SetStatusCondition(&manifest.Status.Conditions, metav1.Condition{
+						Type:    ocmworkv1.WorkAvailable,
+						Status:  metav1.ConditionTrue,
+						Reason:  "ResourcesAvailable",
+						Message: "All resources are available",
+					})
+					feedbacks := &manifest.Status.ResourceStatus.Manifests[0].StatusFeedbacks
+					feedbacks.Values = append(feedbacks.Values, ocmworkv1.FeedbackValue{
+						Name: "programmed",
+						Value: ocmworkv1.FieldValue{
+							Type:   ocmworkv1.String,
+							String: &programmed,
+						},
+					})
+					return k8sClient.Status().Update(ctx, manifest)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+			}
+
+			Eventually(clusterSyncStatuses, TestTimeoutMedium, TestRetryIntervalMedium).Should(ConsistOf(
+				SatisfyAll(HaveField("Cluster", nsSpoke1Name), HaveField("Synced", true), HaveField("LastSyncTime", Not(BeNil()))),
+				SatisfyAll(HaveField("Cluster", nsSpoke2Name), HaveField("Synced", true), HaveField("LastSyncTime", Not(BeNil()))),
+			))
+			Eventually(func() bool {
+				Expect(k8sClient.Get(ctx, upstreamGatewayType, upstreamGateway)).To(BeNil())
+				return meta.IsStatusConditionTrue(upstreamGateway.Status.Conditions, string(gatewayv1beta1.GatewayConditionReady))
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
 		})
 
 		// Tests if the placement label isnt present no manifest should be created
@@ -614,6 +667,49 @@ var _ = Describe("GatewayController", func() {
 			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
 
 		})
+
+		// Tests a gateway pinned to a cluster is only placed on that cluster, whatever the placement decision
+		It("should place a pinned gateway on the pinned cluster only", func() {
+			managedCluster := &ocmclusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: nsSpoke1Name,
+				},
+			}
+			Expect(k8sClient.Create(ctx, managedCluster)).To(BeNil())
+			DeferCleanup(func() {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, managedCluster))).To(BeNil())
+			})
+
+			gateway.Name = "test-gw-pinned"
+			gateway.Annotations = map[string]string{
+				placement.GatewayPinnedClustersAnnotation: nsSpoke1Name + ",unknown-cluster",
+			}
+			Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+
+			// Test: Passes when the manifest is found in the pinned cluster
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Namespace: nsSpoke1Name, Name: "gateway-default-test-gw-pinned"}, &ocmworkv1.ManifestWork{})
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+
+			// Test: Passes when the unknown cluster is reported without blocking the pinned cluster
+			Eventually(func() error {
+				upstreamGateway := &gatewayv1beta1.Gateway{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), upstreamGateway); err != nil {
+					return err
+				}
+				cond := meta.FindStatusCondition(upstreamGateway.Status.Conditions, string(mgcgateway.GatewayConditionClustersPinned))
+				if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != string(mgcgateway.GatewayReasonUnknownClusters) {
+					return fmt.Errorf("expected condition %s to be False with reason %s, got %v", mgcgateway.GatewayConditionClustersPinned, mgcgateway.GatewayReasonUnknownClusters, cond)
+				}
+				return nil
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+
+			// Test: Passes if the gateway is not placed on the cluster of the placement decision it is not pinned to
+			Consistently(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{Namespace: nsSpoke2Name, Name: "gateway-default-test-gw-pinned"}, &ocmworkv1.ManifestWork{})
+				return k8serrors.IsNotFound(err)
+			}, time.Second*5, time.Second).Should(BeTrue())
+		})
 	})
 
 })
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	. "github.com/onsi/gomega"
+	ocmclusterv1 "open-cluster-management.io/api/cluster/v1"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -100,6 +101,11 @@ func (f FakeOCMPlacer) GetClusters(ctx context.Context, gateway *gatewayv1beta1.
 	return f.GetPlacedClusters(ctx, gateway)
 }
 
+func (f FakeOCMPlacer) GetSyncStatus(ctx context.Context, gateway *gatewayv1beta1.Gateway, downstream string) (bool, *metav1.Time, error) {
+	placed, _ := f.GetPlacedClusters(ctx, gateway)
+	return placed.Has(downstream), nil, nil
+}
+
 func (f FakeOCMPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway *gatewayv1beta1.Gateway, listenerName string, downstream string) (int, error) {
 	count := 0
 	for _, placedCluster := range f.placedClusters {
@@ -110,6 +116,10 @@ func (f FakeOCMPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway
 	return count, nil
 }
 
+func (f FakeOCMPlacer) ListenerProgrammed(ctx context.Context, gateway *gatewayv1beta1.Gateway, listenerName string, downstream string) (metav1.ConditionStatus, error) {
+	return "", nil
+}
+
 func (f FakeOCMPlacer) GetAddresses(ctx context.Context, gateway *gatewayv1beta1.Gateway, downstream string) ([]gatewayv1beta1.GatewayAddress, error) {
 	gwAddresses := []gatewayv1beta1.GatewayAddress{}
 	t := gatewayv1beta1.IPAddressType
@@ -126,12 +136,18 @@ func (f FakeOCMPlacer) GetAddresses(ctx context.Context, gateway *gatewayv1beta1
 
 func (f FakeOCMPlacer) GetClusterGateway(ctx context.Context, gateway *gatewayv1beta1.Gateway, clusterName string) (dns.ClusterGateway, error) {
 	gwAddresses, _ := f.GetAddresses(ctx, gateway, clusterName)
-	cgw := dns.ClusterGateway{
-		Cluster: &testutil.TestResource{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: clusterName,
-			},
+	var cluster metav1.Object = &testutil.TestResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterName,
 		},
+	}
+	// use the ManagedCluster when a test created one, so its labels and annotations are taken into account
+	managedCluster := &ocmclusterv1.ManagedCluster{}
+	if err := testClient().Get(ctx, client.ObjectKey{Name: clusterName}, managedCluster); err == nil {
+		cluster = managedCluster
+	}
+	cgw := dns.ClusterGateway{
+		Cluster:          cluster,
 		GatewayAddresses: gwAddresses,
 	}
 	return cgw, nil
@@ -20,10 +20,13 @@ import (
 	. "github.com/onsi/gomega"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/managedzone"
 	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
 	//+kubebuilder:scaffold:imports
 )
@@ -80,6 +83,90 @@ var _ = Describe("ManagedZoneReconciler", func() {
 			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
 		})
 
+		Context("with a parent managed zone", func() {
+			var childZone *v1alpha1.ManagedZone
+
+			BeforeEach(func() {
+				Expect(k8sClient.Create(ctx, managedZone)).To(BeNil())
+				childZone = &v1alpha1.ManagedZone{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "team-a." + testutil.Domain,
+						Namespace: defaultNS,
+					},
+					Spec: v1alpha1.ManagedZoneSpec{
+						ID:         "team-a." + testutil.Domain,
+						DomainName: "team-a." + testutil.Domain,
+						SecretRef: &v1alpha1.SecretRef{
+							Name:      providerCredential,
+							Namespace: defaultNS,
+						},
+						ParentManagedZone: &v1alpha1.ManagedZoneReference{
+							Name: managedZone.Name,
+						},
+					},
+				}
+			})
+
+			It("should delegate the child zone in the parent zone and clean up on deletion", func() {
+				Expect(k8sClient.Create(ctx, childZone)).To(BeNil())
+
+				nsRecord := &v1alpha1.DNSRecord{}
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNS, Name: childZone.Spec.DomainName}, nsRecord)).To(Succeed())
+					g.Expect(nsRecord.Spec.ManagedZoneRef.Name).To(Equal(managedZone.Name))
+					g.Expect(nsRecord.Spec.Endpoints).To(HaveLen(1))
+					g.Expect(nsRecord.Spec.Endpoints[0].RecordType).To(Equal(string(v1alpha1.NSRecordType)))
+					g.Expect(nsRecord.Spec.Endpoints[0].Targets).To(ConsistOf("ns1." + childZone.Spec.DomainName))
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(childZone), childZone)).To(Succeed())
+					delegated := meta.FindStatusCondition(childZone.Status.Conditions, string(managedzone.ManagedZoneDelegated))
+					g.Expect(delegated).NotTo(BeNil())
+					g.Expect(delegated.Status).To(Equal(metav1.ConditionFalse))
+					g.Expect(delegated.Reason).To(Equal("ParentZoneNSRecordNotReady"))
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				meta.SetStatusCondition(&nsRecord.Status.Conditions, metav1.Condition{
+					Type:   string(conditions.ConditionTypeReady),
+					Status: metav1.ConditionTrue,
+					Reason: "ProviderSuccess",
+				})
+				Expect(k8sClient.Status().Update(ctx, nsRecord)).To(Succeed())
+
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(childZone), childZone)).To(Succeed())
+					g.Expect(meta.IsStatusConditionTrue(childZone.Status.Conditions, string(managedzone.ManagedZoneDelegated))).To(BeTrue())
+					g.Expect(meta.IsStatusConditionTrue(childZone.Status.Conditions, string(conditions.ConditionTypeReady))).To(BeTrue())
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Expect(k8sClient.Delete(ctx, childZone)).To(BeNil())
+
+				Eventually(func() bool {
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(nsRecord), nsRecord)
+					return errors.IsNotFound(err)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+			})
+
+			It("should not delegate a zone that is not a subdomain of the parent zone", func() {
+				childZone.Name = "other.com"
+				childZone.Spec.ID = "other.com"
+				childZone.Spec.DomainName = "other.com"
+				Expect(k8sClient.Create(ctx, childZone)).To(BeNil())
+
+				Eventually(func(g Gomega) {
+					g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(childZone), childZone)).To(Succeed())
+					delegated := meta.FindStatusCondition(childZone.Status.Conditions, string(managedzone.ManagedZoneDelegated))
+					g.Expect(delegated).NotTo(BeNil())
+					g.Expect(delegated.Status).To(Equal(metav1.ConditionFalse))
+					g.Expect(delegated.Reason).To(Equal("InvalidParentZone"))
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: defaultNS, Name: childZone.Spec.DomainName}, &v1alpha1.DNSRecord{})).
+					To(MatchError(ContainSubstring("not found")))
+			})
+		})
+
 		It("should reject a managed zone with an invalid domain name", func() {
 			invalidDomainNameManagedZone := &v1alpha1.ManagedZone{
 				ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,161 @@
+//go:build integration
+
+package integration
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+var _ = Describe("ManagedZone webhook", func() {
+
+	var testNamespace string
+	var managedZone *v1alpha1.ManagedZone
+	var otherZone *v1alpha1.ManagedZone
+	var dnsRecord *v1alpha1.DNSRecord
+
+	BeforeEach(func() {
+		CreateNamespace(&testNamespace)
+		managedZone = testBuildManagedZone("example.com", testNamespace)
+		Expect(k8sClient.Create(ctx, managedZone)).To(Succeed())
+		otherZone = testBuildManagedZone("other.example.com", testNamespace)
+		Expect(k8sClient.Create(ctx, otherZone)).To(Succeed())
+		dnsRecord = &v1alpha1.DNSRecord{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test.example.com",
+				Namespace: testNamespace,
+			},
+			Spec: v1alpha1.DNSRecordSpec{
+				ManagedZoneRef: &v1alpha1.ManagedZoneReference{
+					Name: managedZone.Name,
+				},
+				Endpoints: []*v1alpha1.Endpoint{
+					{
+						DNSName:    "test.example.com",
+						Targets:    []string{"172.0.0.1"},
+						RecordType: "A",
+						RecordTTL:  60,
+					},
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		recordList := &v1alpha1.DNSRecordList{}
+		Expect(k8sClient.List(ctx, recordList, client.InNamespace(testNamespace))).To(Succeed())
+		for _, record := range recordList.Items {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, &record))).To(Succeed())
+		}
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.List(ctx, recordList, client.InNamespace(testNamespace))).To(Succeed())
+			g.Expect(recordList.Items).To(BeEmpty())
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, managedZone))).To(Succeed())
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, otherZone))).To(Succeed())
+	})
+
+	It("should refuse to delete a managed zone targeted by a DNSRecord", func() {
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+
+		err := k8sClient.Delete(ctx, managedZone)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(dnsRecord.Name))
+		Expect(err.Error()).To(ContainSubstring(v1alpha1.ManagedZoneCascadeDeleteAnnotation))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone)).To(Succeed())
+		Expect(managedZone.DeletionTimestamp).To(BeNil())
+	})
+
+	It("should delete a managed zone once its DNSRecords are deleted", func() {
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, dnsRecord)).To(Succeed())
+		Eventually(func() bool {
+			return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord))
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+
+		Expect(k8sClient.Delete(ctx, managedZone)).To(Succeed())
+		Eventually(func() bool {
+			return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone))
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+	})
+
+	It("should delete the DNSRecords with a managed zone annotated for cascading deletion", func() {
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone); err != nil {
+				return err
+			}
+			managedZone.Annotations = map[string]string{v1alpha1.ManagedZoneCascadeDeleteAnnotation: "true"}
+			return k8sClient.Update(ctx, managedZone)
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+		Expect(k8sClient.Delete(ctx, managedZone)).To(Succeed())
+		Eventually(func() bool {
+			return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord))
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+		Eventually(func() bool {
+			return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone))
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+	})
+
+	It("should refuse to delete a managed zone that is an additional managed zone of a DNSRecord", func() {
+		dnsRecord.Spec.ManagedZoneRef.Name = otherZone.Name
+		dnsRecord.Spec.AdditionalManagedZoneRefs = []v1alpha1.ManagedZoneReference{{Name: managedZone.Name}}
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+
+		err := k8sClient.Delete(ctx, managedZone)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(dnsRecord.Name))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone)).To(Succeed())
+		Expect(managedZone.DeletionTimestamp).To(BeNil())
+	})
+
+	It("should only remove a managed zone annotated for cascading deletion from the DNSRecords publishing to it as an additional managed zone", func() {
+		dnsRecord.Spec.ManagedZoneRef.Name = otherZone.Name
+		dnsRecord.Spec.AdditionalManagedZoneRefs = []v1alpha1.ManagedZoneReference{{Name: managedZone.Name}}
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone); err != nil {
+				return err
+			}
+			managedZone.Annotations = map[string]string{v1alpha1.ManagedZoneCascadeDeleteAnnotation: "true"}
+			return k8sClient.Update(ctx, managedZone)
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+		Expect(k8sClient.Delete(ctx, managedZone)).To(Succeed())
+		Eventually(func() bool {
+			return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone))
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord)).To(Succeed())
+		Expect(dnsRecord.DeletionTimestamp).To(BeNil())
+		Expect(dnsRecord.Spec.ManagedZoneRef.Name).To(Equal(otherZone.Name))
+		Expect(dnsRecord.Spec.AdditionalManagedZoneRefs).To(BeEmpty())
+	})
+
+	It("should delete the CAA record of the TLSPolicies with the managed zone", func() {
+		dnsRecord.Name = managedZone.Name + "-caa"
+		dnsRecord.Labels = map[string]string{v1alpha1.TLSPolicyCAALabel: "true"}
+		dnsRecord.Spec.Endpoints = []*v1alpha1.Endpoint{
+			{
+				DNSName:    "example.com",
+				RecordType: string(v1alpha1.CAARecordType),
+				CAATargets: []v1alpha1.CAATarget{{Tag: "issue", Value: "letsencrypt.org"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, dnsRecord)).To(Succeed())
+
+		Expect(k8sClient.Delete(ctx, managedZone)).To(Succeed())
+		Eventually(func() bool {
+			return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(dnsRecord), dnsRecord))
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+		Eventually(func() bool {
+			return k8serrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedZone), managedZone))
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+	})
+})
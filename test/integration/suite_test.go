@@ -17,6 +17,9 @@ package integration
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
 	"path/filepath"
 	"testing"
 	"time"
@@ -47,6 +50,7 @@ import (
 	. "github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/managedzone"
 	. "github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/tlspolicy"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/dns/dnsprovider"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/health"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	//+kubebuilder:scaffold:imports
@@ -89,6 +93,9 @@ var _ = BeforeSuite(func() {
 			filepath.Join("../../", "config", "ocm", "crd"),
 		},
 		ErrorIfCRDPathMissing: true,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("../../", "config", "webhook")},
+		},
 	}
 
 	var err error
@@ -124,6 +131,9 @@ var _ = BeforeSuite(func() {
 		Scheme:                 scheme.Scheme,
 		HealthProbeBindAddress: "0",
 		MetricsBindAddress:     "0",
+		Host:                   testEnv.WebhookInstallOptions.LocalServingHost,
+		Port:                   testEnv.WebhookInstallOptions.LocalServingPort,
+		CertDir:                testEnv.WebhookInstallOptions.LocalServingCertDir,
 	})
 	Expect(err).ToNot(HaveOccurred())
 
@@ -159,6 +169,9 @@ var _ = BeforeSuite(func() {
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&v1alpha1.DNSPolicy{}).SetupWebhookWithManager(k8sManager, dnsprovider.NewGeoCodeValidator(k8sManager.GetClient()))
+	Expect(err).ToNot(HaveOccurred())
+
 	tlsPolicyBaseReconciler := reconcilers.NewBaseReconciler(
 		k8sManager.GetClient(), k8sManager.GetScheme(), k8sManager.GetAPIReader(),
 		logger.WithName("tlspolicy"),
@@ -172,6 +185,9 @@ var _ = BeforeSuite(func() {
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&v1alpha1.TLSPolicy{}).SetupWebhookWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	err = (&GatewayClassReconciler{
 		Client: k8sManager.GetClient(),
 		Scheme: k8sManager.GetScheme(),
@@ -192,6 +208,9 @@ var _ = BeforeSuite(func() {
 	}).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&v1alpha1.ManagedZone{}).SetupWebhookWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	err = (&DNSHealthCheckProbeReconciler{
 		Client:        k8sManager.GetClient(),
 		HealthMonitor: monitor,
@@ -204,6 +223,17 @@ var _ = BeforeSuite(func() {
 		err = k8sManager.Start(ctx)
 		Expect(err).ToNot(HaveOccurred(), "failed to run manager")
 	}()
+
+	// wait for the webhook server to be ready
+	dialer := &net.Dialer{Timeout: time.Second}
+	addrPort := fmt.Sprintf("%s:%d", testEnv.WebhookInstallOptions.LocalServingHost, testEnv.WebhookInstallOptions.LocalServingPort)
+	Eventually(func() error {
+		conn, err := tls.DialWithDialer(dialer, "tcp", addrPort, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}).Should(Succeed())
 })
 
 var _ = AfterSuite(func() {
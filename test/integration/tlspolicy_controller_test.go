@@ -5,19 +5,27 @@ package integration
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
 	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	. "github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/tlspolicy"
 	. "github.com/Kuadrant/multicluster-gateway-controller/test/util"
@@ -214,6 +222,65 @@ var _ = Describe("TLSPolicy", Ordered, func() {
 			})
 		})
 
+		Context("with missing issuer", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("missingissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should record an issuer not found event on the gateway", func() {
+				Eventually(func() error {
+					eventList := &corev1.EventList{}
+					if err := k8sClient.List(ctx, eventList, &client.ListOptions{Namespace: testNamespace}); err != nil {
+						return err
+					}
+					for _, e := range eventList.Items {
+						if e.Reason == EventReasonIssuerNotFound && e.Type == corev1.EventTypeWarning &&
+							e.InvolvedObject.Kind == "Gateway" && e.InvolvedObject.Name == gateway.Name {
+							return nil
+						}
+					}
+					return fmt.Errorf("expected %s event for gateway %s", EventReasonIssuerNotFound, gateway.Name)
+				}, time.Second*15, time.Second).Should(BeNil())
+			})
+
+			It("should become ready promptly once the issuer is created and ready", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if !meta.IsStatusConditionFalse(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady)) {
+						return fmt.Errorf("expected tlsPolicy status condition %s to be False", conditions.ConditionTypeReady)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				missingIssuer := NewTestIssuer("missingissuer", testNamespace)
+				Expect(k8sClient.Create(ctx, missingIssuer)).To(Succeed())
+				missingIssuer.Status.Conditions = []certmanv1.IssuerCondition{{Type: certmanv1.IssuerConditionReady, Status: cmmeta.ConditionTrue}}
+				Expect(k8sClient.Status().Update(ctx, missingIssuer)).To(Succeed())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if !meta.IsStatusConditionTrue(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady)) {
+						return fmt.Errorf("expected tlsPolicy status condition %s to be True", conditions.ConditionTypeReady)
+					}
+					return nil
+				}, time.Second*5, time.Second).Should(BeNil())
+			})
+		})
+
 		Context("with http listener", func() {
 
 			BeforeEach(func() {
@@ -294,6 +361,28 @@ var _ = Describe("TLSPolicy", Ordered, func() {
 				cert1 := &certmanv1.Certificate{}
 				err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert1)
 				Expect(err).ToNot(HaveOccurred())
+				Expect(cert1.Spec.CommonName).To(Equal("test.example.com"))
+			})
+
+			It("should not set the certificate common name when it is omitted", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					tlsPolicy.Spec.OmitCommonName = true
+					return k8sClient.Update(ctx, tlsPolicy)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func() error {
+					cert := &certmanv1.Certificate{}
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Spec.CommonName != "" {
+						return fmt.Errorf("expected certificate common name to be empty, got %s", cert.Spec.CommonName)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
 			})
 		})
 
@@ -338,6 +427,1070 @@ var _ = Describe("TLSPolicy", Ordered, func() {
 			})
 		})
 
+		Context("with a target selector matching two gateways", func() {
+			var greenGateway *gatewayv1beta1.Gateway
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("blue-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("blue.example.com", "blue-tls-secret").Gateway
+				gateway.Labels = map[string]string{"app": "prod-web"}
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				greenGateway = NewTestGateway("green-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("green.example.com", "green-tls-secret").Gateway
+				greenGateway.Labels = map[string]string{"app": "prod-web"}
+				Expect(k8sClient.Create(ctx, greenGateway)).To(BeNil())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithTargetSelector(map[string]string{"app": "prod-web"}).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should issue certificates for both gateways and clean up a gateway that is no longer selected", func() {
+				policyKey := client.ObjectKeyFromObject(tlsPolicy).String()
+				Eventually(func(g Gomega) {
+					blueCert := &certmanv1.Certificate{}
+					g.Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "blue-tls-secret", Namespace: testNamespace}, blueCert)).To(Succeed())
+					g.Expect(blueCert.Labels).To(HaveKeyWithValue("gateway", gateway.Name))
+					greenCert := &certmanv1.Certificate{}
+					g.Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "green-tls-secret", Namespace: testNamespace}, greenCert)).To(Succeed())
+					g.Expect(greenCert.Labels).To(HaveKeyWithValue("gateway", greenGateway.Name))
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func(g Gomega) {
+					for _, gw := range []*gatewayv1beta1.Gateway{gateway, greenGateway} {
+						g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(gw), gw)).To(Succeed())
+						g.Expect(gw.Annotations).To(HaveKeyWithValue(TLSPolicyBackRefAnnotation, policyKey))
+					}
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(greenGateway), greenGateway); err != nil {
+						return err
+					}
+					greenGateway.Labels = nil
+					return k8sClient.Update(ctx, greenGateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func(g Gomega) {
+					err := k8sClient.Get(ctx, client.ObjectKey{Name: "green-tls-secret", Namespace: testNamespace}, &certmanv1.Certificate{})
+					g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+					g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(greenGateway), greenGateway)).To(Succeed())
+					g.Expect(greenGateway.Annotations).NotTo(HaveKey(TLSPolicyBackRefAnnotation))
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+				Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "blue-tls-secret", Namespace: testNamespace}, &certmanv1.Certificate{})).To(Succeed())
+			})
+		})
+
+		Context("with httproute target", func() {
+			var route *gatewayv1beta1.HTTPRoute
+			var gatewayPolicy *v1alpha1.TLSPolicy
+
+			BeforeEach(func() {
+				wildcardHostname := gatewayv1beta1.Hostname("*.example.com")
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithListener(gatewayv1beta1.Listener{
+						Name:     "wildcard",
+						Hostname: &wildcardHostname,
+						Port:     gatewayv1beta1.PortNumber(443),
+						Protocol: gatewayv1beta1.HTTPSProtocolType,
+						TLS: &gatewayv1beta1.GatewayTLSConfig{
+							Mode: Pointer(gatewayv1beta1.TLSModeTerminate),
+							CertificateRefs: []gatewayv1beta1.SecretObjectReference{
+								{
+									Name:      "test-tls-secret",
+									Namespace: Pointer(gatewayv1beta1.Namespace(testNamespace)),
+								},
+							},
+						},
+					}).Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+
+				route = &gatewayv1beta1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-route",
+						Namespace: testNamespace,
+					},
+					Spec: gatewayv1beta1.HTTPRouteSpec{
+						CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+							ParentRefs: []gatewayv1beta1.ParentReference{
+								{Name: gatewayv1beta1.ObjectName(gateway.Name)},
+							},
+						},
+						Hostnames: []gatewayv1beta1.Hostname{"app.example.com"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, route)).To(BeNil())
+
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetHTTPRoute(route.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			AfterEach(func() {
+				Expect(k8sClient.Delete(ctx, route)).To(BeNil())
+				if gatewayPolicy != nil {
+					Expect(k8sClient.Delete(ctx, gatewayPolicy)).To(BeNil())
+					gatewayPolicy = nil
+				}
+			})
+
+			It("should create a certificate for the route hostnames", func() {
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					return k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert)
+				}, time.Second*10, time.Second).Should(BeNil())
+				Expect(cert.Spec.DNSNames).To(ConsistOf("app.example.com"))
+			})
+
+			It("should set httproute back reference", func() {
+				Eventually(func() error {
+					existingRoute := &gatewayv1beta1.HTTPRoute{}
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(route), existingRoute); err != nil {
+						return err
+					}
+					if existingRoute.GetAnnotations()[TLSPolicyBackRefAnnotation] != testNamespace+"/"+tlsPolicy.Name {
+						return fmt.Errorf("existingRoute annotations[%s] does not have expected value", TLSPolicyBackRefAnnotation)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+			})
+
+			It("should report a conflict when another policy targets overlapping hostnames", func() {
+				Eventually(func() error {
+					return k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, &certmanv1.Certificate{})
+				}, time.Second*10, time.Second).Should(BeNil())
+
+				gatewayPolicy = NewTestTLSPolicy("test-gateway-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				Expect(k8sClient.Create(ctx, gatewayPolicy)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gatewayPolicy), gatewayPolicy); err != nil {
+						return err
+					}
+					readyCond := meta.FindStatusCondition(gatewayPolicy.Status.Conditions, string(conditions.ConditionTypeReady))
+					if readyCond == nil || readyCond.Reason != string(conditions.PolicyReasonConflicted) {
+						return fmt.Errorf("expected tlsPolicy %s condition reason to be %s", conditions.ConditionTypeReady, conditions.PolicyReasonConflicted)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with certificate labels and annotations", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.CertificateLabels = map[string]string{
+					"team":    "platform",
+					"gateway": "not-allowed",
+				}
+				tlsPolicy.Spec.CertificateAnnotations = map[string]string{
+					"example.com/owner": "platform",
+				}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should propagate labels and annotations without clobbering controller labels", func() {
+				Eventually(func() error {
+					cert := &certmanv1.Certificate{}
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Labels["team"] != "platform" {
+						return fmt.Errorf("expected certificate label team to be platform")
+					}
+					if cert.Labels["gateway"] != gateway.Name {
+						return fmt.Errorf("expected certificate label gateway to be %s", gateway.Name)
+					}
+					if cert.Annotations["example.com/owner"] != "platform" {
+						return fmt.Errorf("expected certificate annotation example.com/owner to be platform")
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+			})
+
+			It("should update and remove labels on an existing certificate", func() {
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					return k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert)
+				}, time.Second*10, time.Second).Should(BeNil())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy)).To(BeNil())
+				patch := client.MergeFrom(tlsPolicy.DeepCopy())
+				tlsPolicy.Spec.CertificateLabels = map[string]string{
+					"tier": "frontend",
+				}
+				Expect(k8sClient.Patch(ctx, tlsPolicy, patch)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cert), cert); err != nil {
+						return err
+					}
+					if cert.Labels["tier"] != "frontend" {
+						return fmt.Errorf("expected certificate label tier to be frontend")
+					}
+					if _, ok := cert.Labels["team"]; ok {
+						return fmt.Errorf("expected certificate label team to be removed")
+					}
+					if cert.Labels["gateway"] != gateway.Name {
+						return fmt.Errorf("expected certificate label gateway to be %s", gateway.Name)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with a secret template", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.SecretTemplate = &certmanv1.CertificateSecretTemplate{
+					Labels: map[string]string{
+						"istio.io/managed": "true",
+					},
+				}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should set and remove the secret template labels on the certificate", func() {
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Spec.SecretTemplate == nil || cert.Spec.SecretTemplate.Labels["istio.io/managed"] != "true" {
+						return fmt.Errorf("expected certificate secret template label istio.io/managed to be true")
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy)).To(BeNil())
+				patch := client.MergeFrom(tlsPolicy.DeepCopy())
+				tlsPolicy.Spec.SecretTemplate = nil
+				Expect(k8sClient.Patch(ctx, tlsPolicy, patch)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cert), cert); err != nil {
+						return err
+					}
+					if _, ok := cert.Spec.SecretTemplate.Labels["istio.io/managed"]; ok {
+						return fmt.Errorf("expected certificate secret template label istio.io/managed to be removed")
+					}
+					if cert.Spec.SecretTemplate.Labels["gateway"] != gateway.Name {
+						return fmt.Errorf("expected certificate secret template label gateway to be %s", gateway.Name)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with client auth usages", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.Usages = []certmanv1.KeyUsage{
+					certmanv1.UsageDigitalSignature,
+					certmanv1.UsageKeyEncipherment,
+					certmanv1.UsageServerAuth,
+					certmanv1.UsageClientAuth,
+				}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should issue the certificate with the client auth extended key usage", func() {
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if !reflect.DeepEqual(cert.Spec.Usages, tlsPolicy.Spec.Usages) {
+						return fmt.Errorf("expected certificate usages %v, got %v", tlsPolicy.Spec.Usages, cert.Spec.Usages)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with a paused policy", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should not recreate the certificate until the policy is resumed", func() {
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					return k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert)
+				}, time.Second*10, time.Second).Should(BeNil())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy)).To(BeNil())
+				patch := client.MergeFrom(tlsPolicy.DeepCopy())
+				metav1.SetMetaDataAnnotation(&tlsPolicy.ObjectMeta, metadata.PausedAnnotation, "true")
+				Expect(k8sClient.Patch(ctx, tlsPolicy, patch)).To(BeNil())
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if !meta.IsStatusConditionTrue(tlsPolicy.Status.Conditions, string(conditions.ConditionTypePaused)) {
+						return fmt.Errorf("expected tlsPolicy status condition %s to be True", conditions.ConditionTypePaused)
+					}
+					return nil
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+
+				Expect(k8sClient.Delete(ctx, cert)).To(BeNil())
+				Consistently(func() bool {
+					err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cert), &certmanv1.Certificate{})
+					return apierrors.IsNotFound(err)
+				}, time.Second*5, time.Second).Should(BeTrue())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy)).To(BeNil())
+				patch = client.MergeFrom(tlsPolicy.DeepCopy())
+				delete(tlsPolicy.Annotations, metadata.PausedAnnotation)
+				Expect(k8sClient.Patch(ctx, tlsPolicy, patch)).To(BeNil())
+
+				Eventually(func() error {
+					return k8sClient.Get(ctx, client.ObjectKeyFromObject(cert), cert)
+				}, time.Second*10, time.Second).Should(BeNil())
+				Eventually(func() bool {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return false
+					}
+					return meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(conditions.ConditionTypePaused)) == nil
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
+			})
+		})
+
+		Context("with multiple issuers", func() {
+
+			BeforeEach(func() {
+				fallbackIssuer := NewTestIssuer("fallbackissuer", testNamespace)
+				Expect(k8sClient.Create(ctx, fallbackIssuer)).To(BeNil())
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).TLSPolicy
+				tlsPolicy.Spec.IssuerRefs = []cmmeta.ObjectReference{
+					{Name: "testissuer", Kind: certmanv1.IssuerKind, Group: "cert-manager.io"},
+					{Name: "fallbackissuer", Kind: certmanv1.IssuerKind, Group: "cert-manager.io"},
+				}
+				tlsPolicy.Spec.IssuerFallbackThreshold = &metav1.Duration{Duration: time.Second}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should use the first issuer and report it as active", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if tlsPolicy.Status.ActiveIssuer == nil || tlsPolicy.Status.ActiveIssuer.Name != "testissuer" {
+						return fmt.Errorf("expected tlsPolicy active issuer to be testissuer")
+					}
+					cert := &certmanv1.Certificate{}
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Spec.IssuerRef.Name != "testissuer" {
+						return fmt.Errorf("expected certificate issuer to be testissuer")
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+			})
+
+			It("should fall back to the next issuer when the certificate keeps failing", func() {
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					return k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert)
+				}, time.Second*10, time.Second).Should(BeNil())
+
+				now := metav1.Now()
+				cert.Status.LastFailureTime = &now
+				cert.Status.Conditions = []certmanv1.CertificateCondition{{
+					Type:               certmanv1.CertificateConditionReady,
+					Status:             cmmeta.ConditionFalse,
+					Reason:             "Failed",
+					LastTransitionTime: &now,
+				}}
+				Expect(k8sClient.Status().Update(ctx, cert)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if tlsPolicy.Status.ActiveIssuer == nil || tlsPolicy.Status.ActiveIssuer.Name != "fallbackissuer" {
+						return fmt.Errorf("expected tlsPolicy active issuer to be fallbackissuer")
+					}
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cert), cert); err != nil {
+						return err
+					}
+					if cert.Spec.IssuerRef.Name != "fallbackissuer" {
+						return fmt.Errorf("expected certificate issuer to be fallbackissuer")
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with duration and renewBefore", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+			})
+
+			It("should set and update the certificate duration and renewBefore in place", func() {
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.Duration = &metav1.Duration{Duration: time.Hour * 24}
+				tlsPolicy.Spec.RenewBefore = &metav1.Duration{Duration: time.Hour * 8}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Spec.Duration == nil || cert.Spec.Duration.Duration != time.Hour*24 {
+						return fmt.Errorf("expected certificate duration to be %v", time.Hour*24)
+					}
+					if cert.Spec.RenewBefore == nil || cert.Spec.RenewBefore.Duration != time.Hour*8 {
+						return fmt.Errorf("expected certificate renewBefore to be %v", time.Hour*8)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+				certUID := cert.UID
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy)).To(BeNil())
+				patch := client.MergeFrom(tlsPolicy.DeepCopy())
+				tlsPolicy.Spec.Duration = &metav1.Duration{Duration: time.Hour * 48}
+				Expect(k8sClient.Patch(ctx, tlsPolicy, patch)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Spec.Duration == nil || cert.Spec.Duration.Duration != time.Hour*48 {
+						return fmt.Errorf("expected certificate duration to be %v", time.Hour*48)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+				Expect(cert.UID).To(Equal(certUID))
+			})
+
+			It("should not be ready when renewBefore is not shorter than duration", func() {
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.Duration = &metav1.Duration{Duration: time.Hour * 8}
+				tlsPolicy.Spec.RenewBefore = &metav1.Duration{Duration: time.Hour * 8}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					readyCond := meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady))
+					if readyCond == nil || readyCond.Status != metav1.ConditionFalse {
+						return fmt.Errorf("expected tlsPolicy %s condition to be False", conditions.ConditionTypeReady)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with private key options", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+			})
+
+			It("should set an ECDSA P-256 key on the certificate and update it in place", func() {
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.PrivateKey = &certmanv1.CertificatePrivateKey{
+					Algorithm: certmanv1.ECDSAKeyAlgorithm,
+					Size:      256,
+				}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				cert := &certmanv1.Certificate{}
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Spec.PrivateKey == nil || cert.Spec.PrivateKey.Algorithm != certmanv1.ECDSAKeyAlgorithm || cert.Spec.PrivateKey.Size != 256 {
+						return fmt.Errorf("expected certificate private key to be %s 256, got %+v", certmanv1.ECDSAKeyAlgorithm, cert.Spec.PrivateKey)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+				certUID := cert.UID
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy)).To(BeNil())
+				patch := client.MergeFrom(tlsPolicy.DeepCopy())
+				tlsPolicy.Spec.PrivateKey.Size = 384
+				tlsPolicy.Spec.PrivateKey.RotationPolicy = certmanv1.RotationPolicyAlways
+				Expect(k8sClient.Patch(ctx, tlsPolicy, patch)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if cert.Spec.PrivateKey.Size != 384 || cert.Spec.PrivateKey.RotationPolicy != certmanv1.RotationPolicyAlways {
+						return fmt.Errorf("expected certificate private key to be updated, got %+v", cert.Spec.PrivateKey)
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+				Expect(cert.UID).To(Equal(certUID))
+			})
+		})
+
+		Context("with an existing secret", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("test.example.com", "test-tls-secret").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithSecretRef("test-tls-secret").TLSPolicy
+			})
+
+			It("should be ready and not create any certificates", func() {
+				secret, err := NewTestTLSSecret("test-tls-secret", testNamespace, "test.example.com")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(k8sClient.Create(ctx, secret)).To(BeNil())
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if !meta.IsStatusConditionTrue(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady)) {
+						return fmt.Errorf("expected tlsPolicy status condition to be %s", string(conditions.ConditionTypeReady))
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+				Expect(tlsPolicy.Status.ActiveIssuer).To(BeNil())
+
+				Consistently(func() []certmanv1.Certificate {
+					certList := &certmanv1.CertificateList{}
+					err := k8sClient.List(ctx, certList, &client.ListOptions{Namespace: testNamespace})
+					Expect(err).ToNot(HaveOccurred())
+					return certList.Items
+				}, time.Second*5, time.Second).Should(BeEmpty())
+			})
+
+			It("should not be ready until the secret holds a valid certificate and key", func() {
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					readyCond := meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady))
+					if readyCond == nil || readyCond.Status != metav1.ConditionFalse || readyCond.Reason != "InvalidSecret" {
+						return fmt.Errorf("expected tlsPolicy %s condition to be False with reason InvalidSecret", conditions.ConditionTypeReady)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				secret, err := NewTestTLSSecret("test-tls-secret", testNamespace, "test.example.com")
+				Expect(err).ToNot(HaveOccurred())
+				delete(secret.Data, corev1.TLSPrivateKeyKey)
+				secret.Type = corev1.SecretTypeOpaque
+				Expect(k8sClient.Create(ctx, secret)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					readyCond := meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady))
+					if readyCond == nil || readyCond.Status != metav1.ConditionFalse || !strings.Contains(readyCond.Message, corev1.TLSPrivateKeyKey) {
+						return fmt.Errorf("expected tlsPolicy %s condition to report the missing %s", conditions.ConditionTypeReady, corev1.TLSPrivateKeyKey)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				valid, err := NewTestTLSSecret("test-tls-secret", testNamespace, "test.example.com")
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+						return err
+					}
+					secret.Data = valid.Data
+					return k8sClient.Update(ctx, secret)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if !meta.IsStatusConditionTrue(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady)) {
+						return fmt.Errorf("expected tlsPolicy status condition to be %s", string(conditions.ConditionTypeReady))
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with auto configured listeners", func() {
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithListener(gatewayv1beta1.Listener{
+						Name:     "api",
+						Hostname: Pointer(gatewayv1beta1.Hostname("api.example.com")),
+						Port:     gatewayv1beta1.PortNumber(443),
+						Protocol: gatewayv1beta1.HTTPSProtocolType,
+					}).
+					WithHTTPSListener("www.example.com", "user-tls-secret").
+					WithHTTPListener("test.example.com").Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.AutoConfigureListeners = true
+				tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should configure the selected listeners and leave the others untouched", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					for _, l := range gateway.Spec.Listeners {
+						if l.Name != "api" {
+							continue
+						}
+						if l.TLS == nil || len(l.TLS.CertificateRefs) != 1 || l.TLS.CertificateRefs[0].Name != "test-gateway-api-tls" {
+							return fmt.Errorf("expected listener %s to reference test-gateway-api-tls, got %+v", l.Name, l.TLS)
+						}
+						if l.TLS.Mode == nil || *l.TLS.Mode != gatewayv1beta1.TLSModeTerminate {
+							return fmt.Errorf("expected listener %s TLS mode to be %s", l.Name, gatewayv1beta1.TLSModeTerminate)
+						}
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+				Expect(gateway.Annotations).To(HaveKeyWithValue(TLSPolicyListenersAnnotation, "api=test-gateway-api-tls"))
+
+				for _, l := range gateway.Spec.Listeners {
+					switch l.Name {
+					case "test.example.com":
+						Expect(l.TLS).To(BeNil())
+					case "www.example.com":
+						Expect(l.TLS.CertificateRefs).To(HaveLen(1))
+						Expect(l.TLS.CertificateRefs[0].Name).To(Equal(gatewayv1beta1.ObjectName("user-tls-secret")))
+					}
+				}
+
+				Eventually(func() error {
+					cert := &certmanv1.Certificate{}
+					return k8sClient.Get(ctx, client.ObjectKey{Name: "test-gateway-api-tls", Namespace: testNamespace}, cert)
+				}, time.Second*10, time.Second).Should(BeNil())
+			})
+
+			It("should back off when a configured listener is changed by other means", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					if gateway.Annotations[TLSPolicyListenersAnnotation] != "api=test-gateway-api-tls" {
+						return fmt.Errorf("expected listener api to be configured by the policy")
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					for i := range gateway.Spec.Listeners {
+						if gateway.Spec.Listeners[i].Name == "api" {
+							gateway.Spec.Listeners[i].TLS.CertificateRefs[0].Name = "manual-tls-secret"
+						}
+					}
+					return k8sClient.Update(ctx, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					if _, ok := gateway.Annotations[TLSPolicyListenersAnnotation]; ok {
+						return fmt.Errorf("expected the policy to stop managing listener api")
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				Consistently(func() gatewayv1beta1.ObjectName {
+					Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway)).To(Succeed())
+					for _, l := range gateway.Spec.Listeners {
+						if l.Name == "api" {
+							return l.TLS.CertificateRefs[0].Name
+						}
+					}
+					return ""
+				}, time.Second*5, time.Second).Should(Equal(gatewayv1beta1.ObjectName("manual-tls-secret")))
+			})
+		})
+
+		Context("with policies targeting listeners by name", func() {
+			var adminPolicy *v1alpha1.TLSPolicy
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithListener(gatewayv1beta1.Listener{
+						Name:     "api",
+						Hostname: Pointer(gatewayv1beta1.Hostname("api.example.com")),
+						Port:     gatewayv1beta1.PortNumber(443),
+						Protocol: gatewayv1beta1.HTTPSProtocolType,
+					}).
+					WithListener(gatewayv1beta1.Listener{
+						Name:     "admin",
+						Hostname: Pointer(gatewayv1beta1.Hostname("admin.example.com")),
+						Port:     gatewayv1beta1.PortNumber(443),
+						Protocol: gatewayv1beta1.HTTPSProtocolType,
+					}).Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+
+				tlsPolicy = NewTestTLSPolicy("api-tls-policy", testNamespace).
+					WithTargetGatewayListener(gateway.Name, "api").
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.AutoConfigureListeners = true
+				tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				adminPolicy = NewTestTLSPolicy("admin-tls-policy", testNamespace).
+					WithTargetGatewayListener(gateway.Name, "admin").
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				adminPolicy.Spec.AutoConfigureListeners = true
+				adminPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com"}
+				Expect(k8sClient.Create(ctx, adminPolicy)).To(BeNil())
+			})
+
+			It("should only configure and issue certificates for the targeted listener of each policy", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					if val := gateway.Annotations[TLSPolicyListenersAnnotation]; val != "admin=test-gateway-admin-tls,api=test-gateway-api-tls" {
+						return fmt.Errorf("expected both listeners to be configured, got %q", val)
+					}
+					if val := gateway.Annotations[TLSPolicyBackRefAnnotation+".api"]; val != testNamespace+"/"+tlsPolicy.Name {
+						return fmt.Errorf("expected listener api to be claimed by %s, got %q", tlsPolicy.Name, val)
+					}
+					if val := gateway.Annotations[TLSPolicyBackRefAnnotation+".admin"]; val != testNamespace+"/"+adminPolicy.Name {
+						return fmt.Errorf("expected listener admin to be claimed by %s, got %q", adminPolicy.Name, val)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+				Expect(gateway.Annotations).ToNot(HaveKey(TLSPolicyBackRefAnnotation))
+
+				for policyName, certName := range map[string]string{tlsPolicy.Name: "test-gateway-api-tls", adminPolicy.Name: "test-gateway-admin-tls"} {
+					Eventually(func() error {
+						cert := &certmanv1.Certificate{}
+						if err := k8sClient.Get(ctx, client.ObjectKey{Name: certName, Namespace: testNamespace}, cert); err != nil {
+							return err
+						}
+						if cert.Labels[TLSPolicyBackRefAnnotation] != policyName {
+							return fmt.Errorf("expected certificate %s to belong to %s, got %s", certName, policyName, cert.Labels[TLSPolicyBackRefAnnotation])
+						}
+						return nil
+					}, time.Second*10, time.Second).Should(BeNil())
+				}
+			})
+
+			It("should keep the other listener configured when a policy is deleted", func() {
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					if val := gateway.Annotations[TLSPolicyListenersAnnotation]; val != "admin=test-gateway-admin-tls,api=test-gateway-api-tls" {
+						return fmt.Errorf("expected both listeners to be configured, got %q", val)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				Expect(k8sClient.Delete(ctx, adminPolicy)).To(Succeed())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					if val := gateway.Annotations[TLSPolicyListenersAnnotation]; val != "api=test-gateway-api-tls" {
+						return fmt.Errorf("expected only listener api to be configured, got %q", val)
+					}
+					if _, ok := gateway.Annotations[TLSPolicyBackRefAnnotation+".admin"]; ok {
+						return fmt.Errorf("expected listener admin to no longer be claimed")
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+				Expect(gateway.Annotations).To(HaveKeyWithValue(TLSPolicyBackRefAnnotation+".api", testNamespace+"/"+tlsPolicy.Name))
+			})
+		})
+
+		Context("with consolidated wildcard listeners", func() {
+
+			httpsListener := func(name, hostname string) gatewayv1beta1.Listener {
+				return gatewayv1beta1.Listener{
+					Name:     gatewayv1beta1.SectionName(name),
+					Hostname: Pointer(gatewayv1beta1.Hostname(hostname)),
+					Port:     gatewayv1beta1.PortNumber(443),
+					Protocol: gatewayv1beta1.HTTPSProtocolType,
+				}
+			}
+
+			BeforeEach(func() {
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithListener(httpsListener("a", "a.example.com")).
+					WithListener(httpsListener("b", "b.example.com")).
+					WithListener(httpsListener("c", "c.example.com")).Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				tlsPolicy.Spec.AutoConfigureListeners = true
+				tlsPolicy.Spec.ConsolidateWildcard = true
+				tlsPolicy.Spec.ListenerHostnames = []gatewayv1beta1.Hostname{"*.example.com", "*.other.com"}
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+			})
+
+			It("should issue a single wildcard certificate shared by the listeners", func() {
+				Eventually(func() error {
+					cert := &certmanv1.Certificate{}
+					if err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-gateway-wildcard-tls", Namespace: testNamespace}, cert); err != nil {
+						return err
+					}
+					if len(cert.Spec.DNSNames) != 1 || cert.Spec.DNSNames[0] != "*.example.com" {
+						return fmt.Errorf("expected certificate dnsNames to be [*.example.com], got %v", cert.Spec.DNSNames)
+					}
+					if cert.Spec.CommonName != "*.example.com" {
+						return fmt.Errorf("expected certificate common name to be *.example.com, got %s", cert.Spec.CommonName)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				certList := &certmanv1.CertificateList{}
+				Expect(k8sClient.List(ctx, certList, client.InNamespace(testNamespace))).To(BeNil())
+				Expect(certList.Items).To(HaveLen(1))
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway)).To(Succeed())
+				for _, l := range gateway.Spec.Listeners {
+					Expect(l.TLS).NotTo(BeNil())
+					Expect(l.TLS.CertificateRefs).To(HaveLen(1))
+					Expect(l.TLS.CertificateRefs[0].Name).To(Equal(gatewayv1beta1.ObjectName("test-gateway-wildcard-tls")))
+				}
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					if !meta.IsStatusConditionTrue(tlsPolicy.Status.Conditions, string(WildcardConsolidated)) {
+						return fmt.Errorf("expected tlsPolicy status condition %s to be True", WildcardConsolidated)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+			})
+
+			It("should issue individual certificates once the listeners have mixed domains", func() {
+				Eventually(func() error {
+					cert := &certmanv1.Certificate{}
+					return k8sClient.Get(ctx, client.ObjectKey{Name: "test-gateway-wildcard-tls", Namespace: testNamespace}, cert)
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(gateway), gateway); err != nil {
+						return err
+					}
+					gateway.Spec.Listeners = append(gateway.Spec.Listeners, httpsListener("other", "api.other.com"))
+					return k8sClient.Update(ctx, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).Should(Succeed())
+
+				Eventually(func() error {
+					certList := &certmanv1.CertificateList{}
+					if err := k8sClient.List(ctx, certList, client.InNamespace(testNamespace)); err != nil {
+						return err
+					}
+					var names []string
+					for _, cert := range certList.Items {
+						names = append(names, cert.Name)
+					}
+					if len(names) != 4 || slice.ContainsString(names, "test-gateway-wildcard-tls") {
+						return fmt.Errorf("expected an individual certificate per listener, got %v", names)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					cond := meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(WildcardConsolidated))
+					if cond == nil || cond.Status != metav1.ConditionFalse {
+						return fmt.Errorf("expected tlsPolicy status condition %s to be False, got %+v", WildcardConsolidated, cond)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+			})
+		})
+
+		Context("with wildcard and apex https listeners sharing a secret", func() {
+			var managedZone *v1alpha1.ManagedZone
+
+			BeforeEach(func() {
+				wildcardHostname := gatewayv1beta1.Hostname("*.example.com")
+				gateway = NewTestGateway("test-gateway", gwClassName, testNamespace).
+					WithHTTPSListener("example.com", "test-tls-secret").
+					WithListener(gatewayv1beta1.Listener{
+						Name:     "wildcard",
+						Hostname: &wildcardHostname,
+						Port:     gatewayv1beta1.PortNumber(443),
+						Protocol: gatewayv1beta1.HTTPSProtocolType,
+						TLS: &gatewayv1beta1.GatewayTLSConfig{
+							Mode: Pointer(gatewayv1beta1.TLSModeTerminate),
+							CertificateRefs: []gatewayv1beta1.SecretObjectReference{
+								{
+									Name:      "test-tls-secret",
+									Namespace: Pointer(gatewayv1beta1.Namespace(testNamespace)),
+								},
+							},
+						},
+					}).Gateway
+				Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+				Eventually(func() error { //gateway exists
+					return k8sClient.Get(ctx, client.ObjectKey{Name: gateway.Name, Namespace: gateway.Namespace}, gateway)
+				}, TestTimeoutMedium, TestRetryIntervalMedium).ShouldNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				if managedZone != nil {
+					Expect(k8sClient.Delete(ctx, managedZone)).To(BeNil())
+					managedZone = nil
+				}
+			})
+
+			It("should create a single certificate with both dnsNames", func() {
+				managedZone = testBuildManagedZone("example.com", testNamespace)
+				Expect(k8sClient.Create(ctx, managedZone)).To(BeNil())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithSolverRef(managedZone.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				Eventually(func() error {
+					certList := &certmanv1.CertificateList{}
+					err := k8sClient.List(ctx, certList, &client.ListOptions{Namespace: testNamespace})
+					Expect(err).ToNot(HaveOccurred())
+					if len(certList.Items) != 1 {
+						return fmt.Errorf("expected CertificateList to be 1")
+					}
+					return nil
+				}, time.Second*10, time.Second).Should(BeNil())
+
+				cert := &certmanv1.Certificate{}
+				err := k8sClient.Get(ctx, client.ObjectKey{Name: "test-tls-secret", Namespace: testNamespace}, cert)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cert.Spec.DNSNames).To(ConsistOf("example.com", "*.example.com"))
+				Expect(cert.Labels).To(HaveKeyWithValue(TLSPolicySolverZoneLabel, managedZone.Name))
+			})
+
+			It("should not be ready when the solver zone cannot satisfy the wildcard host", func() {
+				managedZone = testBuildManagedZone("other.com", testNamespace)
+				Expect(k8sClient.Create(ctx, managedZone)).To(BeNil())
+				tlsPolicy = NewTestTLSPolicy("test-tls-policy", testNamespace).
+					WithTargetGateway(gateway.Name).
+					WithSolverRef(managedZone.Name).
+					WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+				Expect(k8sClient.Create(ctx, tlsPolicy)).To(BeNil())
+
+				Eventually(func() error {
+					if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+						return err
+					}
+					readyCond := meta.FindStatusCondition(tlsPolicy.Status.Conditions, string(conditions.ConditionTypeReady))
+					if readyCond == nil || readyCond.Status != metav1.ConditionFalse {
+						return fmt.Errorf("expected tlsPolicy %s condition to be False", conditions.ConditionTypeReady)
+					}
+					return nil
+				}, time.Second*15, time.Second).Should(BeNil())
+
+				Consistently(func() []certmanv1.Certificate {
+					certList := &certmanv1.CertificateList{}
+					err := k8sClient.List(ctx, certList, &client.ListOptions{Namespace: testNamespace})
+					Expect(err).ToNot(HaveOccurred())
+					return certList.Items
+				}, time.Second*5, time.Second).Should(BeEmpty())
+			})
+		})
+
 	})
 
 })
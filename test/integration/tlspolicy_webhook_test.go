@@ -0,0 +1,183 @@
+//go:build integration
+
+package integration
+
+import (
+	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	. "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+var _ = Describe("TLSPolicy webhook", func() {
+
+	var testNamespace string
+
+	BeforeEach(func() {
+		CreateNamespace(&testNamespace)
+	})
+
+	AfterEach(func() {
+		policyList := v1alpha1.TLSPolicyList{}
+		Expect(k8sClient.List(ctx, &policyList, client.InNamespace(testNamespace))).To(BeNil())
+		for _, policy := range policyList.Items {
+			k8sClient.Delete(ctx, &policy)
+		}
+	})
+
+	It("should allow a policy referencing an issuer that does not exist yet", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("missingissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		Expect(k8sClient.Create(ctx, tlsPolicy)).To(Succeed())
+	})
+
+	It("should allow a policy with the issuer group and kind unset", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", "", "").TLSPolicy
+		Expect(k8sClient.Create(ctx, tlsPolicy)).To(Succeed())
+	})
+
+	It("should reject a policy with an unsupported issuer group", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "example.com").TLSPolicy
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid value for issuerRef.group example.com"))
+	})
+
+	It("should allow a policy with an issuer group allowed by the controller", func() {
+		v1alpha1.SetAllowedIssuerGroups("cert-manager.io", "awspca.cert-manager.io")
+		DeferCleanup(v1alpha1.SetAllowedIssuerGroups)
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", "AWSPCAClusterIssuer", "awspca.cert-manager.io").TLSPolicy
+		Expect(k8sClient.Create(ctx, tlsPolicy)).To(Succeed())
+	})
+
+	It("should reject a policy with an unsupported issuer kind", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", "Secret", "cert-manager.io").TLSPolicy
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid value for issuerRef.kind Secret"))
+	})
+
+	It("should reject a policy with an unsupported issuer kind in the issuer list", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").TLSPolicy
+		tlsPolicy.Spec.IssuerRefs = []cmmeta.ObjectReference{
+			{Name: "testissuer", Kind: certmanv1.IssuerKind, Group: "cert-manager.io"},
+			{Name: "fallbackissuer", Kind: "Secret", Group: "cert-manager.io"},
+		}
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid value for issuerRef.kind Secret"))
+	})
+
+	It("should reject a policy with an unsupported target kind", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		tlsPolicy.Spec.TargetRef.Kind = "Service"
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid targetRef.Kind Service"))
+	})
+
+	It("should reject a policy with a sectionName for an unsupported target kind", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetHTTPRoute("test-route").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		sectionName := gatewayv1beta1.SectionName("api")
+		tlsPolicy.Spec.TargetRef.SectionName = &sectionName
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("A sectionName is only supported for a Gateway targetRef"))
+	})
+
+	It("should reject a policy omitting a common name it sets", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		tlsPolicy.Spec.CommonName = "legacy.example.com"
+		tlsPolicy.Spec.OmitCommonName = true
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("it cannot be set together with spec.commonName"))
+	})
+
+	It("should allow a policy referencing a secret that does not exist yet", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithSecretRef("missing-tls-secret").TLSPolicy
+		Expect(k8sClient.Create(ctx, tlsPolicy)).To(Succeed())
+	})
+
+	It("should reject a policy with both an issuer and a secret reference", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").
+			WithSecretRef("test-tls-secret").TLSPolicy
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("it cannot be set together with spec.issuerRef or spec.issuerRefs"))
+	})
+
+	It("should reject a policy with an unsupported private key algorithm", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		tlsPolicy.Spec.PrivateKey = &certmanv1.CertificatePrivateKey{Algorithm: certmanv1.Ed25519KeyAlgorithm}
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid value for privateKey.algorithm Ed25519"))
+	})
+
+	It("should reject a policy with a key size that does not match the algorithm", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		tlsPolicy.Spec.PrivateKey = &certmanv1.CertificatePrivateKey{Algorithm: certmanv1.RSAKeyAlgorithm, Size: 256}
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid value for privateKey.size 256"))
+	})
+
+	It("should reject a policy with an invalid issuer rule hostname", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		tlsPolicy.Spec.IssuerRules = []v1alpha1.IssuerRule{{
+			Hostname:  "db.*.example.com",
+			IssuerRef: cmmeta.ObjectReference{Name: "internal-ca", Kind: certmanv1.IssuerKind},
+		}}
+		err := k8sClient.Create(ctx, tlsPolicy)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid value for spec.issuerRules[0].hostname"))
+	})
+
+	It("should reject an update to an unsupported issuer kind", func() {
+		tlsPolicy := NewTestTLSPolicy("test-tls-policy", testNamespace).
+			WithTargetGateway("test-gateway").
+			WithIssuer("testissuer", certmanv1.IssuerKind, "cert-manager.io").TLSPolicy
+		Expect(k8sClient.Create(ctx, tlsPolicy)).To(Succeed())
+
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(tlsPolicy), tlsPolicy); err != nil {
+				return err
+			}
+			tlsPolicy.Spec.IssuerRef.Kind = "Secret"
+			return k8sClient.Update(ctx, tlsPolicy)
+		}, TestTimeoutMedium, TestRetryIntervalMedium).Should(MatchError(ContainSubstring("invalid value for issuerRef.kind Secret")))
+	})
+})
@@ -3,9 +3,19 @@
 package testutil
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
 	certmanv1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/jetstack/cert-manager/pkg/apis/meta/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -107,11 +117,38 @@ func NewTestTLSPolicy(policyName, ns string) *TestTLSPolicy {
 
 func (t *TestTLSPolicy) WithTargetGateway(gwName string) *TestTLSPolicy {
 	typedNamespace := gatewayv1beta1.Namespace(t.GetNamespace())
-	t.Spec.TargetRef = gatewayapiv1alpha2.PolicyTargetReference{
-		Group:     "gateway.networking.k8s.io",
-		Kind:      "Gateway",
-		Name:      gatewayv1beta1.ObjectName(gwName),
-		Namespace: &typedNamespace,
+	t.Spec.TargetRef = v1alpha1.PolicyTargetReferenceWithSectionName{
+		PolicyTargetReference: gatewayapiv1alpha2.PolicyTargetReference{
+			Group:     "gateway.networking.k8s.io",
+			Kind:      "Gateway",
+			Name:      gatewayv1beta1.ObjectName(gwName),
+			Namespace: &typedNamespace,
+		},
+	}
+	return t
+}
+
+func (t *TestTLSPolicy) WithTargetGatewayListener(gwName, listenerName string) *TestTLSPolicy {
+	t.WithTargetGateway(gwName)
+	sectionName := gatewayv1beta1.SectionName(listenerName)
+	t.Spec.TargetRef.SectionName = &sectionName
+	return t
+}
+
+func (t *TestTLSPolicy) WithTargetSelector(matchLabels map[string]string) *TestTLSPolicy {
+	t.Spec.TargetSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	return t
+}
+
+func (t *TestTLSPolicy) WithTargetHTTPRoute(routeName string) *TestTLSPolicy {
+	typedNamespace := gatewayv1beta1.Namespace(t.GetNamespace())
+	t.Spec.TargetRef = v1alpha1.PolicyTargetReferenceWithSectionName{
+		PolicyTargetReference: gatewayapiv1alpha2.PolicyTargetReference{
+			Group:     "gateway.networking.k8s.io",
+			Kind:      "HTTPRoute",
+			Name:      gatewayv1beta1.ObjectName(routeName),
+			Namespace: &typedNamespace,
+		},
 	}
 	return t
 }
@@ -130,6 +167,56 @@ func (t *TestTLSPolicy) WithIssuer(name, kind, group string) *TestTLSPolicy {
 	return t
 }
 
+func (t *TestTLSPolicy) WithSolverRef(managedZoneName string) *TestTLSPolicy {
+	t.Spec.SolverRef = &v1alpha1.ManagedZoneReference{
+		Name: managedZoneName,
+	}
+	return t
+}
+
+func (t *TestTLSPolicy) WithSecretRef(secretName string) *TestTLSPolicy {
+	t.Spec.SecretRef = &corev1.LocalObjectReference{
+		Name: secretName,
+	}
+	return t
+}
+
+// NewTestTLSSecret returns a TLS Secret holding a self signed certificate and private key for the given hosts
+func NewTestTLSSecret(name, ns string, hosts ...string) (*corev1.Secret, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     hosts,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}),
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}),
+		},
+	}, nil
+}
+
 var _ client.Object = &TestResource{}
 
 // TestResource dummy client.Object that can be used in place of a real k8s resource for testing